@@ -487,6 +487,7 @@ func (c *client) RegisterAgent(ctx context.Context, info rpc.AgentInfo) (int64,
 		Version:      info.Version,
 		Capacity:     int32(info.Capacity),
 		CustomLabels: info.CustomLabels,
+		Resources:    resourcesToProto(info.Resources),
 	}
 
 	res, err := c.client.RegisterAgent(ctx, req)
@@ -498,10 +499,12 @@ func (c *client) UnregisterAgent(ctx context.Context) error {
 	return err
 }
 
-func (c *client) ReportHealth(ctx context.Context) (err error) {
+func (c *client) ReportHealth(ctx context.Context, health rpc.Health) (err error) {
 	retry := c.newBackOff()
 	req := new(proto.ReportHealthRequest)
 	req.Status = "I am alive!"
+	req.Capacity = resourcesToProto(health.Capacity)
+	req.Usage = resourcesToProto(health.Usage)
 
 	for {
 		_, err = c.client.ReportHealth(ctx, req)
@@ -539,3 +542,10 @@ func (c *client) ReportHealth(ctx context.Context) (err error) {
 		}
 	}
 }
+
+func resourcesToProto(resources rpc.Resources) *proto.Resources {
+	return &proto.Resources{
+		Cpu:    resources.CPU,
+		Memory: resources.Memory,
+	}
+}
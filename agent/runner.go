@@ -33,11 +33,18 @@ import (
 )
 
 type Runner struct {
-	client   rpc.Peer
-	filter   rpc.Filter
-	hostname string
-	counter  *State
-	backend  *backend.Backend
+	client        rpc.Peer
+	filter        rpc.Filter
+	hostname      string
+	counter       *State
+	backend       *backend.Backend
+	prefetched    chan prefetchedWorkflow
+	prefetchLease time.Duration
+}
+
+type prefetchedWorkflow struct {
+	workflow *rpc.Workflow
+	received time.Time
 }
 
 func NewRunner(workEngine rpc.Peer, f rpc.Filter, h string, state *State, backend *backend.Backend) Runner {
@@ -50,6 +57,16 @@ func NewRunner(workEngine rpc.Peer, f rpc.Filter, h string, state *State, backen
 	}
 }
 
+// EnablePrefetch lets the runner poll up to depth workflows while it is busy.
+// The server reserves prefetched workflows only for the given lease.
+func (r *Runner) EnablePrefetch(depth int, lease time.Duration) {
+	if depth <= 0 || lease <= 0 {
+		return
+	}
+	r.prefetched = make(chan prefetchedWorkflow, depth)
+	r.prefetchLease = lease
+}
+
 func (r *Runner) Run(runnerCtx, shutdownCtx context.Context) error { //nolint:contextcheck
 	log.Debug().Msg("request next execution")
 
@@ -57,7 +74,7 @@ func (r *Runner) Run(runnerCtx, shutdownCtx context.Context) error { //nolint:co
 	ctxMeta := metadata.NewOutgoingContext(context.Background(), meta)
 
 	// get the next workflow from the queue
-	workflow, err := r.client.Next(runnerCtx, r.filter)
+	workflow, err := r.next(runnerCtx)
 	if err != nil {
 		return err
 	}
@@ -65,6 +82,9 @@ func (r *Runner) Run(runnerCtx, shutdownCtx context.Context) error { //nolint:co
 		return nil
 	}
 
+	stopPrefetch := r.prefetch(runnerCtx)
+	defer stopPrefetch()
+
 	timeout := time.Hour
 	if minutes := workflow.Timeout; minutes != 0 {
 		timeout = time.Duration(minutes) * time.Minute
@@ -133,8 +153,9 @@ func (r *Runner) Run(runnerCtx, shutdownCtx context.Context) error { //nolint:co
 
 	err = r.client.Init(runnerCtx, workflow.ID, state)
 	if err != nil {
-		logger.Error().Err(err).Msg("workflow initialization failed")
-		// TODO: should we return here?
+		// e.g. the lease of a prefetched workflow expired and it got handed out to another agent
+		logger.Error().Err(err).Msg("workflow initialization failed, abandon workflow")
+		return nil
 	}
 
 	var uploads sync.WaitGroup
@@ -190,6 +211,62 @@ func (r *Runner) Run(runnerCtx, shutdownCtx context.Context) error { //nolint:co
 	return nil
 }
 
+// next returns a prefetched workflow if its lease is still valid or polls a new one.
+func (r *Runner) next(ctx context.Context) (*rpc.Workflow, error) {
+	for {
+		select {
+		case p := <-r.prefetched:
+			// keep a safety margin, the workflow has to be started before the lease ends
+			if time.Since(p.received) < r.prefetchLease*3/4 {
+				log.Debug().Str("workflow_id", p.workflow.ID).Msg("use prefetched workflow")
+				return p.workflow, nil
+			}
+			log.Debug().Str("workflow_id", p.workflow.ID).Msg("drop prefetched workflow as its lease expired")
+		default:
+			return r.client.Next(ctx, r.filter)
+		}
+	}
+}
+
+// prefetch polls workflows in the background until the buffer is full or the returned func is called.
+// Nothing is prefetched while other runners are idle, as they can take new workflows right away.
+func (r *Runner) prefetch(ctx context.Context) func() {
+	if r.prefetched == nil {
+		return func() {}
+	}
+
+	ctx, cancel := context.WithCancel(metadata.AppendToOutgoingContext(ctx,
+		constant.PrefetchLeaseMetadataKey, r.prefetchLease.String()))
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		for len(r.prefetched) < cap(r.prefetched) && ctx.Err() == nil {
+			if r.counter.Idle() > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+				continue
+			}
+
+			workflow, err := r.client.Next(ctx, r.filter)
+			if err != nil {
+				log.Error().Err(err).Msg("prefetching workflow failed")
+				return
+			}
+			if workflow != nil {
+				r.prefetched <- prefetchedWorkflow{workflow: workflow, received: time.Now()}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
 func extractRepositoryName(config *backend.Config) string {
 	return config.Stages[0].Steps[0].Environment["CI_REPO"]
 }
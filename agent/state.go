@@ -59,6 +59,13 @@ func (s *State) Done(id string) {
 	s.Unlock()
 }
 
+// Idle returns the number of runners waiting for a workflow.
+func (s *State) Idle() int {
+	s.Lock()
+	defer s.Unlock()
+	return s.Polling
+}
+
 func (s *State) Healthy() bool {
 	s.Lock()
 	defer s.Unlock()
@@ -17,8 +17,12 @@ package admin
 import (
 	"github.com/urfave/cli/v3"
 
+	"go.woodpecker-ci.org/woodpecker/v3/cli/admin/agenttoken"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/admin/db"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/admin/loglevel"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/admin/maintenance"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/admin/org"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/admin/queue"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/admin/registry"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/admin/secret"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/admin/user"
@@ -29,8 +33,12 @@ var Command = &cli.Command{
 	Name:  "admin",
 	Usage: "manage server settings",
 	Commands: []*cli.Command{
+		agenttoken.Command,
+		db.Command,
 		loglevel.Command,
+		maintenance.Command,
 		org.Command,
+		queue.Command,
 		registry.Command,
 		secret.Command,
 		user.Command,
@@ -0,0 +1,30 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agenttoken
+
+import (
+	"github.com/urfave/cli/v3"
+)
+
+// Command exports the agent-token command set.
+var Command = &cli.Command{
+	Name:  "agent-token",
+	Usage: "manage the shared tokens agents can register with",
+	Commands: []*cli.Command{
+		agentTokenCreateCmd,
+		agentTokenDeleteCmd,
+		agentTokenListCmd,
+	},
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agenttoken
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+)
+
+var agentTokenDeleteCmd = &cli.Command{
+	Name:   "rm",
+	Usage:  "retire an agent token",
+	Action: agentTokenDelete,
+	Flags: []cli.Flag{
+		&cli.Int64Flag{
+			Name:     "id",
+			Usage:    "agent token id",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "force",
+			Usage: "remove the token even if agents still use it",
+		},
+	},
+}
+
+func agentTokenDelete(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if err := client.AgentTokenDelete(c.Int64("id"), c.Bool("force")); err != nil {
+		return err
+	}
+
+	fmt.Println("Success")
+	return nil
+}
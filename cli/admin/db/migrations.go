@@ -0,0 +1,111 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/output"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+var migrationsCmd = &cli.Command{
+	Name:  "migrations",
+	Usage: "inspect and roll back store migrations",
+	Commands: []*cli.Command{
+		migrationStatusCmd,
+		migrationRollbackCmd,
+	},
+}
+
+var migrationStatusCmd = &cli.Command{
+	Name:   "status",
+	Usage:  "list applied and pending migrations",
+	Action: migrationStatus,
+}
+
+var migrationRollbackCmd = &cli.Command{
+	Name:      "rollback",
+	Usage:     "roll back the last applied migration",
+	ArgsUsage: "<version>",
+	Action:    migrationRollback,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "yes",
+			Usage: "confirm the rollback, it may drop data",
+		},
+	},
+}
+
+var errRollbackNotConfirmed = errors.New("rolling back a migration may drop data, rerun with --yes to confirm")
+
+func migrationStatus(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	return showMigrationStatus(client, os.Stdout)
+}
+
+func showMigrationStatus(client woodpecker.Client, out io.Writer) error {
+	list, err := client.MigrationStatus()
+	if err != nil {
+		return err
+	}
+
+	cols := []string{"ID", "Applied", "Reversible", "Description"}
+	table := output.NewTable(out)
+	table.WriteHeader(cols)
+	for _, m := range list {
+		if err := table.Write(cols, m); err != nil {
+			return err
+		}
+	}
+	return table.Flush()
+}
+
+func migrationRollback(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	return rollbackMigration(c, client)
+}
+
+func rollbackMigration(c *cli.Command, client woodpecker.Client) error {
+	version := c.Args().First()
+	if version == "" {
+		return errors.New("missing migration version")
+	}
+	if !c.Bool("yes") {
+		return errRollbackNotConfirmed
+	}
+
+	if err := client.MigrationRollback(version); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rolled back migration %s\n", version)
+	return nil
+}
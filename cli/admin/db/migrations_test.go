@@ -0,0 +1,75 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestShowMigrationStatus(t *testing.T) {
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("MigrationStatus").Return([]*woodpecker.Migration{
+		{ID: "split-trusted", Applied: true},
+		{ID: "fix-forge-columns", Description: "fix forge columns", Applied: false, Reversible: true},
+	}, nil)
+
+	var out bytes.Buffer
+	assert.NoError(t, showMigrationStatus(mockClient, &out))
+	assert.Equal(t, `ID                 APPLIED  REVERSIBLE  DESCRIPTION
+split-trusted      yes      no          -
+fix-forge-columns  no       yes         fix forge columns
+`, out.String())
+}
+
+func TestRollbackMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		rollback bool
+		wantErr  string
+	}{
+		{
+			name:    "missing version",
+			args:    []string{"rollback", "--yes"},
+			wantErr: "missing migration version",
+		},
+		{
+			name:    "not confirmed",
+			args:    []string{"rollback", "fix-forge-columns"},
+			wantErr: errRollbackNotConfirmed.Error(),
+		},
+		{
+			name:     "confirmed",
+			args:     []string{"rollback", "--yes", "fix-forge-columns"},
+			rollback: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			if tt.rollback {
+				mockClient.On("MigrationRollback", "fix-forge-columns").Return(nil)
+			}
+
+			command := &cli.Command{
+				Flags: []cli.Flag{&cli.BoolFlag{Name: "yes"}},
+				Action: func(_ context.Context, c *cli.Command) error {
+					return rollbackMigration(c, mockClient)
+				},
+			}
+			err := command.Run(t.Context(), tt.args)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+// Command exports the maintenance command used to toggle the servers maintenance mode.
+var Command = &cli.Command{
+	Name:      "maintenance",
+	ArgsUsage: "[on|off]",
+	Usage:     "retrieve maintenance mode from server, or enable/disable it with [on|off]",
+	Action:    maintenance,
+}
+
+func maintenance(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var m *woodpecker.Maintenance
+	switch arg := c.Args().First(); arg {
+	case "":
+		m, err = client.Maintenance()
+	case "on", "off":
+		m, err = client.SetMaintenance(&woodpecker.Maintenance{
+			Enabled: arg == "on",
+		})
+	default:
+		return fmt.Errorf("invalid argument '%s', must be 'on' or 'off'", arg)
+	}
+	if err != nil {
+		return err
+	}
+
+	if m.Enabled {
+		log.Info().Msg("maintenance mode: on")
+	} else {
+		log.Info().Msg("maintenance mode: off")
+	}
+	return nil
+}
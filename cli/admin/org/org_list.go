@@ -18,6 +18,7 @@ var Command = &cli.Command{
 	Usage: "manage organizations",
 	Commands: []*cli.Command{
 		orgListCmd,
+		orgUpdateCmd,
 	},
 }
 
@@ -46,7 +47,7 @@ func orgList(ctx context.Context, c *cli.Command) error {
 		return err
 	}
 
-	tmpl, err := template.New("_").Funcs(orgFuncMap).Parse(format)
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Funcs(orgFuncMap).Parse(format)
 	if err != nil {
 		return err
 	}
@@ -62,6 +63,18 @@ func orgList(ctx context.Context, c *cli.Command) error {
 // Template for org list items.
 var tmplOrgList = "\x1b[33m{{ .Name }} \x1b[0m" + `
 Organization ID: {{ .ID }}
+{{- with .FeatureFlags }}
+{{- with .AllowPullRequests }}
+Allow pull requests: {{ . }}{{ end }}
+{{- with .PrivilegedPlugins }}
+Privileged plugins: {{ list . }}{{ end }}
+{{- with .ExposeSecretsToForks }}
+Expose secrets to forks: {{ . }}{{ end }}
+{{- with .DefaultTimeout }}
+Default timeout: {{ . }}m{{ end }}
+{{- with .MaxRunningPipelines }}
+Max running pipelines: {{ . }}{{ end }}
+{{- end }}
 `
 
 var orgFuncMap = template.FuncMap{
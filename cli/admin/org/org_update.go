@@ -0,0 +1,132 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package org
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+var orgUpdateCmd = &cli.Command{
+	Name:      "update",
+	Usage:     "update the feature flag overrides of an organization",
+	ArgsUsage: "<org-id|org-full-name>",
+	Action:    orgUpdate,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "allow-pull-requests",
+			Usage: "allow pull requests for newly activated repositories",
+		},
+		&cli.StringSliceFlag{
+			Name:  "privileged-plugins",
+			Usage: "plugins allowed to run in privileged mode",
+		},
+		&cli.BoolFlag{
+			Name:  "expose-secrets-to-forks",
+			Usage: "pass secrets to pipelines of pull requests from forks",
+		},
+		&cli.DurationFlag{
+			Name:  "default-timeout",
+			Usage: "default timeout for newly activated repositories",
+		},
+		&cli.IntFlag{
+			Name:  "max-running-pipelines",
+			Usage: "max number of pipelines of the organization running at once, 0 means unlimited",
+		},
+		&cli.StringSliceFlag{
+			Name:  "reset",
+			Usage: "remove overrides so the global default applies again (allow-pull-requests, privileged-plugins, expose-secrets-to-forks, default-timeout, max-running-pipelines)",
+		},
+	},
+}
+
+func orgUpdate(ctx context.Context, c *cli.Command) error {
+	orgIDOrName := c.Args().First()
+	if orgIDOrName == "" {
+		return fmt.Errorf("missing organization id or name")
+	}
+
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var org *woodpecker.Org
+	if orgID, err := strconv.ParseInt(orgIDOrName, 10, 64); err == nil {
+		org, err = client.Org(orgID)
+		if err != nil {
+			return err
+		}
+	} else {
+		org, err = client.OrgLookup(orgIDOrName)
+		if err != nil {
+			return err
+		}
+	}
+
+	flags := org.FeatureFlags
+	for _, name := range c.StringSlice("reset") {
+		switch name {
+		case "allow-pull-requests":
+			flags.AllowPullRequests = nil
+		case "privileged-plugins":
+			flags.PrivilegedPlugins = nil
+		case "expose-secrets-to-forks":
+			flags.ExposeSecretsToForks = nil
+		case "default-timeout":
+			flags.DefaultTimeout = nil
+		case "max-running-pipelines":
+			flags.MaxRunningPipelines = nil
+		default:
+			return fmt.Errorf("unknown feature flag '%s'", name)
+		}
+	}
+
+	if c.IsSet("allow-pull-requests") {
+		v := c.Bool("allow-pull-requests")
+		flags.AllowPullRequests = &v
+	}
+	if c.IsSet("privileged-plugins") {
+		v := c.StringSlice("privileged-plugins")
+		flags.PrivilegedPlugins = &v
+	}
+	if c.IsSet("expose-secrets-to-forks") {
+		v := c.Bool("expose-secrets-to-forks")
+		flags.ExposeSecretsToForks = &v
+	}
+	if c.IsSet("default-timeout") {
+		v := int64(c.Duration("default-timeout") / time.Minute)
+		flags.DefaultTimeout = &v
+	}
+	if c.IsSet("max-running-pipelines") {
+		v := c.Int("max-running-pipelines")
+		flags.MaxRunningPipelines = &v
+	}
+
+	org, err = client.OrgPatch(org.ID, &woodpecker.OrgPatch{FeatureFlags: &flags})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully updated organization %s\n", org.Name)
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/output"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+var queueListCmd = &cli.Command{
+	Name:   "ls",
+	Usage:  "list pending, waiting and running tasks",
+	Action: queueList,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "watch",
+			Usage: "refresh the list until interrupted",
+		},
+		&cli.DurationFlag{
+			Name:  "interval",
+			Usage: "refresh interval of --watch",
+			Value: 2 * time.Second, //nolint:mnd
+		},
+	},
+}
+
+// queueTask is a table row of a task in the queue.
+type queueTask struct {
+	State    string
+	ID       string
+	Repo     string
+	Pipeline string
+	Agent    string
+	Labels   string
+}
+
+func queueList(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if !c.Bool("watch") {
+		return showQueue(client, os.Stdout)
+	}
+
+	for {
+		// move the cursor home and clear the screen
+		fmt.Fprint(os.Stdout, "\x1b[H\x1b[2J")
+		if err := showQueue(client, os.Stdout); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(c.Duration("interval")):
+		}
+	}
+}
+
+func showQueue(client woodpecker.Client, out io.Writer) error {
+	info, err := client.QueueInfo()
+	if err != nil {
+		return err
+	}
+
+	status := ""
+	if info.Paused {
+		status = " (paused)"
+	}
+	fmt.Fprintf(out, "Workers: %d  Pending: %d  Waiting on deps: %d  Running: %d%s\n\n",
+		info.Stats.Workers, info.Stats.Pending, info.Stats.WaitingOnDeps, info.Stats.Running, status)
+
+	cols := []string{"State", "ID", "Repo", "Pipeline", "Agent", "Labels"}
+	table := output.NewTable(out)
+	table.WriteHeader(cols)
+	for _, list := range []struct {
+		state string
+		tasks []woodpecker.Task
+	}{
+		{"running", info.Running},
+		{"pending", info.Pending},
+		{"waiting", info.WaitingOnDeps},
+	} {
+		for _, task := range list.tasks {
+			if err := table.Write(cols, toQueueTask(list.state, task)); err != nil {
+				return err
+			}
+		}
+	}
+	return table.Flush()
+}
+
+func toQueueTask(state string, task woodpecker.Task) queueTask {
+	row := queueTask{
+		State: state,
+		ID:    task.ID,
+		Repo:  task.RepoFullName,
+		Agent: task.AgentName,
+	}
+	if row.Repo == "" && task.RepoID != 0 {
+		row.Repo = fmt.Sprintf("%d", task.RepoID)
+	}
+	if task.PipelineNumber != 0 {
+		row.Pipeline = fmt.Sprintf("#%d", task.PipelineNumber)
+	}
+
+	labels := make([]string, 0, len(task.Labels))
+	for _, key := range slices.Sorted(maps.Keys(task.Labels)) {
+		if task.Labels[key] != "" {
+			labels = append(labels, key+"="+task.Labels[key])
+		}
+	}
+	row.Labels = strings.Join(labels, ",")
+	return row
+}
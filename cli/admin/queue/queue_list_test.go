@@ -0,0 +1,52 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestShowQueue(t *testing.T) {
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("QueueInfo").Return(&woodpecker.Info{
+		Pending: []woodpecker.Task{
+			{ID: "12", RepoID: 3, PipelineNumber: 8, Labels: map[string]string{"repo": "owner/other", "platform": "linux/arm64", "backend": ""}},
+		},
+		WaitingOnDeps: []woodpecker.Task{
+			{ID: "13", RepoFullName: "owner/repo", PipelineNumber: 7},
+		},
+		Running: []woodpecker.Task{
+			{ID: "11", RepoFullName: "owner/repo", PipelineNumber: 7, AgentName: "agent-1", Labels: map[string]string{"platform": "linux/amd64"}},
+		},
+		Stats:  woodpecker.QueueStats{Workers: 2, Pending: 1, WaitingOnDeps: 1, Running: 1},
+		Paused: true,
+	}, nil)
+
+	var out bytes.Buffer
+	assert.NoError(t, showQueue(mockClient, &out))
+	assert.Equal(t, `Workers: 2  Pending: 1  Waiting on deps: 1  Running: 1 (paused)
+
+STATE    ID  REPO        PIPELINE  AGENT    LABELS
+running  11  owner/repo  #7        agent-1  platform=linux/amd64
+pending  12  3           #8        -        platform=linux/arm64,repo=owner/other
+waiting  13  owner/repo  #7        -        -
+`, out.String())
+}
@@ -55,7 +55,7 @@ func registryShow(ctx context.Context, c *cli.Command) error {
 		return err
 	}
 
-	tmpl, err := template.New("_").Parse(format)
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(format)
 	if err != nil {
 		return err
 	}
@@ -26,6 +26,7 @@ var Command = &cli.Command{
 		secretCreateCmd,
 		secretDeleteCmd,
 		secretListCmd,
+		secretRotateJWTCmd,
 		secretShowCmd,
 		secretUpdateCmd,
 	},
@@ -51,7 +51,7 @@ func secretList(ctx context.Context, c *cli.Command) error {
 		return err
 	}
 
-	tmpl, err := template.New("_").Funcs(secretFuncMap).Parse(format)
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Funcs(secretFuncMap).Parse(format)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,52 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+)
+
+var secretRotateJWTCmd = &cli.Command{
+	Name:   "rotate-jwt",
+	Usage:  "rotate the secret the server signs its tokens with",
+	Action: secretRotateJWT,
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "grace-period",
+			Usage: "duration tokens signed with the previous secret stay valid",
+			Value: 24 * time.Hour,
+		},
+	},
+}
+
+func secretRotateJWT(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if err := client.RotateJWTSecret(c.Duration("grace-period")); err != nil {
+		return err
+	}
+
+	fmt.Println("Success")
+	return nil
+}
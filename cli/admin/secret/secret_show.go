@@ -59,7 +59,7 @@ func secretShow(ctx context.Context, c *cli.Command) error {
 		return err
 	}
 
-	tmpl, err := template.New("_").Funcs(secretFuncMap).Parse(format)
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Funcs(secretFuncMap).Parse(format)
 	if err != nil {
 		return err
 	}
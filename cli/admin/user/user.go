@@ -26,6 +26,7 @@ var Command = &cli.Command{
 		userAddCmd,
 		userListCmd,
 		userRemoveCmd,
+		userResetTwoFactorCmd,
 		userShowCmd,
 	},
 }
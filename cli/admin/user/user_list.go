@@ -47,7 +47,7 @@ func userList(ctx context.Context, c *cli.Command) error {
 		return err
 	}
 
-	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(c.String("format") + "\n")
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,49 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package user
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+)
+
+var userResetTwoFactorCmd = &cli.Command{
+	Name:      "reset-2fa",
+	Usage:     "remove the second factor of a user, e.g. after the device and recovery codes got lost",
+	ArgsUsage: "<username>",
+	Action:    userResetTwoFactor,
+}
+
+func userResetTwoFactor(ctx context.Context, c *cli.Command) error {
+	login := c.Args().First()
+	if login == "" {
+		return fmt.Errorf("missing or invalid user login")
+	}
+
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	if err := client.UserResetTwoFactor(login); err != nil {
+		return err
+	}
+	fmt.Printf("Successfully removed the second factor of user %s\n", login)
+	return nil
+}
@@ -50,7 +50,7 @@ func userShow(ctx context.Context, c *cli.Command) error {
 		return err
 	}
 
-	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(c.String("format") + "\n")
 	if err != nil {
 		return err
 	}
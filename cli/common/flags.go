@@ -70,6 +70,7 @@ func FormatFlag(tmpl string, deprecated bool, hidden ...bool) *cli.StringFlag {
 	if deprecated {
 		usage = fmt.Sprintf("%s (deprecated)", usage)
 	}
+	usage = fmt.Sprintf("%s, %s", usage, templateFuncsUsage)
 
 	return &cli.StringFlag{
 		Name:   "format",
@@ -0,0 +1,89 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/muesli/termenv"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+// templateFuncsUsage documents the TemplateFuncs in the usage of the format flag.
+const templateFuncsUsage = `template funcs: {{ .Started | date "2006-01-02 15:04" }}, {{ duration .Started .Finished }}, {{ .State | color }}, {{ truncate .Name 20 }}`
+
+// templateOutput is used to colorize the template output, colors are disabled if stdout is no terminal.
+var templateOutput = termenv.NewOutput(os.Stdout)
+
+// TemplateFuncs are the functions available in the format templates of the commands.
+var TemplateFuncs = template.FuncMap{
+	"date":     templateDate,
+	"duration": templateDuration,
+	"color":    templateColor,
+	"truncate": templateTruncate,
+}
+
+// templateDate formats a unix timestamp in the local timezone, unset timestamps are empty.
+func templateDate(layout string, timestamp int64) string {
+	if timestamp == 0 {
+		return ""
+	}
+	return time.Unix(timestamp, 0).Format(layout)
+}
+
+// templateDuration returns the time between two unix timestamps, running until now if end is unset.
+func templateDuration(start, end int64) string {
+	if start == 0 {
+		return ""
+	}
+	if end == 0 {
+		end = time.Now().Unix()
+	}
+	return (time.Duration(end-start) * time.Second).String()
+}
+
+// templateColor colorizes a pipeline, workflow or step state.
+func templateColor(state any) string {
+	s := fmt.Sprint(state)
+
+	var color string
+	switch s {
+	case woodpecker.StatusSuccess:
+		color = "2"
+	case woodpecker.StatusFailure, woodpecker.StatusError, woodpecker.StatusKilled:
+		color = "1"
+	case woodpecker.StatusPending, woodpecker.StatusRunning, woodpecker.StatusBlocked:
+		color = "3"
+	default:
+		return s
+	}
+	return templateOutput.String(s).Foreground(templateOutput.Color(color)).String()
+}
+
+// templateTruncate shortens s to at most n characters, marking cut strings with an ellipsis.
+func templateTruncate(s string, n int) string {
+	runes := []rune(s)
+	switch {
+	case len(runes) <= n:
+		return s
+	case n <= 0:
+		return ""
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -0,0 +1,93 @@
+package common
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/muesli/termenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	started := time.Date(2025, 3, 4, 10, 30, 0, 0, time.Local)
+	step := map[string]any{
+		"Name":     "build-and-publish-the-image",
+		"State":    "failure",
+		"Started":  started.Unix(),
+		"Finished": started.Add(90 * time.Second).Unix(),
+		"Created":  int64(0),
+	}
+
+	tests := []struct {
+		name   string
+		format string
+		want   string
+	}{
+		{
+			name:   "date",
+			format: `{{ .Started | date "2006-01-02 15:04" }}`,
+			want:   "2025-03-04 10:30",
+		},
+		{
+			name:   "date unset",
+			format: `[{{ .Created | date "2006-01-02" }}]`,
+			want:   "[]",
+		},
+		{
+			name:   "duration",
+			format: `{{ duration .Started .Finished }}`,
+			want:   "1m30s",
+		},
+		{
+			name:   "duration unset",
+			format: `[{{ duration .Created .Finished }}]`,
+			want:   "[]",
+		},
+		{
+			name:   "color without terminal",
+			format: `{{ .State | color }}`,
+			want:   "failure",
+		},
+		{
+			name:   "truncate",
+			format: `{{ truncate .Name 10 }}`,
+			want:   "build-and…",
+		},
+		{
+			name:   "truncate short",
+			format: `{{ truncate .State 10 }}`,
+			want:   "failure",
+		},
+	}
+
+	templateOutput = termenv.NewOutput(io.Discard, termenv.WithProfile(termenv.Ascii))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := template.New("_").Funcs(TemplateFuncs).Parse(tt.format)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			require.NoError(t, tmpl.Execute(&buf, step))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestTemplateColor(t *testing.T) {
+	templateOutput = termenv.NewOutput(io.Discard, termenv.WithProfile(termenv.ANSI))
+	t.Cleanup(func() { templateOutput = termenv.NewOutput(io.Discard, termenv.WithProfile(termenv.Ascii)) })
+
+	tmpl, err := template.New("_").Funcs(TemplateFuncs).Parse(`{{ range . }}{{ . | color }} {{ end }}`)
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	require.NoError(t, tmpl.Execute(&buf, []string{"success", "failure", "running", "skipped"}))
+	assert.Equal(t, "\x1b[32msuccess\x1b[0m \x1b[31mfailure\x1b[0m \x1b[33mrunning\x1b[0m skipped ", buf.String())
+}
+
+func TestTemplateDurationRunning(t *testing.T) {
+	started := time.Now().Add(-time.Minute).Unix()
+	assert.NotEmpty(t, templateDuration(started, 0))
+}
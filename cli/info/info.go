@@ -16,43 +16,97 @@ package info
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net/http"
 	"os"
+	"strings"
 	"text/template"
 
+	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v3"
 
 	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/version"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
 )
 
 // Command exports the info command.
 var Command = &cli.Command{
 	Name:      "info",
-	Usage:     "show information about the current user",
+	Usage:     "show information about the current user and the server",
 	ArgsUsage: " ",
 	Action:    info,
 	Flags:     []cli.Flag{common.FormatFlag(tmplInfo, true)},
 }
 
+// infoData is passed to the template, the user fields are embedded to keep existing templates working.
+// Server is nil if the server does not provide the server info.
+type infoData struct {
+	*woodpecker.User
+	Server *woodpecker.ServerInfo
+}
+
 func info(ctx context.Context, c *cli.Command) error {
 	client, err := internal.NewClient(ctx, c)
 	if err != nil {
 		return err
 	}
 
+	return showInfo(c, client, os.Stdout)
+}
+
+func showInfo(c *cli.Command, client woodpecker.Client, out io.Writer) error {
 	user, err := client.Self()
 	if err != nil {
 		return err
 	}
 
-	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	serverInfo, err := client.ServerInfo()
+	var clientErr *woodpecker.ClientError
+	if errors.As(err, &clientErr) && clientErr.StatusCode == http.StatusNotFound {
+		// older servers don't provide the server info
+		log.Warn().Msg("server does not provide server information, it is probably older than the cli")
+		serverInfo, err = nil, nil
+	}
+	if err != nil {
+		return err
+	}
+	if serverInfo != nil && versionMismatch(version.String(), serverInfo.Version) {
+		log.Warn().Msgf("cli version %s does not match server version %s, some commands might not work as expected", version.String(), serverInfo.Version)
+	}
+
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(c.String("format") + "\n")
 	if err != nil {
 		return err
 	}
 
-	return tmpl.Execute(os.Stdout, user)
+	return tmpl.Execute(out, infoData{User: user, Server: serverInfo})
+}
+
+// versionMismatch returns whether the major or minor version of the cli and the server differ.
+// Development builds are never reported as mismatch.
+func versionMismatch(cliVersion, serverVersion string) bool {
+	isDev := func(v string) bool {
+		return v == "" || v == "dev" || strings.HasPrefix(v, "next-")
+	}
+	if isDev(cliVersion) || isDev(serverVersion) {
+		return false
+	}
+
+	majorMinor := func(v string) string {
+		parts := strings.SplitN(strings.TrimPrefix(v, "v"), ".", 3) //nolint:mnd
+		return strings.Join(parts[:min(len(parts), 2)], ".")
+	}
+	return majorMinor(cliVersion) != majorMinor(serverVersion)
 }
 
-// Template for user information.
+// Template for user and server information.
 var tmplInfo = `User: {{ .Login }}
-Email: {{ .Email }}`
+Email: {{ .Email }}{{ with .Server }}
+Server version: {{ .Version }}
+Forge: {{ .Forge }}
+Log store: {{ .Features.LogStore }}
+Swagger: {{ .Features.Swagger }}
+Open registration: {{ .Features.OpenRegistration }}{{ end }}`
@@ -0,0 +1,72 @@
+package info
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestShowInfo(t *testing.T) {
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("Self").Return(&woodpecker.User{Login: "octocat", Email: "octocat@example.com"}, nil)
+	mockClient.On("ServerInfo").Return(&woodpecker.ServerInfo{
+		Version: "3.5.0",
+		Forge:   "github",
+		Features: woodpecker.ServerFeatures{
+			Swagger:  true,
+			LogStore: "database",
+		},
+	}, nil)
+
+	var out bytes.Buffer
+	command := &cli.Command{
+		Flags: []cli.Flag{common.FormatFlag(tmplInfo, true)},
+		Action: func(_ context.Context, c *cli.Command) error {
+			return showInfo(c, mockClient, &out)
+		},
+	}
+	assert.NoError(t, command.Run(t.Context(), []string{"info"}))
+	assert.Equal(t, `User: octocat
+Email: octocat@example.com
+Server version: 3.5.0
+Forge: github
+Log store: database
+Swagger: true
+Open registration: false
+`, out.String())
+}
+
+func TestShowInfoWithoutServerInfo(t *testing.T) {
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("Self").Return(&woodpecker.User{Login: "octocat", Email: "octocat@example.com"}, nil)
+	mockClient.On("ServerInfo").Return(nil, &woodpecker.ClientError{StatusCode: http.StatusNotFound, Message: "404 page not found"})
+
+	var out bytes.Buffer
+	command := &cli.Command{
+		Flags: []cli.Flag{common.FormatFlag(tmplInfo, true)},
+		Action: func(_ context.Context, c *cli.Command) error {
+			return showInfo(c, mockClient, &out)
+		},
+	}
+	assert.NoError(t, command.Run(t.Context(), []string{"info"}))
+	assert.Equal(t, `User: octocat
+Email: octocat@example.com
+`, out.String())
+}
+
+func TestVersionMismatch(t *testing.T) {
+	assert.False(t, versionMismatch("3.5.0", "3.5.2"))
+	assert.False(t, versionMismatch("v3.5.0", "3.5.0"))
+	assert.True(t, versionMismatch("3.4.0", "3.5.0"))
+	assert.True(t, versionMismatch("2.8.0", "3.0.0"))
+	assert.False(t, versionMismatch("dev", "3.5.0"))
+	assert.False(t, versionMismatch("3.5.0", "next-1234abcd"))
+}
@@ -1,8 +1,13 @@
 package output
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
 	"strings"
+
+	"gopkg.in/yaml.v3"
 )
 
 var ErrOutputOptionRequired = errors.New("output option required")
@@ -22,3 +27,36 @@ func ParseOutputOptions(out string) (string, []string) {
 
 	return out, optList
 }
+
+// IsStructured returns if the output format is machine-readable json or yaml.
+func IsStructured(out string) bool {
+	return out == "json" || out == "yaml"
+}
+
+// WriteStructured writes v as json or yaml to w.
+// The yaml keys are the same as the json keys.
+func WriteStructured(w io.Writer, out string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	switch out {
+	case "json":
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case "yaml":
+		var generic any
+		if err := json.Unmarshal(data, &generic); err != nil {
+			return err
+		}
+		enc := yaml.NewEncoder(w)
+		enc.SetIndent(2)
+		if err := enc.Encode(generic); err != nil {
+			return err
+		}
+		return enc.Close()
+	default:
+		return fmt.Errorf("unsupported output format '%s'", out)
+	}
+}
@@ -0,0 +1,77 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteStructured(t *testing.T) {
+	type item struct {
+		PID      int    `json:"pid"`
+		Name     string `json:"name"`
+		ExitCode int    `json:"exit_code"`
+	}
+	items := []item{{PID: 1, Name: "clone"}, {PID: 2, Name: "build", ExitCode: 1}}
+
+	tests := []struct {
+		format  string
+		items   []item
+		want    string
+		wantErr string
+	}{
+		{
+			format: "json",
+			items:  items,
+			want: `[
+  {
+    "pid": 1,
+    "name": "clone",
+    "exit_code": 0
+  },
+  {
+    "pid": 2,
+    "name": "build",
+    "exit_code": 1
+  }
+]
+`,
+		},
+		{
+			format: "json",
+			items:  []item{},
+			want:   "[]\n",
+		},
+		{
+			format: "yaml",
+			items:  items,
+			want: `- exit_code: 0
+  name: clone
+  pid: 1
+- exit_code: 1
+  name: build
+  pid: 2
+`,
+		},
+		{
+			format:  "xml",
+			items:   items,
+			wantErr: "unsupported output format 'xml'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var out bytes.Buffer
+			err := WriteStructured(&out, tt.format, tt.items)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
@@ -134,7 +134,7 @@ func deploy(ctx context.Context, c *cli.Command) error {
 		return err
 	}
 
-	tmpl, err := template.New("_").Parse(c.String("format"))
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(c.String("format"))
 	if err != nil {
 		return err
 	}
@@ -40,7 +40,7 @@ func buildPipelineListCmd() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:  "event",
-				Usage: "event filter",
+				Usage: "event filter (push, pull_request, tag, cron, ...)",
 			},
 			&cli.StringFlag{
 				Name:  "status",
@@ -106,6 +106,14 @@ func pipelineList(c *cli.Command, client woodpecker.Client) ([]*woodpecker.Pipel
 	status := c.String("status")
 	limit := c.Int("limit")
 
+	if err := validateEvent(event); err != nil {
+		return nil, err
+	}
+	var events []string
+	if event != "" {
+		events = []string{event}
+	}
+
 	pipelines, err := shared_utils.Paginate(func(page int) ([]*woodpecker.Pipeline, error) {
 		return client.PipelineList(repoID,
 			woodpecker.PipelineListOptions{
@@ -115,7 +123,7 @@ func pipelineList(c *cli.Command, client woodpecker.Client) ([]*woodpecker.Pipel
 				Before: opt.Before,
 				After:  opt.After,
 				Branch: branch,
-				Events: []string{event},
+				Events: events,
 				Status: status,
 			},
 		)
@@ -54,6 +54,23 @@ func TestPipelineList(t *testing.T) {
 				{ID: 2, Branch: "develop", Event: "pull_request", Status: "running"},
 			},
 		},
+		{
+			name:   "event filter",
+			repoID: 1,
+			pipelines: []*woodpecker.Pipeline{
+				{ID: 1, Branch: "main", Event: "push", Status: "success"},
+			},
+			args: []string{"ls", "--event", "push", "repo/name"},
+			expected: []*woodpecker.Pipeline{
+				{ID: 1, Branch: "main", Event: "push", Status: "success"},
+			},
+		},
+		{
+			name:    "invalid event filter",
+			repoID:  1,
+			args:    []string{"ls", "--event", "commit", "repo/name"},
+			wantErr: errors.New("invalid event 'commit', must be one of: push, pull_request, pull_request_closed, pull_request_metadata, tag, release, deployment, cron, manual"),
+		},
 		{
 			name:        "pipeline list error",
 			repoID:      1,
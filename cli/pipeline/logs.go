@@ -0,0 +1,152 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/template"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+func buildPipelineLogsCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "logs",
+		Usage:     "show the logs of a pipeline",
+		ArgsUsage: "<repo-id|repo-full-name> [pipeline]",
+		Action:    pipelineLogs,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "step",
+				Usage: "only show the logs of the step with the given number or name",
+			},
+			&cli.BoolFlag{
+				Name:    "follow",
+				Aliases: []string{"f"},
+				Usage:   "stream the logs of running steps until they are done",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "output format ('raw' prints the log lines only, without step headers)",
+			},
+		},
+	}
+}
+
+func pipelineLogs(ctx context.Context, c *cli.Command) error {
+	repoIDOrFullName := c.Args().First()
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+	repoID, err := internal.ParseRepo(client, repoIDOrFullName)
+	if err != nil {
+		return fmt.Errorf("invalid repo '%s': %w", repoIDOrFullName, err)
+	}
+
+	number, err := parsePipelineNumber(client, repoID, c.Args().Get(1), "")
+	if err != nil {
+		return err
+	}
+
+	return showPipelineLogs(ctx, c, client, repoID, number, os.Stdout)
+}
+
+// showPipelineLogs prints the logs of the selected steps of a pipeline one after another.
+func showPipelineLogs(ctx context.Context, c *cli.Command, client woodpecker.Client, repoID, number int64, out io.Writer) error {
+	raw := false
+	switch c.String("output") {
+	case "":
+	case "raw":
+		raw = true
+	default:
+		return fmt.Errorf("invalid output format '%s'", c.String("output"))
+	}
+
+	pipeline, err := client.Pipeline(repoID, number)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New("_").Parse(tmplPipelineLogsHeader + "\n")
+	if err != nil {
+		return err
+	}
+
+	stepArg := c.String("step")
+	found := false
+	for _, workflow := range pipeline.Workflows {
+		for _, step := range workflow.Children {
+			if stepArg != "" && stepArg != strconv.Itoa(step.PID) && stepArg != step.Name {
+				continue
+			}
+			found = true
+
+			if !raw {
+				if err := tmpl.Execute(out, map[string]any{"workflow": workflow, "step": step}); err != nil {
+					return err
+				}
+			}
+			if err := printStepLogs(ctx, c, client, repoID, number, step, out); err != nil {
+				return err
+			}
+		}
+	}
+
+	if stepArg != "" && !found {
+		return fmt.Errorf("no step with number or name '%s' found", stepArg)
+	}
+	return nil
+}
+
+// printStepLogs prints the logs of a step, with --follow the logs of running steps are streamed until the step is done.
+func printStepLogs(ctx context.Context, c *cli.Command, client woodpecker.Client, repoID, number int64, step *woodpecker.Step, out io.Writer) error {
+	write := func(entry *woodpecker.LogEntry) error {
+		_, err := fmt.Fprintln(out, string(entry.Data))
+		return err
+	}
+
+	if c.Bool("follow") && (step.State == woodpecker.StatusPending || step.State == woodpecker.StatusRunning) {
+		streamed := false
+		err := client.StepLogStream(ctx, repoID, number, step.ID, func(entry *woodpecker.LogEntry) error {
+			streamed = true
+			return write(entry)
+		})
+		// the step may have finished before the stream was opened, then its logs are only stored
+		if err != nil || streamed {
+			return err
+		}
+	}
+
+	entries, err := client.StepLogEntries(repoID, number, step.ID)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := write(entry); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var tmplPipelineLogsHeader = "\x1b[33m{{ .workflow.Name }} > {{ .step.Name }} (#{{ .step.PID }}):\x1b[0m"
@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func logsPipeline() *woodpecker.Pipeline {
+	return &woodpecker.Pipeline{Number: 1, Workflows: []*woodpecker.Workflow{{
+		Name: "build",
+		Children: []*woodpecker.Step{
+			{ID: 10, PID: 1, Name: "clone", State: woodpecker.StatusSuccess},
+			{ID: 11, PID: 2, Name: "test", State: woodpecker.StatusRunning},
+		},
+	}}}
+}
+
+func logEntries(lines ...string) []*woodpecker.LogEntry {
+	entries := make([]*woodpecker.LogEntry, 0, len(lines))
+	for i, line := range lines {
+		entries = append(entries, &woodpecker.LogEntry{Line: i, Data: []byte(line)})
+	}
+	return entries
+}
+
+func TestPipelineLogs(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		stream  []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "all steps",
+			args: []string{"logs"},
+			want: "\x1b[33mbuild > clone (#1):\x1b[0m\ncloning\n\x1b[33mbuild > test (#2):\x1b[0m\ngo test\nok\n",
+		},
+		{
+			name: "step by number raw",
+			args: []string{"logs", "--step", "2", "--output", "raw"},
+			want: "go test\nok\n",
+		},
+		{
+			name:   "follow running step",
+			args:   []string{"logs", "--step", "test", "--output", "raw", "--follow"},
+			stream: []string{"go test", "ok", "PASS"},
+			want:   "go test\nok\nPASS\n",
+		},
+		{
+			name:   "follow step finished before the stream was opened",
+			args:   []string{"logs", "--step", "test", "--output", "raw", "--follow"},
+			stream: []string{},
+			want:   "go test\nok\n",
+		},
+		{
+			name:    "unknown step",
+			args:    []string{"logs", "--step", "deploy"},
+			wantErr: "no step with number or name 'deploy' found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			mockClient.On("Pipeline", int64(1), int64(1)).Return(logsPipeline(), nil)
+			mockClient.On("StepLogEntries", int64(1), int64(1), int64(10)).Return(logEntries("cloning"), nil).Maybe()
+			mockClient.On("StepLogEntries", int64(1), int64(1), int64(11)).Return(logEntries("go test", "ok"), nil).Maybe()
+			if tt.stream != nil {
+				mockClient.On("StepLogStream", mock.Anything, int64(1), int64(1), int64(11), mock.Anything).
+					Return(func(_ context.Context, _, _, _ int64, fn func(*woodpecker.LogEntry) error) error {
+						for _, entry := range logEntries(tt.stream...) {
+							if err := fn(entry); err != nil {
+								return err
+							}
+						}
+						return nil
+					})
+			}
+
+			var out bytes.Buffer
+			command := &cli.Command{
+				Flags: buildPipelineLogsCmd().Flags,
+				Action: func(ctx context.Context, c *cli.Command) error {
+					return showPipelineLogs(ctx, c, mockClient, 1, 1, &out)
+				},
+			}
+			err := command.Run(t.Context(), tt.args)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
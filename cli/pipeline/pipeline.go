@@ -18,10 +18,13 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"slices"
+	"strings"
 	"text/template"
 
 	"github.com/urfave/cli/v3"
 
+	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/output"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/pipeline/deploy"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/pipeline/log"
@@ -39,10 +42,12 @@ var Command = &cli.Command{
 		deploy.Command,
 		pipelineKillCmd,
 		pipelineLastCmd,
+		buildPipelineLogsCmd(),
 		buildPipelineListCmd(),
 		log.Command,
 		pipelinePsCmd,
 		pipelinePurgeCmd,
+		buildPipelineQueryCmd(),
 		pipelineQueueCmd,
 		pipelineShowCmd,
 		pipelineStartCmd,
@@ -50,6 +55,27 @@ var Command = &cli.Command{
 	},
 }
 
+// pipelineEvents are the events pipelines can be filtered by.
+var pipelineEvents = []string{
+	woodpecker.EventPush,
+	woodpecker.EventPull,
+	woodpecker.EventPullClosed,
+	woodpecker.EventPullMetadata,
+	woodpecker.EventTag,
+	woodpecker.EventRelease,
+	woodpecker.EventDeploy,
+	woodpecker.EventCron,
+	woodpecker.EventManual,
+}
+
+// validateEvent returns an error if the event filter is set to an unknown event.
+func validateEvent(event string) error {
+	if event == "" || slices.Contains(pipelineEvents, event) {
+		return nil
+	}
+	return fmt.Errorf("invalid event '%s', must be one of: %s", event, strings.Join(pipelineEvents, ", "))
+}
+
 func pipelineOutput(c *cli.Command, pipelines []*woodpecker.Pipeline, fd ...io.Writer) error {
 	outFmt, outOpt := output.ParseOutputOptions(c.String("output"))
 	noHeader := c.Bool("output-no-headers")
@@ -61,12 +87,14 @@ func pipelineOutput(c *cli.Command, pipelines []*woodpecker.Pipeline, fd ...io.W
 	}
 
 	switch outFmt {
+	case "json", "yaml":
+		return output.WriteStructured(out, outFmt, pipelines)
 	case "go-template":
 		if len(outOpt) < 1 {
 			return fmt.Errorf("%w: missing template", output.ErrOutputOptionRequired)
 		}
 
-		tmpl, err := template.New("_").Parse(outOpt[0] + "\n")
+		tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(outOpt[0] + "\n")
 		if err != nil {
 			return err
 		}
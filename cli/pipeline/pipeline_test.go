@@ -3,10 +3,12 @@ package pipeline
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"io"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/urfave/cli/v3"
 
 	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
@@ -84,3 +86,23 @@ func TestPipelineOutput(t *testing.T) {
 		})
 	}
 }
+
+func TestPipelineOutputJSON(t *testing.T) {
+	pipelines := []*woodpecker.Pipeline{{Number: 1, Status: "success", Event: "push", Branch: "main"}}
+
+	command := &cli.Command{
+		Writer: io.Discard,
+		Name:   "output",
+		Flags:  common.OutputFlags("table"),
+		Action: func(_ context.Context, c *cli.Command) error {
+			var buf bytes.Buffer
+			require.NoError(t, pipelineOutput(c, pipelines, &buf))
+
+			var decoded []*woodpecker.Pipeline
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+			assert.Equal(t, pipelines, decoded)
+			return nil
+		},
+	}
+	require.NoError(t, command.Run(t.Context(), []string{"output", "--output", "json"}))
+}
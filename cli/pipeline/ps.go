@@ -17,14 +17,20 @@ package pipeline
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"path"
+	"slices"
 	"strconv"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/urfave/cli/v3"
 
 	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/output"
 	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
 )
 
@@ -33,7 +39,48 @@ var pipelinePsCmd = &cli.Command{
 	Usage:     "show pipeline steps",
 	ArgsUsage: "<repo-id|repo-full-name> <pipeline>",
 	Action:    pipelinePs,
-	Flags:     []cli.Flag{common.FormatFlag(tmplPipelinePs, false)},
+	Flags: append(common.OutputFlags(""), []cli.Flag{
+		common.FormatFlag(tmplPipelinePs, false),
+		&cli.BoolFlag{
+			Name:  "watch",
+			Usage: "refresh the steps until all are done, fails if a step failed",
+		},
+		&cli.DurationFlag{
+			Name:  "interval",
+			Usage: "refresh interval of --watch",
+			Value: 2 * time.Second,
+		},
+		&cli.StringSliceFlag{
+			Name:  "state",
+			Usage: "only show steps with the given state (e.g. failure), can be repeated",
+		},
+		&cli.StringFlag{
+			Name:  "event",
+			Usage: "select the last pipeline with this event (push, pull_request, tag, cron, ...) of any branch instead of the last pipeline of the default branch",
+		},
+		&cli.StringFlag{
+			Name:  "step",
+			Usage: "only show steps with a name matching the glob pattern (e.g. 'test-*')",
+		},
+		&cli.BoolFlag{
+			Name:  "summary",
+			Usage: "show one line per workflow with its state, duration and number of steps per state",
+		},
+		&cli.BoolFlag{
+			Name:    "quiet",
+			Aliases: []string{"q"},
+			Usage:   "do not print the steps, only fail if a step failed",
+		},
+		&cli.BoolFlag{
+			Name:  "show-failed-logs",
+			Usage: "print the last log lines of failed steps beneath their status",
+		},
+		&cli.IntFlag{
+			Name:  "tail",
+			Usage: "number of log lines shown by --show-failed-logs, 0 shows the whole log",
+			Value: 20,
+		},
+	}...),
 }
 
 func pipelinePs(ctx context.Context, c *cli.Command) error {
@@ -47,45 +94,360 @@ func pipelinePs(ctx context.Context, c *cli.Command) error {
 		return fmt.Errorf("invalid repo '%s': %w", repoIDOrFullName, err)
 	}
 
-	pipelineArg := c.Args().Get(1)
-	var number int64
+	if _, err := stepFilter(c); err != nil {
+		return err
+	}
+	if c.Int("tail") < 0 {
+		return fmt.Errorf("tail must not be negative")
+	}
+	event := c.String("event")
+	if err := validateEvent(event); err != nil {
+		return err
+	}
+
+	number, err := parsePipelineNumber(client, repoID, c.Args().Get(1), event)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("watch") {
+		return watchPipelineSteps(ctx, c, client, repoID, number, os.Stdout)
+	}
+	return showPipelineSteps(c, client, repoID, number, os.Stdout)
+}
+
+// parsePipelineNumber returns the number of the pipeline argument, "last" or no argument select the last pipeline
+// of the default branch. If an event is given, the last pipeline with this event of any branch is selected instead,
+// as tags and pull requests are not bound to the default branch.
+func parsePipelineNumber(client woodpecker.Client, repoID int64, pipelineArg, event string) (int64, error) {
+	if (pipelineArg == "last" || len(pipelineArg) == 0) && event != "" {
+		pipelines, err := client.PipelineList(repoID, woodpecker.PipelineListOptions{
+			ListOptions: woodpecker.ListOptions{PerPage: 1},
+			Events:      []string{event},
+		})
+		if err != nil {
+			return 0, err
+		}
+		if len(pipelines) == 0 {
+			return 0, fmt.Errorf("no %s pipeline found", event)
+		}
+		return pipelines[0].Number, nil
+	}
 
 	if pipelineArg == "last" || len(pipelineArg) == 0 {
 		// Fetch the pipeline number from the last pipeline
 		pipeline, err := client.PipelineLast(repoID, woodpecker.PipelineLastOptions{})
 		if err != nil {
-			return err
+			return 0, err
 		}
+		return pipeline.Number, nil
+	}
 
-		number = pipeline.Number
-	} else {
-		number, err = strconv.ParseInt(pipelineArg, 10, 64)
-		if err != nil {
-			return fmt.Errorf("invalid pipeline '%s': %w", pipelineArg, err)
-		}
+	number, err := strconv.ParseInt(pipelineArg, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pipeline '%s': %w", pipelineArg, err)
 	}
+	return number, nil
+}
 
+// showPipelineSteps prints the steps of a pipeline once and fails if a step failed.
+func showPipelineSteps(c *cli.Command, client woodpecker.Client, repoID, number int64, out io.Writer) error {
 	pipeline, err := client.Pipeline(repoID, number)
 	if err != nil {
 		return err
 	}
 
-	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	if !c.Bool("quiet") {
+		if err := printPipelineSteps(c, client, repoID, pipeline, out); err != nil {
+			return err
+		}
+	}
+
+	if len(pipeline.Workflows) == 0 {
+		_, err := pipelineDone(pipeline)
+		return err
+	}
+	match, err := stepFilter(c)
+	if err != nil {
+		return err
+	}
+	if _, failed := pipelineStepsDone(pipeline, match); len(failed) > 0 {
+		return fmt.Errorf("pipeline steps failed: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+func printPipelineSteps(c *cli.Command, client woodpecker.Client, repoID int64, pipeline *woodpecker.Pipeline, out io.Writer) error {
+	match, err := stepFilter(c)
+	if err != nil {
+		return err
+	}
+
+	if c.Bool("summary") {
+		return printWorkflowSummaries(c, summarizeWorkflows(pipeline, match, time.Now()), out)
+	}
+
+	structured, format, err := psOutput(c, c.String("format"))
+	if err != nil {
+		return err
+	}
+	if structured != "" {
+		steps := []*woodpecker.Step{}
+		for _, workflow := range pipeline.Workflows {
+			for _, step := range workflow.Children {
+				if match(step) {
+					steps = append(steps, step)
+				}
+			}
+		}
+		return output.WriteStructured(out, structured, steps)
+	}
+
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(format + "\n")
 	if err != nil {
 		return err
 	}
 
 	for _, workflow := range pipeline.Workflows {
 		for _, step := range workflow.Children {
-			if err := tmpl.Execute(os.Stdout, map[string]any{"workflow": workflow, "step": step}); err != nil {
+			if !match(step) {
+				continue
+			}
+			if err := tmpl.Execute(out, map[string]any{"workflow": workflow, "step": step}); err != nil {
 				return err
 			}
+			if c.Bool("show-failed-logs") && (step.State == woodpecker.StatusFailure || step.State == woodpecker.StatusError) {
+				if err := printStepLogTail(client, repoID, pipeline.Number, step, c.Int("tail"), out); err != nil {
+					return err
+				}
+			}
 		}
 	}
 
 	return nil
 }
 
+// printStepLogTail prints the last lines of the log of a step indented beneath its status, all lines if lines is 0.
+// A log which can not be fetched is reported in place of the lines, so the remaining steps are still shown.
+func printStepLogTail(client woodpecker.Client, repoID, number int64, step *woodpecker.Step, lines int, out io.Writer) error {
+	entries, err := client.StepLogEntries(repoID, number, step.ID)
+	if err != nil {
+		_, err = fmt.Fprintf(out, "    could not fetch logs: %v\n", err)
+		return err
+	}
+
+	if lines > 0 && len(entries) > lines {
+		entries = entries[len(entries)-lines:]
+	}
+	for _, entry := range entries {
+		if _, err := fmt.Fprintf(out, "    %s\n", entry.Data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workflowSummary is the rollup of a workflow shown by --summary.
+type workflowSummary struct {
+	Name  string `json:"name"`
+	State string `json:"state"`
+	// Duration is the runtime of the workflow in seconds.
+	Duration int64 `json:"duration"`
+	// Steps are the number of selected steps by state.
+	Steps map[string]int `json:"steps"`
+}
+
+// Elapsed returns the runtime of the workflow.
+func (s workflowSummary) Elapsed() time.Duration {
+	return time.Duration(s.Duration) * time.Second
+}
+
+// summarizeWorkflows returns the summary of each workflow, counting only the matching steps.
+// Running workflows are measured until now.
+func summarizeWorkflows(pipeline *woodpecker.Pipeline, match func(*woodpecker.Step) bool, now time.Time) []workflowSummary {
+	summaries := make([]workflowSummary, 0, len(pipeline.Workflows))
+	for _, workflow := range pipeline.Workflows {
+		summary := workflowSummary{
+			Name:  workflow.Name,
+			State: workflow.State,
+			Steps: map[string]int{},
+		}
+		if workflow.Started > 0 {
+			stopped := workflow.Stopped
+			if stopped == 0 {
+				stopped = now.Unix()
+			}
+			summary.Duration = max(stopped-workflow.Started, 0)
+		}
+		for _, step := range workflow.Children {
+			if match(step) {
+				summary.Steps[step.State]++
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func printWorkflowSummaries(c *cli.Command, summaries []workflowSummary, out io.Writer) error {
+	format := tmplPipelinePsSummary
+	if c.IsSet("format") {
+		format = c.String("format")
+	}
+	structured, format, err := psOutput(c, format)
+	if err != nil {
+		return err
+	}
+	if structured != "" {
+		return output.WriteStructured(out, structured, summaries)
+	}
+
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(format + "\n")
+	if err != nil {
+		return err
+	}
+	for _, summary := range summaries {
+		if err := tmpl.Execute(out, summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// psOutput returns the json or yaml format selected by --output, or otherwise the template to print with.
+// A go-template given by --output replaces the template of --format.
+func psOutput(c *cli.Command, format string) (structured, tmpl string, err error) {
+	outFmt, outOpt := output.ParseOutputOptions(c.String("output"))
+	switch {
+	case outFmt == "":
+		return "", format, nil
+	case output.IsStructured(outFmt):
+		return outFmt, "", nil
+	case outFmt == "go-template":
+		if len(outOpt) < 1 {
+			return "", "", fmt.Errorf("%w: missing template", output.ErrOutputOptionRequired)
+		}
+		return "", outOpt[0], nil
+	default:
+		return "", "", fmt.Errorf("unsupported output format '%s'", outFmt)
+	}
+}
+
+// stepFilter returns a function matching the steps selected by the --state and --step flags.
+func stepFilter(c *cli.Command) (func(*woodpecker.Step) bool, error) {
+	states := c.StringSlice("state")
+	for _, state := range states {
+		if !slices.Contains(stepStates, state) {
+			return nil, fmt.Errorf("invalid step state '%s', must be one of: %s", state, strings.Join(stepStates, ", "))
+		}
+	}
+
+	pattern := c.String("step")
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid step pattern '%s': %w", pattern, err)
+	}
+
+	return func(step *woodpecker.Step) bool {
+		if len(states) > 0 && !slices.Contains(states, step.State) {
+			return false
+		}
+		if pattern != "" {
+			matched, _ := path.Match(pattern, step.Name)
+			return matched
+		}
+		return true
+	}, nil
+}
+
+var stepStates = []string{
+	woodpecker.StatusBlocked,
+	woodpecker.StatusSkipped,
+	woodpecker.StatusPending,
+	woodpecker.StatusRunning,
+	woodpecker.StatusSuccess,
+	woodpecker.StatusFailure,
+	woodpecker.StatusKilled,
+	woodpecker.StatusError,
+	woodpecker.StatusDeclined,
+	woodpecker.StatusCreated,
+}
+
+// watchPipelineSteps redraws the steps of a pipeline until all steps are done or the context is canceled,
+// which is reported as error as the result of the pipeline is unknown.
+func watchPipelineSteps(ctx context.Context, c *cli.Command, client woodpecker.Client, repoID, number int64, out io.Writer) error {
+	for {
+		pipeline, err := client.Pipeline(repoID, number)
+		if err != nil {
+			return err
+		}
+
+		if !c.Bool("quiet") {
+			if outFmt, _ := output.ParseOutputOptions(c.String("output")); !output.IsStructured(outFmt) {
+				// move the cursor home and clear the screen
+				fmt.Fprint(out, "\x1b[H\x1b[2J")
+			}
+			if err := printPipelineSteps(c, client, repoID, pipeline, out); err != nil {
+				return err
+			}
+		}
+
+		match, err := stepFilter(c)
+		if err != nil {
+			return err
+		}
+		if len(pipeline.Workflows) == 0 {
+			// the workflows are only created once the pipeline got queued
+			if done, err := pipelineDone(pipeline); done {
+				return err
+			}
+		} else if done, failed := pipelineStepsDone(pipeline, match); done {
+			if len(failed) > 0 {
+				return fmt.Errorf("pipeline steps failed: %s", strings.Join(failed, ", "))
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.Duration("interval")):
+		}
+	}
+}
+
+// pipelineStepsDone returns whether all matching steps reached a final state and the names of the failed steps.
+func pipelineStepsDone(pipeline *woodpecker.Pipeline, match func(*woodpecker.Step) bool) (done bool, failed []string) {
+	done = true
+	for _, workflow := range pipeline.Workflows {
+		for _, step := range workflow.Children {
+			if !match(step) {
+				continue
+			}
+			switch step.State {
+			case woodpecker.StatusSuccess, woodpecker.StatusSkipped:
+			case woodpecker.StatusFailure, woodpecker.StatusKilled, woodpecker.StatusError:
+				failed = append(failed, workflow.Name+" > "+step.Name)
+			default:
+				done = false
+			}
+		}
+	}
+	return done, failed
+}
+
+// pipelineDone returns whether a pipeline without workflows reached a final state
+// and an error if it did not succeed, e.g. as its config could not be parsed.
+func pipelineDone(pipeline *woodpecker.Pipeline) (bool, error) {
+	switch pipeline.Status {
+	case woodpecker.StatusSuccess, woodpecker.StatusSkipped:
+		return true, nil
+	case woodpecker.StatusFailure, woodpecker.StatusKilled, woodpecker.StatusError, woodpecker.StatusDeclined:
+		return true, fmt.Errorf("pipeline %s without workflows", pipeline.Status)
+	default:
+		return false, nil
+	}
+}
+
 // template for pipeline ps information.
 var tmplPipelinePs = "\x1b[33m{{ .workflow.Name }} > {{ .step.Name }} (#{{ .step.PID }}):\x1b[0m" + `
 Step: {{ .step.Name }}
@@ -93,4 +455,8 @@ Started: {{ .step.Started }}
 Stopped: {{ .step.Stopped }}
 Type: {{ .step.Type }}
 State: {{ .step.State }}
+Exit Code: {{ .step.ExitCode }}
 `
+
+// template for the workflow summary of pipeline ps.
+var tmplPipelinePsSummary = "\x1b[33m{{ .Name }}\x1b[0m {{ .State }} ({{ .Elapsed }}){{ range $state, $count := .Steps }} {{ $state }}={{ $count }}{{ end }}"
@@ -0,0 +1,566 @@
+package pipeline
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func pipelineWithStates(states ...string) *woodpecker.Pipeline {
+	workflow := &woodpecker.Workflow{Name: "build"}
+	for i, state := range states {
+		workflow.Children = append(workflow.Children, &woodpecker.Step{PID: i + 1, Name: state, State: state})
+	}
+	return &woodpecker.Pipeline{Number: 1, Workflows: []*woodpecker.Workflow{workflow}}
+}
+
+func allSteps(*woodpecker.Step) bool { return true }
+
+func TestPipelineStepsDone(t *testing.T) {
+	done, failed := pipelineStepsDone(pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusRunning), allSteps)
+	assert.False(t, done)
+	assert.Empty(t, failed)
+
+	done, failed = pipelineStepsDone(pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusSkipped), allSteps)
+	assert.True(t, done)
+	assert.Empty(t, failed)
+
+	done, failed = pipelineStepsDone(pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusFailure, woodpecker.StatusKilled), allSteps)
+	assert.True(t, done)
+	assert.Equal(t, []string{"build > failure", "build > killed"}, failed)
+
+	done, failed = pipelineStepsDone(pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusFailure, woodpecker.StatusRunning), func(step *woodpecker.Step) bool {
+		return step.State != woodpecker.StatusRunning
+	})
+	assert.True(t, done, "steps which are not selected are ignored")
+	assert.Equal(t, []string{"build > failure"}, failed)
+}
+
+func TestPipelinePsWatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		pipelines []*woodpecker.Pipeline
+		wantErr   string
+	}{
+		{
+			name: "success",
+			pipelines: []*woodpecker.Pipeline{
+				pipelineWithStates(woodpecker.StatusRunning, woodpecker.StatusPending),
+				pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusRunning),
+				pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusSuccess),
+			},
+		},
+		{
+			name: "failure",
+			pipelines: []*woodpecker.Pipeline{
+				pipelineWithStates(woodpecker.StatusRunning),
+				pipelineWithStates(woodpecker.StatusFailure),
+			},
+			wantErr: "pipeline steps failed: build > failure",
+		},
+		{
+			name: "no workflows yet",
+			pipelines: []*woodpecker.Pipeline{
+				{Number: 1, Status: woodpecker.StatusPending},
+				pipelineWithStates(woodpecker.StatusSuccess),
+			},
+		},
+		{
+			name: "error without workflows",
+			pipelines: []*woodpecker.Pipeline{
+				{Number: 1, Status: woodpecker.StatusPending},
+				{Number: 1, Status: woodpecker.StatusError},
+			},
+			wantErr: "pipeline error without workflows",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			for _, pipeline := range tt.pipelines {
+				mockClient.On("Pipeline", int64(1), int64(1)).Return(pipeline, nil).Once()
+			}
+
+			var out bytes.Buffer
+			command := pipelinePsCmd
+			command.Writer = io.Discard
+			command.Action = func(ctx context.Context, c *cli.Command) error {
+				err := watchPipelineSteps(ctx, c, mockClient, 1, 1, &out)
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+					return nil
+				}
+				assert.NoError(t, err)
+				return nil
+			}
+
+			_ = command.Run(t.Context(), []string{"ps", "--watch", "--interval", "1ms", "--format", "{{ .step.Name }}={{ .step.State }}", "repo/name", "1"})
+			assert.Contains(t, out.String(), "\x1b[H\x1b[2J")
+		})
+	}
+}
+
+func TestPipelinePsFilter(t *testing.T) {
+	pipeline := &woodpecker.Pipeline{Number: 1, Workflows: []*woodpecker.Workflow{
+		{Name: "build", Children: []*woodpecker.Step{
+			{PID: 1, Name: "clone", State: woodpecker.StatusSuccess},
+			{PID: 2, Name: "test-unit", State: woodpecker.StatusFailure},
+			{PID: 3, Name: "test-e2e", State: woodpecker.StatusSuccess},
+			{PID: 4, Name: "lint", State: woodpecker.StatusKilled},
+		}},
+	}}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "no filter",
+			want: "clone=success\ntest-unit=failure\ntest-e2e=success\nlint=killed\n",
+		},
+		{
+			name: "state",
+			args: []string{"--state", "failure", "--state", "killed"},
+			want: "test-unit=failure\nlint=killed\n",
+		},
+		{
+			name: "step",
+			args: []string{"--step", "test-*"},
+			want: "test-unit=failure\ntest-e2e=success\n",
+		},
+		{
+			name: "state and step",
+			args: []string{"--state", "success", "--step", "test-*"},
+			want: "test-e2e=success\n",
+		},
+		{
+			name: "nothing matches",
+			args: []string{"--state", "running"},
+			want: "",
+		},
+		{
+			name: "declined and created",
+			args: []string{"--state", "declined", "--state", "created"},
+			want: "",
+		},
+		{
+			name:    "invalid state",
+			args:    []string{"--state", "failed"},
+			wantErr: "invalid step state 'failed', must be one of: blocked, skipped, pending, running, success, failure, killed, error, declined, created",
+		},
+		{
+			name:    "invalid pattern",
+			args:    []string{"--step", "["},
+			wantErr: "invalid step pattern '[': syntax error in pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			command := pipelinePsCmd
+			command.Writer = io.Discard
+			command.Action = func(_ context.Context, c *cli.Command) error {
+				return printPipelineSteps(c, nil, 1, pipeline, &out)
+			}
+
+			args := append([]string{"ps", "--format", "{{ .step.Name }}={{ .step.State }}"}, tt.args...)
+			err := command.Run(t.Context(), append(args, "repo/name", "1"))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestPipelinePsExitCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		pipeline *woodpecker.Pipeline
+		args     []string
+		want     string
+		wantErr  string
+	}{
+		{
+			name:     "success",
+			pipeline: pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusSkipped),
+			want:     "success=0\nskipped=0\n",
+		},
+		{
+			name:     "failure",
+			pipeline: pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusFailure, woodpecker.StatusError),
+			want:     "success=0\nfailure=1\nerror=1\n",
+			wantErr:  "pipeline steps failed: build > failure, build > error",
+		},
+		{
+			name:     "quiet",
+			pipeline: pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusFailure),
+			args:     []string{"--quiet"},
+			wantErr:  "pipeline steps failed: build > failure",
+		},
+		{
+			name:     "failed steps filtered out",
+			pipeline: pipelineWithStates(woodpecker.StatusSuccess, woodpecker.StatusFailure),
+			args:     []string{"--state", "success"},
+			want:     "success=0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, step := range tt.pipeline.Workflows[0].Children {
+				if step.State == woodpecker.StatusFailure || step.State == woodpecker.StatusError {
+					step.ExitCode = 1
+				}
+			}
+			mockClient := mocks.NewMockClient(t)
+			mockClient.On("Pipeline", int64(1), int64(1)).Return(tt.pipeline, nil).Once()
+
+			var out bytes.Buffer
+			var err error
+			command := pipelinePsCmd
+			command.Writer = io.Discard
+			command.Action = func(_ context.Context, c *cli.Command) error {
+				err = showPipelineSteps(c, mockClient, 1, 1, &out)
+				return nil
+			}
+
+			args := append([]string{"ps", "--format", "{{ .step.Name }}={{ .step.ExitCode }}"}, tt.args...)
+			assert.NoError(t, command.Run(t.Context(), append(args, "repo/name", "1")))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestPipelinePsSummary(t *testing.T) {
+	pipeline := &woodpecker.Pipeline{Number: 1, Workflows: []*woodpecker.Workflow{
+		{Name: "build", State: woodpecker.StatusFailure, Started: 100, Stopped: 190, Children: []*woodpecker.Step{
+			{PID: 1, Name: "clone", State: woodpecker.StatusSuccess},
+			{PID: 2, Name: "test", State: woodpecker.StatusFailure},
+			{PID: 3, Name: "lint", State: woodpecker.StatusSuccess},
+		}},
+		{Name: "deploy", State: woodpecker.StatusRunning, Started: 150, Children: []*woodpecker.Step{
+			{PID: 4, Name: "clone", State: woodpecker.StatusSuccess},
+			{PID: 5, Name: "deploy", State: woodpecker.StatusRunning},
+		}},
+		{Name: "docs", State: woodpecker.StatusPending, Children: []*woodpecker.Step{
+			{PID: 6, Name: "clone", State: woodpecker.StatusPending},
+		}},
+	}}
+
+	t.Run("rollup", func(t *testing.T) {
+		summaries := summarizeWorkflows(pipeline, allSteps, time.Unix(200, 0))
+		assert.Equal(t, []workflowSummary{
+			{Name: "build", State: woodpecker.StatusFailure, Duration: 90, Steps: map[string]int{woodpecker.StatusSuccess: 2, woodpecker.StatusFailure: 1}},
+			{Name: "deploy", State: woodpecker.StatusRunning, Duration: 50, Steps: map[string]int{woodpecker.StatusSuccess: 1, woodpecker.StatusRunning: 1}},
+			{Name: "docs", State: woodpecker.StatusPending, Steps: map[string]int{woodpecker.StatusPending: 1}},
+		}, summaries)
+	})
+
+	t.Run("filtered steps", func(t *testing.T) {
+		summaries := summarizeWorkflows(pipeline, func(step *woodpecker.Step) bool { return step.Name != "clone" }, time.Unix(200, 0))
+		assert.Equal(t, map[string]int{woodpecker.StatusSuccess: 1, woodpecker.StatusFailure: 1}, summaries[0].Steps)
+		assert.Empty(t, summaries[2].Steps)
+	})
+
+	finished := &woodpecker.Pipeline{Number: 1, Workflows: pipeline.Workflows[:1]}
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "text",
+			want: "\x1b[33mbuild\x1b[0m failure (1m30s) failure=1 success=2\n",
+		},
+		{
+			name: "json",
+			args: []string{"--output", "json"},
+			want: `[
+  {
+    "name": "build",
+    "state": "failure",
+    "duration": 90,
+    "steps": {
+      "failure": 1,
+      "success": 2
+    }
+  }
+]
+`,
+		},
+		{
+			name: "yaml",
+			args: []string{"--output", "yaml"},
+			want: `- duration: 90
+  name: build
+  state: failure
+  steps:
+    failure: 1
+    success: 2
+`,
+		},
+		{
+			name: "go-template output",
+			args: []string{"--output", "go-template={{ .Name }}"},
+			want: "build\n",
+		},
+		{
+			name: "custom format",
+			args: []string{"--format", "{{ .Name }}={{ .State }}"},
+			want: "build=failure\n",
+		},
+		{
+			name: "custom format with funcs",
+			args: []string{"--format", "{{ truncate .Name 3 }} {{ .State | color }}"},
+			want: "bu… failure\n",
+		},
+	}
+
+	t.Run("unsupported output", func(t *testing.T) {
+		command := &cli.Command{
+			Name:   "ps",
+			Writer: io.Discard,
+			Flags:  append(common.OutputFlags(""), &cli.BoolFlag{Name: "summary"}),
+			Action: func(_ context.Context, c *cli.Command) error {
+				return printPipelineSteps(c, nil, 1, finished, io.Discard)
+			},
+		}
+		assert.EqualError(t, command.Run(t.Context(), []string{"ps", "--output", "table", "repo/name", "1"}), "unsupported output format 'table'")
+	})
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			// use fresh flags, the flags of pipelinePsCmd keep being set by the other tests
+			command := &cli.Command{
+				Name:   "ps",
+				Writer: io.Discard,
+				Flags: append(common.OutputFlags(""),
+					common.FormatFlag(tmplPipelinePs, false),
+					&cli.StringSliceFlag{Name: "state"},
+					&cli.StringFlag{Name: "step"},
+					&cli.BoolFlag{Name: "summary"},
+				),
+				Action: func(_ context.Context, c *cli.Command) error {
+					return printPipelineSteps(c, nil, 1, finished, &out)
+				},
+			}
+
+			args := append([]string{"ps", "--summary"}, tt.args...)
+			assert.NoError(t, command.Run(t.Context(), append(args, "repo/name", "1")))
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestPipelinePsShowFailedLogs(t *testing.T) {
+	pipeline := &woodpecker.Pipeline{Number: 3, Workflows: []*woodpecker.Workflow{
+		{Name: "build", Children: []*woodpecker.Step{
+			{ID: 10, PID: 1, Name: "clone", State: woodpecker.StatusSuccess},
+			{ID: 11, PID: 2, Name: "test", State: woodpecker.StatusFailure},
+			{ID: 12, PID: 3, Name: "deploy", State: woodpecker.StatusError},
+			{ID: 13, PID: 4, Name: "notify", State: woodpecker.StatusSkipped},
+		}},
+	}}
+	logs := func(lines ...string) []*woodpecker.LogEntry {
+		entries := make([]*woodpecker.LogEntry, 0, len(lines))
+		for _, line := range lines {
+			entries = append(entries, &woodpecker.LogEntry{Data: []byte(line)})
+		}
+		return entries
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{
+			name: "without flag",
+			want: "clone=success\ntest=failure\ndeploy=error\nnotify=skipped\n",
+		},
+		{
+			name: "last lines of failed steps",
+			args: []string{"--show-failed-logs", "--tail", "2"},
+			want: "clone=success\ntest=failure\n    2\n    FAIL\ndeploy=error\n    could not fetch logs: not found\nnotify=skipped\n",
+		},
+		{
+			name: "whole log",
+			args: []string{"--show-failed-logs", "--tail", "0"},
+			want: "clone=success\ntest=failure\n    1\n    2\n    FAIL\ndeploy=error\n    could not fetch logs: not found\nnotify=skipped\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			mockClient.On("Pipeline", int64(1), int64(3)).Return(pipeline, nil).Once()
+			if len(tt.args) > 0 {
+				// only the logs of the failed steps are fetched
+				mockClient.On("StepLogEntries", int64(1), int64(3), int64(11)).Return(logs("1", "2", "FAIL"), nil).Once()
+				mockClient.On("StepLogEntries", int64(1), int64(3), int64(12)).Return(nil, errors.New("not found")).Once()
+			}
+
+			var out bytes.Buffer
+			var err error
+			// use fresh flags, the flags of pipelinePsCmd keep being set by the other tests
+			command := &cli.Command{
+				Name:   "ps",
+				Writer: io.Discard,
+				Flags: []cli.Flag{
+					common.FormatFlag(tmplPipelinePs, false),
+					&cli.StringFlag{Name: "output"},
+					&cli.StringSliceFlag{Name: "state"},
+					&cli.StringFlag{Name: "step"},
+					&cli.BoolFlag{Name: "quiet"},
+					&cli.BoolFlag{Name: "show-failed-logs"},
+					&cli.IntFlag{Name: "tail", Value: 20},
+				},
+				Action: func(_ context.Context, c *cli.Command) error {
+					err = showPipelineSteps(c, mockClient, 1, 3, &out)
+					return nil
+				},
+			}
+
+			args := append([]string{"ps", "--format", "{{ .step.Name }}={{ .step.State }}"}, tt.args...)
+			assert.NoError(t, command.Run(t.Context(), append(args, "repo/name", "3")))
+			assert.EqualError(t, err, "pipeline steps failed: build > test, build > deploy")
+			assert.Equal(t, tt.want, out.String())
+		})
+	}
+}
+
+func TestPipelinePsWatchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("Pipeline", int64(1), int64(1)).Return(pipelineWithStates(woodpecker.StatusRunning), nil).Run(func(mock.Arguments) {
+		cancel()
+	}).Once()
+
+	command := pipelinePsCmd
+	command.Writer = io.Discard
+	command.Action = func(ctx context.Context, c *cli.Command) error {
+		assert.ErrorIs(t, watchPipelineSteps(ctx, c, mockClient, 1, 1, io.Discard), context.Canceled)
+		return nil
+	}
+
+	_ = command.Run(ctx, []string{"ps", "--watch", "--interval", "1h", "repo/name", "1"})
+}
+
+func TestParsePipelineNumber(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		event   string
+		setup   func(*mocks.MockClient)
+		want    int64
+		wantErr string
+	}{
+		{
+			name: "number",
+			arg:  "5",
+			want: 5,
+		},
+		{
+			name: "last of default branch",
+			arg:  "last",
+			setup: func(m *mocks.MockClient) {
+				m.On("PipelineLast", int64(1), woodpecker.PipelineLastOptions{}).Return(&woodpecker.Pipeline{Number: 9}, nil).Once()
+			},
+			want: 9,
+		},
+		{
+			name:  "last push of any branch",
+			arg:   "last",
+			event: woodpecker.EventPush,
+			setup: func(m *mocks.MockClient) {
+				m.On("PipelineList", int64(1), woodpecker.PipelineListOptions{
+					ListOptions: woodpecker.ListOptions{PerPage: 1},
+					Events:      []string{woodpecker.EventPush},
+				}).Return([]*woodpecker.Pipeline{{Number: 7, Event: woodpecker.EventPush, Branch: "feature"}}, nil).Once()
+			},
+			want: 7,
+		},
+		{
+			name:  "tag",
+			arg:   "last",
+			event: woodpecker.EventTag,
+			setup: func(m *mocks.MockClient) {
+				m.On("PipelineList", int64(1), woodpecker.PipelineListOptions{
+					ListOptions: woodpecker.ListOptions{PerPage: 1},
+					Events:      []string{woodpecker.EventTag},
+				}).Return([]*woodpecker.Pipeline{{Number: 8, Event: woodpecker.EventTag, Ref: "refs/tags/v1.0.0"}}, nil).Once()
+			},
+			want: 8,
+		},
+		{
+			name:  "no argument with event",
+			event: woodpecker.EventCron,
+			setup: func(m *mocks.MockClient) {
+				m.On("PipelineList", int64(1), mock.Anything).Return([]*woodpecker.Pipeline{{Number: 3, Event: woodpecker.EventCron}}, nil).Once()
+			},
+			want: 3,
+		},
+		{
+			name:  "no pipeline with event",
+			arg:   "last",
+			event: woodpecker.EventTag,
+			setup: func(m *mocks.MockClient) {
+				m.On("PipelineList", int64(1), mock.Anything).Return([]*woodpecker.Pipeline{}, nil).Once()
+			},
+			wantErr: "no tag pipeline found",
+		},
+		{
+			name:  "number ignores event",
+			arg:   "4",
+			event: woodpecker.EventPush,
+			want:  4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			if tt.setup != nil {
+				tt.setup(mockClient)
+			}
+
+			number, err := parsePipelineNumber(mockClient, 1, tt.arg, tt.event)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, number)
+		})
+	}
+}
+
+func TestValidateEvent(t *testing.T) {
+	assert.NoError(t, validateEvent(""))
+	assert.NoError(t, validateEvent(woodpecker.EventPull))
+	assert.EqualError(t, validateEvent("pr"), "invalid event 'pr', must be one of: push, pull_request, pull_request_closed, pull_request_metadata, tag, release, deployment, cron, manual")
+}
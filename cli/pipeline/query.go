@@ -0,0 +1,169 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	shared_utils "go.woodpecker-ci.org/woodpecker/v3/shared/utils"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+//nolint:mnd
+func buildPipelineQueryCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "query",
+		Usage:     "query pipelines across repositories",
+		ArgsUsage: " ",
+		Action:    pipelineQuery,
+		Flags: []cli.Flag{
+			common.FormatFlag(tmplPipelineQuery, false),
+			&cli.StringSliceFlag{
+				Name:  "repo",
+				Usage: "repository filter (id or full name)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "event",
+				Usage: "event filter (push, pull_request, tag, cron, ...)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "status",
+				Usage: "status filter",
+			},
+			&cli.StringSliceFlag{
+				Name:  "author",
+				Usage: "author filter",
+			},
+			&cli.TimestampFlag{
+				Name:  "before",
+				Usage: "only return pipelines created before this date (RFC3339)",
+				Config: cli.TimestampConfig{
+					Layouts: []string{
+						time.RFC3339,
+					},
+				},
+			},
+			&cli.TimestampFlag{
+				Name:  "after",
+				Usage: "only return pipelines created after this date (RFC3339)",
+				Config: cli.TimestampConfig{
+					Layouts: []string{
+						time.RFC3339,
+					},
+				},
+			},
+			&cli.DurationFlag{
+				Name:  "min-duration",
+				Usage: "only return finished pipelines running at least this long",
+			},
+			&cli.DurationFlag{
+				Name:  "max-duration",
+				Usage: "only return finished pipelines running at most this long",
+			},
+			&cli.StringFlag{
+				Name:  "sort",
+				Usage: "sort pipelines by created, started, finished or duration",
+			},
+			&cli.BoolFlag{
+				Name:  "asc",
+				Usage: "sort pipelines in ascending order",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "limit the list size",
+				Value: 25,
+			},
+		},
+	}
+}
+
+func pipelineQuery(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	pipelines, err := queryPipelines(c, client)
+	if err != nil {
+		return err
+	}
+
+	if len(pipelines) == 0 {
+		fmt.Println("there are no pipelines matching the query")
+		return nil
+	}
+
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(c.String("format") + "\n")
+	if err != nil {
+		return err
+	}
+
+	for _, pipeline := range pipelines {
+		if err := tmpl.Execute(os.Stdout, pipeline); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func queryPipelines(c *cli.Command, client woodpecker.Client) ([]*woodpecker.Feed, error) {
+	query := &woodpecker.PipelineQuery{
+		Events:      c.StringSlice("event"),
+		Statuses:    c.StringSlice("status"),
+		Authors:     c.StringSlice("author"),
+		After:       c.Timestamp("after"),
+		Before:      c.Timestamp("before"),
+		MinDuration: c.Duration("min-duration"),
+		MaxDuration: c.Duration("max-duration"),
+		SortBy:      c.String("sort"),
+		Ascending:   c.Bool("asc"),
+	}
+
+	for _, repo := range c.StringSlice("repo") {
+		repoID, err := internal.ParseRepo(client, repo)
+		if err != nil {
+			return nil, err
+		}
+		query.RepoIDs = append(query.RepoIDs, repoID)
+	}
+	for _, event := range query.Events {
+		if err := validateEvent(event); err != nil {
+			return nil, err
+		}
+	}
+
+	return shared_utils.Paginate(func(page int) ([]*woodpecker.Feed, error) {
+		return client.PipelineQuery(query, woodpecker.ListOptions{Page: page})
+	}, c.Int("limit"))
+}
+
+// Template for pipeline query information.
+var tmplPipelineQuery = "\x1b[33mRepo {{ .RepoID }} #{{ .Number }} \x1b[0m" + `
+Status: {{ .Status }}
+Event: {{ .Event }}
+Commit: {{ .Commit }}
+Branch: {{ .Branch }}
+Ref: {{ .Ref }}
+Author: {{ .Author }} {{ if .Email }}<{{.Email}}>{{ end }}
+Message: {{ .Message }}
+`
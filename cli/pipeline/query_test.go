@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestPipelineQuery(t *testing.T) {
+	feed := []*woodpecker.Feed{
+		{RepoID: 1, ID: 1, Event: "push", Status: "success"},
+		{RepoID: 2, ID: 2, Event: "push", Status: "failure"},
+	}
+	noFilter := &woodpecker.PipelineQuery{Events: []string{}, Statuses: []string{}, Authors: []string{}}
+
+	tests := []struct {
+		name     string
+		args     []string
+		query    *woodpecker.PipelineQuery
+		expected []*woodpecker.Feed
+		wantErr  error
+	}{
+		{
+			name:     "no filter",
+			args:     []string{"query"},
+			query:    noFilter,
+			expected: feed,
+		},
+		{
+			name: "filters",
+			args: []string{
+				"query", "--repo", "repo/name", "--event", "push", "--status", "success", "--status", "failure", "--author", "joe",
+				"--after", "2025-01-01T00:00:00Z", "--min-duration", "1m", "--max-duration", "1h", "--sort", "duration", "--asc",
+			},
+			query: &woodpecker.PipelineQuery{
+				RepoIDs:     []int64{1},
+				Events:      []string{"push"},
+				Statuses:    []string{"success", "failure"},
+				Authors:     []string{"joe"},
+				After:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+				MinDuration: time.Minute,
+				MaxDuration: time.Hour,
+				SortBy:      "duration",
+				Ascending:   true,
+			},
+			expected: feed,
+		},
+		{
+			name:     "limit results",
+			args:     []string{"query", "--limit", "1"},
+			query:    noFilter,
+			expected: feed[:1],
+		},
+		{
+			name:    "invalid event filter",
+			args:    []string{"query", "--event", "commit"},
+			wantErr: errors.New("invalid event 'commit', must be one of: push, pull_request, pull_request_closed, pull_request_metadata, tag, release, deployment, cron, manual"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			mockClient.On("PipelineQuery", mock.Anything, mock.Anything).Return(func(query *woodpecker.PipelineQuery, opt woodpecker.ListOptions) ([]*woodpecker.Feed, error) {
+				assert.Equal(t, tt.query, query)
+				if opt.Page == 1 {
+					return feed, nil
+				}
+				return []*woodpecker.Feed{}, nil
+			}).Maybe()
+			mockClient.On("RepoLookup", mock.Anything).Return(&woodpecker.Repo{ID: 1}, nil).Maybe()
+
+			command := buildPipelineQueryCmd()
+			command.Writer = io.Discard
+			command.Action = func(_ context.Context, c *cli.Command) error {
+				pipelines, err := queryPipelines(c, mockClient)
+				if tt.wantErr != nil {
+					assert.EqualError(t, err, tt.wantErr.Error())
+					return nil
+				}
+
+				assert.NoError(t, err)
+				assert.EqualValues(t, tt.expected, pipelines)
+
+				return nil
+			}
+
+			_ = command.Run(t.Context(), tt.args)
+		})
+	}
+}
@@ -50,7 +50,7 @@ func pipelineQueue(ctx context.Context, c *cli.Command) error {
 		return nil
 	}
 
-	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(c.String("format") + "\n")
 	if err != nil {
 		return err
 	}
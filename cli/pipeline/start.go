@@ -40,6 +40,10 @@ var pipelineStartCmd = &cli.Command{
 				TrimSpace: true,
 			},
 		},
+		&cli.BoolFlag{
+			Name:  "force",
+			Usage: "run the pipeline even if the result of an identical pipeline could be reused",
+		},
 	},
 }
 
@@ -75,6 +79,7 @@ func pipelineStart(ctx context.Context, c *cli.Command) (err error) {
 
 	opt := woodpecker.PipelineStartOptions{
 		Params: internal.ParseKeyPair(c.StringSlice("param")),
+		Force:  c.Bool("force"),
 	}
 
 	pipeline, err := client.PipelineStart(repoID, number, opt)
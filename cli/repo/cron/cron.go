@@ -15,6 +15,8 @@
 package cron
 
 import (
+	"strings"
+
 	"github.com/urfave/cli/v3"
 )
 
@@ -25,8 +27,31 @@ var Command = &cli.Command{
 	Commands: []*cli.Command{
 		cronCreateCmd,
 		cronDeleteCmd,
+		cronExportCmd,
+		cronImportCmd,
 		cronListCmd,
+		cronRunCmd,
 		cronShowCmd,
 		cronUpdateCmd,
 	},
 }
+
+var variablesFlag = &cli.StringSliceFlag{
+	Name:  "var",
+	Usage: "pipeline variable of the triggered pipelines as key=value",
+	Config: cli.StringConfig{
+		TrimSpace: true,
+	},
+}
+
+// parseVariables parses key=value pairs, the value may be empty. Entries without a key or "=" are ignored.
+func parseVariables(vars []string) map[string]string {
+	variables := make(map[string]string)
+	for _, v := range vars {
+		key, value, ok := strings.Cut(v, "=")
+		if ok && key != "" {
+			variables[key] = value
+		}
+	}
+	return variables
+}
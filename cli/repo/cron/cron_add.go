@@ -47,6 +47,7 @@ var cronCreateCmd = &cli.Command{
 			Usage:    "cron schedule",
 			Required: true,
 		},
+		variablesFlag,
 		common.FormatFlag(tmplCronList, true),
 	},
 }
@@ -74,15 +75,16 @@ func cronCreate(ctx context.Context, c *cli.Command) error {
 	}
 
 	cron := &woodpecker.Cron{
-		Name:     cronName,
-		Branch:   branch,
-		Schedule: schedule,
+		Name:      cronName,
+		Branch:    branch,
+		Schedule:  schedule,
+		Variables: parseVariables(c.StringSlice("var")),
 	}
 	cron, err = client.CronCreate(repoID, cron)
 	if err != nil {
 		return err
 	}
-	tmpl, err := template.New("_").Parse(format)
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(format)
 	if err != nil {
 		return err
 	}
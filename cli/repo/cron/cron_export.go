@@ -0,0 +1,102 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"io"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	shared_utils "go.woodpecker-ci.org/woodpecker/v3/shared/utils"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+var cronExportCmd = &cli.Command{
+	Name:      "export",
+	Usage:     "export the cron jobs as yaml",
+	ArgsUsage: "[repo-id|repo-full-name]",
+	Action:    cronExport,
+	Flags: []cli.Flag{
+		common.RepoFlag,
+	},
+}
+
+// cronDefinition is a cron job as exported and imported, crons are identified by their name.
+type cronDefinition struct {
+	Name      string            `yaml:"name"`
+	Schedule  string            `yaml:"schedule"`
+	Branch    string            `yaml:"branch,omitempty"`
+	Variables map[string]string `yaml:"variables,omitempty"`
+}
+
+func (d cronDefinition) cron() *woodpecker.Cron {
+	return &woodpecker.Cron{
+		Name:      d.Name,
+		Schedule:  d.Schedule,
+		Branch:    d.Branch,
+		Variables: d.Variables,
+	}
+}
+
+func cronExport(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+	return exportCrons(c, client, c.Root().Writer)
+}
+
+func exportCrons(c *cli.Command, client woodpecker.Client, out io.Writer) error {
+	repoIDOrFullName := c.String("repository")
+	if repoIDOrFullName == "" {
+		repoIDOrFullName = c.Args().First()
+	}
+	repoID, err := internal.ParseRepo(client, repoIDOrFullName)
+	if err != nil {
+		return err
+	}
+
+	crons, err := listCrons(client, repoID)
+	if err != nil {
+		return err
+	}
+
+	definitions := make([]cronDefinition, 0, len(crons))
+	for _, cron := range crons {
+		definitions = append(definitions, cronDefinition{
+			Name:      cron.Name,
+			Schedule:  cron.Schedule,
+			Branch:    cron.Branch,
+			Variables: cron.Variables,
+		})
+	}
+
+	enc := yaml.NewEncoder(out)
+	enc.SetIndent(2)
+	if err := enc.Encode(definitions); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func listCrons(client woodpecker.Client, repoID int64) ([]*woodpecker.Cron, error) {
+	return shared_utils.Paginate(func(page int) ([]*woodpecker.Cron, error) {
+		return client.CronList(repoID, woodpecker.CronListOptions{ListOptions: woodpecker.ListOptions{Page: page}})
+	}, -1)
+}
@@ -0,0 +1,175 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+var cronImportCmd = &cli.Command{
+	Name:      "import",
+	Usage:     "create or update the cron jobs of a yaml file",
+	ArgsUsage: "[repo-id|repo-full-name]",
+	Action:    cronImport,
+	Flags: []cli.Flag{
+		common.RepoFlag,
+		&cli.StringFlag{
+			Name:     "file",
+			Aliases:  []string{"f"},
+			Usage:    "yaml file with the cron jobs as written by export, '-' reads from stdin",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "prune",
+			Usage: "remove cron jobs which are not part of the file",
+		},
+	},
+}
+
+func cronImport(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+	return importCrons(c, client, os.Stdin, c.Root().Writer)
+}
+
+func importCrons(c *cli.Command, client woodpecker.Client, in io.Reader, out io.Writer) error {
+	repoIDOrFullName := c.String("repository")
+	if repoIDOrFullName == "" {
+		repoIDOrFullName = c.Args().First()
+	}
+
+	definitions, err := readCronDefinitions(c.String("file"), in)
+	if err != nil {
+		return err
+	}
+
+	repoID, err := internal.ParseRepo(client, repoIDOrFullName)
+	if err != nil {
+		return err
+	}
+
+	crons, err := listCrons(client, repoID)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*woodpecker.Cron, len(crons))
+	for _, cron := range crons {
+		if _, ok := existing[cron.Name]; ok {
+			return fmt.Errorf("cron name '%s' is ambiguous, remove the duplicates before importing", cron.Name)
+		}
+		existing[cron.Name] = cron
+	}
+
+	var created, updated, unchanged, removed int
+	for _, definition := range definitions {
+		cron, ok := existing[definition.Name]
+		delete(existing, definition.Name)
+
+		switch {
+		case !ok:
+			if _, err := client.CronCreate(repoID, definition.cron()); err != nil {
+				return fmt.Errorf("could not create cron '%s': %w", definition.Name, err)
+			}
+			created++
+		case cron.Schedule == definition.Schedule && cron.Branch == definition.Branch && maps.Equal(cron.Variables, definition.Variables):
+			unchanged++
+		case definition.Branch == "" && cron.Branch != "":
+			// the branch can not be reset by an update, so the cron has to be recreated
+			if err := client.CronDelete(repoID, cron.ID); err != nil {
+				return fmt.Errorf("could not update cron '%s': %w", definition.Name, err)
+			}
+			if _, err := client.CronCreate(repoID, definition.cron()); err != nil {
+				return fmt.Errorf("could not update cron '%s': %w", definition.Name, err)
+			}
+			updated++
+		default:
+			update := definition.cron()
+			update.ID = cron.ID
+			if update.Variables == nil && len(cron.Variables) != 0 {
+				// variables are only removed by an empty map
+				update.Variables = map[string]string{}
+			}
+			if _, err := client.CronUpdate(repoID, update); err != nil {
+				return fmt.Errorf("could not update cron '%s': %w", definition.Name, err)
+			}
+			updated++
+		}
+	}
+
+	if c.Bool("prune") {
+		for _, cron := range crons {
+			if _, ok := existing[cron.Name]; !ok {
+				continue
+			}
+			if err := client.CronDelete(repoID, cron.ID); err != nil {
+				return fmt.Errorf("could not remove cron '%s': %w", cron.Name, err)
+			}
+			removed++
+		}
+	}
+
+	_, err = fmt.Fprintf(out, "Created: %d, updated: %d, unchanged: %d, removed: %d\n", created, updated, unchanged, removed)
+	return err
+}
+
+func readCronDefinitions(file string, stdin io.Reader) ([]cronDefinition, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var definitions []cronDefinition
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&definitions); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("could not parse %s: %w", file, err)
+	}
+
+	names := make(map[string]bool, len(definitions))
+	for i, definition := range definitions {
+		if definition.Name == "" {
+			return nil, fmt.Errorf("cron %d has no name", i+1)
+		}
+		if definition.Schedule == "" {
+			return nil, fmt.Errorf("cron '%s' has no schedule", definition.Name)
+		}
+		if names[definition.Name] {
+			return nil, fmt.Errorf("cron '%s' is defined multiple times", definition.Name)
+		}
+		names[definition.Name] = true
+	}
+	return definitions, nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func newCronClient(t *testing.T, crons []*woodpecker.Cron) *mocks.MockClient {
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("RepoLookup", mock.Anything).Maybe().Return(&woodpecker.Repo{ID: 1}, nil)
+	mockClient.On("CronList", int64(1), mock.Anything).Maybe().Return(func(_ int64, opt woodpecker.CronListOptions) ([]*woodpecker.Cron, error) {
+		if opt.Page == 1 {
+			return crons, nil
+		}
+		return []*woodpecker.Cron{}, nil
+	})
+	return mockClient
+}
+
+func TestCronExport(t *testing.T) {
+	mockClient := newCronClient(t, []*woodpecker.Cron{
+		{ID: 1, Name: "nightly", Schedule: "@daily", Branch: "main", NextExec: 100, Variables: map[string]string{"RELEASE": "nightly"}},
+		{ID: 2, Name: "weekly", Schedule: "0 0 * * 0"},
+	})
+
+	out := &bytes.Buffer{}
+	command := cronExportCmd
+	command.Writer = io.Discard
+	command.Action = func(_ context.Context, c *cli.Command) error {
+		return exportCrons(c, mockClient, out)
+	}
+	require.NoError(t, command.Run(t.Context(), []string{"export", "repo/name"}))
+
+	assert.Equal(t, `- name: nightly
+  schedule: '@daily'
+  branch: main
+  variables:
+    RELEASE: nightly
+- name: weekly
+  schedule: 0 0 * * 0
+`, out.String())
+}
+
+func TestCronImport(t *testing.T) {
+	existing := []*woodpecker.Cron{
+		{ID: 1, Name: "nightly", Schedule: "@daily", Branch: "main"},
+		{ID: 2, Name: "weekly", Schedule: "0 0 * * 0"},
+		{ID: 3, Name: "hourly", Schedule: "@hourly", Branch: "dev"},
+		{ID: 4, Name: "old", Schedule: "@monthly"},
+	}
+	file := `- name: nightly
+  schedule: '@daily'
+  branch: main
+- name: weekly
+  schedule: 0 1 * * 0
+- name: hourly
+  schedule: '@hourly'
+- name: new
+  schedule: '@yearly'
+  branch: release
+`
+
+	tests := []struct {
+		name        string
+		args        []string
+		file        string
+		crons       []*woodpecker.Cron
+		wantCreated []string
+		wantUpdated []*woodpecker.Cron
+		wantDeleted []int64
+		wantOutput  string
+		wantErr     string
+	}{
+		{
+			name:        "import",
+			args:        []string{"import", "-f", "-", "repo/name"},
+			file:        file,
+			crons:       existing,
+			wantCreated: []string{"hourly", "new"},
+			wantUpdated: []*woodpecker.Cron{{ID: 2, Name: "weekly", Schedule: "0 1 * * 0"}},
+			wantDeleted: []int64{3},
+			wantOutput:  "Created: 1, updated: 2, unchanged: 1, removed: 0\n",
+		},
+		{
+			name:        "import with prune",
+			args:        []string{"import", "-f", "-", "--prune", "repo/name"},
+			file:        file,
+			crons:       existing,
+			wantCreated: []string{"hourly", "new"},
+			wantUpdated: []*woodpecker.Cron{{ID: 2, Name: "weekly", Schedule: "0 1 * * 0"}},
+			wantDeleted: []int64{3, 4},
+			wantOutput:  "Created: 1, updated: 2, unchanged: 1, removed: 1\n",
+		},
+		{
+			name:       "unchanged",
+			args:       []string{"import", "-f", "-", "--prune", "repo/name"},
+			file:       "- name: nightly\n  schedule: '@daily'\n  branch: main\n",
+			crons:      existing[:1],
+			wantOutput: "Created: 0, updated: 0, unchanged: 1, removed: 0\n",
+		},
+		{
+			name:  "variables",
+			args:  []string{"import", "-f", "-", "repo/name"},
+			file:  "- name: nightly\n  schedule: '@daily'\n  variables:\n    RELEASE: nightly\n- name: weekly\n  schedule: '@weekly'\n",
+			crons: []*woodpecker.Cron{{ID: 1, Name: "nightly", Schedule: "@daily"}, {ID: 2, Name: "weekly", Schedule: "@weekly", Variables: map[string]string{"RELEASE": "weekly"}}},
+			wantUpdated: []*woodpecker.Cron{
+				{ID: 1, Name: "nightly", Schedule: "@daily", Variables: map[string]string{"RELEASE": "nightly"}},
+				{ID: 2, Name: "weekly", Schedule: "@weekly", Variables: map[string]string{}},
+			},
+			wantOutput: "Created: 0, updated: 2, unchanged: 0, removed: 0\n",
+		},
+		{
+			name:       "empty file",
+			args:       []string{"import", "-f", "-", "repo/name"},
+			crons:      existing,
+			wantOutput: "Created: 0, updated: 0, unchanged: 0, removed: 0\n",
+		},
+		{
+			name:    "duplicate name",
+			args:    []string{"import", "-f", "-", "repo/name"},
+			file:    "- name: nightly\n  schedule: '@daily'\n- name: nightly\n  schedule: '@hourly'\n",
+			wantErr: "cron 'nightly' is defined multiple times",
+		},
+		{
+			name:    "missing schedule",
+			args:    []string{"import", "-f", "-", "repo/name"},
+			file:    "- name: nightly\n",
+			wantErr: "cron 'nightly' has no schedule",
+		},
+		{
+			name:    "unknown field",
+			args:    []string{"import", "-f", "-", "repo/name"},
+			file:    "- name: nightly\n  schedule: '@daily'\n  cron: '@daily'\n",
+			wantErr: "could not parse -: yaml: unmarshal errors:\n  line 3: field cron not found in type cron.cronDefinition",
+		},
+		{
+			name:    "ambiguous existing cron",
+			args:    []string{"import", "-f", "-", "repo/name"},
+			file:    file,
+			crons:   []*woodpecker.Cron{{ID: 1, Name: "nightly"}, {ID: 5, Name: "nightly"}},
+			wantErr: "cron name 'nightly' is ambiguous, remove the duplicates before importing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := newCronClient(t, tt.crons)
+			var created []string
+			mockClient.On("CronCreate", int64(1), mock.Anything).Maybe().Return(func(_ int64, cron *woodpecker.Cron) (*woodpecker.Cron, error) {
+				created = append(created, cron.Name)
+				return cron, nil
+			})
+			var updated []*woodpecker.Cron
+			mockClient.On("CronUpdate", int64(1), mock.Anything).Maybe().Return(func(_ int64, cron *woodpecker.Cron) (*woodpecker.Cron, error) {
+				updated = append(updated, cron)
+				return cron, nil
+			})
+			var deleted []int64
+			mockClient.On("CronDelete", int64(1), mock.Anything).Maybe().Return(func(_, cronID int64) error {
+				deleted = append(deleted, cronID)
+				return nil
+			})
+
+			out := &bytes.Buffer{}
+			command := cronImportCmd
+			command.Writer = io.Discard
+			command.Action = func(_ context.Context, c *cli.Command) error {
+				return importCrons(c, mockClient, strings.NewReader(tt.file), out)
+			}
+			err := command.Run(t.Context(), tt.args)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantCreated, created)
+			assert.Equal(t, tt.wantUpdated, updated)
+			assert.Equal(t, tt.wantDeleted, deleted)
+			assert.Equal(t, tt.wantOutput, out.String())
+		})
+	}
+}
@@ -58,7 +58,7 @@ func cronList(ctx context.Context, c *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	tmpl, err := template.New("_").Parse(format)
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(format)
 	if err != nil {
 		return err
 	}
@@ -76,4 +76,7 @@ ID: {{ .ID }}
 Branch: {{ .Branch }}
 Schedule: {{ .Schedule }}
 NextExec: {{ .NextExec }}
+{{- range $key, $value := .Variables }}
+Variable: {{ $key }}={{ $value }}
+{{- end }}
 `
@@ -16,12 +16,16 @@ package cron
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/urfave/cli/v3"
 
 	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
 )
 
 var cronDeleteCmd = &cli.Command{
@@ -31,35 +35,99 @@ var cronDeleteCmd = &cli.Command{
 	Action:    cronDelete,
 	Flags: []cli.Flag{
 		common.RepoFlag,
+		&cli.Int64SliceFlag{
+			Name:  "id",
+			Usage: "cron id, can be repeated or comma separated to remove multiple crons",
+		},
 		&cli.StringFlag{
-			Name:     "id",
-			Usage:    "cron id",
-			Required: true,
+			Name:  "name",
+			Usage: "cron name",
 		},
 	},
 }
 
 func cronDelete(ctx context.Context, c *cli.Command) error {
-	var (
-		cronID           = c.Int64("id")
-		repoIDOrFullName = c.String("repository")
-	)
-	if repoIDOrFullName == "" {
-		repoIDOrFullName = c.Args().First()
-	}
 	client, err := internal.NewClient(ctx, c)
 	if err != nil {
 		return err
 	}
+	return deleteCron(c, client)
+}
+
+func deleteCron(c *cli.Command, client woodpecker.Client) error {
+	repoIDOrFullName := c.String("repository")
+	if repoIDOrFullName == "" {
+		repoIDOrFullName = c.Args().First()
+	}
+	cronIDs := c.Int64Slice("id")
+	cronName := c.String("name")
+	if len(cronIDs) != 0 && cronName != "" {
+		return errors.New("either --id or --name can be set, not both")
+	}
+	if len(cronIDs) == 0 && cronName == "" {
+		return errors.New("either --id or --name is required")
+	}
+
 	repoID, err := internal.ParseRepo(client, repoIDOrFullName)
 	if err != nil {
 		return err
 	}
-	err = client.CronDelete(repoID, cronID)
-	if err != nil {
-		return err
+
+	if cronName != "" {
+		cronID, err := cronIDByName(client, repoID, cronName)
+		if err != nil {
+			return err
+		}
+		cronIDs = []int64{cronID}
+	}
+
+	if len(cronIDs) == 1 {
+		if err := client.CronDelete(repoID, cronIDs[0]); err != nil {
+			return err
+		}
+		fmt.Println("Success")
+		return nil
 	}
 
-	fmt.Println("Success")
+	// remove all given crons, even if some of them fail
+	var failed []string
+	for _, cronID := range cronIDs {
+		if err := client.CronDelete(repoID, cronID); err != nil {
+			fmt.Printf("Failed to remove cron %d: %v\n", cronID, err)
+			failed = append(failed, strconv.FormatInt(cronID, 10))
+			continue
+		}
+		fmt.Printf("Removed cron %d\n", cronID)
+	}
+
+	fmt.Printf("Removed %d of %d crons\n", len(cronIDs)-len(failed), len(cronIDs))
+	if len(failed) > 0 {
+		return fmt.Errorf("could not remove the crons with the ids %s", strings.Join(failed, ", "))
+	}
 	return nil
 }
+
+func cronIDByName(client woodpecker.Client, repoID int64, name string) (int64, error) {
+	crons, err := listCrons(client, repoID)
+	if err != nil {
+		return 0, err
+	}
+
+	var ids []string
+	var cronID int64
+	for _, cron := range crons {
+		if cron.Name == name {
+			cronID = cron.ID
+			ids = append(ids, strconv.FormatInt(cron.ID, 10))
+		}
+	}
+
+	switch len(ids) {
+	case 0:
+		return 0, fmt.Errorf("cron '%s' not found", name)
+	case 1:
+		return cronID, nil
+	default:
+		return 0, fmt.Errorf("cron name '%s' is ambiguous, it matches the crons with the ids %s", name, strings.Join(ids, ", "))
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestCronDelete(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		crons        []*woodpecker.Cron
+		wantDeleteID []int64
+		failDeleteID int64
+		wantErr      string
+	}{
+		{
+			name:         "delete by id",
+			args:         []string{"rm", "--id", "2", "repo/name"},
+			wantDeleteID: []int64{2},
+		},
+		{
+			name:         "delete multiple ids",
+			args:         []string{"rm", "--id", "1", "--id", "2,3", "repo/name"},
+			wantDeleteID: []int64{1, 2, 3},
+		},
+		{
+			name:         "delete multiple ids with failure",
+			args:         []string{"rm", "--id", "1,2,3", "repo/name"},
+			wantDeleteID: []int64{1, 2, 3},
+			failDeleteID: 2,
+			wantErr:      "could not remove the crons with the ids 2",
+		},
+		{
+			name:         "delete single id with failure",
+			args:         []string{"rm", "--id", "2", "repo/name"},
+			wantDeleteID: []int64{2},
+			failDeleteID: 2,
+			wantErr:      "not found",
+		},
+		{
+			name:         "delete by name",
+			args:         []string{"rm", "--name", "nightly", "repo/name"},
+			crons:        []*woodpecker.Cron{{ID: 1, Name: "weekly"}, {ID: 3, Name: "nightly"}},
+			wantDeleteID: []int64{3},
+		},
+		{
+			name:    "name not found",
+			args:    []string{"rm", "--name", "nightly", "repo/name"},
+			crons:   []*woodpecker.Cron{{ID: 1, Name: "weekly"}},
+			wantErr: "cron 'nightly' not found",
+		},
+		{
+			name:    "ambiguous name",
+			args:    []string{"rm", "--name", "nightly", "repo/name"},
+			crons:   []*woodpecker.Cron{{ID: 1, Name: "nightly"}, {ID: 4, Name: "nightly"}},
+			wantErr: "cron name 'nightly' is ambiguous, it matches the crons with the ids 1, 4",
+		},
+		{
+			name:    "id and name",
+			args:    []string{"rm", "--id", "2", "--name", "nightly", "repo/name"},
+			wantErr: "either --id or --name can be set, not both",
+		},
+		{
+			name:    "neither id nor name",
+			args:    []string{"rm", "repo/name"},
+			wantErr: "either --id or --name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			mockClient.On("RepoLookup", mock.Anything).Maybe().Return(&woodpecker.Repo{ID: 1}, nil)
+			mockClient.On("CronList", int64(1), mock.Anything).Maybe().Return(func(_ int64, opt woodpecker.CronListOptions) ([]*woodpecker.Cron, error) {
+				if opt.Page == 1 {
+					return tt.crons, nil
+				}
+				return []*woodpecker.Cron{}, nil
+			})
+			var deleted []int64
+			mockClient.On("CronDelete", int64(1), mock.Anything).Maybe().Return(func(_, cronID int64) error {
+				deleted = append(deleted, cronID)
+				if cronID == tt.failDeleteID {
+					return errors.New("not found")
+				}
+				return nil
+			})
+
+			command := cronDeleteCmd
+			command.Writer = io.Discard
+			command.Action = func(_ context.Context, c *cli.Command) error {
+				err := deleteCron(c, mockClient)
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+					return nil
+				}
+
+				assert.NoError(t, err)
+				return nil
+			}
+
+			_ = command.Run(t.Context(), tt.args)
+			assert.Equal(t, tt.wantDeleteID, deleted)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+var cronRunCmd = &cli.Command{
+	Name:      "run",
+	Usage:     "run a cron job now",
+	ArgsUsage: "[repo-id|repo-full-name]",
+	Action:    cronRun,
+	Flags: []cli.Flag{
+		common.RepoFlag,
+		&cli.Int64Flag{
+			Name:  "id",
+			Usage: "cron id",
+		},
+		&cli.StringFlag{
+			Name:  "name",
+			Usage: "cron name",
+		},
+	},
+}
+
+func cronRun(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+	return runCron(c, client)
+}
+
+func runCron(c *cli.Command, client woodpecker.Client) error {
+	repoIDOrFullName := c.String("repository")
+	if repoIDOrFullName == "" {
+		repoIDOrFullName = c.Args().First()
+	}
+	cronID := c.Int64("id")
+	cronName := c.String("name")
+	if cronID != 0 && cronName != "" {
+		return errors.New("either --id or --name can be set, not both")
+	}
+	if cronID == 0 && cronName == "" {
+		return errors.New("either --id or --name is required")
+	}
+
+	repoID, err := internal.ParseRepo(client, repoIDOrFullName)
+	if err != nil {
+		return err
+	}
+
+	if cronName != "" {
+		cronID, err = cronIDByName(client, repoID, cronName)
+		if err != nil {
+			return err
+		}
+	}
+
+	pipeline, err := client.CronRun(repoID, cronID)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Root().Writer, "Started pipeline %s#%d\n", repoIDOrFullName, pipeline.Number)
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cron
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestCronRun(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		crons     []*woodpecker.Cron
+		runErr    error
+		wantRunID int64
+		wantOut   string
+		wantErr   string
+	}{
+		{
+			name:      "run by id",
+			args:      []string{"run", "--id", "2", "repo/name"},
+			wantRunID: 2,
+			wantOut:   "Started pipeline repo/name#42\n",
+		},
+		{
+			name:      "run by name",
+			args:      []string{"run", "--name", "nightly", "repo/name"},
+			crons:     []*woodpecker.Cron{{ID: 1, Name: "weekly"}, {ID: 3, Name: "nightly"}},
+			wantRunID: 3,
+			wantOut:   "Started pipeline repo/name#42\n",
+		},
+		{
+			name:    "name not found",
+			args:    []string{"run", "--name", "nightly", "repo/name"},
+			crons:   []*woodpecker.Cron{{ID: 1, Name: "weekly"}},
+			wantErr: "cron 'nightly' not found",
+		},
+		{
+			name:      "server error",
+			args:      []string{"run", "--id", "2", "repo/name"},
+			runErr:    errors.New("cron not found"),
+			wantRunID: 2,
+			wantErr:   "cron not found",
+		},
+		{
+			name:    "id and name",
+			args:    []string{"run", "--id", "2", "--name", "nightly", "repo/name"},
+			wantErr: "either --id or --name can be set, not both",
+		},
+		{
+			name:    "neither id nor name",
+			args:    []string{"run", "repo/name"},
+			wantErr: "either --id or --name is required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			mockClient.On("RepoLookup", mock.Anything).Maybe().Return(&woodpecker.Repo{ID: 1}, nil)
+			mockClient.On("CronList", int64(1), mock.Anything).Maybe().Return(func(_ int64, opt woodpecker.CronListOptions) ([]*woodpecker.Cron, error) {
+				if opt.Page == 1 {
+					return tt.crons, nil
+				}
+				return []*woodpecker.Cron{}, nil
+			})
+			var runID int64
+			mockClient.On("CronRun", int64(1), mock.Anything).Maybe().Return(func(_, cronID int64) (*woodpecker.Pipeline, error) {
+				runID = cronID
+				if tt.runErr != nil {
+					return nil, tt.runErr
+				}
+				return &woodpecker.Pipeline{Number: 42}, nil
+			})
+
+			var out bytes.Buffer
+			command := cronRunCmd
+			command.Writer = &out
+			command.Action = func(_ context.Context, c *cli.Command) error {
+				err := runCron(c, mockClient)
+				if tt.wantErr != "" {
+					assert.EqualError(t, err, tt.wantErr)
+					return nil
+				}
+
+				assert.NoError(t, err)
+				return nil
+			}
+
+			_ = command.Run(t.Context(), tt.args)
+			assert.Equal(t, tt.wantRunID, runID)
+			assert.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
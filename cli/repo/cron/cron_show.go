@@ -63,7 +63,7 @@ func cronShow(ctx context.Context, c *cli.Command) error {
 	if err != nil {
 		return err
 	}
-	tmpl, err := template.New("_").Parse(format)
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(format)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,13 @@
+package cron
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseVariables(t *testing.T) {
+	assert.Equal(t, map[string]string{"RELEASE": "nightly", "URL": "https://example.com/?a=b", "EMPTY": ""},
+		parseVariables([]string{"RELEASE=nightly", "URL=https://example.com/?a=b", "EMPTY=", "=value", "invalid"}))
+	assert.Empty(t, parseVariables([]string{""}))
+}
@@ -50,6 +50,7 @@ var cronUpdateCmd = &cli.Command{
 			Name:  "schedule",
 			Usage: "cron schedule",
 		},
+		variablesFlag,
 		common.FormatFlag(tmplCronList, true),
 	},
 }
@@ -80,11 +81,15 @@ func cronUpdate(ctx context.Context, c *cli.Command) error {
 		Branch:   branch,
 		Schedule: schedule,
 	}
+	if c.IsSet("var") {
+		// replaces all variables, passing an empty variable removes them
+		cron.Variables = parseVariables(c.StringSlice("var"))
+	}
 	cron, err = client.CronUpdate(repoID, cron)
 	if err != nil {
 		return err
 	}
-	tmpl, err := template.New("_").Parse(format)
+	tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(format)
 	if err != nil {
 		return err
 	}
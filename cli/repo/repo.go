@@ -23,6 +23,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v3"
 
+	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/output"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/repo/cron"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/repo/registry"
@@ -38,11 +39,13 @@ var Command = &cli.Command{
 		repoAddCmd,
 		repoChownCmd,
 		cron.Command,
+		repoHookCmd,
 		repoListCmd,
 		registry.Command,
 		repoRemoveCmd,
 		repoRepairCmd,
 		secret.Command,
+		repoSetTimeoutCmd,
 		repoShowCmd,
 		repoSyncCmd,
 		repoUpdateCmd,
@@ -68,12 +71,14 @@ func repoOutput(c *cli.Command, repos []*woodpecker.Repo, fd ...io.Writer) error
 	}
 
 	switch outFmt {
+	case "json", "yaml":
+		return output.WriteStructured(out, outFmt, repos)
 	case "go-template":
 		if len(outOpt) < 1 {
 			return fmt.Errorf("%w: missing template", output.ErrOutputOptionRequired)
 		}
 
-		tmpl, err := template.New("_").Parse(outOpt[0] + "\n")
+		tmpl, err := template.New("_").Funcs(common.TemplateFuncs).Parse(outOpt[0] + "\n")
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,103 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+var repoHookCmd = &cli.Command{
+	Name:  "hook",
+	Usage: "manage the webhook secret of a repository",
+	Commands: []*cli.Command{
+		repoHookRotateCmd,
+		repoHookPruneCmd,
+	},
+}
+
+var repoHookRotateCmd = &cli.Command{
+	Name:      "rotate",
+	Usage:     "rotate the webhook secret and re-register the webhook",
+	ArgsUsage: "<repo-id|repo-full-name>",
+	Action:    repoHookRotate,
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "grace-period",
+			Usage: "duration webhooks using the previous secret are still accepted",
+			Value: time.Hour,
+		},
+	},
+}
+
+var repoHookPruneCmd = &cli.Command{
+	Name:      "prune",
+	Usage:     "revoke the previous webhook secrets",
+	ArgsUsage: "<repo-id|repo-full-name>",
+	Action:    repoHookPrune,
+}
+
+func repoHookRotate(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+	return rotateRepoHook(c, client)
+}
+
+func rotateRepoHook(c *cli.Command, client woodpecker.Client) error {
+	repoIDOrFullName := c.Args().First()
+	repoID, err := internal.ParseRepo(client, repoIDOrFullName)
+	if err != nil {
+		return err
+	}
+
+	grace := c.Duration("grace-period")
+	if err := client.RepoHookRotate(repoID, grace); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Root().Writer, "Successfully rotated the webhook secret of repository %s, the previous secret is accepted for %s\n", repoIDOrFullName, grace)
+	return nil
+}
+
+func repoHookPrune(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+	return pruneRepoHook(c, client)
+}
+
+func pruneRepoHook(c *cli.Command, client woodpecker.Client) error {
+	repoIDOrFullName := c.Args().First()
+	repoID, err := internal.ParseRepo(client, repoIDOrFullName)
+	if err != nil {
+		return err
+	}
+
+	if err := client.RepoHookPrune(repoID); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(c.Root().Writer, "Successfully revoked the previous webhook secrets of repository %s\n", repoIDOrFullName)
+	return nil
+}
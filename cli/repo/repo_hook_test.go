@@ -0,0 +1,91 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestRepoHookRotate(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		grace         time.Duration
+		rotateErr     error
+		expectedOut   string
+		expectedError string
+	}{
+		{
+			name:        "default grace period",
+			grace:       time.Hour,
+			expectedOut: "Successfully rotated the webhook secret of repository repo/name, the previous secret is accepted for 1h0m0s\n",
+		},
+		{
+			name:        "custom grace period",
+			args:        []string{"--grace-period", "10m"},
+			grace:       10 * time.Minute,
+			expectedOut: "Successfully rotated the webhook secret of repository repo/name, the previous secret is accepted for 10m0s\n",
+		},
+		{
+			name:          "server error",
+			grace:         time.Hour,
+			rotateErr:     errors.New("forbidden"),
+			expectedError: "forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			mockClient.On("RepoLookup", "repo/name").Return(&woodpecker.Repo{ID: 123}, nil)
+			mockClient.On("RepoHookRotate", int64(123), tt.grace).Return(tt.rotateErr).Once()
+
+			var out bytes.Buffer
+			command := &cli.Command{
+				Name:   repoHookRotateCmd.Name,
+				Writer: &out,
+				Flags:  repoHookRotateCmd.Flags,
+				Action: func(_ context.Context, c *cli.Command) error {
+					err := rotateRepoHook(c, mockClient)
+					if tt.expectedError != "" {
+						assert.EqualError(t, err, tt.expectedError)
+						return nil
+					}
+
+					assert.NoError(t, err)
+					return nil
+				},
+			}
+
+			args := append([]string{"rotate"}, tt.args...)
+			assert.NoError(t, command.Run(t.Context(), append(args, "repo/name")))
+			assert.Equal(t, tt.expectedOut, out.String())
+		})
+	}
+}
+
+func TestRepoHookPrune(t *testing.T) {
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("RepoLookup", "repo/name").Return(&woodpecker.Repo{ID: 123}, nil)
+	mockClient.On("RepoHookPrune", int64(123)).Return(nil).Once()
+
+	var out bytes.Buffer
+	command := &cli.Command{
+		Name:   repoHookPruneCmd.Name,
+		Writer: &out,
+		Action: func(_ context.Context, c *cli.Command) error {
+			return pruneRepoHook(c, mockClient)
+		},
+	}
+
+	assert.NoError(t, command.Run(t.Context(), []string{"prune", "repo/name"}))
+	assert.Equal(t, "Successfully revoked the previous webhook secrets of repository repo/name\n", out.String())
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
 )
 
 var repoRepairCmd = &cli.Command{
@@ -31,20 +32,29 @@ var repoRepairCmd = &cli.Command{
 }
 
 func repoRepair(ctx context.Context, c *cli.Command) error {
-	repoIDOrFullName := c.Args().First()
 	client, err := internal.NewClient(ctx, c)
 	if err != nil {
 		return err
 	}
+	return repairRepo(c, client)
+}
+
+func repairRepo(c *cli.Command, client woodpecker.Client) error {
+	repoIDOrFullName := c.Args().First()
 	repoID, err := internal.ParseRepo(client, repoIDOrFullName)
 	if err != nil {
 		return err
 	}
 
-	if err := client.RepoRepair(repoID); err != nil {
+	report, err := client.RepoRepair(repoID)
+	if err != nil {
 		return err
 	}
 
-	fmt.Printf("Successfully repaired repository %s\n", repoIDOrFullName)
+	w := c.Root().Writer
+	for _, change := range report.Changes {
+		fmt.Fprintf(w, "- %s\n", change)
+	}
+	fmt.Fprintf(w, "Successfully repaired repository %s\n", repoIDOrFullName)
 	return nil
 }
@@ -0,0 +1,65 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestRepoRepair(t *testing.T) {
+	tests := []struct {
+		name          string
+		report        *woodpecker.RepoRepairReport
+		repairErr     error
+		expectedOut   string
+		expectedError string
+	}{
+		{
+			name: "report changes",
+			report: &woodpecker.RepoRepairReport{RepoID: 123, Changes: []string{
+				"renamed from old/name to repo/name",
+				"registered webhook for https://ci.example.com",
+			}},
+			expectedOut: "- renamed from old/name to repo/name\n- registered webhook for https://ci.example.com\nSuccessfully repaired repository repo/name\n",
+		},
+		{
+			name:          "server error",
+			repairErr:     errors.New("forbidden"),
+			expectedError: "forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			mockClient.On("RepoLookup", "repo/name").Return(&woodpecker.Repo{ID: 123}, nil)
+			mockClient.On("RepoRepair", int64(123)).Return(tt.report, tt.repairErr).Once()
+
+			var out bytes.Buffer
+			command := &cli.Command{
+				Name:   repoRepairCmd.Name,
+				Writer: &out,
+				Action: func(_ context.Context, c *cli.Command) error {
+					err := repairRepo(c, mockClient)
+					if tt.expectedError != "" {
+						assert.EqualError(t, err, tt.expectedError)
+						return nil
+					}
+
+					assert.NoError(t, err)
+					return nil
+				},
+			}
+
+			assert.NoError(t, command.Run(t.Context(), []string{"repair", "repo/name"}))
+			assert.Equal(t, tt.expectedOut, out.String())
+		})
+	}
+}
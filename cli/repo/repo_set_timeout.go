@@ -0,0 +1,87 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+var repoSetTimeoutCmd = &cli.Command{
+	Name:      "set-timeout",
+	Usage:     "set the default and max timeout of the repository's workflows",
+	ArgsUsage: "<repo-id|repo-full-name>",
+	Action:    setTimeout,
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "timeout",
+			Usage: "timeout of the workflows, capped by the max timeout",
+		},
+		&cli.DurationFlag{
+			Name:  "max-timeout",
+			Usage: "max timeout of the workflows, 0 uses the max timeout of the server which can not be exceeded (requires admin privileges)",
+		},
+	},
+}
+
+func setTimeout(ctx context.Context, c *cli.Command) error {
+	client, err := internal.NewClient(ctx, c)
+	if err != nil {
+		return err
+	}
+	repo, err := repoSetTimeout(c, client)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Successfully updated the timeout of repository %s\n", repo.FullName)
+	return nil
+}
+
+func repoSetTimeout(c *cli.Command, client woodpecker.Client) (*woodpecker.Repo, error) {
+	if !c.IsSet("timeout") && !c.IsSet("max-timeout") {
+		return nil, errors.New("either --timeout or --max-timeout is required")
+	}
+
+	repoID, err := internal.ParseRepo(client, c.Args().First())
+	if err != nil {
+		return nil, err
+	}
+
+	patch := new(woodpecker.RepoPatch)
+	if c.IsSet("timeout") {
+		timeout := int64(c.Duration("timeout") / time.Minute)
+		if timeout <= 0 {
+			return nil, errors.New("timeout must be at least one minute")
+		}
+		patch.Timeout = &timeout
+	}
+	if c.IsSet("max-timeout") {
+		maxTimeout := int64(c.Duration("max-timeout") / time.Minute)
+		if maxTimeout < 0 {
+			return nil, errors.New("max timeout must not be negative")
+		}
+		patch.MaxTimeout = &maxTimeout
+	}
+
+	return client.RepoPatch(repoID, patch)
+}
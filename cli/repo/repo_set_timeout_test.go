@@ -0,0 +1,83 @@
+package repo
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestRepoSetTimeout(t *testing.T) {
+	int64Ptr := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name          string
+		args          []string
+		expectedPatch *woodpecker.RepoPatch
+		expectedError string
+	}{
+		{
+			name:          "timeout",
+			args:          []string{"set-timeout", "--timeout", "90m", "123"},
+			expectedPatch: &woodpecker.RepoPatch{Timeout: int64Ptr(90)},
+		},
+		{
+			name:          "timeout and max timeout",
+			args:          []string{"set-timeout", "--timeout", "1h", "--max-timeout", "3h", "123"},
+			expectedPatch: &woodpecker.RepoPatch{Timeout: int64Ptr(60), MaxTimeout: int64Ptr(180)},
+		},
+		{
+			name:          "reset max timeout",
+			args:          []string{"set-timeout", "--max-timeout", "0", "123"},
+			expectedPatch: &woodpecker.RepoPatch{MaxTimeout: int64Ptr(0)},
+		},
+		{
+			name:          "no timeout",
+			args:          []string{"set-timeout", "123"},
+			expectedError: "either --timeout or --max-timeout is required",
+		},
+		{
+			name:          "timeout below a minute",
+			args:          []string{"set-timeout", "--timeout", "30s", "123"},
+			expectedError: "timeout must be at least one minute",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			if tt.expectedPatch != nil {
+				mockClient.On("RepoPatch", int64(123), mock.Anything).Return(&woodpecker.Repo{ID: 123}, nil).Once()
+			}
+
+			// use fresh flags, the flags of repoSetTimeoutCmd are not reset between runs
+			command := &cli.Command{
+				Name:   repoSetTimeoutCmd.Name,
+				Writer: io.Discard,
+				Flags: []cli.Flag{
+					&cli.DurationFlag{Name: "timeout"},
+					&cli.DurationFlag{Name: "max-timeout"},
+				},
+				Action: func(_ context.Context, c *cli.Command) error {
+					_, err := repoSetTimeout(c, mockClient)
+					if tt.expectedError != "" {
+						assert.EqualError(t, err, tt.expectedError)
+						return nil
+					}
+
+					assert.NoError(t, err)
+					mockClient.AssertCalled(t, "RepoPatch", int64(123), tt.expectedPatch)
+					return nil
+				},
+			}
+
+			assert.NoError(t, command.Run(t.Context(), tt.args))
+		})
+	}
+}
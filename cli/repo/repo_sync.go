@@ -16,56 +16,63 @@ package repo
 
 import (
 	"context"
-	"os"
-	"text/template"
+	"fmt"
 
 	"github.com/urfave/cli/v3"
 
-	"go.woodpecker-ci.org/woodpecker/v3/cli/common"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
 	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
 )
 
 var repoSyncCmd = &cli.Command{
 	Name:      "sync",
-	Usage:     "synchronize the repository list",
+	Usage:     "synchronize the repository list with the forge",
 	ArgsUsage: " ",
 	Action:    repoSync,
-	Flags:     []cli.Flag{common.FormatFlag(tmplRepoList, false)},
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "org",
+			Usage: "only synchronize repositories of this organization",
+		},
+		&cli.StringFlag{
+			Name:  "enable",
+			Usage: "activate added repositories with a full name matching this glob pattern (e.g. my-org/*)",
+		},
+	},
 }
 
-// TODO: remove this and add an option to the list cmd as we do not store the remote repo list anymore
 func repoSync(ctx context.Context, c *cli.Command) error {
 	client, err := internal.NewClient(ctx, c)
 	if err != nil {
 		return err
 	}
+	return syncRepos(c, client)
+}
 
-	opt := woodpecker.RepoListOptions{
-		All: true,
-	}
-
-	repos, err := client.RepoList(opt)
-	if err != nil || len(repos) == 0 {
-		return err
-	}
-
-	tmpl, err := template.New("_").Parse(c.String("format") + "\n")
+func syncRepos(c *cli.Command, client woodpecker.Client) error {
+	report, err := client.RepoSync(woodpecker.RepoSyncOptions{
+		Org:    c.String("org"),
+		Enable: c.String("enable"),
+	})
 	if err != nil {
 		return err
 	}
 
-	org := c.String("org")
-	for _, repo := range repos {
-		if org != "" && org != repo.Owner {
-			continue
-		}
-		if err := tmpl.Execute(os.Stdout, repo); err != nil {
-			return err
-		}
+	w := c.Root().Writer
+	for _, name := range report.Added {
+		fmt.Fprintf(w, "added: %s\n", name)
+	}
+	for _, name := range report.Enabled {
+		fmt.Fprintf(w, "enabled: %s\n", name)
+	}
+	for _, name := range report.Removed {
+		fmt.Fprintf(w, "removed: %s\n", name)
+	}
+	for _, rename := range report.Renamed {
+		fmt.Fprintf(w, "renamed: %s -> %s\n", rename.From, rename.To)
+	}
+	if len(report.Added)+len(report.Removed)+len(report.Renamed) == 0 {
+		fmt.Fprintln(w, "Repositories are up to date")
 	}
 	return nil
 }
-
-// Template for repository list items.
-var tmplRepoList = "\x1b[33m{{ .FullName }}\x1b[0m (id: {{ .ID }}, forgeRemoteID: {{ .ForgeRemoteID }}, isActive: {{ .IsActive }})"
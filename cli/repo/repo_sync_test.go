@@ -0,0 +1,78 @@
+package repo
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func TestRepoSync(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		expectedOpt   woodpecker.RepoSyncOptions
+		report        *woodpecker.RepoSyncReport
+		syncErr       error
+		expectedOut   string
+		expectedError string
+	}{
+		{
+			name:        "report changes",
+			args:        []string{"sync", "--org", "octo-org", "--enable", "octo-org/*"},
+			expectedOpt: woodpecker.RepoSyncOptions{Org: "octo-org", Enable: "octo-org/*"},
+			report: &woodpecker.RepoSyncReport{
+				Added:   []string{"octo-org/new"},
+				Enabled: []string{"octo-org/new"},
+				Removed: []string{"octo-org/gone"},
+				Renamed: []woodpecker.RepoRename{{From: "octo-org/old", To: "octo-org/renamed"}},
+			},
+			expectedOut: "added: octo-org/new\nenabled: octo-org/new\nremoved: octo-org/gone\nrenamed: octo-org/old -> octo-org/renamed\n",
+		},
+		{
+			name:        "up to date",
+			args:        []string{"sync"},
+			report:      &woodpecker.RepoSyncReport{},
+			expectedOut: "Repositories are up to date\n",
+		},
+		{
+			name:          "server error",
+			args:          []string{"sync"},
+			syncErr:       errors.New("forbidden"),
+			expectedError: "forbidden",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockClient := mocks.NewMockClient(t)
+			mockClient.On("RepoSync", tt.expectedOpt).Return(tt.report, tt.syncErr).Once()
+
+			var out bytes.Buffer
+			command := &cli.Command{
+				Name:   repoSyncCmd.Name,
+				Flags:  repoSyncCmd.Flags,
+				Writer: &out,
+				Action: func(_ context.Context, c *cli.Command) error {
+					err := syncRepos(c, mockClient)
+					if tt.expectedError != "" {
+						assert.EqualError(t, err, tt.expectedError)
+						return nil
+					}
+
+					assert.NoError(t, err)
+					return nil
+				},
+			}
+
+			assert.NoError(t, command.Run(t.Context(), tt.args))
+			assert.Equal(t, tt.expectedOut, out.String())
+		})
+	}
+}
@@ -17,6 +17,7 @@ package repo
 import (
 	"context"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/urfave/cli/v3"
@@ -47,6 +48,38 @@ var repoUpdateCmd = &cli.Command{
 			Name:  "timeout",
 			Usage: "repository timeout",
 		},
+		&cli.IntFlag{
+			Name:  "priority",
+			Usage: "queue priority of the repository's workflows, higher values run first (requires admin privileges)",
+		},
+		&cli.StringFlag{
+			Name:  "log-store",
+			Usage: "log store of the repository, an empty value selects the default log store (requires admin privileges)",
+		},
+		&cli.IntFlag{
+			Name:  "log-max-size",
+			Usage: "max log size of a step in bytes, 0 uses the server default (requires admin privileges)",
+		},
+		&cli.IntFlag{
+			Name:  "log-max-lines",
+			Usage: "max log line count of a step, 0 uses the server default (requires admin privileges)",
+		},
+		&cli.StringFlag{
+			Name:  "log-retention",
+			Usage: "how long the file log store keeps the logs of the repo, e.g. 720h, 0 keeps them forever and an empty value uses the server default (requires admin privileges)",
+		},
+		&cli.IntFlag{
+			Name:  "max-matrix-jobs",
+			Usage: "max number of workflows a pipeline may expand to by its matrix, 0 uses the server limit (requires admin privileges)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "privileged-plugins",
+			Usage: "plugins allowed to run in privileged mode in addition to the global ones, an empty value removes them (requires admin privileges)",
+		},
+		&cli.StringSliceFlag{
+			Name:  "trusted-clone-plugins",
+			Usage: "clone plugins trusted in addition to the global ones, an empty value removes them (requires admin privileges)",
+		},
 		&cli.StringFlag{
 			Name:  "visibility",
 			Usage: "repository visibility",
@@ -55,6 +88,18 @@ var repoUpdateCmd = &cli.Command{
 			Name:  "config",
 			Usage: "repository configuration path. Example: .woodpecker.yml",
 		},
+		&cli.BoolFlag{
+			Name:  "result-cache",
+			Usage: "reuse the result of identical successful pipelines",
+		},
+		&cli.BoolFlag{
+			Name:  "expose-secrets-to-forks",
+			Usage: "expose secrets to pull requests from forks, overrides the org and server setting (requires admin privileges)",
+		},
+		&cli.BoolFlag{
+			Name:  "workflow-status-checks",
+			Usage: "report a commit status for every workflow including skipped ones, so they can be required by branch protection",
+		},
 		&cli.IntFlag{
 			Name:  "pipeline-counter",
 			Usage: "repository starting pipeline number",
@@ -81,9 +126,17 @@ func repoUpdate(ctx context.Context, c *cli.Command) error {
 		visibility      = c.String("visibility")
 		config          = c.String("config")
 		timeout         = c.Duration("timeout")
+		priority        = c.Int("priority")
+		logStore        = c.String("log-store")
+		logMaxSize      = c.Int("log-max-size")
+		logMaxLines     = c.Int("log-max-lines")
+		maxMatrixJobs   = c.Int("max-matrix-jobs")
 		trusted         = c.Bool("trusted")
 		requireApproval = c.String("require-approval")
 		pipelineCounter = c.Int("pipeline-counter")
+		resultCache     = c.Bool("result-cache")
+		statusChecks    = c.Bool("workflow-status-checks")
+		forkSecrets     = c.Bool("expose-secrets-to-forks")
 		unsafe          = c.Bool("unsafe")
 	)
 
@@ -108,6 +161,33 @@ func repoUpdate(ctx context.Context, c *cli.Command) error {
 		v := int64(timeout / time.Minute)
 		patch.Timeout = &v
 	}
+	if c.IsSet("priority") {
+		patch.Priority = &priority
+	}
+	if c.IsSet("log-store") {
+		patch.LogStore = &logStore
+	}
+	if c.IsSet("log-max-size") {
+		patch.LogMaxSize = &logMaxSize
+	}
+	if c.IsSet("log-max-lines") {
+		patch.LogMaxLines = &logMaxLines
+	}
+	if c.IsSet("log-retention") {
+		logRetention := c.String("log-retention")
+		patch.LogRetention = &logRetention
+	}
+	if c.IsSet("max-matrix-jobs") {
+		patch.MaxMatrixJobs = &maxMatrixJobs
+	}
+	if c.IsSet("privileged-plugins") {
+		v := slices.DeleteFunc(c.StringSlice("privileged-plugins"), func(plugin string) bool { return plugin == "" })
+		patch.PrivilegedPlugins = &v
+	}
+	if c.IsSet("trusted-clone-plugins") {
+		v := slices.DeleteFunc(c.StringSlice("trusted-clone-plugins"), func(plugin string) bool { return plugin == "" })
+		patch.TrustedClonePlugins = &v
+	}
 	if c.IsSet("config") {
 		patch.Config = &config
 	}
@@ -117,6 +197,15 @@ func repoUpdate(ctx context.Context, c *cli.Command) error {
 			patch.Visibility = &visibility
 		}
 	}
+	if c.IsSet("result-cache") {
+		patch.ResultCache = &resultCache
+	}
+	if c.IsSet("workflow-status-checks") {
+		patch.WorkflowStatusChecks = &statusChecks
+	}
+	if c.IsSet("expose-secrets-to-forks") {
+		patch.ExposeSecretsToForks = &forkSecrets
+	}
 	if c.IsSet("pipeline-counter") && !unsafe {
 		fmt.Printf("Setting the pipeline counter is an unsafe operation that could put your repository in an inconsistent state. Please use --unsafe to proceed")
 	}
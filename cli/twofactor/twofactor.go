@@ -0,0 +1,130 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package twofactor
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/cli/internal"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+)
+
+// Command exports the 2fa command set.
+var Command = &cli.Command{
+	Name:  "2fa",
+	Usage: "manage the second factor of your account",
+	Commands: []*cli.Command{
+		{
+			Name:   "enroll",
+			Usage:  "create a new TOTP secret for your authenticator app",
+			Action: withClient(enroll),
+		},
+		{
+			Name:      "activate",
+			Usage:     "activate the enrolled second factor and show the recovery codes",
+			ArgsUsage: "<code>",
+			Action:    withClient(activate),
+		},
+		{
+			Name:      "verify",
+			Usage:     "verify a code and print a token with admin permissions",
+			ArgsUsage: "<code>",
+			Action:    withClient(verify),
+		},
+		{
+			Name:      "recovery-codes",
+			Usage:     "replace the recovery codes",
+			ArgsUsage: "<code>",
+			Action:    withClient(recoveryCodes),
+		},
+	},
+}
+
+type action func(c *cli.Command, client woodpecker.Client, out io.Writer) error
+
+func withClient(fn action) cli.ActionFunc {
+	return func(ctx context.Context, c *cli.Command) error {
+		client, err := internal.NewClient(ctx, c)
+		if err != nil {
+			return err
+		}
+		return fn(c, client, os.Stdout)
+	}
+}
+
+func enroll(_ *cli.Command, client woodpecker.Client, out io.Writer) error {
+	enrollment, err := client.TwoFactorEnroll()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(out, "Secret: %s\nURI: %s\nAdd the secret to your authenticator app and run `2fa activate <code>`.\n", enrollment.Secret, enrollment.URI)
+	return err
+}
+
+func activate(c *cli.Command, client woodpecker.Client, out io.Writer) error {
+	code, err := codeArg(c)
+	if err != nil {
+		return err
+	}
+	codes, err := client.TwoFactorActivate(code)
+	if err != nil {
+		return err
+	}
+	return printRecoveryCodes(out, codes)
+}
+
+func verify(c *cli.Command, client woodpecker.Client, out io.Writer) error {
+	code, err := codeArg(c)
+	if err != nil {
+		return err
+	}
+	token, err := client.TwoFactorVerify(code)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, token)
+	return err
+}
+
+func recoveryCodes(c *cli.Command, client woodpecker.Client, out io.Writer) error {
+	code, err := codeArg(c)
+	if err != nil {
+		return err
+	}
+	codes, err := client.TwoFactorRecoveryCodes(code)
+	if err != nil {
+		return err
+	}
+	return printRecoveryCodes(out, codes)
+}
+
+func codeArg(c *cli.Command) (string, error) {
+	code := strings.TrimSpace(c.Args().First())
+	if code == "" {
+		return "", fmt.Errorf("missing code")
+	}
+	return code, nil
+}
+
+func printRecoveryCodes(out io.Writer, codes *woodpecker.TwoFactorRecoveryCodes) error {
+	_, err := fmt.Fprintf(out, "Recovery codes, store them in a safe place:\n%s\n", strings.Join(codes.RecoveryCodes, "\n"))
+	return err
+}
@@ -0,0 +1,54 @@
+package twofactor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
+	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/mocks"
+)
+
+func run(t *testing.T, fn action, client woodpecker.Client, args ...string) (string, error) {
+	var out bytes.Buffer
+	command := &cli.Command{
+		Action: func(_ context.Context, c *cli.Command) error {
+			return fn(c, client, &out)
+		},
+	}
+	err := command.Run(t.Context(), append([]string{"2fa"}, args...))
+	return out.String(), err
+}
+
+func TestEnroll(t *testing.T) {
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("TwoFactorEnroll").Return(&woodpecker.TwoFactorEnrollment{Secret: "JBSWY3DPEHPK3PXP", URI: "otpauth://totp/x"}, nil)
+
+	out, err := run(t, enroll, mockClient)
+	assert.NoError(t, err)
+	assert.Contains(t, out, "Secret: JBSWY3DPEHPK3PXP\nURI: otpauth://totp/x\n")
+}
+
+func TestActivate(t *testing.T) {
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("TwoFactorActivate", "123456").Return(&woodpecker.TwoFactorRecoveryCodes{RecoveryCodes: []string{"a-b", "c-d"}}, nil)
+
+	out, err := run(t, activate, mockClient, "123456")
+	assert.NoError(t, err)
+	assert.Equal(t, "Recovery codes, store them in a safe place:\na-b\nc-d\n", out)
+
+	_, err = run(t, activate, mockClient)
+	assert.ErrorContains(t, err, "missing code")
+}
+
+func TestVerify(t *testing.T) {
+	mockClient := mocks.NewMockClient(t)
+	mockClient.On("TwoFactorVerify", "123456").Return("token", nil)
+
+	out, err := run(t, verify, mockClient, "123456")
+	assert.NoError(t, err)
+	assert.Equal(t, "token\n", out)
+}
@@ -209,12 +209,19 @@ func run(ctx context.Context, c *cli.Command, backends []types.Backend) error {
 		log.Debug().Msgf("custom labels detected: %#v", customLabels)
 	}
 
+	// agents not reporting resources are only scheduled by labels
+	health := func() rpc.Health { return rpc.Health{} }
+	if c.Bool("report-resources") {
+		health = hostResources
+	}
+
 	agentConfig.AgentID, err = client.RegisterAgent(grpcCtx, rpc.AgentInfo{ //nolint:contextcheck
 		Version:      version.String(),
 		Backend:      backendEngine.Name(),
 		Platform:     engInfo.Platform,
 		Capacity:     maxWorkflows,
 		CustomLabels: customLabels,
+		Resources:    health().Capacity,
 	})
 	if err != nil {
 		return err
@@ -264,7 +271,7 @@ func run(ctx context.Context, c *cli.Command, backends []types.Backend) error {
 
 	serviceWaitingGroup.Go(func() error {
 		for {
-			err := client.ReportHealth(grpcCtx)
+			err := client.ReportHealth(grpcCtx, health())
 			if err != nil {
 				log.Err(err).Msg("failed to report health")
 				// Check if the error is due to context cancellation
@@ -287,6 +294,7 @@ func run(ctx context.Context, c *cli.Command, backends []types.Backend) error {
 	for i := range maxWorkflows {
 		serviceWaitingGroup.Go(func() error {
 			runner := agent.NewRunner(client, filter, hostname, counter, &backendEngine)
+			runner.EnablePrefetch(c.Int("prefetch-workflows"), c.Duration("prefetch-lease"))
 			log.Debug().Msgf("created new runner %d", i)
 
 			for {
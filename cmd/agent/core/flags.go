@@ -77,6 +77,24 @@ var flags = []cli.Flag{
 		Usage:   "agent parallel workflows",
 		Value:   1,
 	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_PREFETCH_WORKFLOWS"),
+		Name:    "prefetch-workflows",
+		Usage:   "number of workflows each busy runner reserves in advance (0 to disable)",
+		Value:   0,
+	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_PREFETCH_LEASE"),
+		Name:    "prefetch-lease",
+		Usage:   "duration a prefetched workflow stays reserved for the agent before it is requeued",
+		Value:   30 * time.Second,
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_REPORT_RESOURCES"),
+		Name:    "report-resources",
+		Usage:   "report the cpu and memory capacity and usage of the host to the server for scheduling",
+		Value:   true,
+	},
 	&cli.BoolFlag{
 		Sources: cli.EnvVars("WOODPECKER_HEALTHCHECK"),
 		Name:    "healthcheck",
@@ -0,0 +1,95 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.woodpecker-ci.org/woodpecker/v3/pipeline/rpc"
+)
+
+const (
+	procMeminfo = "/proc/meminfo"
+	procLoadavg = "/proc/loadavg"
+)
+
+// hostResources returns the cpu and memory capacity and usage of the host.
+// Values which can not be determined on the platform are left zero.
+func hostResources() rpc.Health {
+	return readResources(procMeminfo, procLoadavg)
+}
+
+func readResources(meminfo, loadavg string) rpc.Health {
+	var health rpc.Health
+	health.Capacity.CPU = int64(runtime.NumCPU()) * 1000 //nolint:mnd
+
+	if total, available, ok := readMeminfo(meminfo); ok {
+		health.Capacity.Memory = total
+		health.Usage.Memory = total - available
+	}
+
+	if load, ok := readLoadavg(loadavg); ok {
+		// the load of the last minute approximates the number of busy cpus
+		health.Usage.CPU = min(int64(load*1000), health.Capacity.CPU) //nolint:mnd
+	}
+
+	return health
+}
+
+// readMeminfo returns the total and available memory in bytes from a meminfo file.
+func readMeminfo(path string) (total, available int64, ok bool) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, 0, false
+	}
+	defer file.Close()
+
+	values := make(map[string]int64)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// lines look like "MemTotal:       16318472 kB"
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 { //nolint:mnd
+			continue
+		}
+		value, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[strings.TrimSuffix(fields[0], ":")] = value * 1024 //nolint:mnd
+	}
+
+	total, hasTotal := values["MemTotal"]
+	available, hasAvailable := values["MemAvailable"]
+	return total, available, hasTotal && hasAvailable
+}
+
+// readLoadavg returns the load average of the last minute from a loadavg file.
+func readLoadavg(path string) (float64, bool) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return 0, false
+	}
+	load, err := strconv.ParseFloat(fields[0], 64)
+	return load, err == nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadResources(t *testing.T) {
+	dir := t.TempDir()
+	meminfo := filepath.Join(dir, "meminfo")
+	loadavg := filepath.Join(dir, "loadavg")
+	assert.NoError(t, os.WriteFile(meminfo, []byte(`MemTotal:        8192000 kB
+MemFree:          512000 kB
+MemAvailable:    2048000 kB
+Buffers:          128000 kB
+`), 0o600))
+	assert.NoError(t, os.WriteFile(loadavg, []byte("0.50 0.75 1.00 2/512 12345\n"), 0o600))
+
+	health := readResources(meminfo, loadavg)
+	assert.EqualValues(t, runtime.NumCPU()*1000, health.Capacity.CPU)
+	assert.EqualValues(t, 500, health.Usage.CPU)
+	assert.EqualValues(t, 8192000*1024, health.Capacity.Memory)
+	assert.EqualValues(t, (8192000-2048000)*1024, health.Usage.Memory)
+}
+
+func TestReadResourcesUnavailable(t *testing.T) {
+	dir := t.TempDir()
+	loadavg := filepath.Join(dir, "loadavg")
+	// a load above the number of cpus is capped at the capacity
+	assert.NoError(t, os.WriteFile(loadavg, []byte("100000.00 0.75 1.00 2/512 12345\n"), 0o600))
+
+	health := readResources(filepath.Join(dir, "missing"), loadavg)
+	assert.EqualValues(t, runtime.NumCPU()*1000, health.Usage.CPU)
+	assert.Zero(t, health.Capacity.Memory)
+	assert.Zero(t, health.Usage.Memory)
+}
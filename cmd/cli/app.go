@@ -26,6 +26,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/cli/pipeline"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/repo"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/setup"
+	"go.woodpecker-ci.org/woodpecker/v3/cli/twofactor"
 	"go.woodpecker-ci.org/woodpecker/v3/cli/update"
 	"go.woodpecker-ci.org/woodpecker/v3/version"
 )
@@ -54,6 +55,7 @@ func newApp() *cli.Command {
 		pipeline.Command,
 		repo.Command,
 		setup.Command,
+		twofactor.Command,
 		update.Command,
 	}
 
@@ -0,0 +1,84 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/permissions"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+// reloadAdminsOnHangup reloads the admin file every time the server receives a SIGHUP,
+// so admins can be granted and revoked without restarting the server.
+func reloadAdminsOnHangup(ctx context.Context, _store store.Store, manager services.Manager, admins *permissions.Admins) {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	defer signal.Stop(hangup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hangup:
+			if err := admins.Reload(); err != nil {
+				log.Error().Err(err).Msg("could not reload admin file, keeping the current admins")
+				continue
+			}
+			log.Info().Msg("admin file reloaded")
+			revokeAdmins(ctx, _store, manager, admins)
+		}
+	}
+}
+
+// revokeAdmins removes the admin permission of stored users who are no longer admins.
+// New admins are granted the permission with their next login.
+func revokeAdmins(ctx context.Context, _store store.Store, manager services.Manager, admins *permissions.Admins) {
+	if !admins.Configured() {
+		return
+	}
+
+	users, err := _store.GetUserList(&model.ListOptions{All: true})
+	if err != nil {
+		log.Error().Err(err).Msg("could not list users to revoke admins")
+		return
+	}
+	for _, user := range users {
+		if !user.Admin {
+			continue
+		}
+		_forge, err := manager.ForgeFromUser(user)
+		if err != nil {
+			log.Error().Err(err).Msgf("could not get forge of user %s", user.Login)
+			continue
+		}
+		if admins.IsAdmin(ctx, _forge, user) {
+			continue
+		}
+		user.Admin = false
+		if err := _store.UpdateUser(user); err != nil {
+			log.Error().Err(err).Msgf("could not revoke admin permission of user %s", user.Login)
+			continue
+		}
+		log.Info().Msgf("revoked admin permission of user %s", user.Login)
+	}
+}
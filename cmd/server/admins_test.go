@@ -0,0 +1,52 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	forge_mocks "go.woodpecker-ci.org/woodpecker/v3/server/forge/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	services_mocks "go.woodpecker-ci.org/woodpecker/v3/server/services/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/permissions"
+	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+)
+
+func TestRevokeAdmins(t *testing.T) {
+	kept := &model.User{ID: 1, Login: "kept", Admin: true}
+	removed := &model.User{ID: 2, Login: "removed", Admin: true}
+	normal := &model.User{ID: 3, Login: "normal"}
+
+	_store := store_mocks.NewMockStore(t)
+	_manager := services_mocks.NewMockManager(t)
+	_forge := forge_mocks.NewMockForge(t)
+	_store.On("GetUserList", mock.Anything).Return([]*model.User{kept, removed, normal}, nil)
+	_manager.On("ForgeFromUser", mock.Anything).Return(_forge, nil)
+	_store.On("UpdateUser", removed).Return(nil).Once()
+
+	revokeAdmins(t.Context(), _store, _manager, permissions.NewAdmins([]string{"kept"}))
+
+	assert.True(t, kept.Admin)
+	assert.False(t, removed.Admin)
+	assert.False(t, normal.Admin)
+}
+
+func TestRevokeAdminsWithoutConfiguredAdmins(t *testing.T) {
+	// without configured admins the admin permissions are managed in the UI only
+	revokeAdmins(t.Context(), store_mocks.NewMockStore(t), services_mocks.NewMockManager(t), permissions.NewAdmins(nil))
+}
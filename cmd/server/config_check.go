@@ -0,0 +1,293 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge/common"
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge/setup"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/encryption/wrapper/serverconfig"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/permissions"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/tokenhash"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/datastore"
+)
+
+const configCheckForgeTimeout = 10 * time.Second
+
+// configCheckResult is the outcome of a single check of --config-check.
+type configCheckResult struct {
+	Name   string   `json:"name"`
+	OK     bool     `json:"ok"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// validateConfig checks the flags for invalid values without connecting to any service
+// and returns all problems found.
+func validateConfig(c *cli.Command) []error {
+	var errs []error
+
+	if err := validateServerHost(c.String("server-host")); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, _, err := parseWebhookHosts(c.StringSlice("server-webhook-host"), ""); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Int("log-stream-replay-lines") < 0 || c.Int("log-stream-replay-bytes") < 0 {
+		errs = append(errs, fmt.Errorf("log stream replay limits must not be negative"))
+	}
+	if c.Int("log-max-size") < 0 || c.Int("log-max-lines") < 0 {
+		errs = append(errs, fmt.Errorf("log limits must not be negative"))
+	}
+
+	if approvalMode := model.ApprovalMode(c.String("default-approval-mode")); !approvalMode.Valid() {
+		errs = append(errs, fmt.Errorf("approval mode %s is not valid", approvalMode))
+	}
+
+	if _, err := parseWebhookEvents(c.StringSlice("default-cancel-previous-pipeline-events")); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := loadDefaultWorkflowLabels(c.StringSlice("default-workflow-labels"), c.String("default-workflow-labels-file")); err != nil {
+		errs = append(errs, err)
+	}
+
+	if err := validateCustomFile(strings.TrimSpace(c.String("custom-css-file"))); err != nil {
+		errs = append(errs, fmt.Errorf("invalid custom css file: %w", err))
+	}
+	if err := validateCustomFile(strings.TrimSpace(c.String("custom-js-file"))); err != nil {
+		errs = append(errs, fmt.Errorf("invalid custom js file: %w", err))
+	}
+
+	if c.Int("max-org-running-pipelines") < 0 {
+		errs = append(errs, fmt.Errorf("max org running pipelines must not be negative"))
+	}
+
+	if c.Int("default-clone-depth") < 0 {
+		errs = append(errs, fmt.Errorf("default clone depth must not be negative"))
+	}
+
+	if c.Float("webhook-rate-limit") < 0 || c.Float("webhook-global-rate-limit") < 0 {
+		errs = append(errs, fmt.Errorf("webhook rate limits must not be negative"))
+	}
+
+	if backend := c.String("secret-backend"); backend != "database" && backend != "vault" {
+		errs = append(errs, fmt.Errorf("secret backend '%s' is not supported", backend))
+	}
+
+	for _, name := range c.StringSlice("log-store-additional") {
+		if !slices.Contains([]string{"database", "addon", "file", "s3"}, name) {
+			errs = append(errs, fmt.Errorf("additional log store '%s' is not supported", name))
+		}
+	}
+
+	if err := common.ValidateStatusContextFormat(c.String("status-context-format")); err != nil {
+		errs = append(errs, fmt.Errorf("invalid status context format: %w", err))
+	}
+
+	if idle, dead := c.Duration("agent-idle-timeout"), c.Duration("agent-dead-timeout"); idle < 0 || dead < 0 {
+		errs = append(errs, fmt.Errorf("agent idle and dead timeout must not be negative"))
+	} else if idle > 0 && dead > 0 && dead < idle {
+		errs = append(errs, fmt.Errorf("agent dead timeout must not be shorter than the agent idle timeout"))
+	}
+
+	if err := permissions.ValidateAdmins(c.StringSlice("admin")); err != nil {
+		errs = append(errs, fmt.Errorf("invalid admin: %w", err))
+	}
+	if adminFile := c.String("admin-file"); adminFile != "" {
+		if _, err := permissions.ReadAdminFile(adminFile); err != nil {
+			errs = append(errs, fmt.Errorf("invalid admin file: %w", err))
+		}
+	}
+
+	if _, _, err := parsePendingTTL(c.String("queue-pending-ttl")); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Int("queue-max-pending") < 0 {
+		errs = append(errs, fmt.Errorf("queue max pending must not be negative"))
+	}
+	if action := c.String("queue-full-action"); action != server.QueueFullReject && action != server.QueueFullBlock {
+		errs = append(errs, fmt.Errorf("queue full action '%s' is not valid, must be %s or %s", action, server.QueueFullReject, server.QueueFullBlock))
+	}
+
+	if _, err := tokenhash.Hash(c.String("agent-token-hash-algorithm"), ""); err != nil {
+		errs = append(errs, fmt.Errorf("agent token hash algorithm must be one of %s: %w", strings.Join(tokenhash.Algorithms(), ", "), err))
+	}
+
+	if _, err := setupCookieOptions(c, ""); err != nil {
+		errs = append(errs, err)
+	}
+	if _, err := setupCORSOptions(c); err != nil {
+		errs = append(errs, err)
+	}
+
+	if c.Bool("session-sliding") && c.Duration("session-max-lifetime") < c.Duration("session-expires") {
+		errs = append(errs, fmt.Errorf("session max lifetime must not be shorter than the session expiration time"))
+	}
+
+	pool := setupPool(c)
+	if err := pool.Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid database connection pool settings: %w", err))
+	}
+	if err := setupReplicaPool(c, pool).Validate(); err != nil {
+		errs = append(errs, fmt.Errorf("invalid database replica connection pool settings: %w", err))
+	}
+
+	if tls := setupDatabaseTLS(c); tls.Enabled() {
+		if err := tls.Validate(); err != nil {
+			errs = append(errs, fmt.Errorf("invalid database tls settings: %w", err))
+		} else if c.String("db-driver") == "sqlite3" {
+			errs = append(errs, fmt.Errorf("database tls settings are not supported by sqlite3"))
+		}
+	}
+
+	if key := c.String("server-config-encryption-key"); key != "" {
+		if _, err := serverconfig.NewAESCipher(key); err != nil {
+			errs = append(errs, fmt.Errorf("invalid server config encryption key: %w", err))
+		}
+	}
+
+	return errs
+}
+
+func validateServerHost(host string) error {
+	if host == "" {
+		return fmt.Errorf("WOODPECKER_HOST is not properly configured")
+	}
+	if !strings.Contains(host, "://") {
+		return fmt.Errorf("WOODPECKER_HOST must be <scheme>://<hostname> format")
+	}
+	if _, err := url.Parse(host); err != nil {
+		return fmt.Errorf("could not parse WOODPECKER_HOST: %w", err)
+	}
+	return nil
+}
+
+// runConfigCheck validates the config, connects to the database without migrating or changing it
+// and checks the forge is reachable. A summary of all checks is written to out.
+func runConfigCheck(ctx context.Context, c *cli.Command, out io.Writer) error {
+	results := []configCheckResult{
+		newConfigCheckResult("config", validateConfig(c)...),
+		newConfigCheckResult("database", checkDatabase(c)),
+		newConfigCheckResult("forge", checkForge(ctx, c)),
+	}
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		return err
+	}
+
+	for _, result := range results {
+		if !result.OK {
+			return cli.Exit("config check failed", 1)
+		}
+	}
+	return nil
+}
+
+func newConfigCheckResult(name string, errs ...error) configCheckResult {
+	result := configCheckResult{Name: name, OK: true}
+	for _, err := range errs {
+		if err != nil {
+			result.OK = false
+			result.Errors = append(result.Errors, err.Error())
+		}
+	}
+	return result
+}
+
+// checkDatabase opens the database read-only, so neither a sqlite file is created nor pragmas are applied,
+// and checks that it is reachable and its migrations can be read.
+func checkDatabase(c *cli.Command) error {
+	driver := c.String("db-driver")
+	datasource := c.String("db-datasource")
+	if driver == "sqlite3" {
+		path, _, _ := strings.Cut(strings.TrimPrefix(datasource, "file:"), "?")
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			// the server creates the file on the first start
+			return nil
+		}
+		var err error
+		if datasource, err = sqliteReadOnly(datasource); err != nil {
+			return err
+		}
+	}
+
+	_store, err := datastore.NewEngine(&store.Opts{
+		Driver: driver,
+		Config: datasource,
+		XORM:   store.XORM{Pool: setupPool(c)},
+		TLS:    setupDatabaseTLS(c),
+	})
+	if err != nil {
+		return fmt.Errorf("could not open datastore: %w", err)
+	}
+	if err := _store.Ping(); err != nil {
+		return errors.Join(err, _store.Close())
+	}
+	if _, err := _store.MigrationStatus(); err != nil {
+		return errors.Join(fmt.Errorf("could not read migrations: %w", err), _store.Close())
+	}
+	return _store.Close()
+}
+
+// sqliteReadOnly turns the data source into an uri opened in read-only mode.
+func sqliteReadOnly(datasource string) (string, error) {
+	path, query, _ := strings.Cut(datasource, "?")
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid sqlite data source: %w", err)
+	}
+	params.Set("mode", "ro")
+	if !strings.HasPrefix(path, "file:") {
+		path = "file:" + path
+	}
+	return path + "?" + params.Encode(), nil
+}
+
+func checkForge(ctx context.Context, c *cli.Command) error {
+	forgeModel, err := services.ForgeFromFlags(c, nil)
+	if err != nil {
+		return err
+	}
+	_forge, err := setup.Forge(forgeModel)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, configCheckForgeTimeout)
+	defer cancel()
+	return forge.Healthy(ctx, _forge)
+}
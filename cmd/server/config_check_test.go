@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+)
+
+// runWithFlags runs fn with a command parsing the server flags from args.
+func runWithFlags(t *testing.T, fn func(context.Context, *cli.Command) error, args ...string) error {
+	cmd := &cli.Command{
+		Flags:  flags,
+		Action: fn,
+	}
+	return cmd.Run(t.Context(), append([]string{"woodpecker-server"}, args...))
+}
+
+func TestValidateConfig(t *testing.T) {
+	t.Run("valid", func(t *testing.T) {
+		assert.NoError(t, runWithFlags(t, func(_ context.Context, c *cli.Command) error {
+			assert.Empty(t, validateConfig(c))
+			return nil
+		}, "--server-host", "https://ci.example.com"))
+	})
+
+	t.Run("all problems are reported", func(t *testing.T) {
+		assert.NoError(t, runWithFlags(t, func(_ context.Context, c *cli.Command) error {
+			errs := validateConfig(c)
+			var messages []string
+			for _, err := range errs {
+				messages = append(messages, err.Error())
+			}
+			assert.Equal(t, []string{
+				"WOODPECKER_HOST must be <scheme>://<hostname> format",
+				"approval mode sometimes is not valid",
+				"invalid custom css file: unsupported scheme 'ftp', only http and https are supported",
+				"additional log store 'gcs' is not supported",
+				"invalid status context format: template: context:1: function \"workflw\" not defined",
+				"session max lifetime must not be shorter than the session expiration time",
+				"invalid database connection pool settings: max idle connections (20) must not exceed max open connections (10)",
+				"invalid database replica connection pool settings: max idle connections (20) must not exceed max open connections (10)",
+				"invalid database tls settings: tls client certificate and key must be set together",
+			}, messages)
+			return nil
+		},
+			"--server-host", "ci.example.com",
+			"--default-approval-mode", "sometimes",
+			"--custom-css-file", "ftp://cdn.example.com/woodpecker.css",
+			"--log-store-additional", "s3,gcs",
+			"--status-context-format", "{{ workflw }}",
+			"--session-sliding",
+			"--session-max-lifetime", "24h",
+			"--db-max-open-connections", "10",
+			"--db-max-idle-connections", "20",
+			"--db-tls-cert", "client.pem",
+		))
+	})
+}
+
+func TestRunConfigCheck(t *testing.T) {
+	forgeServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/version" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"version":"1.22.0"}`))
+	}))
+	defer forgeServer.Close()
+
+	args := []string{
+		"--server-host", "https://ci.example.com",
+		"--db-datasource", filepath.Join(t.TempDir(), "woodpecker.sqlite"),
+		"--gitea",
+		"--forge-url", forgeServer.URL,
+	}
+
+	t.Run("success", func(t *testing.T) {
+		var out bytes.Buffer
+		assert.NoError(t, runWithFlags(t, func(ctx context.Context, c *cli.Command) error {
+			return runConfigCheck(ctx, c, &out)
+		}, args...))
+
+		var results []configCheckResult
+		require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+		assert.Equal(t, []configCheckResult{
+			{Name: "config", OK: true},
+			{Name: "database", OK: true},
+			{Name: "forge", OK: true},
+		}, results)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		offline := httptest.NewServer(http.NotFoundHandler())
+		offline.Close()
+
+		var out bytes.Buffer
+		var err error
+		assert.NoError(t, runWithFlags(t, func(ctx context.Context, c *cli.Command) error {
+			err = runConfigCheck(ctx, c, &out)
+			return nil
+		}, append(args, "--forge-url", offline.URL, "--default-approval-mode", "sometimes")...))
+
+		var exitErr cli.ExitCoder
+		require.ErrorAs(t, err, &exitErr)
+		assert.Equal(t, 1, exitErr.ExitCode())
+
+		var results []configCheckResult
+		require.NoError(t, json.Unmarshal(out.Bytes(), &results))
+		require.Len(t, results, 3)
+		assert.Equal(t, configCheckResult{Name: "config", Errors: []string{"approval mode sometimes is not valid"}}, results[0])
+		assert.True(t, results[1].OK)
+		assert.False(t, results[2].OK)
+	})
+}
+
+func TestCheckDatabase(t *testing.T) {
+	t.Run("missing sqlite file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "woodpecker.sqlite")
+		assert.NoError(t, runWithFlags(t, func(_ context.Context, c *cli.Command) error {
+			assert.NoError(t, checkDatabase(c))
+			return nil
+		}, "--db-datasource", path))
+		assert.NoFileExists(t, path, "the check must not create the sqlite file")
+	})
+
+	t.Run("read-only", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "woodpecker.sqlite")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+
+		assert.NoError(t, runWithFlags(t, func(_ context.Context, c *cli.Command) error {
+			assert.NoError(t, checkDatabase(c))
+			return nil
+		}, "--db-datasource", path, "--db-sqlite-journal-mode", "WAL"))
+
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		assert.Zero(t, info.Size(), "the check must not write to the database")
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "the check must not apply the journal mode")
+	})
+}
+
+func TestSqliteReadOnly(t *testing.T) {
+	datasource, err := sqliteReadOnly("woodpecker.sqlite")
+	require.NoError(t, err)
+	assert.Equal(t, "file:woodpecker.sqlite?mode=ro", datasource)
+
+	datasource, err = sqliteReadOnly("file:woodpecker.sqlite?_busy_timeout=1000&mode=rwc")
+	require.NoError(t, err)
+	assert.Equal(t, "file:woodpecker.sqlite?_busy_timeout=1000&mode=ro", datasource)
+}
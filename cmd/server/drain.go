@@ -0,0 +1,80 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+)
+
+// drainCheckInterval is the interval in which the running workflows are checked while draining.
+const drainCheckInterval = time.Second
+
+// drain prepares the server to be replaced without interrupting the running workflows:
+// New webhooks are rejected and no workflows are handed out to the agents anymore,
+// then it waits until the running workflows finished or the timeout is over.
+// The pending workflows are kept in the store, so the next server continues with them.
+func drain(q queue.Queue, timeout, checkInterval time.Duration) {
+	log.Info().Msgf("draining server, waiting up to %s for the running workflows", timeout)
+	server.SetDraining(true)
+	// a shared queue keeps handing out workflows on the other servers
+	shared, isShared := q.(queue.Shared)
+	if isShared {
+		shared.PauseLocal()
+	} else {
+		q.Pause()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if persister, ok := q.(queue.Persister); ok {
+		if err := persister.Persist(ctx); err != nil {
+			log.Error().Err(err).Msg("could not persist the pending workflows")
+		}
+	}
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+
+	lastRunning := -1
+	for {
+		info := q.Info(ctx)
+		running := info.Stats.Running
+		if isShared {
+			running = shared.RunningLocal()
+		}
+		if running == 0 {
+			log.Info().Msgf("all running workflows finished, %d pending workflows are left in the queue", info.Stats.Pending+info.Stats.WaitingOnDeps)
+			return
+		}
+		if running != lastRunning {
+			log.Info().Msgf("draining server, waiting for %d running workflows", running)
+			lastRunning = running
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Warn().Msgf("drain timeout is over, stopping the server with %d running workflows", running)
+			return
+		case <-ticker.C:
+		}
+	}
+}
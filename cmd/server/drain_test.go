@@ -0,0 +1,124 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+)
+
+// fakeQueue finishes one of its running workflows on every call of Info.
+type fakeQueue struct {
+	queue.Queue
+	sync.Mutex
+	running   int
+	paused    bool
+	persisted bool
+}
+
+func (q *fakeQueue) Pause() {
+	q.Lock()
+	defer q.Unlock()
+	q.paused = true
+}
+
+func (q *fakeQueue) Info(_ context.Context) queue.InfoT {
+	q.Lock()
+	defer q.Unlock()
+	info := queue.InfoT{}
+	info.Stats.Running = q.running
+	info.Stats.Pending = 2
+	q.running = max(q.running-1, 0)
+	return info
+}
+
+func (q *fakeQueue) Persist(_ context.Context) error {
+	q.Lock()
+	defer q.Unlock()
+	q.persisted = true
+	return nil
+}
+
+// fakeSharedQueue has running workflows of other servers, which are never finished.
+type fakeSharedQueue struct {
+	*fakeQueue
+	pausedLocal bool
+}
+
+func (q *fakeSharedQueue) PauseLocal() {
+	q.Lock()
+	defer q.Unlock()
+	q.pausedLocal = true
+}
+
+func (q *fakeSharedQueue) RunningLocal() int {
+	q.Lock()
+	defer q.Unlock()
+	return q.running
+}
+
+func (q *fakeSharedQueue) Info(c context.Context) queue.InfoT {
+	info := q.fakeQueue.Info(c)
+	info.Stats.Running += 10
+	return info
+}
+
+func TestDrain(t *testing.T) {
+	t.Cleanup(func() { server.SetDraining(false) })
+
+	t.Run("running workflows finish", func(t *testing.T) {
+		server.SetDraining(false)
+		q := &fakeQueue{running: 3}
+
+		start := time.Now()
+		drain(q, time.Minute, time.Millisecond)
+		assert.Less(t, time.Since(start), time.Minute)
+
+		assert.True(t, server.Draining(), "webhooks are rejected")
+		assert.True(t, q.paused)
+		assert.True(t, q.persisted)
+		assert.Zero(t, q.running)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		server.SetDraining(false)
+		q := &fakeQueue{running: 1000}
+
+		start := time.Now()
+		drain(q, 20*time.Millisecond, 5*time.Millisecond)
+		assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+		assert.True(t, server.Draining())
+		assert.Positive(t, q.running, "the server stops with running workflows")
+	})
+	t.Run("shared queue", func(t *testing.T) {
+		server.SetDraining(false)
+		q := &fakeSharedQueue{fakeQueue: &fakeQueue{running: 3}}
+
+		start := time.Now()
+		drain(q, time.Minute, time.Millisecond)
+		assert.Less(t, time.Since(start), time.Minute, "only the workflows of this server are waited for")
+
+		assert.True(t, q.pausedLocal)
+		assert.False(t, q.paused, "the other servers keep handing out workflows")
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/encryption/wrapper/serverconfig"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/logger"
+)
+
+func encryptServerConfig(ctx context.Context, c *cli.Command) error {
+	if err := logger.SetupGlobalLogger(ctx, c, true); err != nil {
+		return err
+	}
+	key := c.String("server-config-encryption-key")
+	if key == "" {
+		return errors.New("WOODPECKER_SERVER_CONFIG_ENCRYPTION_KEY is required")
+	}
+	aead, err := serverconfig.NewAESCipher(key)
+	if err != nil {
+		return err
+	}
+
+	_store, err := setupStore(ctx, c)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := _store.Close(); err != nil {
+			log.Error().Err(err).Msg("could not close store")
+		}
+	}()
+
+	count, err := serverconfig.New(_store, aead).EncryptAll()
+	if err != nil {
+		return fmt.Errorf("could not encrypt server config: %w", err)
+	}
+
+	log.Info().Msgf("encrypted %d server config values", count)
+	return nil
+}
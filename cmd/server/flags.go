@@ -21,6 +21,8 @@ import (
 
 	"github.com/urfave/cli/v3"
 
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/tokenhash"
 	host_matcher "go.woodpecker-ci.org/woodpecker/v3/server/services/utils/hostmatcher"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/constant"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/logger"
@@ -39,6 +41,11 @@ var flags = append([]cli.Flag{
 		Aliases: []string{"log-xorm-sql"}, // TODO: remove in v4.0.0
 		Usage:   "enable logging of sql commands",
 	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_SLOW_QUERY_THRESHOLD"),
+		Name:    "db-slow-query-threshold",
+		Usage:   "log sql commands taking longer than this duration, zero disables the logging",
+	},
 	&cli.IntFlag{
 		Sources: cli.EnvVars("WOODPECKER_DATABASE_MAX_CONNECTIONS"),
 		Name:    "db-max-open-connections",
@@ -57,12 +64,50 @@ var flags = append([]cli.Flag{
 		Usage:   "time an active connection is allowed to stay open",
 		Value:   3 * time.Second,
 	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_CONN_MAX_IDLE_TIME"),
+		Name:    "db-conn-max-idle-time",
+		Usage:   "time a connection is allowed to stay idle before it is closed, 0 keeps idle connections open",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_REPLICA_MAX_CONNECTIONS"),
+		Name:    "db-replica-max-open-connections",
+		Usage:   "max connections xorm is allowed create to the read replica, defaults to db-max-open-connections",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_REPLICA_IDLE_CONNECTIONS"),
+		Name:    "db-replica-max-idle-connections",
+		Usage:   "amount of connections xorm will hold open to the read replica, defaults to db-max-idle-connections",
+	},
 	&cli.UintFlag{
-		Sources: cli.EnvVars("WOODPECKER_DATABASE_MAX_RETRIES"),
-		Name:    "db-max-retries",
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_CONNECT_RETRIES", "WOODPECKER_DATABASE_MAX_RETRIES"),
+		Name:    "db-connect-retries",
+		Aliases: []string{"db-max-retries"},
 		Usage:   "max number of retries for the initial connection to the database",
 		Value:   10,
 	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_CONNECT_RETRY_INTERVAL"),
+		Name:    "db-connect-retry-interval",
+		Usage:   "initial interval between retries of the initial connection to the database, it grows exponentially",
+		Value:   500 * time.Millisecond,
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_SQLITE_JOURNAL_MODE"),
+		Name:    "db-sqlite-journal-mode",
+		Usage:   "journal mode of sqlite databases ('WAL', 'DELETE', 'TRUNCATE', 'PERSIST', 'MEMORY' or 'OFF')",
+		Value:   "WAL",
+	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_SQLITE_BUSY_TIMEOUT"),
+		Name:    "db-sqlite-busy-timeout",
+		Usage:   "time to wait for a locked sqlite database before failing",
+		Value:   5 * time.Second,
+	},
+	&cli.BoolFlag{
+		Name:  "config-check",
+		Usage: "validate the config, check the database and forge can be reached and exit without starting the server",
+	},
 	&cli.StringFlag{
 		Sources: cli.EnvVars("WOODPECKER_HOST"),
 		Name:    "server-host",
@@ -93,12 +138,40 @@ var flags = append([]cli.Flag{
 	&cli.StringFlag{
 		Sources: cli.EnvVars("WOODPECKER_CUSTOM_CSS_FILE"),
 		Name:    "custom-css-file",
-		Usage:   "file path for the server to serve a custom .CSS file, used for customizing the UI",
+		Usage:   "file path or http(s) url for the server to serve a custom .CSS file, used for customizing the UI",
 	},
 	&cli.StringFlag{
 		Sources: cli.EnvVars("WOODPECKER_CUSTOM_JS_FILE"),
 		Name:    "custom-js-file",
-		Usage:   "file path for the server to serve a custom .JS file, used for customizing the UI",
+		Usage:   "file path or http(s) url for the server to serve a custom .JS file, used for customizing the UI",
+	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_CUSTOM_FILES_CACHE_TTL"),
+		Name:    "custom-files-cache-ttl",
+		Usage:   "how long custom .CSS and .JS files fetched from a url are cached",
+		Value:   10 * time.Minute,
+	},
+	&cli.StringSliceFlag{
+		Sources: cli.EnvVars("WOODPECKER_CORS_ALLOWED_ORIGINS"),
+		Name:    "cors-allowed-origins",
+		Usage:   "origins allowed to send cross-origin requests to the api, '*' allows all origins without credentials",
+		Config: cli.StringConfig{
+			TrimSpace: true,
+		},
+	},
+	&cli.StringSliceFlag{
+		Sources: cli.EnvVars("WOODPECKER_CORS_ALLOWED_METHODS"),
+		Name:    "cors-allowed-methods",
+		Usage:   "methods allowed in cross-origin requests to the api",
+		Value:   []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+		Config: cli.StringConfig{
+			TrimSpace: true,
+		},
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_CORS_ALLOW_CREDENTIALS"),
+		Name:    "cors-allow-credentials",
+		Usage:   "allow cookies in cross-origin requests from the allowed origins",
 	},
 	&cli.StringFlag{
 		Sources: cli.EnvVars("WOODPECKER_GRPC_ADDR"),
@@ -123,14 +196,29 @@ var flags = append([]cli.Flag{
 		Usage:   "metrics server address",
 		Value:   "",
 	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_SHUTDOWN_DRAIN_TIMEOUT"),
+		Name:    "shutdown-drain-timeout",
+		Usage:   "time to wait for the running workflows to finish on shutdown, zero stops the server immediately",
+	},
 	&cli.StringSliceFlag{
 		Sources: cli.EnvVars("WOODPECKER_ADMIN"),
 		Name:    "admin",
-		Usage:   "list of admin users",
+		Usage:   "list of admin users and forge teams (@org/team)",
 		Config: cli.StringConfig{
 			TrimSpace: true,
 		},
 	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_ADMIN_FILE"),
+		Name:    "admin-file",
+		Usage:   "file with one admin user or forge team (@org/team) per line, merged with --admin and reloaded on SIGHUP",
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_ADMIN_REQUIRE_2FA"),
+		Name:    "admin-require-2fa",
+		Usage:   "require admins to verify a TOTP code after login before they get admin permissions",
+	},
 	&cli.StringSliceFlag{
 		Sources: cli.EnvVars("WOODPECKER_ORGS"),
 		Name:    "orgs",
@@ -185,18 +273,38 @@ var flags = append([]cli.Flag{
 		Usage:   "The default docker image to be used when cloning the repo",
 		Value:   constant.DefaultClonePlugin,
 	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_DEFAULT_CLONE_DEPTH"),
+		Name:    "default-clone-depth",
+		Usage:   "The default clone depth of trusted clone plugins, 0 clones the full history",
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_DEFAULT_CLONE_SUBMODULES"),
+		Name:    "default-clone-submodules",
+		Usage:   "Whether trusted clone plugins should clone submodules by default, unset keeps the plugin default",
+	},
 	&cli.Int64Flag{
 		Sources: cli.EnvVars("WOODPECKER_DEFAULT_PIPELINE_TIMEOUT"),
 		Name:    "default-pipeline-timeout",
 		Usage:   "The default time in minutes for a repo in minutes before a pipeline gets killed",
 		Value:   60,
 	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_MAX_ORG_RUNNING_PIPELINES"),
+		Name:    "max-org-running-pipelines",
+		Usage:   "The default max number of pipelines of an organization running at once, 0 means unlimited",
+	},
 	&cli.Int64Flag{
 		Sources: cli.EnvVars("WOODPECKER_MAX_PIPELINE_TIMEOUT"),
 		Name:    "max-pipeline-timeout",
 		Usage:   "The maximum time in minutes you can set in the repo settings before a pipeline gets killed",
 		Value:   120,
 	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_MAX_MATRIX_JOBS"),
+		Name:    "max-matrix-jobs",
+		Usage:   "max number of workflows a pipeline may expand to by its matrix, 0 means unlimited",
+	},
 	&cli.StringSliceFlag{
 		Sources: cli.EnvVars("WOODPECKER_DEFAULT_WORKFLOW_LABELS"),
 		Name:    "default-workflow-labels",
@@ -205,12 +313,104 @@ var flags = append([]cli.Flag{
 			TrimSpace: true,
 		},
 	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_DEFAULT_WORKFLOW_LABELS_FILE"),
+		Name:    "default-workflow-labels-file",
+		Usage:   "path to a YAML or JSON map of default workflow labels, values of default-workflow-labels take precedence",
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_UNMATCHED_LABELS_WARN_ONLY"),
+		Name:    "unmatched-labels-warn-only",
+		Usage:   "only log a warning instead of failing workflows whose labels no registered agent matches",
+	},
 	&cli.DurationFlag{
 		Sources: cli.EnvVars("WOODPECKER_SESSION_EXPIRES"),
 		Name:    "session-expires",
 		Usage:   "session expiration time",
 		Value:   time.Hour * 72,
 	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_SESSION_SLIDING"),
+		Name:    "session-sliding",
+		Usage:   "renew the session of active users before it expires",
+	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_SESSION_MAX_LIFETIME"),
+		Name:    "session-max-lifetime",
+		Usage:   "maximum lifetime of a renewed session since the login",
+		Value:   time.Hour * 24 * 30,
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_COOKIE_SAMESITE"),
+		Name:    "cookie-samesite",
+		Usage:   "SameSite attribute of the session cookie (lax, strict, none)",
+		Value:   "lax",
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_COOKIE_SECURE"),
+		Name:    "cookie-secure",
+		Usage:   "only send the session cookie over HTTPS, defaults to true if the server host uses https",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_COOKIE_DOMAIN"),
+		Name:    "cookie-domain",
+		Usage:   "domain of the session cookie, e.g. to share it with subdomains",
+	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_MEMBERSHIP_CACHE_TTL"),
+		Name:    "membership-cache-ttl",
+		Usage:   "how long the organization membership of a user is cached",
+		Value:   10 * time.Minute,
+	},
+	&cli.FloatFlag{
+		Sources: cli.EnvVars("WOODPECKER_WEBHOOK_RATE_LIMIT"),
+		Name:    "webhook-rate-limit",
+		Usage:   "allowed webhooks per second and repo, 0 disables the limit",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_WEBHOOK_RATE_LIMIT_BURST"),
+		Name:    "webhook-rate-limit-burst",
+		Usage:   "number of webhooks per repo allowed at once above the rate limit",
+		Value:   10,
+	},
+	&cli.FloatFlag{
+		Sources: cli.EnvVars("WOODPECKER_WEBHOOK_GLOBAL_RATE_LIMIT"),
+		Name:    "webhook-global-rate-limit",
+		Usage:   "allowed webhooks per second across all repos, 0 disables the limit",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_WEBHOOK_GLOBAL_RATE_LIMIT_BURST"),
+		Name:    "webhook-global-rate-limit-burst",
+		Usage:   "number of webhooks across all repos allowed at once above the rate limit",
+		Value:   100,
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_WEBHOOK_CONCURRENCY"),
+		Name:    "webhook-concurrency",
+		Usage:   "number of webhooks processed at once, 0 disables the limit",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_WEBHOOK_QUEUE_SIZE"),
+		Name:    "webhook-queue-size",
+		Usage:   "number of webhooks waiting for processing if the concurrency limit is reached, further webhooks are rejected",
+		Value:   100,
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_MAX_PENDING"),
+		Name:    "queue-max-pending",
+		Usage:   "number of pending tasks in the queue above which webhooks are rejected or their pipelines blocked, 0 disables the limit",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_FULL_ACTION"),
+		Name:    "queue-full-action",
+		Usage:   "action for webhooks received while the queue is full: reject or block",
+		Value:   server.QueueFullReject,
+	},
+	&cli.Uint64Flag{
+		Sources: cli.EnvVars("WOODPECKER_MEMBERSHIP_CACHE_SIZE"),
+		Name:    "membership-cache-size",
+		Usage:   "maximum number of cached organization memberships, 0 means unlimited",
+	},
 	&cli.StringSliceFlag{
 		Sources: cli.EnvVars("WOODPECKER_PLUGINS_PRIVILEGED"),
 		Name:    "plugins-privileged",
@@ -219,6 +419,12 @@ var flags = append([]cli.Flag{
 			TrimSpace: true,
 		},
 	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_EXPOSE_SECRETS_TO_FORKS"),
+		Name:    "expose-secrets-to-forks",
+		Usage:   "The default value for passing secrets to pipelines of pull requests from forks.",
+		Value:   true,
+	},
 	&cli.StringSliceFlag{
 		Sources: cli.EnvVars("WOODPECKER_PLUGINS_TRUSTED_CLONE"),
 		Name:    "plugins-trusted-clone",
@@ -239,6 +445,54 @@ var flags = append([]cli.Flag{
 		Sources: cli.EnvVars("WOODPECKER_DOCKER_CONFIG"),
 		Name:    "docker-config",
 	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_SECRET_BACKEND"),
+		Name:    "secret-backend",
+		Usage:   "secret backend to use ('database' or 'vault')",
+		Value:   "database",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_VAULT_ADDR"),
+		Name:    "vault-addr",
+		Usage:   "address of the vault server",
+	},
+	&cli.StringFlag{
+		Sources: cli.NewValueSourceChain(
+			cli.File(os.Getenv("WOODPECKER_VAULT_TOKEN_FILE")),
+			cli.EnvVar("WOODPECKER_VAULT_TOKEN")),
+		Name:  "vault-token",
+		Usage: "token to authenticate at vault",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_VAULT_ROLE_ID"),
+		Name:    "vault-role-id",
+		Usage:   "role id of the vault approle to authenticate with",
+	},
+	&cli.StringFlag{
+		Sources: cli.NewValueSourceChain(
+			cli.File(os.Getenv("WOODPECKER_VAULT_SECRET_ID_FILE")),
+			cli.EnvVar("WOODPECKER_VAULT_SECRET_ID")),
+		Name:  "vault-secret-id",
+		Usage: "secret id of the vault approle to authenticate with",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_VAULT_MOUNT"),
+		Name:    "vault-mount",
+		Usage:   "path of the kv version 2 secrets engine",
+		Value:   "secret",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_VAULT_PREFIX"),
+		Name:    "vault-prefix",
+		Usage:   "path in the secrets engine the secrets are stored below",
+		Value:   "woodpecker",
+	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_VAULT_CACHE_TTL"),
+		Name:    "vault-cache-ttl",
+		Usage:   "how long the secrets fetched from vault are cached",
+		Value:   30 * time.Second,
+	},
 	&cli.StringSliceFlag{
 		Sources: cli.EnvVars("WOODPECKER_ENVIRONMENT"),
 		Name:    "environment",
@@ -253,6 +507,16 @@ var flags = append([]cli.Flag{
 			TrimSpace: true,
 		},
 	},
+	&cli.StringFlag{
+		Sources: cli.NewValueSourceChain(
+			cli.File(os.Getenv("WOODPECKER_SERVER_CONFIG_ENCRYPTION_KEY_FILE")),
+			cli.EnvVar("WOODPECKER_SERVER_CONFIG_ENCRYPTION_KEY")),
+		Name:  "server-config-encryption-key",
+		Usage: "key used to encrypt the server config values like the jwt secret in the database",
+		Config: cli.StringConfig{
+			TrimSpace: true,
+		},
+	},
 	&cli.StringFlag{
 		Sources: cli.NewValueSourceChain(
 			cli.File(os.Getenv("WOODPECKER_AGENT_SECRET_FILE")),
@@ -268,6 +532,23 @@ var flags = append([]cli.Flag{
 		Name:    "disable-user-agent-registration",
 		Usage:   "Disable user registered agents",
 	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_AGENT_IDLE_TIMEOUT"),
+		Name:    "agent-idle-timeout",
+		Usage:   "mark agents as offline if they did not report their health for this duration, 0 disables it",
+		Value:   5 * time.Minute,
+	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_AGENT_DEAD_TIMEOUT"),
+		Name:    "agent-dead-timeout",
+		Usage:   "remove agents registered with the agent secret if they did not report their health for this duration, 0 disables it",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_AGENT_TOKEN_HASH_ALGORITHM"),
+		Name:    "agent-token-hash-algorithm",
+		Usage:   "algorithm used to hash stored agent tokens (sha256, sha3-256, sha512)",
+		Value:   tokenhash.DefaultAlgorithm,
+	},
 	&cli.DurationFlag{
 		Sources: cli.EnvVars("WOODPECKER_KEEPALIVE_MIN_TIME"),
 		Name:    "keepalive-min-time",
@@ -303,6 +584,36 @@ var flags = append([]cli.Flag{
 			TrimSpace: true,
 		},
 	},
+	&cli.StringFlag{
+		Sources: cli.NewValueSourceChain(
+			cli.File(os.Getenv("WOODPECKER_DATABASE_DATASOURCE_REPLICA_FILE")),
+			cli.EnvVar("WOODPECKER_DATABASE_DATASOURCE_REPLICA")),
+		Name:  "db-datasource-replica",
+		Usage: "database driver configuration string of a read replica",
+		Config: cli.StringConfig{
+			TrimSpace: true,
+		},
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_TLS_MODE"),
+		Name:    "db-tls-mode",
+		Usage:   "tls mode of the mysql or postgres connection ('disable', 'require', 'verify-ca' or 'verify-full'), defaults to 'verify-full' if any other tls option is set",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_TLS_CA"),
+		Name:    "db-tls-ca",
+		Usage:   "path of the certificate authority verifying the database server certificate",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_TLS_CERT"),
+		Name:    "db-tls-cert",
+		Usage:   "path of the client certificate used to connect to the database",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_DATABASE_TLS_KEY"),
+		Name:    "db-tls-key",
+		Usage:   "path of the key of the client certificate used to connect to the database",
+	},
 	&cli.StringFlag{
 		Sources: cli.NewValueSourceChain(
 			cli.File(os.Getenv("WOODPECKER_PROMETHEUS_AUTH_TOKEN_FILE")),
@@ -330,6 +641,16 @@ var flags = append([]cli.Flag{
 		Name:    "migrations-allow-long",
 		Value:   false,
 	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_MIGRATIONS_LOCK_TIMEOUT"),
+		Name:    "migrations-lock-timeout",
+		Usage:   "how long to wait for another instance to finish migrating before exiting, 0 waits without limit",
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_SKIP_MIGRATIONS"),
+		Name:    "skip-migrations",
+		Usage:   "do not run database migrations on startup, for migrations run out-of-band",
+	},
 	&cli.BoolFlag{
 		Sources: cli.EnvVars("WOODPECKER_ENABLE_SWAGGER"),
 		Name:    "enable-swagger",
@@ -340,17 +661,162 @@ var flags = append([]cli.Flag{
 		Usage:   "Disable version check in admin web ui.",
 		Name:    "skip-version-check",
 	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_FAIR_SCHEDULING"),
+		Name:    "queue-fair-scheduling",
+		Usage:   "assign tasks of the same priority round-robin across repos instead of in the order they were queued",
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_RESOURCE_SCHEDULING"),
+		Name:    "queue-resource-scheduling",
+		Usage:   "only assign workflows requesting resources to agents reporting enough free cpu and memory",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_PENDING_TTL"),
+		Name:    "queue-pending-ttl",
+		Usage:   "duration a workflow may wait for an agent before it fails, or 'timeout' to use the pipeline timeout of the repo, empty disables the limit",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_BACKEND"),
+		Name:    "queue-backend",
+		Usage:   "queue backend to use ('memory' or 'redis')",
+		Value:   "memory",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_REDIS_ADDR"),
+		Name:    "queue-redis-addr",
+		Usage:   "address (host:port) of the redis server used by the redis queue backend",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_REDIS_USERNAME"),
+		Name:    "queue-redis-username",
+		Usage:   "username for the redis server of the queue",
+	},
+	&cli.StringFlag{
+		Sources: cli.NewValueSourceChain(
+			cli.File(os.Getenv("WOODPECKER_QUEUE_REDIS_PASSWORD_FILE")),
+			cli.EnvVar("WOODPECKER_QUEUE_REDIS_PASSWORD")),
+		Name:  "queue-redis-password",
+		Usage: "password for the redis server of the queue",
+		Config: cli.StringConfig{
+			TrimSpace: true,
+		},
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_REDIS_DB"),
+		Name:    "queue-redis-db",
+		Usage:   "redis database used by the redis queue backend",
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_QUEUE_REDIS_TLS"),
+		Name:    "queue-redis-tls",
+		Usage:   "connect to the redis server of the queue using TLS",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_PUBSUB_BACKEND"),
+		Name:    "pubsub-backend",
+		Usage:   "pubsub backend used to distribute events and logs to the UI ('memory' or 'redis')",
+		Value:   "memory",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_PUBSUB_REDIS_ADDR"),
+		Name:    "pubsub-redis-addr",
+		Usage:   "address (host:port) of the redis server used by the redis pubsub backend",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_PUBSUB_REDIS_USERNAME"),
+		Name:    "pubsub-redis-username",
+		Usage:   "username for the redis server",
+	},
+	&cli.StringFlag{
+		Sources: cli.NewValueSourceChain(
+			cli.File(os.Getenv("WOODPECKER_PUBSUB_REDIS_PASSWORD_FILE")),
+			cli.EnvVar("WOODPECKER_PUBSUB_REDIS_PASSWORD")),
+		Name:  "pubsub-redis-password",
+		Usage: "password for the redis server",
+		Config: cli.StringConfig{
+			TrimSpace: true,
+		},
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_PUBSUB_REDIS_CHANNEL"),
+		Name:    "pubsub-redis-channel",
+		Usage:   "redis channel used to exchange messages between servers",
+		Value:   "woodpecker",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_PUBSUB_REDIS_DB"),
+		Name:    "pubsub-redis-db",
+		Usage:   "redis database used by the redis pubsub backend",
+	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_PUBSUB_REDIS_TLS"),
+		Name:    "pubsub-redis-tls",
+		Usage:   "connect to the redis server using TLS",
+	},
 	&cli.StringFlag{
 		Sources: cli.EnvVars("WOODPECKER_LOG_STORE"),
 		Name:    "log-store",
-		Usage:   "log store to use ('database', 'addon' or 'file')",
+		Usage:   "log store to use ('database', 'addon', 'file' or 's3')",
 		Value:   "database",
 	},
+	&cli.StringSliceFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_STORE_ADDITIONAL"),
+		Name:    "log-store-additional",
+		Usage:   "additional log stores admins can select for single repos ('database', 'addon', 'file' or 's3')",
+	},
 	&cli.StringFlag{
 		Sources: cli.EnvVars("WOODPECKER_LOG_STORE_FILE_PATH"),
 		Name:    "log-store-file-path",
 		Usage:   "directory used for file based log storage or addon executable file path",
 	},
+	&cli.BoolFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_STORE_FILE_COMPRESS"),
+		Name:    "log-store-file-compress",
+		Usage:   "gzip compress the log files of finished steps of the file based log storage",
+		Value:   true,
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_STORE_S3_BUCKET"),
+		Name:    "log-store-s3-bucket",
+		Usage:   "bucket used for s3 based log storage",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_STORE_S3_ENDPOINT"),
+		Name:    "log-store-s3-endpoint",
+		Usage:   "endpoint of a s3 compatible object storage (defaults to AWS S3)",
+	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_STORE_RETENTION"),
+		Name:    "log-store-retention",
+		Usage:   "remove file based logs older than this duration (0 keeps logs forever)",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_STREAM_BUFFER"),
+		Name:    "log-stream-buffer",
+		Usage:   "how many batches of log lines are buffered for each log stream client before lines get dropped",
+		Value:   30,
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_STREAM_REPLAY_LINES"),
+		Name:    "log-stream-replay-lines",
+		Usage:   "how many of the last log lines are replayed when a client connects to a running step (0 replays the whole log)",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_STREAM_REPLAY_BYTES"),
+		Name:    "log-stream-replay-bytes",
+		Usage:   "limit the replayed log lines on connect to this amount of bytes (0 for no limit)",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_MAX_SIZE"),
+		Name:    "log-max-size",
+		Usage:   "max log size of a step in bytes, further lines get dropped (0 for no limit)",
+	},
+	&cli.IntFlag{
+		Sources: cli.EnvVars("WOODPECKER_LOG_MAX_LINES"),
+		Name:    "log-max-lines",
+		Usage:   "max log line count of a step, further lines get dropped (0 for no limit)",
+	},
 	//
 	// backend options for pipeline compiler
 	//
@@ -384,6 +850,18 @@ var flags = append([]cli.Flag{
 		Usage:   "How many retries of fetching the Woodpecker configuration from a forge are done before we fail",
 		Value:   3,
 	},
+	&cli.UintFlag{
+		Sources: cli.EnvVars("WOODPECKER_FORGE_RETRY_MAX"),
+		Name:    "forge-retry-max",
+		Usage:   "how many times forge API calls failing with rate limits or server errors are retried, 0 disables retries",
+		Value:   3,
+	},
+	&cli.DurationFlag{
+		Sources: cli.EnvVars("WOODPECKER_FORGE_RETRY_BACKOFF"),
+		Name:    "forge-retry-backoff",
+		Usage:   "wait before the first retry of a failed forge API call, doubled for every further retry",
+		Value:   time.Second,
+	},
 	//
 	// generic forge settings
 	//
@@ -450,6 +928,16 @@ var flags = append([]cli.Flag{
 			"WOODPECKER_FORGEJO_SKIP_VERIFY",
 			"WOODPECKER_BITBUCKET_SKIP_VERIFY"),
 	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_FORGE_PROXY"),
+		Name:    "forge-proxy",
+		Usage:   "proxy the server uses for requests to the forge, the HTTP(S)_PROXY env vars are used if unset. Format: <scheme>://<host>[:<port>]",
+	},
+	&cli.StringFlag{
+		Sources: cli.EnvVars("WOODPECKER_FORGE_NO_PROXY"),
+		Name:    "forge-no-proxy",
+		Usage:   "comma separated list of hosts that are requested without the forge proxy",
+	},
 	&cli.StringFlag{
 		Sources: cli.EnvVars("WOODPECKER_EXPERT_FORGE_OAUTH_HOST"),
 		Name:    "forge-oauth-host",
@@ -560,10 +1048,10 @@ var flags = append([]cli.Flag{
 	//
 	// expert flags
 	//
-	&cli.StringFlag{
+	&cli.StringSliceFlag{
 		Sources: cli.EnvVars("WOODPECKER_EXPERT_WEBHOOK_HOST"),
 		Name:    "server-webhook-host",
-		Usage:   "fully qualified woodpecker server url, called by the webhooks of the forge. Format: <scheme>://<host>[/<prefix path>]",
+		Usage:   "fully qualified woodpecker server url, called by the webhooks of the forge. Format: [<forge-id>=]<scheme>://<host>[/<prefix path>], can be set per forge",
 	},
 	//
 	// secrets encryption in DB
@@ -59,6 +59,7 @@ func runGrpcServer(ctx context.Context, c *cli.Command, _store store.Store) erro
 	woodpeckerAuthServer := woodpeckerGrpcServer.NewWoodpeckerAuthServer(
 		jwtManager,
 		server.Config.Server.AgentToken,
+		server.Config.Agent.TokenHashAlgorithm,
 		_store,
 	)
 	proto.RegisterWoodpeckerAuthServer(grpcServer, woodpeckerAuthServer)
@@ -72,7 +73,18 @@ func runGrpcServer(ctx context.Context, c *cli.Command, _store store.Store) erro
 			return
 		}
 		log.Info().Msg("terminating grpc service gracefully")
-		grpcServer.GracefulStop()
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			// agents waiting for a workflow keep their streams open, close them
+			log.Info().Msg("closing remaining agent connections")
+			grpcServer.Stop()
+		}
 		log.Info().Msg("grpc service stopped")
 	}()
 
@@ -45,6 +45,12 @@ func main() {
 			Usage:  "ping the server",
 			Action: pinger,
 		},
+		{
+			Name:   "encrypt-server-config",
+			Usage:  "encrypt all server config values in the database with the server config encryption key",
+			Action: encryptServerConfig,
+		},
+		migrateStoreCmd,
 	}
 	app.Flags = flags
 
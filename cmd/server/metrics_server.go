@@ -17,13 +17,18 @@ package main
 import (
 	"context"
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/prometheus/client_golang/prometheus"
 	prometheus_auto "github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/rs/zerolog/log"
 
+	"go.woodpecker-ci.org/woodpecker/v3/pipeline"
 	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 )
 
@@ -64,6 +69,8 @@ func startMetricsCollector(ctx context.Context, _store store.Store) {
 		Help:      "Total number of repos.",
 	})
 
+	queueMetrics := newQueueMetrics(prometheus.DefaultRegisterer)
+
 	go func() {
 		log.Info().Msg("queue metric collector started")
 
@@ -73,6 +80,7 @@ func startMetricsCollector(ctx context.Context, _store store.Store) {
 			waitingSteps.Set(float64(stats.Stats.WaitingOnDeps))
 			runningSteps.Set(float64(stats.Stats.Running))
 			workers.Set(float64(stats.Stats.Workers))
+			queueMetrics.update(stats, time.Now())
 
 			select {
 			case <-ctx.Done():
@@ -106,3 +114,70 @@ func startMetricsCollector(ctx context.Context, _store store.Store) {
 		}
 	}()
 }
+
+// queueMetrics are the queue metrics broken down by the labels of the tasks.
+type queueMetrics struct {
+	pending          *prometheus.GaugeVec
+	running          *prometheus.GaugeVec
+	oldestPendingAge *prometheus.GaugeVec
+}
+
+func newQueueMetrics(reg prometheus.Registerer) *queueMetrics {
+	factory := prometheus_auto.With(reg)
+	return &queueMetrics{
+		pending: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "woodpecker",
+			Subsystem: "queue",
+			Name:      "pending_tasks",
+			Help:      "Number of pending tasks by task labels.",
+		}, []string{"labels"}),
+		running: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "woodpecker",
+			Subsystem: "queue",
+			Name:      "running_tasks",
+			Help:      "Number of running tasks by task labels.",
+		}, []string{"labels"}),
+		oldestPendingAge: factory.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: "woodpecker",
+			Subsystem: "queue",
+			Name:      "oldest_pending_task_age_seconds",
+			Help:      "Age of the oldest pending task by task labels.",
+		}, []string{"labels"}),
+	}
+}
+
+func (m *queueMetrics) update(info queue.InfoT, now time.Time) {
+	m.pending.Reset()
+	m.running.Reset()
+	m.oldestPendingAge.Reset()
+
+	oldest := map[string]int64{}
+	for _, task := range info.Pending {
+		labels := taskMetricLabels(task)
+		m.pending.WithLabelValues(labels).Inc()
+		if task.Created > 0 && (oldest[labels] == 0 || task.Created < oldest[labels]) {
+			oldest[labels] = task.Created
+		}
+	}
+	for labels, created := range oldest {
+		m.oldestPendingAge.WithLabelValues(labels).Set(now.Sub(time.Unix(created, 0)).Seconds())
+	}
+
+	for _, task := range info.Running {
+		m.running.WithLabelValues(taskMetricLabels(task)).Inc()
+	}
+}
+
+// taskMetricLabels returns the labels of a task as sorted "key=value" list.
+// The repo and org labels are left out, as they would create a time series per repo.
+func taskMetricLabels(task *model.Task) string {
+	labels := make([]string, 0, len(task.Labels))
+	for key, value := range task.Labels {
+		if key == pipeline.LabelFilterRepo || key == pipeline.LabelFilterOrg {
+			continue
+		}
+		labels = append(labels, key+"="+value)
+	}
+	slices.Sort(labels)
+	return strings.Join(labels, ",")
+}
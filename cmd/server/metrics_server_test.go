@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+)
+
+func TestQueueMetrics(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics := newQueueMetrics(reg)
+
+	now := time.Unix(1000, 0)
+	amd64 := map[string]string{"platform": "linux/amd64", "repo": "octocat/hello-world", "org-id": "1"}
+	arm64 := map[string]string{"platform": "linux/arm64", "repo": "octocat/hello-world", "org-id": "1"}
+	metrics.update(queue.InfoT{
+		Pending: []*model.Task{
+			{Labels: amd64, Created: 900},
+			{Labels: amd64, Created: 950},
+			{Labels: arm64, Created: 990},
+		},
+		Running: []*model.Task{
+			{Labels: arm64, Created: 800},
+		},
+	}, now)
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+
+	values := map[string]map[string]float64{}
+	for _, family := range families {
+		values[family.GetName()] = map[string]float64{}
+		for _, metric := range family.GetMetric() {
+			require.Len(t, metric.GetLabel(), 1)
+			assert.Equal(t, "labels", metric.GetLabel()[0].GetName())
+			values[family.GetName()][metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+
+	assert.Equal(t, map[string]map[string]float64{
+		"woodpecker_queue_pending_tasks": {
+			"platform=linux/amd64": 2,
+			"platform=linux/arm64": 1,
+		},
+		"woodpecker_queue_oldest_pending_task_age_seconds": {
+			"platform=linux/amd64": 100,
+			"platform=linux/arm64": 10,
+		},
+		"woodpecker_queue_running_tasks": {
+			"platform=linux/arm64": 1,
+		},
+	}, values)
+
+	// label sets without tasks are removed
+	metrics.update(queue.InfoT{}, now)
+	families, err = reg.Gather()
+	require.NoError(t, err)
+	assert.Empty(t, families)
+}
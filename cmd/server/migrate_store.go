@@ -0,0 +1,137 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/datastore"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/logger"
+)
+
+var migrateStoreCmd = &cli.Command{
+	Name:  "migrate-store",
+	Usage: "copy all data from one database into another, e.g. to switch from sqlite to postgres",
+	Description: "Both databases are migrated first, then all tables are copied row by row keeping the ids.\n" +
+		"Rows already present in the target are skipped, so an interrupted run can be started again.\n" +
+		"Stop the server before, so no data is written to the source while copying.",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "from",
+			Usage:    "datasource of the database to copy from",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "from-driver",
+			Usage: "database driver of the source",
+			Value: "sqlite3",
+		},
+		&cli.StringFlag{
+			Name:     "to",
+			Usage:    "datasource of the database to copy into",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:  "to-driver",
+			Usage: "database driver of the target",
+			Value: datastore.DriverPostgres,
+		},
+	},
+	Action: migrateStore,
+}
+
+func migrateStore(ctx context.Context, c *cli.Command) error {
+	if err := logger.SetupGlobalLogger(ctx, c, true); err != nil {
+		return err
+	}
+
+	from, fromDriver := c.String("from"), c.String("from-driver")
+	to, toDriver := c.String("to"), c.String("to-driver")
+	if from == to && fromDriver == toDriver {
+		return errors.New("source and target database must differ")
+	}
+	if fromDriver == "sqlite3" {
+		// do not create an empty source
+		if _, err := os.Stat(from); err != nil {
+			return fmt.Errorf("source: %w", err)
+		}
+	}
+
+	source, err := openMigrationStore(ctx, c, fromDriver, from)
+	if err != nil {
+		return fmt.Errorf("source: %w", err)
+	}
+	defer func() {
+		if err := source.Close(); err != nil {
+			log.Error().Err(err).Msg("could not close source store")
+		}
+	}()
+
+	target, err := openMigrationStore(ctx, c, toDriver, to)
+	if err != nil {
+		return fmt.Errorf("target: %w", err)
+	}
+	defer func() {
+		if err := target.Close(); err != nil {
+			log.Error().Err(err).Msg("could not close target store")
+		}
+	}()
+
+	_, err = datastore.Copy(ctx, source, target, func(tc datastore.TableCopy) {
+		log.Info().Str("table", tc.Table).Int64("copied", tc.Copied).Int64("rows", tc.Target).Msg("copied table")
+	})
+	if err != nil {
+		return fmt.Errorf("could not copy database: %w", err)
+	}
+
+	log.Info().Msg("copied all tables, the row counts of source and target match")
+	return nil
+}
+
+func openMigrationStore(ctx context.Context, c *cli.Command, driver, datasource string) (store.Store, error) {
+	if !datastore.SupportedDriver(driver) {
+		return nil, fmt.Errorf("database driver '%s' not supported", driver)
+	}
+
+	s, err := datastore.NewEngine(&store.Opts{
+		Driver: driver,
+		Config: datasource,
+		XORM: store.XORM{
+			Log:                c.Bool("db-log"),
+			ShowSQL:            c.Bool("db-log-sql"),
+			SlowQueryThreshold: c.Duration("db-slow-query-threshold"),
+			Pool:               setupPool(c),
+		},
+		TLS: setupDatabaseTLS(c),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Ping(); err != nil {
+		return nil, errors.Join(err, s.Close())
+	}
+	if err := s.Migrate(ctx, true, 0); err != nil {
+		return nil, errors.Join(fmt.Errorf("could not migrate datastore: %w", err), s.Close())
+	}
+	return s, nil
+}
@@ -0,0 +1,63 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/datastore"
+)
+
+func TestMigrateStore(t *testing.T) {
+	if !datastore.SupportedDriver("sqlite3") {
+		t.Skip("sqlite3 is not supported")
+	}
+
+	run := func(args ...string) error {
+		cmd := &cli.Command{
+			Flags:    flags,
+			Commands: []*cli.Command{migrateStoreCmd},
+			Action:   func(context.Context, *cli.Command) error { return nil },
+		}
+		return cmd.Run(t.Context(), append([]string{"woodpecker-server", "migrate-store"}, args...))
+	}
+
+	from := filepath.Join(t.TempDir(), "source.sqlite")
+	to := filepath.Join(t.TempDir(), "target.sqlite")
+
+	assert.ErrorContains(t, run("--from", from, "--to", to, "--to-driver", "sqlite3"), "no such file")
+	assert.ErrorContains(t, run("--from", from, "--to", from, "--to-driver", "sqlite3"), "must differ")
+
+	source, err := openMigrationStore(t.Context(), &cli.Command{}, "sqlite3", from)
+	require.NoError(t, err)
+	require.NoError(t, source.CreateUser(&model.User{Login: "octocat", ForgeRemoteID: "1", Hash: "1"}))
+	require.NoError(t, source.Close())
+
+	require.NoError(t, run("--from", from, "--to", to, "--to-driver", "sqlite3"))
+
+	target, err := openMigrationStore(t.Context(), &cli.Command{}, "sqlite3", to)
+	require.NoError(t, err)
+	defer target.Close()
+	user, err := target.GetUser(1)
+	require.NoError(t, err)
+	assert.Equal(t, "octocat", user.Login)
+}
@@ -18,6 +18,107 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/agent-tokens": {
+            "get": {
+                "description": "Lists the shared tokens agents can register with in addition to the agent secret of the server config.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "List agent tokens",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/AgentToken"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new shared token agents can register with. Used to roll out a new agent secret without restarting all agents at once. The token is only returned once, as only its hash is stored.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Create an agent token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/AgentToken"
+                        }
+                    }
+                }
+            }
+        },
+        "/agent-tokens/{token_id}": {
+            "delete": {
+                "description": "Removes a shared agent token. Fails if agents are still registered with it, unless force is set.\nForcing it disconnects these agents, they have to register with another token.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Agents"
+                ],
+                "summary": "Retire an agent token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the agent token's id",
+                        "name": "token_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "remove the token even if agents still use it",
+                        "name": "force",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
         "/agents": {
             "get": {
                 "produces": [
@@ -64,7 +165,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Creates a new agent with a random token",
+                "description": "Creates a new agent with a random token. The token is only returned once, as only its hash is stored",
                 "produces": [
                     "application/json"
                 ],
@@ -790,9 +891,9 @@ const docTemplate = `{
         },
         "/healthz": {
             "get": {
-                "description": "If everything is fine, just a 204 will be returned, a 500 signals server state is unhealthy.",
+                "description": "If everything is fine, just a 204 will be returned, a 503 signals server state is unhealthy.\nThe database, the queue and all forges are checked, the body names the failing ones.\nThe result is cached for a few seconds.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
                     "System"
@@ -802,8 +903,19 @@ const docTemplate = `{
                     "204": {
                         "description": "No Content"
                     },
-                    "500": {
-                        "description": "Internal Server Error"
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "errors": {
+                                    "type": "object",
+                                    "additionalProperties": {
+                                        "type": "string"
+                                    }
+                                }
+                            }
+                        }
                     }
                 }
             }
@@ -835,6 +947,66 @@ const docTemplate = `{
                 }
             }
         },
+        "/info": {
+            "get": {
+                "description": "Endpoint returns the server version, the kind of the main forge and the enabled features.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Get server information",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/ServerInfo"
+                        }
+                    }
+                }
+            }
+        },
+        "/jwt-secret/rotate": {
+            "post": {
+                "description": "Creates a new secret to sign server issued tokens. Tokens signed with the previous secret stay valid for the grace period. Requires admin rights.",
+                "tags": [
+                    "System"
+                ],
+                "summary": "Rotate the JWT secret",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "duration the previous secret stays valid (default 24h)",
+                        "name": "grace_period",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
         "/log-level": {
             "get": {
                 "description": "Endpoint returns the current logging level. Requires admin rights.",
@@ -907,6 +1079,155 @@ const docTemplate = `{
                 }
             }
         },
+        "/maintenance": {
+            "get": {
+                "description": "Endpoint returns whether the maintenance mode is enabled. Requires admin rights.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Current maintenance mode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "enabled": {
+                                    "type": "boolean"
+                                }
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Endpoint enables or disables the maintenance mode. While it is enabled the server is read-only\nand rejects webhooks and all other writes. Requires admin rights.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Set maintenance mode",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "whether the maintenance mode is enabled",
+                        "name": "maintenance",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "enabled": {
+                                    "type": "boolean"
+                                }
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "properties": {
+                                "enabled": {
+                                    "type": "boolean"
+                                }
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/migrations": {
+            "get": {
+                "description": "Endpoint returns all known store migrations in execution order and whether they were applied. Requires admin rights.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "List store migrations",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/MigrationStatus"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/migrations/{version}/rollback": {
+            "post": {
+                "description": "Endpoint reverts the given store migration. Only the last applied migration can be rolled back\nand only if it provides a rollback. Requires admin rights.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "System"
+                ],
+                "summary": "Rollback a store migration",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the migration id",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            }
+        },
         "/orgs": {
             "get": {
                 "description": "Returns all registered orgs in the system. Requires admin rights.",
@@ -1061,6 +1382,50 @@ const docTemplate = `{
                         }
                     }
                 }
+            },
+            "patch": {
+                "description": "Updates the feature flag overrides of the given org. Requires admin rights.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Orgs"
+                ],
+                "summary": "Update an organization",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the organization's id",
+                        "name": "org_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the org's data",
+                        "name": "org",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/OrgPatch"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Org"
+                        }
+                    }
+                }
             }
         },
         "/orgs/{org_id}/agents": {
@@ -1116,7 +1481,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Creates a new agent with a random token, scoped to the specified organization",
+                "description": "Creates a new agent with a random token, scoped to the specified organization. The token is only returned once, as only its hash is stored",
                 "produces": [
                     "application/json"
                 ],
@@ -1775,6 +2140,114 @@ const docTemplate = `{
                 }
             }
         },
+        "/pipelines/query": {
+            "get": {
+                "description": "Query pipelines across all active repositories the user has access to. Admins can query the pipelines of all active repositories.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Pipelines"
+                ],
+                "summary": "Query pipelines",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "for response pagination, page offset number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 50,
+                        "description": "for response pagination, max items per page",
+                        "name": "perPage",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter pipelines by repository ids (comma separated)",
+                        "name": "repo_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter pipelines by webhook events (comma separated)",
+                        "name": "event",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter pipelines by status (comma separated)",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "filter pipelines by author (comma separated)",
+                        "name": "author",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return pipelines created before this RFC3339 date",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return pipelines created after this RFC3339 date",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only return finished pipelines running at least this many seconds",
+                        "name": "min_duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only return finished pipelines running at most this many seconds",
+                        "name": "max_duration",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "created",
+                        "description": "sort pipelines by created, started, finished or duration",
+                        "name": "sort_by",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "sort pipelines in ascending order",
+                        "name": "ascending",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/Feed"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/queue/info": {
             "get": {
                 "description": "Returns pipeline queue information with agent details",
@@ -2613,7 +3086,95 @@ const docTemplate = `{
                 "tags": [
                     "Repository cron jobs"
                 ],
-                "summary": "Delete a cron job",
+                "summary": "Delete a cron job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the repository id",
+                        "name": "repo_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cron job id",
+                        "name": "cron",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    }
+                }
+            },
+            "patch": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository cron jobs"
+                ],
+                "summary": "Update a cron job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "the repository id",
+                        "name": "repo_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the cron job id",
+                        "name": "cron",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "the cron job data",
+                        "name": "cronJob",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/Cron"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/Cron"
+                        }
+                    }
+                }
+            }
+        },
+        "/repos/{repo_id}/hook/prune": {
+            "post": {
+                "description": "Webhooks are only accepted with the current secret afterwards, even if the grace period of a previous secret is not over yet.",
+                "tags": [
+                    "Repositories"
+                ],
+                "summary": "Revoke the previous webhook secrets of a repository",
                 "parameters": [
                     {
                         "type": "string",
@@ -2629,13 +3190,6 @@ const docTemplate = `{
                         "name": "repo_id",
                         "in": "path",
                         "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "the cron job id",
-                        "name": "cron",
-                        "in": "path",
-                        "required": true
                     }
                 ],
                 "responses": {
@@ -2643,15 +3197,15 @@ const docTemplate = `{
                         "description": "No Content"
                     }
                 }
-            },
-            "patch": {
-                "produces": [
-                    "application/json"
-                ],
+            }
+        },
+        "/repos/{repo_id}/hook/rotate": {
+            "post": {
+                "description": "Creates a new secret to verify the webhooks of the repository and re-registers the webhook. Webhooks using the previous secret are accepted for the grace period.",
                 "tags": [
-                    "Repository cron jobs"
+                    "Repositories"
                 ],
-                "summary": "Update a cron job",
+                "summary": "Rotate the webhook secret of a repository",
                 "parameters": [
                     {
                         "type": "string",
@@ -2670,27 +3224,14 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "the cron job id",
-                        "name": "cron",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "the cron job data",
-                        "name": "cronJob",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/Cron"
-                        }
+                        "description": "duration the previous secret is accepted (default 1h)",
+                        "name": "grace_period",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/Cron"
-                        }
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
@@ -3130,6 +3671,12 @@ const docTemplate = `{
                         "description": "override the target deploy value",
                         "name": "deploy_to",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "run the pipeline even if the result of an identical pipeline could be reused",
+                        "name": "force",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -3677,8 +4224,9 @@ const docTemplate = `{
         },
         "/repos/{repo_id}/repair": {
             "post": {
+                "description": "Re-registers the webhook of the repository and syncs its data with the forge. Returns what was fixed.",
                 "produces": [
-                    "text/plain"
+                    "application/json"
                 ],
                 "tags": [
                     "Repositories"
@@ -3702,8 +4250,11 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "No Content"
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/RepoRepairReport"
+                        }
                     }
                 }
             }
@@ -4200,6 +4751,18 @@ const docTemplate = `{
                         "name": "stepID",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "how many of the last log lines to replay on connect (0 replays the whole log)",
+                        "name": "replay_lines",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit the replayed log lines to this amount of bytes (0 for no limit)",
+                        "name": "replay_bytes",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -4238,6 +4801,150 @@ const docTemplate = `{
                 }
             }
         },
+        "/user/2fa/activate": {
+            "post": {
+                "description": "Enables the enrolled second factor of the current user and returns the recovery codes, which are only shown once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Activate the enrolled second factor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "a TOTP code of the enrolled secret",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TwoFactorCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TwoFactorRecoveryCodes"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/enroll": {
+            "post": {
+                "description": "Creates a new TOTP secret for the current user. The second factor is enabled after activating it with a valid code.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Enroll a second factor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TwoFactorEnrollment"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/recovery-codes": {
+            "post": {
+                "description": "Replaces all recovery codes of the current user and returns the new ones, which are only shown once.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Regenerate the recovery codes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "a TOTP code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TwoFactorCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/TwoFactorRecoveryCodes"
+                        }
+                    }
+                }
+            }
+        },
+        "/user/2fa/verify": {
+            "post": {
+                "description": "Verifies a TOTP or recovery code of the current user and returns a new token, which grants admin permissions to admins.\nBrowser sessions get the new session cookie set. Recovery codes can only be used once.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Verify the second factor",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "a TOTP or recovery code",
+                        "name": "code",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/TwoFactorCode"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
         "/user/feed": {
             "get": {
                 "description": "The feed lists the most recent pipeline for the currently authenticated user.",
@@ -4316,6 +5023,48 @@ const docTemplate = `{
                 }
             }
         },
+        "/user/repos/sync": {
+            "post": {
+                "description": "Re-fetch the repositories of the currently authenticated User from the forge, update renamed ones and report\nadded, removed and renamed repositories. Added repositories matching the enable pattern get activated.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "User"
+                ],
+                "summary": "Synchronize user's repositories with the forge",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only synchronize repos of this organization",
+                        "name": "org",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "activate added repos with a full name matching this glob pattern",
+                        "name": "enable",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/RepoSyncReport"
+                        }
+                    }
+                }
+            }
+        },
         "/user/token": {
             "post": {
                 "produces": [
@@ -4584,11 +5333,52 @@ const docTemplate = `{
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/User"
-                        }
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/User"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{login}/2fa": {
+            "delete": {
+                "description": "Removes the second factor and the recovery codes of the given user, e.g. after the user lost access to them.\nAll tokens of the user are revoked, so tokens issued after verifying the removed second factor stop working. Requires admin rights.",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Users"
+                ],
+                "summary": "Reset the second factor of a user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "Bearer \u003cpersonal access token\u003e",
+                        "description": "Insert your personal access token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "the user's login name",
+                        "name": "login",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "specify forge (else default will be used)",
+                        "name": "forge_id",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
                     }
                 }
             }
@@ -4657,6 +5447,10 @@ const docTemplate = `{
                 "no_schedule": {
                     "type": "boolean"
                 },
+                "offline": {
+                    "description": "set if the agent did not report its health for longer than the idle timeout",
+                    "type": "boolean"
+                },
                 "org_id": {
                     "description": "OrgID is counted as unset if set to -1, this is done to ensure a new(Agent) still enforce the OrgID check by default",
                     "type": "integer"
@@ -4667,6 +5461,12 @@ const docTemplate = `{
                 "platform": {
                     "type": "string"
                 },
+                "resource_capacity": {
+                    "$ref": "#/definitions/Resources"
+                },
+                "resource_usage": {
+                    "$ref": "#/definitions/Resources"
+                },
                 "token": {
                     "type": "string"
                 },
@@ -4678,6 +5478,20 @@ const docTemplate = `{
                 }
             }
         },
+        "AgentToken": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
         "Config": {
             "type": "object",
             "properties": {
@@ -4722,6 +5536,13 @@ const docTemplate = `{
                 "schedule": {
                     "description": "@weekly,\t3min, ...",
                     "type": "string"
+                },
+                "variables": {
+                    "description": "additional pipeline variables of the triggered pipelines",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
                 }
             }
         },
@@ -4883,9 +5704,29 @@ const docTemplate = `{
                 "LogEntryProgress"
             ]
         },
+        "MigrationStatus": {
+            "type": "object",
+            "properties": {
+                "applied": {
+                    "type": "boolean"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "reversible": {
+                    "type": "boolean"
+                }
+            }
+        },
         "Org": {
             "type": "object",
             "properties": {
+                "feature_flags": {
+                    "$ref": "#/definitions/OrgFeatureFlags"
+                },
                 "forge_id": {
                     "type": "integer"
                 },
@@ -4900,6 +5741,37 @@ const docTemplate = `{
                 }
             }
         },
+        "OrgFeatureFlags": {
+            "type": "object",
+            "properties": {
+                "allow_pull_requests": {
+                    "type": "boolean"
+                },
+                "default_timeout": {
+                    "type": "integer"
+                },
+                "expose_secrets_to_forks": {
+                    "type": "boolean"
+                },
+                "max_running_pipelines": {
+                    "type": "integer"
+                },
+                "privileged_plugins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "OrgPatch": {
+            "type": "object",
+            "properties": {
+                "feature_flags": {
+                    "$ref": "#/definitions/OrgFeatureFlags"
+                }
+            }
+        },
         "OrgPerm": {
             "type": "object",
             "properties": {
@@ -4949,6 +5821,9 @@ const docTemplate = `{
                 "branch": {
                     "type": "string"
                 },
+                "cache_hit": {
+                    "type": "integer"
+                },
                 "changed_files": {
                     "type": "array",
                     "items": {
@@ -5196,6 +6071,9 @@ const docTemplate = `{
                 "default_branch": {
                     "type": "string"
                 },
+                "expose_secrets_to_forks": {
+                    "type": "boolean"
+                },
                 "forge_id": {
                     "type": "integer"
                 },
@@ -5212,6 +6090,21 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "log_max_lines": {
+                    "type": "integer"
+                },
+                "log_max_size": {
+                    "type": "integer"
+                },
+                "log_store": {
+                    "type": "string"
+                },
+                "max_matrix_jobs": {
+                    "type": "integer"
+                },
+                "max_timeout": {
+                    "type": "integer"
+                },
                 "name": {
                     "type": "string"
                 },
@@ -5221,6 +6114,15 @@ const docTemplate = `{
                         "type": "string"
                     }
                 },
+                "notify_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/StatusValue"
+                    }
+                },
+                "notify_url": {
+                    "type": "string"
+                },
                 "org_id": {
                     "type": "integer"
                 },
@@ -5233,17 +6135,38 @@ const docTemplate = `{
                 "private": {
                     "type": "boolean"
                 },
+                "priority": {
+                    "type": "integer"
+                },
+                "privileged_plugins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "require_approval": {
                     "$ref": "#/definitions/model.ApprovalMode"
                 },
+                "result_cache": {
+                    "type": "boolean"
+                },
                 "timeout": {
                     "type": "integer"
                 },
                 "trusted": {
                     "$ref": "#/definitions/model.TrustedConfiguration"
                 },
+                "trusted_clone_plugins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "visibility": {
                     "$ref": "#/definitions/RepoVisibility"
+                },
+                "workflow_status_checks": {
+                    "type": "boolean"
                 }
             }
         },
@@ -5332,6 +6255,9 @@ const docTemplate = `{
                 "require_approval": {
                     "$ref": "#/definitions/model.ApprovalMode"
                 },
+                "result_cache": {
+                    "type": "boolean"
+                },
                 "timeout": {
                     "type": "integer"
                 },
@@ -5340,6 +6266,9 @@ const docTemplate = `{
                 },
                 "visibility": {
                     "$ref": "#/definitions/RepoVisibility"
+                },
+                "workflow_status_checks": {
+                    "type": "boolean"
                 }
             }
         },
@@ -5370,23 +6299,135 @@ const docTemplate = `{
                 "config_file": {
                     "type": "string"
                 },
+                "expose_secrets_to_forks": {
+                    "type": "boolean"
+                },
+                "log_max_lines": {
+                    "type": "integer"
+                },
+                "log_max_size": {
+                    "type": "integer"
+                },
+                "log_retention": {
+                    "type": "string"
+                },
+                "log_store": {
+                    "type": "string"
+                },
+                "max_matrix_jobs": {
+                    "type": "integer"
+                },
+                "max_timeout": {
+                    "type": "integer"
+                },
                 "netrc_trusted": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
+                "notify_events": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/StatusValue"
+                    }
+                },
+                "notify_secret": {
+                    "type": "string"
+                },
+                "notify_url": {
+                    "type": "string"
+                },
+                "priority": {
+                    "type": "integer"
+                },
+                "privileged_plugins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "require_approval": {
                     "type": "string"
                 },
+                "result_cache": {
+                    "type": "boolean"
+                },
                 "timeout": {
                     "type": "integer"
                 },
                 "trusted": {
                     "$ref": "#/definitions/model.TrustedConfigurationPatch"
                 },
+                "trusted_clone_plugins": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
                 "visibility": {
                     "type": "string"
+                },
+                "workflow_status_checks": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "RepoRename": {
+            "type": "object",
+            "properties": {
+                "from": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
+                }
+            }
+        },
+        "RepoRepairReport": {
+            "type": "object",
+            "properties": {
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "repo_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "RepoSyncReport": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "description": "Added lists the repositories the user can activate which are not active yet.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "enabled": {
+                    "description": "Enabled lists the added repositories which got activated.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "removed": {
+                    "description": "Removed lists the active repositories which are no longer available at the forge.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "renamed": {
+                    "description": "Renamed lists the repositories whose full name changed at the forge.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/RepoRename"
+                    }
                 }
             }
         },
@@ -5403,6 +6444,17 @@ const docTemplate = `{
                 "VisibilityInternal"
             ]
         },
+        "Resources": {
+            "type": "object",
+            "properties": {
+                "cpu": {
+                    "type": "integer"
+                },
+                "memory": {
+                    "type": "integer"
+                }
+            }
+        },
         "Secret": {
             "type": "object",
             "properties": {
@@ -5435,6 +6487,34 @@ const docTemplate = `{
                 }
             }
         },
+        "ServerFeatures": {
+            "type": "object",
+            "properties": {
+                "log_store": {
+                    "type": "string"
+                },
+                "open_registration": {
+                    "type": "boolean"
+                },
+                "swagger": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "ServerInfo": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "$ref": "#/definitions/ServerFeatures"
+                },
+                "forge": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
         "StatusValue": {
             "type": "string",
             "enum": [
@@ -5550,6 +6630,9 @@ const docTemplate = `{
                 "agent_id": {
                     "type": "integer"
                 },
+                "created": {
+                    "type": "integer"
+                },
                 "dep_status": {
                     "type": "object",
                     "additionalProperties": {
@@ -5574,15 +6657,27 @@ const docTemplate = `{
                 "name": {
                     "type": "string"
                 },
+                "org_id": {
+                    "type": "integer"
+                },
+                "pending_ttl": {
+                    "type": "integer"
+                },
                 "pid": {
                     "type": "integer"
                 },
                 "pipeline_id": {
                     "type": "integer"
                 },
+                "priority": {
+                    "type": "integer"
+                },
                 "repo_id": {
                     "type": "integer"
                 },
+                "resources": {
+                    "$ref": "#/definitions/Resources"
+                },
                 "run_on": {
                     "type": "array",
                     "items": {
@@ -5591,6 +6686,38 @@ const docTemplate = `{
                 }
             }
         },
+        "TwoFactorCode": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
+        "TwoFactorEnrollment": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "description": "Secret is the base32 encoded TOTP secret to enter in an authenticator app.",
+                    "type": "string"
+                },
+                "uri": {
+                    "description": "URI is the otpauth uri of the secret, e.g. to show as QR code.",
+                    "type": "string"
+                }
+            }
+        },
+        "TwoFactorRecoveryCodes": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
         "User": {
             "type": "object",
             "properties": {
@@ -5623,6 +6750,10 @@ const docTemplate = `{
                 "org_id": {
                     "description": "OrgID is the of the user as model.Org.",
                     "type": "integer"
+                },
+                "totp_enabled": {
+                    "description": "TOTPEnabled indicates the user enrolled a TOTP second factor.",
+                    "type": "boolean"
                 }
             }
         },
@@ -5986,6 +7117,9 @@ const docTemplate = `{
                 "pipeline_number": {
                     "type": "integer"
                 },
+                "repo_full_name": {
+                    "type": "string"
+                },
                 "repo_id": {
                     "type": "integer"
                 },
@@ -22,10 +22,10 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"os"
 	"strings"
 	"time"
 
-	"github.com/cenkalti/backoff/v5"
 	"github.com/gin-gonic/gin"
 	prometheus_http "github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/rs/zerolog"
@@ -37,7 +37,9 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/cron"
 	"go.woodpecker-ci.org/woodpecker/v3/server/router"
 	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/agentreaper"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
 	"go.woodpecker-ci.org/woodpecker/v3/server/web"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/logger"
 	"go.woodpecker-ci.org/woodpecker/v3/version"
@@ -58,7 +60,9 @@ func run(ctx context.Context, c *cli.Command) error {
 		return err
 	}
 
-	ctx, ctxCancel := context.WithCancelCause(ctx)
+	// on a termination signal the services keep running until the server is drained
+	signalCtx := ctx
+	ctx, ctxCancel := context.WithCancelCause(context.WithoutCancel(ctx))
 	stopServerFunc = func(err error) {
 		if err != nil {
 			log.Error().Err(err).Msg("shutdown of whole server")
@@ -75,16 +79,12 @@ func run(ctx context.Context, c *cli.Command) error {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	if c.String("server-host") == "" {
-		return fmt.Errorf("WOODPECKER_HOST is not properly configured")
+	if c.Bool("config-check") {
+		return runConfigCheck(signalCtx, c, os.Stdout)
 	}
 
-	if !strings.Contains(c.String("server-host"), "://") {
-		return fmt.Errorf("WOODPECKER_HOST must be <scheme>://<hostname> format")
-	}
-
-	if _, err := url.Parse(c.String("server-host")); err != nil {
-		return fmt.Errorf("could not parse WOODPECKER_HOST: %w", err)
+	if err := errors.Join(validateConfig(c)...); err != nil {
+		return err
 	}
 
 	if strings.Contains(c.String("server-host"), "://localhost") {
@@ -93,15 +93,16 @@ func run(ctx context.Context, c *cli.Command) error {
 		)
 	}
 
-	_store, err := backoff.Retry(ctx,
+	_store, err := setupStoreWithRetry(signalCtx,
 		func() (store.Store, error) {
-			return setupStore(ctx, c)
+			return setupStore(signalCtx, c)
 		},
-		backoff.WithBackOff(backoff.NewExponentialBackOff()),
-		backoff.WithMaxTries(c.Uint("db-max-retries")),
-		backoff.WithNotify(func(err error, delay time.Duration) {
-			log.Error().Msgf("failed to setup store: %v: retry in %v", err, delay)
-		}))
+		c.Uint("db-connect-retries"),
+		c.Duration("db-connect-retry-interval"))
+	if errors.Is(err, types.MigrationLocked) {
+		log.Warn().Msgf("could not acquire the migration lock within %v, assuming another instance is migrating, exiting", c.Duration("migrations-lock-timeout"))
+		return nil
+	}
 	if err != nil {
 		return err
 	}
@@ -112,11 +113,28 @@ func run(ctx context.Context, c *cli.Command) error {
 		}
 	}()
 
+	_store, err = setupServerConfigEncryption(c, _store)
+	if err != nil {
+		return err
+	}
+
 	err = setupEvilGlobals(ctx, c, _store)
 	if err != nil {
 		return fmt.Errorf("can't setup globals: %w", err)
 	}
 
+	go func() {
+		select {
+		case <-signalCtx.Done():
+		case <-ctx.Done():
+			return
+		}
+		if timeout := c.Duration("shutdown-drain-timeout"); timeout > 0 {
+			drain(server.Config.Services.Queue, timeout, drainCheckInterval)
+		}
+		stopServerFunc(nil)
+	}()
+
 	// wait for all services until one do stops with an error
 	serviceWaitingGroup := errgroup.Group{}
 
@@ -124,6 +142,14 @@ func run(ctx context.Context, c *cli.Command) error {
 
 	startMetricsCollector(ctx, _store)
 
+	if c.String("admin-file") != "" {
+		go reloadAdminsOnHangup(ctx, _store, server.Config.Services.Manager, server.Config.Permissions.Admins)
+	}
+
+	if server.Config.Agent.IdleTimeout > 0 || server.Config.Agent.DeadTimeout > 0 {
+		go agentreaper.New(_store, server.Config.Services.Queue, server.Config.Agent.IdleTimeout, server.Config.Agent.DeadTimeout).Run(ctx)
+	}
+
 	serviceWaitingGroup.Go(func() error {
 		log.Info().Msg("starting cron service ...")
 		if err := cron.Run(ctx, _store); err != nil {
@@ -175,6 +201,7 @@ func run(ctx context.Context, c *cli.Command) error {
 		middleware.Logger(time.RFC3339, true),
 		middleware.Version,
 		middleware.Store(_store),
+		middleware.Maintenance,
 	)
 
 	if c.String("server-cert") != "" {
@@ -17,49 +17,106 @@ package main
 
 import (
 	"context"
-	"encoding/base32"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/google/tink/go/subtle/random"
+	"github.com/cenkalti/backoff/v5"
+	"github.com/jellydator/ttlcache/v3"
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
 
 	"go.woodpecker-ci.org/woodpecker/v3/server"
 	"go.woodpecker-ci.org/woodpecker/v3/server/cache"
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge/setup"
 	"go.woodpecker-ci.org/woodpecker/v3/server/logging"
+	loggingRedis "go.woodpecker-ci.org/woodpecker/v3/server/logging/redis"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/pipeline"
 	"go.woodpecker-ci.org/woodpecker/v3/server/pubsub"
+	pubsubRedis "go.woodpecker-ci.org/woodpecker/v3/server/pubsub/redis"
 	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
+	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/header"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/encryption/wrapper/serverconfig"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/jwtsecret"
 	logService "go.woodpecker-ci.org/woodpecker/v3/server/services/log"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/log/addon"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/log/file"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log/limit"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log/routing"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log/s3"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/maintenance"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/permissions"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store/datastore"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+	"go.woodpecker-ci.org/woodpecker/v3/server/web"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
 )
 
 const (
 	queueInfoRefreshInterval = 500 * time.Millisecond
 	storeInfoRefreshInterval = 10 * time.Second
+	orgLimitCacheTTL         = 10 * time.Second
 )
 
 func setupStore(ctx context.Context, c *cli.Command) (store.Store, error) {
+	store, err := openStore(c)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = store.Ping(); err != nil {
+		return nil, errors.Join(err, store.Close())
+	}
+
+	if c.Bool("skip-migrations") {
+		log.Info().Msg("skipping database migrations, they have to be run out-of-band")
+		return store, nil
+	}
+
+	lockTimeout := c.Duration("migrations-lock-timeout")
+	if err := store.Migrate(ctx, c.Bool("migrations-allow-long"), lockTimeout); err != nil {
+		if errors.Is(err, types.MigrationLocked) {
+			// waiting again would not help, another instance is migrating
+			return nil, backoff.Permanent(errors.Join(err, store.Close()))
+		}
+		return nil, errors.Join(fmt.Errorf("could not migrate datastore: %w", err), store.Close())
+	}
+	log.Info().Msg("database migrations finished")
+
+	return store, nil
+}
+
+// openStore connects to the configured database without checking or migrating it.
+func openStore(c *cli.Command) (store.Store, error) {
 	datasource := c.String("db-datasource")
 	driver := c.String("db-driver")
 	xorm := store.XORM{
-		Log:             c.Bool("db-log"),
-		ShowSQL:         c.Bool("db-log-sql"),
-		MaxOpenConns:    c.Int("db-max-open-connections"),
-		MaxIdleConns:    c.Int("db-max-idle-connections"),
-		ConnMaxLifetime: c.Duration("db-max-connection-timeout"),
+		Log:                c.Bool("db-log"),
+		ShowSQL:            c.Bool("db-log-sql"),
+		SlowQueryThreshold: c.Duration("db-slow-query-threshold"),
+		Pool:               setupPool(c),
+	}
+	if err := xorm.Pool.Validate(); err != nil {
+		return nil, backoff.Permanent(fmt.Errorf("invalid database connection pool settings: %w", err))
+	}
+	replicaPool := setupReplicaPool(c, xorm.Pool)
+	if err := replicaPool.Validate(); err != nil {
+		return nil, backoff.Permanent(fmt.Errorf("invalid database replica connection pool settings: %w", err))
+	}
+	tls := setupDatabaseTLS(c)
+	if err := tls.Validate(); err != nil {
+		return nil, backoff.Permanent(fmt.Errorf("invalid database tls settings: %w", err))
 	}
 
 	if driver == "sqlite3" {
@@ -81,25 +138,75 @@ func setupStore(ctx context.Context, c *cli.Command) (store.Store, error) {
 	}
 
 	opts := &store.Opts{
-		Driver: driver,
-		Config: datasource,
-		XORM:   xorm,
+		Driver:        driver,
+		Config:        datasource,
+		ReplicaConfig: c.String("db-datasource-replica"),
+		ReplicaPool:   &replicaPool,
+		XORM:          xorm,
+		SQLite: store.SQLite{
+			JournalMode: c.String("db-sqlite-journal-mode"),
+			BusyTimeout: c.Duration("db-sqlite-busy-timeout"),
+		},
+		TLS: tls,
 	}
 	log.Debug().Str("driver", driver).Any("xorm", xorm).Msg("setting up datastore")
 	store, err := datastore.NewEngine(opts)
 	if err != nil {
 		return nil, fmt.Errorf("could not open datastore: %w", err)
 	}
+	return store, nil
+}
+
+// setupDatabaseTLS returns the tls options of the database connections.
+func setupDatabaseTLS(c *cli.Command) store.TLS {
+	return store.TLS{
+		Mode: c.String("db-tls-mode"),
+		CA:   c.String("db-tls-ca"),
+		Cert: c.String("db-tls-cert"),
+		Key:  c.String("db-tls-key"),
+	}
+}
 
-	if err = store.Ping(); err != nil {
-		return nil, err
+// setupPool returns the connection pool settings of the primary database.
+func setupPool(c *cli.Command) store.Pool {
+	return store.Pool{
+		MaxOpenConns:    c.Int("db-max-open-connections"),
+		MaxIdleConns:    c.Int("db-max-idle-connections"),
+		ConnMaxLifetime: c.Duration("db-max-connection-timeout"),
+		ConnMaxIdleTime: c.Duration("db-conn-max-idle-time"),
 	}
+}
 
-	if err := store.Migrate(ctx, c.Bool("migrations-allow-long")); err != nil {
-		return nil, fmt.Errorf("could not migrate datastore: %w", err)
+// setupReplicaPool returns the connection pool settings of the read replica,
+// settings which are not set explicitly are taken from the primary database.
+func setupReplicaPool(c *cli.Command, primary store.Pool) store.Pool {
+	pool := primary
+	if c.IsSet("db-replica-max-open-connections") {
+		pool.MaxOpenConns = c.Int("db-replica-max-open-connections")
+	}
+	if c.IsSet("db-replica-max-idle-connections") {
+		pool.MaxIdleConns = c.Int("db-replica-max-idle-connections")
 	}
+	return pool
+}
 
-	return store, nil
+// setupStoreWithRetry retries to set up the store with an exponential backoff,
+// e.g. if the database is not ready yet or the migration lock is held by another server.
+func setupStoreWithRetry(ctx context.Context, setup func() (store.Store, error), retries uint, interval time.Duration) (store.Store, error) {
+	attempt := uint(0)
+	b := backoff.NewExponentialBackOff()
+	b.InitialInterval = interval
+
+	return backoff.Retry(ctx,
+		func() (store.Store, error) {
+			attempt++
+			return setup()
+		},
+		backoff.WithBackOff(b),
+		backoff.WithMaxTries(retries+1),
+		backoff.WithNotify(func(err error, delay time.Duration) {
+			log.Warn().Err(err).Msgf("failed to setup store (attempt %d of %d): retry in %v", attempt, retries+1, delay)
+		}))
 }
 
 func checkSqliteFileExist(path string) error {
@@ -111,57 +218,173 @@ func checkSqliteFileExist(path string) error {
 	return err
 }
 
-func setupQueue(ctx context.Context, s store.Store) (queue.Queue, error) {
+func setupQueue(ctx context.Context, c *cli.Command, s store.Store) (queue.Queue, error) {
+	redisConfig := redis.Config{
+		Addr:     c.String("queue-redis-addr"),
+		Username: c.String("queue-redis-username"),
+		Password: c.String("queue-redis-password"),
+		DB:       int(c.Int("queue-redis-db")),
+	}
+	if c.Bool("queue-redis-tls") {
+		redisConfig.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
 	return queue.New(ctx, queue.Config{
-		Backend: queue.TypeMemory,
-		Store:   s,
+		Backend:        queue.Type(c.String("queue-backend")),
+		Store:          s,
+		Redis:          redisConfig,
+		FairScheduling: c.Bool("queue-fair-scheduling"),
+		OrgLimit:       orgRunningPipelinesLimit(s),
+		Expired: func(tasks []*model.Task) {
+			pipeline.FailExpiredTasks(ctx, s, tasks)
+		},
 	})
 }
 
-func setupMembershipService(_ context.Context, _store store.Store) cache.MembershipService {
-	return cache.NewMembershipService(_store)
+// orgRunningPipelinesLimit returns the running pipeline limit of an org with the org overrides applied.
+// The limits are cached, as the queue looks them up whenever a task of an org waits for an agent.
+func orgRunningPipelinesLimit(s store.Store) queue.OrgLimitFn {
+	limits := ttlcache.New(
+		ttlcache.WithTTL[int64, int](orgLimitCacheTTL),
+		ttlcache.WithDisableTouchOnHit[int64, int](),
+	)
+	return func(orgID int64) int {
+		if item := limits.Get(orgID); item != nil {
+			return item.Value()
+		}
+
+		org, err := s.OrgGet(orgID)
+		if err != nil {
+			log.Error().Err(err).Msgf("could not get org %d to look up its running pipeline limit", orgID)
+			org = nil
+		}
+		limit := server.FeatureFlags(org, nil).MaxRunningPipelines
+		limits.Set(orgID, limit, ttlcache.DefaultTTL)
+		return limit
+	}
 }
 
-func setupLogStore(c *cli.Command, s store.Store) (logService.Service, error) {
-	switch c.String("log-store") {
-	case "file":
-		return file.NewLogStore(c.String("log-store-file-path"))
-	case "addon":
-		return addon.Load(c.String("log-store-file-path"))
-	default:
+func setupServerConfigEncryption(c *cli.Command, s store.Store) (store.Store, error) {
+	key := c.String("server-config-encryption-key")
+	if key == "" {
 		return s, nil
 	}
+	aead, err := serverconfig.NewAESCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("could not setup server config encryption: %w", err)
+	}
+	return serverconfig.New(s, aead), nil
 }
 
-const jwtSecretID = "jwt-secret"
+func setupPubsub(ctx context.Context, c *cli.Command) (pubsub.Broker, error) {
+	switch backend := c.String("pubsub-backend"); backend {
+	case "memory":
+		return pubsub.New(), nil
+	case "redis":
+		return pubsubRedis.New(ctx, pubsubRedis.Config{
+			Config:  pubsubRedisConfig(c),
+			Channel: c.String("pubsub-redis-channel"),
+		})
+	default:
+		return nil, fmt.Errorf("unsupported pubsub backend: %s", backend)
+	}
+}
 
-func setupJWTSecret(_store store.Store) (string, error) {
-	jwtSecret, err := _store.ServerConfigGet(jwtSecretID)
-	if errors.Is(err, types.RecordNotExist) {
-		jwtSecret := base32.StdEncoding.EncodeToString(
-			random.GetRandomBytes(32),
-		)
-		err = _store.ServerConfigSet(jwtSecretID, jwtSecret)
-		if err != nil {
-			return "", err
-		}
-		log.Debug().Msg("created jwt secret")
-		return jwtSecret, nil
+// setupLogs returns the multiplexer of the live logs, with the redis pubsub backend
+// the logs are exchanged between the servers as well.
+func setupLogs(ctx context.Context, c *cli.Command) (logging.Log, error) {
+	if c.String("pubsub-backend") != "redis" {
+		return logging.New(), nil
+	}
+	return loggingRedis.New(ctx, loggingRedis.Config{
+		Config:  pubsubRedisConfig(c),
+		Channel: c.String("pubsub-redis-channel") + ":logs",
+	})
+}
+
+func pubsubRedisConfig(c *cli.Command) redis.Config {
+	config := redis.Config{
+		Addr:     c.String("pubsub-redis-addr"),
+		Username: c.String("pubsub-redis-username"),
+		Password: c.String("pubsub-redis-password"),
+		DB:       int(c.Int("pubsub-redis-db")),
 	}
+	if c.Bool("pubsub-redis-tls") {
+		config.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+	return config
+}
 
+func setupMembershipService(_ context.Context, c *cli.Command, _store store.Store) cache.MembershipService {
+	return cache.NewMembershipServiceWithOptions(_store, cache.MembershipOptions{
+		TTL:  c.Duration("membership-cache-ttl"),
+		Size: c.Uint64("membership-cache-size"),
+	})
+}
+
+func setupLogStore(ctx context.Context, c *cli.Command, s store.Store) (logService.Service, error) {
+	logStore, err := newLogStore(ctx, c, s, c.String("log-store"))
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	names := c.StringSlice("log-store-additional")
+	if len(names) == 0 {
+		return logStore, nil
 	}
+	stores := make(map[string]logService.Service, len(names))
+	for _, name := range names {
+		if name == c.String("log-store") {
+			stores[name] = logStore
+			continue
+		}
+		if stores[name], err = newLogStore(ctx, c, s, name); err != nil {
+			return nil, fmt.Errorf("could not setup additional log store '%s': %w", name, err)
+		}
+	}
+	return routing.NewLogStore(logStore, stores, s), nil
+}
 
-	return jwtSecret, nil
+func newLogStore(ctx context.Context, c *cli.Command, s store.Store, name string) (logService.Service, error) {
+	switch name {
+	case "file":
+		logStore, err := file.NewLogStore(c.String("log-store-file-path"), c.Bool("log-store-file-compress"))
+		if err != nil {
+			return nil, err
+		}
+		// the pruner also runs without a global retention, as repos can override it
+		go file.NewPruner(c.String("log-store-file-path"), s, c.Duration("log-store-retention")).Run(ctx)
+		return logStore, nil
+	case "s3":
+		return s3.NewLogStore(s3.Config{
+			Bucket:   c.String("log-store-s3-bucket"),
+			Endpoint: c.String("log-store-s3-endpoint"),
+			Region:   os.Getenv("AWS_REGION"),
+			Credentials: s3.Credentials{
+				AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+				SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+				SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+			},
+		}, s)
+	case "addon":
+		return addon.Load(c.String("log-store-file-path"))
+	default:
+		return s, nil
+	}
 }
 
 func setupEvilGlobals(ctx context.Context, c *cli.Command, s store.Store) (err error) {
 	// services
-	server.Config.Services.Logs = logging.New()
-	server.Config.Services.Pubsub = pubsub.New()
-	server.Config.Services.Membership = setupMembershipService(ctx, s)
-	server.Config.Services.Queue, err = setupQueue(ctx, s)
+	server.Config.Services.Pubsub, err = setupPubsub(ctx, c)
+	if err != nil {
+		return fmt.Errorf("could not setup pubsub: %w", err)
+	}
+	server.Config.Services.Logs, err = setupLogs(ctx, c)
+	if err != nil {
+		return fmt.Errorf("could not setup live logs: %w", err)
+	}
+	server.Config.Services.Membership = setupMembershipService(ctx, c, s)
+	server.Config.Services.Queue, err = setupQueue(ctx, c, s)
 	if err != nil {
 		return fmt.Errorf("could not setup queue: %w", err)
 	}
@@ -169,13 +392,42 @@ func setupEvilGlobals(ctx context.Context, c *cli.Command, s store.Store) (err e
 	if err != nil {
 		return fmt.Errorf("could not setup service manager: %w", err)
 	}
-	server.Config.Services.LogStore, err = setupLogStore(c, s)
+	server.Config.Logs.Store = c.String("log-store")
+	server.Config.Logs.Stores = c.StringSlice("log-store-additional")
+	server.Config.Services.LogStore, err = setupLogStore(ctx, c, s)
 	if err != nil {
 		return fmt.Errorf("could not setup log store: %w", err)
 	}
 
+	// log streaming
+	server.Config.Logs.StreamBuffer = c.Int("log-stream-buffer")
+	server.Config.Logs.StreamReplayLines = c.Int("log-stream-replay-lines")
+	server.Config.Logs.StreamReplayBytes = c.Int("log-stream-replay-bytes")
+
+	// log limits
+	server.Config.Logs.MaxSize = c.Int("log-max-size")
+	server.Config.Logs.MaxLines = c.Int("log-max-lines")
+	server.Config.Services.LogLimiter = limit.New(s, limit.Limits{
+		MaxSize:  server.Config.Logs.MaxSize,
+		MaxLines: server.Config.Logs.MaxLines,
+	})
+
 	// agents
 	server.Config.Agent.DisableUserRegisteredAgentRegistration = c.Bool("disable-user-agent-registration")
+	server.Config.Agent.IdleTimeout = c.Duration("agent-idle-timeout")
+	server.Config.Agent.DeadTimeout = c.Duration("agent-dead-timeout")
+	server.Config.Agent.TokenHashAlgorithm = c.String("agent-token-hash-algorithm")
+	server.Config.Agent.ResourceScheduling = c.Bool("queue-resource-scheduling")
+
+	// webhooks
+	server.Config.Webhook.RateLimit = c.Float("webhook-rate-limit")
+	server.Config.Webhook.RateLimitBurst = c.Int("webhook-rate-limit-burst")
+	server.Config.Webhook.GlobalRateLimit = c.Float("webhook-global-rate-limit")
+	server.Config.Webhook.GlobalRateLimitBurst = c.Int("webhook-global-rate-limit-burst")
+	server.Config.Webhook.Concurrency = c.Int("webhook-concurrency")
+	server.Config.Webhook.QueueSize = c.Int("webhook-queue-size")
+	server.Config.Webhook.QueueMaxPending = c.Int("queue-max-pending")
+	server.Config.Webhook.QueueFullAction = c.String("queue-full-action")
 
 	// authentication
 	server.Config.Pipeline.AuthenticatePublicRepos = c.Bool("authenticate-public-repos")
@@ -184,39 +436,32 @@ func setupEvilGlobals(ctx context.Context, c *cli.Command, s store.Store) (err e
 	server.Config.Pipeline.DefaultAllowPullRequests = c.Bool("default-allow-pull-requests")
 
 	// Approval mode
-	approvalMode := model.ApprovalMode(c.String("default-approval-mode"))
-	if !approvalMode.Valid() {
-		return fmt.Errorf("approval mode %s is not valid", approvalMode)
-	}
-	server.Config.Pipeline.DefaultApprovalMode = approvalMode
+	server.Config.Pipeline.DefaultApprovalMode = model.ApprovalMode(c.String("default-approval-mode"))
 
 	// Cloning
 	server.Config.Pipeline.DefaultClonePlugin = c.String("default-clone-plugin")
+	server.Config.Pipeline.DefaultCloneSettings = defaultCloneSettings(c)
 	server.Config.Pipeline.TrustedClonePlugins = c.StringSlice("plugins-trusted-clone")
 	server.Config.Pipeline.TrustedClonePlugins = append(server.Config.Pipeline.TrustedClonePlugins, server.Config.Pipeline.DefaultClonePlugin)
 
 	// Execution
-	_events := c.StringSlice("default-cancel-previous-pipeline-events")
-	events := make([]model.WebhookEvent, 0, len(_events))
-	for _, v := range _events {
-		e := model.WebhookEvent(v)
-		if err := e.Validate(); err != nil {
-			return err
-		}
-		events = append(events, e)
+	server.Config.Pipeline.DefaultCancelPreviousPipelineEvents, err = parseWebhookEvents(c.StringSlice("default-cancel-previous-pipeline-events"))
+	if err != nil {
+		return err
 	}
-	server.Config.Pipeline.DefaultCancelPreviousPipelineEvents = events
 	server.Config.Pipeline.DefaultTimeout = c.Int64("default-pipeline-timeout")
+	server.Config.Pipeline.PendingTTL, server.Config.Pipeline.PendingTTLFromTimeout, err = parsePendingTTL(c.String("queue-pending-ttl"))
+	if err != nil {
+		return err
+	}
 	server.Config.Pipeline.MaxTimeout = c.Int64("max-pipeline-timeout")
+	server.Config.Pipeline.MaxMatrixJobs = c.Int("max-matrix-jobs")
+	server.Config.Pipeline.MaxOrgRunningPipelines = c.Int("max-org-running-pipelines")
+	server.Config.Pipeline.UnmatchedLabelsWarnOnly = c.Bool("unmatched-labels-warn-only")
 
-	_labels := c.StringSlice("default-workflow-labels")
-	labels := make(map[string]string, len(_labels))
-	for _, v := range _labels {
-		name, value, ok := strings.Cut(v, "=")
-		if !ok {
-			return fmt.Errorf("invalid label filter: %s", v)
-		}
-		labels[name] = value
+	labels, err := loadDefaultWorkflowLabels(c.StringSlice("default-workflow-labels"), c.String("default-workflow-labels-file"))
+	if err != nil {
+		return err
 	}
 	server.Config.Pipeline.DefaultWorkflowLabels = labels
 
@@ -226,19 +471,23 @@ func setupEvilGlobals(ctx context.Context, c *cli.Command, s store.Store) (err e
 	server.Config.Pipeline.Proxy.HTTPS = c.String("backend-https-proxy")
 
 	// server configuration
-	server.Config.Server.JWTSecret, err = setupJWTSecret(s)
+	jwtSecret, err := jwtsecret.Current(s)
 	if err != nil {
 		return fmt.Errorf("could not setup jwt secret: %w", err)
 	}
+	server.SetJWTSecret(jwtSecret)
+	if err := maintenance.Load(s); err != nil {
+		return fmt.Errorf("could not load maintenance mode: %w", err)
+	}
+	go maintenance.Watch(ctx, s, maintenance.ReloadInterval)
 	server.Config.Server.Cert = c.String("server-cert")
 	server.Config.Server.Key = c.String("server-key")
 	server.Config.Server.AgentToken = c.String("agent-secret")
 	serverHost := strings.TrimSuffix(c.String("server-host"), "/")
 	server.Config.Server.Host = serverHost
-	if c.IsSet("server-webhook-host") {
-		server.Config.Server.WebhookHost = c.String("server-webhook-host")
-	} else {
-		server.Config.Server.WebhookHost = serverHost
+	server.Config.Server.WebhookHost, server.Config.Server.WebhookForgeHosts, err = parseWebhookHosts(c.StringSlice("server-webhook-host"), serverHost)
+	if err != nil {
+		return err
 	}
 	server.Config.Server.OAuthHost = serverHost
 	server.Config.Server.Port = c.String("server-addr")
@@ -246,19 +495,31 @@ func setupEvilGlobals(ctx context.Context, c *cli.Command, s store.Store) (err e
 	server.Config.Server.StatusContext = c.String("status-context")
 	server.Config.Server.StatusContextFormat = c.String("status-context-format")
 	server.Config.Server.SessionExpires = c.Duration("session-expires")
+	server.Config.Server.SessionSliding = c.Bool("session-sliding")
+	server.Config.Server.SessionMaxLifetime = c.Duration("session-max-lifetime")
 	u, _ := url.Parse(server.Config.Server.Host)
 	rootPath := strings.TrimSuffix(u.Path, "/")
 	if rootPath != "" && !strings.HasPrefix(rootPath, "/") {
 		rootPath = "/" + rootPath
 	}
 	server.Config.Server.RootPath = rootPath
+	server.Config.Server.Cookie, err = setupCookieOptions(c, rootPath)
+	if err != nil {
+		return err
+	}
 	server.Config.Server.CustomCSSFile = strings.TrimSpace(c.String("custom-css-file"))
 	server.Config.Server.CustomJsFile = strings.TrimSpace(c.String("custom-js-file"))
+	server.Config.Server.CustomFilesCacheTTL = c.Duration("custom-files-cache-ttl")
+	server.Config.Server.CORS, err = setupCORSOptions(c)
+	if err != nil {
+		return err
+	}
 	server.Config.Pipeline.Networks = c.StringSlice("network")
 	server.Config.Pipeline.Volumes = c.StringSlice("volume")
 	server.Config.WebUI.EnableSwagger = c.Bool("enable-swagger")
 	server.Config.WebUI.SkipVersionCheck = c.Bool("skip-version-check")
 	server.Config.Pipeline.PrivilegedPlugins = c.StringSlice("plugins-privileged")
+	server.Config.Pipeline.ExposeSecretsToForks = c.Bool("expose-secrets-to-forks")
 
 	// prometheus
 	server.Config.Prometheus.AuthToken = c.String("prometheus-auth-token")
@@ -266,7 +527,214 @@ func setupEvilGlobals(ctx context.Context, c *cli.Command, s store.Store) (err e
 	// permissions
 	server.Config.Permissions.Open = c.Bool("open")
 	server.Config.Permissions.Admins = permissions.NewAdmins(c.StringSlice("admin"))
+	if adminFile := c.String("admin-file"); adminFile != "" {
+		if err := server.Config.Permissions.Admins.LoadFile(adminFile); err != nil {
+			return fmt.Errorf("could not load admin file: %w", err)
+		}
+	}
+	server.Config.Permissions.AdminRequire2FA = c.Bool("admin-require-2fa")
+	server.Config.Permissions.Admins.SetTeamResolver(permissions.MembershipResolver(server.Config.Services.Membership))
 	server.Config.Permissions.Orgs = permissions.NewOrgs(c.StringSlice("orgs"))
 	server.Config.Permissions.OwnersAllowlist = permissions.NewOwnersAllowlist(c.StringSlice("repo-owners"))
 	return nil
 }
+
+// parseWebhookHosts parses the webhook hosts in the format [<forge-id>=]<url>.
+// It returns the host without forge id, or the server host if there is none, and the hosts per forge.
+func parseWebhookHosts(values []string, serverHost string) (string, map[int64]string, error) {
+	defaultHost := ""
+	forgeHosts := make(map[int64]string)
+	for _, value := range values {
+		host := value
+		forgeID, hasForgeID := int64(0), false
+		if before, after, ok := strings.Cut(value, "="); ok && !strings.Contains(before, "://") {
+			id, err := strconv.ParseInt(before, 10, 64)
+			if err != nil {
+				return "", nil, fmt.Errorf("invalid forge id of webhook host '%s': %w", value, err)
+			}
+			forgeID, hasForgeID, host = id, true, after
+		}
+
+		if !strings.Contains(host, "://") {
+			return "", nil, fmt.Errorf("webhook host '%s' must be <scheme>://<hostname> format", host)
+		}
+		host = strings.TrimSuffix(host, "/")
+
+		switch {
+		case hasForgeID:
+			if _, exists := forgeHosts[forgeID]; exists {
+				return "", nil, fmt.Errorf("webhook host of forge %d is set multiple times", forgeID)
+			}
+			forgeHosts[forgeID] = host
+		case defaultHost != "":
+			return "", nil, fmt.Errorf("only one webhook host without forge id is allowed")
+		default:
+			defaultHost = host
+		}
+	}
+
+	if defaultHost == "" {
+		defaultHost = serverHost
+	}
+	return defaultHost, forgeHosts, nil
+}
+
+func parseWebhookEvents(values []string) ([]model.WebhookEvent, error) {
+	events := make([]model.WebhookEvent, 0, len(values))
+	for _, v := range values {
+		e := model.WebhookEvent(v)
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// setupCookieOptions returns the attributes of the session cookie. The cookie is limited to the root path
+// and is secure by default if the server is served over https.
+func setupCookieOptions(c *cli.Command, rootPath string) (httputil.CookieOptions, error) {
+	sameSite, err := parseSameSite(c.String("cookie-samesite"))
+	if err != nil {
+		return httputil.CookieOptions{}, err
+	}
+
+	opts := httputil.CookieOptions{
+		Path:     rootPath,
+		Domain:   c.String("cookie-domain"),
+		SameSite: sameSite,
+	}
+	if c.IsSet("cookie-secure") {
+		secure := c.Bool("cookie-secure")
+		opts.Secure = &secure
+	} else if strings.HasPrefix(c.String("server-host"), "https://") {
+		secure := true
+		opts.Secure = &secure
+	}
+
+	if sameSite == http.SameSiteNoneMode && (opts.Secure == nil || !*opts.Secure) {
+		return httputil.CookieOptions{}, errors.New("cookie samesite none requires secure cookies")
+	}
+	return opts, nil
+}
+
+// setupCORSOptions returns the cross-origin requests allowed to the api. Credentials are only
+// allowed for explicitly listed origins, so the session cookie is never exposed to any origin.
+func setupCORSOptions(c *cli.Command) (header.CORSOptions, error) {
+	opts := header.CORSOptions{
+		AllowCredentials: c.Bool("cors-allow-credentials"),
+	}
+
+	for _, origin := range c.StringSlice("cors-allowed-origins") {
+		origin = strings.TrimSuffix(origin, "/")
+		if origin == "*" {
+			if opts.AllowCredentials {
+				return header.CORSOptions{}, errors.New("cors credentials can not be allowed for the '*' origin")
+			}
+		} else if u, err := url.Parse(origin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.Path != "" {
+			return header.CORSOptions{}, fmt.Errorf("cors origin '%s' must be <scheme>://<hostname> format", origin)
+		}
+		opts.AllowedOrigins = append(opts.AllowedOrigins, origin)
+	}
+
+	for _, method := range c.StringSlice("cors-allowed-methods") {
+		opts.AllowedMethods = append(opts.AllowedMethods, strings.ToUpper(method))
+	}
+
+	return opts, nil
+}
+
+func parseSameSite(value string) (http.SameSite, error) {
+	switch strings.ToLower(value) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("cookie samesite '%s' is not supported, must be one of: lax, strict, none", value)
+	}
+}
+
+// defaultCloneSettings returns the settings added to steps of trusted clone plugins.
+func defaultCloneSettings(c *cli.Command) map[string]any {
+	settings := make(map[string]any)
+	if depth := c.Int("default-clone-depth"); depth > 0 {
+		settings["depth"] = strconv.Itoa(depth)
+	}
+	if c.IsSet("default-clone-submodules") {
+		settings["recursive"] = strconv.FormatBool(c.Bool("default-clone-submodules"))
+	}
+	return settings
+}
+
+// loadDefaultWorkflowLabels merges the labels of the given YAML or JSON file with the
+// name=value pairs set inline, inline values take precedence.
+func loadDefaultWorkflowLabels(inline []string, file string) (map[string]string, error) {
+	labels := make(map[string]string, len(inline))
+
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read default workflow labels file: %w", err)
+		}
+		// JSON is valid YAML, so both formats are parsed the same way
+		var fileLabels map[string]string
+		if err := yaml.Unmarshal(data, &fileLabels); err != nil {
+			return nil, fmt.Errorf("invalid default workflow labels file %s: %w", file, err)
+		}
+		for name, value := range fileLabels {
+			if name == "" {
+				return nil, fmt.Errorf("invalid default workflow labels file %s: empty label name", file)
+			}
+			labels[name] = value
+		}
+	}
+
+	for _, v := range inline {
+		name, value, ok := strings.Cut(v, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid label filter: %s", v)
+		}
+		labels[name] = value
+	}
+
+	return labels, nil
+}
+
+// pendingTTLFromTimeout is the pending ttl which uses the pipeline timeout of the repo.
+const pendingTTLFromTimeout = "timeout"
+
+// parsePendingTTL parses the pending ttl of workflows, which is either a duration
+// or 'timeout' to use the pipeline timeout of the repo.
+func parsePendingTTL(value string) (time.Duration, bool, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "":
+		return 0, false, nil
+	case pendingTTLFromTimeout:
+		return 0, true, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, false, fmt.Errorf("queue pending ttl must be a duration or '%s': %w", pendingTTLFromTimeout, err)
+	}
+	if ttl < 0 {
+		return 0, false, errors.New("queue pending ttl must not be negative")
+	}
+	return ttl, false, nil
+}
+
+// validateCustomFile checks that a custom file is either a local path or a http(s) url.
+func validateCustomFile(path string) error {
+	if !strings.Contains(path, "://") || web.IsRemoteFile(path) {
+		return nil
+	}
+	u, err := url.Parse(path)
+	if err != nil {
+		return err
+	}
+	return fmt.Errorf("unsupported scheme '%s', only http and https are supported", u.Scheme)
+}
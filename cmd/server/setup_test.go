@@ -0,0 +1,390 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/urfave/cli/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/header"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/datastore"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
+)
+
+func TestSetupStoreWithRetry(t *testing.T) {
+	errNotReady := errors.New("database not ready")
+
+	flakySetup := func(failures int, attempts *int) func() (store.Store, error) {
+		return func() (store.Store, error) {
+			*attempts++
+			if *attempts <= failures {
+				return nil, errNotReady
+			}
+			return mocks.NewMockStore(t), nil
+		}
+	}
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		attempts := 0
+		s, err := setupStoreWithRetry(t.Context(), flakySetup(2, &attempts), 3, time.Millisecond)
+		assert.NoError(t, err)
+		assert.NotNil(t, s)
+		assert.Equal(t, 3, attempts)
+	})
+
+	t.Run("returns last error", func(t *testing.T) {
+		attempts := 0
+		_, err := setupStoreWithRetry(t.Context(), flakySetup(5, &attempts), 2, time.Millisecond)
+		assert.ErrorIs(t, err, errNotReady)
+		assert.Equal(t, 3, attempts)
+	})
+}
+
+func TestSetupStoreMigrations(t *testing.T) {
+	if !datastore.SupportedDriver("sqlite3") {
+		t.Skip("sqlite3 is not supported")
+	}
+
+	setup := func(datasource string, args ...string) (s store.Store, attempts int, err error) {
+		cmd := &cli.Command{
+			Flags: flags,
+			Action: func(ctx context.Context, c *cli.Command) error {
+				s, err = setupStoreWithRetry(ctx, func() (store.Store, error) {
+					attempts++
+					return setupStore(ctx, c)
+				}, 2, time.Millisecond)
+				return nil
+			},
+		}
+		require.NoError(t, cmd.Run(t.Context(), append([]string{"woodpecker-server", "--db-datasource", datasource}, args...)))
+		if s != nil {
+			t.Cleanup(func() { assert.NoError(t, s.Close()) })
+		}
+		return s, attempts, err
+	}
+
+	t.Run("skip migrations", func(t *testing.T) {
+		s, _, err := setup(filepath.Join(t.TempDir(), "woodpecker.sqlite"), "--skip-migrations")
+		require.NoError(t, err)
+		// no tables were created
+		_, err = s.GetUserCount()
+		assert.ErrorContains(t, err, "no such table")
+	})
+
+	t.Run("exit on lock timeout", func(t *testing.T) {
+		datasource := filepath.Join(t.TempDir(), "woodpecker.sqlite")
+		other, _, err := setup(datasource)
+		require.NoError(t, err)
+		ok, err := other.LeaseAcquire(&model.Lease{Name: "migrations", Holder: "other", Expires: time.Now().Add(time.Hour).Unix()}, time.Now().Unix())
+		require.NoError(t, err)
+		require.True(t, ok)
+
+		_, attempts, err := setup(datasource, "--migrations-lock-timeout", "10ms")
+		assert.ErrorIs(t, err, types.MigrationLocked)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestLoadDefaultWorkflowLabels(t *testing.T) {
+	writeFile := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "labels")
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		inline  []string
+		file    string
+		want    map[string]string
+		wantErr bool
+	}{
+		{
+			name:   "inline only",
+			inline: []string{"platform=linux/amd64"},
+			want:   map[string]string{"platform": "linux/amd64"},
+		},
+		{
+			name:   "yaml file with inline precedence",
+			inline: []string{"platform=linux/arm64"},
+			file:   "platform: linux/amd64\nbackend: docker\n",
+			want:   map[string]string{"platform": "linux/arm64", "backend": "docker"},
+		},
+		{
+			name: "json file",
+			file: `{"backend": "docker", "zone": 1}`,
+			want: map[string]string{"backend": "docker", "zone": "1"},
+		},
+		{
+			name:    "nested value",
+			file:    "backend:\n  name: docker\n",
+			wantErr: true,
+		},
+		{
+			name:    "no map",
+			file:    "- backend=docker\n",
+			wantErr: true,
+		},
+		{
+			name:    "invalid inline value",
+			inline:  []string{"platform"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := ""
+			if tt.file != "" {
+				file = writeFile(t, tt.file)
+			}
+			labels, err := loadDefaultWorkflowLabels(tt.inline, file)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, labels)
+		})
+	}
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := loadDefaultWorkflowLabels(nil, filepath.Join(t.TempDir(), "missing"))
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateCustomFile(t *testing.T) {
+	assert.NoError(t, validateCustomFile(""))
+	assert.NoError(t, validateCustomFile("/usr/local/www/woodpecker.css"))
+	assert.NoError(t, validateCustomFile("https://cdn.example.com/woodpecker.css"))
+	assert.NoError(t, validateCustomFile("http://cdn.example.com/woodpecker.css"))
+	assert.EqualError(t, validateCustomFile("ftp://cdn.example.com/woodpecker.css"), "unsupported scheme 'ftp', only http and https are supported")
+}
+
+func TestParsePendingTTL(t *testing.T) {
+	ttl, fromTimeout, err := parsePendingTTL("")
+	assert.NoError(t, err)
+	assert.Zero(t, ttl)
+	assert.False(t, fromTimeout)
+
+	ttl, fromTimeout, err = parsePendingTTL("timeout")
+	assert.NoError(t, err)
+	assert.Zero(t, ttl)
+	assert.True(t, fromTimeout)
+
+	ttl, fromTimeout, err = parsePendingTTL("2h")
+	assert.NoError(t, err)
+	assert.Equal(t, 2*time.Hour, ttl)
+	assert.False(t, fromTimeout)
+
+	_, _, err = parsePendingTTL("-1h")
+	assert.Error(t, err)
+	_, _, err = parsePendingTTL("soon")
+	assert.Error(t, err)
+}
+
+func TestSetupReplicaPool(t *testing.T) {
+	primary := store.Pool{MaxOpenConns: 100, MaxIdleConns: 2, ConnMaxLifetime: time.Second}
+
+	run := func(args ...string) store.Pool {
+		var pool store.Pool
+		cmd := &cli.Command{
+			Flags: flags,
+			Action: func(_ context.Context, c *cli.Command) error {
+				pool = setupReplicaPool(c, primary)
+				return nil
+			},
+		}
+		assert.NoError(t, cmd.Run(t.Context(), append([]string{"woodpecker-server"}, args...)))
+		return pool
+	}
+
+	assert.Equal(t, primary, run())
+	assert.Equal(t, store.Pool{MaxOpenConns: 20, MaxIdleConns: 10, ConnMaxLifetime: time.Second},
+		run("--db-replica-max-open-connections", "20", "--db-replica-max-idle-connections", "10"))
+}
+
+func TestDefaultCloneSettings(t *testing.T) {
+	run := func(args ...string) map[string]any {
+		var settings map[string]any
+		cmd := &cli.Command{
+			Flags: flags,
+			Action: func(_ context.Context, c *cli.Command) error {
+				settings = defaultCloneSettings(c)
+				return nil
+			},
+		}
+		assert.NoError(t, cmd.Run(t.Context(), append([]string{"woodpecker-server"}, args...)))
+		return settings
+	}
+
+	assert.Empty(t, run())
+	assert.Equal(t, map[string]any{"depth": "50"}, run("--default-clone-depth", "50"))
+	assert.Equal(t, map[string]any{"recursive": "false"}, run("--default-clone-submodules=false"))
+	assert.Equal(t, map[string]any{"depth": "1", "recursive": "true"}, run("--default-clone-depth", "1", "--default-clone-submodules"))
+}
+
+func TestParseWebhookHosts(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      []string
+		wantDefault string
+		wantForges  map[int64]string
+		wantErr     string
+	}{
+		{
+			name:        "server host",
+			wantDefault: "https://ci.example.com",
+			wantForges:  map[int64]string{},
+		},
+		{
+			name:        "single host",
+			values:      []string{"https://hooks.example.com/"},
+			wantDefault: "https://hooks.example.com",
+			wantForges:  map[int64]string{},
+		},
+		{
+			name:        "multiple hosts",
+			values:      []string{"https://ci.example.com", "2=http://woodpecker.internal:8000", "3=https://ci.internal?x=1"},
+			wantDefault: "https://ci.example.com",
+			wantForges:  map[int64]string{2: "http://woodpecker.internal:8000", 3: "https://ci.internal?x=1"},
+		},
+		{
+			name:        "forge hosts only",
+			values:      []string{"2=http://woodpecker.internal:8000"},
+			wantDefault: "https://ci.example.com",
+			wantForges:  map[int64]string{2: "http://woodpecker.internal:8000"},
+		},
+		{
+			name:    "multiple default hosts",
+			values:  []string{"https://ci.example.com", "http://woodpecker.internal"},
+			wantErr: "only one webhook host without forge id is allowed",
+		},
+		{
+			name:    "duplicate forge",
+			values:  []string{"2=https://ci.example.com", "2=http://woodpecker.internal"},
+			wantErr: "webhook host of forge 2 is set multiple times",
+		},
+		{
+			name:    "invalid forge id",
+			values:  []string{"gitea=https://ci.example.com"},
+			wantErr: "invalid forge id of webhook host 'gitea=https://ci.example.com': strconv.ParseInt: parsing \"gitea\": invalid syntax",
+		},
+		{
+			name:    "invalid host",
+			values:  []string{"ci.example.com"},
+			wantErr: "webhook host 'ci.example.com' must be <scheme>://<hostname> format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defaultHost, forgeHosts, err := parseWebhookHosts(tt.values, "https://ci.example.com")
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantDefault, defaultHost)
+			assert.Equal(t, tt.wantForges, forgeHosts)
+		})
+	}
+}
+
+func TestSetupCookieOptions(t *testing.T) {
+	run := func(args ...string) (httputil.CookieOptions, error) {
+		var (
+			opts httputil.CookieOptions
+			err  error
+		)
+		cmd := &cli.Command{
+			Flags: flags,
+			Action: func(_ context.Context, c *cli.Command) error {
+				opts, err = setupCookieOptions(c, "/ci")
+				return nil
+			},
+		}
+		assert.NoError(t, cmd.Run(t.Context(), append([]string{"woodpecker-server"}, args...)))
+		return opts, err
+	}
+
+	opts, err := run()
+	assert.NoError(t, err)
+	assert.Equal(t, httputil.CookieOptions{Path: "/ci", SameSite: http.SameSiteLaxMode}, opts)
+
+	opts, err = run("--server-host", "https://ci.example.com")
+	assert.NoError(t, err)
+	if assert.NotNil(t, opts.Secure) {
+		assert.True(t, *opts.Secure)
+	}
+
+	opts, err = run("--server-host", "https://ci.example.com", "--cookie-secure=false")
+	assert.NoError(t, err)
+	if assert.NotNil(t, opts.Secure) {
+		assert.False(t, *opts.Secure)
+	}
+
+	opts, err = run("--cookie-samesite", "strict", "--cookie-domain", "example.com")
+	assert.NoError(t, err)
+	assert.Equal(t, http.SameSiteStrictMode, opts.SameSite)
+	assert.Equal(t, "example.com", opts.Domain)
+
+	_, err = run("--cookie-samesite", "none")
+	assert.Error(t, err)
+	_, err = run("--cookie-samesite", "none", "--cookie-secure")
+	assert.NoError(t, err)
+	_, err = run("--cookie-samesite", "invalid")
+	assert.Error(t, err)
+}
+
+func TestSetupCORSOptions(t *testing.T) {
+	run := func(args ...string) (header.CORSOptions, error) {
+		var (
+			opts header.CORSOptions
+			err  error
+		)
+		cmd := &cli.Command{
+			Flags: flags,
+			Action: func(_ context.Context, c *cli.Command) error {
+				opts, err = setupCORSOptions(c)
+				return nil
+			},
+		}
+		assert.NoError(t, cmd.Run(t.Context(), append([]string{"woodpecker-server"}, args...)))
+		return opts, err
+	}
+
+	opts, err := run()
+	assert.NoError(t, err)
+	assert.Empty(t, opts.AllowedOrigins)
+	assert.Equal(t, []string{"GET", "POST", "PUT", "PATCH", "DELETE"}, opts.AllowedMethods)
+
+	opts, err = run("--cors-allowed-origins", "https://dashboard.example.com/, http://localhost:3000", "--cors-allowed-methods", "get", "--cors-allow-credentials")
+	assert.NoError(t, err)
+	assert.Equal(t, header.CORSOptions{
+		AllowedOrigins:   []string{"https://dashboard.example.com", "http://localhost:3000"},
+		AllowedMethods:   []string{"GET"},
+		AllowCredentials: true,
+	}, opts)
+
+	_, err = run("--cors-allowed-origins", "*")
+	assert.NoError(t, err)
+	_, err = run("--cors-allowed-origins", "*", "--cors-allow-credentials")
+	assert.Error(t, err)
+	_, err = run("--cors-allowed-origins", "dashboard.example.com")
+	assert.Error(t, err)
+	_, err = run("--cors-allowed-origins", "https://dashboard.example.com/ui")
+	assert.Error(t, err)
+}
@@ -92,6 +92,7 @@ type Compiler struct {
 	registries              []Registry
 	secrets                 map[string]Secret
 	defaultClonePlugin      string
+	defaultCloneSettings    map[string]any
 	trustedClonePlugins     []string
 	securityTrustedPipeline bool
 }
@@ -159,6 +160,9 @@ func (c *Compiler) Compile(conf *yaml_types.Workflow) (*backend_types.Config, er
 			Settings:    cloneSettings,
 			Environment: make(map[string]any),
 		}
+		if container.IsTrustedCloneImage(c.trustedClonePlugins) {
+			maps.Copy(container.Settings, c.defaultCloneSettings)
+		}
 		for k, v := range c.cloneEnv {
 			container.Environment[k] = v
 		}
@@ -181,6 +185,10 @@ func (c *Compiler) Compile(conf *yaml_types.Workflow) (*backend_types.Config, er
 
 			stage := new(backend_types.Stage)
 
+			if container.IsTrustedCloneImage(c.trustedClonePlugins) {
+				container = c.withDefaultCloneSettings(container)
+			}
+
 			step, err := c.createProcess(container, conf, backend_types.StepTypeClone)
 			if err != nil {
 				return nil, err
@@ -264,3 +272,18 @@ func (c *Compiler) Compile(conf *yaml_types.Workflow) (*backend_types.Config, er
 
 	return config, nil
 }
+
+// withDefaultCloneSettings returns a copy of the clone container with the
+// default clone settings added, settings set in the pipeline take precedence.
+func (c *Compiler) withDefaultCloneSettings(container *yaml_types.Container) *yaml_types.Container {
+	if len(c.defaultCloneSettings) == 0 {
+		return container
+	}
+
+	settings := maps.Clone(c.defaultCloneSettings)
+	maps.Copy(settings, container.Settings)
+
+	clone := *container
+	clone.Settings = settings
+	return &clone
+}
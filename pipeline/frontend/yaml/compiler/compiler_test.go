@@ -518,3 +518,64 @@ func TestCompilerCompilePrivileged(t *testing.T) {
 	assert.False(t, backConf.Stages[0].Steps[1].Privileged)
 	assert.False(t, backConf.Stages[0].Steps[2].Privileged)
 }
+
+func TestCompilerCompileDefaultCloneSettings(t *testing.T) {
+	defaultCloneSettings := WithDefaultCloneSettings(map[string]any{
+		"depth":     "50",
+		"recursive": "false",
+	})
+
+	t.Run("default clone step", func(t *testing.T) {
+		backConf, err := New(defaultCloneSettings).Compile(&yaml_types.Workflow{})
+		assert.NoError(t, err)
+
+		assert.Len(t, backConf.Stages, 1)
+		clone := backConf.Stages[0].Steps[0]
+		assert.Equal(t, backend_types.StepTypeClone, clone.Type)
+		assert.Equal(t, "50", clone.Environment["PLUGIN_DEPTH"])
+		assert.Equal(t, "false", clone.Environment["PLUGIN_RECURSIVE"])
+	})
+
+	t.Run("untrusted default clone plugin", func(t *testing.T) {
+		backConf, err := New(
+			defaultCloneSettings,
+			WithDefaultClonePlugin("example.com/custom/clone"),
+		).Compile(&yaml_types.Workflow{})
+		assert.NoError(t, err)
+
+		clone := backConf.Stages[0].Steps[0]
+		assert.Equal(t, "0", clone.Environment["PLUGIN_DEPTH"])
+		assert.NotContains(t, clone.Environment, "PLUGIN_RECURSIVE")
+	})
+
+	t.Run("pipeline overrides trusted clone plugin", func(t *testing.T) {
+		container := &yaml_types.Container{
+			Name:     "git",
+			Image:    constant.DefaultClonePlugin,
+			Settings: map[string]any{"depth": "1"},
+		}
+		backConf, err := New(defaultCloneSettings).Compile(&yaml_types.Workflow{
+			Clone: yaml_types.ContainerList{ContainerList: []*yaml_types.Container{container}},
+		})
+		assert.NoError(t, err)
+
+		clone := backConf.Stages[0].Steps[0]
+		assert.Equal(t, "1", clone.Environment["PLUGIN_DEPTH"])
+		assert.Equal(t, "false", clone.Environment["PLUGIN_RECURSIVE"])
+		assert.Equal(t, map[string]any{"depth": "1"}, container.Settings)
+	})
+
+	t.Run("untrusted clone plugin", func(t *testing.T) {
+		backConf, err := New(defaultCloneSettings).Compile(&yaml_types.Workflow{
+			Clone: yaml_types.ContainerList{ContainerList: []*yaml_types.Container{{
+				Name:  "git",
+				Image: "example.com/custom/clone",
+			}}},
+		})
+		assert.NoError(t, err)
+
+		clone := backConf.Stages[0].Steps[0]
+		assert.NotContains(t, clone.Environment, "PLUGIN_DEPTH")
+		assert.NotContains(t, clone.Environment, "PLUGIN_RECURSIVE")
+	})
+}
@@ -160,6 +160,15 @@ func WithDefaultClonePlugin(cloneImage string) Option {
 	}
 }
 
+// WithDefaultCloneSettings configures the compiler with settings added to
+// clone steps using a trusted clone plugin. Settings defined in the
+// pipeline take precedence.
+func WithDefaultCloneSettings(settings map[string]any) Option {
+	return func(compiler *Compiler) {
+		compiler.defaultCloneSettings = settings
+	}
+}
+
 func WithTrustedClonePlugins(images []string) Option {
 	return func(compiler *Compiler) {
 		compiler.trustedClonePlugins = images
@@ -76,6 +76,10 @@ func TestSchema(t *testing.T) {
 			name:     "Labels",
 			testFile: ".woodpecker/test-labels.yaml",
 		},
+		{
+			name:     "Resources",
+			testFile: ".woodpecker/test-resources.yaml",
+		},
 		{
 			name:     "Map and Sequence Merge", // https://woodpecker-ci.org/docs/next/usage/advanced-yaml-syntax
 			testFile: ".woodpecker/test-merge-map-and-sequence.yaml",
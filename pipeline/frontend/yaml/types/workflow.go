@@ -16,6 +16,7 @@ package types
 
 import (
 	"go.woodpecker-ci.org/woodpecker/v3/pipeline/frontend/yaml/constraint"
+	"go.woodpecker-ci.org/woodpecker/v3/pipeline/frontend/yaml/types/base"
 )
 
 type (
@@ -27,11 +28,18 @@ type (
 		Steps     ContainerList     `yaml:"steps,omitempty"`
 		Services  ContainerList     `yaml:"services,omitempty"`
 		Labels    map[string]string `yaml:"labels,omitempty"`
+		Resources Resources         `yaml:"resources,omitempty"`
 		DependsOn []string          `yaml:"depends_on,omitempty"`
 		RunsOn    []string          `yaml:"runs_on,omitempty"`
 		SkipClone bool              `yaml:"skip_clone"`
 	}
 
+	// Resources defines the cpus and memory a workflow requests from the agent.
+	Resources struct {
+		CPU    float64             `yaml:"cpu,omitempty"`
+		Memory base.MemStringOrInt `yaml:"memory,omitempty"`
+	}
+
 	// Workspace defines a pipeline workspace.
 	Workspace struct {
 		Base string
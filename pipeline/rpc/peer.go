@@ -63,6 +63,19 @@ type (
 		Backend      string            `json:"backend"`
 		Capacity     int               `json:"capacity"`
 		CustomLabels map[string]string `json:"custom_labels"`
+		Resources    Resources         `json:"resources"`
+	}
+
+	// Resources defines an amount of cpu in millicores and memory in bytes, zero if unknown.
+	Resources struct {
+		CPU    int64 `json:"cpu"`
+		Memory int64 `json:"memory"`
+	}
+
+	// Health defines the state the agent reports regularly.
+	Health struct {
+		Capacity Resources `json:"capacity"`
+		Usage    Resources `json:"usage"`
 	}
 )
 
@@ -98,6 +111,6 @@ type Peer interface {
 	// UnregisterAgent unregister our agent from the server
 	UnregisterAgent(ctx context.Context) error
 
-	// ReportHealth reports health status of the agent to the server
-	ReportHealth(c context.Context) error
+	// ReportHealth reports health status and resources of the agent to the server
+	ReportHealth(c context.Context, health Health) error
 }
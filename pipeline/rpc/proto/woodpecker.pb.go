@@ -731,6 +731,8 @@ func (*Empty) Descriptor() ([]byte, []int) {
 type ReportHealthRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Capacity      *Resources             `protobuf:"bytes,2,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	Usage         *Resources             `protobuf:"bytes,3,opt,name=usage,proto3" json:"usage,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -772,6 +774,72 @@ func (x *ReportHealthRequest) GetStatus() string {
 	return ""
 }
 
+func (x *ReportHealthRequest) GetCapacity() *Resources {
+	if x != nil {
+		return x.Capacity
+	}
+	return nil
+}
+
+func (x *ReportHealthRequest) GetUsage() *Resources {
+	if x != nil {
+		return x.Usage
+	}
+	return nil
+}
+
+type Resources struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Cpu           int64                  `protobuf:"varint,1,opt,name=cpu,proto3" json:"cpu,omitempty"`       // millicores
+	Memory        int64                  `protobuf:"varint,2,opt,name=memory,proto3" json:"memory,omitempty"` // bytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Resources) Reset() {
+	*x = Resources{}
+	mi := &file_woodpecker_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Resources) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Resources) ProtoMessage() {}
+
+func (x *Resources) ProtoReflect() protoreflect.Message {
+	mi := &file_woodpecker_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Resources.ProtoReflect.Descriptor instead.
+func (*Resources) Descriptor() ([]byte, []int) {
+	return file_woodpecker_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Resources) GetCpu() int64 {
+	if x != nil {
+		return x.Cpu
+	}
+	return 0
+}
+
+func (x *Resources) GetMemory() int64 {
+	if x != nil {
+		return x.Memory
+	}
+	return 0
+}
+
 type AgentInfo struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Platform      string                 `protobuf:"bytes,1,opt,name=platform,proto3" json:"platform,omitempty"`
@@ -779,13 +847,14 @@ type AgentInfo struct {
 	Backend       string                 `protobuf:"bytes,3,opt,name=backend,proto3" json:"backend,omitempty"`
 	Version       string                 `protobuf:"bytes,4,opt,name=version,proto3" json:"version,omitempty"`
 	CustomLabels  map[string]string      `protobuf:"bytes,5,rep,name=customLabels,proto3" json:"customLabels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Resources     *Resources             `protobuf:"bytes,6,opt,name=resources,proto3" json:"resources,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *AgentInfo) Reset() {
 	*x = AgentInfo{}
-	mi := &file_woodpecker_proto_msgTypes[14]
+	mi := &file_woodpecker_proto_msgTypes[15]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -797,7 +866,7 @@ func (x *AgentInfo) String() string {
 func (*AgentInfo) ProtoMessage() {}
 
 func (x *AgentInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_woodpecker_proto_msgTypes[14]
+	mi := &file_woodpecker_proto_msgTypes[15]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -810,7 +879,7 @@ func (x *AgentInfo) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AgentInfo.ProtoReflect.Descriptor instead.
 func (*AgentInfo) Descriptor() ([]byte, []int) {
-	return file_woodpecker_proto_rawDescGZIP(), []int{14}
+	return file_woodpecker_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *AgentInfo) GetPlatform() string {
@@ -848,6 +917,13 @@ func (x *AgentInfo) GetCustomLabels() map[string]string {
 	return nil
 }
 
+func (x *AgentInfo) GetResources() *Resources {
+	if x != nil {
+		return x.Resources
+	}
+	return nil
+}
+
 type RegisterAgentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Info          *AgentInfo             `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
@@ -857,7 +933,7 @@ type RegisterAgentRequest struct {
 
 func (x *RegisterAgentRequest) Reset() {
 	*x = RegisterAgentRequest{}
-	mi := &file_woodpecker_proto_msgTypes[15]
+	mi := &file_woodpecker_proto_msgTypes[16]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -869,7 +945,7 @@ func (x *RegisterAgentRequest) String() string {
 func (*RegisterAgentRequest) ProtoMessage() {}
 
 func (x *RegisterAgentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_woodpecker_proto_msgTypes[15]
+	mi := &file_woodpecker_proto_msgTypes[16]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -882,7 +958,7 @@ func (x *RegisterAgentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterAgentRequest.ProtoReflect.Descriptor instead.
 func (*RegisterAgentRequest) Descriptor() ([]byte, []int) {
-	return file_woodpecker_proto_rawDescGZIP(), []int{15}
+	return file_woodpecker_proto_rawDescGZIP(), []int{16}
 }
 
 func (x *RegisterAgentRequest) GetInfo() *AgentInfo {
@@ -902,7 +978,7 @@ type VersionResponse struct {
 
 func (x *VersionResponse) Reset() {
 	*x = VersionResponse{}
-	mi := &file_woodpecker_proto_msgTypes[16]
+	mi := &file_woodpecker_proto_msgTypes[17]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -914,7 +990,7 @@ func (x *VersionResponse) String() string {
 func (*VersionResponse) ProtoMessage() {}
 
 func (x *VersionResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_woodpecker_proto_msgTypes[16]
+	mi := &file_woodpecker_proto_msgTypes[17]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -927,7 +1003,7 @@ func (x *VersionResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VersionResponse.ProtoReflect.Descriptor instead.
 func (*VersionResponse) Descriptor() ([]byte, []int) {
-	return file_woodpecker_proto_rawDescGZIP(), []int{16}
+	return file_woodpecker_proto_rawDescGZIP(), []int{17}
 }
 
 func (x *VersionResponse) GetGrpcVersion() int32 {
@@ -953,7 +1029,7 @@ type NextResponse struct {
 
 func (x *NextResponse) Reset() {
 	*x = NextResponse{}
-	mi := &file_woodpecker_proto_msgTypes[17]
+	mi := &file_woodpecker_proto_msgTypes[18]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -965,7 +1041,7 @@ func (x *NextResponse) String() string {
 func (*NextResponse) ProtoMessage() {}
 
 func (x *NextResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_woodpecker_proto_msgTypes[17]
+	mi := &file_woodpecker_proto_msgTypes[18]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -978,7 +1054,7 @@ func (x *NextResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use NextResponse.ProtoReflect.Descriptor instead.
 func (*NextResponse) Descriptor() ([]byte, []int) {
-	return file_woodpecker_proto_rawDescGZIP(), []int{17}
+	return file_woodpecker_proto_rawDescGZIP(), []int{18}
 }
 
 func (x *NextResponse) GetWorkflow() *Workflow {
@@ -997,7 +1073,7 @@ type RegisterAgentResponse struct {
 
 func (x *RegisterAgentResponse) Reset() {
 	*x = RegisterAgentResponse{}
-	mi := &file_woodpecker_proto_msgTypes[18]
+	mi := &file_woodpecker_proto_msgTypes[19]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1009,7 +1085,7 @@ func (x *RegisterAgentResponse) String() string {
 func (*RegisterAgentResponse) ProtoMessage() {}
 
 func (x *RegisterAgentResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_woodpecker_proto_msgTypes[18]
+	mi := &file_woodpecker_proto_msgTypes[19]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1022,7 +1098,7 @@ func (x *RegisterAgentResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RegisterAgentResponse.ProtoReflect.Descriptor instead.
 func (*RegisterAgentResponse) Descriptor() ([]byte, []int) {
-	return file_woodpecker_proto_rawDescGZIP(), []int{18}
+	return file_woodpecker_proto_rawDescGZIP(), []int{19}
 }
 
 func (x *RegisterAgentResponse) GetAgentId() int64 {
@@ -1042,7 +1118,7 @@ type AuthRequest struct {
 
 func (x *AuthRequest) Reset() {
 	*x = AuthRequest{}
-	mi := &file_woodpecker_proto_msgTypes[19]
+	mi := &file_woodpecker_proto_msgTypes[20]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1054,7 +1130,7 @@ func (x *AuthRequest) String() string {
 func (*AuthRequest) ProtoMessage() {}
 
 func (x *AuthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_woodpecker_proto_msgTypes[19]
+	mi := &file_woodpecker_proto_msgTypes[20]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1067,7 +1143,7 @@ func (x *AuthRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AuthRequest.ProtoReflect.Descriptor instead.
 func (*AuthRequest) Descriptor() ([]byte, []int) {
-	return file_woodpecker_proto_rawDescGZIP(), []int{19}
+	return file_woodpecker_proto_rawDescGZIP(), []int{20}
 }
 
 func (x *AuthRequest) GetAgentToken() string {
@@ -1095,7 +1171,7 @@ type AuthResponse struct {
 
 func (x *AuthResponse) Reset() {
 	*x = AuthResponse{}
-	mi := &file_woodpecker_proto_msgTypes[20]
+	mi := &file_woodpecker_proto_msgTypes[21]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1107,7 +1183,7 @@ func (x *AuthResponse) String() string {
 func (*AuthResponse) ProtoMessage() {}
 
 func (x *AuthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_woodpecker_proto_msgTypes[20]
+	mi := &file_woodpecker_proto_msgTypes[21]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1120,7 +1196,7 @@ func (x *AuthResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use AuthResponse.ProtoReflect.Descriptor instead.
 func (*AuthResponse) Descriptor() ([]byte, []int) {
-	return file_woodpecker_proto_rawDescGZIP(), []int{20}
+	return file_woodpecker_proto_rawDescGZIP(), []int{21}
 }
 
 func (x *AuthResponse) GetStatus() string {
@@ -1195,15 +1271,21 @@ const file_woodpecker_proto_rawDesc = "" +
 	"\n" +
 	"logEntries\x18\x01 \x03(\v2\x0f.proto.LogEntryR\n" +
 	"logEntries\"\a\n" +
-	"\x05Empty\"-\n" +
+	"\x05Empty\"\x83\x01\n" +
 	"\x13ReportHealthRequest\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\"\x80\x02\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12,\n" +
+	"\bcapacity\x18\x02 \x01(\v2\x10.proto.ResourcesR\bcapacity\x12&\n" +
+	"\x05usage\x18\x03 \x01(\v2\x10.proto.ResourcesR\x05usage\"5\n" +
+	"\tResources\x12\x10\n" +
+	"\x03cpu\x18\x01 \x01(\x03R\x03cpu\x12\x16\n" +
+	"\x06memory\x18\x02 \x01(\x03R\x06memory\"\xb0\x02\n" +
 	"\tAgentInfo\x12\x1a\n" +
 	"\bplatform\x18\x01 \x01(\tR\bplatform\x12\x1a\n" +
 	"\bcapacity\x18\x02 \x01(\x05R\bcapacity\x12\x18\n" +
 	"\abackend\x18\x03 \x01(\tR\abackend\x12\x18\n" +
 	"\aversion\x18\x04 \x01(\tR\aversion\x12F\n" +
-	"\fcustomLabels\x18\x05 \x03(\v2\".proto.AgentInfo.CustomLabelsEntryR\fcustomLabels\x1a?\n" +
+	"\fcustomLabels\x18\x05 \x03(\v2\".proto.AgentInfo.CustomLabelsEntryR\fcustomLabels\x12.\n" +
+	"\tresources\x18\x06 \x01(\v2\x10.proto.ResourcesR\tresources\x1a?\n" +
 	"\x11CustomLabelsEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
 	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"<\n" +
@@ -1252,7 +1334,7 @@ func file_woodpecker_proto_rawDescGZIP() []byte {
 	return file_woodpecker_proto_rawDescData
 }
 
-var file_woodpecker_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
+var file_woodpecker_proto_msgTypes = make([]protoimpl.MessageInfo, 24)
 var file_woodpecker_proto_goTypes = []any{
 	(*StepState)(nil),             // 0: proto.StepState
 	(*WorkflowState)(nil),         // 1: proto.WorkflowState
@@ -1268,55 +1350,59 @@ var file_woodpecker_proto_goTypes = []any{
 	(*LogRequest)(nil),            // 11: proto.LogRequest
 	(*Empty)(nil),                 // 12: proto.Empty
 	(*ReportHealthRequest)(nil),   // 13: proto.ReportHealthRequest
-	(*AgentInfo)(nil),             // 14: proto.AgentInfo
-	(*RegisterAgentRequest)(nil),  // 15: proto.RegisterAgentRequest
-	(*VersionResponse)(nil),       // 16: proto.VersionResponse
-	(*NextResponse)(nil),          // 17: proto.NextResponse
-	(*RegisterAgentResponse)(nil), // 18: proto.RegisterAgentResponse
-	(*AuthRequest)(nil),           // 19: proto.AuthRequest
-	(*AuthResponse)(nil),          // 20: proto.AuthResponse
-	nil,                           // 21: proto.Filter.LabelsEntry
-	nil,                           // 22: proto.AgentInfo.CustomLabelsEntry
+	(*Resources)(nil),             // 14: proto.Resources
+	(*AgentInfo)(nil),             // 15: proto.AgentInfo
+	(*RegisterAgentRequest)(nil),  // 16: proto.RegisterAgentRequest
+	(*VersionResponse)(nil),       // 17: proto.VersionResponse
+	(*NextResponse)(nil),          // 18: proto.NextResponse
+	(*RegisterAgentResponse)(nil), // 19: proto.RegisterAgentResponse
+	(*AuthRequest)(nil),           // 20: proto.AuthRequest
+	(*AuthResponse)(nil),          // 21: proto.AuthResponse
+	nil,                           // 22: proto.Filter.LabelsEntry
+	nil,                           // 23: proto.AgentInfo.CustomLabelsEntry
 }
 var file_woodpecker_proto_depIdxs = []int32{
-	21, // 0: proto.Filter.labels:type_name -> proto.Filter.LabelsEntry
+	22, // 0: proto.Filter.labels:type_name -> proto.Filter.LabelsEntry
 	3,  // 1: proto.NextRequest.filter:type_name -> proto.Filter
 	1,  // 2: proto.InitRequest.state:type_name -> proto.WorkflowState
 	1,  // 3: proto.DoneRequest.state:type_name -> proto.WorkflowState
 	0,  // 4: proto.UpdateRequest.state:type_name -> proto.StepState
 	2,  // 5: proto.LogRequest.logEntries:type_name -> proto.LogEntry
-	22, // 6: proto.AgentInfo.customLabels:type_name -> proto.AgentInfo.CustomLabelsEntry
-	14, // 7: proto.RegisterAgentRequest.info:type_name -> proto.AgentInfo
-	4,  // 8: proto.NextResponse.workflow:type_name -> proto.Workflow
-	12, // 9: proto.Woodpecker.Version:input_type -> proto.Empty
-	5,  // 10: proto.Woodpecker.Next:input_type -> proto.NextRequest
-	6,  // 11: proto.Woodpecker.Init:input_type -> proto.InitRequest
-	7,  // 12: proto.Woodpecker.Wait:input_type -> proto.WaitRequest
-	8,  // 13: proto.Woodpecker.Done:input_type -> proto.DoneRequest
-	9,  // 14: proto.Woodpecker.Extend:input_type -> proto.ExtendRequest
-	10, // 15: proto.Woodpecker.Update:input_type -> proto.UpdateRequest
-	11, // 16: proto.Woodpecker.Log:input_type -> proto.LogRequest
-	15, // 17: proto.Woodpecker.RegisterAgent:input_type -> proto.RegisterAgentRequest
-	12, // 18: proto.Woodpecker.UnregisterAgent:input_type -> proto.Empty
-	13, // 19: proto.Woodpecker.ReportHealth:input_type -> proto.ReportHealthRequest
-	19, // 20: proto.WoodpeckerAuth.Auth:input_type -> proto.AuthRequest
-	16, // 21: proto.Woodpecker.Version:output_type -> proto.VersionResponse
-	17, // 22: proto.Woodpecker.Next:output_type -> proto.NextResponse
-	12, // 23: proto.Woodpecker.Init:output_type -> proto.Empty
-	12, // 24: proto.Woodpecker.Wait:output_type -> proto.Empty
-	12, // 25: proto.Woodpecker.Done:output_type -> proto.Empty
-	12, // 26: proto.Woodpecker.Extend:output_type -> proto.Empty
-	12, // 27: proto.Woodpecker.Update:output_type -> proto.Empty
-	12, // 28: proto.Woodpecker.Log:output_type -> proto.Empty
-	18, // 29: proto.Woodpecker.RegisterAgent:output_type -> proto.RegisterAgentResponse
-	12, // 30: proto.Woodpecker.UnregisterAgent:output_type -> proto.Empty
-	12, // 31: proto.Woodpecker.ReportHealth:output_type -> proto.Empty
-	20, // 32: proto.WoodpeckerAuth.Auth:output_type -> proto.AuthResponse
-	21, // [21:33] is the sub-list for method output_type
-	9,  // [9:21] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	14, // 6: proto.ReportHealthRequest.capacity:type_name -> proto.Resources
+	14, // 7: proto.ReportHealthRequest.usage:type_name -> proto.Resources
+	23, // 8: proto.AgentInfo.customLabels:type_name -> proto.AgentInfo.CustomLabelsEntry
+	14, // 9: proto.AgentInfo.resources:type_name -> proto.Resources
+	15, // 10: proto.RegisterAgentRequest.info:type_name -> proto.AgentInfo
+	4,  // 11: proto.NextResponse.workflow:type_name -> proto.Workflow
+	12, // 12: proto.Woodpecker.Version:input_type -> proto.Empty
+	5,  // 13: proto.Woodpecker.Next:input_type -> proto.NextRequest
+	6,  // 14: proto.Woodpecker.Init:input_type -> proto.InitRequest
+	7,  // 15: proto.Woodpecker.Wait:input_type -> proto.WaitRequest
+	8,  // 16: proto.Woodpecker.Done:input_type -> proto.DoneRequest
+	9,  // 17: proto.Woodpecker.Extend:input_type -> proto.ExtendRequest
+	10, // 18: proto.Woodpecker.Update:input_type -> proto.UpdateRequest
+	11, // 19: proto.Woodpecker.Log:input_type -> proto.LogRequest
+	16, // 20: proto.Woodpecker.RegisterAgent:input_type -> proto.RegisterAgentRequest
+	12, // 21: proto.Woodpecker.UnregisterAgent:input_type -> proto.Empty
+	13, // 22: proto.Woodpecker.ReportHealth:input_type -> proto.ReportHealthRequest
+	20, // 23: proto.WoodpeckerAuth.Auth:input_type -> proto.AuthRequest
+	17, // 24: proto.Woodpecker.Version:output_type -> proto.VersionResponse
+	18, // 25: proto.Woodpecker.Next:output_type -> proto.NextResponse
+	12, // 26: proto.Woodpecker.Init:output_type -> proto.Empty
+	12, // 27: proto.Woodpecker.Wait:output_type -> proto.Empty
+	12, // 28: proto.Woodpecker.Done:output_type -> proto.Empty
+	12, // 29: proto.Woodpecker.Extend:output_type -> proto.Empty
+	12, // 30: proto.Woodpecker.Update:output_type -> proto.Empty
+	12, // 31: proto.Woodpecker.Log:output_type -> proto.Empty
+	19, // 32: proto.Woodpecker.RegisterAgent:output_type -> proto.RegisterAgentResponse
+	12, // 33: proto.Woodpecker.UnregisterAgent:output_type -> proto.Empty
+	12, // 34: proto.Woodpecker.ReportHealth:output_type -> proto.Empty
+	21, // 35: proto.WoodpeckerAuth.Auth:output_type -> proto.AuthResponse
+	24, // [24:36] is the sub-list for method output_type
+	12, // [12:24] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
 }
 
 func init() { file_woodpecker_proto_init() }
@@ -1330,7 +1416,7 @@ func file_woodpecker_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_woodpecker_proto_rawDesc), len(file_woodpecker_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   23,
+			NumMessages:   24,
 			NumExtensions: 0,
 			NumServices:   2,
 		},
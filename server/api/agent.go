@@ -23,6 +23,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/session"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/tokenhash"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 )
 
@@ -46,6 +47,7 @@ func GetAgents(c *gin.Context) {
 		c.String(http.StatusInternalServerError, "Error getting agent list. %s", err)
 		return
 	}
+	hideAgentTokens(agents...)
 	c.JSON(http.StatusOK, agents)
 }
 
@@ -70,6 +72,7 @@ func GetAgent(c *gin.Context) {
 		handleDBError(c, err)
 		return
 	}
+	hideAgentTokens(agent)
 	c.JSON(http.StatusOK, agent)
 }
 
@@ -151,13 +154,14 @@ func PatchAgent(c *gin.Context) {
 		return
 	}
 
+	hideAgentTokens(agent)
 	c.JSON(http.StatusOK, agent)
 }
 
 // PostAgent
 //
 //	@Summary		Create a new agent
-//	@Description	Creates a new agent with a random token
+//	@Description	Creates a new agent with a random token. The token is only returned once, as only its hash is stored
 //	@Router			/agents [post]
 //	@Produce		json
 //	@Success		200	{object}	Agent
@@ -174,17 +178,26 @@ func PostAgent(c *gin.Context) {
 
 	user := session.User(c)
 
+	token, hashedToken, err := newAgentToken()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	agent := &model.Agent{
 		Name:       in.Name,
 		OwnerID:    user.ID,
 		OrgID:      model.IDNotSet,
 		NoSchedule: in.NoSchedule,
-		Token:      model.GenerateNewAgentToken(),
+		Token:      hashedToken,
 	}
 	if err = store.FromContext(c).AgentCreate(agent); err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
+
+	// only the hash of the token is stored, so this is the only time the token is shown
+	agent.Token = token
 	c.JSON(http.StatusOK, agent)
 }
 
@@ -238,7 +251,7 @@ func DeleteAgent(c *gin.Context) {
 // PostOrgAgent
 //
 //	@Summary		Create a new organization-scoped agent
-//	@Description	Creates a new agent with a random token, scoped to the specified organization
+//	@Description	Creates a new agent with a random token, scoped to the specified organization. The token is only returned once, as only its hash is stored
 //	@Router			/orgs/{org_id}/agents [post]
 //	@Produce		json
 //	@Success		200	{object}	Agent
@@ -263,12 +276,18 @@ func PostOrgAgent(c *gin.Context) {
 		return
 	}
 
+	token, hashedToken, err := newAgentToken()
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	agent := &model.Agent{
 		Name:       in.Name,
 		OwnerID:    user.ID,
 		OrgID:      orgID,
 		NoSchedule: in.NoSchedule,
-		Token:      model.GenerateNewAgentToken(),
+		Token:      hashedToken,
 	}
 
 	if err = _store.AgentCreate(agent); err != nil {
@@ -276,6 +295,8 @@ func PostOrgAgent(c *gin.Context) {
 		return
 	}
 
+	// only the hash of the token is stored, so this is the only time the token is shown
+	agent.Token = token
 	c.JSON(http.StatusOK, agent)
 }
 
@@ -299,6 +320,7 @@ func GetOrgAgents(c *gin.Context) {
 		c.String(http.StatusInternalServerError, "Error getting agent list. %s", err)
 		return
 	}
+	hideAgentTokens(agents...)
 
 	c.JSON(http.StatusOK, agents)
 }
@@ -353,6 +375,7 @@ func PatchOrgAgent(c *gin.Context) {
 		return
 	}
 
+	hideAgentTokens(agent)
 	c.JSON(http.StatusOK, agent)
 }
 
@@ -406,3 +429,17 @@ func DeleteOrgAgent(c *gin.Context) {
 
 	c.Status(http.StatusNoContent)
 }
+
+// newAgentToken generates a new agent token and returns it together with its hash.
+func newAgentToken() (token, hashedToken string, err error) {
+	token = model.GenerateNewAgentToken()
+	hashedToken, err = tokenhash.Hash(server.Config.Agent.TokenHashAlgorithm, token)
+	return token, hashedToken, err
+}
+
+// hideAgentTokens removes the hashed tokens of the agents, as they are of no use for clients.
+func hideAgentTokens(agents ...*model.Agent) {
+	for _, agent := range agents {
+		agent.Token = ""
+	}
+}
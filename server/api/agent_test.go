@@ -30,6 +30,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
 	queue_mocks "go.woodpecker-ci.org/woodpecker/v3/server/queue/mocks"
 	manager_mocks "go.woodpecker-ci.org/woodpecker/v3/server/services/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/tokenhash"
 	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
 )
@@ -153,13 +154,18 @@ func TestPatchAgent(t *testing.T) {
 func TestPostAgent(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
+	server.Config.Agent.TokenHashAlgorithm = tokenhash.DefaultAlgorithm
+
 	t.Run("should create agent", func(t *testing.T) {
 		newAgent := &model.Agent{
 			Name: "new-agent",
 		}
 
+		var storedToken string
 		mockStore := store_mocks.NewMockStore(t)
-		mockStore.On("AgentCreate", mock.AnythingOfType("*model.Agent")).Return(nil)
+		mockStore.On("AgentCreate", mock.AnythingOfType("*model.Agent")).Run(func(args mock.Arguments) {
+			storedToken = args.Get(0).(*model.Agent).Token
+		}).Return(nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -179,6 +185,10 @@ func TestPostAgent(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, newAgent.Name, response.Name)
 		assert.NotEmpty(t, response.Token)
+
+		// only the hash of the returned token is stored
+		assert.NotEqual(t, response.Token, storedToken)
+		assert.True(t, tokenhash.Verify(storedToken, response.Token))
 	})
 }
 
@@ -241,6 +251,7 @@ func TestDeleteAgent(t *testing.T) {
 
 func TestPostOrgAgent(t *testing.T) {
 	gin.SetMode(gin.TestMode)
+	server.Config.Agent.TokenHashAlgorithm = tokenhash.DefaultAlgorithm
 
 	t.Run("create org agent should succeed", func(t *testing.T) {
 		mockStore := store_mocks.NewMockStore(t)
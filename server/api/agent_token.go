@@ -0,0 +1,104 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/agenttoken"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+// GetAgentTokens
+//
+//	@Summary		List agent tokens
+//	@Description	Lists the shared tokens agents can register with in addition to the agent secret of the server config.
+//	@Router			/agent-tokens [get]
+//	@Produce		json
+//	@Success		200	{array}	AgentToken
+//	@Tags			Agents
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func GetAgentTokens(c *gin.Context) {
+	tokens, err := agenttoken.List(store.FromContext(c))
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error getting agent tokens. %s", err)
+		return
+	}
+	// tokens are only stored hashed and only shown once on creation
+	for _, token := range tokens {
+		token.Token = ""
+	}
+	c.JSON(http.StatusOK, tokens)
+}
+
+// PostAgentToken
+//
+//	@Summary		Create an agent token
+//	@Description	Creates a new shared token agents can register with. Used to roll out a new agent secret without restarting all agents at once. The token is only returned once, as only its hash is stored.
+//	@Router			/agent-tokens [post]
+//	@Produce		json
+//	@Success		200	{object}	AgentToken
+//	@Tags			Agents
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func PostAgentToken(c *gin.Context) {
+	token, err := agenttoken.Add(store.FromContext(c), time.Now(), server.Config.Agent.TokenHashAlgorithm)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error creating agent token. %s", err)
+		return
+	}
+	c.JSON(http.StatusOK, token)
+}
+
+// DeleteAgentToken
+//
+//	@Summary		Retire an agent token
+//	@Description	Removes a shared agent token. Fails if agents are still registered with it, unless force is set.
+//	@Description	Forcing it disconnects these agents, they have to register with another token.
+//	@Router			/agent-tokens/{token_id} [delete]
+//	@Produce		plain
+//	@Success		204
+//	@Tags			Agents
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			token_id		path	int		true	"the agent token's id"
+//	@Param			force			query	bool	false	"remove the token even if agents still use it"
+func DeleteAgentToken(c *gin.Context) {
+	tokenID, err := strconv.ParseInt(c.Param("token_id"), 10, 64)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	force, _ := strconv.ParseBool(c.Query("force"))
+
+	revoked, err := agenttoken.Retire(store.FromContext(c), tokenID, force)
+	// agents which lost their token must not keep working with their current access
+	for _, agent := range revoked {
+		server.Config.Services.Queue.KickAgentWorkers(agent.ID)
+	}
+	if errors.Is(err, agenttoken.ErrInUse) {
+		c.String(http.StatusConflict, "Agent token is still used by agents")
+		return
+	}
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
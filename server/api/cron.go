@@ -83,7 +83,7 @@ func RunCron(c *gin.Context) {
 
 	repo, newPipeline, err := cronScheduler.CreatePipeline(c, _store, cron)
 	if err != nil {
-		c.String(http.StatusInternalServerError, "Error creating pipeline for cron %q. %s", id, err)
+		c.String(http.StatusInternalServerError, "Error creating pipeline for cron %d. %s", id, err)
 		return
 	}
 
@@ -128,6 +128,7 @@ func PostCron(c *gin.Context) {
 		CreatorID: user.ID,
 		Schedule:  in.Schedule,
 		Branch:    in.Branch,
+		Variables: in.Variables,
 	}
 	if err := cron.Validate(); err != nil {
 		c.String(http.StatusUnprocessableEntity, "Error inserting cron. validate failed: %s", err)
@@ -218,6 +219,10 @@ func PatchCron(c *gin.Context) {
 	if in.Name != "" {
 		cron.Name = in.Name
 	}
+	if in.Variables != nil {
+		// an empty map removes all variables
+		cron.Variables = in.Variables
+	}
 	cron.CreatorID = user.ID
 
 	if err := cron.Validate(); err != nil {
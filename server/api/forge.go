@@ -42,8 +42,7 @@ func GetForges(c *gin.Context) {
 		return
 	}
 
-	user := session.User(c)
-	if user != nil && user.Admin {
+	if session.IsAdmin(c) {
 		c.JSON(http.StatusOK, forges)
 		return
 	}
@@ -78,8 +77,7 @@ func GetForge(c *gin.Context) {
 		return
 	}
 
-	user := session.User(c)
-	if user != nil && user.Admin {
+	if session.IsAdmin(c) {
 		c.JSON(http.StatusOK, forge)
 	} else {
 		c.JSON(http.StatusOK, forge.PublicCopy())
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -47,23 +48,24 @@ func GetQueueInfo(c *gin.Context) {
 	info := server.Config.Services.Queue.Info(c)
 	_store := store.FromContext(c)
 
-	// Create a map to store agent names by ID
+	// Create maps to store agent and repo names by ID
 	agentNameMap := make(map[int64]string)
+	repoNameMap := make(map[int64]string)
 
-	// Process tasks and add agent names
-	pendingWithAgents, err := processQueueTasks(_store, info.Pending, agentNameMap)
+	// Process tasks and add agent and repo names
+	pendingWithAgents, err := processQueueTasks(_store, info.Pending, agentNameMap, repoNameMap)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	waitingWithAgents, err := processQueueTasks(_store, info.WaitingOnDeps, agentNameMap)
+	waitingWithAgents, err := processQueueTasks(_store, info.WaitingOnDeps, agentNameMap, repoNameMap)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	runningWithAgents, err := processQueueTasks(_store, info.Running, agentNameMap)
+	runningWithAgents, err := processQueueTasks(_store, info.Running, agentNameMap, repoNameMap)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
 		return
@@ -115,8 +117,22 @@ func getAgentName(store store.Store, agentNameMap map[int64]string, agentID int6
 	return "", false
 }
 
-// processQueueTasks converts tasks to QueueTask structs and adds agent names.
-func processQueueTasks(store store.Store, tasks []*model.Task, agentNameMap map[int64]string) ([]model.QueueTask, error) {
+// getRepoName finds the full name of a repo, utilizing a map as a cache.
+func getRepoName(store store.Store, repoNameMap map[int64]string, repoID int64) (string, error) {
+	if name, exists := repoNameMap[repoID]; exists {
+		return name, nil
+	}
+
+	repo, err := store.GetRepo(repoID)
+	if err != nil {
+		return "", err
+	}
+	repoNameMap[repoID] = repo.FullName
+	return repo.FullName, nil
+}
+
+// processQueueTasks converts tasks to QueueTask structs and adds agent and repo names.
+func processQueueTasks(store store.Store, tasks []*model.Task, agentNameMap, repoNameMap map[int64]string) ([]model.QueueTask, error) {
 	result := make([]model.QueueTask, 0, len(tasks))
 
 	for _, task := range tasks {
@@ -142,6 +158,15 @@ func processQueueTasks(store store.Store, tasks []*model.Task, agentNameMap map[
 			taskResponse.PipelineNumber = p.Number
 		}
 
+		if task.RepoID != 0 {
+			name, err := getRepoName(store, repoNameMap, task.RepoID)
+			if err != nil {
+				return nil, fmt.Errorf("repo not found for task %s", task.ID)
+			}
+
+			taskResponse.RepoFullName = name
+		}
+
 		result = append(result, taskResponse)
 	}
 	return result, nil
@@ -202,20 +227,36 @@ func BlockTilQueueHasRunningItem(c *gin.Context) {
 func PostHook(c *gin.Context) {
 	_store := store.FromContext(c)
 
+	if server.Draining() {
+		// the forge can deliver the webhook again to the server which replaces this one
+		c.Header("Retry-After", "60")
+		c.String(http.StatusServiceUnavailable, "server is shutting down")
+		return
+	}
+
+	queueFull := isQueueFull(c)
+	if queueFull && server.Config.Webhook.QueueFullAction != server.QueueFullBlock {
+		// the forge can deliver the webhook again once the agents caught up with the queue
+		c.Header("Retry-After", "60")
+		c.String(http.StatusServiceUnavailable, "queue is full")
+		return
+	}
+
 	//
 	// 1. Check if the webhook is valid and authorized
 	//
 
 	var repo *model.Repo
 
-	_, err := token.ParseRequest([]token.Type{token.HookToken}, c.Request, func(t *token.Token) (string, error) {
+	_, err := token.ParseRequestSecrets([]token.Type{token.HookToken}, c.Request, func(t *token.Token) ([]string, error) {
 		var err error
 		repo, err = getRepoFromToken(_store, t)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 
-		return repo.Hash, nil
+		// previous secrets are accepted during their grace period after a rotation
+		return repo.WebhookSecrets(time.Now().Unix()), nil
 	})
 	if err != nil {
 		msg := "failure to parse token from hook"
@@ -337,6 +378,11 @@ func PostHook(c *gin.Context) {
 	// 6. Finally create a pipeline
 	//
 
+	if queueFull {
+		log.Warn().Str("repo", repo.FullName).Msg("queue is full, the pipeline is blocked until it gets approved")
+		pipelineFromForge.Status = model.StatusBlocked
+	}
+
 	pl, err := pipeline.Create(c, _store, repo, pipelineFromForge)
 	if err != nil {
 		handlePipelineErr(c, err)
@@ -345,6 +391,14 @@ func PostHook(c *gin.Context) {
 	}
 }
 
+// isQueueFull checks if the queue has more pending tasks than allowed by the server config.
+func isQueueFull(c *gin.Context) bool {
+	if server.Config.Webhook.QueueMaxPending <= 0 {
+		return false
+	}
+	return server.Config.Services.Queue.Info(c).Stats.Pending > server.Config.Webhook.QueueMaxPending
+}
+
 func getRepoFromToken(store store.Store, t *token.Token) (*model.Repo, error) {
 	if t.Get("repo-forge-remote-id") != "" {
 		// TODO: use both the forge ID and repo forge remote ID
@@ -1,11 +1,14 @@
 package api_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -15,6 +18,8 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/api"
 	forge_mocks "go.woodpecker-ci.org/woodpecker/v3/server/forge/mocks"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+	queue_mocks "go.woodpecker-ci.org/woodpecker/v3/server/queue/mocks"
 	config_service_mocks "go.woodpecker-ci.org/woodpecker/v3/server/services/config/mocks"
 	services_mocks "go.woodpecker-ci.org/woodpecker/v3/server/services/mocks"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/permissions"
@@ -82,6 +87,7 @@ func TestHook(t *testing.T) {
 	_configService.On("Fetch", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
 	_forge.On("Netrc", mock.Anything, mock.Anything).Return(&model.Netrc{}, nil)
 	_store.On("GetPipelineLastBefore", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+	_store.On("OrgGet", repo.OrgID).Return(&model.Org{ID: repo.OrgID}, nil)
 	_manager.On("SecretServiceFromRepo", repo).Return(_secretService)
 	_secretService.On("SecretListPipeline", repo, mock.Anything, mock.Anything).Return(nil, nil)
 	_manager.On("RegistryServiceFromRepo", repo).Return(_registryService)
@@ -94,3 +100,167 @@ func TestHook(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
 	assert.Equal(t, "true", w.Header().Get("Pipeline-Filtered"))
 }
+
+func TestHookSecretRotation(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Now().Unix()
+	repo := &model.Repo{
+		ID:   123,
+		Hash: "the-current-secret",
+		PreviousHashes: []model.RepoHash{
+			{Hash: "the-previous-secret", Expires: now + 3600},
+			{Hash: "the-expired-secret", Expires: now - 60},
+		},
+	}
+
+	tests := []struct {
+		secret     string
+		wantStatus int
+	}{
+		// accepted webhooks fail afterwards, as the test has no forge
+		{secret: "the-current-secret", wantStatus: http.StatusInternalServerError},
+		{secret: "the-previous-secret", wantStatus: http.StatusInternalServerError},
+		{secret: "the-expired-secret", wantStatus: http.StatusBadRequest},
+		{secret: "an-unknown-secret", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			_manager := services_mocks.NewMockManager(t)
+			_store := store_mocks.NewMockStore(t)
+			server.Config.Services.Manager = _manager
+			_store.On("GetRepo", repo.ID).Return(repo, nil)
+			if tt.wantStatus == http.StatusInternalServerError {
+				_manager.On("ForgeFromRepo", repo).Return(nil, errors.New("no forge"))
+			}
+
+			repoToken := token.New(token.HookToken)
+			repoToken.Set("repo-id", fmt.Sprintf("%d", repo.ID))
+			signedToken, err := repoToken.Sign(tt.secret)
+			assert.NoError(t, err)
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("store", _store)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/hook", nil)
+			c.Request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", signedToken))
+
+			api.PostHook(c)
+
+			assert.Equal(t, tt.wantStatus, c.Writer.Status())
+		})
+	}
+}
+
+func TestHookDraining(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	server.SetDraining(true)
+	t.Cleanup(func() { server.SetDraining(false) })
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("store", store_mocks.NewMockStore(t))
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/hook", nil)
+
+	api.PostHook(c)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+}
+
+func TestHookQueueFull(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	origMaxPending := server.Config.Webhook.QueueMaxPending
+	origAction := server.Config.Webhook.QueueFullAction
+	origQueue := server.Config.Services.Queue
+	t.Cleanup(func() {
+		server.Config.Webhook.QueueMaxPending = origMaxPending
+		server.Config.Webhook.QueueFullAction = origAction
+		server.Config.Services.Queue = origQueue
+	})
+	server.Config.Webhook.QueueMaxPending = 2
+
+	tests := []struct {
+		name       string
+		pending    int
+		action     string
+		wantStatus int
+	}{
+		// webhooks which are not rejected fail afterwards, as the test request has no token
+		{name: "below limit", pending: 1, action: server.QueueFullReject, wantStatus: http.StatusBadRequest},
+		{name: "at limit", pending: 2, action: server.QueueFullReject, wantStatus: http.StatusBadRequest},
+		{name: "above limit", pending: 3, action: server.QueueFullReject, wantStatus: http.StatusServiceUnavailable},
+		{name: "above limit blocking", pending: 3, action: server.QueueFullBlock, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := queue.InfoT{}
+			info.Stats.Pending = tt.pending
+			mockQueue := queue_mocks.NewMockQueue(t)
+			mockQueue.On("Info", mock.Anything).Return(info)
+			server.Config.Services.Queue = mockQueue
+			server.Config.Webhook.QueueFullAction = tt.action
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Set("store", store_mocks.NewMockStore(t))
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/hook", nil)
+
+			api.PostHook(c)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantStatus == http.StatusServiceUnavailable {
+				assert.Equal(t, "60", w.Header().Get("Retry-After"))
+			}
+		})
+	}
+}
+
+func TestGetQueueInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	_store := store_mocks.NewMockStore(t)
+	_store.On("AgentFind", int64(5)).Return(&model.Agent{ID: 5, Name: "agent-5"}, nil).Once()
+	_store.On("GetPipeline", int64(10)).Return(&model.Pipeline{ID: 10, Number: 3}, nil)
+	_store.On("GetRepo", int64(2)).Return(&model.Repo{ID: 2, FullName: "owner/repo"}, nil).Once()
+
+	info := queue.InfoT{
+		Pending: []*model.Task{{ID: "1", RepoID: 2, PipelineID: 10, Labels: map[string]string{"platform": "linux/amd64"}}},
+		Running: []*model.Task{{ID: "2", RepoID: 2, PipelineID: 10, AgentID: 5}},
+	}
+	info.Stats.Workers = 4
+	info.Stats.Pending = 1
+	info.Stats.Running = 1
+
+	mockQueue := queue_mocks.NewMockQueue(t)
+	mockQueue.On("Info", mock.Anything).Return(info)
+	server.Config.Services.Queue = mockQueue
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("store", _store)
+
+	api.GetQueueInfo(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var got model.QueueInfo
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &got))
+	assert.Equal(t, 4, got.Stats.WorkerCount)
+	assert.Equal(t, 1, got.Stats.PendingCount)
+	assert.Equal(t, 1, got.Stats.RunningCount)
+	if assert.Len(t, got.Pending, 1) {
+		assert.Equal(t, "owner/repo", got.Pending[0].RepoFullName)
+		assert.Equal(t, int64(3), got.Pending[0].PipelineNumber)
+		assert.Equal(t, map[string]string{"platform": "linux/amd64"}, got.Pending[0].Labels)
+		assert.Empty(t, got.Pending[0].AgentName)
+	}
+	if assert.Len(t, got.Running, 1) {
+		assert.Equal(t, "owner/repo", got.Running[0].RepoFullName)
+		assert.Equal(t, "agent-5", got.Running[0].AgentName)
+	}
+	assert.Empty(t, got.WaitingOnDeps)
+}
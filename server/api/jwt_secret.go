@@ -0,0 +1,59 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/jwtsecret"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+const defaultJWTSecretGracePeriod = 24 * time.Hour
+
+// RotateJWTSecret
+//
+//	@Summary		Rotate the JWT secret
+//	@Description	Creates a new secret to sign server issued tokens. Tokens signed with the previous secret stay valid for the grace period. Requires admin rights.
+//	@Router			/jwt-secret/rotate [post]
+//	@Success		204
+//	@Tags			System
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			grace_period	query	string	false	"duration the previous secret stays valid (default 24h)"
+func RotateJWTSecret(c *gin.Context) {
+	grace := defaultJWTSecretGracePeriod
+	if value := c.Query("grace_period"); value != "" {
+		var err error
+		grace, err = time.ParseDuration(value)
+		if err != nil || grace < 0 {
+			c.String(http.StatusBadRequest, fmt.Sprintf("invalid grace period '%s'", value))
+			return
+		}
+	}
+
+	secret, err := jwtsecret.Rotate(store.FromContext(c), time.Now(), grace)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	server.SetJWTSecret(secret)
+
+	c.Status(http.StatusNoContent)
+}
@@ -31,6 +31,8 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
 	forge_types "go.woodpecker-ci.org/woodpecker/v3/server/forge/types"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/session"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/jwtsecret"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
@@ -70,9 +72,7 @@ func HandleAuth(c *gin.Context) {
 	var forgeID int64
 
 	if isCallback { // validate the state token
-		stateToken, err := token.Parse([]token.Type{token.OAuthStateToken}, state, func(_ *token.Token) (string, error) {
-			return server.Config.Server.JWTSecret, nil
-		})
+		stateToken, err := jwtsecret.Parse(_store, []token.Type{token.OAuthStateToken}, state, server.JWTSecret())
 		if err != nil {
 			log.Error().Err(err).Msg("cannot verify state token")
 			c.Redirect(http.StatusSeeOther, server.Config.Server.RootPath+"/login?error=invalid_state")
@@ -101,7 +101,7 @@ func HandleAuth(c *gin.Context) {
 			}
 		}
 
-		jwtSecret := server.Config.Server.JWTSecret
+		jwtSecret := server.JWTSecret()
 		exp := time.Now().Add(stateTokenDuration).Unix()
 		stateToken := token.New(token.OAuthStateToken)
 		stateToken.Set("forge-id", strconv.FormatInt(forgeID, 10))
@@ -186,7 +186,7 @@ func HandleAuth(c *gin.Context) {
 
 	if user == nil || errors.Is(err, types.RecordNotExist) {
 		// if self-registration is disabled we should return a not authorized error
-		if !server.Config.Permissions.Open && !server.Config.Permissions.Admins.IsAdmin(userFromForge) {
+		if !server.Config.Permissions.Open && !server.Config.Permissions.Admins.IsAdmin(c, _forge, userFromForge) {
 			log.Error().Msgf("cannot register %s. registration closed", userFromForge.Login)
 			c.Redirect(http.StatusSeeOther, server.Config.Server.RootPath+"/login?error=registration_closed")
 			return
@@ -273,7 +273,9 @@ func HandleAuth(c *gin.Context) {
 	user.ForgeID = forgeID
 	user.ForgeRemoteID = userFromForge.ForgeRemoteID
 	user.Login = userFromForge.Login
-	user.Admin = user.Admin || server.Config.Permissions.Admins.IsAdmin(userFromForge)
+	if admins := server.Config.Permissions.Admins; admins.Configured() {
+		user.Admin = admins.IsAdmin(c, _forge, userFromForge)
+	}
 
 	if err := _store.UpdateUser(user); err != nil {
 		log.Error().Err(err).Msgf("cannot update user %s", user.Login)
@@ -281,10 +283,7 @@ func HandleAuth(c *gin.Context) {
 		return
 	}
 
-	exp := time.Now().Add(server.Config.Server.SessionExpires).Unix()
-	_token := token.New(token.SessToken)
-	_token.Set("user-id", strconv.FormatInt(user.ID, 10))
-	tokenString, err := _token.SignExpires(user.Hash, exp)
+	tokenString, err := session.NewToken(user, time.Now())
 	if err != nil {
 		log.Error().Msgf("cannot create token for user %s", user.Login)
 		c.Redirect(http.StatusSeeOther, server.Config.Server.RootPath+"/login?error=internal_error")
@@ -298,7 +297,13 @@ func HandleAuth(c *gin.Context) {
 		return
 	}
 
-	httputil.SetCookie(c.Writer, c.Request, "user_sess", tokenString)
+	httputil.SetCookie(c.Writer, c.Request, "user_sess", tokenString, server.Config.Server.Cookie)
+
+	// admins with a second factor only get admin permissions after verifying it
+	if session.TwoFactorRequired(user) && user.TOTPEnabled {
+		c.Redirect(http.StatusSeeOther, server.Config.Server.RootPath+"/login/2fa")
+		return
+	}
 
 	c.Redirect(http.StatusSeeOther, server.Config.Server.RootPath+"/")
 }
@@ -342,7 +347,12 @@ func updateRepoPermissions(c *gin.Context, user *model.User, _store store.Store,
 }
 
 func GetLogout(c *gin.Context) {
-	httputil.DelCookie(c.Writer, c.Request, "user_sess")
-	httputil.DelCookie(c.Writer, c.Request, "user_last")
+	opts := server.Config.Server.Cookie
+	httputil.DelCookie(c.Writer, c.Request, "user_sess", opts)
+	httputil.DelCookie(c.Writer, c.Request, "user_last", opts)
+	if (opts.Path != "" && opts.Path != "/") || opts.Domain != "" {
+		// sessions created before the cookie options were configured use the default path and domain
+		httputil.DelCookie(c.Writer, c.Request, "user_sess", httputil.CookieOptions{Secure: opts.Secure, SameSite: opts.SameSite})
+	}
 	c.Redirect(http.StatusSeeOther, server.Config.Server.RootPath+"/")
 }
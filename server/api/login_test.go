@@ -22,6 +22,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/permissions"
 	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
 )
 
@@ -78,6 +79,8 @@ func TestHandleAuth(t *testing.T) {
 		server.Config.Permissions.Admins = permissions.NewAdmins(nil)
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
+		_store.On("ServerConfigGet", mock.Anything).Return("", types.RecordNotExist)
+		_store.On("ServerConfigSet", "jwt-secret", mock.Anything).Return(nil)
 		c.Set("store", _store)
 
 		query := url.Values{}
@@ -367,4 +370,55 @@ func TestHandleAuth(t *testing.T) {
 		assert.Equal(t, "/", c.Writer.Header().Get("Location"))
 		assert.NotEmpty(t, c.Writer.Header().Get("Set-Cookie"))
 	})
+	t.Run("should revoke the admin permission of a user removed from the configured admins", func(t *testing.T) {
+		_manager := services_mocks.NewMockManager(t)
+		_forge := forge_mocks.NewMockForge(t)
+		_store := store_mocks.NewMockStore(t)
+		server.Config.Services.Manager = _manager
+		server.Config.Permissions.Open = true
+		server.Config.Permissions.Orgs = permissions.NewOrgs(nil)
+		server.Config.Permissions.Admins = permissions.NewAdmins([]string{"other-admin"})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("store", _store)
+		c.Request = &http.Request{
+			Header: make(http.Header),
+			URL: &url.URL{
+				Scheme: "https",
+			},
+		}
+		stored := *user
+		stored.Admin = true
+
+		_manager.On("ForgeByID", int64(1)).Return(_forge, nil)
+		_forge.On("Login", mock.Anything, mock.Anything).Return(user, "", nil)
+		_store.On("GetUserByRemoteID", user.ForgeID, user.ForgeRemoteID).Return(&stored, nil)
+		_store.On("OrgGet", user.OrgID).Return(&model.Org{ID: 1, Name: user.Login}, nil)
+		_store.On("UpdateUser", mock.Anything).Return(nil)
+		_forge.On("Repos", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
+
+		api.HandleAuth(c)
+
+		assert.Equal(t, http.StatusSeeOther, c.Writer.Status())
+		assert.False(t, stored.Admin)
+	})
+}
+
+func TestGetLogout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer func() { server.Config.Server.Cookie = httputil.CookieOptions{} }()
+	server.Config.Server.Cookie = httputil.CookieOptions{Path: "/ci"}
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = &http.Request{Header: make(http.Header), URL: &url.URL{}}
+
+	api.GetLogout(c)
+
+	assert.Equal(t, http.StatusSeeOther, c.Writer.Status())
+	assert.Equal(t, []string{
+		"user_sess=deleted; Path=/ci; Max-Age=0; HttpOnly",
+		"user_last=deleted; Path=/ci; Max-Age=0; HttpOnly",
+		"user_sess=deleted; Path=/; Max-Age=0; HttpOnly",
+	}, w.Header().Values("Set-Cookie"))
 }
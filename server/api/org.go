@@ -61,6 +61,47 @@ func GetOrg(c *gin.Context) {
 	c.JSON(http.StatusOK, org)
 }
 
+// PatchOrg
+//
+//	@Summary		Update an organization
+//	@Description	Updates the feature flag overrides of the given org. Requires admin rights.
+//	@Router			/orgs/{org_id} [patch]
+//	@Produce		json
+//	@Success		200	{object}	Org
+//	@Tags			Orgs
+//	@Param			Authorization	header	string		true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			org_id			path	string		true	"the organization's id"
+//	@Param			org				body	OrgPatch	true	"the org's data"
+func PatchOrg(c *gin.Context) {
+	_store := store.FromContext(c)
+	org := session.Org(c)
+
+	in := new(model.OrgPatch)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing request. %s", err)
+		return
+	}
+
+	if in.FeatureFlags != nil {
+		if in.FeatureFlags.DefaultTimeout != nil && *in.FeatureFlags.DefaultTimeout <= 0 {
+			c.String(http.StatusBadRequest, "Default timeout has to be a positive number of minutes")
+			return
+		}
+		if in.FeatureFlags.MaxRunningPipelines != nil && *in.FeatureFlags.MaxRunningPipelines < 0 {
+			c.String(http.StatusBadRequest, "Max running pipelines must not be negative")
+			return
+		}
+		org.FeatureFlags = *in.FeatureFlags
+	}
+
+	if err := _store.OrgUpdate(org); err != nil {
+		c.String(http.StatusInternalServerError, "Error updating org. %s", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, org)
+}
+
 // GetOrgPermissions
 //
 //	@Summary	Get the permissions of the currently authenticated user for the given organization
@@ -86,7 +127,7 @@ func GetOrgPermissions(c *gin.Context) {
 		return
 	}
 
-	if (org.IsUser && org.Name == user.Login) || (user.Admin && !org.IsUser) {
+	if (org.IsUser && org.Name == user.Login) || (session.IsAdmin(c) && !org.IsUser) {
 		c.JSON(http.StatusOK, &model.OrgPerm{
 			Member: true,
 			Admin:  true,
@@ -141,10 +182,10 @@ func LookupOrg(c *gin.Context) {
 			return
 		}
 
-		if !user.Admin && org.Name != user.Login {
+		if !session.IsAdmin(c) && org.Name != user.Login {
 			c.AbortWithStatus(http.StatusNotFound)
 			return
-		} else if !user.Admin {
+		} else if !session.IsAdmin(c) {
 			perm, err := server.Config.Services.Membership.Get(c, _forge, user, org.Name)
 			if err != nil {
 				log.Error().Err(err).Msg("failed to check membership")
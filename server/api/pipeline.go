@@ -581,6 +581,110 @@ func GetPipelineQueue(c *gin.Context) {
 	c.JSON(http.StatusOK, out)
 }
 
+// QueryPipelines
+//
+//	@Summary		Query pipelines
+//	@Description	Query pipelines across all active repositories the user has access to. Admins can query the pipelines of all active repositories.
+//	@Router			/pipelines/query [get]
+//	@Produce		json
+//	@Success		200	{array}	Feed
+//	@Tags			Pipelines
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			page			query	int		false	"for response pagination, page offset number"	default(1)
+//	@Param			perPage			query	int		false	"for response pagination, max items per page"	default(50)
+//	@Param			repo_id			query	string	false	"filter pipelines by repository ids (comma separated)"
+//	@Param			event			query	string	false	"filter pipelines by webhook events (comma separated)"
+//	@Param			status			query	string	false	"filter pipelines by status (comma separated)"
+//	@Param			author			query	string	false	"filter pipelines by author (comma separated)"
+//	@Param			before			query	string	false	"only return pipelines created before this RFC3339 date"
+//	@Param			after			query	string	false	"only return pipelines created after this RFC3339 date"
+//	@Param			min_duration	query	int		false	"only return finished pipelines running at least this many seconds"
+//	@Param			max_duration	query	int		false	"only return finished pipelines running at most this many seconds"
+//	@Param			sort_by			query	string	false	"sort pipelines by created, started, finished or duration"	default(created)
+//	@Param			ascending		query	bool	false	"sort pipelines in ascending order"
+func QueryPipelines(c *gin.Context) {
+	query, err := parsePipelineQuery(c)
+	if err != nil {
+		c.String(http.StatusBadRequest, "Error parsing query. %s", err)
+		return
+	}
+	if err := query.Validate(); err != nil {
+		c.String(http.StatusBadRequest, "Error validating query. %s", err)
+		return
+	}
+
+	user := session.User(c)
+	if session.IsAdmin(c) {
+		// admins can see the pipelines of all repos
+		user = nil
+	}
+
+	out, err := store.FromContext(c).QueryPipelines(user, query, session.Pagination(c))
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error querying pipelines. %s", err)
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
+
+// parsePipelineQuery reads the pipeline query from the query parameters, lists are comma separated.
+func parsePipelineQuery(c *gin.Context) (*model.PipelineQuery, error) {
+	query := &model.PipelineQuery{
+		SortBy:    model.PipelineSortField(c.Query("sort_by")),
+		Ascending: c.Query("ascending") == "true",
+	}
+
+	for _, repoID := range splitQuery(c, "repo_id") {
+		id, err := strconv.ParseInt(repoID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		query.RepoIDs = append(query.RepoIDs, id)
+	}
+	for _, event := range splitQuery(c, "event") {
+		query.Events = append(query.Events, model.WebhookEvent(event))
+	}
+	for _, status := range splitQuery(c, "status") {
+		query.Statuses = append(query.Statuses, model.StatusValue(status))
+	}
+	query.Authors = splitQuery(c, "author")
+
+	if before := c.Query("before"); before != "" {
+		beforeDt, err := time.Parse(time.RFC3339, before)
+		if err != nil {
+			return nil, err
+		}
+		query.Before = beforeDt.Unix()
+	}
+	if after := c.Query("after"); after != "" {
+		afterDt, err := time.Parse(time.RFC3339, after)
+		if err != nil {
+			return nil, err
+		}
+		query.After = afterDt.Unix()
+	}
+
+	for name, duration := range map[string]*int64{"min_duration": &query.MinDuration, "max_duration": &query.MaxDuration} {
+		if value := c.Query(name); value != "" {
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			*duration = seconds
+		}
+	}
+
+	return query, nil
+}
+
+func splitQuery(c *gin.Context, name string) []string {
+	value := c.Query(name)
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
 // PostPipeline
 //
 //	@Summary		Restart a pipeline
@@ -594,6 +698,7 @@ func GetPipelineQueue(c *gin.Context) {
 //	@Param			number			path	int		true	"the number of the pipeline"
 //	@Param			event			query	string	false	"override the event type"
 //	@Param			deploy_to		query	string	false	"override the target deploy value"
+//	@Param			force			query	bool	false	"run the pipeline even if the result of an identical pipeline could be reused"
 func PostPipeline(c *gin.Context) {
 	_store := store.FromContext(c)
 	repo := session.Repo(c)
@@ -646,7 +751,7 @@ func PostPipeline(c *gin.Context) {
 	for key, val := range c.Request.URL.Query() {
 		switch key {
 		// Skip some options of the endpoint
-		case "fork", "event", "deploy_to":
+		case "fork", "event", "deploy_to", "force":
 			continue
 		default:
 			// We only accept string literals, because pipeline parameters will be
@@ -656,7 +761,8 @@ func PostPipeline(c *gin.Context) {
 		}
 	}
 
-	newPipeline, err := pipeline.Restart(c, _store, pl, user, repo, envs)
+	force, _ := strconv.ParseBool(c.Query("force"))
+	newPipeline, err := pipeline.Restart(c, _store, pl, user, repo, envs, force)
 	if err != nil {
 		handlePipelineErr(c, err)
 	} else {
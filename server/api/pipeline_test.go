@@ -116,6 +116,84 @@ func TestGetPipelines(t *testing.T) {
 	})
 }
 
+func TestQueryPipelines(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	user := &model.User{ID: 1, Login: "joe"}
+	feed := []*model.Feed{{ID: fakePipeline.ID, Number: fakePipeline.Number}}
+
+	t.Run("should paginate and scope to user", func(t *testing.T) {
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("QueryPipelines", user, &model.PipelineQuery{Authors: []string{"joe"}}, &model.ListOptions{Page: 2, PerPage: 10}).Return(feed, nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("store", mockStore)
+		c.Set("user", user)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/?page=2&perPage=10&author=joe", nil)
+
+		QueryPipelines(c)
+
+		assert.Equal(t, http.StatusOK, c.Writer.Status())
+		var out []*model.Feed
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &out))
+		assert.Equal(t, feed, out)
+	})
+
+	t.Run("should parse all filters", func(t *testing.T) {
+		query := &model.PipelineQuery{
+			RepoIDs:     []int64{1, 2},
+			Events:      []model.WebhookEvent{model.EventPush, model.EventTag},
+			Statuses:    []model.StatusValue{model.StatusFailure},
+			Authors:     []string{"joe", "jane"},
+			After:       1735689600,
+			Before:      1735776000,
+			MinDuration: 60,
+			MaxDuration: 3600,
+			SortBy:      model.PipelineSortDuration,
+			Ascending:   true,
+		}
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("QueryPipelines", user, query, &model.ListOptions{Page: 1, PerPage: 50}).Return(feed, nil)
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("store", mockStore)
+		c.Set("user", user)
+		c.Request, _ = http.NewRequest(http.MethodGet, "/?repo_id=1,2&event=push,tag&status=failure&author=joe,jane"+
+			"&after=2025-01-01T00:00:00Z&before=2025-01-02T00:00:00Z&min_duration=60&max_duration=3600&sort_by=duration&ascending=true", nil)
+
+		QueryPipelines(c)
+
+		assert.Equal(t, http.StatusOK, c.Writer.Status())
+	})
+
+	t.Run("should query all repos as admin", func(t *testing.T) {
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("QueryPipelines", (*model.User)(nil), &model.PipelineQuery{}, &model.ListOptions{Page: 1, PerPage: 50}).Return(feed, nil)
+
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Set("store", mockStore)
+		c.Set("user", &model.User{ID: 2, Admin: true})
+		c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+
+		QueryPipelines(c)
+
+		assert.Equal(t, http.StatusOK, c.Writer.Status())
+	})
+
+	for _, rawQuery := range []string{"sort_by=author", "repo_id=octocat", "after=yesterday", "min_duration=1m", "event=commit"} {
+		t.Run("should reject invalid query "+rawQuery, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Set("user", user)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/?"+rawQuery, nil)
+
+			QueryPipelines(c)
+
+			assert.Equal(t, http.StatusBadRequest, c.Writer.Status())
+		})
+	}
+}
+
 func TestDeletePipeline(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
@@ -20,6 +20,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"time"
 
@@ -31,6 +33,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/session"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log/file"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
@@ -87,12 +90,28 @@ func PostRepo(c *gin.Context) {
 		return
 	}
 
+	if !enabledOnce {
+		repo = nil
+	}
+	repo, err = activateRepo(c, _store, _forge, user, repo, from)
+	if err != nil {
+		log.Error().Err(err).Msg("could not activate repo")
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, repo)
+}
+
+// activateRepo activates the forge repository from for the user and registers its webhook.
+// The stored repo is nil if the repository was never activated before.
+func activateRepo(c *gin.Context, _store store.Store, _forge forge.Forge, user *model.User, repo, from *model.Repo) (*model.Repo, error) {
+	enabledOnce := repo != nil
 	if enabledOnce {
 		repo.Update(from)
 	} else {
 		repo = from
 		repo.RequireApproval = server.Config.Pipeline.DefaultApprovalMode
-		repo.AllowPull = server.Config.Pipeline.DefaultAllowPullRequests
 		repo.AllowDeploy = false
 		repo.CancelPreviousPipelineEvents = server.Config.Pipeline.DefaultCancelPreviousPipelineEvents
 		repo.ForgeID = user.ForgeID // TODO: allow to use other connected forges of the user
@@ -107,12 +126,6 @@ func PostRepo(c *gin.Context) {
 		}
 	}
 
-	if repo.Timeout == 0 {
-		repo.Timeout = server.Config.Pipeline.DefaultTimeout
-	} else if repo.Timeout > server.Config.Pipeline.MaxTimeout {
-		repo.Timeout = server.Config.Pipeline.MaxTimeout
-	}
-
 	if repo.Hash == "" {
 		repo.Hash = base32.StdEncoding.EncodeToString(
 			random.GetRandomBytes(32),
@@ -120,59 +133,55 @@ func PostRepo(c *gin.Context) {
 	}
 
 	// find org of repo
-	var org *model.Org
-	org, err = _store.OrgFindByName(repo.Owner, user.ForgeID)
+	org, err := _store.OrgFindByName(repo.Owner, user.ForgeID)
 	if err != nil && !errors.Is(err, types.RecordNotExist) {
-		c.String(http.StatusInternalServerError, err.Error())
-		return
+		return nil, err
 	}
 
 	// create an org if it doesn't exist yet
 	if errors.Is(err, types.RecordNotExist) {
 		org, err = _forge.Org(c, user, repo.Owner)
 		if err != nil {
-			msg := fmt.Sprintf("Organization %s not found in DB. Attempting to create new one.", repo.Owner)
-			log.Error().Err(err).Msg(msg)
-			c.String(http.StatusInternalServerError, msg)
-			return
+			return nil, fmt.Errorf("organization %s not found in DB. Attempting to create new one: %w", repo.Owner, err)
 		}
 
 		org.ForgeID = user.ForgeID
 		err = _store.OrgCreate(org)
 		if err != nil {
-			msg := fmt.Sprintf("Failed to create organization %s.", repo.Owner)
-			log.Error().Err(err).Msg(msg)
-			c.String(http.StatusInternalServerError, msg)
-			return
+			return nil, fmt.Errorf("failed to create organization %s: %w", repo.Owner, err)
 		}
 	}
 
 	repo.OrgID = org.ID
 
+	flags := server.FeatureFlags(org, repo)
+	if !enabledOnce {
+		repo.AllowPull = flags.AllowPullRequests
+	}
+	if repo.Timeout == 0 {
+		repo.Timeout = flags.DefaultTimeout
+	} else if maxTimeout := server.MaxTimeout(repo); repo.Timeout > maxTimeout {
+		repo.Timeout = maxTimeout
+	}
+
 	// creates the jwt token used to verify the repository
 	t := token.New(token.HookToken)
-	t.Set("repo-forge-remote-id", string(forgeRemoteID))
+	t.Set("repo-forge-remote-id", string(repo.ForgeRemoteID))
 	t.Set("forge-id", strconv.FormatInt(repo.ForgeID, 10))
 	sig, err := t.Sign(repo.Hash)
 	if err != nil {
-		msg := "could not generate new jwt token."
-		log.Error().Err(err).Msg(msg)
-		c.String(http.StatusInternalServerError, msg)
-		return
+		return nil, fmt.Errorf("could not generate new jwt token: %w", err)
 	}
 
 	hookURL := fmt.Sprintf(
 		"%s/api/hook?access_token=%s",
-		server.Config.Server.WebhookHost,
+		server.WebhookHost(repo.ForgeID),
 		sig,
 	)
 
 	err = _forge.Activate(c, user, repo, hookURL)
 	if err != nil {
-		msg := "could not create webhook in forge."
-		log.Error().Err(err).Msg(msg)
-		c.String(http.StatusInternalServerError, msg)
-		return
+		return nil, fmt.Errorf("could not create webhook in forge: %w", err)
 	}
 
 	if enabledOnce {
@@ -181,10 +190,7 @@ func PostRepo(c *gin.Context) {
 		err = _store.CreateRepo(repo)
 	}
 	if err != nil {
-		msg := "could not create/update repo in store."
-		log.Error().Err(err).Msg(msg)
-		c.String(http.StatusInternalServerError, msg)
-		return
+		return nil, fmt.Errorf("could not create/update repo in store: %w", err)
 	}
 
 	repo.Perm = from.Perm
@@ -192,13 +198,11 @@ func PostRepo(c *gin.Context) {
 	repo.Perm.UserID = user.ID
 	repo.Perm.RepoID = repo.ID
 	repo.Perm.Repo = repo
-	err = _store.PermUpsert(repo.Perm)
-	if err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
-		return
+	if err := _store.PermUpsert(repo.Perm); err != nil {
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, repo)
+	return repo, nil
 }
 
 // PatchRepo
@@ -222,13 +226,90 @@ func PatchRepo(c *gin.Context) {
 		return
 	}
 
-	if in.Timeout != nil && *in.Timeout > server.Config.Pipeline.MaxTimeout && !user.Admin {
-		c.String(http.StatusForbidden, fmt.Sprintf("Timeout is not allowed to be higher than max timeout (%d min)", server.Config.Pipeline.MaxTimeout))
+	if maxTimeout := server.MaxTimeout(repo); in.Timeout != nil && *in.Timeout > maxTimeout && !session.IsAdmin(c) {
+		c.String(http.StatusForbidden, fmt.Sprintf("Timeout is not allowed to be higher than max timeout (%d min)", maxTimeout))
+		return
+	}
+
+	if in.MaxTimeout != nil && *in.MaxTimeout != repo.MaxTimeout && !session.IsAdmin(c) {
+		log.Trace().Msgf("user '%s' wants to change the max timeout without being an instance admin", user.Login)
+		c.String(http.StatusForbidden, "Insufficient privileges")
+		return
+	}
+	if in.LogStore != nil && *in.LogStore != repo.LogStore {
+		if !session.IsAdmin(c) {
+			log.Trace().Msgf("user '%s' wants to change the log store without being an instance admin", user.Login)
+			c.String(http.StatusForbidden, "Insufficient privileges")
+			return
+		}
+		if *in.LogStore != "" && !slices.Contains(server.Config.Logs.Stores, *in.LogStore) {
+			c.String(http.StatusBadRequest, fmt.Sprintf("Log store '%s' is not configured", *in.LogStore))
+			return
+		}
+	}
+	if in.MaxTimeout != nil && *in.MaxTimeout < 0 {
+		c.String(http.StatusBadRequest, "Max timeout must not be negative")
+		return
+	}
+	if in.MaxMatrixJobs != nil && *in.MaxMatrixJobs != repo.MaxMatrixJobs && !session.IsAdmin(c) {
+		log.Trace().Msgf("user '%s' wants to change the max matrix jobs without being an instance admin", user.Login)
+		c.String(http.StatusForbidden, "Insufficient privileges")
+		return
+	}
+	if in.MaxMatrixJobs != nil && *in.MaxMatrixJobs < 0 {
+		c.String(http.StatusBadRequest, "Max matrix jobs must not be negative")
+		return
+	}
+	if (in.LogMaxSize != nil && *in.LogMaxSize != repo.LogMaxSize) || (in.LogMaxLines != nil && *in.LogMaxLines != repo.LogMaxLines) {
+		if !session.IsAdmin(c) {
+			log.Trace().Msgf("user '%s' wants to change the log limits without being an instance admin", user.Login)
+			c.String(http.StatusForbidden, "Insufficient privileges")
+			return
+		}
+		if (in.LogMaxSize != nil && *in.LogMaxSize < 0) || (in.LogMaxLines != nil && *in.LogMaxLines < 0) {
+			c.String(http.StatusBadRequest, "Log limits must not be negative")
+			return
+		}
+	}
+	var logRetention time.Duration
+	if in.LogRetention != nil {
+		if !session.IsAdmin(c) {
+			log.Trace().Msgf("user '%s' wants to change the log retention without being an instance admin", user.Login)
+			c.String(http.StatusForbidden, "Insufficient privileges")
+			return
+		}
+		if *in.LogRetention != "" {
+			var err error
+			if logRetention, err = time.ParseDuration(*in.LogRetention); err != nil || logRetention < 0 {
+				c.String(http.StatusBadRequest, "Log retention must be a non-negative duration")
+				return
+			}
+		}
+	}
+
+	if in.Priority != nil && *in.Priority != repo.Priority && !session.IsAdmin(c) {
+		log.Trace().Msgf("user '%s' wants to change the priority without being an instance admin", user.Login)
+		c.String(http.StatusForbidden, "Insufficient privileges")
+		return
+	}
+
+	if (in.PrivilegedPlugins != nil && !slices.Equal(*in.PrivilegedPlugins, repo.PrivilegedPlugins)) ||
+		(in.TrustedClonePlugins != nil && !slices.Equal(*in.TrustedClonePlugins, repo.TrustedClonePlugins)) {
+		if !session.IsAdmin(c) {
+			log.Trace().Msgf("user '%s' wants to change the privileged or trusted clone plugins without being an instance admin", user.Login)
+			c.String(http.StatusForbidden, "Insufficient privileges")
+			return
+		}
+	}
+
+	if in.ExposeSecretsToForks != nil && (repo.ExposeSecretsToForks == nil || *in.ExposeSecretsToForks != *repo.ExposeSecretsToForks) && !session.IsAdmin(c) {
+		log.Trace().Msgf("user '%s' wants to change the exposure of secrets to forks without being an instance admin", user.Login)
+		c.String(http.StatusForbidden, "Insufficient privileges")
 		return
 	}
 
 	if in.Trusted != nil {
-		if (*in.Trusted.Network != repo.Trusted.Network || *in.Trusted.Volumes != repo.Trusted.Volumes || *in.Trusted.Security != repo.Trusted.Security) && !user.Admin {
+		if (*in.Trusted.Network != repo.Trusted.Network || *in.Trusted.Volumes != repo.Trusted.Volumes || *in.Trusted.Security != repo.Trusted.Security) && !session.IsAdmin(c) {
 			log.Trace().Msgf("user '%s' wants to change trusted without being an instance admin", user.Login)
 			c.String(http.StatusForbidden, "Insufficient privileges")
 			return
@@ -251,6 +332,15 @@ func PatchRepo(c *gin.Context) {
 	if in.AllowDeploy != nil {
 		repo.AllowDeploy = *in.AllowDeploy
 	}
+	if in.ResultCache != nil {
+		repo.ResultCache = *in.ResultCache
+	}
+	if in.WorkflowStatusChecks != nil {
+		repo.WorkflowStatusChecks = *in.WorkflowStatusChecks
+	}
+	if in.ExposeSecretsToForks != nil {
+		repo.ExposeSecretsToForks = in.ExposeSecretsToForks
+	}
 
 	if in.RequireApproval != nil {
 		if mode := model.ApprovalMode(*in.RequireApproval); mode.Valid() {
@@ -266,6 +356,24 @@ func PatchRepo(c *gin.Context) {
 	if in.Timeout != nil {
 		repo.Timeout = *in.Timeout
 	}
+	if in.MaxTimeout != nil {
+		repo.MaxTimeout = *in.MaxTimeout
+	}
+	if in.MaxMatrixJobs != nil {
+		repo.MaxMatrixJobs = *in.MaxMatrixJobs
+	}
+	if in.LogStore != nil {
+		repo.LogStore = *in.LogStore
+	}
+	if in.LogMaxSize != nil {
+		repo.LogMaxSize = *in.LogMaxSize
+	}
+	if in.LogMaxLines != nil {
+		repo.LogMaxLines = *in.LogMaxLines
+	}
+	if in.Priority != nil {
+		repo.Priority = *in.Priority
+	}
 	if in.Config != nil {
 		repo.Config = *in.Config
 	}
@@ -275,6 +383,12 @@ func PatchRepo(c *gin.Context) {
 	if in.NetrcTrusted != nil {
 		repo.NetrcTrustedPlugins = *in.NetrcTrusted
 	}
+	if in.PrivilegedPlugins != nil {
+		repo.PrivilegedPlugins = *in.PrivilegedPlugins
+	}
+	if in.TrustedClonePlugins != nil {
+		repo.TrustedClonePlugins = *in.TrustedClonePlugins
+	}
 	if in.Visibility != nil {
 		switch *in.Visibility {
 		case string(model.VisibilityInternal), string(model.VisibilityPrivate), string(model.VisibilityPublic):
@@ -287,6 +401,27 @@ func PatchRepo(c *gin.Context) {
 	if in.ConfigExtensionEndpoint != nil {
 		repo.ConfigExtensionEndpoint = *in.ConfigExtensionEndpoint
 	}
+	if in.NotifyURL != nil {
+		if *in.NotifyURL != "" {
+			if u, err := url.Parse(*in.NotifyURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				c.String(http.StatusBadRequest, "Invalid notify url")
+				return
+			}
+		}
+		repo.NotifyURL = *in.NotifyURL
+	}
+	if in.NotifySecret != nil {
+		repo.NotifySecret = *in.NotifySecret
+	}
+	if in.NotifyEvents != nil {
+		for _, event := range *in.NotifyEvents {
+			if err := event.Validate(); err != nil {
+				c.String(http.StatusBadRequest, err.Error())
+				return
+			}
+		}
+		repo.NotifyEvents = *in.NotifyEvents
+	}
 
 	err := _store.UpdateRepo(repo)
 	if err != nil {
@@ -294,6 +429,18 @@ func PatchRepo(c *gin.Context) {
 		return
 	}
 
+	if in.LogRetention != nil {
+		if *in.LogRetention == "" {
+			err = file.DeleteRepoRetention(_store, repo.ID)
+		} else {
+			err = file.SetRepoRetention(_store, repo.ID, logRetention)
+		}
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	c.JSON(http.StatusOK, repo)
 }
 
@@ -459,7 +606,7 @@ func DeleteRepo(c *gin.Context) {
 		return
 	}
 
-	if err := _forge.Deactivate(c, user, repo, server.Config.Server.WebhookHost); err != nil {
+	if err := _forge.Deactivate(c, user, repo, server.WebhookHost(repo.ForgeID)); err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, err)
 		return
 	}
@@ -484,15 +631,52 @@ func DeleteRepo(c *gin.Context) {
 
 // RepairRepo
 //
-//	@Summary	Repair a repository
-//	@Router		/repos/{repo_id}/repair [post]
-//	@Produce	plain
-//	@Success	204
-//	@Tags		Repositories
-//	@Param		Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
-//	@Param		repo_id			path	int		true	"the repository id"
+//	@Summary		Repair a repository
+//	@Description	Re-registers the webhook of the repository and syncs its data with the forge. Returns what was fixed.
+//	@Router			/repos/{repo_id}/repair [post]
+//	@Produce		json
+//	@Success		200	{object}	RepoRepairReport
+//	@Tags			Repositories
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			repo_id			path	int		true	"the repository id"
 func RepairRepo(c *gin.Context) {
 	repo := session.Repo(c)
+	report := repairRepo(c, repo, true, false)
+	if c.Writer.Written() {
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+const defaultWebhookSecretGracePeriod = time.Hour
+
+// RotateRepoHook
+//
+//	@Summary		Rotate the webhook secret of a repository
+//	@Description	Creates a new secret to verify the webhooks of the repository and re-registers the webhook. Webhooks using the previous secret are accepted for the grace period.
+//	@Router			/repos/{repo_id}/hook/rotate [post]
+//	@Success		204
+//	@Tags			Repositories
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			repo_id			path	int		true	"the repository id"
+//	@Param			grace_period	query	string	false	"duration the previous secret is accepted (default 1h)"
+func RotateRepoHook(c *gin.Context) {
+	repo := session.Repo(c)
+
+	grace := defaultWebhookSecretGracePeriod
+	if value := c.Query("grace_period"); value != "" {
+		var err error
+		grace, err = time.ParseDuration(value)
+		if err != nil || grace < 0 {
+			c.String(http.StatusBadRequest, fmt.Sprintf("invalid grace period '%s'", value))
+			return
+		}
+	}
+
+	now := time.Now()
+	repo.RotateHash(base32.StdEncoding.EncodeToString(random.GetRandomBytes(32)), now.Unix(), now.Add(grace).Unix())
+
+	// repairing stores the new secret and registers the webhook using it
 	repairRepo(c, repo, true, false)
 	if c.Writer.Written() {
 		return
@@ -500,6 +684,27 @@ func RepairRepo(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
+// PruneRepoHook
+//
+//	@Summary		Revoke the previous webhook secrets of a repository
+//	@Description	Webhooks are only accepted with the current secret afterwards, even if the grace period of a previous secret is not over yet.
+//	@Router			/repos/{repo_id}/hook/prune [post]
+//	@Success		204
+//	@Tags			Repositories
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			repo_id			path	int		true	"the repository id"
+func PruneRepoHook(c *gin.Context) {
+	_store := store.FromContext(c)
+	repo := session.Repo(c)
+
+	repo.PreviousHashes = nil
+	if err := _store.UpdateRepo(repo); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
 // MoveRepo
 //
 //	@Summary	Move a repository to a new owner
@@ -574,7 +779,7 @@ func MoveRepo(c *gin.Context) {
 	}
 
 	// reconstruct the hook url
-	host := server.Config.Server.WebhookHost
+	host := server.WebhookHost(repo.ForgeID)
 	hookURL := fmt.Sprintf(
 		"%s/api/hook?access_token=%s",
 		host,
@@ -645,30 +850,32 @@ func RepairAllRepos(c *gin.Context) {
 	c.Status(http.StatusNoContent)
 }
 
-func repairRepo(c *gin.Context, repo *model.Repo, withPerms, skipOnErr bool) {
+func repairRepo(c *gin.Context, repo *model.Repo, withPerms, skipOnErr bool) *model.RepoRepairReport {
 	_store := store.FromContext(c)
+	report := &model.RepoRepairReport{RepoID: repo.ID, Changes: []string{}}
 	_forge, err := server.Config.Services.Manager.ForgeFromRepo(repo)
 	if err != nil {
 		log.Error().Err(err).Msg("Cannot get forge from repo")
 		c.AbortWithStatus(http.StatusInternalServerError)
-		return
+		return nil
 	}
 
 	user, err := _store.GetUser(repo.UserID)
 	if err != nil {
-		if errors.Is(err, types.RecordNotExist) {
-			oldUserID := repo.UserID
-			user = session.User(c)
-			repo.UserID = user.ID
-			err = _store.UpdateRepo(repo)
-			if err != nil {
-				_ = c.AbortWithError(http.StatusInternalServerError, err)
-			}
-			log.Debug().Msgf("Could not find repo user with ID %d during repo repair, set to repair request user with ID %d", oldUserID, user.ID)
-		} else {
+		if !errors.Is(err, types.RecordNotExist) {
 			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return nil
 		}
-		return
+
+		oldUserID := repo.UserID
+		user = session.User(c)
+		repo.UserID = user.ID
+		if err := _store.UpdateRepo(repo); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, err)
+			return nil
+		}
+		log.Debug().Msgf("Could not find repo user with ID %d during repo repair, set to repair request user with ID %d", oldUserID, user.ID)
+		report.Changes = append(report.Changes, fmt.Sprintf("replaced missing repository user with %s", user.Login))
 	}
 
 	// creates the jwt token used to verify the repository
@@ -678,11 +885,11 @@ func repairRepo(c *gin.Context, repo *model.Repo, withPerms, skipOnErr bool) {
 	sig, err := t.Sign(repo.Hash)
 	if err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
-		return
+		return nil
 	}
 
 	// reconstruct the hook url
-	host := server.Config.Server.WebhookHost
+	host := server.WebhookHost(repo.ForgeID)
 	hookURL := fmt.Sprintf(
 		"%s/api/hook?access_token=%s",
 		host,
@@ -695,7 +902,7 @@ func repairRepo(c *gin.Context, repo *model.Repo, withPerms, skipOnErr bool) {
 		if !skipOnErr {
 			c.AbortWithStatus(http.StatusInternalServerError)
 		}
-		return
+		return nil
 	}
 
 	if repo.FullName != from.FullName {
@@ -703,22 +910,28 @@ func repairRepo(c *gin.Context, repo *model.Repo, withPerms, skipOnErr bool) {
 		err = _store.CreateRedirection(&model.Redirection{RepoID: repo.ID, FullName: repo.FullName})
 		if err != nil {
 			_ = c.AbortWithError(http.StatusInternalServerError, err)
-			return
+			return nil
 		}
+		report.Changes = append(report.Changes, fmt.Sprintf("renamed from %s to %s", repo.FullName, from.FullName))
 	}
 
+	before := *repo
 	repo.Update(from)
+	report.Changes = append(report.Changes, repoSyncChanges(&before, repo)...)
 	if err := _store.UpdateRepo(repo); err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, err)
-		return
+		return nil
 	}
-	if withPerms {
+	if withPerms && repo.Perm != nil && from.Perm != nil {
+		if repo.Perm.Pull != from.Perm.Pull || repo.Perm.Push != from.Perm.Push || repo.Perm.Admin != from.Perm.Admin {
+			report.Changes = append(report.Changes, "updated permissions")
+		}
 		repo.Perm.Pull = from.Perm.Pull
 		repo.Perm.Push = from.Perm.Push
 		repo.Perm.Admin = from.Perm.Admin
 		if err := _store.PermUpsert(repo.Perm); err != nil {
 			_ = c.AbortWithError(http.StatusInternalServerError, err)
-			return
+			return nil
 		}
 	}
 
@@ -727,6 +940,30 @@ func repairRepo(c *gin.Context, repo *model.Repo, withPerms, skipOnErr bool) {
 	}
 	if err := _forge.Activate(c, user, repo, hookURL); err != nil {
 		c.String(http.StatusInternalServerError, err.Error())
-		return
+		return nil
+	}
+	report.Changes = append(report.Changes, fmt.Sprintf("registered webhook for %s", host))
+
+	return report
+}
+
+// repoSyncChanges lists the forge data of a repository changed by syncing it.
+func repoSyncChanges(before, after *model.Repo) []string {
+	var changes []string
+	if before.ForgeRemoteID != after.ForgeRemoteID {
+		changes = append(changes, "updated forge remote id")
+	}
+	if before.ForgeURL != after.ForgeURL {
+		changes = append(changes, "updated forge url")
+	}
+	if before.Clone != after.Clone || before.CloneSSH != after.CloneSSH {
+		changes = append(changes, "updated clone urls")
+	}
+	if before.Branch != after.Branch {
+		changes = append(changes, fmt.Sprintf("updated default branch from %s to %s", before.Branch, after.Branch))
+	}
+	if before.IsSCMPrivate != after.IsSCMPrivate {
+		changes = append(changes, fmt.Sprintf("updated visibility to %s", after.Visibility))
 	}
+	return changes
 }
@@ -0,0 +1,368 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	forge_mocks "go.woodpecker-ci.org/woodpecker/v3/server/forge/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log/file"
+	manager_mocks "go.woodpecker-ci.org/woodpecker/v3/server/services/mocks"
+	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
+)
+
+func TestRepairRepo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server.Config.Server.WebhookHost = "https://ci.example.com"
+
+	user := &model.User{ID: 1, Login: "octocat"}
+	newRepo := func() *model.Repo {
+		return &model.Repo{
+			ID:            2,
+			UserID:        1,
+			ForgeID:       1,
+			ForgeRemoteID: "3",
+			Owner:         "octocat",
+			Name:          "hello-world",
+			FullName:      "octocat/hello-world",
+			Branch:        "main",
+			Hash:          "secret",
+			Perm:          &model.Perm{Pull: true, Push: true, Admin: true},
+		}
+	}
+
+	// repair runs the repair endpoint with a stub forge returning the given forge repo
+	// and records the hook urls registered at the forge.
+	repair := func(t *testing.T, repo, from *model.Repo, mockStore *store_mocks.MockStore) (*httptest.ResponseRecorder, []string) {
+		var activated []string
+		mockForge := forge_mocks.NewMockForge(t)
+		mockForge.On("Repo", mock.Anything, user, repo.ForgeRemoteID, repo.Owner, repo.Name).Return(from, nil)
+		mockForge.On("Deactivate", mock.Anything, user, repo, "https://ci.example.com").Return(nil)
+		mockForge.On("Activate", mock.Anything, user, repo, mock.Anything).Run(func(args mock.Arguments) {
+			activated = append(activated, args.String(3))
+		}).Return(nil)
+
+		mockManager := manager_mocks.NewMockManager(t)
+		mockManager.On("ForgeFromRepo", repo).Return(mockForge, nil)
+		server.Config.Services.Manager = mockManager
+
+		mockStore.On("UpdateRepo", repo).Return(nil)
+		mockStore.On("PermUpsert", repo.Perm).Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("store", mockStore)
+		c.Set("repo", repo)
+		c.Set("perm", repo.Perm)
+		c.Set("user", user)
+
+		RepairRepo(c)
+		return w, activated
+	}
+
+	t.Run("should re-register webhook", func(t *testing.T) {
+		repo := newRepo()
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("GetUser", int64(1)).Return(user, nil)
+
+		w, activated := repair(t, repo, newRepo(), mockStore)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		if assert.Len(t, activated, 1) {
+			assert.True(t, strings.HasPrefix(activated[0], "https://ci.example.com/api/hook?access_token="))
+		}
+		var report model.RepoRepairReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, model.RepoRepairReport{
+			RepoID:  2,
+			Changes: []string{"registered webhook for https://ci.example.com"},
+		}, report)
+	})
+
+	t.Run("should be idempotent", func(t *testing.T) {
+		repo := newRepo()
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("GetUser", int64(1)).Return(user, nil)
+
+		first, _ := repair(t, repo, newRepo(), mockStore)
+		second, activated := repair(t, repo, newRepo(), mockStore)
+
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Len(t, activated, 1)
+		assert.JSONEq(t, first.Body.String(), second.Body.String())
+	})
+
+	t.Run("should report renamed repo", func(t *testing.T) {
+		repo := newRepo()
+		from := newRepo()
+		from.Owner = "octo-org"
+		from.FullName = "octo-org/hello-world"
+		from.Branch = "develop"
+		from.Perm = &model.Perm{Pull: true, Push: true}
+
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("GetUser", int64(1)).Return(user, nil)
+		mockStore.On("CreateRedirection", &model.Redirection{RepoID: 2, FullName: "octocat/hello-world"}).Return(nil)
+
+		w, activated := repair(t, repo, from, mockStore)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, activated, 1)
+		var report model.RepoRepairReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, []string{
+			"renamed from octocat/hello-world to octo-org/hello-world",
+			"updated default branch from main to develop",
+			"updated permissions",
+			"registered webhook for https://ci.example.com",
+		}, report.Changes)
+		assert.Equal(t, "octo-org/hello-world", repo.FullName)
+		assert.False(t, repo.Perm.Admin)
+	})
+
+	t.Run("should replace missing repo user", func(t *testing.T) {
+		repo := newRepo()
+		repo.UserID = 5
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("GetUser", int64(5)).Return(nil, types.RecordNotExist)
+
+		w, activated := repair(t, repo, newRepo(), mockStore)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, activated, 1)
+		assert.Equal(t, int64(1), repo.UserID)
+		var report model.RepoRepairReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, []string{
+			"replaced missing repository user with octocat",
+			"registered webhook for https://ci.example.com",
+		}, report.Changes)
+	})
+}
+
+func TestRotateRepoHook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server.Config.Server.WebhookHost = "https://ci.example.com"
+
+	user := &model.User{ID: 1, Login: "octocat"}
+	repo := &model.Repo{
+		ID:            2,
+		UserID:        1,
+		ForgeRemoteID: "3",
+		Owner:         "octocat",
+		Name:          "hello-world",
+		FullName:      "octocat/hello-world",
+		Hash:          "secret",
+		PreviousHashes: []model.RepoHash{
+			{Hash: "expired-secret", Expires: time.Now().Add(-time.Minute).Unix()},
+		},
+		Perm: &model.Perm{Pull: true, Push: true, Admin: true},
+	}
+
+	t.Run("should reject invalid grace period", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("repo", repo)
+		c.Request = httptest.NewRequest(http.MethodPost, "/?grace_period=soon", nil)
+
+		RotateRepoHook(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "secret", repo.Hash)
+	})
+
+	t.Run("should register webhook with new secret", func(t *testing.T) {
+		var hookURL string
+		mockForge := forge_mocks.NewMockForge(t)
+		mockForge.On("Repo", mock.Anything, user, repo.ForgeRemoteID, repo.Owner, repo.Name).Return(&model.Repo{
+			ForgeRemoteID: "3",
+			Owner:         "octocat",
+			Name:          "hello-world",
+			FullName:      "octocat/hello-world",
+		}, nil)
+		mockForge.On("Deactivate", mock.Anything, user, repo, "https://ci.example.com").Return(nil)
+		mockForge.On("Activate", mock.Anything, user, repo, mock.Anything).Run(func(args mock.Arguments) {
+			hookURL = args.String(3)
+		}).Return(nil)
+		mockManager := manager_mocks.NewMockManager(t)
+		mockManager.On("ForgeFromRepo", repo).Return(mockForge, nil)
+		server.Config.Services.Manager = mockManager
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("GetUser", int64(1)).Return(user, nil)
+		mockStore.On("UpdateRepo", repo).Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("store", mockStore)
+		c.Set("repo", repo)
+		c.Set("user", user)
+		c.Request = httptest.NewRequest(http.MethodPost, "/?grace_period=30m", nil)
+
+		RotateRepoHook(c)
+
+		assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+		assert.NotEqual(t, "secret", repo.Hash)
+		// the expired secret is dropped, the previous one stays valid for the grace period
+		if assert.Len(t, repo.PreviousHashes, 1) {
+			assert.Equal(t, "secret", repo.PreviousHashes[0].Hash)
+			assert.InDelta(t, time.Now().Add(30*time.Minute).Unix(), repo.PreviousHashes[0].Expires, 5)
+		}
+
+		parsed, err := url.Parse(hookURL)
+		assert.NoError(t, err)
+		_, err = token.Parse([]token.Type{token.HookToken}, parsed.Query().Get("access_token"), func(*token.Token) (string, error) {
+			return repo.Hash, nil
+		})
+		assert.NoError(t, err)
+	})
+}
+
+func TestPruneRepoHook(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	repo := &model.Repo{
+		ID:             2,
+		Hash:           "secret",
+		PreviousHashes: []model.RepoHash{{Hash: "previous-secret", Expires: time.Now().Add(time.Hour).Unix()}},
+	}
+	mockStore := store_mocks.NewMockStore(t)
+	mockStore.On("UpdateRepo", repo).Return(nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Set("store", mockStore)
+	c.Set("repo", repo)
+
+	PruneRepoHook(c)
+
+	assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+	assert.Empty(t, repo.PreviousHashes)
+	assert.Equal(t, []string{"secret"}, repo.WebhookSecrets(time.Now().Unix()))
+}
+
+func TestPatchRepoPlugins(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	patch := func(t *testing.T, user *model.User, body string, mockStore *store_mocks.MockStore) (*httptest.ResponseRecorder, *model.Repo) {
+		repo := &model.Repo{ID: 2, FullName: "octocat/hello-world", PrivilegedPlugins: []string{"docker"}}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPatch, "/api/repos/2", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("store", mockStore)
+		c.Set("repo", repo)
+		c.Set("user", user)
+
+		PatchRepo(c)
+		return w, repo
+	}
+
+	t.Run("repo owner can not grant plugins", func(t *testing.T) {
+		owner := &model.User{ID: 1, Login: "octocat"}
+		mockStore := store_mocks.NewMockStore(t)
+
+		w, repo := patch(t, owner, `{"privileged_plugins":["docker","buildx"]}`, mockStore)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Equal(t, []string{"docker"}, repo.PrivilegedPlugins)
+
+		w, repo = patch(t, owner, `{"trusted_clone_plugins":["my-clone"]}`, mockStore)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, repo.TrustedClonePlugins)
+	})
+
+	t.Run("repo owner can keep granted plugins", func(t *testing.T) {
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("UpdateRepo", mock.Anything).Return(nil)
+
+		w, repo := patch(t, &model.User{ID: 1, Login: "octocat"}, `{"privileged_plugins":["docker"],"allow_pr":true}`, mockStore)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, repo.AllowPull)
+	})
+
+	t.Run("admin can grant plugins", func(t *testing.T) {
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("UpdateRepo", mock.Anything).Return(nil)
+
+		w, repo := patch(t, &model.User{ID: 1, Login: "admin", Admin: true}, `{"privileged_plugins":["docker","buildx"],"trusted_clone_plugins":["my-clone"]}`, mockStore)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, []string{"docker", "buildx"}, repo.PrivilegedPlugins)
+		assert.Equal(t, []string{"my-clone"}, repo.TrustedClonePlugins)
+	})
+
+	t.Run("only admin can set max matrix jobs", func(t *testing.T) {
+		w, repo := patch(t, &model.User{ID: 1, Login: "octocat"}, `{"max_matrix_jobs":100}`, store_mocks.NewMockStore(t))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Zero(t, repo.MaxMatrixJobs)
+
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("UpdateRepo", mock.Anything).Return(nil)
+		w, repo = patch(t, &model.User{ID: 1, Login: "admin", Admin: true}, `{"max_matrix_jobs":100}`, mockStore)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 100, repo.MaxMatrixJobs)
+
+		w, _ = patch(t, &model.User{ID: 1, Login: "admin", Admin: true}, `{"max_matrix_jobs":-1}`, store_mocks.NewMockStore(t))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("only admin can expose secrets to forks", func(t *testing.T) {
+		w, repo := patch(t, &model.User{ID: 1, Login: "octocat"}, `{"expose_secrets_to_forks":false}`, store_mocks.NewMockStore(t))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Nil(t, repo.ExposeSecretsToForks)
+
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("UpdateRepo", mock.Anything).Return(nil)
+		w, repo = patch(t, &model.User{ID: 1, Login: "admin", Admin: true}, `{"expose_secrets_to_forks":false}`, mockStore)
+		assert.Equal(t, http.StatusOK, w.Code)
+		if assert.NotNil(t, repo.ExposeSecretsToForks) {
+			assert.False(t, *repo.ExposeSecretsToForks)
+		}
+	})
+
+	t.Run("only admin can set the log retention", func(t *testing.T) {
+		w, _ := patch(t, &model.User{ID: 1, Login: "octocat"}, `{"log_retention":"24h"}`, store_mocks.NewMockStore(t))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		admin := &model.User{ID: 1, Login: "admin", Admin: true}
+		w, _ = patch(t, admin, `{"log_retention":"a day"}`, store_mocks.NewMockStore(t))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("UpdateRepo", mock.Anything).Return(nil)
+		mockStore.On("ServerConfigSet", file.RepoRetentionConfigKey(2), "24h0m0s").Return(nil).Once()
+		w, _ = patch(t, admin, `{"log_retention":"24h"}`, mockStore)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		mockStore = store_mocks.NewMockStore(t)
+		mockStore.On("UpdateRepo", mock.Anything).Return(nil)
+		mockStore.On("ServerConfigDelete", file.RepoRetentionConfigKey(2)).Return(types.RecordNotExist).Once()
+		w, _ = patch(t, admin, `{"log_retention":""}`, mockStore)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
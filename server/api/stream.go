@@ -38,6 +38,7 @@ import (
 const (
 	// How many batches of logs to keep for each client before starting to
 	// drop them if the client is not consuming them faster than they arrive.
+	// Used if no buffer size is configured.
 	maxQueuedBatchesPerClient int = 30
 )
 
@@ -139,6 +140,8 @@ func EventStreamSSE(c *gin.Context) {
 //	@Param		repo_id		path	int	true	"the repository id"
 //	@Param		pipeline	path	int	true	"the number of the pipeline"
 //	@Param		stepID		path	int	true	"the step id"
+//	@Param		replay_lines	query	int	false	"how many of the last log lines to replay on connect (0 replays the whole log)"
+//	@Param		replay_bytes	query	int	false	"limit the replayed log lines to this amount of bytes (0 for no limit)"
 func LogStreamSSE(c *gin.Context) {
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -199,6 +202,20 @@ func LogStreamSSE(c *gin.Context) {
 		return
 	}
 
+	replay, err := newLogReplay(c)
+	if err != nil {
+		log.Debug().Err(err).Msg("log stream: invalid replay options")
+		logWriteStringErr(io.WriteString(rw, "event: error\ndata: "+err.Error()+"\n\n"))
+		return
+	}
+
+	// load the lines already persisted before we start tailing, the tail
+	// starts with all lines the server has seen so nothing can get lost in between
+	stored, err := server.Config.Services.LogStore.LogFind(step)
+	if err != nil {
+		log.Error().Err(err).Msg("log stream: could not load stored logs to replay")
+	}
+
 	logChan := make(chan []byte, 10)
 	ctx, cancel := context.WithCancelCause(
 		context.Background(),
@@ -220,7 +237,11 @@ func LogStreamSSE(c *gin.Context) {
 	}
 
 	go func() {
-		batches := make(logging.LogChan, maxQueuedBatchesPerClient)
+		bufferSize := server.Config.Logs.StreamBuffer
+		if bufferSize <= 0 {
+			bufferSize = maxQueuedBatchesPerClient
+		}
+		batches := make(logging.LogChan, bufferSize)
 
 		go func() {
 			defer func() {
@@ -229,11 +250,11 @@ func LogStreamSSE(c *gin.Context) {
 				}
 			}()
 
-			for entries := range batches {
+			send := func(entries []*model.LogEntry) bool {
 				for _, entry := range entries {
 					select {
 					case <-ctx.Done():
-						return
+						return false
 					default:
 						if ee, err := json.Marshal(entry); err == nil {
 							logChan <- ee
@@ -242,6 +263,17 @@ func LogStreamSSE(c *gin.Context) {
 						}
 					}
 				}
+				return true
+			}
+
+			if !send(replay.stored(stored)) {
+				return
+			}
+
+			for entries := range batches {
+				if !send(replay.live(entries)) {
+					return
+				}
 			}
 		}()
 
@@ -295,6 +327,97 @@ func LogStreamSSE(c *gin.Context) {
 	}
 }
 
+// logReplay selects the lines sent to a client joining a running step, before
+// switching over to live tailing. It keeps track of the last line sent so lines
+// are neither sent twice nor skipped at the boundary between both.
+type logReplay struct {
+	maxLines int
+	maxBytes int
+	lastLine int
+	started  bool
+}
+
+func newLogReplay(c *gin.Context) (*logReplay, error) {
+	replay := &logReplay{
+		maxLines: server.Config.Logs.StreamReplayLines,
+		maxBytes: server.Config.Logs.StreamReplayBytes,
+		lastLine: -1,
+	}
+
+	if v := c.Query("replay_lines"); v != "" {
+		lines, err := strconv.Atoi(v)
+		if err != nil || lines < 0 {
+			return nil, fmt.Errorf("invalid replay_lines '%s'", v)
+		}
+		replay.maxLines = lines
+	}
+
+	if v := c.Query("replay_bytes"); v != "" {
+		bytes, err := strconv.Atoi(v)
+		if err != nil || bytes < 0 {
+			return nil, fmt.Errorf("invalid replay_bytes '%s'", v)
+		}
+		replay.maxBytes = bytes
+	}
+
+	return replay, nil
+}
+
+// stored returns the part of the already persisted lines which should be replayed.
+func (r *logReplay) stored(entries []*model.LogEntry) []*model.LogEntry {
+	if len(entries) == 0 {
+		return nil
+	}
+	r.started = true
+
+	// everything persisted counts as seen, even if it is cut by the window
+	for _, entry := range entries {
+		r.lastLine = max(r.lastLine, entry.Line)
+	}
+
+	return r.window(entries)
+}
+
+// live filters a batch received from the live tail.
+func (r *logReplay) live(entries []*model.LogEntry) []*model.LogEntry {
+	if !r.started {
+		// nothing was persisted yet, so the first batch (holding all lines
+		// the server has seen so far) is the replay
+		r.started = true
+		entries = r.window(entries)
+	}
+
+	result := make([]*model.LogEntry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Line <= r.lastLine {
+			continue
+		}
+		r.lastLine = entry.Line
+		result = append(result, entry)
+	}
+	return result
+}
+
+func (r *logReplay) window(entries []*model.LogEntry) []*model.LogEntry {
+	start := 0
+	if r.maxLines > 0 && len(entries) > r.maxLines {
+		start = len(entries) - r.maxLines
+	}
+
+	if r.maxBytes > 0 {
+		size := 0
+		for i := len(entries) - 1; i >= start; i-- {
+			size += len(entries[i].Data)
+			if size > r.maxBytes {
+				start = i + 1
+				break
+			}
+		}
+	}
+
+	return entries[start:]
+}
+
 func logWriteStringErr(_ int, err error) {
 	if err != nil {
 		log.Error().Err(err).Caller(1).Msg("fail to write string")
@@ -0,0 +1,93 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+func logLines(from, to int) []*model.LogEntry {
+	entries := make([]*model.LogEntry, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		entries = append(entries, &model.LogEntry{Line: i, Data: []byte("line")})
+	}
+	return entries
+}
+
+func lineNumbers(entries []*model.LogEntry) []int {
+	lines := make([]int, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, entry.Line)
+	}
+	return lines
+}
+
+func newTestLogReplay(t *testing.T, query string) (*logReplay, error) {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/stream/logs/1/1/1"+query, nil)
+	return newLogReplay(c)
+}
+
+func TestLogReplay(t *testing.T) {
+	server.Config.Logs.StreamReplayLines = 3
+	server.Config.Logs.StreamReplayBytes = 0
+	defer func() { server.Config.Logs.StreamReplayLines = 0 }()
+
+	t.Run("replay stored lines then continue live without duplicates", func(t *testing.T) {
+		replay, err := newTestLogReplay(t, "")
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{7, 8, 9}, lineNumbers(replay.stored(logLines(0, 9))))
+		// the tail starts with all lines the server knows about
+		assert.Equal(t, []int{10, 11}, lineNumbers(replay.live(logLines(0, 11))))
+		assert.Equal(t, []int{12}, lineNumbers(replay.live(logLines(12, 12))))
+	})
+
+	t.Run("replay from tail if nothing is stored yet", func(t *testing.T) {
+		replay, err := newTestLogReplay(t, "")
+		require.NoError(t, err)
+
+		assert.Empty(t, replay.stored(nil))
+		assert.Equal(t, []int{3, 4, 5}, lineNumbers(replay.live(logLines(0, 5))))
+		assert.Equal(t, []int{6, 7}, lineNumbers(replay.live(logLines(6, 7))))
+	})
+
+	t.Run("override by request", func(t *testing.T) {
+		replay, err := newTestLogReplay(t, "?replay_lines=0")
+		require.NoError(t, err)
+		assert.Len(t, replay.stored(logLines(0, 9)), 10)
+
+		replay, err = newTestLogReplay(t, "?replay_lines=5&replay_bytes=8")
+		require.NoError(t, err)
+		assert.Equal(t, []int{8, 9}, lineNumbers(replay.stored(logLines(0, 9))))
+	})
+
+	t.Run("invalid request options", func(t *testing.T) {
+		_, err := newTestLogReplay(t, "?replay_lines=-1")
+		assert.Error(t, err)
+
+		_, err = newTestLogReplay(t, "?replay_bytes=abc")
+		assert.Error(t, err)
+	})
+}
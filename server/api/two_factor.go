@@ -0,0 +1,356 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/base32"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/tink/go/subtle/random"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/session"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/tokenhash"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/totp"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
+)
+
+const (
+	// totpIssuer is shown by authenticator apps next to the account.
+	totpIssuer = "Woodpecker"
+	// recoveryCodeCount is the number of recovery codes generated for a user.
+	recoveryCodeCount = 10
+
+	// twoFactorMaxFailures is the number of invalid codes a user can send in a row before being locked out.
+	twoFactorMaxFailures = 5
+	// twoFactorLockout is how long a user is locked out first, it doubles with every further invalid code.
+	twoFactorLockout = time.Minute
+	// twoFactorMaxLockout limits how long a user is locked out.
+	twoFactorMaxLockout = time.Hour
+)
+
+// twoFactorLimiter counts the invalid codes of the users, so codes can't be guessed.
+var twoFactorLimiter = &twoFactorAttempts{
+	failures:    make(map[int64]int),
+	lockedUntil: make(map[int64]time.Time),
+}
+
+type twoFactorAttempts struct {
+	sync.Mutex
+	failures    map[int64]int
+	lockedUntil map[int64]time.Time
+}
+
+// begin counts an attempt of the user as failed until it succeeded, so parallel attempts
+// can't bypass the lockout. It returns how long the user has to wait if locked out.
+func (a *twoFactorAttempts) begin(userID int64, now time.Time) time.Duration {
+	a.Lock()
+	defer a.Unlock()
+
+	if until, ok := a.lockedUntil[userID]; ok && now.Before(until) {
+		return until.Sub(now)
+	}
+	a.failures[userID]++
+	if excess := a.failures[userID] - twoFactorMaxFailures; excess >= 0 {
+		a.lockedUntil[userID] = now.Add(min(twoFactorLockout<<min(excess, 10), twoFactorMaxLockout))
+	}
+	return 0
+}
+
+// reset forgets the invalid codes of the user.
+func (a *twoFactorAttempts) reset(userID int64) {
+	a.Lock()
+	defer a.Unlock()
+
+	delete(a.failures, userID)
+	delete(a.lockedUntil, userID)
+}
+
+// PostTwoFactorEnroll
+//
+//	@Summary		Enroll a second factor
+//	@Description	Creates a new TOTP secret for the current user. The second factor is enabled after activating it with a valid code.
+//	@Router			/user/2fa/enroll [post]
+//	@Produce		json
+//	@Success		200	{object}	TwoFactorEnrollment
+//	@Tags			User
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func PostTwoFactorEnroll(c *gin.Context) {
+	_store := store.FromContext(c)
+	user := session.User(c)
+
+	if user.TOTPEnabled {
+		c.String(http.StatusConflict, "Second factor is already enabled")
+		return
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	user.TOTPSecret = secret
+	user.TOTPLastStep = 0
+	if err := _store.UpdateUser(user); err != nil {
+		c.String(http.StatusInternalServerError, "Error enrolling second factor. %s", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &model.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    totp.URI(totpIssuer, user.Login, secret),
+	})
+}
+
+// PostTwoFactorActivate
+//
+//	@Summary		Activate the enrolled second factor
+//	@Description	Enables the enrolled second factor of the current user and returns the recovery codes, which are only shown once.
+//	@Router			/user/2fa/activate [post]
+//	@Produce		json
+//	@Success		200	{object}	TwoFactorRecoveryCodes
+//	@Tags			User
+//	@Param			Authorization	header	string			true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			code			body	TwoFactorCode	true	"a TOTP code of the enrolled secret"
+func PostTwoFactorActivate(c *gin.Context) {
+	_store := store.FromContext(c)
+	user := session.User(c)
+
+	in := new(model.TwoFactorCode)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing request. %s", err)
+		return
+	}
+
+	if user.TOTPEnabled {
+		c.String(http.StatusConflict, "Second factor is already enabled")
+		return
+	}
+	if user.TOTPSecret == "" {
+		c.String(http.StatusBadRequest, "No second factor enrolled")
+		return
+	}
+	if !verifyTwoFactorCode(c, user, in.Code, false) {
+		return
+	}
+
+	codes, err := newRecoveryCodes(user)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	user.TOTPEnabled = true
+	if err := _store.UpdateUser(user); err != nil {
+		c.String(http.StatusInternalServerError, "Error activating second factor. %s", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &model.TwoFactorRecoveryCodes{RecoveryCodes: codes})
+}
+
+// PostTwoFactorVerify
+//
+//	@Summary		Verify the second factor
+//	@Description	Verifies a TOTP or recovery code of the current user and returns a new token, which grants admin permissions to admins.
+//	@Description	Browser sessions get the new session cookie set. Recovery codes can only be used once.
+//	@Router			/user/2fa/verify [post]
+//	@Produce		plain
+//	@Success		200
+//	@Tags			User
+//	@Param			Authorization	header	string			true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			code			body	TwoFactorCode	true	"a TOTP or recovery code"
+func PostTwoFactorVerify(c *gin.Context) {
+	_store := store.FromContext(c)
+	user := session.User(c)
+
+	in := new(model.TwoFactorCode)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing request. %s", err)
+		return
+	}
+
+	if !user.TOTPEnabled {
+		c.String(http.StatusBadRequest, "No second factor enabled")
+		return
+	}
+	if !verifyTwoFactorCode(c, user, in.Code, true) {
+		return
+	}
+	if err := _store.UpdateUser(user); err != nil {
+		c.String(http.StatusInternalServerError, "Error verifying second factor. %s", err)
+		return
+	}
+
+	t := session.Token(c)
+	tokenString, err := session.NewVerifiedToken(t, user, time.Now())
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if t.Type == token.SessToken {
+		httputil.SetCookie(c.Writer, c.Request, "user_sess", tokenString, server.Config.Server.Cookie)
+	}
+	c.String(http.StatusOK, tokenString)
+}
+
+// PostTwoFactorRecoveryCodes
+//
+//	@Summary		Regenerate the recovery codes
+//	@Description	Replaces all recovery codes of the current user and returns the new ones, which are only shown once.
+//	@Router			/user/2fa/recovery-codes [post]
+//	@Produce		json
+//	@Success		200	{object}	TwoFactorRecoveryCodes
+//	@Tags			User
+//	@Param			Authorization	header	string			true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			code			body	TwoFactorCode	true	"a TOTP code"
+func PostTwoFactorRecoveryCodes(c *gin.Context) {
+	_store := store.FromContext(c)
+	user := session.User(c)
+
+	in := new(model.TwoFactorCode)
+	if err := c.Bind(in); err != nil {
+		c.String(http.StatusBadRequest, "Error parsing request. %s", err)
+		return
+	}
+
+	if !user.TOTPEnabled {
+		c.String(http.StatusBadRequest, "No second factor enabled")
+		return
+	}
+	if !verifyTwoFactorCode(c, user, in.Code, false) {
+		return
+	}
+
+	codes, err := newRecoveryCodes(user)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	if err := _store.UpdateUser(user); err != nil {
+		c.String(http.StatusInternalServerError, "Error generating recovery codes. %s", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &model.TwoFactorRecoveryCodes{RecoveryCodes: codes})
+}
+
+// DeleteUserTwoFactor
+//
+//	@Summary		Reset the second factor of a user
+//	@Description	Removes the second factor and the recovery codes of the given user, e.g. after the user lost access to them.
+//	@Description	All tokens of the user are revoked, so tokens issued after verifying the removed second factor stop working. Requires admin rights.
+//	@Router			/users/{login}/2fa [delete]
+//	@Produce		plain
+//	@Success		204
+//	@Tags			Users
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			login			path	string	true	"the user's login name"
+//	@Param			forge_id		query	string	true	"specify forge (else default will be used)"
+func DeleteUserTwoFactor(c *gin.Context) {
+	_store := store.FromContext(c)
+
+	forgeID, err := strconv.ParseInt(c.DefaultQuery("forge_id", fmt.Sprint(defaultForgeID)), 10, 64)
+	if err != nil {
+		c.AbortWithStatus(http.StatusBadRequest)
+		return
+	}
+
+	user, err := _store.GetUserByLogin(forgeID, c.Param("login"))
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+
+	user.TOTPEnabled = false
+	user.TOTPSecret = ""
+	user.TOTPLastStep = 0
+	user.TOTPRecoveryCodes = nil
+	// revoke all tokens, as the ones issued after verifying the removed second factor would still grant admin permissions
+	user.Hash = base32.StdEncoding.EncodeToString(
+		random.GetRandomBytes(32),
+	)
+	if err := _store.UpdateUser(user); err != nil {
+		c.String(http.StatusInternalServerError, "Error resetting second factor. %s", err)
+		return
+	}
+	twoFactorLimiter.reset(user.ID)
+	c.Status(http.StatusNoContent)
+}
+
+// verifyTwoFactorCode checks a code of the user like checkTwoFactorCode and writes the error
+// response if it is invalid or the user is locked out after too many invalid codes.
+func verifyTwoFactorCode(c *gin.Context, user *model.User, code string, allowRecovery bool) bool {
+	if wait := twoFactorLimiter.begin(user.ID, time.Now()); wait > 0 {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.String(http.StatusTooManyRequests, "Too many invalid codes, try again later")
+		return false
+	}
+	if !checkTwoFactorCode(user, code, allowRecovery) {
+		c.String(http.StatusForbidden, "Invalid code")
+		return false
+	}
+	twoFactorLimiter.reset(user.ID)
+	return true
+}
+
+// checkTwoFactorCode checks a TOTP code, or if allowed a recovery code, of the user.
+// Used codes are remembered on the user, so the user has to be saved afterwards.
+func checkTwoFactorCode(user *model.User, code string, allowRecovery bool) bool {
+	if step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), user.TOTPLastStep); ok {
+		user.TOTPLastStep = step
+		return true
+	}
+	if !allowRecovery {
+		return false
+	}
+
+	code = strings.ToLower(strings.TrimSpace(code))
+	for i, hashed := range user.TOTPRecoveryCodes {
+		if tokenhash.Verify(hashed, code) {
+			user.TOTPRecoveryCodes = slices.Delete(user.TOTPRecoveryCodes, i, i+1)
+			return true
+		}
+	}
+	return false
+}
+
+// newRecoveryCodes replaces the recovery codes of the user and returns the new plaintext codes.
+func newRecoveryCodes(user *model.User) ([]string, error) {
+	codes, err := totp.RecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashed, err := tokenhash.Hash(tokenhash.DefaultAlgorithm, code)
+		if err != nil {
+			return nil, fmt.Errorf("could not hash recovery code: %w", err)
+		}
+		hashes = append(hashes, hashed)
+	}
+	user.TOTPRecoveryCodes = hashes
+	return codes, nil
+}
@@ -0,0 +1,298 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware"
+	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/session"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/maintenance"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/totp"
+	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
+)
+
+func TestTwoFactor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server.Config.Server.SessionExpires = time.Hour
+	defer func() { server.Config.Server.SessionExpires = 0 }()
+
+	// call runs the handler as the user authenticated with a token of the given type
+	call := func(t *testing.T, handler gin.HandlerFunc, user *model.User, tokenType token.Type, body string) *httptest.ResponseRecorder {
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("UpdateUser", user).Return(nil).Maybe()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("store", mockStore)
+		c.Set("user", user)
+		c.Set("token", token.New(tokenType))
+
+		handler(c)
+		return w
+	}
+
+	currentCode := func(t *testing.T, user *model.User) string {
+		code, err := totp.Code(user.TOTPSecret, totp.Step(time.Now()))
+		require.NoError(t, err)
+		return `{"code":"` + code + `"}`
+	}
+
+	user := &model.User{ID: 1, Login: "octocat", Admin: true, Hash: "hash"}
+
+	t.Run("enroll", func(t *testing.T) {
+		w := call(t, PostTwoFactorEnroll, user, token.SessToken, "")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var enrollment model.TwoFactorEnrollment
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enrollment))
+		assert.Equal(t, user.TOTPSecret, enrollment.Secret)
+		assert.True(t, strings.HasPrefix(enrollment.URI, "otpauth://totp/Woodpecker:octocat?"))
+		assert.False(t, user.TOTPEnabled, "the second factor is only enabled after activation")
+	})
+
+	t.Run("activate with invalid code", func(t *testing.T) {
+		w := call(t, PostTwoFactorActivate, user, token.SessToken, `{"code":"abc"}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.False(t, user.TOTPEnabled)
+	})
+
+	var recoveryCodes []string
+	t.Run("activate", func(t *testing.T) {
+		w := call(t, PostTwoFactorActivate, user, token.SessToken, currentCode(t, user))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var codes model.TwoFactorRecoveryCodes
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &codes))
+		assert.Len(t, codes.RecoveryCodes, recoveryCodeCount)
+		assert.True(t, user.TOTPEnabled)
+		assert.Len(t, user.TOTPRecoveryCodes, recoveryCodeCount)
+		assert.NotContains(t, user.TOTPRecoveryCodes, codes.RecoveryCodes[0], "recovery codes are stored hashed")
+		recoveryCodes = codes.RecoveryCodes
+	})
+
+	t.Run("enroll again", func(t *testing.T) {
+		w := call(t, PostTwoFactorEnroll, user, token.SessToken, "")
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("verify with used code", func(t *testing.T) {
+		// the code used for the activation can't be used again
+		w := call(t, PostTwoFactorVerify, user, token.SessToken, currentCode(t, user))
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("verify session with recovery code", func(t *testing.T) {
+		w := call(t, PostTwoFactorVerify, user, token.SessToken, `{"code":"`+strings.ToUpper(recoveryCodes[0])+`"}`)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Set-Cookie"), "user_sess=")
+		parsed, err := token.Parse([]token.Type{token.SessToken}, w.Body.String(), func(*token.Token) (string, error) {
+			return user.Hash, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "true", parsed.Get(session.TwoFactorClaim))
+		assert.Equal(t, "1", parsed.Get("user-id"))
+		assert.Len(t, user.TOTPRecoveryCodes, recoveryCodeCount-1)
+	})
+
+	t.Run("verify with used recovery code", func(t *testing.T) {
+		w := call(t, PostTwoFactorVerify, user, token.SessToken, `{"code":"`+recoveryCodes[0]+`"}`)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("verify user token", func(t *testing.T) {
+		user.TOTPLastStep = 0 // pretend the last code was used long ago
+		w := call(t, PostTwoFactorVerify, user, token.UserToken, currentCode(t, user))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Set-Cookie"))
+		parsed, err := token.Parse([]token.Type{token.UserToken}, w.Body.String(), func(*token.Token) (string, error) {
+			return user.Hash, nil
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "true", parsed.Get(session.TwoFactorClaim))
+	})
+
+	t.Run("regenerate recovery codes", func(t *testing.T) {
+		user.TOTPLastStep = 0
+		w := call(t, PostTwoFactorRecoveryCodes, user, token.SessToken, currentCode(t, user))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, user.TOTPRecoveryCodes, recoveryCodeCount)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("GetUserByLogin", int64(1), "octocat").Return(user, nil)
+		mockStore.On("UpdateUser", user).Return(nil)
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodDelete, "/", nil)
+		c.Params = gin.Params{{Key: "login", Value: "octocat"}}
+		c.Set("store", mockStore)
+
+		verified := token.New(token.SessToken)
+		verified.Set("user-id", "1")
+		verified.Set(session.TwoFactorClaim, "true")
+		tokenString, err := verified.Sign(user.Hash)
+		require.NoError(t, err)
+
+		DeleteUserTwoFactor(c)
+
+		assert.Equal(t, http.StatusNoContent, c.Writer.Status())
+		assert.False(t, user.TOTPEnabled)
+		assert.Empty(t, user.TOTPSecret)
+		assert.Empty(t, user.TOTPRecoveryCodes)
+		// the tokens issued after verifying the removed second factor are revoked
+		assert.NotEqual(t, "hash", user.Hash)
+		_, err = token.Parse([]token.Type{token.SessToken}, tokenString, func(*token.Token) (string, error) {
+			return user.Hash, nil
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestTwoFactorLockout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	user := &model.User{ID: 2, Login: "octocat", TOTPEnabled: true, TOTPSecret: secret}
+	defer twoFactorLimiter.reset(user.ID)
+
+	verify := func(t *testing.T, code string) *httptest.ResponseRecorder {
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("UpdateUser", user).Return(nil).Maybe()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/", strings.NewReader(`{"code":"`+code+`"}`))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("store", mockStore)
+		c.Set("user", user)
+		c.Set("token", token.New(token.UserToken))
+
+		PostTwoFactorVerify(c)
+		return w
+	}
+
+	for range twoFactorMaxFailures {
+		assert.Equal(t, http.StatusForbidden, verify(t, "000000x").Code)
+	}
+
+	// even a valid code is refused while locked out
+	code, err := totp.Code(user.TOTPSecret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	w := verify(t, code)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	t.Run("lockout doubles", func(t *testing.T) {
+		now := time.Now()
+		limiter := &twoFactorAttempts{failures: map[int64]int{1: twoFactorMaxFailures}, lockedUntil: map[int64]time.Time{}}
+
+		assert.Zero(t, limiter.begin(1, now))
+		assert.Equal(t, 2*twoFactorLockout, limiter.begin(1, now))
+		assert.Zero(t, limiter.begin(1, now.Add(2*twoFactorLockout)))
+		assert.Equal(t, 4*twoFactorLockout, limiter.begin(1, now.Add(2*twoFactorLockout)))
+
+		limiter.failures[1] = 100
+		assert.Zero(t, limiter.begin(1, now.Add(time.Hour)))
+		assert.Equal(t, twoFactorMaxLockout, limiter.begin(1, now.Add(time.Hour)))
+	})
+
+	t.Run("reset after valid code", func(t *testing.T) {
+		twoFactorLimiter.reset(user.ID)
+		assert.Equal(t, http.StatusForbidden, verify(t, "000000x").Code)
+		assert.Equal(t, http.StatusOK, verify(t, code).Code)
+		assert.NotContains(t, twoFactorLimiter.failures, user.ID)
+	})
+}
+
+func TestTwoFactorInMaintenance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server.Config.Server.SessionExpires = time.Hour
+	defer func() { server.Config.Server.SessionExpires = 0 }()
+
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	user := &model.User{ID: 1, Login: "octocat", Admin: true, Hash: "hash", TOTPEnabled: true, TOTPSecret: secret}
+
+	mockStore := store_mocks.NewMockStore(t)
+	mockStore.On("ServerConfigSet", "maintenance", mock.Anything).Return(nil)
+	mockStore.On("UpdateUser", user).Return(nil)
+	require.NoError(t, maintenance.Set(mockStore, true))
+	t.Cleanup(func() { require.NoError(t, maintenance.Set(mockStore, false)) })
+
+	e := gin.New()
+	e.Use(func(c *gin.Context) {
+		// authenticate with the token of the request, or with an unverified session
+		t := token.New(token.SessToken)
+		if raw := c.GetHeader("Authorization"); raw != "" {
+			t, _ = token.Parse([]token.Type{token.SessToken}, raw, func(*token.Token) (string, error) {
+				return user.Hash, nil
+			})
+		}
+		c.Set("store", mockStore)
+		c.Set("user", user)
+		c.Set("token", t)
+	})
+	e.Use(middleware.Maintenance)
+	e.POST("/api/user/2fa/verify", PostTwoFactorVerify)
+	e.POST("/api/maintenance", session.MustAdmin(), SetMaintenance)
+
+	request := func(path, authorization, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authorization)
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	// the admin has to verify the second factor before the maintenance mode can be disabled
+	w := request("/api/maintenance", "", `{"enabled":false}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	code, err := totp.Code(secret, totp.Step(time.Now()))
+	require.NoError(t, err)
+	w = request("/api/user/2fa/verify", "", `{"code":"`+code+`"}`)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = request("/api/maintenance", w.Body.String(), `{"enabled":false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.False(t, maintenance.Enabled())
+}
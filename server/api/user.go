@@ -16,7 +16,9 @@ package api
 
 import (
 	"encoding/base32"
+	"errors"
 	"net/http"
+	"path"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
@@ -27,6 +29,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/session"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/utils"
 )
@@ -40,7 +43,7 @@ import (
 //	@Tags		User
 //	@Param		Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
 func GetSelf(c *gin.Context) {
-	c.JSON(http.StatusOK, session.User(c))
+	c.JSON(http.StatusOK, session.ScopedUser(c))
 }
 
 // GetFeed
@@ -177,6 +180,138 @@ func GetRepos(c *gin.Context) {
 	c.JSON(http.StatusOK, repos)
 }
 
+// SyncRepos
+//
+//	@Summary		Synchronize user's repositories with the forge
+//	@Description	Re-fetch the repositories of the currently authenticated User from the forge, update renamed ones and report
+//	@Description	added, removed and renamed repositories. Added repositories matching the enable pattern get activated.
+//	@Router			/user/repos/sync [post]
+//	@Produce		json
+//	@Success		200	{object}	RepoSyncReport
+//	@Tags			User
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			org				query	string	false	"only synchronize repos of this organization"
+//	@Param			enable			query	string	false	"activate added repos with a full name matching this glob pattern"
+func SyncRepos(c *gin.Context) {
+	_store := store.FromContext(c)
+	user := session.User(c)
+	_forge, err := server.Config.Services.Manager.ForgeFromUser(user)
+	if err != nil {
+		log.Error().Err(err).Msg("Cannot get forge from user")
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+
+	org := c.Query("org")
+	enable := c.Query("enable")
+	if _, err := path.Match(enable, ""); err != nil {
+		c.String(http.StatusBadRequest, "Invalid enable pattern. %s", err)
+		return
+	}
+
+	if org != "" && org != user.Login && !session.IsAdmin(c) {
+		perm, err := server.Config.Services.Membership.Get(c, _forge, user, org)
+		if err != nil {
+			c.String(http.StatusInternalServerError, "Error fetching membership. %s", err)
+			return
+		}
+		if !perm.Member {
+			c.String(http.StatusForbidden, "User is not a member of this organization")
+			return
+		}
+	}
+
+	dbRepos, err := _store.RepoList(user, true, false, nil)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error fetching repository list. %s", err)
+		return
+	}
+
+	stored := make(map[model.ForgeRemoteID]*model.Repo, len(dbRepos))
+	for _, r := range dbRepos {
+		stored[r.ForgeRemoteID] = r
+	}
+
+	forgeRepos, err := utils.Paginate(func(page int) ([]*model.Repo, error) {
+		return _forge.Repos(c, user, &model.ListOptions{
+			Page:    page,
+			PerPage: perPage,
+		})
+	}, maxPage)
+	if err != nil {
+		c.String(http.StatusInternalServerError, "Error fetching repository list. %s", err)
+		return
+	}
+
+	report := &model.RepoSyncReport{
+		Added:   []string{},
+		Enabled: []string{},
+		Removed: []string{},
+		Renamed: []model.RepoRename{},
+	}
+	seen := make(map[model.ForgeRemoteID]bool, len(forgeRepos))
+	for _, from := range forgeRepos {
+		if org != "" && from.Owner != org {
+			continue
+		}
+		seen[from.ForgeRemoteID] = true
+
+		repo := stored[from.ForgeRemoteID]
+		if repo == nil && from.Perm.Admin {
+			// the repo may have been activated by another user without permissions of this user being stored yet
+			repo, err = _store.GetRepoForgeID(from.ForgeRemoteID)
+			if errors.Is(err, types.RecordNotExist) {
+				repo = nil
+			} else if err != nil {
+				c.String(http.StatusInternalServerError, "Error fetching repository. %s", err)
+				return
+			}
+		}
+		if repo != nil && repo.FullName != from.FullName {
+			// keep the old name working and update the stored one instead of orphaning the repo
+			if err := _store.CreateRedirection(&model.Redirection{RepoID: repo.ID, FullName: repo.FullName}); err != nil {
+				c.String(http.StatusInternalServerError, "Error creating redirection. %s", err)
+				return
+			}
+			rename := model.RepoRename{From: repo.FullName, To: from.FullName}
+			repo.Update(from)
+			if err := _store.UpdateRepo(repo); err != nil {
+				c.String(http.StatusInternalServerError, "Error updating repository. %s", err)
+				return
+			}
+			report.Renamed = append(report.Renamed, rename)
+		}
+
+		// you must be admin to enable the repo
+		if (repo != nil && repo.IsActive) || !from.Perm.Admin || !server.Config.Permissions.OwnersAllowlist.IsAllowed(from) {
+			continue
+		}
+		report.Added = append(report.Added, from.FullName)
+
+		if enable == "" {
+			continue
+		}
+		if matched, _ := path.Match(enable, from.FullName); !matched {
+			continue
+		}
+		if _, err := activateRepo(c, _store, _forge, user, repo, from); err != nil {
+			log.Error().Err(err).Msgf("could not activate repo %s", from.FullName)
+			c.String(http.StatusInternalServerError, "Error activating repository %s. %s", from.FullName, err)
+			return
+		}
+		report.Enabled = append(report.Enabled, from.FullName)
+	}
+
+	for _, repo := range dbRepos {
+		if !repo.IsActive || seen[repo.ForgeRemoteID] || (org != "" && repo.Owner != org) {
+			continue
+		}
+		report.Removed = append(report.Removed, repo.FullName)
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
 // PostToken
 //
 //	@Summary	Return the token of the current user as string
@@ -189,6 +324,9 @@ func PostToken(c *gin.Context) {
 	user := session.User(c)
 	t := token.New(token.UserToken)
 	t.Set("user-id", strconv.FormatInt(user.ID, 10))
+	if session.TwoFactorVerified(c) {
+		t.Set(session.TwoFactorClaim, "true")
+	}
 	tokenString, err := t.Sign(user.Hash)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, err)
@@ -220,6 +358,9 @@ func DeleteToken(c *gin.Context) {
 
 	t := token.New(token.UserToken)
 	t.Set("user-id", strconv.FormatInt(user.ID, 10))
+	if session.TwoFactorVerified(c) {
+		t.Set(session.TwoFactorClaim, "true")
+	}
 	tokenString, err := t.Sign(user.Hash)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, err)
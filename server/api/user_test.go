@@ -0,0 +1,200 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/cache"
+	forge_mocks "go.woodpecker-ci.org/woodpecker/v3/server/forge/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	manager_mocks "go.woodpecker-ci.org/woodpecker/v3/server/services/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/permissions"
+	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+func TestSyncRepos(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server.Config.Server.WebhookHost = "https://ci.example.com"
+	server.Config.Permissions.OwnersAllowlist = permissions.NewOwnersAllowlist([]string{})
+
+	user := &model.User{ID: 1, Login: "octocat"}
+	admin := &model.Perm{Pull: true, Push: true, Admin: true}
+
+	// stored repos before the sync
+	stored := func() []*model.Repo {
+		return []*model.Repo{
+			{ID: 1, ForgeRemoteID: "1", Owner: "octocat", Name: "old-name", FullName: "octocat/old-name", IsActive: true},
+			{ID: 2, ForgeRemoteID: "2", Owner: "octocat", Name: "gone", FullName: "octocat/gone", IsActive: true},
+			{ID: 3, ForgeRemoteID: "3", Owner: "octocat", Name: "inactive", FullName: "octocat/inactive"},
+			{ID: 4, ForgeRemoteID: "4", Owner: "octo-org", Name: "removed", FullName: "octo-org/removed", IsActive: true},
+		}
+	}
+
+	// changed repo set returned by the stub forge
+	forgeRepos := func() []*model.Repo {
+		return []*model.Repo{
+			{ForgeRemoteID: "1", Owner: "octocat", Name: "new-name", FullName: "octocat/new-name", Perm: admin},
+			{ForgeRemoteID: "3", Owner: "octocat", Name: "inactive", FullName: "octocat/inactive", Perm: admin},
+			{ForgeRemoteID: "5", Owner: "octocat", Name: "new-repo", FullName: "octocat/new-repo", Perm: admin},
+			{ForgeRemoteID: "6", Owner: "octocat", Name: "other", FullName: "octocat/other", Perm: admin},
+			{ForgeRemoteID: "7", Owner: "octocat", Name: "read-only", FullName: "octocat/read-only", Perm: &model.Perm{Pull: true, Push: true}},
+		}
+	}
+
+	sync := func(t *testing.T, query string, mockStore *store_mocks.MockStore, mockForge *forge_mocks.MockForge) *httptest.ResponseRecorder {
+		mockManager := manager_mocks.NewMockManager(t)
+		mockManager.On("ForgeFromUser", user).Return(mockForge, nil)
+		server.Config.Services.Manager = mockManager
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request, _ = http.NewRequest(http.MethodPost, "/api/user/repos/sync?"+query, nil)
+		c.Set("store", mockStore)
+		c.Set("user", user)
+
+		SyncRepos(c)
+		return w
+	}
+
+	newForge := func(t *testing.T) *forge_mocks.MockForge {
+		mockForge := forge_mocks.NewMockForge(t)
+		mockForge.On("Repos", mock.Anything, user, &model.ListOptions{Page: 1, PerPage: perPage}).Return(forgeRepos(), nil)
+		mockForge.On("Repos", mock.Anything, user, &model.ListOptions{Page: 2, PerPage: perPage}).Return([]*model.Repo{}, nil).Maybe()
+		return mockForge
+	}
+
+	t.Run("should report changes and rename repos", func(t *testing.T) {
+		mockForge := newForge(t)
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("RepoList", user, true, false, (*model.RepoFilter)(nil)).Return(stored(), nil)
+		mockStore.On("GetRepoForgeID", mock.Anything).Return(nil, types.RecordNotExist)
+		mockStore.On("CreateRedirection", &model.Redirection{RepoID: 1, FullName: "octocat/old-name"}).Return(nil)
+		mockStore.On("UpdateRepo", mock.MatchedBy(func(repo *model.Repo) bool {
+			return repo.ID == 1 && repo.FullName == "octocat/new-name" && repo.IsActive
+		})).Return(nil)
+
+		w := sync(t, "", mockStore, mockForge)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var report model.RepoSyncReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, model.RepoSyncReport{
+			Added:   []string{"octocat/inactive", "octocat/new-repo", "octocat/other"},
+			Enabled: []string{},
+			Removed: []string{"octocat/gone", "octo-org/removed"},
+			Renamed: []model.RepoRename{{From: "octocat/old-name", To: "octocat/new-name"}},
+		}, report)
+	})
+
+	t.Run("should enable matching repos", func(t *testing.T) {
+		mockForge := newForge(t)
+		mockForge.On("Activate", mock.Anything, user, mock.MatchedBy(func(repo *model.Repo) bool {
+			return repo.FullName == "octocat/new-repo"
+		}), mock.Anything).Return(nil)
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("RepoList", user, true, false, (*model.RepoFilter)(nil)).Return(stored(), nil)
+		mockStore.On("GetRepoForgeID", mock.Anything).Return(nil, types.RecordNotExist)
+		mockStore.On("CreateRedirection", mock.Anything).Return(nil)
+		mockStore.On("UpdateRepo", mock.Anything).Return(nil)
+		mockStore.On("OrgFindByName", "octocat", int64(0)).Return(&model.Org{ID: 1, Name: "octocat"}, nil)
+		mockStore.On("CreateRepo", mock.MatchedBy(func(repo *model.Repo) bool {
+			return repo.FullName == "octocat/new-repo" && repo.IsActive && repo.OrgID == 1
+		})).Return(nil)
+		mockStore.On("PermUpsert", mock.Anything).Return(nil)
+
+		w := sync(t, "enable=octocat/new-*", mockStore, mockForge)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var report model.RepoSyncReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, []string{"octocat/inactive", "octocat/new-repo", "octocat/other"}, report.Added)
+		assert.Equal(t, []string{"octocat/new-repo"}, report.Enabled)
+	})
+
+	t.Run("should use repos stored without permissions of the user", func(t *testing.T) {
+		mockForge := newForge(t)
+		mockForge.On("Activate", mock.Anything, user, mock.MatchedBy(func(repo *model.Repo) bool {
+			return repo.FullName == "octocat/new-repo"
+		}), mock.Anything).Return(nil)
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("RepoList", user, true, false, (*model.RepoFilter)(nil)).Return(stored(), nil)
+		mockStore.On("CreateRedirection", mock.Anything).Return(nil)
+		mockStore.On("UpdateRepo", mock.Anything).Return(nil)
+		// new-repo was activated by another user, other is active already
+		mockStore.On("GetRepoForgeID", model.ForgeRemoteID("5")).Return(&model.Repo{ID: 5, ForgeRemoteID: "5", OrgID: 1, Owner: "octocat", Name: "new-repo", FullName: "octocat/new-repo", Hash: "hash"}, nil)
+		mockStore.On("GetRepoForgeID", model.ForgeRemoteID("6")).Return(&model.Repo{ID: 6, ForgeRemoteID: "6", Owner: "octocat", Name: "other", FullName: "octocat/other", IsActive: true}, nil)
+		mockStore.On("OrgFindByName", "octocat", int64(0)).Return(&model.Org{ID: 1, Name: "octocat"}, nil)
+		mockStore.On("PermUpsert", mock.Anything).Return(nil)
+
+		w := sync(t, "enable=octocat/new-*", mockStore, mockForge)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var report model.RepoSyncReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, []string{"octocat/inactive", "octocat/new-repo"}, report.Added)
+		assert.Equal(t, []string{"octocat/new-repo"}, report.Enabled)
+		mockStore.AssertCalled(t, "UpdateRepo", mock.MatchedBy(func(repo *model.Repo) bool {
+			return repo.ID == 5 && repo.IsActive
+		}))
+		mockStore.AssertNotCalled(t, "CreateRepo", mock.Anything)
+	})
+
+	t.Run("should only sync repos of the org", func(t *testing.T) {
+		mockForge := newForge(t)
+		mockForge.On("OrgMembership", mock.Anything, user, "octo-org").Return(&model.OrgPerm{Member: true}, nil)
+		mockStore := store_mocks.NewMockStore(t)
+		mockStore.On("RepoList", user, true, false, (*model.RepoFilter)(nil)).Return(stored(), nil)
+		server.Config.Services.Membership = cache.NewMembershipService(mockStore)
+
+		w := sync(t, "org=octo-org", mockStore, mockForge)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var report model.RepoSyncReport
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+		assert.Equal(t, model.RepoSyncReport{
+			Added:   []string{},
+			Enabled: []string{},
+			Removed: []string{"octo-org/removed"},
+			Renamed: []model.RepoRename{},
+		}, report)
+	})
+
+	t.Run("should reject non org members", func(t *testing.T) {
+		mockForge := forge_mocks.NewMockForge(t)
+		mockForge.On("OrgMembership", mock.Anything, user, "other-org").Return(&model.OrgPerm{}, nil)
+		mockStore := store_mocks.NewMockStore(t)
+		server.Config.Services.Membership = cache.NewMembershipService(mockStore)
+
+		w := sync(t, "org=other-org", mockStore, mockForge)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("should reject invalid pattern", func(t *testing.T) {
+		w := sync(t, "enable=[", store_mocks.NewMockStore(t), forge_mocks.NewMockForge(t))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
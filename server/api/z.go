@@ -15,33 +15,144 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/maintenance"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
 	"go.woodpecker-ci.org/woodpecker/v3/version"
 )
 
+const (
+	healthCheckTimeout = 5 * time.Second
+	// healthCacheTTL is how long a health check result is reused,
+	// so frequent probes don't cause a request to each forge every time.
+	healthCacheTTL = 10 * time.Second
+	mainForgeID    = 1
+)
+
+// healthCache holds the result of the last health check.
+var healthCache struct {
+	sync.Mutex
+	checked time.Time
+	errs    map[string]string
+}
+
+// queueCheckRunning is set while a queue health check waits for the queue,
+// so a queue which does not respond anymore does not pile up goroutines.
+var queueCheckRunning atomic.Bool
+
 // Health
 //
 //	@Summary		Health information
-//	@Description	If everything is fine, just a 204 will be returned, a 500 signals server state is unhealthy.
+//	@Description	If everything is fine, just a 204 will be returned, a 503 signals server state is unhealthy.
+//	@Description	The database, the queue and all forges are checked, the body names the failing ones.
+//	@Description	The result is cached for a few seconds.
 //	@Router			/healthz [get]
-//	@Produce		plain
+//	@Produce		json
 //	@Success		204
-//	@Failure		500
+//	@Failure		503	{object}	object{errors=map[string]string}
 //	@Tags			System
 func Health(c *gin.Context) {
-	if err := store.FromContext(c).Ping(); err != nil {
-		c.String(http.StatusInternalServerError, err.Error())
+	errs := checkHealth(c, store.FromContext(c), time.Now())
+	if len(errs) > 0 {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"errors": errs})
 		return
 	}
 	c.Status(http.StatusNoContent)
 }
 
+// checkHealth returns the failing components, reusing the last result if it is recent enough.
+// Concurrent callers wait for the running check instead of starting their own.
+func checkHealth(c context.Context, _store store.Store, now time.Time) map[string]string {
+	healthCache.Lock()
+	defer healthCache.Unlock()
+	if now.Sub(healthCache.checked) < healthCacheTTL {
+		return healthCache.errs
+	}
+
+	// the result is shared, so a client going away must not cancel the check
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(c), healthCheckTimeout)
+	defer cancel()
+
+	errs := map[string]string{}
+	if err := _store.Ping(); err != nil {
+		errs["store"] = err.Error()
+	}
+	if err := queueHealthy(ctx); err != nil {
+		errs["queue"] = err.Error()
+	}
+	for name, err := range forgesHealthy(ctx, _store) {
+		errs[name] = err.Error()
+	}
+
+	if len(errs) > 0 {
+		log.Warn().Any("errors", errs).Msg("health check failed")
+	}
+	healthCache.checked = now
+	healthCache.errs = errs
+	return errs
+}
+
+func queueHealthy(ctx context.Context) error {
+	if !queueCheckRunning.CompareAndSwap(false, true) {
+		return errors.New("queue is not responding")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer queueCheckRunning.Store(false)
+		server.Config.Services.Queue.Info(ctx)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.New("queue is not responding")
+	}
+}
+
+// forgesHealthy checks all configured forges and returns the errors by the name they are reported with.
+// The main forge is reported as "forge", additional ones as "forge-<id>".
+func forgesHealthy(ctx context.Context, _store store.Store) map[string]error {
+	forges, err := _store.ForgeList(&model.ListOptions{All: true})
+	if err != nil {
+		return map[string]error{"forge": fmt.Errorf("could not list forges: %w", err)}
+	}
+
+	errs := map[string]error{}
+	for _, f := range forges {
+		name := "forge"
+		if f.ID != mainForgeID {
+			name = fmt.Sprintf("forge-%d", f.ID)
+		}
+
+		_forge, err := server.Config.Services.Manager.ForgeByID(f.ID)
+		if err == nil {
+			err = forge.Healthy(ctx, _forge)
+		}
+		if err != nil {
+			errs[name] = err
+		}
+	}
+	return errs
+}
+
 // Version
 //
 //	@Summary		Get version
@@ -57,6 +168,33 @@ func Version(c *gin.Context) {
 	})
 }
 
+// GetInfo
+//
+//	@Summary		Get server information
+//	@Description	Endpoint returns the server version, the kind of the main forge and the enabled features.
+//	@Router			/info [get]
+//	@Produce		json
+//	@Success		200	{object}	ServerInfo
+//	@Tags			System
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func GetInfo(c *gin.Context) {
+	_forge, err := server.Config.Services.Manager.ForgeByID(mainForgeID)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, &model.ServerInfo{
+		Version: version.String(),
+		Forge:   _forge.Name(),
+		Features: model.ServerFeatures{
+			Swagger:          server.Config.WebUI.EnableSwagger,
+			OpenRegistration: server.Config.Permissions.Open,
+			LogStore:         server.Config.Logs.Store,
+		},
+	})
+}
+
 // LogLevel
 //
 //	@Summary		Current log level
@@ -100,3 +238,89 @@ func SetLogLevel(c *gin.Context) {
 	zerolog.SetGlobalLevel(lvl)
 	c.JSON(http.StatusOK, logLevel)
 }
+
+// GetMaintenance
+//
+//	@Summary		Current maintenance mode
+//	@Description	Endpoint returns whether the maintenance mode is enabled. Requires admin rights.
+//	@Router			/maintenance [get]
+//	@Produce		json
+//	@Success		200	{object}	object{enabled=bool}
+//	@Tags			System
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func GetMaintenance(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": maintenance.Enabled(),
+	})
+}
+
+// SetMaintenance
+//
+//	@Summary		Set maintenance mode
+//	@Description	Endpoint enables or disables the maintenance mode. While it is enabled the server is read-only
+//	@Description	and rejects webhooks and all other writes. Requires admin rights.
+//	@Router			/maintenance [post]
+//	@Produce		json
+//	@Success		200	{object}	object{enabled=bool}
+//	@Tags			System
+//	@Param			Authorization	header	string					true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			maintenance		body	object{enabled=bool}	true	"whether the maintenance mode is enabled"
+func SetMaintenance(c *gin.Context) {
+	in := struct {
+		Enabled bool `json:"enabled"`
+	}{}
+	if err := c.Bind(&in); err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := maintenance.Set(store.FromContext(c), in.Enabled); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, in)
+}
+
+// GetMigrations
+//
+//	@Summary		List store migrations
+//	@Description	Endpoint returns all known store migrations in execution order and whether they were applied. Requires admin rights.
+//	@Router			/migrations [get]
+//	@Produce		json
+//	@Success		200	{array}	MigrationStatus
+//	@Tags			System
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+func GetMigrations(c *gin.Context) {
+	list, err := store.FromContext(c).MigrationStatus()
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, list)
+}
+
+// RollbackMigration
+//
+//	@Summary		Rollback a store migration
+//	@Description	Endpoint reverts the given store migration. Only the last applied migration can be rolled back
+//	@Description	and only if it provides a rollback. Requires admin rights.
+//	@Router			/migrations/{version}/rollback [post]
+//	@Produce		plain
+//	@Success		204
+//	@Tags			System
+//	@Param			Authorization	header	string	true	"Insert your personal access token"	default(Bearer <personal access token>)
+//	@Param			version			path	string	true	"the migration id"
+func RollbackMigration(c *gin.Context) {
+	err := store.FromContext(c).MigrationRollback(c.Param("version"))
+	if errors.Is(err, types.MigrationNotLast) || errors.Is(err, types.MigrationNotReversible) {
+		c.String(http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		handleDBError(c, err)
+		return
+	}
+
+	log.Warn().Str("migration", c.Param("version")).Msg("store migration rolled back")
+	c.Status(http.StatusNoContent)
+}
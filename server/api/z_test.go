@@ -0,0 +1,186 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	forge_mocks "go.woodpecker-ci.org/woodpecker/v3/server/forge/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+	queue_mocks "go.woodpecker-ci.org/woodpecker/v3/server/queue/mocks"
+	services_mocks "go.woodpecker-ci.org/woodpecker/v3/server/services/mocks"
+	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/version"
+)
+
+// stubForge is a forge with a configurable health check.
+type stubForge struct {
+	*forge_mocks.MockForge
+	err error
+}
+
+func (f *stubForge) Healthy(_ context.Context) error {
+	return f.err
+}
+
+func resetHealthCache(t *testing.T) {
+	t.Helper()
+	reset := func() {
+		healthCache.Lock()
+		healthCache.checked = time.Time{}
+		healthCache.errs = nil
+		healthCache.Unlock()
+	}
+	reset()
+	t.Cleanup(reset)
+}
+
+func TestHealth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		storeErr   error
+		forgeErr   error
+		otherErr   error
+		wantStatus int
+		wantErrors map[string]string
+	}{
+		{
+			name:       "healthy",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "forge unhealthy",
+			forgeErr:   errors.New("connection refused"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantErrors: map[string]string{"forge": "connection refused"},
+		},
+		{
+			name:       "additional forge unhealthy",
+			otherErr:   errors.New("connection refused"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantErrors: map[string]string{"forge-2": "connection refused"},
+		},
+		{
+			name:       "store and forge unhealthy",
+			storeErr:   errors.New("database is locked"),
+			forgeErr:   errors.New("connection refused"),
+			wantStatus: http.StatusServiceUnavailable,
+			wantErrors: map[string]string{"store": "database is locked", "forge": "connection refused"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetHealthCache(t)
+
+			mockStore := store_mocks.NewMockStore(t)
+			mockStore.On("Ping").Return(tt.storeErr).Once()
+			mockStore.On("ForgeList", mock.Anything).Return([]*model.Forge{{ID: 1}, {ID: 2}}, nil).Once()
+
+			mockQueue := queue_mocks.NewMockQueue(t)
+			mockQueue.On("Info", mock.Anything).Return(queue.InfoT{}).Once()
+			server.Config.Services.Queue = mockQueue
+
+			mockManager := services_mocks.NewMockManager(t)
+			mockManager.On("ForgeByID", int64(1)).Return(&stubForge{MockForge: forge_mocks.NewMockForge(t), err: tt.forgeErr}, nil).Once()
+			mockManager.On("ForgeByID", int64(2)).Return(&stubForge{MockForge: forge_mocks.NewMockForge(t), err: tt.otherErr}, nil).Once()
+			server.Config.Services.Manager = mockManager
+
+			// the second request reuses the result of the first one
+			for range 2 {
+				w := httptest.NewRecorder()
+				c, _ := gin.CreateTestContext(w)
+				c.Request = httptest.NewRequest(http.MethodGet, "/healthz", nil)
+				c.Set("store", mockStore)
+
+				Health(c)
+				c.Writer.WriteHeaderNow()
+
+				assert.Equal(t, tt.wantStatus, w.Code)
+				if tt.wantErrors != nil {
+					var body struct {
+						Errors map[string]string `json:"errors"`
+					}
+					assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+					assert.Equal(t, tt.wantErrors, body.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestHealthCacheExpires(t *testing.T) {
+	resetHealthCache(t)
+
+	mockStore := store_mocks.NewMockStore(t)
+	mockStore.On("Ping").Return(nil).Twice()
+	mockStore.On("ForgeList", mock.Anything).Return([]*model.Forge{}, nil).Twice()
+	mockQueue := queue_mocks.NewMockQueue(t)
+	mockQueue.On("Info", mock.Anything).Return(queue.InfoT{}).Twice()
+	server.Config.Services.Queue = mockQueue
+
+	now := time.Now()
+	assert.Empty(t, checkHealth(t.Context(), mockStore, now))
+	assert.Empty(t, checkHealth(t.Context(), mockStore, now.Add(time.Second)))
+	assert.Empty(t, checkHealth(t.Context(), mockStore, now.Add(healthCacheTTL)))
+}
+
+func TestGetInfo(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockForge := forge_mocks.NewMockForge(t)
+	mockForge.On("Name").Return("gitea")
+	mockManager := services_mocks.NewMockManager(t)
+	mockManager.On("ForgeByID", int64(1)).Return(mockForge, nil)
+	server.Config.Services.Manager = mockManager
+	server.Config.WebUI.EnableSwagger = true
+	server.Config.Logs.Store = "file"
+	t.Cleanup(func() {
+		server.Config.WebUI.EnableSwagger = false
+		server.Config.Logs.Store = ""
+	})
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/info", nil)
+
+	GetInfo(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var info model.ServerInfo
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+	assert.Equal(t, model.ServerInfo{
+		Version: version.String(),
+		Forge:   "gitea",
+		Features: model.ServerFeatures{
+			Swagger:  true,
+			LogStore: "file",
+		},
+	}, info)
+}
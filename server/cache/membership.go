@@ -17,6 +17,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jellydator/ttlcache/v3"
@@ -26,24 +27,66 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 )
 
+const (
+	defaultMembershipTTL  = 10 * time.Minute
+	defaultMembershipSize = 0
+)
+
 // MembershipService is a service to check for user membership.
 type MembershipService interface {
 	// Get returns if the user is a member of the organization.
+	// The organization can also be a team in the format <org>/<team>.
 	Get(ctx context.Context, _forge forge.Forge, u *model.User, org string) (*model.OrgPerm, error)
+	// InvalidateOrg removes the cached memberships of all users of the organization.
+	InvalidateOrg(org string)
+}
+
+// MembershipOptions configures the membership cache.
+type MembershipOptions struct {
+	// TTL is the duration a membership is cached for.
+	TTL time.Duration
+	// Size is the maximum number of cached memberships, the least recently used are evicted first.
+	// Zero means unlimited.
+	Size uint64
+}
+
+type membershipEntry struct {
+	org     string
+	perm    *model.OrgPerm
+	expires time.Time
 }
 
 type membershipCache struct {
-	cache *ttlcache.Cache[string, *model.OrgPerm]
+	cache *ttlcache.Cache[string, membershipEntry]
 	store store.Store
 	ttl   time.Duration
+	now   func() time.Time
 }
 
 // NewMembershipService creates a new membership service.
 func NewMembershipService(_store store.Store) MembershipService {
+	return NewMembershipServiceWithOptions(_store, MembershipOptions{
+		TTL:  defaultMembershipTTL,
+		Size: defaultMembershipSize,
+	})
+}
+
+// NewMembershipServiceWithOptions creates a new membership service with the given cache options.
+func NewMembershipServiceWithOptions(_store store.Store, opts MembershipOptions) MembershipService {
+	if opts.TTL <= 0 {
+		opts.TTL = defaultMembershipTTL
+	}
+	cacheOpts := []ttlcache.Option[string, membershipEntry]{
+		ttlcache.WithTTL[string, membershipEntry](ttlcache.NoTTL),
+	}
+	if opts.Size > 0 {
+		cacheOpts = append(cacheOpts, ttlcache.WithCapacity[string, membershipEntry](opts.Size))
+	}
 	return &membershipCache{
-		ttl:   10 * time.Minute, //nolint:mnd
+		ttl:   opts.TTL,
 		store: _store,
-		cache: ttlcache.New(ttlcache.WithDisableTouchOnHit[string, *model.OrgPerm]()),
+		cache: ttlcache.New(cacheOpts...),
+		now:   time.Now,
 	}
 }
 
@@ -51,14 +94,38 @@ func NewMembershipService(_store store.Store) MembershipService {
 func (c *membershipCache) Get(ctx context.Context, _forge forge.Forge, u *model.User, org string) (*model.OrgPerm, error) {
 	key := fmt.Sprintf("%s-%s", u.ForgeRemoteID, org)
 	item := c.cache.Get(key)
-	if item != nil && !item.IsExpired() {
-		return item.Value(), nil
+	if item != nil && c.now().Before(item.Value().expires) {
+		return item.Value().perm, nil
 	}
 
-	perm, err := _forge.OrgMembership(ctx, u, org)
+	perm, err := fetchMembership(ctx, _forge, u, org)
 	if err != nil {
 		return nil, err
 	}
-	c.cache.Set(key, perm, c.ttl)
+	c.cache.Set(key, membershipEntry{org: org, perm: perm, expires: c.now().Add(c.ttl)}, ttlcache.NoTTL)
 	return perm, nil
 }
+
+// InvalidateOrg removes the cached memberships of all users of the organization.
+func (c *membershipCache) InvalidateOrg(org string) {
+	for key, item := range c.cache.Items() {
+		if item.Value().org == org {
+			c.cache.Delete(key)
+		}
+	}
+}
+
+// fetchMembership asks the forge for the membership.
+// Teams are checked by forges supporting them, all other forges treat them as nested organization.
+func fetchMembership(ctx context.Context, _forge forge.Forge, u *model.User, org string) (*model.OrgPerm, error) {
+	if owner, team, ok := strings.Cut(org, "/"); ok {
+		if checker, ok := _forge.(forge.TeamMembershipChecker); ok {
+			member, err := checker.TeamMembership(ctx, u, owner, team)
+			if err != nil {
+				return nil, err
+			}
+			return &model.OrgPerm{Member: member}, nil
+		}
+	}
+	return _forge.OrgMembership(ctx, u, org)
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	forge_mocks "go.woodpecker-ci.org/woodpecker/v3/server/forge/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+func newTestMembershipCache(t *testing.T, opts MembershipOptions) (*membershipCache, *forge_mocks.MockForge, *time.Time) {
+	now := time.Unix(1000, 0)
+	c, _ := NewMembershipServiceWithOptions(nil, opts).(*membershipCache)
+	c.now = func() time.Time { return now }
+
+	f := forge_mocks.NewMockForge(t)
+	f.On("OrgMembership", mock.Anything, mock.Anything, mock.Anything).Return(func(_ context.Context, _ *model.User, org string) (*model.OrgPerm, error) {
+		return &model.OrgPerm{Member: true, Admin: org == "admins"}, nil
+	}).Maybe()
+	return c, f, &now
+}
+
+func TestMembershipExpiry(t *testing.T) {
+	c, f, now := newTestMembershipCache(t, MembershipOptions{TTL: time.Minute})
+	user := &model.User{ForgeRemoteID: "1"}
+
+	_, err := c.Get(t.Context(), f, user, "org")
+	assert.NoError(t, err)
+	*now = now.Add(59 * time.Second)
+	_, err = c.Get(t.Context(), f, user, "org")
+	assert.NoError(t, err)
+	f.AssertNumberOfCalls(t, "OrgMembership", 1)
+
+	*now = now.Add(time.Second)
+	_, err = c.Get(t.Context(), f, user, "org")
+	assert.NoError(t, err)
+	f.AssertNumberOfCalls(t, "OrgMembership", 2)
+}
+
+func TestMembershipEviction(t *testing.T) {
+	c, f, _ := newTestMembershipCache(t, MembershipOptions{TTL: time.Hour, Size: 2})
+	user := &model.User{ForgeRemoteID: "1"}
+
+	for _, org := range []string{"a", "b", "a", "c"} {
+		_, err := c.Get(t.Context(), f, user, org)
+		assert.NoError(t, err)
+	}
+	// "b" was the least recently used membership when "c" was added
+	f.AssertNumberOfCalls(t, "OrgMembership", 3)
+	assert.Equal(t, 2, c.cache.Len())
+	assert.False(t, c.cache.Has("1-b"))
+
+	_, err := c.Get(t.Context(), f, user, "a")
+	assert.NoError(t, err)
+	f.AssertNumberOfCalls(t, "OrgMembership", 3)
+}
+
+func TestMembershipInvalidateOrg(t *testing.T) {
+	c, f, _ := newTestMembershipCache(t, MembershipOptions{})
+
+	for _, user := range []*model.User{{ForgeRemoteID: "1"}, {ForgeRemoteID: "2"}} {
+		for _, org := range []string{"org", "admins"} {
+			_, err := c.Get(t.Context(), f, user, org)
+			assert.NoError(t, err)
+		}
+	}
+	assert.Equal(t, 4, c.cache.Len())
+
+	c.InvalidateOrg("org")
+	assert.Equal(t, 2, c.cache.Len())
+	assert.True(t, c.cache.Has("1-admins"))
+	assert.True(t, c.cache.Has("2-admins"))
+}
+
+// teamForge is a forge with teams, which only contain the user "team-member".
+type teamForge struct {
+	*forge_mocks.MockForge
+}
+
+func (f *teamForge) TeamMembership(_ context.Context, u *model.User, _, _ string) (bool, error) {
+	return u.Login == "team-member", nil
+}
+
+func TestMembershipTeam(t *testing.T) {
+	c, f, _ := newTestMembershipCache(t, MembershipOptions{})
+	user := &model.User{Login: "team-member", ForgeRemoteID: "1"}
+
+	perm, err := c.Get(t.Context(), &teamForge{f}, user, "org/team")
+	assert.NoError(t, err)
+	assert.Equal(t, &model.OrgPerm{Member: true}, perm)
+	f.AssertNotCalled(t, "OrgMembership", mock.Anything, mock.Anything, mock.Anything)
+
+	// forges without teams resolve them as nested organization
+	perm, err = c.Get(t.Context(), f, &model.User{ForgeRemoteID: "2"}, "group/subgroup")
+	assert.NoError(t, err)
+	assert.True(t, perm.Member)
+	f.AssertCalled(t, "OrgMembership", mock.Anything, mock.Anything, "group/subgroup")
+}
@@ -16,6 +16,8 @@
 package server
 
 import (
+	"slices"
+	"sync/atomic"
 	"time"
 
 	"go.woodpecker-ci.org/woodpecker/v3/server/cache"
@@ -23,39 +25,79 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 	"go.woodpecker-ci.org/woodpecker/v3/server/pubsub"
 	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/header"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/log"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log/limit"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/permissions"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
 )
 
 var Config = struct {
 	Services struct {
-		Pubsub     *pubsub.Publisher
+		Pubsub     pubsub.Broker
 		Queue      queue.Queue
 		Logs       logging.Log
 		Membership cache.MembershipService
 		Manager    services.Manager
 		LogStore   log.Service
+		LogLimiter *limit.Limiter
 	}
 	Server struct {
-		JWTSecret           string
 		Key                 string
 		Cert                string
 		OAuthHost           string
 		Host                string
 		WebhookHost         string
+		WebhookForgeHosts   map[int64]string
 		Port                string
 		PortTLS             string
 		AgentToken          string
 		StatusContext       string
 		StatusContextFormat string
 		SessionExpires      time.Duration
+		SessionSliding      bool
+		SessionMaxLifetime  time.Duration
 		RootPath            string
+		Cookie              httputil.CookieOptions
 		CustomCSSFile       string
 		CustomJsFile        string
+		CustomFilesCacheTTL time.Duration
+		// CORS configures the cross-origin requests allowed to the API, by default only same-origin requests are.
+		CORS header.CORSOptions
 	}
 	Agent struct {
 		DisableUserRegisteredAgentRegistration bool
+		IdleTimeout                            time.Duration
+		DeadTimeout                            time.Duration
+		TokenHashAlgorithm                     string
+		// ResourceScheduling only assigns workflows requesting resources to agents which report enough free resources.
+		ResourceScheduling bool
+	}
+	Webhook struct {
+		RateLimit            float64
+		RateLimitBurst       int
+		GlobalRateLimit      float64
+		GlobalRateLimitBurst int
+		Concurrency          int
+		QueueSize            int
+		// QueueMaxPending is the number of pending tasks in the queue above which webhooks are
+		// handled according to QueueFullAction. Zero disables the limit.
+		QueueMaxPending int
+		QueueFullAction string
+	}
+	Logs struct {
+		// Store is the default log store.
+		Store string
+		// Stores are the log stores which can be selected for single repos.
+		Stores            []string
+		StreamBuffer      int
+		StreamReplayLines int
+		StreamReplayBytes int
+		// MaxSize is the default max log size of a step in bytes, zero means no limit.
+		MaxSize int
+		// MaxLines is the default max log line count of a step, zero means no limit.
+		MaxLines int
 	}
 	WebUI struct {
 		EnableSwagger    bool
@@ -71,22 +113,149 @@ var Config = struct {
 		DefaultApprovalMode                 model.ApprovalMode
 		DefaultWorkflowLabels               map[string]string
 		DefaultClonePlugin                  string
+		DefaultCloneSettings                map[string]any
 		TrustedClonePlugins                 []string
 		Volumes                             []string
 		Networks                            []string
 		PrivilegedPlugins                   []string
+		ExposeSecretsToForks                bool
 		DefaultTimeout                      int64
 		MaxTimeout                          int64
-		Proxy                               struct {
+		MaxOrgRunningPipelines              int
+		// MaxMatrixJobs is the max number of workflows a pipeline may expand to, 0 means unlimited.
+		MaxMatrixJobs int
+		// UnmatchedLabelsWarnOnly only logs a warning for workflows whose labels no registered agent matches,
+		// instead of failing them when they get queued.
+		UnmatchedLabelsWarnOnly bool
+		// PendingTTL is how long a workflow may wait for an agent before it fails, 0 disables the limit.
+		PendingTTL time.Duration
+		// PendingTTLFromTimeout uses the pipeline timeout of the repo as pending ttl.
+		PendingTTLFromTimeout bool
+		Proxy                 struct {
 			No    string
 			HTTP  string
 			HTTPS string
 		}
 	}
 	Permissions struct {
-		Open            bool
-		Admins          *permissions.Admins
+		Open   bool
+		Admins *permissions.Admins
+		// AdminRequire2FA requires admins to verify a TOTP code before they get admin permissions.
+		AdminRequire2FA bool
 		Orgs            *permissions.Orgs
 		OwnersAllowlist *permissions.OwnersAllowlist
 	}
 }{}
+
+// Actions for webhooks received while the queue has more pending tasks than allowed.
+const (
+	// QueueFullReject rejects the webhook, so the forge delivers it again later.
+	QueueFullReject = "reject"
+	// QueueFullBlock creates the pipeline, but blocks it until it gets approved.
+	QueueFullBlock = "block"
+)
+
+// draining is set while the server waits for the running workflows to finish before it shuts down.
+var draining atomic.Bool
+
+// SetDraining marks the server as draining, new webhooks are rejected while it is draining.
+func SetDraining(v bool) {
+	draining.Store(v)
+}
+
+// Draining returns whether the server is draining.
+func Draining() bool {
+	return draining.Load()
+}
+
+// jwtSecret is the secret new server issued tokens are signed with, it is replaced when the secret gets rotated.
+var jwtSecret atomic.Pointer[string]
+
+// SetJWTSecret sets the secret new server issued tokens are signed with.
+func SetJWTSecret(secret string) {
+	jwtSecret.Store(&secret)
+}
+
+// JWTSecret returns the secret new server issued tokens are signed with.
+func JWTSecret() string {
+	if secret := jwtSecret.Load(); secret != nil {
+		return *secret
+	}
+	return ""
+}
+
+// FeatureFlags returns the pipeline feature flags for the given repo of the given org.
+// Org overrides take precedence over the global defaults and repo overrides over the org ones.
+// Allowing PRs and the default timeout are copied to the repo settings on activation,
+// so the repo fields for them are the repo override and are not applied here.
+// Privileged plugins granted to the repo are added to the resulting ones by the step builder.
+func FeatureFlags(org *model.Org, repo *model.Repo) model.FeatureFlags {
+	flags := model.FeatureFlags{
+		AllowPullRequests:    Config.Pipeline.DefaultAllowPullRequests,
+		PrivilegedPlugins:    Config.Pipeline.PrivilegedPlugins,
+		ExposeSecretsToForks: Config.Pipeline.ExposeSecretsToForks,
+		DefaultTimeout:       Config.Pipeline.DefaultTimeout,
+		MaxRunningPipelines:  Config.Pipeline.MaxOrgRunningPipelines,
+	}
+	if org != nil {
+		flags = org.FeatureFlags.Apply(flags)
+	}
+	if repo != nil && repo.ExposeSecretsToForks != nil {
+		flags.ExposeSecretsToForks = *repo.ExposeSecretsToForks
+	}
+	return flags
+}
+
+// TrustedClonePlugins returns the trusted clone plugins of the repo,
+// the plugins granted to the repo by an admin are trusted in addition to the global ones.
+func TrustedClonePlugins(repo *model.Repo) []string {
+	return slices.Concat(Config.Pipeline.TrustedClonePlugins, repo.TrustedClonePlugins)
+}
+
+// MaxTimeout returns the max timeout in minutes of the workflows of the repo.
+// The max timeout of a repo can only lower the global max timeout.
+func MaxTimeout(repo *model.Repo) int64 {
+	if repo.MaxTimeout > 0 && repo.MaxTimeout < Config.Pipeline.MaxTimeout {
+		return repo.MaxTimeout
+	}
+	return Config.Pipeline.MaxTimeout
+}
+
+// MaxMatrixJobs returns the max number of workflows a pipeline of the repo may expand to, 0 means unlimited.
+// Admins can raise or lower the global limit for single repos.
+func MaxMatrixJobs(repo *model.Repo) int {
+	if repo.MaxMatrixJobs > 0 {
+		return repo.MaxMatrixJobs
+	}
+	return Config.Pipeline.MaxMatrixJobs
+}
+
+// PipelineTimeout returns the timeout in minutes of the workflows of the repo:
+// the timeout of the repo or else the global default timeout, capped by the max timeout of the repo.
+func PipelineTimeout(repo *model.Repo) int64 {
+	timeout := repo.Timeout
+	if timeout <= 0 {
+		timeout = Config.Pipeline.DefaultTimeout
+	}
+	if maxTimeout := MaxTimeout(repo); maxTimeout > 0 {
+		timeout = min(timeout, maxTimeout)
+	}
+	return timeout
+}
+
+// PendingTTL returns how long a workflow of the repo may wait for an agent, 0 means unlimited.
+func PendingTTL(repo *model.Repo) time.Duration {
+	if Config.Pipeline.PendingTTLFromTimeout {
+		return time.Duration(PipelineTimeout(repo)) * time.Minute
+	}
+	return Config.Pipeline.PendingTTL
+}
+
+// WebhookHost returns the url the forge with the given id calls the webhooks on.
+// Forges without a specific webhook host use the default one.
+func WebhookHost(forgeID int64) string {
+	if host, ok := Config.Server.WebhookForgeHosts[forgeID]; ok {
+		return host
+	}
+	return Config.Server.WebhookHost
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+func TestWebhookHost(t *testing.T) {
+	Config.Server.WebhookHost = "https://ci.example.com"
+	Config.Server.WebhookForgeHosts = nil
+	t.Cleanup(func() {
+		Config.Server.WebhookHost = ""
+		Config.Server.WebhookForgeHosts = nil
+	})
+
+	assert.Equal(t, "https://ci.example.com", WebhookHost(1))
+
+	Config.Server.WebhookForgeHosts = map[int64]string{2: "http://woodpecker.internal"}
+	assert.Equal(t, "https://ci.example.com", WebhookHost(1))
+	assert.Equal(t, "http://woodpecker.internal", WebhookHost(2))
+}
+
+func TestPipelineTimeout(t *testing.T) {
+	Config.Pipeline.DefaultTimeout = 60
+	Config.Pipeline.MaxTimeout = 120
+	t.Cleanup(func() {
+		Config.Pipeline.DefaultTimeout = 0
+		Config.Pipeline.MaxTimeout = 0
+	})
+
+	tests := []struct {
+		name    string
+		repo    *model.Repo
+		timeout int64
+		max     int64
+	}{
+		{
+			name:    "global default",
+			repo:    &model.Repo{},
+			timeout: 60,
+			max:     120,
+		},
+		{
+			name:    "repo timeout",
+			repo:    &model.Repo{Timeout: 90},
+			timeout: 90,
+			max:     120,
+		},
+		{
+			name:    "repo timeout above global max",
+			repo:    &model.Repo{Timeout: 300},
+			timeout: 120,
+			max:     120,
+		},
+		{
+			name:    "repo max",
+			repo:    &model.Repo{Timeout: 90, MaxTimeout: 30},
+			timeout: 30,
+			max:     30,
+		},
+		{
+			name:    "global default above repo max",
+			repo:    &model.Repo{MaxTimeout: 10},
+			timeout: 10,
+			max:     10,
+		},
+		{
+			name:    "repo max above global max",
+			repo:    &model.Repo{Timeout: 200, MaxTimeout: 240},
+			timeout: 120,
+			max:     120,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.max, MaxTimeout(tt.repo))
+			assert.Equal(t, tt.timeout, PipelineTimeout(tt.repo))
+		})
+	}
+}
+
+func TestMaxMatrixJobs(t *testing.T) {
+	t.Cleanup(func() { Config.Pipeline.MaxMatrixJobs = 0 })
+
+	assert.Zero(t, MaxMatrixJobs(&model.Repo{}))
+
+	Config.Pipeline.MaxMatrixJobs = 20
+	assert.Equal(t, 20, MaxMatrixJobs(&model.Repo{}))
+	// admins can raise and lower the limit of a repo
+	assert.Equal(t, 50, MaxMatrixJobs(&model.Repo{MaxMatrixJobs: 50}))
+	assert.Equal(t, 5, MaxMatrixJobs(&model.Repo{MaxMatrixJobs: 5}))
+}
+
+func TestFeatureFlags(t *testing.T) {
+	t.Cleanup(func() { Config.Pipeline.ExposeSecretsToForks = false })
+	Config.Pipeline.ExposeSecretsToForks = true
+
+	enabled, disabled := true, false
+	org := &model.Org{FeatureFlags: model.OrgFeatureFlags{ExposeSecretsToForks: &disabled}}
+
+	assert.True(t, FeatureFlags(nil, nil).ExposeSecretsToForks)
+	assert.False(t, FeatureFlags(org, &model.Repo{}).ExposeSecretsToForks)
+	// the repo override wins over the org one
+	assert.True(t, FeatureFlags(org, &model.Repo{ExposeSecretsToForks: &enabled}).ExposeSecretsToForks)
+	assert.False(t, FeatureFlags(nil, &model.Repo{ExposeSecretsToForks: &disabled}).ExposeSecretsToForks)
+}
+
+func TestJWTSecret(t *testing.T) {
+	t.Cleanup(func() { SetJWTSecret("") })
+
+	assert.Empty(t, JWTSecret())
+	SetJWTSecret("secret")
+	assert.Equal(t, "secret", JWTSecret())
+}
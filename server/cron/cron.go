@@ -17,9 +17,11 @@ package cron
 import (
 	"context"
 	"fmt"
+	"os"
 	"time"
 
 	"github.com/gdgvda/cron"
+	"github.com/google/tink/go/subtle/random"
 	"github.com/rs/zerolog/log"
 
 	"go.woodpecker-ci.org/woodpecker/v3/server"
@@ -35,17 +37,35 @@ const (
 
 	// Specifies the batch size of crons to retrieve per check from database.
 	checkItems = 10
+
+	// Name of the lease that elects the instance running crons.
+	leaseName = "cron"
+
+	// Specifies how long the lease is valid without being renewed, after that another instance takes over.
+	leaseTTL = 2 * checkTime
 )
 
 // Run starts the cron scheduler loop.
+// If multiple servers share the same store, only the instance holding the cron lease executes due crons.
 func Run(ctx context.Context, store store.Store) error {
+	lease := &model.Lease{Name: leaseName, Holder: instanceID()}
+	defer func() {
+		if err := store.LeaseRelease(lease); err != nil {
+			log.Error().Err(err).Msg("release cron lease")
+		}
+	}()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
 		case <-time.After(checkTime):
+			now := time.Now()
+			if !acquireLease(store, lease, now) {
+				continue
+			}
+
 			go func() {
-				now := time.Now()
 				log.Trace().Msg("cron: fetch next crons")
 
 				crons, err := store.CronListNextExecute(now.Unix(), checkItems)
@@ -64,6 +84,25 @@ func Run(ctx context.Context, store store.Store) error {
 	}
 }
 
+// acquireLease takes or renews the cron lease and reports whether this instance is allowed to run crons.
+func acquireLease(store store.Store, lease *model.Lease, now time.Time) bool {
+	lease.Expires = now.Add(leaseTTL).Unix()
+	ok, err := store.LeaseAcquire(lease, now.Unix())
+	if err != nil {
+		log.Error().Err(err).Msg("acquire cron lease")
+		return false
+	}
+	if !ok {
+		log.Trace().Msg("cron: lease is held by another instance")
+	}
+	return ok
+}
+
+func instanceID() string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s-%x", hostname, random.GetRandomBytes(4)) //nolint:mnd
+}
+
 // CalcNewNext parses a cron string and calculates the next exec time based on it.
 func CalcNewNext(schedule string, now time.Time) (time.Time, error) {
 	// remove local timezone
@@ -145,5 +184,7 @@ func CreatePipeline(ctx context.Context, store store.Store, cron *model.Cron) (*
 		Timestamp: cron.NextExec,
 		Sender:    cron.Name,
 		ForgeURL:  commit.ForgeURL,
+		// cron variables are only set for pipelines triggered by the cron
+		AdditionalVariables: cron.Variables,
 	}, nil
 }
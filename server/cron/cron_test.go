@@ -15,6 +15,7 @@
 package cron
 
 import (
+	"errors"
 	"testing"
 	"time"
 
@@ -57,17 +58,19 @@ func TestCreatePipeline(t *testing.T) {
 	server.Config.Services.Manager = _manager
 
 	_, pipeline, err := CreatePipeline(ctx, store, &model.Cron{
-		Name: "test",
+		Name:      "test",
+		Variables: map[string]string{"RELEASE": "nightly"},
 	})
 	assert.NoError(t, err)
 	assert.EqualValues(t, &model.Pipeline{
-		Branch:   "default",
-		Commit:   "sha1",
-		Event:    "cron",
-		ForgeURL: "https://example.com/sha1",
-		Message:  "test",
-		Ref:      "refs/heads/default",
-		Sender:   "test",
+		Branch:              "default",
+		Commit:              "sha1",
+		Event:               "cron",
+		ForgeURL:            "https://example.com/sha1",
+		Message:             "test",
+		Ref:                 "refs/heads/default",
+		Sender:              "test",
+		AdditionalVariables: map[string]string{"RELEASE": "nightly"},
 	}, pipeline)
 }
 
@@ -80,3 +83,43 @@ func TestCalcNewNext(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, 1661962669, schedule.Unix())
 }
+
+func TestAcquireLease(t *testing.T) {
+	now := time.Unix(1661962369, 0)
+	store := store_mocks.NewMockStore(t)
+
+	// simulate the store side of the lease shared by two instances
+	var holder string
+	var expires int64
+	store.On("LeaseAcquire", mock.Anything, mock.Anything).Return(func(lease *model.Lease, now int64) (bool, error) {
+		if holder != "" && holder != lease.Holder && expires >= now {
+			return false, nil
+		}
+		holder, expires = lease.Holder, lease.Expires
+		return true, nil
+	})
+
+	instance1 := &model.Lease{Name: leaseName, Holder: "instance-1"}
+	instance2 := &model.Lease{Name: leaseName, Holder: "instance-2"}
+
+	assert.True(t, acquireLease(store, instance1, now))
+	assert.Equal(t, now.Add(leaseTTL).Unix(), instance1.Expires)
+	assert.False(t, acquireLease(store, instance2, now))
+
+	// leader renews its lease on every check
+	now = now.Add(checkTime)
+	assert.True(t, acquireLease(store, instance1, now))
+	assert.False(t, acquireLease(store, instance2, now))
+
+	// leader died, the other instance takes over after the lease expired
+	now = now.Add(leaseTTL + time.Second)
+	assert.True(t, acquireLease(store, instance2, now))
+	assert.False(t, acquireLease(store, instance1, now))
+}
+
+func TestAcquireLeaseError(t *testing.T) {
+	store := store_mocks.NewMockStore(t)
+	store.On("LeaseAcquire", mock.Anything, mock.Anything).Return(false, errors.New("database gone"))
+
+	assert.False(t, acquireLease(store, &model.Lease{Name: leaseName, Holder: "instance-1"}, time.Now()))
+}
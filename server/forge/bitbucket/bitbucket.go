@@ -78,6 +78,11 @@ func (c *config) URL() string {
 	return c.url
 }
 
+// Healthy checks if the Bitbucket API can be reached.
+func (c *config) Healthy(ctx context.Context) error {
+	return common.CheckAPI(ctx, c.api+"/2.0/user", false, nil, "forge-bitbucket")
+}
+
 // Login authenticates an account with Bitbucket using the oauth2 protocol. The
 // Bitbucket account details are returned when the user is successfully authenticated.
 func (c *config) Login(ctx context.Context, req *forge_types.OAuthRequest) (*model.User, string, error) {
@@ -289,7 +294,7 @@ func (c *config) Dir(ctx context.Context, u *model.User, r *model.Repo, p *model
 // Status creates a pipeline status for the Bitbucket commit.
 func (c *config) Status(ctx context.Context, user *model.User, repo *model.Repo, pipeline *model.Pipeline, workflow *model.Workflow) error {
 	status := internal.PipelineStatus{
-		State: convertStatus(workflow.State),
+		State: convertStatus(common.GetWorkflowStatus(repo, workflow)),
 		Desc:  common.GetPipelineStatusDescription(workflow.State),
 		Key:   common.GetPipelineStatusContext(repo, pipeline, workflow),
 		URL:   common.GetPipelineStatusURL(repo, pipeline, workflow),
@@ -102,6 +102,11 @@ func (c *client) URL() string {
 	return c.url
 }
 
+// Healthy checks if the Bitbucket Datacenter API can be reached.
+func (c *client) Healthy(ctx context.Context) error {
+	return common.CheckAPI(ctx, c.urlAPI+"/api/latest/application-properties", false, nil, "forge-bitbucketdatacenter")
+}
+
 func (c *client) Login(ctx context.Context, req *forge_types.OAuthRequest) (*model.User, string, error) {
 	config := c.newOAuth2Config()
 
@@ -333,7 +338,7 @@ func (c *client) Status(ctx context.Context, u *model.User, repo *model.Repo, pi
 		return fmt.Errorf("unable to create bitbucket client: %w", err)
 	}
 	status := &bb.BuildStatus{
-		State:       convertStatus(workflow.State),
+		State:       convertStatus(common.GetWorkflowStatus(repo, workflow)),
 		URL:         common.GetPipelineStatusURL(repo, pipeline, workflow),
 		Key:         common.GetPipelineStatusContext(repo, pipeline, workflow),
 		Description: common.GetPipelineStatusDescription(workflow.State),
@@ -509,7 +514,7 @@ func (c *client) Hook(ctx context.Context, r *http.Request) (*model.Repo, *model
 		return nil, nil, fmt.Errorf("failed to get user and repo: %w", err)
 	}
 
-	err = bb.ValidateSignature(r, hook.Payload, []byte(repo.Hash))
+	err = validateSignature(r, hook.Payload, repo.WebhookSecrets(time.Now().Unix()))
 	if err != nil {
 		return nil, nil, fmt.Errorf("unable to validate signature on incoming webhook payload: %w", err)
 	}
@@ -534,6 +539,16 @@ func (c *client) Hook(ctx context.Context, r *http.Request) (*model.Repo, *model
 	return repo, pipe, nil
 }
 
+// validateSignature checks the payload is signed with any of the webhook secrets of the repo.
+func validateSignature(r *http.Request, payload []byte, secrets []string) (err error) {
+	for _, secret := range secrets {
+		if err = bb.ValidateSignature(r, payload, []byte(secret)); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
 func (c *client) getUserAndRepo(ctx context.Context, r *model.Repo) (*model.User, *model.Repo, error) {
 	_store, ok := store.TryFromContext(ctx)
 	if !ok {
@@ -15,10 +15,16 @@
 package bitbucketdatacenter
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	bb "github.com/neticdk/go-bitbucket/bitbucket"
 	"github.com/stretchr/testify/assert"
 
 	"go.woodpecker-ci.org/woodpecker/v3/server"
@@ -126,3 +132,19 @@ var (
 		State: model.StatusSuccess,
 	}
 )
+
+func TestValidateSignature(t *testing.T) {
+	payload := []byte(`{"eventKey":"repo:refs_changed"}`)
+	sign := func(secret string) *http.Request {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		r := httptest.NewRequest(http.MethodPost, "/api/hook", nil)
+		r.Header.Set(bb.EventSignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		return r
+	}
+	secrets := []string{"current-secret", "previous-secret"}
+
+	assert.NoError(t, validateSignature(sign("current-secret"), payload, secrets))
+	assert.NoError(t, validateSignature(sign("previous-secret"), payload, secrets))
+	assert.Error(t, validateSignature(sign("unknown-secret"), payload, secrets))
+}
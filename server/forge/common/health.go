@@ -0,0 +1,44 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
+)
+
+// CheckAPI requests the given API url and returns an error if the forge could not be reached
+// or answered with a server error. Client errors like 401 are fine, as the check is done without a user token.
+func CheckAPI(ctx context.Context, url string, skipVerify bool, proxy ProxyFunc, component string) error {
+	client := httputil.WrapClient(&http.Client{Transport: NewTransport(proxy, skipVerify)}, component)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("forge api returned status %d", resp.StatusCode)
+	}
+	return nil
+}
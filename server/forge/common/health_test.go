@@ -0,0 +1,42 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAPI(t *testing.T) {
+	status := http.StatusOK
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	assert.NoError(t, CheckAPI(t.Context(), srv.URL, false, nil, "test"))
+
+	status = http.StatusUnauthorized
+	assert.NoError(t, CheckAPI(t.Context(), srv.URL, false, nil, "test"))
+
+	status = http.StatusBadGateway
+	assert.EqualError(t, CheckAPI(t.Context(), srv.URL, false, nil, "test"), "forge api returned status 502")
+
+	srv.Close()
+	assert.Error(t, CheckAPI(t.Context(), srv.URL, false, nil, "test"))
+}
@@ -0,0 +1,67 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ProxyFunc returns the proxy to use for a request, nil means no proxy.
+type ProxyFunc func(*http.Request) (*url.URL, error)
+
+// NewProxyFunc returns a ProxyFunc that sends all requests through the given proxy,
+// except the ones to hosts matching the comma separated noProxy list.
+// If no proxy is set, nil is returned and the proxy is taken from the environment.
+func NewProxyFunc(proxy, noProxy string) (ProxyFunc, error) {
+	if proxy == "" {
+		return nil, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy url '%s': scheme and host are required", proxy)
+	}
+
+	proxyForURL := (&httpproxy.Config{
+		HTTPProxy:  proxy,
+		HTTPSProxy: proxy,
+		NoProxy:    noProxy,
+	}).ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return proxyForURL(req.URL)
+	}, nil
+}
+
+// NewTransport returns a http transport using the given proxy, or the one from the
+// environment if it is nil, that skips the TLS verification if requested.
+func NewTransport(proxy ProxyFunc, skipVerify bool) *http.Transport {
+	transport, _ := http.DefaultTransport.(*http.Transport)
+	transport = transport.Clone()
+	if proxy != nil {
+		transport.Proxy = proxy
+	}
+	if skipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return transport
+}
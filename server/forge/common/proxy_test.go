@@ -0,0 +1,92 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package common
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProxyFunc(t *testing.T) {
+	proxy, err := NewProxyFunc("", "")
+	assert.NoError(t, err)
+	assert.Nil(t, proxy)
+
+	_, err = NewProxyFunc("proxy.example.com:3128", "")
+	assert.Error(t, err)
+
+	_, err = NewProxyFunc("http://[::1", "")
+	assert.Error(t, err)
+}
+
+func TestNewTransportProxy(t *testing.T) {
+	githubProxy, err := NewProxyFunc("http://proxy.example.com:3128", "internal.example.com,.corp.example.com")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		proxy ProxyFunc
+		url   string
+		want  string
+	}{
+		{
+			name:  "proxied https",
+			proxy: githubProxy,
+			url:   "https://api.github.com/user",
+			want:  "http://proxy.example.com:3128",
+		},
+		{
+			name:  "proxied http",
+			proxy: githubProxy,
+			url:   "http://github.example.com/api/v3/",
+			want:  "http://proxy.example.com:3128",
+		},
+		{
+			name:  "no proxy host",
+			proxy: githubProxy,
+			url:   "https://internal.example.com/api/v1/version",
+		},
+		{
+			name:  "no proxy subdomain",
+			proxy: githubProxy,
+			url:   "https://gitea.corp.example.com/api/v1/version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, tt.url, nil)
+			require.NoError(t, err)
+
+			got, err := NewTransport(tt.proxy, false).Proxy(req)
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Nil(t, got)
+				return
+			}
+			assert.Equal(t, tt.want, got.String())
+		})
+	}
+}
+
+func TestNewTransportEnvironmentProxy(t *testing.T) {
+	transport := NewTransport(nil, true)
+	assert.True(t, transport.TLSClientConfig.InsecureSkipVerify)
+	assert.NotNil(t, transport.Proxy)
+	assert.NotSame(t, http.DefaultTransport, transport)
+}
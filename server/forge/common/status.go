@@ -17,6 +17,8 @@ package common
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"maps"
 	"text/template"
 
 	"github.com/rs/zerolog/log"
@@ -26,31 +28,67 @@ import (
 )
 
 func GetPipelineStatusContext(repo *model.Repo, pipeline *model.Pipeline, workflow *model.Workflow) string {
-	event := string(pipeline.Event)
-	if pipeline.Event == model.EventPull {
-		event = "pr"
-	}
-
-	tmpl, err := template.New("context").Parse(server.Config.Server.StatusContextFormat)
+	tmpl, err := parseStatusContextFormat(server.Config.Server.StatusContextFormat)
 	if err != nil {
 		log.Error().Err(err).Msg("could not create status from template")
 		return ""
 	}
 	var ctx bytes.Buffer
-	err = tmpl.Execute(&ctx, map[string]any{
+	err = tmpl.Execute(&ctx, statusContextData(repo, pipeline, workflow))
+	if err != nil {
+		log.Error().Err(err).Msg("could not create status context")
+		return ""
+	}
+
+	return ctx.String()
+}
+
+// ValidateStatusContextFormat checks that the status context format can be rendered.
+func ValidateStatusContextFormat(format string) error {
+	tmpl, err := parseStatusContextFormat(format)
+	if err != nil {
+		return err
+	}
+	return tmpl.Execute(io.Discard, statusContextData(
+		&model.Repo{Owner: "owner", Name: "repo"},
+		&model.Pipeline{Event: model.EventPush},
+		&model.Workflow{Name: "workflow", AxisID: 1, Environ: map[string]string{"AXIS": "value"}},
+	))
+}
+
+// parseStatusContextFormat parses the status context format, missing matrix variables are empty.
+func parseStatusContextFormat(format string) (*template.Template, error) {
+	return template.New("context").Option("missingkey=zero").Parse(format)
+}
+
+// statusContextData returns the variables which can be used in the status context format.
+func statusContextData(repo *model.Repo, pipeline *model.Pipeline, workflow *model.Workflow) map[string]any {
+	event := string(pipeline.Event)
+	if pipeline.Event == model.EventPull {
+		event = "pr"
+	}
+
+	matrix := make(map[string]string, len(workflow.Environ))
+	maps.Copy(matrix, workflow.Environ)
+
+	return map[string]any{
 		"context":  server.Config.Server.StatusContext,
 		"event":    event,
 		"workflow": workflow.Name,
 		"owner":    repo.Owner,
 		"repo":     repo.Name,
 		"axis_id":  workflow.AxisID,
-	})
-	if err != nil {
-		log.Error().Err(err).Msg("could not create status context")
-		return ""
+		"matrix":   matrix,
 	}
+}
 
-	return ctx.String()
+// GetWorkflowStatus returns the state of the workflow reported to the forge. If the repo reports the status
+// of every workflow, skipped workflows are reported as successful to not block merges requiring them.
+func GetWorkflowStatus(repo *model.Repo, workflow *model.Workflow) model.StatusValue {
+	if repo.WorkflowStatusChecks && workflow.State == model.StatusSkipped {
+		return model.StatusSuccess
+	}
+	return workflow.State
 }
 
 // GetPipelineStatusDescription is a helper function that generates a description
@@ -71,6 +109,8 @@ func GetPipelineStatusDescription(status model.StatusValue) string {
 		return "Pipeline is pending approval"
 	case model.StatusDeclined:
 		return "Pipeline was rejected"
+	case model.StatusSkipped:
+		return "Pipeline was skipped"
 	default:
 		return "unknown status"
 	}
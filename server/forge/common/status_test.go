@@ -47,3 +47,47 @@ func TestGetPipelineStatusContext(t *testing.T) {
 	server.Config.Server.StatusContextFormat = "{{ .context }}:{{ .owner }}/{{ .repo }}:{{ .event }}:{{ .workflow }}"
 	assert.EqualValues(t, "ci:user1/repo1:push:lint", GetPipelineStatusContext(repo, pipeline, workflow))
 }
+
+func TestGetPipelineStatusContextMatrix(t *testing.T) {
+	origFormat := server.Config.Server.StatusContextFormat
+	origCtx := server.Config.Server.StatusContext
+	defer func() {
+		server.Config.Server.StatusContextFormat = origFormat
+		server.Config.Server.StatusContext = origCtx
+	}()
+
+	repo := &model.Repo{Owner: "user1", Name: "repo1"}
+	pipeline := &model.Pipeline{Event: model.EventPush}
+	workflow := &model.Workflow{Name: "test", AxisID: 2, Environ: map[string]string{"GO_VERSION": "1.24", "DB": "postgres"}}
+
+	server.Config.Server.StatusContext = "ci/woodpecker"
+	server.Config.Server.StatusContextFormat = "{{ .context }}/{{ .event }}/{{ .workflow }}{{ with .matrix.GO_VERSION }} (go {{ . }}{{ with $.matrix.DB }}, {{ . }}{{ end }}){{ end }}"
+	assert.Equal(t, "ci/woodpecker/push/test (go 1.24, postgres)", GetPipelineStatusContext(repo, pipeline, workflow))
+
+	// workflows without matrix have no matrix variables
+	assert.Equal(t, "ci/woodpecker/push/lint", GetPipelineStatusContext(repo, pipeline, &model.Workflow{Name: "lint"}))
+
+	// the default format keeps using the axis id
+	server.Config.Server.StatusContextFormat = "{{ .context }}/{{ .event }}/{{ .workflow }}{{if not (eq .axis_id 0)}}/{{.axis_id}}{{end}}"
+	assert.Equal(t, "ci/woodpecker/push/test/2", GetPipelineStatusContext(repo, pipeline, workflow))
+}
+
+func TestValidateStatusContextFormat(t *testing.T) {
+	assert.NoError(t, ValidateStatusContextFormat("{{ .context }}/{{ .workflow }}/{{ .matrix.GO_VERSION }}"))
+	assert.Error(t, ValidateStatusContextFormat("{{ .context "))
+	assert.Error(t, ValidateStatusContextFormat("{{ .workflow.Name }}"))
+}
+
+func TestGetWorkflowStatus(t *testing.T) {
+	repo := &model.Repo{}
+	skipped := &model.Workflow{State: model.StatusSkipped}
+	failed := &model.Workflow{State: model.StatusFailure}
+
+	assert.Equal(t, model.StatusSkipped, GetWorkflowStatus(repo, skipped))
+	assert.Equal(t, model.StatusFailure, GetWorkflowStatus(repo, failed))
+
+	repo.WorkflowStatusChecks = true
+	assert.Equal(t, model.StatusSuccess, GetWorkflowStatus(repo, skipped))
+	assert.Equal(t, model.StatusFailure, GetWorkflowStatus(repo, failed))
+	assert.Equal(t, "Pipeline was skipped", GetPipelineStatusDescription(model.StatusSkipped))
+}
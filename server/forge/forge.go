@@ -139,7 +139,7 @@ type Forge interface {
 	//
 	// Webhook Processing Flow:
 	//  1. HTTP request arrives at /api/hook with forge-specific format
-	//  2. Webhook token verified against repo.Hash
+	//  2. Webhook token verified against repo.Hash (or a previous hash during its grace period)
 	//  3. Hook() parses webhook and returns (Repo, Pipeline, error)
 	//
 	// Return Semantics:
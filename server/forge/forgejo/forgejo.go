@@ -16,7 +16,6 @@ package forgejo
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -51,16 +50,18 @@ type Forgejo struct {
 	oAuthClientID     string
 	oAuthClientSecret string
 	skipVerify        bool
+	proxy             common.ProxyFunc
 	pageSize          int
 }
 
 // Opts defines configuration options.
 type Opts struct {
-	URL               string // Forgejo server url.
-	OAuth2URL         string // User-facing Forgejo server url for OAuth2.
-	OAuthClientID     string // OAuth2 Client ID
-	OAuthClientSecret string // OAuth2 Client Secret
-	SkipVerify        bool   // Skip ssl verification.
+	URL               string           // Forgejo server url.
+	OAuth2URL         string           // User-facing Forgejo server url for OAuth2.
+	OAuthClientID     string           // OAuth2 Client ID
+	OAuthClientSecret string           // OAuth2 Client Secret
+	SkipVerify        bool             // Skip ssl verification.
+	Proxy             common.ProxyFunc // Proxy for requests to the forge, taken from the environment if nil.
 }
 
 // New returns a Forge implementation that integrates with Forgejo,
@@ -76,6 +77,7 @@ func New(opts Opts) (forge.Forge, error) {
 		oAuthClientID:     opts.OAuthClientID,
 		oAuthClientSecret: opts.OAuthClientSecret,
 		skipVerify:        opts.SkipVerify,
+		proxy:             opts.Proxy,
 	}, nil
 }
 
@@ -89,6 +91,11 @@ func (c *Forgejo) URL() string {
 	return c.url
 }
 
+// Healthy checks if the Forgejo API can be reached.
+func (c *Forgejo) Healthy(ctx context.Context) error {
+	return common.CheckAPI(ctx, c.url+"/api/v1/version", c.skipVerify, c.proxy, "forge-forgejo")
+}
+
 func (c *Forgejo) oauth2Config(ctx context.Context) (*oauth2.Config, context.Context) {
 	return &oauth2.Config{
 			ClientID:     c.oAuthClientID,
@@ -100,10 +107,7 @@ func (c *Forgejo) oauth2Config(ctx context.Context) (*oauth2.Config, context.Con
 			RedirectURL: fmt.Sprintf("%s/authorize", server.Config.Server.OAuthHost),
 		},
 
-		context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.skipVerify},
-			Proxy:           http.ProxyFromEnvironment,
-		}})
+		context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: common.NewTransport(c.proxy, c.skipVerify)})
 }
 
 // Login authenticates an account with Forgejo using basic authentication. The
@@ -334,7 +338,7 @@ func (c *Forgejo) Status(ctx context.Context, user *model.User, repo *model.Repo
 		repo.Name,
 		pipeline.Commit,
 		forgejo.CreateStatusOption{
-			State:       getStatus(workflow.State),
+			State:       getStatus(common.GetWorkflowStatus(repo, workflow)),
 			TargetURL:   common.GetPipelineStatusURL(repo, pipeline, workflow),
 			Description: common.GetPipelineStatusDescription(workflow.State),
 			Context:     common.GetPipelineStatusContext(repo, pipeline, workflow),
@@ -551,6 +555,35 @@ func (c *Forgejo) OrgMembership(ctx context.Context, u *model.User, owner string
 	return &model.OrgPerm{Member: member, Admin: perm.IsAdmin || perm.IsOwner}, nil
 }
 
+// TeamMembership returns if user is member of the team of the organization.
+func (c *Forgejo) TeamMembership(ctx context.Context, u *model.User, org, team string) (bool, error) {
+	client, err := c.newClientToken(ctx, u.AccessToken)
+	if err != nil {
+		return false, err
+	}
+
+	teams, _, err := client.SearchOrgTeams(org, &forgejo.SearchTeamsOptions{Query: team})
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range teams {
+		if !strings.EqualFold(t.Name, team) {
+			continue
+		}
+		_, resp, err := client.GetTeamMember(t.ID, u.Login)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
 func (c *Forgejo) Org(ctx context.Context, u *model.User, owner string) (*model.Org, error) {
 	client, err := c.newClientToken(ctx, u.AccessToken)
 	if err != nil {
@@ -581,12 +614,7 @@ func (c *Forgejo) Org(ctx context.Context, u *model.User, owner string) (*model.
 
 // newClientToken returns a Forgejo client with token.
 func (c *Forgejo) newClientToken(ctx context.Context, token string) (*forgejo.Client, error) {
-	httpClient := &http.Client{}
-	if c.skipVerify {
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
+	httpClient := &http.Client{Transport: common.NewTransport(c.proxy, c.skipVerify)}
 	wrappedClient := httputil.WrapClient(httpClient, "forge-forgejo")
 	client, err := forgejo.NewClient(c.url, forgejo.SetToken(token), forgejo.SetHTTPClient(wrappedClient), forgejo.SetContext(ctx))
 	if err != nil &&
@@ -18,7 +18,6 @@ package gitea
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -53,16 +52,18 @@ type Gitea struct {
 	oAuthClientSecret string
 	oAuthHost         string
 	skipVerify        bool
+	proxy             common.ProxyFunc
 	pageSize          int
 }
 
 // Opts defines configuration options.
 type Opts struct {
-	URL               string // Gitea server url.
-	OAuthClientID     string // OAuth2 Client ID
-	OAuthClientSecret string // OAuth2 Client Secret
-	OAuthHost         string // OAuth2 Host
-	SkipVerify        bool   // Skip ssl verification.
+	URL               string           // Gitea server url.
+	OAuthClientID     string           // OAuth2 Client ID
+	OAuthClientSecret string           // OAuth2 Client Secret
+	OAuthHost         string           // OAuth2 Host
+	SkipVerify        bool             // Skip ssl verification.
+	Proxy             common.ProxyFunc // Proxy for requests to the forge, taken from the environment if nil.
 }
 
 // New returns a Forge implementation that integrates with Gitea,
@@ -74,6 +75,7 @@ func New(opts Opts) (forge.Forge, error) {
 		oAuthClientSecret: opts.OAuthClientSecret,
 		oAuthHost:         opts.OAuthHost,
 		skipVerify:        opts.SkipVerify,
+		proxy:             opts.Proxy,
 	}, nil
 }
 
@@ -87,6 +89,11 @@ func (c *Gitea) URL() string {
 	return c.url
 }
 
+// Healthy checks if the Gitea API can be reached.
+func (c *Gitea) Healthy(ctx context.Context) error {
+	return common.CheckAPI(ctx, c.url+"/api/v1/version", c.skipVerify, c.proxy, "forge-gitea")
+}
+
 func (c *Gitea) oauth2Config(ctx context.Context) (*oauth2.Config, context.Context) {
 	publicOAuthURL := c.oAuthHost
 	if publicOAuthURL == "" {
@@ -102,10 +109,7 @@ func (c *Gitea) oauth2Config(ctx context.Context) (*oauth2.Config, context.Conte
 			RedirectURL: fmt.Sprintf("%s/authorize", server.Config.Server.OAuthHost),
 		},
 
-		context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: c.skipVerify},
-			Proxy:           http.ProxyFromEnvironment,
-		}})
+		context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: common.NewTransport(c.proxy, c.skipVerify)})
 }
 
 // Login authenticates an account with Gitea using basic authentication. The
@@ -336,7 +340,7 @@ func (c *Gitea) Status(ctx context.Context, user *model.User, repo *model.Repo,
 		repo.Name,
 		pipeline.Commit,
 		gitea.CreateStatusOption{
-			State:       getStatus(workflow.State),
+			State:       getStatus(common.GetWorkflowStatus(repo, workflow)),
 			TargetURL:   common.GetPipelineStatusURL(repo, pipeline, workflow),
 			Description: common.GetPipelineStatusDescription(workflow.State),
 			Context:     common.GetPipelineStatusContext(repo, pipeline, workflow),
@@ -558,6 +562,35 @@ func (c *Gitea) OrgMembership(ctx context.Context, u *model.User, owner string)
 	return &model.OrgPerm{Member: member, Admin: perm.IsAdmin || perm.IsOwner}, nil
 }
 
+// TeamMembership returns if user is member of the team of the organization.
+func (c *Gitea) TeamMembership(ctx context.Context, u *model.User, org, team string) (bool, error) {
+	client, err := c.newClientToken(ctx, u.AccessToken)
+	if err != nil {
+		return false, err
+	}
+
+	teams, _, err := client.SearchOrgTeams(org, &gitea.SearchTeamsOptions{Query: team})
+	if err != nil {
+		return false, err
+	}
+
+	for _, t := range teams {
+		if !strings.EqualFold(t.Name, team) {
+			continue
+		}
+		_, resp, err := client.GetTeamMember(t.ID, u.Login)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	return false, nil
+}
+
 func (c *Gitea) Org(ctx context.Context, u *model.User, owner string) (*model.Org, error) {
 	client, err := c.newClientToken(ctx, u.AccessToken)
 	if err != nil {
@@ -588,12 +621,7 @@ func (c *Gitea) Org(ctx context.Context, u *model.User, owner string) (*model.Or
 
 // newClientToken returns the Gitea client with Token.
 func (c *Gitea) newClientToken(ctx context.Context, token string) (*gitea.Client, error) {
-	httpClient := &http.Client{}
-	if c.skipVerify {
-		httpClient.Transport = &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-		}
-	}
+	httpClient := &http.Client{Transport: common.NewTransport(c.proxy, c.skipVerify)}
 	wrappedClient := httputil.WrapClient(httpClient, "forge-gitea")
 	client, err := gitea.NewClient(c.url, gitea.SetToken(token), gitea.SetHTTPClient(wrappedClient), gitea.SetContext(ctx))
 	if err != nil &&
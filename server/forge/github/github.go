@@ -17,7 +17,6 @@ package github
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"net/http"
@@ -52,13 +51,14 @@ const (
 
 // Opts defines configuration options.
 type Opts struct {
-	URL               string // GitHub server url.
-	OAuthClientID     string // GitHub oauth client id.
-	OAuthClientSecret string // GitHub oauth client secret.
-	SkipVerify        bool   // Skip ssl verification.
-	MergeRef          bool   // Clone pull requests using the merge ref.
-	OnlyPublic        bool   // Only obtain OAuth tokens with access to public repos.
-	OAuthHost         string // Public url for oauth if different from url.
+	URL               string           // GitHub server url.
+	OAuthClientID     string           // GitHub oauth client id.
+	OAuthClientSecret string           // GitHub oauth client secret.
+	SkipVerify        bool             // Skip ssl verification.
+	MergeRef          bool             // Clone pull requests using the merge ref.
+	OnlyPublic        bool             // Only obtain OAuth tokens with access to public repos.
+	OAuthHost         string           // Public url for oauth if different from url.
+	Proxy             common.ProxyFunc // Proxy for requests to the forge, taken from the environment if nil.
 }
 
 // New returns a Forge implementation that integrates with a GitHub Cloud or
@@ -73,6 +73,7 @@ func New(opts Opts) (forge.Forge, error) {
 		SkipVerify: opts.SkipVerify,
 		MergeRef:   opts.MergeRef,
 		OnlyPublic: opts.OnlyPublic,
+		proxy:      opts.Proxy,
 	}
 	if opts.URL != defaultURL {
 		r.url = strings.TrimSuffix(opts.URL, "/")
@@ -91,6 +92,7 @@ type client struct {
 	MergeRef   bool
 	OnlyPublic bool
 	oAuthHost  string
+	proxy      common.ProxyFunc
 }
 
 // Name returns the string name of this driver.
@@ -103,6 +105,11 @@ func (c *client) URL() string {
 	return c.url
 }
 
+// Healthy checks if the GitHub API can be reached.
+func (c *client) Healthy(ctx context.Context) error {
+	return common.CheckAPI(ctx, c.API+"meta", c.SkipVerify, c.proxy, "forge-github")
+}
+
 // Login authenticates the session and returns the forge user details.
 func (c *client) Login(ctx context.Context, req *forge_types.OAuthRequest) (*model.User, string, error) {
 	config := c.newConfig()
@@ -392,6 +399,20 @@ func (c *client) OrgMembership(ctx context.Context, u *model.User, owner string)
 	return &model.OrgPerm{Member: org.GetState() == "active", Admin: org.GetRole() == "admin"}, nil
 }
 
+// TeamMembership returns if user is an active member of the team of the organization.
+func (c *client) TeamMembership(ctx context.Context, u *model.User, org, team string) (bool, error) {
+	client := c.newClientToken(ctx, u.AccessToken)
+	membership, resp, err := client.Teams.GetTeamMembershipBySlug(ctx, org, team, u.Login)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return membership.GetState() == "active", nil
+}
+
 func (c *client) Org(ctx context.Context, u *model.User, owner string) (*model.Org, error) {
 	client := c.newClientToken(ctx, u.AccessToken)
 
@@ -417,18 +438,13 @@ func (c *client) Org(ctx context.Context, u *model.User, owner string) (*model.O
 }
 
 // newContext returns the GitHub oauth2 context using an HTTPClient that
-// disables TLS verification if disabled in the forge settings.
+// disables TLS verification if disabled in the forge settings and uses the forge's proxy.
 func (c *client) newContext(ctx context.Context) context.Context {
-	if !c.SkipVerify {
+	if !c.SkipVerify && c.proxy == nil {
 		return ctx
 	}
 	return context.WithValue(ctx, oauth2.HTTPClient, &http.Client{
-		Transport: &http.Transport{
-			Proxy: http.ProxyFromEnvironment,
-			TLSClientConfig: &tls.Config{
-				InsecureSkipVerify: true,
-			},
-		},
+		Transport: common.NewTransport(c.proxy, c.SkipVerify),
 	})
 }
 
@@ -474,17 +490,8 @@ func (c *client) newClientToken(ctx context.Context, token string) *github.Clien
 	// Get the oauth2 transport to set custom base
 	tp, _ := tc.Transport.(*oauth2.Transport)
 
-	baseTransport := &http.Transport{
-		Proxy: http.ProxyFromEnvironment,
-	}
-	if c.SkipVerify {
-		baseTransport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: true,
-		}
-	}
-
 	// Wrap the base transport with User-Agent support
-	tp.Base = httputil.NewUserAgentRoundTripper(baseTransport, "forge-github")
+	tp.Base = httputil.NewUserAgentRoundTripper(common.NewTransport(c.proxy, c.SkipVerify), "forge-github")
 
 	client := github.NewClient(tc)
 	client.BaseURL, _ = url.Parse(c.API)
@@ -553,7 +560,7 @@ func (c *client) Status(ctx context.Context, user *model.User, repo *model.Repo,
 
 	_, _, err := client.Repositories.CreateStatus(ctx, repo.Owner, repo.Name, pipeline.Commit, &github.RepoStatus{
 		Context:     github.Ptr(common.GetPipelineStatusContext(repo, pipeline, workflow)),
-		State:       github.Ptr(convertStatus(workflow.State)),
+		State:       github.Ptr(convertStatus(common.GetWorkflowStatus(repo, workflow))),
 		Description: github.Ptr(common.GetPipelineStatusDescription(workflow.State)),
 		TargetURL:   github.Ptr(common.GetPipelineStatusURL(repo, pipeline, workflow)),
 	})
@@ -17,7 +17,6 @@ package gitlab
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"io"
@@ -47,11 +46,12 @@ const (
 
 // Opts defines configuration options.
 type Opts struct {
-	URL               string // Gitlab server url.
-	OAuthClientID     string // Oauth2 client id.
-	OAuthClientSecret string // Oauth2 client secret.
-	SkipVerify        bool   // Skip ssl verification.
-	OAuthHost         string // Public url for oauth if different from url.
+	URL               string           // Gitlab server url.
+	OAuthClientID     string           // Oauth2 client id.
+	OAuthClientSecret string           // Oauth2 client secret.
+	SkipVerify        bool             // Skip ssl verification.
+	OAuthHost         string           // Public url for oauth if different from url.
+	Proxy             common.ProxyFunc // Proxy for requests to the forge, taken from the environment if nil.
 }
 
 // Gitlab implements "Forge" interface.
@@ -60,6 +60,7 @@ type GitLab struct {
 	oAuthClientID     string
 	oAuthClientSecret string
 	skipVerify        bool
+	proxy             common.ProxyFunc
 	hideArchives      bool
 	search            bool
 	oAuthHost         string
@@ -74,6 +75,7 @@ func New(opts Opts) (forge.Forge, error) {
 		oAuthClientSecret: opts.OAuthClientSecret,
 		oAuthHost:         opts.OAuthHost,
 		skipVerify:        opts.SkipVerify,
+		proxy:             opts.Proxy,
 		hideArchives:      true,
 	}, nil
 }
@@ -88,6 +90,11 @@ func (g *GitLab) URL() string {
 	return g.url
 }
 
+// Healthy checks if the GitLab API can be reached.
+func (g *GitLab) Healthy(ctx context.Context) error {
+	return common.CheckAPI(ctx, g.url+"/api/v4/version", g.skipVerify, g.proxy, "forge-gitlab")
+}
+
 func (g *GitLab) oauth2Config(ctx context.Context) (*oauth2.Config, context.Context) {
 	publicOAuthURL := g.oAuthHost
 	if publicOAuthURL == "" {
@@ -105,10 +112,7 @@ func (g *GitLab) oauth2Config(ctx context.Context) (*oauth2.Config, context.Cont
 			RedirectURL: fmt.Sprintf("%s/authorize", server.Config.Server.OAuthHost),
 		},
 
-		context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: g.skipVerify},
-			Proxy:           http.ProxyFromEnvironment,
-		}})
+		context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: common.NewTransport(g.proxy, g.skipVerify)})
 }
 
 // Login authenticates the session and returns the
@@ -127,7 +131,7 @@ func (g *GitLab) Login(ctx context.Context, req *forge_types.OAuthRequest) (*mod
 		return nil, redirectURL, fmt.Errorf("error exchanging token: %w", err)
 	}
 
-	client, err := newClient(g.url, token.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, token.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, redirectURL, err
 	}
@@ -178,7 +182,7 @@ func (g *GitLab) Refresh(ctx context.Context, user *model.User) (bool, error) {
 
 // Auth authenticates the session and returns the forge user login for the given token.
 func (g *GitLab) Auth(ctx context.Context, token, _ string) (string, error) {
-	client, err := newClient(g.url, token, g.skipVerify)
+	client, err := newClient(g.url, token, g.skipVerify, g.proxy)
 	if err != nil {
 		return "", err
 	}
@@ -192,7 +196,7 @@ func (g *GitLab) Auth(ctx context.Context, token, _ string) (string, error) {
 
 // Teams fetches a list of team memberships from the forge.
 func (g *GitLab) Teams(ctx context.Context, user *model.User, p *model.ListOptions) ([]*model.Team, error) {
-	client, err := newClient(g.url, user.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, user.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -262,7 +266,7 @@ func (g *GitLab) getInheritedProjectMember(ctx context.Context, client *gitlab.C
 
 // Repo fetches the repository from the forge.
 func (g *GitLab) Repo(ctx context.Context, user *model.User, remoteID model.ForgeRemoteID, owner, name string) (*model.Repo, error) {
-	client, err := newClient(g.url, user.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, user.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -287,7 +291,7 @@ func (g *GitLab) Repo(ctx context.Context, user *model.User, remoteID model.Forg
 
 // Repos fetches a list of repos from the forge.
 func (g *GitLab) Repos(ctx context.Context, user *model.User, p *model.ListOptions) ([]*model.Repo, error) {
-	client, err := newClient(g.url, user.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, user.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -338,7 +342,7 @@ func (g *GitLab) Repos(ctx context.Context, user *model.User, p *model.ListOptio
 
 func (g *GitLab) PullRequests(ctx context.Context, u *model.User, r *model.Repo, p *model.ListOptions) ([]*model.PullRequest, error) {
 	token := common.UserToken(ctx, r, u)
-	client, err := newClient(g.url, token, g.skipVerify)
+	client, err := newClient(g.url, token, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -369,7 +373,7 @@ func (g *GitLab) PullRequests(ctx context.Context, u *model.User, r *model.Repo,
 
 // File fetches a file from the forge repository and returns in string format.
 func (g *GitLab) File(ctx context.Context, user *model.User, repo *model.Repo, pipeline *model.Pipeline, fileName string) ([]byte, error) {
-	client, err := newClient(g.url, user.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, user.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -386,7 +390,7 @@ func (g *GitLab) File(ctx context.Context, user *model.User, repo *model.Repo, p
 
 // Dir fetches a folder from the forge repository.
 func (g *GitLab) Dir(ctx context.Context, user *model.User, repo *model.Repo, pipeline *model.Pipeline, path string) ([]*forge_types.FileMeta, error) {
-	client, err := newClient(g.url, user.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, user.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -438,7 +442,7 @@ func (g *GitLab) Dir(ctx context.Context, user *model.User, repo *model.Repo, pi
 
 // Status sends the commit status back to gitlab.
 func (g *GitLab) Status(ctx context.Context, user *model.User, repo *model.Repo, pipeline *model.Pipeline, workflow *model.Workflow) error {
-	client, err := newClient(g.url, user.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, user.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return err
 	}
@@ -449,7 +453,7 @@ func (g *GitLab) Status(ctx context.Context, user *model.User, repo *model.Repo,
 	}
 
 	_, _, err = client.Commits.SetCommitStatus(_repo.ID, pipeline.Commit, &gitlab.SetCommitStatusOptions{
-		State:       getStatus(workflow.State),
+		State:       getStatus(common.GetWorkflowStatus(repo, workflow)),
 		Description: gitlab.Ptr(common.GetPipelineStatusDescription(workflow.State)),
 		TargetURL:   gitlab.Ptr(common.GetPipelineStatusURL(repo, pipeline, workflow)),
 		Context:     gitlab.Ptr(common.GetPipelineStatusContext(repo, pipeline, workflow)),
@@ -496,7 +500,7 @@ func (g *GitLab) getTokenAndWebURL(link string) (token, webURL string, err error
 // Activate activates a repository by adding a Post-commit hook and
 // a Public Deploy key, if applicable.
 func (g *GitLab) Activate(ctx context.Context, user *model.User, repo *model.Repo, link string) error {
-	client, err := newClient(g.url, user.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, user.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return err
 	}
@@ -531,7 +535,7 @@ func (g *GitLab) Activate(ctx context.Context, user *model.User, repo *model.Rep
 // Deactivate removes a repository by removing all the post-commit hooks
 // which are equal to link and removing the SSH deploy key.
 func (g *GitLab) Deactivate(ctx context.Context, user *model.User, repo *model.Repo, link string) error {
-	client, err := newClient(g.url, user.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, user.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return err
 	}
@@ -580,7 +584,7 @@ func (g *GitLab) Deactivate(ctx context.Context, user *model.User, repo *model.R
 // Branches returns the names of all branches for the named repository.
 func (g *GitLab) Branches(ctx context.Context, user *model.User, repo *model.Repo, p *model.ListOptions) ([]string, error) {
 	token := common.UserToken(ctx, repo, user)
-	client, err := newClient(g.url, token, g.skipVerify)
+	client, err := newClient(g.url, token, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -607,7 +611,7 @@ func (g *GitLab) Branches(ctx context.Context, user *model.User, repo *model.Rep
 // BranchHead returns the sha of the head (latest commit) of the specified branch.
 func (g *GitLab) BranchHead(ctx context.Context, u *model.User, r *model.Repo, branch string) (*model.Commit, error) {
 	token := common.UserToken(ctx, r, u)
-	client, err := newClient(g.url, token, g.skipVerify)
+	client, err := newClient(g.url, token, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -672,7 +676,7 @@ func (g *GitLab) Hook(ctx context.Context, req *http.Request) (*model.Repo, *mod
 // OrgMembership returns if user is member of organization and if user
 // is admin/owner in this organization.
 func (g *GitLab) OrgMembership(ctx context.Context, u *model.User, owner string) (*model.OrgPerm, error) {
-	client, err := newClient(g.url, u.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, u.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -726,7 +730,7 @@ func (g *GitLab) OrgMembership(ctx context.Context, u *model.User, owner string)
 }
 
 func (g *GitLab) Org(ctx context.Context, u *model.User, owner string) (*model.Org, error) {
-	client, err := newClient(g.url, u.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, u.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
@@ -792,7 +796,7 @@ func (g *GitLab) loadMetadataFromMergeRequest(ctx context.Context, tmpRepo *mode
 		return nil, err
 	}
 
-	client, err := newClient(g.url, user.AccessToken, g.skipVerify)
+	client, err := newClient(g.url, user.AccessToken, g.skipVerify, g.proxy)
 	if err != nil {
 		return nil, err
 	}
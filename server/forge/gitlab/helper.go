@@ -16,12 +16,12 @@
 package gitlab
 
 import (
-	"crypto/tls"
 	"net/http"
 
 	gitlab "gitlab.com/gitlab-org/api/client-go"
 	"golang.org/x/oauth2"
 
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge/common"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
 )
 
@@ -31,16 +31,11 @@ const (
 
 // newClient is a helper function that returns a new GitHub
 // client using the provided OAuth token.
-func newClient(url, accessToken string, skipVerify bool) (*gitlab.Client, error) {
+func newClient(url, accessToken string, skipVerify bool, proxy common.ProxyFunc) (*gitlab.Client, error) {
 	return gitlab.NewAuthSourceClient(gitlab.OAuthTokenSource{
 		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: accessToken}),
 	}, gitlab.WithBaseURL(url), gitlab.WithHTTPClient(&http.Client{
-		Transport: httputil.NewUserAgentRoundTripper(
-			&http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: skipVerify},
-				Proxy:           http.ProxyFromEnvironment,
-			},
-			"forge-gitlab"),
+		Transport: httputil.NewUserAgentRoundTripper(common.NewTransport(proxy, skipVerify), "forge-gitlab"),
 	}))
 }
 
@@ -0,0 +1,33 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import "context"
+
+// HealthChecker is an optional interface to check if the forge can be reached.
+//
+// Implementations: GitHub, Gitea, Forgejo, GitLab, Bitbucket, Bitbucket Datacenter.
+type HealthChecker interface {
+	// Healthy does a cheap request to the forge API and returns an error if it failed.
+	Healthy(ctx context.Context) error
+}
+
+// Healthy checks the forge if it implements HealthChecker, other forges are always considered healthy.
+func Healthy(ctx context.Context, forge Forge) error {
+	if checker, ok := forge.(HealthChecker); ok {
+		return checker.Healthy(ctx)
+	}
+	return nil
+}
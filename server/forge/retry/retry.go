@@ -0,0 +1,261 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package retry provides a forge decorator which retries forge API calls
+// failing with transient errors, like rate limits or server errors.
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v76/github"
+	"github.com/rs/zerolog/log"
+	gitlab "gitlab.com/gitlab-org/api/client-go"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge/types"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+// maxRetryAfter is the longest wait requested by the forge which is honored,
+// calls asked to wait longer fail immediately.
+const maxRetryAfter = time.Minute
+
+// Config configures the retries.
+type Config struct {
+	// Max is the maximum number of retries of a call, zero disables retries.
+	Max uint
+	// Backoff is the wait before the first retry, it is doubled for every further retry.
+	Backoff time.Duration
+}
+
+type retryForge struct {
+	forge.Forge
+	config Config
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// New returns a forge which retries the calls of the given forge failing with transient errors.
+// Calls which can not safely be repeated, like Login, Hook and Activate, are not retried.
+// The returned forge only implements forge.TeamMembershipChecker if the given forge does.
+func New(f forge.Forge, config Config) forge.Forge {
+	retrying := &retryForge{Forge: f, config: config, sleep: sleep}
+	if checker, ok := f.(forge.TeamMembershipChecker); ok {
+		return &teamRetryForge{retryForge: retrying, checker: checker}
+	}
+	return retrying
+}
+
+// teamRetryForge wraps forges implementing forge.TeamMembershipChecker.
+type teamRetryForge struct {
+	*retryForge
+	checker forge.TeamMembershipChecker
+}
+
+func (f *teamRetryForge) TeamMembership(ctx context.Context, u *model.User, org, team string) (bool, error) {
+	return do(ctx, f.retryForge, "team membership", func() (bool, error) {
+		return f.checker.TeamMembership(ctx, u, org, team)
+	})
+}
+
+func (f *retryForge) Auth(ctx context.Context, token, secret string) (string, error) {
+	return do(ctx, f, "auth", func() (string, error) {
+		return f.Forge.Auth(ctx, token, secret)
+	})
+}
+
+func (f *retryForge) Teams(ctx context.Context, u *model.User, p *model.ListOptions) ([]*model.Team, error) {
+	return do(ctx, f, "teams", func() ([]*model.Team, error) {
+		return f.Forge.Teams(ctx, u, p)
+	})
+}
+
+func (f *retryForge) Repo(ctx context.Context, u *model.User, remoteID model.ForgeRemoteID, owner, name string) (*model.Repo, error) {
+	return do(ctx, f, "repo", func() (*model.Repo, error) {
+		return f.Forge.Repo(ctx, u, remoteID, owner, name)
+	})
+}
+
+func (f *retryForge) Repos(ctx context.Context, u *model.User, p *model.ListOptions) ([]*model.Repo, error) {
+	return do(ctx, f, "repos", func() ([]*model.Repo, error) {
+		return f.Forge.Repos(ctx, u, p)
+	})
+}
+
+func (f *retryForge) File(ctx context.Context, u *model.User, r *model.Repo, b *model.Pipeline, fileName string) ([]byte, error) {
+	return do(ctx, f, "file", func() ([]byte, error) {
+		return f.Forge.File(ctx, u, r, b, fileName)
+	})
+}
+
+func (f *retryForge) Dir(ctx context.Context, u *model.User, r *model.Repo, b *model.Pipeline, dirName string) ([]*types.FileMeta, error) {
+	return do(ctx, f, "dir", func() ([]*types.FileMeta, error) {
+		return f.Forge.Dir(ctx, u, r, b, dirName)
+	})
+}
+
+func (f *retryForge) Status(ctx context.Context, u *model.User, r *model.Repo, b *model.Pipeline, p *model.Workflow) error {
+	_, err := do(ctx, f, "status", func() (struct{}, error) {
+		return struct{}{}, f.Forge.Status(ctx, u, r, b, p)
+	})
+	return err
+}
+
+func (f *retryForge) Deactivate(ctx context.Context, u *model.User, r *model.Repo, link string) error {
+	_, err := do(ctx, f, "deactivate", func() (struct{}, error) {
+		return struct{}{}, f.Forge.Deactivate(ctx, u, r, link)
+	})
+	return err
+}
+
+func (f *retryForge) Branches(ctx context.Context, u *model.User, r *model.Repo, p *model.ListOptions) ([]string, error) {
+	return do(ctx, f, "branches", func() ([]string, error) {
+		return f.Forge.Branches(ctx, u, r, p)
+	})
+}
+
+func (f *retryForge) BranchHead(ctx context.Context, u *model.User, r *model.Repo, branch string) (*model.Commit, error) {
+	return do(ctx, f, "branch head", func() (*model.Commit, error) {
+		return f.Forge.BranchHead(ctx, u, r, branch)
+	})
+}
+
+func (f *retryForge) PullRequests(ctx context.Context, u *model.User, r *model.Repo, p *model.ListOptions) ([]*model.PullRequest, error) {
+	return do(ctx, f, "pull requests", func() ([]*model.PullRequest, error) {
+		return f.Forge.PullRequests(ctx, u, r, p)
+	})
+}
+
+func (f *retryForge) OrgMembership(ctx context.Context, u *model.User, org string) (*model.OrgPerm, error) {
+	return do(ctx, f, "org membership", func() (*model.OrgPerm, error) {
+		return f.Forge.OrgMembership(ctx, u, org)
+	})
+}
+
+func (f *retryForge) Org(ctx context.Context, u *model.User, org string) (*model.Org, error) {
+	return do(ctx, f, "org", func() (*model.Org, error) {
+		return f.Forge.Org(ctx, u, org)
+	})
+}
+
+// Refresh implements forge.Refresher, tokens of forges without refresh support are never updated.
+func (f *retryForge) Refresh(ctx context.Context, u *model.User) (bool, error) {
+	if refresher, ok := f.Forge.(forge.Refresher); ok {
+		return refresher.Refresh(ctx, u)
+	}
+	return false, nil
+}
+
+// Healthy implements forge.HealthChecker, it is not retried to report the current state.
+func (f *retryForge) Healthy(ctx context.Context) error {
+	return forge.Healthy(ctx, f.Forge)
+}
+
+// do calls fn until it succeeds, fails with an error which is not transient or the retries are exhausted.
+func do[T any](ctx context.Context, f *retryForge, call string, fn func() (T, error)) (T, error) {
+	backoff := f.config.Backoff
+	for attempt := uint(0); ; attempt++ {
+		result, err := fn()
+		if err == nil || attempt >= f.config.Max {
+			return result, err
+		}
+
+		wait, retry := retryAfter(err)
+		if !retry {
+			return result, err
+		}
+		if wait == 0 {
+			wait = backoff
+		}
+		backoff *= 2
+
+		log.Debug().Err(err).Msgf("forge %s call failed, retry %d/%d in %s", call, attempt+1, f.config.Max, wait)
+		if err := f.sleep(ctx, wait); err != nil {
+			return result, err
+		}
+	}
+}
+
+// retryAfter returns whether the error is transient and the wait the forge asked for, if any.
+// Only the errors of the GitHub and GitLab clients carry the response status,
+// errors of all other forges are never considered transient.
+func retryAfter(err error) (time.Duration, bool) {
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		return limitWait(abuseErr.GetRetryAfter())
+	}
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return limitWait(time.Until(rateErr.Rate.Reset.Time))
+	}
+
+	var resp *http.Response
+	var githubErr *github.ErrorResponse
+	var gitlabErr *gitlab.ErrorResponse
+	switch {
+	case errors.As(err, &githubErr):
+		resp = githubErr.Response
+	case errors.As(err, &gitlabErr):
+		resp = gitlabErr.Response
+	}
+	if resp == nil {
+		return 0, false
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode == http.StatusServiceUnavailable:
+		return limitWait(parseRetryAfter(resp.Header.Get("Retry-After")))
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return 0, true
+	default:
+		return 0, false
+	}
+}
+
+// limitWait returns the wait if it is not too long to retry.
+func limitWait(wait time.Duration) (time.Duration, bool) {
+	if wait > maxRetryAfter {
+		return 0, false
+	}
+	return max(wait, 0), true
+}
+
+// parseRetryAfter parses the Retry-After header, which is either in seconds or a http date.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+	return 0
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
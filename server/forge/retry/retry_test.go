@@ -0,0 +1,128 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v76/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+func responseError(status int, header http.Header) error {
+	return &github.ErrorResponse{Response: &http.Response{StatusCode: status, Header: header}}
+}
+
+func newTestForge(f forge.Forge, config Config) (*retryForge, *[]time.Duration) {
+	var waits []time.Duration
+	retrying := New(f, config).(*retryForge)
+	retrying.sleep = func(_ context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	return retrying, &waits
+}
+
+func TestRetryStatus(t *testing.T) {
+	mockForge := mocks.NewMockForge(t)
+	mockForge.On("Status", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(responseError(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"7"}})).Once()
+	mockForge.On("Status", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+		Return(nil).Once()
+
+	f, waits := newTestForge(mockForge, Config{Max: 3, Backoff: time.Second})
+	assert.NoError(t, f.Status(t.Context(), &model.User{}, &model.Repo{}, &model.Pipeline{}, &model.Workflow{}))
+	assert.Equal(t, []time.Duration{7 * time.Second}, *waits, "the Retry-After header must be honored")
+}
+
+func TestRetryBackoff(t *testing.T) {
+	mockForge := mocks.NewMockForge(t)
+	mockForge.On("Repo", mock.Anything, mock.Anything, mock.Anything, "owner", "name").
+		Return(nil, responseError(http.StatusBadGateway, nil)).Times(3)
+
+	f, waits := newTestForge(mockForge, Config{Max: 2, Backoff: time.Second})
+	_, err := f.Repo(t.Context(), &model.User{}, "", "owner", "name")
+	assert.Error(t, err)
+	assert.Equal(t, []time.Duration{time.Second, 2 * time.Second}, *waits)
+}
+
+func TestRetryNotTransient(t *testing.T) {
+	for _, err := range []error{
+		responseError(http.StatusNotFound, nil),
+		errors.New("connection refused"),
+		responseError(http.StatusTooManyRequests, http.Header{"Retry-After": []string{"3600"}}),
+	} {
+		mockForge := mocks.NewMockForge(t)
+		mockForge.On("BranchHead", mock.Anything, mock.Anything, mock.Anything, "main").Return(nil, err).Once()
+
+		f, waits := newTestForge(mockForge, Config{Max: 3, Backoff: time.Second})
+		_, got := f.BranchHead(t.Context(), &model.User{}, &model.Repo{}, "main")
+		assert.ErrorIs(t, got, err)
+		assert.Empty(t, *waits)
+	}
+}
+
+func TestRetryContextCanceled(t *testing.T) {
+	mockForge := mocks.NewMockForge(t)
+	mockForge.On("Org", mock.Anything, mock.Anything, "org").Return(nil, responseError(http.StatusServiceUnavailable, nil)).Once()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err := New(mockForge, Config{Max: 3, Backoff: time.Minute}).Org(ctx, &model.User{}, "org")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// teamForge is a forge with teams.
+type teamForge struct {
+	*mocks.MockForge
+	calls int
+}
+
+func (f *teamForge) TeamMembership(_ context.Context, _ *model.User, org, team string) (bool, error) {
+	f.calls++
+	if f.calls == 1 {
+		return false, responseError(http.StatusBadGateway, nil)
+	}
+	return org == "org" && team == "team", nil
+}
+
+func TestRetryTeamMembership(t *testing.T) {
+	_, ok := New(mocks.NewMockForge(t), Config{}).(forge.TeamMembershipChecker)
+	assert.False(t, ok, "forges without teams must not get a team membership check")
+
+	f := &teamForge{MockForge: mocks.NewMockForge(t)}
+	retrying := New(f, Config{Max: 1, Backoff: time.Nanosecond})
+	checker, ok := retrying.(forge.TeamMembershipChecker)
+	assert.True(t, ok)
+	member, err := checker.TeamMembership(t.Context(), &model.User{}, "org", "team")
+	assert.NoError(t, err)
+	assert.True(t, member)
+	assert.Equal(t, 2, f.calls)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), parseRetryAfter(""))
+	assert.Equal(t, 5*time.Second, parseRetryAfter("5"))
+	assert.InDelta(t, 30*time.Second, parseRetryAfter(time.Now().Add(30*time.Second).UTC().Format(http.TimeFormat)), float64(2*time.Second))
+}
@@ -11,6 +11,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge/addon"
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge/bitbucket"
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge/bitbucketdatacenter"
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge/common"
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge/forgejo"
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge/gitea"
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge/github"
@@ -58,6 +59,10 @@ func setupGitea(forge *model.Forge) (forge.Forge, error) {
 	if err != nil {
 		return nil, err
 	}
+	proxy, err := forgeProxy(forge)
+	if err != nil {
+		return nil, err
+	}
 
 	opts := gitea.Opts{
 		URL:               strings.TrimRight(serverURL.String(), "/"),
@@ -65,6 +70,7 @@ func setupGitea(forge *model.Forge) (forge.Forge, error) {
 		OAuthClientSecret: forge.OAuthClientSecret,
 		SkipVerify:        forge.SkipVerify,
 		OAuthHost:         forge.OAuthHost,
+		Proxy:             proxy,
 	}
 	if len(opts.URL) == 0 {
 		return nil, fmt.Errorf("WOODPECKER_GITEA_URL must be set")
@@ -73,6 +79,7 @@ func setupGitea(forge *model.Forge) (forge.Forge, error) {
 		Str("url", opts.URL).
 		Str("oauth-host", opts.OAuthHost).
 		Bool("skip-verify", opts.SkipVerify).
+		Bool("proxy-set", opts.Proxy != nil).
 		Bool("oauth-client-id-set", opts.OAuthClientID != "").
 		Bool("oauth-secret-id-set", opts.OAuthClientSecret != "").
 		Str("type", string(forge.Type)).
@@ -85,6 +92,10 @@ func setupForgejo(forge *model.Forge) (forge.Forge, error) {
 	if err != nil {
 		return nil, err
 	}
+	proxy, err := forgeProxy(forge)
+	if err != nil {
+		return nil, err
+	}
 
 	opts := forgejo.Opts{
 		URL:               strings.TrimRight(server.String(), "/"),
@@ -92,6 +103,7 @@ func setupForgejo(forge *model.Forge) (forge.Forge, error) {
 		OAuthClientSecret: forge.OAuthClientSecret,
 		SkipVerify:        forge.SkipVerify,
 		OAuth2URL:         forge.OAuthHost,
+		Proxy:             proxy,
 	}
 	if len(opts.URL) == 0 {
 		return nil, fmt.Errorf("WOODPECKER_FORGEJO_URL must be set")
@@ -100,6 +112,7 @@ func setupForgejo(forge *model.Forge) (forge.Forge, error) {
 		Str("url", opts.URL).
 		Str("oauth2-url", opts.OAuth2URL).
 		Bool("skip-verify", opts.SkipVerify).
+		Bool("proxy-set", opts.Proxy != nil).
 		Bool("oauth-client-id-set", opts.OAuthClientID != "").
 		Bool("oauth-client-secret-set", opts.OAuthClientSecret != "").
 		Str("type", string(forge.Type)).
@@ -108,17 +121,24 @@ func setupForgejo(forge *model.Forge) (forge.Forge, error) {
 }
 
 func setupGitLab(forge *model.Forge) (forge.Forge, error) {
+	proxy, err := forgeProxy(forge)
+	if err != nil {
+		return nil, err
+	}
+
 	opts := gitlab.Opts{
 		URL:               forge.URL,
 		OAuthClientID:     forge.OAuthClientID,
 		OAuthClientSecret: forge.OAuthClientSecret,
 		SkipVerify:        forge.SkipVerify,
 		OAuthHost:         forge.OAuthHost,
+		Proxy:             proxy,
 	}
 	log.Debug().
 		Str("url", opts.URL).
 		Str("oauth-host", opts.OAuthHost).
 		Bool("skip-verify", opts.SkipVerify).
+		Bool("proxy-set", opts.Proxy != nil).
 		Bool("oauth-client-id-set", opts.OAuthClientID != "").
 		Bool("oauth-client-secret-set", opts.OAuthClientSecret != "").
 		Str("type", string(forge.Type)).
@@ -130,6 +150,10 @@ func setupGitHub(forge *model.Forge) (forge.Forge, error) {
 	// get additional config and be false by default
 	mergeRef, _ := forge.AdditionalOptions["merge-ref"].(bool)
 	publicOnly, _ := forge.AdditionalOptions["public-only"].(bool)
+	proxy, err := forgeProxy(forge)
+	if err != nil {
+		return nil, err
+	}
 
 	opts := github.Opts{
 		URL:               forge.URL,
@@ -139,6 +163,7 @@ func setupGitHub(forge *model.Forge) (forge.Forge, error) {
 		MergeRef:          mergeRef,
 		OnlyPublic:        publicOnly,
 		OAuthHost:         forge.OAuthHost,
+		Proxy:             proxy,
 	}
 	log.Debug().
 		Str("url", opts.URL).
@@ -146,6 +171,7 @@ func setupGitHub(forge *model.Forge) (forge.Forge, error) {
 		Bool("merge-ref", opts.MergeRef).
 		Bool("only-public", opts.OnlyPublic).
 		Bool("skip-verify", opts.SkipVerify).
+		Bool("proxy-set", opts.Proxy != nil).
 		Bool("oauth-client-id-set", opts.OAuthClientID != "").
 		Bool("oauth-client-secret-set", opts.OAuthClientSecret != "").
 		Str("type", string(forge.Type)).
@@ -197,3 +223,11 @@ func setupAddon(forge *model.Forge) (forge.Forge, error) {
 	log.Debug().Str("executable", executable).Msg("setting up forge")
 	return addon.Load(executable)
 }
+
+// forgeProxy returns the proxy configured for the forge, it is independent of the proxy
+// passed to pipeline steps and nil if the proxy from the environment should be used.
+func forgeProxy(forge *model.Forge) (common.ProxyFunc, error) {
+	proxy, _ := forge.AdditionalOptions["proxy"].(string)
+	noProxy, _ := forge.AdditionalOptions["no-proxy"].(string)
+	return common.NewProxyFunc(proxy, noProxy)
+}
@@ -0,0 +1,31 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package forge
+
+import (
+	"context"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+// TeamMembershipChecker is an optional interface for forges with teams inside of organizations.
+//
+// Forges without it, like GitLab, resolve a team as nested organization using OrgMembership.
+//
+// Implementations: GitHub, Gitea, Forgejo.
+type TeamMembershipChecker interface {
+	// TeamMembership returns if the user is a member of the team of the organization.
+	TeamMembership(ctx context.Context, u *model.User, org, team string) (bool, error)
+}
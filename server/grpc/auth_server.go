@@ -16,6 +16,7 @@ package grpc
 
 import (
 	"context"
+	"crypto/subtle"
 	"errors"
 	"fmt"
 
@@ -23,6 +24,8 @@ import (
 
 	"go.woodpecker-ci.org/woodpecker/v3/pipeline/rpc/proto"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/agenttoken"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/tokenhash"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
 )
@@ -31,11 +34,12 @@ type WoodpeckerAuthServer struct {
 	proto.UnimplementedWoodpeckerAuthServer
 	jwtManager       *JWTManager
 	agentMasterToken string
+	hashAlgorithm    string
 	store            store.Store
 }
 
-func NewWoodpeckerAuthServer(jwtManager *JWTManager, agentMasterToken string, store store.Store) *WoodpeckerAuthServer {
-	return &WoodpeckerAuthServer{jwtManager: jwtManager, agentMasterToken: agentMasterToken, store: store}
+func NewWoodpeckerAuthServer(jwtManager *JWTManager, agentMasterToken, hashAlgorithm string, store store.Store) *WoodpeckerAuthServer {
+	return &WoodpeckerAuthServer{jwtManager: jwtManager, agentMasterToken: agentMasterToken, hashAlgorithm: hashAlgorithm, store: store}
 }
 
 func (s *WoodpeckerAuthServer) Auth(_ context.Context, req *proto.AuthRequest) (*proto.AuthResponse, error) {
@@ -58,35 +62,96 @@ func (s *WoodpeckerAuthServer) Auth(_ context.Context, req *proto.AuthRequest) (
 
 func (s *WoodpeckerAuthServer) getAgent(agentID int64, agentToken string) (*model.Agent, error) {
 	// global agent secret auth
-	if s.agentMasterToken != "" {
-		if agentToken == s.agentMasterToken && agentID == -1 {
-			agent := &model.Agent{
-				OwnerID:  model.IDNotSet,
-				OrgID:    model.IDNotSet,
-				Token:    s.agentMasterToken,
-				Capacity: -1,
-			}
-			err := s.store.AgentCreate(agent)
-			if err != nil {
-				log.Error().Err(err).Msg("error creating system agent")
+	sharedHash, err := s.sharedTokenHash(agentToken)
+	if err != nil {
+		return nil, err
+	}
+	if sharedHash != "" {
+		if agentID == -1 {
+			return s.createSystemAgent(sharedHash)
+		}
+
+		agent, err := s.store.AgentFind(agentID)
+		if err != nil && errors.Is(err, types.RecordNotExist) {
+			// the agent was removed, e.g. by the agent reaper after it did not report for too long
+			log.Info().Int64("agent", agentID).Msg("agent not found in database, registering it again")
+			return s.createSystemAgent(sharedHash)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// remember the token the agent uses, so retired tokens can be checked for usage
+		if agent.IsSystemAgent() && agent.Token != sharedHash {
+			agent.Token = sharedHash
+			if err := s.store.AgentUpdate(agent); err != nil {
 				return nil, err
 			}
-			return agent, nil
 		}
+		return agent, nil
+	}
 
-		if agentToken == s.agentMasterToken {
-			agent, err := s.store.AgentFind(agentID)
-			if err != nil && errors.Is(err, types.RecordNotExist) {
-				return nil, fmt.Errorf("AgentID not found in database")
+	// individual agent token auth
+	return s.findAgentByToken(agentToken)
+}
+
+// createSystemAgent creates a new agent authenticated by a shared token.
+func (s *WoodpeckerAuthServer) createSystemAgent(sharedHash string) (*model.Agent, error) {
+	agent := &model.Agent{
+		OwnerID:  model.IDNotSet,
+		OrgID:    model.IDNotSet,
+		Token:    sharedHash,
+		Capacity: -1,
+	}
+	if err := s.store.AgentCreate(agent); err != nil {
+		log.Error().Err(err).Msg("error creating system agent")
+		return nil, err
+	}
+	return agent, nil
+}
+
+// findAgentByToken looks up an individual agent by the hash of its token.
+// Hashes of previous algorithms are replaced by the hash of the configured one.
+func (s *WoodpeckerAuthServer) findAgentByToken(agentToken string) (*model.Agent, error) {
+	err := types.RecordNotExist
+	for _, hash := range tokenhash.Candidates(s.hashAlgorithm, agentToken) {
+		var agent *model.Agent
+		agent, err = s.store.AgentFindByToken(hash)
+		if errors.Is(err, types.RecordNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if agent.IsSystemAgent() {
+			// the token of a system agent is a shared token, which is no longer valid if it got here
+			return nil, errors.New("agent token was retired")
+		}
+
+		if !tokenhash.IsCurrent(s.hashAlgorithm, agent.Token) {
+			if agent.Token, err = tokenhash.Hash(s.hashAlgorithm, agentToken); err != nil {
+				return nil, err
+			}
+			if err := s.store.AgentUpdate(agent); err != nil {
+				return nil, err
 			}
-			return agent, err
 		}
+		return agent, nil
 	}
+	return nil, fmt.Errorf("individual agent not found by token: %w", err)
+}
 
-	// individual agent token auth
-	agent, err := s.store.AgentFindByToken(agentToken)
-	if err != nil && errors.Is(err, types.RecordNotExist) {
-		return nil, fmt.Errorf("individual agent not found by token: %w", err)
+// sharedTokenHash returns the hash to store for agents using the token if it is the agent
+// secret of the server config or one of the agent tokens stored in the database.
+// If the token is not shared an empty string is returned.
+func (s *WoodpeckerAuthServer) sharedTokenHash(agentToken string) (string, error) {
+	if s.agentMasterToken != "" && subtle.ConstantTimeCompare([]byte(agentToken), []byte(s.agentMasterToken)) == 1 {
+		return tokenhash.Hash(s.hashAlgorithm, agentToken)
+	}
+
+	token, err := agenttoken.Find(s.store, agentToken, s.hashAlgorithm)
+	if err != nil || token == nil {
+		return "", err
 	}
-	return agent, err
+	return token.Token, nil
 }
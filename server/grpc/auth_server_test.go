@@ -0,0 +1,149 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/pipeline/rpc/proto"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/agenttoken"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/tokenhash"
+	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+func TestAuthWithRotatedToken(t *testing.T) {
+	config := map[string]string{}
+	store := store_mocks.NewMockStore(t)
+	store.On("ServerConfigGet", mock.Anything).Maybe().Return(func(key string) (string, error) {
+		value, ok := config[key]
+		if !ok {
+			return "", types.RecordNotExist
+		}
+		return value, nil
+	})
+	store.On("ServerConfigSet", mock.Anything, mock.Anything).Maybe().Return(func(key, value string) error {
+		config[key] = value
+		return nil
+	})
+
+	newToken, err := agenttoken.Add(store, time.Now(), tokenhash.DefaultAlgorithm)
+	require.NoError(t, err)
+	newTokenHash, _ := tokenhash.Hash(tokenhash.DefaultAlgorithm, newToken.Token)
+	oldSecretHash, _ := tokenhash.Hash(tokenhash.DefaultAlgorithm, "old-secret")
+
+	authServer := NewWoodpeckerAuthServer(NewJWTManager("secret"), "old-secret", tokenhash.DefaultAlgorithm, store)
+
+	t.Run("current secret", func(t *testing.T) {
+		agent := &model.Agent{ID: 1, OwnerID: model.IDNotSet, Token: oldSecretHash}
+		store.On("AgentFind", int64(1)).Once().Return(agent, nil)
+
+		resp, err := authServer.Auth(t.Context(), &proto.AuthRequest{AgentId: 1, AgentToken: "old-secret"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, resp.AgentId)
+	})
+
+	t.Run("new token", func(t *testing.T) {
+		agent := &model.Agent{ID: 2, OwnerID: model.IDNotSet, Token: oldSecretHash}
+		store.On("AgentFind", int64(2)).Once().Return(agent, nil)
+		store.On("AgentUpdate", mock.MatchedBy(func(a *model.Agent) bool {
+			return a.ID == 2 && a.Token == newTokenHash
+		})).Once().Return(nil)
+
+		resp, err := authServer.Auth(t.Context(), &proto.AuthRequest{AgentId: 2, AgentToken: newToken.Token})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, resp.AgentId)
+	})
+
+	t.Run("new system agent", func(t *testing.T) {
+		store.On("AgentCreate", mock.MatchedBy(func(a *model.Agent) bool {
+			return a.Token == newTokenHash && a.IsSystemAgent()
+		})).Once().Return(nil)
+
+		_, err := authServer.Auth(t.Context(), &proto.AuthRequest{AgentId: -1, AgentToken: newToken.Token})
+		assert.NoError(t, err)
+	})
+
+	t.Run("system agent removed by the reaper", func(t *testing.T) {
+		store.On("AgentFind", int64(3)).Once().Return(nil, types.RecordNotExist)
+		store.On("AgentCreate", mock.MatchedBy(func(a *model.Agent) bool {
+			return a.Token == newTokenHash && a.IsSystemAgent()
+		})).Once().Run(func(args mock.Arguments) {
+			args.Get(0).(*model.Agent).ID = 4
+		}).Return(nil)
+
+		resp, err := authServer.Auth(t.Context(), &proto.AuthRequest{AgentId: 3, AgentToken: newToken.Token})
+		require.NoError(t, err)
+		assert.EqualValues(t, 4, resp.AgentId, "the agent must be registered again")
+	})
+
+	t.Run("retired token", func(t *testing.T) {
+		store.On("AgentFindByToken", newTokenHash).Once().Return(nil, types.RecordNotExist)
+		_, err := agenttoken.Retire(store, newToken.ID, false)
+		require.NoError(t, err)
+
+		store.On("AgentFindByToken", mock.Anything).Times(len(tokenhash.Algorithms())).Return(nil, types.RecordNotExist)
+		_, err = authServer.Auth(t.Context(), &proto.AuthRequest{AgentId: 2, AgentToken: newToken.Token})
+		assert.Error(t, err)
+	})
+
+	t.Run("retired token of a system agent", func(t *testing.T) {
+		// system agents keep the hash of the shared token they used last
+		store.On("AgentFindByToken", newTokenHash).Once().Return(&model.Agent{ID: 2, OwnerID: model.IDNotSet, Token: newTokenHash}, nil)
+
+		_, err := authServer.Auth(t.Context(), &proto.AuthRequest{AgentId: 2, AgentToken: newToken.Token})
+		assert.ErrorContains(t, err, "agent token was retired")
+	})
+}
+
+func TestAuthWithIndividualToken(t *testing.T) {
+	store := store_mocks.NewMockStore(t)
+	store.On("ServerConfigGet", mock.Anything).Maybe().Return("", types.RecordNotExist)
+
+	authServer := NewWoodpeckerAuthServer(NewJWTManager("secret"), "agent-secret", tokenhash.DefaultAlgorithm, store)
+	currentHash, _ := tokenhash.Hash(tokenhash.DefaultAlgorithm, "agent-token")
+	legacyHash, _ := tokenhash.Hash("sha512", "agent-token")
+
+	t.Run("current hash", func(t *testing.T) {
+		store.On("AgentFindByToken", currentHash).Once().Return(&model.Agent{ID: 1, OwnerID: 1, Token: currentHash}, nil)
+
+		resp, err := authServer.Auth(t.Context(), &proto.AuthRequest{AgentId: 1, AgentToken: "agent-token"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 1, resp.AgentId)
+	})
+
+	t.Run("legacy hash is upgraded", func(t *testing.T) {
+		store.On("AgentFindByToken", legacyHash).Once().Return(&model.Agent{ID: 2, OwnerID: 1, Token: legacyHash}, nil)
+		store.On("AgentFindByToken", mock.Anything).Return(nil, types.RecordNotExist)
+		store.On("AgentUpdate", mock.MatchedBy(func(a *model.Agent) bool {
+			return a.ID == 2 && a.Token == currentHash
+		})).Once().Return(nil)
+
+		resp, err := authServer.Auth(t.Context(), &proto.AuthRequest{AgentId: 2, AgentToken: "agent-token"})
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, resp.AgentId)
+	})
+
+	t.Run("hash is no token", func(t *testing.T) {
+		_, err := authServer.Auth(t.Context(), &proto.AuthRequest{AgentId: 1, AgentToken: currentHash})
+		assert.Error(t, err)
+	})
+}
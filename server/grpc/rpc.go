@@ -38,6 +38,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/pubsub"
 	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/constant"
 )
 
 // updateAgentLastWorkDelay the delay before the LastWork info should be updated.
@@ -45,7 +46,7 @@ const updateAgentLastWorkDelay = time.Minute
 
 type RPC struct {
 	queue         queue.Queue
-	pubsub        *pubsub.Publisher
+	pubsub        pubsub.Broker
 	logger        logging.Log
 	store         store.Store
 	pipelineTime  *prometheus.GaugeVec
@@ -78,7 +79,15 @@ func (s *RPC) Next(c context.Context, agentFilter rpc.Filter) (*rpc.Workflow, er
 
 	log.Trace().Msgf("Agent %s[%d] tries to pull task with labels: %v", agent.Name, agent.ID, agentFilter.Labels)
 
-	filterFn := createFilterFunc(agentFilter)
+	filterFn := queue.NewLabelFilter(agentFilter.Labels)
+	if server.Config.Agent.ResourceScheduling {
+		c = queue.WithResources(c, agent.ResourceCapacity, agent.ResourceUsage)
+	}
+
+	// prefetched workflows are only reserved for a short time until the agent starts them
+	if lease := s.getPrefetchLeaseFromContext(c); lease > 0 {
+		c = queue.WithLease(c, lease)
+	}
 
 	for {
 		// poll blocks until a task is available or the context is canceled / worker is kicked
@@ -189,6 +198,9 @@ func (s *RPC) Update(c context.Context, strWorkflowID string, state rpc.StepStat
 
 	if state.Exited {
 		server.Config.Services.LogStore.StepFinished(step)
+		if limiter := server.Config.Services.LogLimiter; limiter != nil {
+			limiter.StepFinished(step)
+		}
 	}
 
 	if currentPipeline.Workflows, err = s.store.WorkflowGetTree(currentPipeline); err != nil {
@@ -233,6 +245,13 @@ func (s *RPC) Init(c context.Context, strWorkflowID string, state rpc.WorkflowSt
 
 	workflow.AgentID = agent.ID
 
+	// turn a possible prefetch lease into the regular deadline, if the lease expired
+	// the workflow is handed out again and the agent has to abandon it
+	if err := s.queue.Extend(c, agent.ID, strWorkflowID); err != nil {
+		log.Warn().Err(err).Msgf("could not extend deadline of workflow %s", strWorkflowID)
+		return fmt.Errorf("workflow %s is not assigned to agent %d anymore: %w", strWorkflowID, agent.ID, err)
+	}
+
 	currentPipeline, err := s.store.GetPipeline(workflow.PipelineID)
 	if err != nil {
 		log.Error().Err(err).Msgf("cannot find pipeline with id %d", workflow.PipelineID)
@@ -253,6 +272,8 @@ func (s *RPC) Init(c context.Context, strWorkflowID string, state rpc.WorkflowSt
 	if currentPipeline.Status == model.StatusPending {
 		if currentPipeline, err = pipeline.UpdateToStatusRunning(s.store, *currentPipeline, state.Started); err != nil {
 			log.Error().Err(err).Msgf("init: cannot update pipeline %d state", currentPipeline.ID)
+		} else {
+			server.Config.Services.Manager.NotificationService().Notify(repo, currentPipeline)
 		}
 	}
 
@@ -356,6 +377,8 @@ func (s *RPC) Done(c context.Context, strWorkflowID string, state rpc.WorkflowSt
 	if !model.IsThereRunningStage(currentPipeline.Workflows) {
 		if currentPipeline, err = pipeline.UpdateStatusToDone(s.store, *currentPipeline, model.PipelineStatus(currentPipeline.Workflows), workflow.Finished); err != nil {
 			logger.Error().Err(err).Msgf("pipeline.UpdateStatusToDone: cannot update workflows final state")
+		} else {
+			server.Config.Services.Manager.NotificationService().Notify(repo, currentPipeline)
 		}
 	}
 
@@ -429,6 +452,13 @@ func (s *RPC) Log(c context.Context, stepUUID string, rpcLogEntries []*rpc.LogEn
 		})
 	}
 
+	// truncate the logs before streaming them, so connected clients see the same lines as stored
+	if limiter := server.Config.Services.LogLimiter; limiter != nil {
+		if logEntries = limiter.Apply(step, logEntries); len(logEntries) == 0 {
+			return nil
+		}
+	}
+
 	// make sure writes to pubsub are non blocking (https://github.com/woodpecker-ci/woodpecker/blob/c919f32e0b6432a95e1a6d3d0ad662f591adf73f/server/logging/log.go#L9)
 	go func() {
 		// write line to listening web clients
@@ -461,6 +491,7 @@ func (s *RPC) RegisterAgent(ctx context.Context, info rpc.AgentInfo) (int64, err
 	agent.Capacity = int32(info.Capacity)
 	agent.Version = info.Version
 	agent.CustomLabels = info.CustomLabels
+	agent.ResourceCapacity = model.Resources(info.Resources)
 
 	err = s.store.AgentUpdate(agent)
 	if err != nil {
@@ -487,7 +518,7 @@ func (s *RPC) UnregisterAgent(ctx context.Context) error {
 	return err
 }
 
-func (s *RPC) ReportHealth(ctx context.Context, status string) error {
+func (s *RPC) ReportHealth(ctx context.Context, status string, health rpc.Health) error {
 	agent, err := s.getAgentFromContext(ctx)
 	if err != nil {
 		return err
@@ -499,6 +530,9 @@ func (s *RPC) ReportHealth(ctx context.Context, status string) error {
 	}
 
 	agent.LastContact = time.Now().Unix()
+	agent.Offline = false
+	agent.ResourceCapacity = model.Resources(health.Capacity)
+	agent.ResourceUsage = model.Resources(health.Usage)
 
 	return s.store.AgentUpdate(agent)
 }
@@ -610,7 +644,15 @@ func (s *RPC) getAgentFromContext(ctx context.Context) (*model.Agent, error) {
 		return nil, errors.New("agent_id is not a valid integer")
 	}
 
-	return s.store.AgentFind(agentID)
+	agent, err := s.store.AgentFind(agentID)
+	if err != nil {
+		return nil, err
+	}
+	if agent.IsSystemAgent() && agent.Token == "" {
+		// the shared token of the agent got retired, it has to authenticate with another one
+		return nil, errors.New("agent token was retired")
+	}
+	return agent, nil
 }
 
 func (s *RPC) getHostnameFromContext(ctx context.Context) (string, error) {
@@ -624,6 +666,24 @@ func (s *RPC) getHostnameFromContext(ctx context.Context) (string, error) {
 	return "", errors.New("no hostname in metadata")
 }
 
+// getPrefetchLeaseFromContext returns the lease an agent requested for a prefetched workflow.
+// The lease is capped by the regular task timeout.
+func (s *RPC) getPrefetchLeaseFromContext(ctx context.Context) time.Duration {
+	metadata, ok := grpcMetadata.FromIncomingContext(ctx)
+	if !ok {
+		return 0
+	}
+	values := metadata.Get(constant.PrefetchLeaseMetadataKey)
+	if len(values) == 0 {
+		return 0
+	}
+	lease, err := time.ParseDuration(values[0])
+	if err != nil || lease <= 0 {
+		return 0
+	}
+	return min(lease, constant.TaskTimeout)
+}
+
 func (s *RPC) updateAgentLastWork(agent *model.Agent) error {
 	// only update agent.LastWork if not recently updated
 	if time.Unix(agent.LastWork, 0).Add(updateAgentLastWorkDelay).After(time.Now()) {
@@ -20,11 +20,14 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"google.golang.org/grpc/metadata"
 
 	"go.woodpecker-ci.org/woodpecker/v3/pipeline/rpc"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
 	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/constant"
 )
 
 func TestRegisterAgent(t *testing.T) {
@@ -159,3 +162,64 @@ func TestUpdateAgentLastWork(t *testing.T) {
 		assert.Equal(t, lastWork, agent.LastWork)
 	})
 }
+
+func TestGetPrefetchLeaseFromContext(t *testing.T) {
+	rpc := RPC{}
+
+	t.Run("When no lease was requested it should return zero", func(t *testing.T) {
+		assert.Zero(t, rpc.getPrefetchLeaseFromContext(t.Context()))
+	})
+
+	t.Run("When a lease was requested it should return it", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(constant.PrefetchLeaseMetadataKey, "10s"))
+		assert.Equal(t, 10*time.Second, rpc.getPrefetchLeaseFromContext(ctx))
+	})
+
+	t.Run("When the lease exceeds the task timeout it should be capped", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(constant.PrefetchLeaseMetadataKey, "1h"))
+		assert.Equal(t, constant.TaskTimeout, rpc.getPrefetchLeaseFromContext(ctx))
+	})
+
+	t.Run("When the lease is invalid it should be ignored", func(t *testing.T) {
+		ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs(constant.PrefetchLeaseMetadataKey, "soon"))
+		assert.Zero(t, rpc.getPrefetchLeaseFromContext(ctx))
+	})
+}
+
+func TestGetAgentWithRetiredToken(t *testing.T) {
+	store := store_mocks.NewMockStore(t)
+	store.On("AgentFind", int64(1)).Once().Return(&model.Agent{ID: 1, OwnerID: model.IDNotSet, Token: ""}, nil)
+	grpc := RPC{store: store}
+
+	ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs("agent_id", "1"))
+	_, err := grpc.getAgentFromContext(ctx)
+	assert.EqualError(t, err, "agent token was retired")
+}
+
+func TestInitExpiredLease(t *testing.T) {
+	store := store_mocks.NewMockStore(t)
+	store.On("WorkflowLoad", int64(1)).Return(&model.Workflow{ID: 1, PipelineID: 1}, nil)
+	store.On("AgentFind", int64(1)).Return(&model.Agent{ID: 1, OwnerID: 1}, nil)
+
+	q, err := queue.New(t.Context(), queue.Config{Backend: queue.TypeMemory})
+	require.NoError(t, err)
+	grpc := RPC{store: store, queue: q}
+	ctx := metadata.NewIncomingContext(t.Context(), metadata.Pairs("hostname", "hostname", "agent_id", "1"))
+	filter := func(*model.Task) (bool, int) { return true, 1 }
+
+	// the agent prefetched the workflow but did not start it within the lease
+	require.NoError(t, q.PushAtOnce(t.Context(), []*model.Task{{ID: "1"}}))
+	_, err = q.Poll(queue.WithLease(t.Context(), 50*time.Millisecond), 1, filter)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return len(q.Info(t.Context()).Pending) == 1 }, time.Second, 10*time.Millisecond)
+
+	t.Run("When the workflow was requeued it should be rejected", func(t *testing.T) {
+		assert.ErrorIs(t, grpc.Init(ctx, "1", rpc.WorkflowState{}), queue.ErrNotFound)
+	})
+
+	t.Run("When the workflow was handed out to another agent it should be rejected", func(t *testing.T) {
+		_, err := q.Poll(t.Context(), 2, filter)
+		require.NoError(t, err)
+		assert.ErrorIs(t, grpc.Init(ctx, "1", rpc.WorkflowState{}), queue.ErrAgentMissMatch)
+	})
+}
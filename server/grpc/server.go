@@ -37,7 +37,7 @@ type WoodpeckerServer struct {
 	peer RPC
 }
 
-func NewWoodpeckerServer(queue queue.Queue, logger logging.Log, pubsub *pubsub.Publisher, store store.Store) proto.WoodpeckerServer {
+func NewWoodpeckerServer(queue queue.Queue, logger logging.Log, pubsub pubsub.Broker, store store.Store) proto.WoodpeckerServer {
 	pipelineTime := prometheus_auto.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: "woodpecker",
 		Name:      "pipeline_time",
@@ -179,6 +179,7 @@ func (s *WoodpeckerServer) RegisterAgent(c context.Context, req *proto.RegisterA
 		Backend:      agentInfo.GetBackend(),
 		Capacity:     int(agentInfo.GetCapacity()),
 		CustomLabels: agentInfo.GetCustomLabels(),
+		Resources:    resourcesFromProto(agentInfo.GetResources()),
 	})
 	res.AgentId = agentID
 	return res, err
@@ -191,6 +192,16 @@ func (s *WoodpeckerServer) UnregisterAgent(ctx context.Context, _ *proto.Empty)
 
 func (s *WoodpeckerServer) ReportHealth(c context.Context, req *proto.ReportHealthRequest) (*proto.Empty, error) {
 	res := new(proto.Empty)
-	err := s.peer.ReportHealth(c, req.GetStatus())
+	err := s.peer.ReportHealth(c, req.GetStatus(), rpc.Health{
+		Capacity: resourcesFromProto(req.GetCapacity()),
+		Usage:    resourcesFromProto(req.GetUsage()),
+	})
 	return res, err
 }
+
+func resourcesFromProto(resources *proto.Resources) rpc.Resources {
+	return rpc.Resources{
+		CPU:    resources.GetCpu(),
+		Memory: resources.GetMemory(),
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redis implements a log multiplexer which passes the log entries on to all servers
+// connected to the same redis, so the live logs of a step can be tailed on any server.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/logging"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
+)
+
+// Config is the configuration of the redis log multiplexer.
+type Config struct {
+	redis.Config
+	// Channel is the redis channel the log entries are published to.
+	Channel string
+}
+
+// message is published for each write and close of a log.
+type message struct {
+	Server  string            `json:"server"`
+	StepID  int64             `json:"step_id"`
+	Entries []*model.LogEntry `json:"entries,omitempty"`
+	Closed  bool              `json:"closed,omitempty"`
+}
+
+// logs writes to the local multiplexer and publishes the writes to the other servers, which
+// apply them to the logs opened on their side. Logs which are not open on a server are skipped,
+// the entries written before a log got opened are replayed from the log store.
+type logs struct {
+	logging.Log
+
+	client   *redis.Client
+	channel  string
+	serverID string
+
+	sync.Mutex
+	open map[int64]struct{}
+}
+
+// New returns a log multiplexer which stays subscribed until the context is done.
+func New(ctx context.Context, config Config) (logging.Log, error) {
+	client, err := redis.NewClient(ctx, config.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	l := &logs{
+		Log:      logging.New(),
+		client:   client,
+		channel:  config.Channel,
+		serverID: fmt.Sprintf("%s-%x", hostname, random.GetRandomBytes(4)), //nolint:mnd
+		open:     map[int64]struct{}{},
+	}
+
+	if err := redis.Subscribe(ctx, config.Config, config.Channel, l.receive); err != nil {
+		client.Close()
+		return nil, err
+	}
+	context.AfterFunc(ctx, client.Close)
+
+	return l, nil
+}
+
+func (l *logs) Open(c context.Context, stepID int64) error {
+	l.Lock()
+	defer l.Unlock()
+
+	l.open[stepID] = struct{}{}
+	return l.Log.Open(c, stepID)
+}
+
+func (l *logs) Write(c context.Context, stepID int64, entries []*model.LogEntry) error {
+	l.Lock()
+	// writes open the local log as well
+	l.open[stepID] = struct{}{}
+	err := l.Log.Write(c, stepID, entries)
+	l.Unlock()
+	if err != nil {
+		return err
+	}
+
+	return l.publish(c, &message{StepID: stepID, Entries: entries})
+}
+
+func (l *logs) Close(c context.Context, stepID int64) error {
+	l.Lock()
+	delete(l.open, stepID)
+	closeErr := l.Log.Close(c, stepID)
+	l.Unlock()
+
+	if err := l.publish(c, &message{StepID: stepID, Closed: true}); err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func (l *logs) publish(c context.Context, m *message) error {
+	m.Server = l.serverID
+	data, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	_, err = l.client.Do(c, "PUBLISH", l.channel, string(data))
+	return err
+}
+
+// receive applies the writes of the other servers to the logs open on this server.
+func (l *logs) receive(data string) {
+	var m message
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		log.Error().Err(err).Msg("could not decode log message")
+		return
+	}
+	if m.Server == l.serverID {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	if _, ok := l.open[m.StepID]; !ok {
+		return
+	}
+	ctx := context.Background()
+	if m.Closed {
+		delete(l.open, m.StepID)
+		_ = l.Log.Close(ctx, m.StepID)
+		return
+	}
+	if err := l.Log.Write(ctx, m.StepID, m.Entries); err != nil {
+		log.Error().Err(err).Msgf("could not write logs of step %d", m.StepID)
+	}
+}
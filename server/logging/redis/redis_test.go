@@ -0,0 +1,64 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/logging"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis/redistest"
+)
+
+func TestTailOnOtherServer(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	config := Config{Config: redis.Config{Addr: server.Addr()}, Channel: "logs"}
+
+	writer, err := New(t.Context(), config)
+	require.NoError(t, err)
+	reader, err := New(t.Context(), config)
+	require.NoError(t, err)
+
+	// the step is only tailed on the reader, the writer has the agent connected
+	require.NoError(t, reader.Open(t.Context(), 1))
+	received := make(logging.LogChan, 10)
+	tailed := make(chan error)
+	go func() { tailed <- reader.Tail(t.Context(), 1, received) }()
+
+	first := []*model.LogEntry{{StepID: 1, Line: 0, Data: []byte("first")}}
+	second := []*model.LogEntry{{StepID: 1, Line: 1, Data: []byte("second")}}
+	require.NoError(t, writer.Write(t.Context(), 1, first))
+	require.NoError(t, writer.Write(t.Context(), 1, second))
+	assert.Equal(t, first, <-received)
+	assert.Equal(t, second, <-received)
+
+	// logs which are not open on the reader are skipped
+	require.NoError(t, writer.Write(t.Context(), 2, first))
+
+	// closing the log on the writer ends the tail on the reader
+	require.NoError(t, writer.Close(t.Context(), 1))
+	select {
+	case err := <-tailed:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("tail not ended after close")
+	}
+	assert.ErrorIs(t, reader.Tail(t.Context(), 2, received), logging.ErrNotFound)
+}
@@ -17,6 +17,7 @@ package model
 import (
 	"encoding/base32"
 	"fmt"
+	"maps"
 
 	"github.com/google/tink/go/subtle/random"
 
@@ -24,24 +25,33 @@ import (
 )
 
 type Agent struct {
-	ID           int64             `json:"id"            xorm:"pk autoincr 'id'"`
-	Created      int64             `json:"created"       xorm:"created"`
-	Updated      int64             `json:"updated"       xorm:"updated"`
-	Name         string            `json:"name"          xorm:"name"`
-	OwnerID      int64             `json:"owner_id"      xorm:"'owner_id'"`
-	Token        string            `json:"token"         xorm:"token"`
-	LastContact  int64             `json:"last_contact"  xorm:"last_contact"`
-	LastWork     int64             `json:"last_work"     xorm:"last_work"` // last time the agent did something, this value is used to determine if the agent is still doing work used by the autoscaler
-	Platform     string            `json:"platform"      xorm:"VARCHAR(100) 'platform'"`
-	Backend      string            `json:"backend"       xorm:"VARCHAR(100) 'backend'"`
-	Capacity     int32             `json:"capacity"      xorm:"capacity"`
-	Version      string            `json:"version"       xorm:"'version'"`
-	NoSchedule   bool              `json:"no_schedule"   xorm:"no_schedule"`
-	CustomLabels map[string]string `json:"custom_labels" xorm:"JSON 'custom_labels'"`
+	ID               int64             `json:"id"                xorm:"pk autoincr 'id'"`
+	Created          int64             `json:"created"           xorm:"created"`
+	Updated          int64             `json:"updated"           xorm:"updated"`
+	Name             string            `json:"name"              xorm:"name"`
+	OwnerID          int64             `json:"owner_id"          xorm:"'owner_id'"`
+	Token            string            `json:"token"             xorm:"token"`
+	LastContact      int64             `json:"last_contact"      xorm:"last_contact"`
+	LastWork         int64             `json:"last_work"         xorm:"last_work"` // last time the agent did something, this value is used to determine if the agent is still doing work used by the autoscaler
+	Platform         string            `json:"platform"          xorm:"VARCHAR(100) 'platform'"`
+	Backend          string            `json:"backend"           xorm:"VARCHAR(100) 'backend'"`
+	Capacity         int32             `json:"capacity"          xorm:"capacity"`
+	Version          string            `json:"version"           xorm:"'version'"`
+	NoSchedule       bool              `json:"no_schedule"       xorm:"no_schedule"`
+	Offline          bool              `json:"offline"           xorm:"offline"` // set if the agent did not report its health for longer than the idle timeout
+	CustomLabels     map[string]string `json:"custom_labels"     xorm:"JSON 'custom_labels'"`
+	ResourceCapacity Resources         `json:"resource_capacity" xorm:"JSON 'resource_capacity'"`
+	ResourceUsage    Resources         `json:"resource_usage"    xorm:"JSON 'resource_usage'"`
 	// OrgID is counted as unset if set to -1, this is done to ensure a new(Agent) still enforce the OrgID check by default
-	OrgID int64 `json:"org_id"        xorm:"INDEX 'org_id'"`
+	OrgID int64 `json:"org_id"            xorm:"INDEX 'org_id'"`
 } //	@name	Agent
 
+// Resources defines an amount of cpu in millicores and memory in bytes, zero if unknown or not requested.
+type Resources struct {
+	CPU    int64 `json:"cpu"`
+	Memory int64 `json:"memory"`
+} //	@name	Resources
+
 const (
 	IDNotSet = -1
 )
@@ -72,6 +82,22 @@ func (a *Agent) GetServerLabels() (map[string]string, error) {
 	return filters, nil
 }
 
+// FilterLabels returns the labels the agent selects workflows with, as far as they are known to the server:
+// the default labels of the agent overwritten by its custom labels and the labels enforced by the server.
+func (a *Agent) FilterLabels() map[string]string {
+	labels := map[string]string{
+		pipeline.LabelFilterHostname: a.Name,
+		pipeline.LabelFilterPlatform: a.Platform,
+		pipeline.LabelFilterBackend:  a.Backend,
+		pipeline.LabelFilterRepo:     "*",
+	}
+	maps.Copy(labels, a.CustomLabels)
+
+	serverLabels, _ := a.GetServerLabels()
+	maps.Copy(labels, serverLabels)
+	return labels
+}
+
 func (a *Agent) CanAccessRepo(repo *Repo) bool {
 	// global agent
 	if a.OrgID == IDNotSet {
@@ -0,0 +1,29 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// ServerInfo describes the server, clients use it to check their compatibility.
+type ServerInfo struct {
+	Version  string         `json:"version"`
+	Forge    string         `json:"forge"`
+	Features ServerFeatures `json:"features"`
+} //	@name	ServerInfo
+
+// ServerFeatures are the features enabled on the server.
+type ServerFeatures struct {
+	Swagger          bool   `json:"swagger"`
+	OpenRegistration bool   `json:"open_registration"`
+	LogStore         string `json:"log_store"`
+} //	@name	ServerFeatures
@@ -0,0 +1,27 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// Lease is a named lock held by one server instance until it expires.
+type Lease struct {
+	Name    string `json:"name"    xorm:"pk 'name'"`
+	Holder  string `json:"holder"  xorm:"holder"`
+	Expires int64  `json:"expires" xorm:"expires"`
+}
+
+// TableName return database table name for xorm.
+func (Lease) TableName() string {
+	return "leases"
+}
@@ -21,10 +21,56 @@ type Org struct {
 	Name    string `json:"name"               xorm:"'name' UNIQUE(s)"`
 	IsUser  bool   `json:"is_user"            xorm:"is_user"`
 	// if name lookup has to check for membership or not
-	Private bool `json:"-"                    xorm:"private"`
+	Private      bool            `json:"-"                  xorm:"private"`
+	FeatureFlags OrgFeatureFlags `json:"feature_flags"      xorm:"json 'feature_flags'"`
 } //	@name	Org
 
 // TableName return database table name for xorm.
 func (Org) TableName() string {
 	return "orgs"
 }
+
+// FeatureFlags are the pipeline feature flags which can be overridden per org.
+type FeatureFlags struct {
+	AllowPullRequests    bool
+	PrivilegedPlugins    []string
+	ExposeSecretsToForks bool
+	DefaultTimeout       int64
+	MaxRunningPipelines  int
+}
+
+// OrgFeatureFlags overrides the global pipeline feature flags for all repos of an org.
+// Unset fields keep the global default.
+type OrgFeatureFlags struct {
+	AllowPullRequests    *bool     `json:"allow_pull_requests,omitempty"`
+	PrivilegedPlugins    *[]string `json:"privileged_plugins,omitempty"`
+	ExposeSecretsToForks *bool     `json:"expose_secrets_to_forks,omitempty"`
+	DefaultTimeout       *int64    `json:"default_timeout,omitempty"`
+	MaxRunningPipelines  *int      `json:"max_running_pipelines,omitempty"`
+} //	@name	OrgFeatureFlags
+
+// Apply returns the given defaults with the org overrides applied.
+func (o OrgFeatureFlags) Apply(defaults FeatureFlags) FeatureFlags {
+	flags := defaults
+	if o.AllowPullRequests != nil {
+		flags.AllowPullRequests = *o.AllowPullRequests
+	}
+	if o.PrivilegedPlugins != nil {
+		flags.PrivilegedPlugins = *o.PrivilegedPlugins
+	}
+	if o.ExposeSecretsToForks != nil {
+		flags.ExposeSecretsToForks = *o.ExposeSecretsToForks
+	}
+	if o.DefaultTimeout != nil {
+		flags.DefaultTimeout = *o.DefaultTimeout
+	}
+	if o.MaxRunningPipelines != nil {
+		flags.MaxRunningPipelines = *o.MaxRunningPipelines
+	}
+	return flags
+}
+
+// OrgPatch represents an org patch object.
+type OrgPatch struct {
+	FeatureFlags *OrgFeatureFlags `json:"feature_flags,omitempty"`
+} //	@name	OrgPatch
@@ -0,0 +1,60 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgFeatureFlagsApply(t *testing.T) {
+	defaults := FeatureFlags{
+		AllowPullRequests:    true,
+		PrivilegedPlugins:    []string{"plugins/docker"},
+		ExposeSecretsToForks: true,
+		DefaultTimeout:       60,
+		MaxRunningPipelines:  5,
+	}
+
+	t.Run("no overrides", func(t *testing.T) {
+		assert.Equal(t, defaults, OrgFeatureFlags{}.Apply(defaults))
+	})
+
+	t.Run("overrides", func(t *testing.T) {
+		allowPull, exposeSecrets, timeout, maxRunning := false, false, int64(30), 0
+		plugins := []string{}
+		flags := OrgFeatureFlags{
+			AllowPullRequests:    &allowPull,
+			PrivilegedPlugins:    &plugins,
+			ExposeSecretsToForks: &exposeSecrets,
+			DefaultTimeout:       &timeout,
+			MaxRunningPipelines:  &maxRunning,
+		}.Apply(defaults)
+		assert.Equal(t, FeatureFlags{
+			PrivilegedPlugins: []string{},
+			DefaultTimeout:    30,
+		}, flags)
+	})
+
+	t.Run("partial overrides", func(t *testing.T) {
+		timeout := int64(10)
+		flags := OrgFeatureFlags{DefaultTimeout: &timeout}.Apply(defaults)
+		assert.True(t, flags.AllowPullRequests)
+		assert.Equal(t, []string{"plugins/docker"}, flags.PrivilegedPlugins)
+		assert.EqualValues(t, 10, flags.DefaultTimeout)
+		assert.Equal(t, 5, flags.MaxRunningPipelines)
+	})
+}
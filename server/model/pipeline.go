@@ -55,6 +55,9 @@ type Pipeline struct {
 	PullRequestMilestone string                 `json:"pr_milestone,omitempty"  xorm:"pr_milestone"`
 	IsPrerelease         bool                   `json:"is_prerelease,omitempty" xorm:"is_prerelease"`
 	FromFork             bool                   `json:"from_fork,omitempty"     xorm:"from_fork"`
+	CacheKey             string                 `json:"-"                       xorm:"INDEX 'cache_key'"`
+	CacheHit             int64                  `json:"cache_hit,omitempty"     xorm:"cache_hit"`
+	LogStore             string                 `json:"-"                       xorm:"varchar(50) 'log_store'"` // log store of the repo when the pipeline was created
 } //	@name	Pipeline
 
 // TableName return database table name for xorm.
@@ -0,0 +1,73 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// PipelineSortField is the field pipelines of a query are sorted by.
+type PipelineSortField string //	@name	PipelineSortField
+
+const (
+	PipelineSortCreated  PipelineSortField = "created"
+	PipelineSortStarted  PipelineSortField = "started"
+	PipelineSortFinished PipelineSortField = "finished"
+	PipelineSortDuration PipelineSortField = "duration"
+)
+
+// PipelineQuery selects pipelines across repos, unset fields do not filter.
+type PipelineQuery struct {
+	RepoIDs  []int64        `json:"repo_ids,omitempty"`
+	Events   []WebhookEvent `json:"events,omitempty"`
+	Statuses []StatusValue  `json:"statuses,omitempty"`
+	Authors  []string       `json:"authors,omitempty"`
+	// After and Before limit the creation time of the pipelines as unix timestamps.
+	After  int64 `json:"after,omitempty"`
+	Before int64 `json:"before,omitempty"`
+	// MinDuration and MaxDuration limit the duration of finished pipelines in seconds.
+	MinDuration int64 `json:"min_duration,omitempty"`
+	MaxDuration int64 `json:"max_duration,omitempty"`
+	// SortBy defaults to the creation time, pipelines are sorted descending unless Ascending is set.
+	SortBy    PipelineSortField `json:"sort_by,omitempty"`
+	Ascending bool              `json:"ascending,omitempty"`
+} //	@name	PipelineQuery
+
+// Validate checks the query for invalid values.
+func (q *PipelineQuery) Validate() error {
+	for _, event := range q.Events {
+		if err := event.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, status := range q.Statuses {
+		if err := status.Validate(); err != nil {
+			return err
+		}
+	}
+	if q.MinDuration < 0 || q.MaxDuration < 0 {
+		return errors.New("durations must not be negative")
+	}
+	if q.MaxDuration > 0 && q.MinDuration > q.MaxDuration {
+		return errors.New("min duration must not exceed max duration")
+	}
+	switch q.SortBy {
+	case "", PipelineSortCreated, PipelineSortStarted, PipelineSortFinished, PipelineSortDuration:
+	default:
+		return fmt.Errorf("pipelines can't be sorted by '%s'", q.SortBy)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ type QueueTask struct {
 	Task
 	PipelineNumber int64  `json:"pipeline_number"`
 	AgentName      string `json:"agent_name"`
+	RepoFullName   string `json:"repo_full_name"`
 }
 
 // QueueInfo represents the response structure for queue information API.
@@ -59,6 +59,12 @@ type Repo struct {
 	Branch                       string               `json:"default_branch,omitempty"        xorm:"varchar(500) 'branch'"`
 	PREnabled                    bool                 `json:"pr_enabled"                      xorm:"DEFAULT TRUE 'pr_enabled'"`
 	Timeout                      int64                `json:"timeout,omitempty"               xorm:"timeout"`
+	MaxTimeout                   int64                `json:"max_timeout,omitempty"           xorm:"max_timeout"`
+	MaxMatrixJobs                int                  `json:"max_matrix_jobs,omitempty"       xorm:"max_matrix_jobs"`
+	LogStore                     string               `json:"log_store,omitempty"             xorm:"varchar(50) 'log_store'"`
+	LogMaxSize                   int                  `json:"log_max_size,omitempty"          xorm:"log_max_size"`
+	LogMaxLines                  int                  `json:"log_max_lines,omitempty"         xorm:"log_max_lines"`
+	Priority                     int                  `json:"priority"                        xorm:"priority"`
 	Visibility                   RepoVisibility       `json:"visibility"                      xorm:"varchar(10) 'visibility'"`
 	IsSCMPrivate                 bool                 `json:"private"                         xorm:"private"`
 	Trusted                      TrustedConfiguration `json:"trusted"                         xorm:"json 'trusted'"`
@@ -67,12 +73,21 @@ type Repo struct {
 	IsActive                     bool                 `json:"active"                          xorm:"active"`
 	AllowPull                    bool                 `json:"allow_pr"                        xorm:"allow_pr"`
 	AllowDeploy                  bool                 `json:"allow_deploy"                    xorm:"allow_deploy"`
+	ResultCache                  bool                 `json:"result_cache"                    xorm:"result_cache"`
+	ExposeSecretsToForks         *bool                `json:"expose_secrets_to_forks"         xorm:"expose_secrets_to_forks"`
+	WorkflowStatusChecks         bool                 `json:"workflow_status_checks"          xorm:"workflow_status_checks"`
 	Config                       string               `json:"config_file"                     xorm:"varchar(500) 'config_path'"`
 	Hash                         string               `json:"-"                               xorm:"varchar(500) 'hash'"`
+	PreviousHashes               []RepoHash           `json:"-"                               xorm:"json 'previous_hashes'"`
 	Perm                         *Perm                `json:"-"                               xorm:"-"`
 	CancelPreviousPipelineEvents []WebhookEvent       `json:"cancel_previous_pipeline_events" xorm:"json 'cancel_previous_pipeline_events'"`
 	NetrcTrustedPlugins          []string             `json:"netrc_trusted"                   xorm:"json 'netrc_trusted'"`
+	PrivilegedPlugins            []string             `json:"privileged_plugins"              xorm:"json 'privileged_plugins'"`
+	TrustedClonePlugins          []string             `json:"trusted_clone_plugins"           xorm:"json 'trusted_clone_plugins'"`
 	ConfigExtensionEndpoint      string               `json:"config_extension_endpoint"       xorm:"varchar(500) 'config_extension_endpoint'"`
+	NotifyURL                    string               `json:"notify_url"                      xorm:"varchar(1000) 'notify_url'"`
+	NotifySecret                 string               `json:"-"                               xorm:"varchar(500) 'notify_secret'"`
+	NotifyEvents                 []StatusValue        `json:"notify_events"                   xorm:"json 'notify_events'"`
 } //	@name	Repo
 
 // TableName return database table name for xorm.
@@ -91,6 +106,36 @@ func (r *Repo) ResetVisibility() {
 	}
 }
 
+// RepoHash is a previous hash of a repository, still accepted to verify webhooks until it expires.
+type RepoHash struct {
+	Hash    string `json:"hash"`
+	Expires int64  `json:"expires"`
+}
+
+// WebhookSecrets returns the hash and the previous hashes which did not expire yet.
+func (r *Repo) WebhookSecrets(now int64) []string {
+	secrets := []string{r.Hash}
+	for _, previous := range r.PreviousHashes {
+		if previous.Expires > now {
+			secrets = append(secrets, previous.Hash)
+		}
+	}
+	return secrets
+}
+
+// RotateHash replaces the hash, the current one is still accepted until expires.
+// Expired previous hashes are dropped.
+func (r *Repo) RotateHash(hash string, now, expires int64) {
+	previous := []RepoHash{{Hash: r.Hash, Expires: expires}}
+	for _, h := range r.PreviousHashes {
+		if h.Expires > now {
+			previous = append(previous, h)
+		}
+	}
+	r.Hash = hash
+	r.PreviousHashes = previous
+}
+
 // ParseRepo parses the repository owner and name from a string.
 func ParseRepo(str string) (user, repo string, err error) {
 	before, after, _ := strings.Cut(str, "/")
@@ -137,15 +182,54 @@ type RepoPatch struct {
 	RequireApproval              *string                    `json:"require_approval,omitempty"`
 	ApprovalAllowedUsers         *[]string                  `json:"approval_allowed_users,omitempty"`
 	Timeout                      *int64                     `json:"timeout,omitempty"`
+	MaxTimeout                   *int64                     `json:"max_timeout,omitempty"`
+	MaxMatrixJobs                *int                       `json:"max_matrix_jobs,omitempty"`
+	LogStore                     *string                    `json:"log_store,omitempty"`
+	LogMaxSize                   *int                       `json:"log_max_size,omitempty"`
+	LogMaxLines                  *int                       `json:"log_max_lines,omitempty"`
+	LogRetention                 *string                    `json:"log_retention,omitempty"`
+	Priority                     *int                       `json:"priority,omitempty"`
 	Visibility                   *string                    `json:"visibility,omitempty"`
 	AllowPull                    *bool                      `json:"allow_pr,omitempty"`
 	AllowDeploy                  *bool                      `json:"allow_deploy,omitempty"`
+	ResultCache                  *bool                      `json:"result_cache,omitempty"`
+	ExposeSecretsToForks         *bool                      `json:"expose_secrets_to_forks,omitempty"`
+	WorkflowStatusChecks         *bool                      `json:"workflow_status_checks,omitempty"`
 	CancelPreviousPipelineEvents *[]WebhookEvent            `json:"cancel_previous_pipeline_events"`
 	NetrcTrusted                 *[]string                  `json:"netrc_trusted"`
+	PrivilegedPlugins            *[]string                  `json:"privileged_plugins,omitempty"`
+	TrustedClonePlugins          *[]string                  `json:"trusted_clone_plugins,omitempty"`
 	Trusted                      *TrustedConfigurationPatch `json:"trusted"`
 	ConfigExtensionEndpoint      *string                    `json:"config_extension_endpoint,omitempty"`
+	NotifyURL                    *string                    `json:"notify_url,omitempty"`
+	NotifySecret                 *string                    `json:"notify_secret,omitempty"`
+	NotifyEvents                 *[]StatusValue             `json:"notify_events,omitempty"`
 } //	@name	RepoPatch
 
+// RepoRepairReport describes what was fixed while repairing a repository.
+type RepoRepairReport struct {
+	RepoID  int64    `json:"repo_id"`
+	Changes []string `json:"changes"`
+} //	@name	RepoRepairReport
+
+// RepoSyncReport describes the differences found while synchronizing the repositories of a user with the forge.
+type RepoSyncReport struct {
+	// Added lists the repositories the user can activate which are not active yet.
+	Added []string `json:"added"`
+	// Enabled lists the added repositories which got activated.
+	Enabled []string `json:"enabled"`
+	// Removed lists the active repositories which are no longer available at the forge.
+	Removed []string `json:"removed"`
+	// Renamed lists the repositories whose full name changed at the forge.
+	Renamed []RepoRename `json:"renamed"`
+} //	@name	RepoSyncReport
+
+// RepoRename describes a renamed repository.
+type RepoRename struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+} //	@name	RepoRename
+
 type ForgeRemoteID string
 
 func (r ForgeRemoteID) IsValid() bool {
@@ -35,6 +35,11 @@ type Task struct {
 	AgentID      int64                  `json:"agent_id"     xorm:"'agent_id'"`
 	PipelineID   int64                  `json:"pipeline_id"  xorm:"'pipeline_id'"`
 	RepoID       int64                  `json:"repo_id"      xorm:"'repo_id'"`
+	OrgID        int64                  `json:"org_id"       xorm:"'org_id'"`
+	Created      int64                  `json:"created"      xorm:"'created'"`
+	Priority     int                    `json:"priority"     xorm:"'priority'"`
+	PendingTTL   int64                  `json:"pending_ttl"  xorm:"'pending_ttl'"`
+	Resources    Resources              `json:"resources"    xorm:"json 'resources'"`
 } //	@name	Task
 
 // TableName return database table name for xorm.
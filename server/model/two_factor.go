@@ -0,0 +1,33 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+// TwoFactorEnrollment is returned when enrolling a TOTP second factor.
+type TwoFactorEnrollment struct {
+	// Secret is the base32 encoded TOTP secret to enter in an authenticator app.
+	Secret string `json:"secret"`
+	// URI is the otpauth uri of the secret, e.g. to show as QR code.
+	URI string `json:"uri"`
+} //	@name	TwoFactorEnrollment
+
+// TwoFactorCode is a TOTP or recovery code sent to verify the second factor.
+type TwoFactorCode struct {
+	Code string `json:"code"`
+} //	@name	TwoFactorCode
+
+// TwoFactorRecoveryCodes are the recovery codes of a user, they are only returned once.
+type TwoFactorRecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+} //	@name	TwoFactorRecoveryCodes
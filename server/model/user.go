@@ -71,6 +71,18 @@ type User struct {
 
 	// OrgID is the of the user as model.Org.
 	OrgID int64 `json:"org_id" xorm:"org_id"`
+
+	// TOTPEnabled indicates the user enrolled a TOTP second factor.
+	TOTPEnabled bool `json:"totp_enabled,omitempty" xorm:"totp_enabled"`
+
+	// TOTPSecret is the base32 encoded TOTP secret, it is set during enrollment already.
+	TOTPSecret string `json:"-" xorm:"varchar(100) 'totp_secret'"`
+
+	// TOTPLastStep is the time step of the last accepted TOTP code, so codes can't be used twice.
+	TOTPLastStep int64 `json:"-" xorm:"totp_last_step"`
+
+	// TOTPRecoveryCodes are the hashes of the unused recovery codes.
+	TOTPRecoveryCodes []string `json:"-" xorm:"json 'totp_recovery_codes'"`
 } //	@name	User
 
 // TableName return database table name for xorm.
@@ -67,7 +67,11 @@ func Create(ctx context.Context, _store store.Store, repo *model.Repo, pipeline
 
 	// update some pipeline fields
 	pipeline.RepoID = repo.ID
-	pipeline.Status = model.StatusCreated
+	pipeline.LogStore = repo.LogStore
+	if pipeline.Status != model.StatusBlocked {
+		// callers can block a pipeline already, e.g. while the queue is full
+		pipeline.Status = model.StatusCreated
+	}
 	setApprovalState(repo, pipeline)
 	err = _store.CreatePipeline(pipeline)
 	if err != nil {
@@ -128,6 +132,12 @@ func Create(ctx context.Context, _store store.Store, repo *model.Repo, pipeline
 		return nil, errors.New(msg)
 	}
 
+	if cacheHit, err := useResultCache(ctx, _forge, _store, pipeline, repoUser, repo, false); err != nil {
+		log.Error().Err(err).Str("repo", repo.FullName).Msgf("error checking result cache for %s#%d", repo.FullName, pipeline.Number)
+	} else if cacheHit {
+		return pipeline, nil
+	}
+
 	if err := prepareStart(ctx, _forge, _store, pipeline, repoUser, repo); err != nil {
 		log.Error().Err(err).Str("repo", repo.FullName).Msgf("error preparing pipeline for %s#%d", repo.FullName, pipeline.Number)
 		return nil, err
@@ -0,0 +1,100 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+const pendingTTLExpiredMsg = "timed out waiting for agent"
+
+// FailExpiredTasks fails the workflows of tasks which waited for an agent longer than their pending ttl
+// and finishes their pipelines if no other workflow is left to run.
+func FailExpiredTasks(ctx context.Context, store store.Store, tasks []*model.Task) {
+	pipelines := map[int64]struct{}{}
+	for _, task := range tasks {
+		workflowID, err := strconv.ParseInt(task.ID, 10, 64)
+		if err != nil {
+			log.Error().Err(err).Msgf("invalid id of expired task %s", task.ID)
+			continue
+		}
+
+		workflow, err := store.WorkflowLoad(workflowID)
+		if err != nil {
+			log.Error().Err(err).Msgf("cannot find workflow with id %d", workflowID)
+			continue
+		}
+		// the workflow could have been canceled in the meantime
+		if workflow.State != model.StatusPending {
+			continue
+		}
+		workflow.Children, err = store.StepListFromWorkflowFind(workflow)
+		if err != nil {
+			log.Error().Err(err).Msgf("cannot find steps of workflow with id %d", workflowID)
+			continue
+		}
+
+		if err := failWorkflow(store, workflow, pendingTTLExpiredMsg); err != nil {
+			log.Error().Err(err).Msgf("cannot fail expired workflow with id %d", workflowID)
+			continue
+		}
+		pipelines[workflow.PipelineID] = struct{}{}
+	}
+
+	for pipelineID := range pipelines {
+		if err := finishExpiredPipeline(ctx, store, pipelineID); err != nil {
+			log.Error().Err(err).Msgf("cannot update pipeline with id %d after its workflows expired", pipelineID)
+		}
+	}
+}
+
+func finishExpiredPipeline(ctx context.Context, store store.Store, pipelineID int64) error {
+	pipeline, err := store.GetPipeline(pipelineID)
+	if err != nil {
+		return err
+	}
+	if pipeline.Workflows, err = store.WorkflowGetTree(pipeline); err != nil {
+		return err
+	}
+	repo, err := store.GetRepo(pipeline.RepoID)
+	if err != nil {
+		return err
+	}
+
+	if !model.IsThereRunningStage(pipeline.Workflows) {
+		if pipeline, err = UpdateStatusToDone(store, *pipeline, model.PipelineStatus(pipeline.Workflows), time.Now().Unix()); err != nil {
+			return err
+		}
+	}
+
+	user, err := store.GetUser(repo.UserID)
+	if err != nil {
+		return err
+	}
+	forge, err := server.Config.Services.Manager.ForgeFromRepo(repo)
+	if err != nil {
+		return err
+	}
+	publishPipeline(ctx, forge, pipeline, repo, user)
+	return nil
+}
@@ -0,0 +1,76 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	forge_mocks "go.woodpecker-ci.org/woodpecker/v3/server/forge/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/pubsub"
+	manager_mocks "go.woodpecker-ci.org/woodpecker/v3/server/services/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+)
+
+type recordingNotifier struct {
+	notified []*model.Pipeline
+}
+
+func (n *recordingNotifier) Notify(_ *model.Repo, pipeline *model.Pipeline) {
+	n.notified = append(n.notified, pipeline)
+}
+
+func TestFailExpiredTasks(t *testing.T) {
+	repo := &model.Repo{ID: 1, UserID: 1, FullName: "octo/repo"}
+	user := &model.User{ID: 1}
+	expired := &model.Workflow{ID: 1, PipelineID: 5, State: model.StatusPending}
+	canceled := &model.Workflow{ID: 2, PipelineID: 5, State: model.StatusSkipped}
+	step := &model.Step{ID: 1, State: model.StatusPending}
+
+	store := mocks.NewMockStore(t)
+	store.On("WorkflowLoad", int64(1)).Return(expired, nil)
+	store.On("WorkflowLoad", int64(2)).Return(canceled, nil)
+	store.On("StepListFromWorkflowFind", expired).Return([]*model.Step{step}, nil)
+	store.On("WorkflowUpdate", expired).Return(nil)
+	store.On("StepUpdate", step).Return(nil)
+	store.On("GetPipeline", int64(5)).Return(&model.Pipeline{ID: 5, RepoID: 1, Status: model.StatusPending}, nil)
+	store.On("WorkflowGetTree", mock.Anything).Return([]*model.Workflow{expired, canceled}, nil)
+	store.On("GetRepo", int64(1)).Return(repo, nil)
+	store.On("UpdatePipeline", mock.MatchedBy(func(p *model.Pipeline) bool { return p.Status == model.StatusError })).Return(nil)
+	store.On("GetUser", int64(1)).Return(user, nil)
+
+	forge := forge_mocks.NewMockForge(t)
+	forge.On("Status", mock.Anything, user, repo, mock.Anything, mock.Anything).Return(nil)
+	notifier := &recordingNotifier{}
+	manager := manager_mocks.NewMockManager(t)
+	manager.On("ForgeFromRepo", repo).Return(forge, nil)
+	manager.On("NotificationService").Return(notifier)
+	server.Config.Services.Manager = manager
+	server.Config.Services.Pubsub = pubsub.New()
+
+	FailExpiredTasks(t.Context(), store, []*model.Task{{ID: "1"}, {ID: "2"}})
+
+	assert.Equal(t, model.StatusError, expired.State)
+	assert.Equal(t, "timed out waiting for agent", expired.Error)
+	assert.Equal(t, model.StatusSkipped, step.State)
+	assert.Equal(t, model.StatusSkipped, canceled.State)
+	if assert.Len(t, notifier.notified, 1) {
+		assert.Equal(t, model.StatusError, notifier.notified[0].Status)
+	}
+}
@@ -19,11 +19,14 @@ import (
 
 	"github.com/rs/zerolog/log"
 
+	"go.woodpecker-ci.org/woodpecker/v3/server"
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 )
 
 func updatePipelineStatus(ctx context.Context, forge forge.Forge, pipeline *model.Pipeline, repo *model.Repo, user *model.User) {
+	server.Config.Services.Manager.NotificationService().Notify(repo, pipeline)
+
 	for _, workflow := range pipeline.Workflows {
 		err := forge.Status(ctx, user, repo, pipeline, workflow)
 		if err != nil {
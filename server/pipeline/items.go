@@ -43,11 +43,21 @@ func parsePipeline(forge forge.Forge, store store.Store, currentPipeline *model.
 		log.Error().Err(err).Str("repo", repo.FullName).Msgf("error getting last pipeline before pipeline number '%d'", currentPipeline.Number)
 	}
 
+	org, err := store.OrgGet(repo.OrgID)
+	if err != nil {
+		log.Error().Err(err).Str("repo", repo.FullName).Msg("error getting org of repo, using global feature flags")
+		org = nil
+	}
+	flags := server.FeatureFlags(org, repo)
+
 	secretService := server.Config.Services.Manager.SecretServiceFromRepo(repo)
 	secs, err := secretService.SecretListPipeline(repo, currentPipeline)
 	if err != nil {
 		log.Error().Err(err).Msgf("error getting secrets for %s#%d", repo.FullName, currentPipeline.Number)
 	}
+	if currentPipeline.IsPullRequest() && currentPipeline.FromFork && !flags.ExposeSecretsToForks {
+		secs = nil
+	}
 
 	registryService := server.Config.Services.Manager.RegistryServiceFromRepo(repo)
 	regs, err := registryService.RegistryListPipeline(repo, currentPipeline)
@@ -71,6 +81,8 @@ func parsePipeline(forge forge.Forge, store store.Store, currentPipeline *model.
 		envs[k] = v
 	}
 
+	currentPipeline.CacheKey = resultCacheKey(repo, currentPipeline, yamls, secs, regs, envs, flags.PrivilegedPlugins)
+
 	b := stepbuilder.StepBuilder{
 		Repo:          repo,
 		Curr:          currentPipeline,
@@ -83,6 +95,8 @@ func parsePipeline(forge forge.Forge, store store.Store, currentPipeline *model.
 		Yamls:         yamls,
 		Forge:         forge,
 		DefaultLabels: server.Config.Pipeline.DefaultWorkflowLabels,
+		Privileged:    flags.PrivilegedPlugins,
+		MaxMatrixJobs: server.MaxMatrixJobs(repo),
 		ProxyOpts: compiler.ProxyOptions{
 			NoProxy:    server.Config.Pipeline.Proxy.No,
 			HTTPProxy:  server.Config.Pipeline.Proxy.HTTP,
@@ -0,0 +1,101 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/pipeline/stepbuilder"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+// failUnmatchedWorkflows sets the workflows to error which request labels no registered agent could ever match,
+// so they don't stay pending forever. It returns whether a workflow was failed.
+func failUnmatchedWorkflows(store store.Store, repo *model.Repo, pipelineItems []*stepbuilder.Item) (bool, error) {
+	agents, err := store.AgentList(&model.ListOptions{All: true})
+	if err != nil {
+		return false, err
+	}
+
+	var agentsLabels []map[string]string
+	for _, agent := range agents {
+		// agents which never connected yet did not report their labels
+		if agent.Backend == "" && agent.Platform == "" {
+			continue
+		}
+		agentsLabels = append(agentsLabels, agent.FilterLabels())
+	}
+	if len(agentsLabels) == 0 {
+		return false, nil
+	}
+
+	failed := false
+	for _, item := range pipelineItems {
+		if item.Workflow.State == model.StatusSkipped {
+			continue
+		}
+
+		task := &model.Task{Labels: maps.Clone(item.Labels)}
+		if err := task.ApplyLabelsFromRepo(repo); err != nil {
+			return failed, err
+		}
+		unmatched := queue.UnmatchedLabels(task, agentsLabels)
+		if len(unmatched) == 0 {
+			continue
+		}
+
+		msg := fmt.Sprintf("no agent with label %s", strings.Join(unmatched, ", "))
+		if len(unmatched) > 1 {
+			msg = fmt.Sprintf("no agent with labels %s", strings.Join(unmatched, ", "))
+		}
+		if server.Config.Pipeline.UnmatchedLabelsWarnOnly {
+			log.Warn().Str("repo", repo.FullName).Msgf("workflow '%s' will stay pending: %s", item.Workflow.Name, msg)
+			continue
+		}
+
+		if err := failWorkflow(store, item.Workflow, msg); err != nil {
+			return failed, err
+		}
+		failed = true
+	}
+	return failed, nil
+}
+
+func failWorkflow(store store.Store, workflow *model.Workflow, msg string) error {
+	now := time.Now().Unix()
+	workflow.State = model.StatusError
+	workflow.Error = msg
+	workflow.Started = now
+	workflow.Finished = now
+	if err := store.WorkflowUpdate(workflow); err != nil {
+		return err
+	}
+
+	for _, step := range workflow.Children {
+		step.State = model.StatusSkipped
+		if err := store.StepUpdate(step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
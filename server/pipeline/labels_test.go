@@ -0,0 +1,107 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/pipeline/stepbuilder"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+)
+
+func TestFailUnmatchedWorkflows(t *testing.T) {
+	repo := &model.Repo{FullName: "octo/repo", OrgID: 1}
+	agents := []*model.Agent{
+		{Name: "amd64", Platform: "linux/amd64", Backend: "docker", OrgID: model.IDNotSet},
+		{Name: "gpu", Platform: "linux/amd64", Backend: "docker", CustomLabels: map[string]string{"gpu": "true"}, OrgID: 1},
+		// never connected, so its labels are unknown
+		{Name: "new", OrgID: model.IDNotSet},
+	}
+	items := func() []*stepbuilder.Item {
+		return []*stepbuilder.Item{
+			{
+				Workflow: &model.Workflow{ID: 1, Name: "build", State: model.StatusPending, Children: []*model.Step{{ID: 1, State: model.StatusPending}}},
+				Labels:   map[string]string{"platform": "linux/amd64"},
+			},
+			{
+				Workflow: &model.Workflow{ID: 2, Name: "train", State: model.StatusPending, Children: []*model.Step{{ID: 2, State: model.StatusPending}}},
+				Labels:   map[string]string{"gpu": "true", "backend": "docker"},
+			},
+			{
+				Workflow: &model.Workflow{ID: 3, Name: "windows", State: model.StatusPending, Children: []*model.Step{{ID: 3, State: model.StatusPending}}},
+				Labels:   map[string]string{"platform": "windows/amd64", "gpu": "true"},
+			},
+		}
+	}
+
+	t.Run("fail", func(t *testing.T) {
+		store := mocks.NewMockStore(t)
+		store.On("AgentList", mock.Anything).Return(agents, nil)
+		store.On("WorkflowUpdate", mock.Anything).Return(nil).Once()
+		store.On("StepUpdate", mock.Anything).Return(nil).Once()
+
+		pipelineItems := items()
+		failed, err := failUnmatchedWorkflows(store, repo, pipelineItems)
+		assert.NoError(t, err)
+		assert.True(t, failed)
+		assert.Equal(t, model.StatusPending, pipelineItems[0].Workflow.State)
+		assert.Equal(t, model.StatusPending, pipelineItems[1].Workflow.State)
+		assert.Equal(t, model.StatusError, pipelineItems[2].Workflow.State)
+		assert.Equal(t, "no agent with label platform=windows/amd64", pipelineItems[2].Workflow.Error)
+		assert.Equal(t, model.StatusSkipped, pipelineItems[2].Workflow.Children[0].State)
+	})
+
+	t.Run("org agent", func(t *testing.T) {
+		store := mocks.NewMockStore(t)
+		store.On("AgentList", mock.Anything).Return(agents, nil)
+		store.On("WorkflowUpdate", mock.Anything).Return(nil).Twice()
+		store.On("StepUpdate", mock.Anything).Return(nil).Twice()
+
+		pipelineItems := items()
+		failed, err := failUnmatchedWorkflows(store, &model.Repo{FullName: "other/repo", OrgID: 2}, pipelineItems)
+		assert.NoError(t, err)
+		assert.True(t, failed)
+		assert.Equal(t, model.StatusPending, pipelineItems[0].Workflow.State)
+		assert.Equal(t, "no agent with labels backend=docker, gpu=true", pipelineItems[1].Workflow.Error)
+	})
+
+	t.Run("warn only", func(t *testing.T) {
+		server.Config.Pipeline.UnmatchedLabelsWarnOnly = true
+		t.Cleanup(func() { server.Config.Pipeline.UnmatchedLabelsWarnOnly = false })
+
+		store := mocks.NewMockStore(t)
+		store.On("AgentList", mock.Anything).Return(agents, nil)
+
+		pipelineItems := items()
+		failed, err := failUnmatchedWorkflows(store, repo, pipelineItems)
+		assert.NoError(t, err)
+		assert.False(t, failed)
+		assert.Equal(t, model.StatusPending, pipelineItems[2].Workflow.State)
+	})
+
+	t.Run("no registered agent", func(t *testing.T) {
+		store := mocks.NewMockStore(t)
+		store.On("AgentList", mock.Anything).Return(agents[2:], nil)
+
+		failed, err := failUnmatchedWorkflows(store, repo, items())
+		assert.NoError(t, err)
+		assert.False(t, failed)
+	})
+}
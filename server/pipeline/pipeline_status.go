@@ -59,6 +59,15 @@ func UpdateToStatusError(store store.Store, pipeline model.Pipeline, err error)
 	return &pipeline, store.UpdatePipeline(&pipeline)
 }
 
+func UpdateToStatusCacheHit(store store.Store, pipeline model.Pipeline, cached *model.Pipeline) (*model.Pipeline, error) {
+	pipeline.Status = model.StatusSuccess
+	pipeline.CacheHit = cached.Number
+	pipeline.Started = time.Now().Unix()
+	pipeline.Finished = pipeline.Started
+	pipeline.Workflows = nil
+	return &pipeline, store.UpdatePipeline(&pipeline)
+}
+
 func UpdateToStatusKilled(store store.Store, pipeline model.Pipeline) (*model.Pipeline, error) {
 	pipeline.Status = model.StatusKilled
 	pipeline.Finished = time.Now().Unix()
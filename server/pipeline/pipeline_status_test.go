@@ -100,3 +100,17 @@ func TestUpdateToStatusKilled(t *testing.T) {
 	assert.Equal(t, model.StatusKilled, pipeline.Status)
 	assert.LessOrEqual(t, now, pipeline.Finished)
 }
+
+func TestUpdateToStatusCacheHit(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now().Unix()
+
+	pipeline, _ := UpdateToStatusCacheHit(mockStorePipeline(t), model.Pipeline{Workflows: []*model.Workflow{{}}}, &model.Pipeline{Number: 3})
+
+	assert.Equal(t, model.StatusSuccess, pipeline.Status)
+	assert.EqualValues(t, 3, pipeline.CacheHit)
+	assert.LessOrEqual(t, now, pipeline.Started)
+	assert.Equal(t, pipeline.Started, pipeline.Finished)
+	assert.Empty(t, pipeline.Workflows)
+}
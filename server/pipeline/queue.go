@@ -19,6 +19,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"maps"
+	"slices"
+	"time"
 
 	"go.woodpecker-ci.org/woodpecker/v3/pipeline/rpc"
 	"go.woodpecker-ci.org/woodpecker/v3/server"
@@ -29,7 +31,7 @@ import (
 func queuePipeline(ctx context.Context, repo *model.Repo, pipelineItems []*stepbuilder.Item) error {
 	var tasks []*model.Task
 	for _, item := range pipelineItems {
-		if item.Workflow.State == model.StatusSkipped {
+		if item.Workflow.State == model.StatusSkipped || item.Workflow.State == model.StatusError {
 			continue
 		}
 		task := &model.Task{
@@ -39,6 +41,11 @@ func queuePipeline(ctx context.Context, repo *model.Repo, pipelineItems []*stepb
 			Labels:     make(map[string]string),
 			PipelineID: item.Workflow.PipelineID,
 			RepoID:     repo.ID,
+			OrgID:      repo.OrgID,
+			Created:    time.Now().Unix(),
+			Priority:   repo.Priority,
+			PendingTTL: int64(server.PendingTTL(repo).Seconds()),
+			Resources:  item.Resources,
 		}
 		maps.Copy(task.Labels, item.Labels)
 		err := task.ApplyLabelsFromRepo(repo)
@@ -48,11 +55,17 @@ func queuePipeline(ctx context.Context, repo *model.Repo, pipelineItems []*stepb
 		task.Dependencies = taskIDs(item.DependsOn, pipelineItems)
 		task.RunOn = item.RunsOn
 		task.DepStatus = make(map[string]model.StatusValue)
+		// dependencies which failed before they got queued never report their status
+		for _, dep := range pipelineItems {
+			if dep.Workflow.State == model.StatusError && slices.Contains(task.Dependencies, fmt.Sprint(dep.Workflow.ID)) {
+				task.DepStatus[fmt.Sprint(dep.Workflow.ID)] = model.StatusError
+			}
+		}
 
 		task.Data, err = json.Marshal(rpc.Workflow{
 			ID:      fmt.Sprint(item.Workflow.ID),
 			Config:  item.Config,
-			Timeout: repo.Timeout,
+			Timeout: server.PipelineTimeout(repo),
 		})
 		if err != nil {
 			return err
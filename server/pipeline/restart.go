@@ -28,7 +28,8 @@ import (
 )
 
 // Restart a pipeline by creating a new one out of the old and start it.
-func Restart(ctx context.Context, store store.Store, lastPipeline *model.Pipeline, user *model.User, repo *model.Repo, envs map[string]string) (*model.Pipeline, error) {
+// Unless forced, the result of an identical successful pipeline is reused if the repo enabled the result cache.
+func Restart(ctx context.Context, store store.Store, lastPipeline *model.Pipeline, user *model.User, repo *model.Repo, envs map[string]string, force bool) (*model.Pipeline, error) {
 	forge, err := server.Config.Services.Manager.ForgeFromRepo(repo)
 	if err != nil {
 		msg := fmt.Sprintf("failure to load forge for repo '%s'", repo.FullName)
@@ -63,6 +64,7 @@ func Restart(ctx context.Context, store store.Store, lastPipeline *model.Pipelin
 
 	newPipeline := createNewOutOfOld(lastPipeline)
 	newPipeline.Parent = lastPipeline.Number
+	newPipeline.LogStore = repo.LogStore
 
 	err = store.CreatePipeline(newPipeline)
 	if err != nil {
@@ -93,6 +95,12 @@ func Restart(ctx context.Context, store store.Store, lastPipeline *model.Pipelin
 		return nil, errors.New(msg)
 	}
 
+	if cacheHit, err := useResultCache(ctx, forge, store, newPipeline, user, repo, force); err != nil {
+		log.Error().Err(err).Msgf("failure to check result cache for %s", repo.FullName)
+	} else if cacheHit {
+		return newPipeline, nil
+	}
+
 	if err := prepareStart(ctx, forge, store, newPipeline, user, repo); err != nil {
 		msg := fmt.Sprintf("failure to prepare pipeline for %s", repo.FullName)
 		log.Error().Err(err).Msg(msg)
@@ -131,5 +139,7 @@ func createNewOutOfOld(old *model.Pipeline) *model.Pipeline {
 	newPipeline.Started = 0
 	newPipeline.Finished = 0
 	newPipeline.Errors = nil
+	newPipeline.CacheKey = ""
+	newPipeline.CacheHit = 0
 	return &newPipeline
 }
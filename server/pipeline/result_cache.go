@@ -0,0 +1,137 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"slices"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
+	forge_types "go.woodpecker-ci.org/woodpecker/v3/server/forge/types"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+// resultCacheEvents are the events whose results can be reused.
+// Cron, manual and deploy pipelines always run, as they either depend on
+// external state or are explicitly requested.
+var resultCacheEvents = []model.WebhookEvent{
+	model.EventPush,
+	model.EventPull,
+	model.EventTag,
+}
+
+type resultCacheSecret struct {
+	Name   string
+	Value  string
+	Images []string
+	Events []model.WebhookEvent
+}
+
+type resultCacheRegistry struct {
+	Address  string
+	Username string
+	Password string
+}
+
+// resultCacheInput contains everything known to the server which could change the result of a pipeline.
+type resultCacheInput struct {
+	Commit            string
+	Ref               string
+	Event             model.WebhookEvent
+	Configs           map[string]string
+	Secrets           []resultCacheSecret
+	Registries        []resultCacheRegistry
+	Envs              map[string]string
+	Trusted           model.TrustedConfiguration
+	PrivilegedPlugins []string
+}
+
+// resultCacheKey returns the result cache key for a pipeline, or an empty
+// string if results of the pipeline must not be reused.
+func resultCacheKey(repo *model.Repo, pipeline *model.Pipeline, yamls []*forge_types.FileMeta, secrets []*model.Secret, registries []*model.Registry, envs map[string]string, privileged []string) string {
+	if !repo.ResultCache || !slices.Contains(resultCacheEvents, pipeline.Event) {
+		return ""
+	}
+
+	in := resultCacheInput{
+		Commit:            pipeline.Commit,
+		Ref:               pipeline.Ref,
+		Event:             pipeline.Event,
+		Configs:           make(map[string]string, len(yamls)),
+		Envs:              envs,
+		Trusted:           repo.Trusted,
+		PrivilegedPlugins: slices.Concat(privileged, repo.PrivilegedPlugins),
+	}
+	for _, yaml := range yamls {
+		sum := sha256.Sum256(yaml.Data)
+		in.Configs[yaml.Name] = hex.EncodeToString(sum[:])
+	}
+	for _, sec := range secrets {
+		in.Secrets = append(in.Secrets, resultCacheSecret{Name: sec.Name, Value: sec.Value, Images: sec.Images, Events: sec.Events})
+	}
+	sort.Slice(in.Secrets, func(i, j int) bool { return in.Secrets[i].Name < in.Secrets[j].Name })
+	for _, reg := range registries {
+		in.Registries = append(in.Registries, resultCacheRegistry{Address: reg.Address, Username: reg.Username, Password: reg.Password})
+	}
+	sort.Slice(in.Registries, func(i, j int) bool { return in.Registries[i].Address < in.Registries[j].Address })
+
+	data, err := json.Marshal(in)
+	if err != nil {
+		log.Error().Err(err).Str("repo", repo.FullName).Msg("could not compute result cache key")
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// useResultCache marks the pipeline as successful if an identical pipeline already succeeded.
+// It returns true if the cached result was used and the pipeline must not be started.
+// A forced pipeline always runs, but its result can be reused later.
+func useResultCache(ctx context.Context, forge forge.Forge, store store.Store, pipeline *model.Pipeline, user *model.User, repo *model.Repo, force bool) (bool, error) {
+	if pipeline.CacheKey == "" {
+		return false, nil
+	}
+
+	if !force {
+		cached, err := store.GetPipelineByCacheKey(repo, pipeline.CacheKey)
+		if err != nil && !errors.Is(err, types.RecordNotExist) {
+			return false, err
+		}
+		if err == nil {
+			log.Debug().Str("repo", repo.FullName).Msgf("reuse result of pipeline #%d for pipeline #%d", cached.Number, pipeline.Number)
+
+			_pipeline, err := UpdateToStatusCacheHit(store, *pipeline, cached)
+			if err != nil {
+				return false, err
+			}
+			*pipeline = *_pipeline
+
+			publishPipeline(ctx, forge, pipeline, repo, user)
+			return true, nil
+		}
+	}
+
+	// remember the key so later identical pipelines can reuse the result
+	return false, store.UpdatePipeline(pipeline)
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	forge_types "go.woodpecker-ci.org/woodpecker/v3/server/forge/types"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+func TestResultCacheKey(t *testing.T) {
+	t.Parallel()
+
+	repo := &model.Repo{ResultCache: true}
+	pipeline := &model.Pipeline{Event: model.EventPush, Commit: "abc"}
+	yamls := []*forge_types.FileMeta{{Name: ".woodpecker.yaml", Data: []byte("steps: []")}}
+	secrets := []*model.Secret{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}}
+
+	key := resultCacheKey(repo, pipeline, yamls, secrets, nil, nil, nil)
+	assert.NotEmpty(t, key)
+
+	t.Run("stable", func(t *testing.T) {
+		reordered := []*model.Secret{secrets[1], secrets[0]}
+		assert.Equal(t, key, resultCacheKey(repo, pipeline, yamls, reordered, nil, nil, nil))
+	})
+
+	t.Run("changed inputs", func(t *testing.T) {
+		assert.NotEqual(t, key, resultCacheKey(repo, &model.Pipeline{Event: model.EventPush, Commit: "def"}, yamls, secrets, nil, nil, nil))
+		assert.NotEqual(t, key, resultCacheKey(repo, pipeline, []*forge_types.FileMeta{{Name: ".woodpecker.yaml", Data: []byte("steps: {}")}}, secrets, nil, nil, nil))
+		assert.NotEqual(t, key, resultCacheKey(repo, pipeline, yamls, []*model.Secret{{Name: "a", Value: "changed"}, secrets[1]}, nil, nil, nil))
+		assert.NotEqual(t, key, resultCacheKey(repo, pipeline, yamls, secrets, []*model.Registry{{Address: "docker.io"}}, nil, nil))
+		assert.NotEqual(t, key, resultCacheKey(repo, pipeline, yamls, secrets, nil, map[string]string{"A": "B"}, nil))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		assert.Empty(t, resultCacheKey(&model.Repo{}, pipeline, yamls, secrets, nil, nil, nil))
+		assert.Empty(t, resultCacheKey(repo, &model.Pipeline{Event: model.EventCron}, yamls, secrets, nil, nil, nil))
+		assert.Empty(t, resultCacheKey(repo, &model.Pipeline{Event: model.EventManual}, yamls, secrets, nil, nil, nil))
+		assert.Empty(t, resultCacheKey(repo, &model.Pipeline{Event: model.EventDeploy}, yamls, secrets, nil, nil, nil))
+	})
+}
+
+func TestUseResultCache(t *testing.T) {
+	t.Parallel()
+
+	repo := &model.Repo{ID: 1, ResultCache: true}
+
+	t.Run("no cache key", func(t *testing.T) {
+		hit, err := useResultCache(t.Context(), nil, mocks.NewMockStore(t), &model.Pipeline{}, nil, repo, false)
+		assert.NoError(t, err)
+		assert.False(t, hit)
+	})
+
+	t.Run("miss", func(t *testing.T) {
+		pipeline := &model.Pipeline{CacheKey: "key"}
+		store := mocks.NewMockStore(t)
+		store.On("GetPipelineByCacheKey", repo, "key").Return(nil, types.RecordNotExist)
+		store.On("UpdatePipeline", pipeline).Return(nil)
+
+		hit, err := useResultCache(t.Context(), nil, store, pipeline, nil, repo, false)
+		assert.NoError(t, err)
+		assert.False(t, hit)
+	})
+
+	t.Run("forced", func(t *testing.T) {
+		pipeline := &model.Pipeline{CacheKey: "key"}
+		store := mocks.NewMockStore(t)
+		store.On("UpdatePipeline", mock.Anything).Return(nil)
+
+		hit, err := useResultCache(t.Context(), nil, store, pipeline, nil, repo, true)
+		assert.NoError(t, err)
+		assert.False(t, hit)
+		store.AssertNotCalled(t, "GetPipelineByCacheKey", mock.Anything, mock.Anything)
+	})
+}
@@ -16,6 +16,7 @@ package pipeline
 
 import (
 	"context"
+	"time"
 
 	"github.com/rs/zerolog/log"
 
@@ -33,6 +34,21 @@ func start(ctx context.Context, forge forge.Forge, store store.Store, activePipe
 		log.Error().Err(err).Msg("failed to cancel previous pipelines")
 	}
 
+	failed, err := failUnmatchedWorkflows(store, repo, pipelineItems)
+	if err != nil {
+		// should be not breaking
+		log.Error().Err(err).Msg("failed to check the labels of the workflows")
+	}
+	if failed && !model.IsThereRunningStage(activePipeline.Workflows) {
+		// no workflow is left to run, so the pipeline is done already
+		activePipeline, err = UpdateStatusToDone(store, *activePipeline, model.PipelineStatus(activePipeline.Workflows), time.Now().Unix())
+		if err != nil {
+			return nil, err
+		}
+		publishPipeline(ctx, forge, activePipeline, repo, user)
+		return activePipeline, nil
+	}
+
 	publishPipeline(ctx, forge, activePipeline, repo, user)
 
 	if err := queuePipeline(ctx, repo, pipelineItems); err != nil {
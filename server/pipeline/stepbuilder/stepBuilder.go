@@ -18,7 +18,9 @@ package stepbuilder
 import (
 	"fmt"
 	"maps"
+	"math"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -54,12 +56,16 @@ type StepBuilder struct {
 	Envs          map[string]string
 	Forge         metadata.ServerForge
 	DefaultLabels map[string]string
+	Privileged    []string
 	ProxyOpts     compiler.ProxyOptions
+	// MaxMatrixJobs is the max number of workflows the pipeline may expand to, 0 means unlimited.
+	MaxMatrixJobs int
 }
 
 type Item struct {
 	Workflow  *model.Workflow
 	Labels    map[string]string
+	Resources model.Resources
 	DependsOn []string
 	RunsOn    []string
 	Config    *backend_types.Config
@@ -70,8 +76,12 @@ func (b *StepBuilder) Build() (items []*Item, errorsAndWarnings error) {
 
 	pidSequence := 1
 
-	for _, y := range b.Yamls {
-		// matrix axes
+	// workflows not run but kept to report their status, see model.Repo.WorkflowStatusChecks
+	var skipped []*Item
+
+	// matrix axes
+	yamlAxes := make([][]matrix.Axis, len(b.Yamls))
+	for i, y := range b.Yamls {
 		axes, err := matrix.ParseString(string(y.Data))
 		if err != nil {
 			return nil, err
@@ -79,7 +89,11 @@ func (b *StepBuilder) Build() (items []*Item, errorsAndWarnings error) {
 		if len(axes) == 0 {
 			axes = append(axes, matrix.Axis{})
 		}
+		yamlAxes[i] = axes
+	}
 
+	for yamlIndex, y := range b.Yamls {
+		axes := yamlAxes[yamlIndex]
 		for i, axis := range axes {
 			workflow := &model.Workflow{
 				PID:     pidSequence,
@@ -100,7 +114,11 @@ func (b *StepBuilder) Build() (items []*Item, errorsAndWarnings error) {
 			if item == nil {
 				continue
 			}
-			items = append(items, item)
+			if item.Workflow.State == model.StatusSkipped {
+				skipped = append(skipped, item)
+			} else {
+				items = append(items, item)
+			}
 			pidSequence++
 		}
 
@@ -108,13 +126,36 @@ func (b *StepBuilder) Build() (items []*Item, errorsAndWarnings error) {
 		// depend on https://github.com/woodpecker-ci/woodpecker/issues/778
 	}
 
-	items = filterItemsWithMissingDependencies(items)
+	filtered := filterItemsWithMissingDependencies(items)
+	if b.Repo.WorkflowStatusChecks {
+		for _, item := range items {
+			if !slices.Contains(filtered, item) {
+				item.Workflow.State = model.StatusSkipped
+				skipped = append(skipped, item)
+			}
+		}
+	}
+	items = filtered
+
+	// only the workflows which are actually queued count towards the limit
+	if b.MaxMatrixJobs > 0 && len(items) > b.MaxMatrixJobs {
+		return nil, &errorTypes.PipelineError{
+			Message: fmt.Sprintf("pipeline expands to %d workflows, which exceeds the limit of %d", len(items), b.MaxMatrixJobs),
+			Type:    errorTypes.PipelineErrorTypeCompiler,
+		}
+	}
 
 	// check if at least one step can start if slice is not empty
 	if len(items) > 0 && !stepListContainsItemsToRun(items) {
 		return nil, fmt.Errorf("pipeline has no steps to run")
 	}
 
+	// skipped workflows are only reported if something runs at all
+	if len(items) > 0 && len(skipped) > 0 {
+		items = append(items, skipped...)
+		slices.SortFunc(items, func(a, b *Item) int { return a.Workflow.PID - b.Workflow.PID })
+	}
+
 	return items, errorsAndWarnings
 }
 
@@ -150,8 +191,8 @@ func (b *StepBuilder) genItemForWorkflow(workflow *model.Workflow, axis matrix.A
 			Volumes:  b.Repo.Trusted.Volumes,
 			Security: b.Repo.Trusted.Security,
 		}),
-		linter.PrivilegedPlugins(server.Config.Pipeline.PrivilegedPlugins),
-		linter.WithTrustedClonePlugins(server.Config.Pipeline.TrustedClonePlugins),
+		linter.PrivilegedPlugins(b.privilegedPlugins()),
+		linter.WithTrustedClonePlugins(server.TrustedClonePlugins(b.Repo)),
 	).Lint([]*linter.WorkflowConfig{{
 		Workflow:  parsed,
 		File:      workflow.Name,
@@ -166,6 +207,11 @@ func (b *StepBuilder) genItemForWorkflow(workflow *model.Workflow, axis matrix.A
 		log.Debug().Str("pipeline", workflow.Name).Msg(
 			"marked as skipped, does not match metadata",
 		)
+		if b.Repo.WorkflowStatusChecks {
+			// keep the workflow to report its status
+			workflow.State = model.StatusSkipped
+			return &Item{Workflow: workflow, Config: new(backend_types.Config)}, nil
+		}
 		return nil, nil
 	} else if err != nil {
 		log.Debug().Str("pipeline", workflow.Name).Msg(
@@ -180,6 +226,10 @@ func (b *StepBuilder) genItemForWorkflow(workflow *model.Workflow, axis matrix.A
 	}
 
 	if len(ir.Stages) == 0 {
+		if b.Repo.WorkflowStatusChecks {
+			workflow.State = model.StatusSkipped
+			return &Item{Workflow: workflow, Config: new(backend_types.Config)}, nil
+		}
 		return nil, nil
 	}
 
@@ -189,6 +239,10 @@ func (b *StepBuilder) genItemForWorkflow(workflow *model.Workflow, axis matrix.A
 		Labels:    parsed.Labels,
 		DependsOn: parsed.DependsOn,
 		RunsOn:    parsed.RunsOn,
+		Resources: model.Resources{
+			CPU:    int64(math.Round(parsed.Resources.CPU * 1000)), //nolint:mnd
+			Memory: int64(parsed.Resources.Memory),
+		},
 	}
 	if len(item.Labels) == 0 {
 		item.Labels = make(map[string]string, len(b.DefaultLabels))
@@ -266,6 +320,11 @@ func containsItemWithName(name string, items []*Item) bool {
 	return false
 }
 
+// privilegedPlugins returns the privileged plugins of the org together with the plugins granted to the repo by an admin.
+func (b *StepBuilder) privilegedPlugins() []string {
+	return slices.Concat(b.Privileged, b.Repo.PrivilegedPlugins)
+}
+
 func (b *StepBuilder) environmentVariables(metadata metadata.Metadata, axis matrix.Axis) map[string]string {
 	environ := metadata.Environ()
 	for k, v := range axis {
@@ -303,7 +362,7 @@ func (b *StepBuilder) toInternalRepresentation(parsed *yaml_types.Workflow, envi
 		compiler.WithEnviron(environ),
 		compiler.WithEnviron(b.Envs),
 		// TODO: server deps should be moved into StepBuilder fields and set on StepBuilder creation
-		compiler.WithEscalated(server.Config.Pipeline.PrivilegedPlugins...),
+		compiler.WithEscalated(b.privilegedPlugins()...),
 		compiler.WithVolumes(server.Config.Pipeline.Volumes...),
 		compiler.WithNetworks(server.Config.Pipeline.Networks...),
 		compiler.WithLocal(false),
@@ -316,7 +375,8 @@ func (b *StepBuilder) toInternalRepresentation(parsed *yaml_types.Workflow, envi
 			b.Repo.IsSCMPrivate || server.Config.Pipeline.AuthenticatePublicRepos,
 		),
 		compiler.WithDefaultClonePlugin(server.Config.Pipeline.DefaultClonePlugin),
-		compiler.WithTrustedClonePlugins(append(b.Repo.NetrcTrustedPlugins, server.Config.Pipeline.TrustedClonePlugins...)),
+		compiler.WithDefaultCloneSettings(server.Config.Pipeline.DefaultCloneSettings),
+		compiler.WithTrustedClonePlugins(append(b.Repo.NetrcTrustedPlugins, server.TrustedClonePlugins(b.Repo)...)),
 		compiler.WithRegistry(registries...),
 		compiler.WithSecret(secrets...),
 		compiler.WithPrefix(
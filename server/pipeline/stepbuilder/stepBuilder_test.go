@@ -191,6 +191,82 @@ steps:
 	}
 }
 
+func TestMaxMatrixJobs(t *testing.T) {
+	t.Parallel()
+
+	yamls := []*forge_types.FileMeta{
+		{Name: "test", Data: []byte(`
+when:
+  event: push
+matrix:
+  GO_VERSION:
+    - 1.24
+    - 1.25
+  DATABASE:
+    - postgres
+    - mysql
+steps:
+  test:
+    image: golang:${GO_VERSION}
+`)},
+		{Name: "lint", Data: []byte(`
+when:
+  event: push
+steps:
+  lint:
+    image: scratch
+`)},
+		// filtered workflows don't count towards the limit
+		{Name: "release", Data: []byte(`
+when:
+  event: tag
+matrix:
+  PLATFORM:
+    - linux
+    - windows
+steps:
+  release:
+    image: scratch
+`)},
+	}
+
+	tests := []struct {
+		name    string
+		limit   int
+		wantErr string
+	}{
+		{name: "unlimited"},
+		{name: "at limit", limit: 5},
+		{name: "over limit", limit: 4, wantErr: "pipeline expands to 5 workflows, which exceeds the limit of 4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := StepBuilder{
+				Repo:          &model.Repo{},
+				Curr:          &model.Pipeline{Event: model.EventPush},
+				Prev:          &model.Pipeline{},
+				Netrc:         &model.Netrc{},
+				Yamls:         yamls,
+				MaxMatrixJobs: tt.limit,
+				Forge:         getMockForge(t),
+			}
+
+			items, err := b.Build()
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, "[compiler] "+tt.wantErr)
+				assert.True(t, errors.HasBlockingErrors(err))
+				assert.Empty(t, items)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Len(t, items, 5)
+		})
+	}
+}
+
 func TestDependsOn(t *testing.T) {
 	t.Parallel()
 
@@ -584,6 +660,109 @@ depends_on: [ shouldbefiltered ]
 	}
 }
 
+func TestWorkflowStatusChecks(t *testing.T) {
+	t.Parallel()
+
+	yamls := []*forge_types.FileMeta{
+		{Name: "build", Data: []byte(`
+when:
+  event: push
+steps:
+  build:
+    image: scratch
+`)},
+		{Name: "release", Data: []byte(`
+when:
+  event: tag
+steps:
+  release:
+    image: scratch
+`)},
+		{Name: "publish", Data: []byte(`
+when:
+  event: push
+steps:
+  publish:
+    image: scratch
+depends_on: [ release ]
+`)},
+		{Name: "zerostep", Data: []byte(`
+when:
+  event: push
+skip_clone: true
+steps:
+  build:
+    when:
+      branch: notdev
+    image: scratch
+`)},
+	}
+
+	newBuilder := func(repo *model.Repo) StepBuilder {
+		return StepBuilder{
+			Forge: getMockForge(t),
+			Repo:  repo,
+			Curr:  &model.Pipeline{Branch: "dev", Event: model.EventPush},
+			Prev:  &model.Pipeline{},
+			Netrc: &model.Netrc{},
+			Secs:  []*model.Secret{},
+			Regs:  []*model.Registry{},
+			Yamls: yamls,
+		}
+	}
+
+	b := newBuilder(&model.Repo{})
+	pipelineItems, err := b.Build()
+	assert.NoError(t, err)
+	if assert.Len(t, pipelineItems, 1) {
+		assert.Equal(t, "build", pipelineItems[0].Workflow.Name)
+	}
+
+	b = newBuilder(&model.Repo{WorkflowStatusChecks: true})
+	pipelineItems, err = b.Build()
+	assert.NoError(t, err)
+	states := map[string]model.StatusValue{}
+	pids := []int{}
+	for _, item := range pipelineItems {
+		states[item.Workflow.Name] = item.Workflow.State
+		pids = append(pids, item.Workflow.PID)
+	}
+	assert.Equal(t, map[string]model.StatusValue{
+		"build":    model.StatusPending,
+		"release":  model.StatusSkipped,
+		"publish":  model.StatusSkipped,
+		"zerostep": model.StatusSkipped,
+	}, states)
+	assert.Equal(t, []int{1, 2, 3, 4}, pids)
+}
+
+func TestWorkflowStatusChecksNothingToRun(t *testing.T) {
+	t.Parallel()
+
+	b := StepBuilder{
+		Forge: getMockForge(t),
+		Repo:  &model.Repo{WorkflowStatusChecks: true},
+		Curr:  &model.Pipeline{Event: model.EventPush},
+		Prev:  &model.Pipeline{},
+		Netrc: &model.Netrc{},
+		Secs:  []*model.Secret{},
+		Regs:  []*model.Registry{},
+		Yamls: []*forge_types.FileMeta{
+			{Name: "release", Data: []byte(`
+when:
+  event: tag
+steps:
+  release:
+    image: scratch
+`)},
+		},
+	}
+
+	pipelineItems, err := b.Build()
+	assert.NoError(t, err)
+	assert.Empty(t, pipelineItems)
+}
+
 func TestSanitizePath(t *testing.T) {
 	t.Parallel()
 
@@ -630,3 +809,63 @@ func getMockForge(t *testing.T) forge.Forge {
 	forge.On("URL").Return("https://codeberg.org")
 	return forge
 }
+
+func TestRepoPlugins(t *testing.T) {
+	t.Parallel()
+
+	build := func(repo *model.Repo) ([]*Item, error) {
+		b := StepBuilder{
+			Forge: getMockForge(t),
+			Repo:  repo,
+			Curr:  &model.Pipeline{Event: model.EventPush},
+			Prev:  &model.Pipeline{},
+			Netrc: &model.Netrc{},
+			Secs:  []*model.Secret{},
+			Regs:  []*model.Registry{},
+			Yamls: []*forge_types.FileMeta{
+				{Data: []byte(`
+when:
+  event: push
+clone:
+  git:
+    image: example.com/team/clone
+steps:
+  publish:
+    image: example.com/team/buildx
+    settings:
+      repo: example/app
+`)},
+			},
+		}
+		return b.Build()
+	}
+	privileged := func(items []*Item) bool {
+		for _, stage := range items[0].Config.Stages {
+			for _, step := range stage.Steps {
+				if step.Name == "publish" {
+					return step.Privileged
+				}
+			}
+		}
+		t.Fatal("publish step not found")
+		return false
+	}
+
+	granted := &model.Repo{
+		FullName:            "team/app",
+		PrivilegedPlugins:   []string{"example.com/team/buildx"},
+		TrustedClonePlugins: []string{"example.com/team/clone"},
+	}
+	items, err := build(granted)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.True(t, privileged(items))
+	}
+
+	// the plugins are neither trusted nor privileged for other repos
+	items, err = build(&model.Repo{FullName: "other/app"})
+	assert.ErrorContains(t, err, "clone image does not match allow list")
+	if assert.Len(t, items, 1) {
+		assert.False(t, privileged(items))
+	}
+}
@@ -34,6 +34,15 @@ type Message struct {
 // Receiver receives published messages.
 type Receiver func(Message)
 
+// Broker publishes messages to all subscribers.
+type Broker interface {
+	// Publish sends the message to all current subscribers.
+	Publish(message Message)
+	// Subscribe calls the receiver for each published message until the context is done.
+	Subscribe(c context.Context, receiver Receiver)
+}
+
+// Publisher is an in-memory broker.
 type Publisher struct {
 	sync.Mutex
 
@@ -0,0 +1,98 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redis implements a pubsub broker which distributes messages
+// to all woodpecker servers connected to the same redis.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/pubsub"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
+)
+
+const defaultChannel = "woodpecker"
+
+// Config is the configuration of the redis broker.
+type Config struct {
+	redis.Config
+	// Channel is the redis channel all messages are published to.
+	Channel string
+}
+
+// Broker publishes messages to a redis channel. Each broker holds a single subscription
+// to this channel for its whole lifetime and passes the received messages on to its local subscribers,
+// so subscribers do not hold any redis resources and only have to be removed locally on disconnect.
+type Broker struct {
+	channel string
+	client  *redis.Client
+	local   *pubsub.Publisher
+}
+
+// New returns a redis broker which stays subscribed until the context is done.
+func New(ctx context.Context, config Config) (*Broker, error) {
+	if config.Channel == "" {
+		config.Channel = defaultChannel
+	}
+
+	client, err := redis.NewClient(ctx, config.Config)
+	if err != nil {
+		return nil, err
+	}
+	b := &Broker{
+		channel: config.Channel,
+		client:  client,
+		local:   pubsub.New(),
+	}
+
+	if err := redis.Subscribe(ctx, config.Config, config.Channel, b.receive); err != nil {
+		client.Close()
+		return nil, err
+	}
+	context.AfterFunc(ctx, client.Close)
+
+	return b, nil
+}
+
+// Publish sends the message to the subscribers of all brokers using the same channel.
+func (b *Broker) Publish(message pubsub.Message) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		log.Error().Err(err).Msg("could not encode pubsub message")
+		return
+	}
+
+	if _, err := b.client.Do(context.Background(), "PUBLISH", b.channel, string(data)); err != nil {
+		log.Error().Err(err).Msg("could not publish message to redis")
+	}
+}
+
+// Subscribe calls the receiver for each message published by any broker until the context is done.
+func (b *Broker) Subscribe(c context.Context, receiver pubsub.Receiver) {
+	b.local.Subscribe(c, receiver)
+}
+
+// receive passes the messages of the subscription on to the local subscribers.
+func (b *Broker) receive(data string) {
+	var message pubsub.Message
+	if err := json.Unmarshal([]byte(data), &message); err != nil {
+		log.Error().Err(err).Msg("could not decode pubsub message")
+		return
+	}
+	b.local.Publish(message)
+}
@@ -0,0 +1,83 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/pubsub"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis/redistest"
+)
+
+func TestCrossInstanceDelivery(t *testing.T) {
+	server := redistest.NewServer(t, "secret")
+	config := Config{Config: redis.Config{Addr: server.Addr(), Password: "secret"}}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	first, err := New(ctx, config)
+	require.NoError(t, err)
+	second, err := New(ctx, config)
+	require.NoError(t, err)
+	assert.Equal(t, 2, server.Subscriptions(defaultChannel))
+
+	received := make(chan pubsub.Message, 1)
+	subCtx, subCancel := context.WithCancel(ctx)
+	subscribed := make(chan struct{})
+	go func() {
+		second.Subscribe(subCtx, func(m pubsub.Message) { received <- m })
+		close(subscribed)
+	}()
+
+	message := pubsub.Message{ID: "1", Data: []byte("hello"), Labels: map[string]string{"repo": "a/b"}}
+	assert.Eventually(t, func() bool {
+		first.Publish(message)
+		select {
+		case m := <-received:
+			assert.Equal(t, message, m)
+			return true
+		case <-time.After(10 * time.Millisecond):
+			return false
+		}
+	}, time.Second, time.Millisecond)
+
+	// local subscribers are removed on disconnect without affecting the redis subscription
+	subCancel()
+	<-subscribed
+	first.Publish(message)
+	select {
+	case <-received:
+		t.Fatal("message received after unsubscribe")
+	case <-time.After(50 * time.Millisecond):
+	}
+	assert.Equal(t, 2, server.Subscriptions(defaultChannel))
+
+	// the redis subscriptions are closed when the brokers are stopped
+	cancel()
+	assert.Eventually(t, func() bool { return server.Subscriptions(defaultChannel) == 0 }, time.Second, time.Millisecond)
+}
+
+func TestWrongPassword(t *testing.T) {
+	server := redistest.NewServer(t, "secret")
+	_, err := New(t.Context(), Config{Config: redis.Config{Addr: server.Addr(), Password: "wrong"}})
+	assert.ErrorContains(t, err, "WRONGPASS")
+}
@@ -36,10 +36,12 @@ type entry struct {
 }
 
 type worker struct {
-	agentID int64
-	filter  FilterFn
-	channel chan *model.Task
-	stop    context.CancelCauseFunc
+	agentID   int64
+	filter    FilterFn
+	channel   chan *model.Task
+	stop      context.CancelCauseFunc
+	lease     time.Duration
+	resources *agentResources
 }
 
 type fifo struct {
@@ -51,30 +53,69 @@ type fifo struct {
 	pending       *list.List
 	waitingOnDeps *list.List
 	extension     time.Duration
+	priorityAging time.Duration
 	paused        bool
+
+	// fairScheduling makes tasks of the same priority be assigned round-robin across repos
+	// instead of in the order they were queued.
+	fairScheduling bool
+	// assigned counts the assigned tasks, lastAssigned holds the count at which a repo got a task assigned last
+	// and lastAssignedAt the time.
+	assigned       uint64
+	lastAssigned   map[int64]uint64
+	lastAssignedAt map[int64]time.Time
+
+	// orgLimit returns the running pipeline limit of an org, nil disables the limits.
+	orgLimit OrgLimitFn
+
+	// readySince holds when the pending tasks with a pending ttl got ready to be assigned.
+	readySince map[string]time.Time
+	expired    ExpiredFn
+	now        func() time.Time
 }
 
 // processTimeInterval is the time till the queue rearranges things,
 // as the agent pull in 10 milliseconds we should also give them work asap.
 const processTimeInterval = 100 * time.Millisecond
 
+// priorityAgingInterval is the time after which the priority of a waiting task is raised by one,
+// so tasks with a low priority are not starved by a constant flow of tasks with a higher priority.
+const priorityAgingInterval = time.Minute
+
 var ErrWorkerKicked = fmt.Errorf("worker was kicked")
 
 // NewMemoryQueue returns a new fifo queue.
 func NewMemoryQueue(ctx context.Context) Queue {
-	q := &fifo{
-		ctx:           ctx,
-		workers:       map[*worker]struct{}{},
-		running:       map[string]*entry{},
-		pending:       list.New(),
-		waitingOnDeps: list.New(),
-		extension:     constant.TaskTimeout,
-		paused:        false,
-	}
+	return newMemoryQueue(ctx, Config{})
+}
+
+func newMemoryQueue(ctx context.Context, config Config) *fifo {
+	q := newFifo(ctx, config)
 	go q.process()
 	return q
 }
 
+// newFifo returns a fifo queue without starting to process it.
+func newFifo(ctx context.Context, config Config) *fifo {
+	return &fifo{
+		ctx:            ctx,
+		workers:        map[*worker]struct{}{},
+		running:        map[string]*entry{},
+		pending:        list.New(),
+		waitingOnDeps:  list.New(),
+		extension:      constant.TaskTimeout,
+		priorityAging:  priorityAgingInterval,
+		paused:         false,
+		fairScheduling: config.FairScheduling,
+		lastAssigned:   map[int64]uint64{},
+		lastAssignedAt: map[int64]time.Time{},
+		orgLimit:       config.OrgLimit,
+		readySince:     map[string]time.Time{},
+		expired:        config.Expired,
+		now:            time.Now,
+	}
+}
+
 // PushAtOnce pushes multiple tasks to the tail of this queue.
 func (q *fifo) PushAtOnce(_ context.Context, tasks []*model.Task) error {
 	q.Lock()
@@ -91,10 +132,12 @@ func (q *fifo) Poll(c context.Context, agentID int64, filter FilterFn) (*model.T
 	ctx, stop := context.WithCancelCause(c)
 
 	_worker := &worker{
-		agentID: agentID,
-		channel: make(chan *model.Task, 1),
-		filter:  filter,
-		stop:    stop,
+		agentID:   agentID,
+		channel:   make(chan *model.Task, 1),
+		filter:    filter,
+		stop:      stop,
+		lease:     leaseFromContext(c),
+		resources: resourcesFromContext(c),
 	}
 	q.workers[_worker] = struct{}{}
 	q.Unlock()
@@ -158,6 +201,7 @@ func (q *fifo) EvictAtOnce(_ context.Context, taskIDs []string) error {
 			task, ok := element.Value.(*model.Task)
 			if ok && task.ID == id {
 				q.pending.Remove(element)
+				delete(q.readySince, id)
 				return nil
 			}
 		}
@@ -268,19 +312,35 @@ func (q *fifo) process() {
 
 		q.resubmitExpiredPipelines()
 		q.filterWaiting()
-		for pending, worker := q.assignToWorker(); pending != nil && worker != nil; pending, worker = q.assignToWorker() {
+		expired := q.expirePending()
+		orgLimits := map[int64]int{}
+		for pending, worker := q.assignToWorker(orgLimits); pending != nil && worker != nil; pending, worker = q.assignToWorker(orgLimits) {
 			task, _ := pending.Value.(*model.Task)
 			task.AgentID = worker.agentID
 			delete(q.workers, worker)
 			q.pending.Remove(pending)
+			delete(q.readySince, task.ID)
+			if q.fairScheduling {
+				q.assigned++
+				q.lastAssigned[task.RepoID] = q.assigned
+				q.lastAssignedAt[task.RepoID] = time.Now()
+			}
+			deadline := q.extension
+			if worker.lease > 0 && worker.lease < deadline {
+				deadline = worker.lease
+			}
 			q.running[task.ID] = &entry{
 				item:     task,
 				done:     make(chan bool),
-				deadline: time.Now().Add(q.extension),
+				deadline: time.Now().Add(deadline),
 			}
 			worker.channel <- task
 		}
 		q.Unlock()
+
+		if len(expired) != 0 && q.expired != nil {
+			q.expired(expired)
+		}
 	}
 }
 
@@ -313,30 +373,164 @@ func (q *fifo) filterWaiting() {
 	}
 }
 
-func (q *fifo) assignToWorker() (*list.Element, *worker) {
+// assignToWorker returns the pending task with the highest priority which can be assigned to a worker
+// together with the best matching worker. Tasks with the same priority are assigned in the order they were queued,
+// with fair scheduling the task of the repo which got a task assigned the longest time ago is preferred.
+// Tasks of orgs which reached their running pipeline limit are skipped.
+func (q *fifo) assignToWorker(orgLimits map[int64]int) (*list.Element, *worker) {
 	var next *list.Element
+	var bestElement *list.Element
 	var bestWorker *worker
-	var bestScore int
+	var bestPriority int
+	var bestTask *model.Task
+	now := time.Now()
+	reserved := q.reservedResources()
 
 	for element := q.pending.Front(); element != nil; element = next {
 		next = element.Next()
 		task, _ := element.Value.(*model.Task)
 		log.Debug().Msgf("queue: trying to assign task: %v with deps %v", task.ID, task.Dependencies)
 
+		var taskWorker *worker
+		var bestScore int
 		for worker := range q.workers {
 			matched, score := worker.filter(task)
+			if matched && worker.resources != nil && !worker.resources.fits(task.Resources, reserved[worker.agentID]) {
+				continue
+			}
 			if matched && score > bestScore {
-				bestWorker = worker
+				taskWorker = worker
 				bestScore = score
 			}
 		}
-		if bestWorker != nil {
-			log.Debug().Msgf("queue: assigned task: %v with deps %v to worker with score %d", task.ID, task.Dependencies, bestScore)
-			return element, bestWorker
+		if taskWorker == nil || !q.orgHasCapacity(task, orgLimits) {
+			continue
+		}
+
+		priority := q.effectivePriority(task, now)
+		if bestElement == nil || priority > bestPriority || (priority == bestPriority && q.assignedBefore(bestTask, task)) {
+			bestElement = element
+			bestWorker = taskWorker
+			bestPriority = priority
+			bestTask = task
 		}
 	}
 
-	return nil, nil
+	if bestElement != nil {
+		task, _ := bestElement.Value.(*model.Task)
+		log.Debug().Msgf("queue: assigned task: %v with deps %v and priority %d", task.ID, task.Dependencies, bestPriority)
+	}
+	return bestElement, bestWorker
+}
+
+// reservedResources sums up the resource requests of the running tasks per agent.
+func (q *fifo) reservedResources() map[int64]model.Resources {
+	reserved := map[int64]model.Resources{}
+	for _, running := range q.running {
+		r := reserved[running.item.AgentID]
+		r.CPU += running.item.Resources.CPU
+		r.Memory += running.item.Resources.Memory
+		reserved[running.item.AgentID] = r
+	}
+	return reserved
+}
+
+// orgHasCapacity reports whether the task can start without exceeding the running pipeline limit of its org.
+// Workflows of pipelines which are already running are always allowed. The limits are looked up once per
+// process cycle and cached in the given map.
+func (q *fifo) orgHasCapacity(task *model.Task, orgLimits map[int64]int) bool {
+	if q.orgLimit == nil || task.OrgID == 0 {
+		return true
+	}
+
+	limit, ok := orgLimits[task.OrgID]
+	if !ok {
+		limit = q.orgLimit(task.OrgID)
+		orgLimits[task.OrgID] = limit
+	}
+	if limit <= 0 {
+		return true
+	}
+
+	pipelines := map[int64]struct{}{}
+	for _, running := range q.running {
+		if running.item.OrgID != task.OrgID {
+			continue
+		}
+		if running.item.PipelineID == task.PipelineID {
+			return true
+		}
+		pipelines[running.item.PipelineID] = struct{}{}
+	}
+	return len(pipelines) < limit
+}
+
+// assignedBefore reports whether fair scheduling prefers the task b over the task a,
+// as the repo of a got a task assigned more recently.
+func (q *fifo) assignedBefore(a, b *model.Task) bool {
+	return q.fairScheduling && q.lastAssigned[b.RepoID] < q.lastAssigned[a.RepoID]
+}
+
+// effectivePriority is the priority of the task raised by the time it is waiting.
+// With fair scheduling the task only ages since its repo got a task assigned last, so the old tasks
+// of a repo with many pipelines can not outrank the tasks of the other repos.
+func (q *fifo) effectivePriority(task *model.Task, now time.Time) int {
+	if task.Created == 0 || q.priorityAging <= 0 {
+		return task.Priority
+	}
+	since := time.Unix(task.Created, 0)
+	if assignedAt, ok := q.lastAssignedAt[task.RepoID]; q.fairScheduling && ok && assignedAt.After(since) {
+		since = assignedAt
+	}
+	waiting := now.Sub(since)
+	return task.Priority + max(int(waiting/q.priorityAging), 0)
+}
+
+// expirePending removes the pending tasks which wait for an agent longer than their pending ttl and returns them.
+// The ttl starts when a task is ready to be assigned, so waiting on dependencies does not count.
+func (q *fifo) expirePending() []*model.Task {
+	now := q.now()
+	var expired []*model.Task
+	for {
+		batch := q.expirePendingAt(now)
+		if len(batch) == 0 {
+			return expired
+		}
+		expired = append(expired, batch...)
+
+		for _, task := range batch {
+			q.updateDepStatusInQueue(task.ID, model.StatusError)
+		}
+		// dependents of the expired tasks are ready now, so their ttl starts now as well
+		q.filterWaiting()
+	}
+}
+
+func (q *fifo) expirePendingAt(now time.Time) []*model.Task {
+	var expired []*model.Task
+	var next *list.Element
+	for element := q.pending.Front(); element != nil; element = next {
+		next = element.Next()
+		task, _ := element.Value.(*model.Task)
+		if task.PendingTTL <= 0 {
+			continue
+		}
+
+		since, ok := q.readySince[task.ID]
+		if !ok {
+			q.readySince[task.ID] = now
+			continue
+		}
+		if now.Sub(since) < time.Duration(task.PendingTTL)*time.Second {
+			continue
+		}
+
+		log.Debug().Msgf("queue: task %s expired after waiting %s for an agent", task.ID, now.Sub(since))
+		q.pending.Remove(element)
+		delete(q.readySince, task.ID)
+		expired = append(expired, task)
+	}
+	return expired
 }
 
 func (q *fifo) resubmitExpiredPipelines() {
@@ -410,6 +604,7 @@ func (q *fifo) removeFromPending(taskID string) {
 		if task.ID == taskID {
 			log.Debug().Msgf("queue: %s is removed from pending", taskID)
 			q.pending.Remove(element)
+			delete(q.readySince, taskID)
 			return
 		}
 	}
@@ -19,6 +19,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -91,6 +92,36 @@ func TestFifoExpire(t *testing.T) {
 	assert.Len(t, info.Pending, 1, "expect task re-added to pending queue")
 }
 
+func TestFifoLease(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	t.Cleanup(func() { cancel(nil) })
+
+	q, _ := NewMemoryQueue(ctx).(*fifo)
+	assert.NotNil(t, q)
+
+	dummyTask := genDummyTask()
+
+	assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{dummyTask}))
+	waitForProcess()
+
+	got, err := q.Poll(WithLease(ctx, time.Millisecond), 1, filterFnTrue)
+	assert.NoError(t, err)
+	assert.Equal(t, dummyTask, got)
+
+	waitForProcess()
+	info := q.Info(ctx)
+	assert.Len(t, info.Pending, 1, "expect task re-added to pending queue after lease expired")
+
+	got, err = q.Poll(WithLease(ctx, time.Millisecond), 1, filterFnTrue)
+	assert.NoError(t, err)
+	assert.NoError(t, q.Extend(ctx, 1, got.ID))
+
+	waitForProcess()
+	info = q.Info(ctx)
+	assert.Len(t, info.Pending, 0, "expect extended task to keep running")
+	assert.Len(t, info.Running, 1, "expect extended task to keep running")
+}
+
 func TestFifoWait(t *testing.T) {
 	ctx, cancel := context.WithCancelCause(t.Context())
 	t.Cleanup(func() { cancel(nil) })
@@ -662,3 +693,345 @@ func TestFifoWithScoring(t *testing.T) {
 		assert.Contains(t, expectedAgents, agentID, "Task %s should be assigned to one of the expected agents", taskID)
 	}
 }
+
+func TestFifoPriority(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	t.Cleanup(func() { cancel(nil) })
+
+	q, _ := NewMemoryQueue(ctx).(*fifo)
+	assert.NotNil(t, q)
+	q.Pause()
+
+	now := time.Now().Unix()
+	tasks := []*model.Task{
+		{ID: "nightly", Priority: 0, Created: now},
+		{ID: "deploy-1", Priority: 10, Created: now},
+		{ID: "build", Priority: 5, Created: now},
+		{ID: "deploy-2", Priority: 10, Created: now},
+	}
+	assert.NoError(t, q.PushAtOnce(ctx, tasks))
+	q.Resume()
+
+	for _, want := range []string{"deploy-1", "deploy-2", "build", "nightly"} {
+		got, err := q.Poll(ctx, 1, filterFnTrue)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got.ID)
+	}
+}
+
+func TestFifoPriorityAging(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	t.Cleanup(func() { cancel(nil) })
+
+	q, _ := NewMemoryQueue(ctx).(*fifo)
+	assert.NotNil(t, q)
+	q.Pause()
+
+	now := time.Now()
+	tasks := []*model.Task{
+		// waiting for 11 minutes, its priority got raised to 11
+		{ID: "nightly", Priority: 0, Created: now.Add(-11 * time.Minute).Unix()},
+		{ID: "deploy", Priority: 10, Created: now.Unix()},
+	}
+	assert.NoError(t, q.PushAtOnce(ctx, tasks))
+	q.Resume()
+
+	for _, want := range []string{"nightly", "deploy"} {
+		got, err := q.Poll(ctx, 1, filterFnTrue)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got.ID)
+	}
+
+	assert.Equal(t, 10, q.effectivePriority(&model.Task{Priority: 10}, now), "tasks without creation time do not age")
+	assert.Equal(t, 12, q.effectivePriority(&model.Task{Priority: 10, Created: now.Add(-2 * time.Minute).Unix()}, now))
+}
+
+func TestFifoFairScheduling(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	t.Cleanup(func() { cancel(nil) })
+
+	tasks := func() []*model.Task {
+		return []*model.Task{
+			{ID: "noisy-1", RepoID: 1},
+			{ID: "noisy-2", RepoID: 1},
+			{ID: "noisy-3", RepoID: 1},
+			{ID: "noisy-4", RepoID: 1},
+			{ID: "arm-1", RepoID: 3, Labels: map[string]string{"platform": "linux/arm64"}},
+			{ID: "quiet-1", RepoID: 2},
+			{ID: "quiet-2", RepoID: 2},
+		}
+	}
+	// the agent only runs amd64 tasks
+	filter := func(task *model.Task) (bool, int) {
+		return task.Labels["platform"] != "linux/arm64", 1
+	}
+
+	tests := []struct {
+		name string
+		fair bool
+		want []string
+	}{
+		{
+			name: "fifo",
+			want: []string{"noisy-1", "noisy-2", "noisy-3", "noisy-4", "quiet-1", "quiet-2"},
+		},
+		{
+			name: "fair",
+			fair: true,
+			want: []string{"noisy-1", "quiet-1", "noisy-2", "quiet-2", "noisy-3", "noisy-4"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := newMemoryQueue(ctx, Config{FairScheduling: tt.fair})
+			q.Pause()
+			assert.NoError(t, q.PushAtOnce(ctx, tasks()))
+			q.Resume()
+
+			for _, want := range tt.want {
+				got, err := q.Poll(ctx, 1, filter)
+				assert.NoError(t, err)
+				assert.Equal(t, want, got.ID)
+			}
+
+			info := q.Info(ctx)
+			assert.Len(t, info.Pending, 1)
+			assert.Equal(t, "arm-1", info.Pending[0].ID)
+		})
+	}
+
+	t.Run("priority first", func(t *testing.T) {
+		q := newMemoryQueue(ctx, Config{FairScheduling: true})
+		q.Pause()
+		assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{
+			{ID: "noisy-1", RepoID: 1},
+			{ID: "noisy-2", RepoID: 1, Priority: 10},
+			{ID: "quiet-1", RepoID: 2},
+		}))
+		q.Resume()
+
+		for _, want := range []string{"noisy-2", "quiet-1", "noisy-1"} {
+			got, err := q.Poll(ctx, 1, filterFnTrue)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got.ID)
+		}
+	})
+
+	t.Run("with aging", func(t *testing.T) {
+		q := newMemoryQueue(ctx, Config{FairScheduling: true})
+		q.Pause()
+		now := time.Now()
+		created := func(ago time.Duration) int64 { return now.Add(-ago).Unix() }
+		// the noisy repo queued its pipelines long before the quiet one
+		assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{
+			{ID: "noisy-1", RepoID: 1, Created: created(10 * time.Minute)},
+			{ID: "noisy-2", RepoID: 1, Created: created(9 * time.Minute)},
+			{ID: "noisy-3", RepoID: 1, Created: created(8 * time.Minute)},
+			{ID: "noisy-4", RepoID: 1, Created: created(7 * time.Minute)},
+			{ID: "quiet-1", RepoID: 2, Created: created(time.Minute)},
+			{ID: "quiet-2", RepoID: 2, Created: created(time.Minute)},
+		}))
+		q.Resume()
+
+		for _, want := range []string{"noisy-1", "quiet-1", "noisy-2", "quiet-2", "noisy-3", "noisy-4"} {
+			got, err := q.Poll(ctx, 1, filterFnTrue)
+			assert.NoError(t, err)
+			assert.Equal(t, want, got.ID)
+		}
+	})
+}
+
+func TestFifoOrgLimit(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	t.Cleanup(func() { cancel(nil) })
+
+	q := newMemoryQueue(ctx, Config{OrgLimit: func(orgID int64) int {
+		if orgID == 1 {
+			return 1
+		}
+		return 0
+	}})
+	q.Pause()
+	assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{
+		{ID: "org1-pipeline1-a", OrgID: 1, PipelineID: 1},
+		{ID: "org1-pipeline2", OrgID: 1, PipelineID: 2},
+		{ID: "org1-pipeline1-b", OrgID: 1, PipelineID: 1},
+		{ID: "org2-pipeline3", OrgID: 2, PipelineID: 3},
+	}))
+	q.Resume()
+
+	// workflows of the running pipeline and of other orgs are assigned
+	for _, want := range []string{"org1-pipeline1-a", "org1-pipeline1-b", "org2-pipeline3"} {
+		got, err := q.Poll(ctx, 1, filterFnTrue)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got.ID)
+	}
+
+	// the second pipeline of the org stays pending even if an agent is idle
+	pollCtx, pollCancel := context.WithTimeout(ctx, 5*processTimeInterval)
+	defer pollCancel()
+	_, err := q.Poll(pollCtx, 1, filterFnTrue)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	info := q.Info(ctx)
+	assert.Len(t, info.Pending, 1)
+	assert.Equal(t, "org1-pipeline2", info.Pending[0].ID)
+
+	// it is assigned once the running pipeline of the org finished
+	assert.NoError(t, q.Done(ctx, "org1-pipeline1-a", model.StatusSuccess))
+	assert.NoError(t, q.Done(ctx, "org1-pipeline1-b", model.StatusSuccess))
+	got, err := q.Poll(ctx, 1, filterFnTrue)
+	assert.NoError(t, err)
+	assert.Equal(t, "org1-pipeline2", got.ID)
+}
+
+func TestFifoPendingTTL(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	t.Cleanup(func() { cancel(nil) })
+
+	expired := make(chan []*model.Task, 1)
+	q := newMemoryQueue(ctx, Config{Expired: func(tasks []*model.Task) { expired <- tasks }})
+
+	var clock atomic.Int64
+	clock.Store(time.Now().UnixNano())
+	advance := func(d time.Duration) {
+		clock.Add(int64(d))
+		waitForProcess()
+	}
+	q.Lock()
+	q.now = func() time.Time { return time.Unix(0, clock.Load()) }
+	q.Unlock()
+
+	assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{
+		{ID: "stale", PendingTTL: 60, DepStatus: map[string]model.StatusValue{}},
+		{ID: "dependent", PendingTTL: 60, Dependencies: []string{"stale"}, DepStatus: map[string]model.StatusValue{}},
+		{ID: "forever", DepStatus: map[string]model.StatusValue{}},
+	}))
+	waitForProcess()
+
+	advance(59 * time.Second)
+	assert.Empty(t, expired)
+	assert.Len(t, q.Info(ctx).Pending, 2)
+
+	advance(2 * time.Second)
+	if assert.Len(t, expired, 1) {
+		tasks := <-expired
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, "stale", tasks[0].ID)
+	}
+
+	// the ttl of the dependent task only starts once its dependency is gone
+	advance(59 * time.Second)
+	assert.Empty(t, expired)
+	advance(2 * time.Second)
+	if assert.Len(t, expired, 1) {
+		tasks := <-expired
+		assert.Len(t, tasks, 1)
+		assert.Equal(t, "dependent", tasks[0].ID)
+		assert.Equal(t, model.StatusError, tasks[0].DepStatus["stale"])
+	}
+
+	// tasks without a ttl never expire
+	advance(time.Hour)
+	assert.Empty(t, expired)
+	info := q.Info(ctx)
+	assert.Len(t, info.Pending, 1)
+	assert.Equal(t, "forever", info.Pending[0].ID)
+}
+
+func TestFifoExpirePendingStartsDependentTTL(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	t.Cleanup(func() { cancel(nil) })
+
+	q := newMemoryQueue(ctx, Config{Expired: func([]*model.Task) {}})
+	q.Lock()
+	q.paused = true
+	q.Unlock()
+	assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{
+		{ID: "stale", PendingTTL: 60, DepStatus: map[string]model.StatusValue{}},
+		{ID: "dependent", PendingTTL: 60, Dependencies: []string{"stale"}, DepStatus: map[string]model.StatusValue{}},
+	}))
+
+	q.Lock()
+	defer q.Unlock()
+	start := time.Now()
+	q.filterWaiting()
+	q.now = func() time.Time { return start }
+	assert.Empty(t, q.expirePending())
+
+	// the dependent gets ready in the same pass its dependency expires in
+	now := start.Add(61 * time.Second)
+	q.now = func() time.Time { return now }
+	expired := q.expirePending()
+	if assert.Len(t, expired, 1) {
+		assert.Equal(t, "stale", expired[0].ID)
+	}
+	assert.Equal(t, 1, q.pending.Len())
+	assert.Equal(t, now, q.readySince["dependent"])
+}
+
+func TestFifoResources(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	t.Cleanup(func() { cancel(nil) })
+
+	q := newMemoryQueue(ctx, Config{})
+	assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{{ID: "big", Resources: model.Resources{CPU: 4000, Memory: 4 << 30}}}))
+
+	// the small agent has not enough resources left for the task
+	small := WithResources(ctx, model.Resources{CPU: 2000, Memory: 8 << 30}, model.Resources{})
+	pollCtx, pollCancel := context.WithTimeout(small, 5*processTimeInterval)
+	defer pollCancel()
+	_, err := q.Poll(pollCtx, 1, filterFnTrue)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// neither has a big agent which is busy with other workflows
+	busy := WithResources(ctx, model.Resources{CPU: 8000, Memory: 16 << 30}, model.Resources{CPU: 6000})
+	busyCtx, busyCancel := context.WithTimeout(busy, 5*processTimeInterval)
+	defer busyCancel()
+	_, err = q.Poll(busyCtx, 2, filterFnTrue)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	large := WithResources(ctx, model.Resources{CPU: 8000, Memory: 16 << 30}, model.Resources{CPU: 1000})
+	got, err := q.Poll(large, 3, filterFnTrue)
+	assert.NoError(t, err)
+	assert.Equal(t, "big", got.ID)
+}
+
+func TestFifoResourcesConcurrentPolls(t *testing.T) {
+	ctx, cancel := context.WithCancelCause(t.Context())
+	t.Cleanup(func() { cancel(nil) })
+
+	q := newMemoryQueue(ctx, Config{})
+	q.Pause()
+	assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{
+		{ID: "1", Resources: model.Resources{CPU: 3000}},
+		{ID: "2", Resources: model.Resources{CPU: 3000}},
+		{ID: "3", Resources: model.Resources{CPU: 3000}},
+	}))
+
+	// all workers of the agent poll with the same health report, which does not include the assigned tasks yet
+	resources := WithResources(ctx, model.Resources{CPU: 8000}, model.Resources{})
+	pollCtx, pollCancel := context.WithTimeout(resources, 5*processTimeInterval)
+	defer pollCancel()
+	var wg sync.WaitGroup
+	var assigned atomic.Int32
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := q.Poll(pollCtx, 1, filterFnTrue); err == nil {
+				assigned.Add(1)
+			}
+		}()
+	}
+	assert.Eventually(t, func() bool {
+		q.Lock()
+		defer q.Unlock()
+		return len(q.workers) == 3
+	}, time.Second, time.Millisecond)
+	q.Resume()
+	wg.Wait()
+
+	assert.EqualValues(t, 2, assigned.Load(), "the agent only has cpu for two tasks")
+	assert.Len(t, q.Info(ctx).Pending, 1)
+}
@@ -0,0 +1,159 @@
+// Copyright 2022 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	pipelineConsts "go.woodpecker-ci.org/woodpecker/v3/pipeline"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+// NewLabelFilter returns a filter which matches the tasks an agent with the given labels can run.
+func NewLabelFilter(agentLabels map[string]string) FilterFn {
+	return func(task *model.Task) (bool, int) {
+		// Create a copy of the labels for filtering to avoid modifying the original task
+		labels := maps.Clone(task.Labels)
+
+		if requiredLabelsMissing(labels, agentLabels) {
+			return false, 0
+		}
+
+		// ignore internal labels for filtering
+		for k := range labels {
+			if strings.HasPrefix(k, pipelineConsts.InternalLabelPrefix) {
+				delete(labels, k)
+			}
+		}
+
+		score := 0
+		for taskLabel, taskLabelValue := range labels {
+			// if a task label is empty it will be ignored
+			if taskLabelValue == "" {
+				continue
+			}
+
+			agentValue, ok := agentLabelValue(agentLabels, taskLabel)
+			matched, labelScore := parseLabelSelector(taskLabelValue).match(agentValue, ok)
+			if !matched {
+				return false, 0
+			}
+			score += labelScore
+		}
+		return true, score
+	}
+}
+
+// agentResources are the cpu and memory an agent reported in its last health report.
+type agentResources struct {
+	capacity model.Resources
+	usage    model.Resources
+}
+
+// fits reports whether the request fits into the resources the agent has left. The requests of the tasks already
+// running on the agent are reserved, as long as the agent did not report a higher usage, so concurrent polls
+// can not assign more than the agent has. Resources the agent does not report are not checked.
+func (r *agentResources) fits(request, reserved model.Resources) bool {
+	return resourceFits(request.CPU, r.capacity.CPU, max(r.usage.CPU, reserved.CPU)) &&
+		resourceFits(request.Memory, r.capacity.Memory, max(r.usage.Memory, reserved.Memory))
+}
+
+func resourceFits(request, capacity, usage int64) bool {
+	return request == 0 || capacity == 0 || request <= capacity-usage
+}
+
+// UnmatchedLabels returns the labels of the task no agent with one of the given label sets could ever satisfy,
+// formatted as "key=value". It returns nil if at least one agent can run the task. If every label is satisfied by
+// some agent but no agent satisfies all of them, all labels of the task are returned, except the repo and org labels
+// set by the server if there are others.
+func UnmatchedLabels(task *model.Task, agentsLabels []map[string]string) []string {
+	var selectable []string
+	for label, value := range task.Labels {
+		if value != "" && !strings.HasPrefix(label, pipelineConsts.InternalLabelPrefix) {
+			selectable = append(selectable, label)
+		}
+	}
+
+	unmatched := make(map[string]bool, len(selectable))
+	for _, label := range selectable {
+		unmatched[label] = true
+	}
+	for _, agentLabels := range agentsLabels {
+		if matched, _ := NewLabelFilter(agentLabels)(task); matched {
+			return nil
+		}
+		for _, label := range selectable {
+			value, ok := agentLabelValue(agentLabels, label)
+			if matched, _ := parseLabelSelector(task.Labels[label]).match(value, ok); matched {
+				delete(unmatched, label)
+			}
+		}
+	}
+
+	if len(unmatched) == 0 {
+		for _, label := range selectable {
+			if label != pipelineConsts.LabelFilterRepo && label != pipelineConsts.LabelFilterOrg {
+				unmatched[label] = true
+			}
+		}
+	}
+	if len(unmatched) == 0 {
+		for _, label := range selectable {
+			unmatched[label] = true
+		}
+	}
+
+	var labels []string
+	for label := range unmatched {
+		labels = append(labels, fmt.Sprintf("%s=%s", label, task.Labels[label]))
+	}
+	slices.Sort(labels)
+	return labels
+}
+
+// agentLabelValue returns the value of an agent label, which might be marked as required by an agent.
+func agentLabelValue(agentLabels map[string]string, label string) (string, bool) {
+	value, ok := agentLabels[label]
+	if !ok {
+		// Check for required label
+		value, ok = agentLabels["!"+label]
+	}
+	return value, ok
+}
+
+func requiredLabelsMissing(taskLabels, agentLabels map[string]string) bool {
+	for label, value := range agentLabels {
+		if len(label) > 0 && label[0] == '!' {
+			val, ok := taskLabels[label[1:]]
+			if !ok || !requiredLabelMatches(val, value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requiredLabelMatches returns whether the task label value satisfies a label the agent requires.
+func requiredLabelMatches(taskValue, agentValue string) bool {
+	selector := parseLabelSelector(taskValue)
+	if selector.op == labelEquals {
+		return taskValue == agentValue
+	}
+	matched, _ := selector.match(agentValue, true)
+	return matched
+}
@@ -0,0 +1,411 @@
+// Copyright 2022 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pipelineConsts "go.woodpecker-ci.org/woodpecker/v3/pipeline"
+	"go.woodpecker-ci.org/woodpecker/v3/pipeline/rpc"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+func TestCreateFilterFunc(t *testing.T) {
+	tests := []struct {
+		name        string
+		agentFilter rpc.Filter
+		task        *model.Task
+		wantMatched bool
+		wantScore   int
+	}{
+		{
+			name: "Two exact matches",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"org-id": "123", "platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"org-id": "123", "platform": "linux"},
+			},
+			wantMatched: true,
+			wantScore:   20,
+		},
+		{
+			name: "Wildcard and exact match",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"org-id": "*", "platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"org-id": "123", "platform": "linux"},
+			},
+			wantMatched: true,
+			wantScore:   11,
+		},
+		{
+			name: "Partial match",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"org-id": "123", "platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"org-id": "123", "platform": "windows"},
+			},
+			wantMatched: false,
+			wantScore:   0,
+		},
+		{
+			name: "No match",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"org-id": "456", "platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"org-id": "123", "platform": "windows"},
+			},
+			wantMatched: false,
+			wantScore:   0,
+		},
+		{
+			name: "Missing label",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"needed": "some"},
+			},
+			wantMatched: false,
+			wantScore:   0,
+		},
+		{
+			name: "Empty task labels",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"org-id": "123", "platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{},
+			},
+			wantMatched: true,
+			wantScore:   0,
+		},
+		{
+			name: "Agent with additional label",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"org-id": "123", "platform": "linux", "extra": "value"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"org-id": "123", "platform": "linux", "empty": ""},
+			},
+			wantMatched: true,
+			wantScore:   20,
+		},
+		{
+			name: "Two wildcard matches",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"org-id": "*", "platform": "*"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"org-id": "123", "platform": "linux"},
+			},
+			wantMatched: true,
+			wantScore:   2,
+		},
+		{
+			name: "Required label matches without shebang",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"!org-id": "123", "platform": "linux", "extra": "value"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"org-id": "123", "platform": "linux", "empty": ""},
+			},
+			wantMatched: true,
+			wantScore:   20,
+		},
+		{
+			name: "In selector matches",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"gpu": "a100", "platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"gpu": "in (h100, a100)", "platform": "linux"},
+			},
+			wantMatched: true,
+			wantScore:   20,
+		},
+		{
+			name: "In selector does not match",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"gpu": "t4"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"gpu": "in (h100,a100)"},
+			},
+			wantMatched: false,
+			wantScore:   0,
+		},
+		{
+			name: "Not equal selector excludes agent",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"spot": "true", "platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"spot": "!= true", "platform": "linux"},
+			},
+			wantMatched: false,
+			wantScore:   0,
+		},
+		{
+			name: "Not equal selector matches agent without label",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"spot": "!= true", "platform": "linux"},
+			},
+			wantMatched: true,
+			wantScore:   10,
+		},
+		{
+			name: "Not in selector matches other value",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"zone": "eu-1"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"zone": "notin (us-1,us-2)"},
+			},
+			wantMatched: true,
+			wantScore:   1,
+		},
+		{
+			name: "Exists selector matches any value",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"gpu": "a100"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"gpu": "exists"},
+			},
+			wantMatched: true,
+			wantScore:   1,
+		},
+		{
+			name: "Exists selector requires label",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"platform": "linux"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"gpu": "exists"},
+			},
+			wantMatched: false,
+			wantScore:   0,
+		},
+		{
+			name: "Not exists selector excludes agent with label",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"spot": "*"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"spot": "!exists"},
+			},
+			wantMatched: false,
+			wantScore:   0,
+		},
+		{
+			name: "Combined selectors",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"gpu": "a100", "platform": "linux", "zone": "*"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"gpu": "in (a100)", "platform": "linux", "spot": "!exists", "zone": "!= us-1"},
+			},
+			wantMatched: true,
+			wantScore:   21,
+		},
+		{
+			name: "Required label matches selector",
+			agentFilter: rpc.Filter{
+				Labels: map[string]string{"!gpu": "a100"},
+			},
+			task: &model.Task{
+				Labels: map[string]string{"gpu": "exists"},
+			},
+			wantMatched: true,
+			wantScore:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filterFunc := NewLabelFilter(tt.agentFilter.Labels)
+			gotMatched, gotScore := filterFunc(tt.task)
+
+			assert.Equal(t, tt.wantMatched, gotMatched, "Matched result")
+			assert.Equal(t, tt.wantScore, gotScore, "Score")
+		})
+	}
+}
+
+func TestMissingRequiredLabels(t *testing.T) {
+	t.Parallel()
+
+	testdata := []struct {
+		taskLabels     map[string]string
+		requiredLabels map[string]string
+		want           bool
+	}{
+		// Required label present and matches
+		{
+			taskLabels:     map[string]string{"os": "linux"},
+			requiredLabels: map[string]string{"!os": "linux", "platform": "arm64"},
+			want:           false,
+		},
+		// Required label present but does not match
+		{
+			taskLabels:     map[string]string{"os": "windows"},
+			requiredLabels: map[string]string{"!os": "linux", "platform": "amd64"},
+			want:           true,
+		},
+		// Required label missing
+		{
+			taskLabels:     map[string]string{"arch": "amd64"},
+			requiredLabels: map[string]string{"!os": "linux"},
+			want:           true,
+		},
+		// No agent labels
+		{
+			taskLabels:     map[string]string{"os": "linux"},
+			requiredLabels: map[string]string{},
+			want:           false,
+		},
+	}
+
+	for _, tt := range testdata {
+		if got := requiredLabelsMissing(tt.taskLabels, tt.requiredLabels); got != tt.want {
+			t.Errorf("requiredLabelsMissing(%v, %v) = %v, want %v", tt.taskLabels, tt.requiredLabels, got, tt.want)
+		}
+	}
+}
+
+func TestUnmatchedLabels(t *testing.T) {
+	t.Parallel()
+
+	agents := []map[string]string{
+		{"platform": "linux/amd64", "backend": "docker", "repo": "*", "org-id": "*"},
+		{"platform": "linux/arm64", "backend": "docker", "gpu": "true", "repo": "*", "org-id": "*"},
+		{"platform": "windows/amd64", "backend": "local", "repo": "*", "org-id": "*"},
+	}
+
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   []string
+	}{
+		{
+			name:   "match",
+			labels: map[string]string{"platform": "linux/arm64", "gpu": "true", "repo": "octo/repo", "org-id": "1"},
+			want:   nil,
+		},
+		{
+			name:   "internal and empty labels are ignored",
+			labels: map[string]string{"gpu": "", pipelineConsts.LabelRepoID: "1"},
+			want:   nil,
+		},
+		{
+			name:   "partial match",
+			labels: map[string]string{"platform": "linux/amd64", "gpu": "true"},
+			want:   []string{"gpu=true", "platform=linux/amd64"},
+		},
+		{
+			name:   "no match",
+			labels: map[string]string{"platform": "linux/amd64", "gpu": "false"},
+			want:   []string{"gpu=false"},
+		},
+		{
+			name:   "no match for selector",
+			labels: map[string]string{"backend": "in (kubernetes,podman)"},
+			want:   []string{"backend=in (kubernetes,podman)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, UnmatchedLabels(&model.Task{Labels: tt.labels}, agents))
+		})
+	}
+
+	assert.Equal(t, []string{"gpu=true"}, UnmatchedLabels(&model.Task{Labels: map[string]string{"gpu": "true"}}, nil))
+}
+
+func TestAgentResourcesFits(t *testing.T) {
+	t.Parallel()
+
+	capacity := model.Resources{CPU: 4000, Memory: 8 << 30}
+	usage := model.Resources{CPU: 1000, Memory: 2 << 30}
+
+	tests := []struct {
+		name      string
+		resources agentResources
+		request   model.Resources
+		reserved  model.Resources
+		want      bool
+	}{
+		{
+			name:      "no resource request",
+			resources: agentResources{capacity: capacity, usage: usage},
+			want:      true,
+		},
+		{
+			name:      "request fits",
+			resources: agentResources{capacity: capacity, usage: usage},
+			request:   model.Resources{CPU: 3000, Memory: 6 << 30},
+			want:      true,
+		},
+		{
+			name:      "cpu does not fit",
+			resources: agentResources{capacity: capacity, usage: usage},
+			request:   model.Resources{CPU: 3001},
+			want:      false,
+		},
+		{
+			name:      "memory does not fit",
+			resources: agentResources{capacity: capacity, usage: usage},
+			request:   model.Resources{Memory: 7 << 30},
+			want:      false,
+		},
+		{
+			name:      "running tasks reserve their requests",
+			resources: agentResources{capacity: capacity, usage: usage},
+			request:   model.Resources{CPU: 2000},
+			reserved:  model.Resources{CPU: 3000},
+			want:      false,
+		},
+		{
+			name:      "reported usage includes the running tasks",
+			resources: agentResources{capacity: capacity, usage: usage},
+			request:   model.Resources{CPU: 3000},
+			reserved:  model.Resources{CPU: 500},
+			want:      true,
+		},
+		{
+			name:      "agent without resource reporting",
+			resources: agentResources{},
+			request:   model.Resources{CPU: 64000, Memory: 256 << 30},
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.resources.fits(tt.request, tt.reserved))
+		})
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"slices"
+	"strings"
+)
+
+type labelOperator int
+
+const (
+	labelEquals labelOperator = iota
+	labelNotEquals
+	labelIn
+	labelNotIn
+	labelExists
+	labelNotExists
+)
+
+const (
+	scoreWildcard = 1
+	scoreExact    = 10
+)
+
+// labelSelector is the parsed value of a workflow label which selects the agents.
+type labelSelector struct {
+	op     labelOperator
+	values []string
+}
+
+// parseLabelSelector parses the value of a workflow label:
+//   - "v" requires the agent label to be v
+//   - "!= v" requires the agent label to be missing or not v
+//   - "in (a,b)" requires the agent label to be one of the values
+//   - "notin (a,b)" requires the agent label to be missing or none of the values
+//   - "exists" requires the agent label to be present with any value
+//   - "!exists" requires the agent label to be missing
+//
+// Values which can not be parsed as an operator are compared as plain value.
+func parseLabelSelector(value string) labelSelector {
+	trimmed := strings.TrimSpace(value)
+	switch {
+	case trimmed == "exists":
+		return labelSelector{op: labelExists}
+	case trimmed == "!exists":
+		return labelSelector{op: labelNotExists}
+	case strings.HasPrefix(trimmed, "!="):
+		return labelSelector{op: labelNotEquals, values: []string{strings.TrimSpace(trimmed[2:])}}
+	}
+
+	if rest, ok := strings.CutPrefix(trimmed, "in"); ok {
+		if values, ok := parseLabelValueList(rest); ok {
+			return labelSelector{op: labelIn, values: values}
+		}
+	}
+	if rest, ok := strings.CutPrefix(trimmed, "notin"); ok {
+		if values, ok := parseLabelValueList(rest); ok {
+			return labelSelector{op: labelNotIn, values: values}
+		}
+	}
+
+	return labelSelector{op: labelEquals, values: []string{value}}
+}
+
+// parseLabelValueList parses a comma separated list of values in parentheses.
+func parseLabelValueList(s string) ([]string, bool) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return nil, false
+	}
+
+	var values []string
+	for v := range strings.SplitSeq(s[1:len(s)-1], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values, len(values) > 0
+}
+
+// match returns whether the agent label satisfies the selector and the score of the match.
+// exists is false if the agent does not have the label, an agent label of "*" matches any value.
+func (s labelSelector) match(agentValue string, exists bool) (bool, int) {
+	switch s.op {
+	case labelExists:
+		return exists, scoreWildcard
+	case labelNotExists:
+		return !exists, 0
+	case labelNotEquals, labelNotIn:
+		if !exists {
+			return true, 0
+		}
+		if agentValue != "*" && slices.Contains(s.values, agentValue) {
+			return false, 0
+		}
+		return true, scoreWildcard
+	default:
+		if !exists {
+			return false, 0
+		}
+		if agentValue == "*" {
+			return true, scoreWildcard
+		}
+		if slices.Contains(s.values, agentValue) {
+			return true, scoreExact
+		}
+		return false, 0
+	}
+}
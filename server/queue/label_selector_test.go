@@ -0,0 +1,46 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLabelSelector(t *testing.T) {
+	tests := []struct {
+		value string
+		want  labelSelector
+	}{
+		{value: "linux", want: labelSelector{op: labelEquals, values: []string{"linux"}}},
+		{value: "!= true", want: labelSelector{op: labelNotEquals, values: []string{"true"}}},
+		{value: "!=true", want: labelSelector{op: labelNotEquals, values: []string{"true"}}},
+		{value: "in (a, b)", want: labelSelector{op: labelIn, values: []string{"a", "b"}}},
+		{value: "in(a)", want: labelSelector{op: labelIn, values: []string{"a"}}},
+		{value: "notin (a,b)", want: labelSelector{op: labelNotIn, values: []string{"a", "b"}}},
+		{value: "exists", want: labelSelector{op: labelExists}},
+		{value: " !exists ", want: labelSelector{op: labelNotExists}},
+		// no operator, the value is compared as it is
+		{value: "in ()", want: labelSelector{op: labelEquals, values: []string{"in ()"}}},
+		{value: "india", want: labelSelector{op: labelEquals, values: []string{"india"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			assert.Equal(t, tt.want, parseLabelSelector(tt.value))
+		})
+	}
+}
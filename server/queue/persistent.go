@@ -39,6 +39,51 @@ type persistentQueue struct {
 	store store.Store
 }
 
+// Persister is implemented by queues backed by a store.
+type Persister interface {
+	// Persist saves the pending tasks to the store, so they are restored on the next start.
+	Persist(c context.Context) error
+}
+
+// Persist saves the pending tasks which are missing in the store,
+// e.g. tasks which were handed out again after their deadline expired.
+func (q *persistentQueue) Persist(c context.Context) error {
+	stored, err := q.store.TaskList()
+	if err != nil {
+		return err
+	}
+	storedIDs := make(map[string]struct{}, len(stored))
+	for _, task := range stored {
+		storedIDs[task.ID] = struct{}{}
+	}
+
+	info := q.Info(c)
+	for _, task := range append(info.Pending, info.WaitingOnDeps...) {
+		if _, ok := storedIDs[task.ID]; ok {
+			continue
+		}
+		if err := q.store.TaskInsert(task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteExpiredTasks removes the expired tasks from the store before passing them on,
+// so they are not restored on the next start.
+func deleteExpiredTasks(s store.Store, expired ExpiredFn) ExpiredFn {
+	return func(tasks []*model.Task) {
+		for _, task := range tasks {
+			if err := s.TaskDelete(task.ID); err != nil {
+				log.Error().Err(err).Msgf("expired queue item: %s: failed to remove from backup", task.ID)
+			}
+		}
+		if expired != nil {
+			expired(tasks)
+		}
+	}
+}
+
 // PushAtOnce pushes multiple tasks to the tail of this queue.
 func (q *persistentQueue) PushAtOnce(c context.Context, tasks []*model.Task) error {
 	// TODO: invent store.NewSession who return context including a session and make TaskInsert & TaskDelete use it
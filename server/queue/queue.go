@@ -19,8 +19,10 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 )
 
@@ -67,11 +69,44 @@ func (t *InfoT) String() string {
 	return sb.String()
 }
 
+type leaseKey struct{}
+
+// WithLease returns a context which makes Poll reserve the polled task only
+// for the given lease. The task is handed out again if it is not extended in time.
+func WithLease(ctx context.Context, lease time.Duration) context.Context {
+	return context.WithValue(ctx, leaseKey{}, lease)
+}
+
+func leaseFromContext(ctx context.Context) time.Duration {
+	lease, _ := ctx.Value(leaseKey{}).(time.Duration)
+	return lease
+}
+
+type resourcesKey struct{}
+
+// WithResources returns a context which makes Poll only return tasks whose resource requests fit into the
+// resources the agent has left, see agentResources.
+func WithResources(ctx context.Context, capacity, usage model.Resources) context.Context {
+	return context.WithValue(ctx, resourcesKey{}, &agentResources{capacity: capacity, usage: usage})
+}
+
+func resourcesFromContext(ctx context.Context) *agentResources {
+	resources, _ := ctx.Value(resourcesKey{}).(*agentResources)
+	return resources
+}
+
 // FilterFn filters tasks in the queue. If the Filter returns false,
 // the Task is skipped and not returned to the subscriber.
 // The int return value represents the matching score (higher is better).
 type FilterFn func(*model.Task) (bool, int)
 
+// OrgLimitFn returns how many pipelines of the org may run at once, 0 means unlimited.
+type OrgLimitFn func(orgID int64) int
+
+// ExpiredFn is called with the tasks which were removed from the queue,
+// as they waited for an agent longer than their pending ttl.
+type ExpiredFn func(tasks []*model.Task)
+
 // Queue defines a task queue for scheduling tasks among
 // a pool of workers.
 type Queue interface {
@@ -112,10 +147,30 @@ type Queue interface {
 	KickAgentWorkers(agentID int64)
 }
 
+// Shared is implemented by queues shared by several servers, whose Pause stops the queue on all servers.
+type Shared interface {
+	// PauseLocal stops handing out tasks to the agents polling this server.
+	PauseLocal()
+
+	// RunningLocal returns the number of running tasks handed out by this server.
+	RunningLocal() int
+}
+
 // Config holds the configuration for the queue.
 type Config struct {
 	Backend Type
 	Store   store.Store
+	// FairScheduling assigns tasks of the same priority round-robin across repos,
+	// so a single repo with many pipelines can not starve the other repos.
+	FairScheduling bool
+	// OrgLimit caps the running pipelines per org, tasks of an org at its limit stay pending.
+	OrgLimit OrgLimitFn
+	// Expired is called with the tasks which expired, so their workflows can be failed.
+	Expired ExpiredFn
+	// Redis is the redis server of the redis backend.
+	Redis redis.Config
+	// RedisPrefix is prepended to the redis keys of the queue, servers sharing a queue must use the same prefix.
+	RedisPrefix string
 }
 
 // Queue type.
@@ -123,6 +178,7 @@ type Type string
 
 const (
 	TypeMemory Type = "memory"
+	TypeRedis  Type = "redis"
 )
 
 // New creates a new queue based on the provided configuration.
@@ -131,10 +187,19 @@ func New(ctx context.Context, config Config) (Queue, error) {
 
 	switch config.Backend {
 	case TypeMemory:
-		q = NewMemoryQueue(ctx)
+		if config.Store != nil {
+			config.Expired = deleteExpiredTasks(config.Store, config.Expired)
+		}
+		q = newMemoryQueue(ctx, config)
 		if config.Store != nil {
 			q = WithTaskStore(ctx, q, config.Store)
 		}
+	case TypeRedis:
+		// redis persists the tasks itself, restoring them from the store would push them again on each start
+		var err error
+		if q, err = newRedisQueue(ctx, config); err != nil {
+			return nil, err
+		}
 	default:
 		return nil, fmt.Errorf("unsupported queue backend: %s", config.Backend)
 	}
@@ -0,0 +1,570 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"cmp"
+	"container/list"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
+)
+
+const (
+	defaultRedisPrefix = "woodpecker:queue"
+	// redisDoneTTL is how long the result of a finished task is kept for its dependents and waiters.
+	redisDoneTTL = 24 * time.Hour
+)
+
+// redisTask is a task as stored in redis.
+type redisTask struct {
+	// Seq is the position in the queue, tasks with the same priority are assigned in this order.
+	Seq  int64       `json:"seq"`
+	Task *model.Task `json:"task"`
+	// Data is the workflow of the task, which is not part of its json encoding.
+	Data []byte `json:"data"`
+	// Deadline is when the lease of a running task ends, in unix milliseconds.
+	Deadline int64 `json:"deadline,omitempty"`
+}
+
+// redisResult is stored when a task finished.
+type redisResult struct {
+	Status model.StatusValue `json:"status"`
+	Error  string            `json:"error,omitempty"`
+}
+
+// redisQueue keeps the tasks in redis, so they survive restarts and are shared by all servers connected
+// to the same redis. The keys below the prefix are:
+//   - pending: a hash of the pending tasks by id
+//   - running: a hash of the tasks handed out to agents by id, claimed with HSETNX so only one server hands a task out
+//   - deadlines: a hash of the extended leases of running tasks by id
+//   - done:<id>: the result of a finished task, used for the dependency status of its dependents and by Wait
+//   - paused: set while the queue is paused
+//   - seq: the counter for the queue positions
+//
+// Agents poll the server they are connected to. Each server schedules the tasks from redis to its own
+// workers using the same rules as the memory queue, a running task whose lease expired is pending again.
+type redisQueue struct {
+	*fifo
+	client *redis.Client
+	prefix string
+	// handedOut holds the running tasks handed out by this server.
+	handedOut map[string]struct{}
+}
+
+func newRedisQueue(ctx context.Context, config Config) (*redisQueue, error) {
+	client, err := redis.NewClient(ctx, config.Redis)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to redis: %w", err)
+	}
+	prefix := config.RedisPrefix
+	if prefix == "" {
+		prefix = defaultRedisPrefix
+	}
+
+	q := &redisQueue{
+		fifo:      newFifo(ctx, config),
+		client:    client,
+		prefix:    prefix,
+		handedOut: map[string]struct{}{},
+	}
+	context.AfterFunc(ctx, client.Close)
+	go q.process()
+	return q, nil
+}
+
+func (q *redisQueue) key(name string) string {
+	return q.prefix + ":" + name
+}
+
+// PushAtOnce pushes multiple tasks to the tail of this queue.
+func (q *redisQueue) PushAtOnce(c context.Context, tasks []*model.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	last, err := redisInt(q.client.Do(c, "INCRBY", q.key("seq"), strconv.Itoa(len(tasks))))
+	if err != nil {
+		return err
+	}
+
+	args := []string{"HSET", q.key("pending")}
+	for i, task := range tasks {
+		data, err := json.Marshal(&redisTask{Seq: last - int64(len(tasks)-i), Task: task, Data: task.Data})
+		if err != nil {
+			return err
+		}
+		args = append(args, task.ID, string(data))
+	}
+	_, err = q.client.Do(c, args...)
+	return err
+}
+
+// Done signals the task is complete.
+func (q *redisQueue) Done(c context.Context, id string, exitStatus model.StatusValue) error {
+	return q.finished(c, []string{id}, exitStatus, nil)
+}
+
+// Error signals the task is done with an error.
+func (q *redisQueue) Error(c context.Context, id string, err error) error {
+	return q.finished(c, []string{id}, model.StatusFailure, err)
+}
+
+// ErrorAtOnce signals multiple done are complete with an error.
+func (q *redisQueue) ErrorAtOnce(c context.Context, ids []string, err error) error {
+	return q.finished(c, ids, model.StatusFailure, err)
+}
+
+func (q *redisQueue) finished(c context.Context, ids []string, exitStatus model.StatusValue, err error) error {
+	result := redisResult{Status: exitStatus}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	data, _ := json.Marshal(&result)
+
+	for _, id := range ids {
+		// the result is stored first, so waiters find it once the task is not running anymore
+		if _, err := q.client.Do(c, "SET", q.key("done:"+id), string(data), "EX", strconv.Itoa(int(redisDoneTTL.Seconds()))); err != nil {
+			return err
+		}
+		for _, name := range []string{"running", "pending", "deadlines"} {
+			if _, err := q.client.Do(c, "HDEL", q.key(name), id); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// EvictAtOnce removes multiple pending tasks from the queue.
+func (q *redisQueue) EvictAtOnce(c context.Context, ids []string) error {
+	deleted, err := redisInt(q.client.Do(c, append([]string{"HDEL", q.key("pending")}, ids...)...))
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Wait waits until the task is complete.
+func (q *redisQueue) Wait(c context.Context, id string) error {
+	running, err := q.isRunning(c, id)
+	if err != nil || !running {
+		return err
+	}
+
+	for {
+		select {
+		case <-c.Done():
+			return nil
+		case <-time.After(processTimeInterval):
+		}
+
+		if running, err = q.isRunning(c, id); err != nil {
+			return err
+		}
+		if running {
+			continue
+		}
+
+		reply, err := q.client.Do(c, "GET", q.key("done:"+id))
+		if err != nil || reply == nil {
+			// the lease expired and the task is pending again
+			return err
+		}
+		var result redisResult
+		if err := json.Unmarshal([]byte(reply.(string)), &result); err != nil {
+			return err
+		}
+		switch result.Error {
+		case "":
+			return nil
+		case ErrCancel.Error():
+			return ErrCancel
+		default:
+			return errors.New(result.Error)
+		}
+	}
+}
+
+func (q *redisQueue) isRunning(c context.Context, id string) (bool, error) {
+	exists, err := redisInt(q.client.Do(c, "HEXISTS", q.key("running"), id))
+	return exists == 1, err
+}
+
+// Extend extends the deadline for a task.
+func (q *redisQueue) Extend(c context.Context, agentID int64, id string) error {
+	reply, err := q.client.Do(c, "HGET", q.key("running"), id)
+	if err != nil {
+		return err
+	}
+	if reply == nil {
+		return ErrNotFound
+	}
+	var running redisTask
+	if err := json.Unmarshal([]byte(reply.(string)), &running); err != nil {
+		return err
+	}
+	if running.Task.AgentID != agentID {
+		return ErrAgentMissMatch
+	}
+
+	// the running entry is not updated, so a task which finished in between can't be restored
+	deadline := time.Now().Add(q.extension).UnixMilli()
+	_, err = q.client.Do(c, "HSET", q.key("deadlines"), id, strconv.FormatInt(deadline, 10))
+	return err
+}
+
+// Info returns internal queue information. The worker count only includes the workers
+// polling this server.
+func (q *redisQueue) Info(c context.Context) InfoT {
+	var stats InfoT
+	snapshot, err := q.load(c)
+	if err != nil {
+		log.Error().Err(err).Msg("queue: could not load tasks from redis")
+		return stats
+	}
+
+	for _, task := range snapshot.pending {
+		if snapshot.waitsOnDeps(task.Task) {
+			stats.WaitingOnDeps = append(stats.WaitingOnDeps, task.Task)
+		} else {
+			stats.Pending = append(stats.Pending, task.Task)
+		}
+	}
+	for _, task := range snapshot.running {
+		stats.Running = append(stats.Running, task.Task)
+	}
+	stats.Paused = snapshot.paused
+
+	q.Lock()
+	stats.Stats.Workers = len(q.workers)
+	q.Unlock()
+	stats.Stats.Pending = len(stats.Pending)
+	stats.Stats.WaitingOnDeps = len(stats.WaitingOnDeps)
+	stats.Stats.Running = len(stats.Running)
+	return stats
+}
+
+// Pause stops all servers from handing out new work items in Poll.
+func (q *redisQueue) Pause() {
+	if _, err := q.client.Do(q.ctx, "SET", q.key("paused"), "1"); err != nil {
+		log.Error().Err(err).Msg("queue: could not pause")
+	}
+}
+
+// Resume starts the queue again.
+func (q *redisQueue) Resume() {
+	if _, err := q.client.Do(q.ctx, "DEL", q.key("paused")); err != nil {
+		log.Error().Err(err).Msg("queue: could not resume")
+	}
+}
+
+// PauseLocal stops handing out tasks to the agents polling this server, e.g. while it is drained.
+func (q *redisQueue) PauseLocal() {
+	q.Lock()
+	q.paused = true
+	q.Unlock()
+}
+
+// RunningLocal returns the number of running tasks handed out by this server.
+func (q *redisQueue) RunningLocal() int {
+	q.Lock()
+	defer q.Unlock()
+	return len(q.handedOut)
+}
+
+// redisSnapshot holds the state of the queue loaded from redis.
+type redisSnapshot struct {
+	paused  bool
+	pending []*redisTask
+	running map[string]*redisTask
+}
+
+// load reads the queue from redis. Pending tasks which are also running, as a server stopped while
+// handing them out, are only returned as running. The dependency status of the pending tasks is
+// set from the results of their finished dependencies.
+func (q *redisQueue) load(c context.Context) (*redisSnapshot, error) {
+	snapshot := &redisSnapshot{running: map[string]*redisTask{}}
+
+	paused, err := q.client.Do(c, "GET", q.key("paused"))
+	if err != nil {
+		return nil, err
+	}
+	snapshot.paused = paused != nil
+
+	running, err := q.hash(c, "running")
+	if err != nil {
+		return nil, err
+	}
+	deadlines, err := q.client.Do(c, "HGETALL", q.key("deadlines"))
+	if err != nil {
+		return nil, err
+	}
+	extended := redisPairs(deadlines)
+	for id, task := range running {
+		if deadline, err := strconv.ParseInt(extended[id], 10, 64); err == nil && deadline > task.Deadline {
+			task.Deadline = deadline
+		}
+		snapshot.running[id] = task
+	}
+
+	pending, err := q.hash(c, "pending")
+	if err != nil {
+		return nil, err
+	}
+	for id, task := range pending {
+		if _, ok := running[id]; !ok {
+			snapshot.pending = append(snapshot.pending, task)
+		}
+	}
+	slices.SortFunc(snapshot.pending, func(a, b *redisTask) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+
+	for _, task := range snapshot.pending {
+		if len(task.Task.Dependencies) == 0 {
+			continue
+		}
+		keys := make([]string, 0, len(task.Task.Dependencies))
+		for _, dep := range task.Task.Dependencies {
+			keys = append(keys, q.key("done:"+dep))
+		}
+		reply, err := q.client.Do(c, append([]string{"MGET"}, keys...)...)
+		if err != nil {
+			return nil, err
+		}
+		results, _ := reply.([]any)
+		for i, result := range results {
+			var r redisResult
+			if data, ok := result.(string); ok && json.Unmarshal([]byte(data), &r) == nil {
+				if task.Task.DepStatus == nil {
+					task.Task.DepStatus = map[string]model.StatusValue{}
+				}
+				task.Task.DepStatus[task.Task.Dependencies[i]] = r.Status
+			}
+		}
+	}
+	return snapshot, nil
+}
+
+func (q *redisQueue) hash(c context.Context, name string) (map[string]*redisTask, error) {
+	reply, err := q.client.Do(c, "HGETALL", q.key(name))
+	if err != nil {
+		return nil, err
+	}
+	tasks := map[string]*redisTask{}
+	for id, data := range redisPairs(reply) {
+		task := new(redisTask)
+		if err := json.Unmarshal([]byte(data), task); err != nil || task.Task == nil {
+			log.Error().Err(err).Msgf("queue: could not decode task %s", id)
+			continue
+		}
+		task.Task.Data = task.Data
+		tasks[id] = task
+	}
+	return tasks, nil
+}
+
+// waitsOnDeps reports whether a dependency of the task is pending or running.
+func (s *redisSnapshot) waitsOnDeps(task *model.Task) bool {
+	for _, dep := range task.Dependencies {
+		if _, ok := s.running[dep]; ok {
+			return true
+		}
+		if slices.ContainsFunc(s.pending, func(pending *redisTask) bool { return pending.Task.ID == dep }) {
+			return true
+		}
+	}
+	return false
+}
+
+// process schedules the tasks in redis to the workers of this server until the context is done.
+func (q *redisQueue) process() {
+	for {
+		select {
+		case <-time.After(processTimeInterval):
+		case <-q.ctx.Done():
+			return
+		}
+
+		snapshot, err := q.load(q.ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("queue: could not load tasks from redis")
+			continue
+		}
+
+		q.Lock()
+		for id := range q.handedOut {
+			if _, ok := snapshot.running[id]; !ok {
+				delete(q.handedOut, id)
+			}
+		}
+		paused := q.paused || snapshot.paused
+		q.Unlock()
+		if paused {
+			continue
+		}
+		q.resubmitExpired(snapshot)
+
+		q.Lock()
+		expired := q.schedule(snapshot)
+		q.Unlock()
+
+		if len(expired) != 0 && q.expired != nil {
+			q.expired(expired)
+		}
+	}
+}
+
+// resubmitExpired makes the running tasks whose lease expired pending again. Only the server
+// which removes a task from the running ones resubmits it, so a task is never resubmitted twice.
+func (q *redisQueue) resubmitExpired(snapshot *redisSnapshot) {
+	now := time.Now().UnixMilli()
+	for id, task := range snapshot.running {
+		if task.Deadline > now {
+			continue
+		}
+		if deleted, err := redisInt(q.client.Do(q.ctx, "HDEL", q.key("running"), id)); err != nil || deleted == 0 {
+			continue
+		}
+		delete(snapshot.running, id)
+		_, _ = q.client.Do(q.ctx, "HDEL", q.key("deadlines"), id)
+
+		task.Deadline = 0
+		data, _ := json.Marshal(task)
+		if _, err := q.client.Do(q.ctx, "HSET", q.key("pending"), id, string(data)); err != nil {
+			log.Error().Err(err).Msgf("queue: could not resubmit task %s", id)
+			continue
+		}
+		snapshot.pending = append([]*redisTask{task}, snapshot.pending...)
+	}
+}
+
+// schedule assigns the pending tasks of the snapshot to the workers of this server with the rules
+// of the memory queue and returns the tasks which waited too long for an agent.
+func (q *redisQueue) schedule(snapshot *redisSnapshot) []*model.Task {
+	seqs := map[string]int64{}
+	q.pending = list.New()
+	q.waitingOnDeps = list.New()
+	for _, task := range snapshot.pending {
+		q.pending.PushBack(task.Task)
+		seqs[task.Task.ID] = task.Seq
+	}
+	for id := range q.readySince {
+		if _, ok := seqs[id]; !ok {
+			delete(q.readySince, id)
+		}
+	}
+	q.running = map[string]*entry{}
+	for id, task := range snapshot.running {
+		q.running[id] = &entry{item: task.Task, deadline: time.UnixMilli(task.Deadline)}
+	}
+	q.filterWaiting()
+
+	var expired []*model.Task
+	for _, task := range q.expirePending() {
+		// only the server removing the task reports it as expired
+		if deleted, err := redisInt(q.client.Do(q.ctx, "HDEL", q.key("pending"), task.ID)); err != nil || deleted == 0 {
+			continue
+		}
+		data, _ := json.Marshal(&redisResult{Status: model.StatusError})
+		_, _ = q.client.Do(q.ctx, "SET", q.key("done:"+task.ID), string(data), "EX", strconv.Itoa(int(redisDoneTTL.Seconds())))
+		expired = append(expired, task)
+	}
+
+	orgLimits := map[int64]int{}
+	for pending, worker := q.assignToWorker(orgLimits); pending != nil && worker != nil; pending, worker = q.assignToWorker(orgLimits) {
+		task, _ := pending.Value.(*model.Task)
+		q.pending.Remove(pending)
+		delete(q.readySince, task.ID)
+
+		lease := q.extension
+		if worker.lease > 0 && worker.lease < lease {
+			lease = worker.lease
+		}
+		deadline := time.Now().Add(lease)
+		if !q.claim(task, seqs[task.ID], worker.agentID, deadline) {
+			continue
+		}
+
+		task.AgentID = worker.agentID
+		delete(q.workers, worker)
+		if q.fairScheduling {
+			q.assigned++
+			q.lastAssigned[task.RepoID] = q.assigned
+			q.lastAssignedAt[task.RepoID] = time.Now()
+		}
+		q.running[task.ID] = &entry{item: task, deadline: deadline}
+		q.handedOut[task.ID] = struct{}{}
+		worker.channel <- task
+	}
+	return expired
+}
+
+// claim moves the task from pending to running for the agent. It returns false if
+// another server claimed the task first.
+func (q *redisQueue) claim(task *model.Task, seq, agentID int64, deadline time.Time) bool {
+	claimed := *task
+	claimed.AgentID = agentID
+	data, err := json.Marshal(&redisTask{Seq: seq, Task: &claimed, Data: task.Data, Deadline: deadline.UnixMilli()})
+	if err != nil {
+		return false
+	}
+
+	ok, err := redisInt(q.client.Do(q.ctx, "HSETNX", q.key("running"), task.ID, string(data)))
+	if err != nil || ok == 0 {
+		return false
+	}
+	_, _ = q.client.Do(q.ctx, "HDEL", q.key("deadlines"), task.ID)
+	// if this fails the task is pending and running, pending tasks which are running are skipped
+	if _, err := q.client.Do(q.ctx, "HDEL", q.key("pending"), task.ID); err != nil {
+		log.Error().Err(err).Msgf("queue: could not remove claimed task %s from pending", task.ID)
+	}
+	return true
+}
+
+func redisInt(reply any, err error) (int64, error) {
+	if err != nil {
+		return 0, err
+	}
+	i, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected redis reply: %v", reply)
+	}
+	return i, nil
+}
+
+// redisPairs converts the reply of HGETALL to a map.
+func redisPairs(reply any) map[string]string {
+	values, _ := reply.([]any)
+	pairs := make(map[string]string, len(values)/2) //nolint:mnd
+	for i := 0; i+1 < len(values); i += 2 {
+		field, _ := values[i].(string)
+		value, _ := values[i+1].(string)
+		pairs[field] = value
+	}
+	return pairs
+}
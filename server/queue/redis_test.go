@@ -0,0 +1,190 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis/redistest"
+)
+
+func newTestRedisQueue(t *testing.T, server *redistest.Server) (Queue, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+	q, err := New(ctx, Config{Backend: TypeRedis, Redis: redis.Config{Addr: server.Addr()}})
+	require.NoError(t, err)
+	return q, cancel
+}
+
+// pollWithin polls the queue and returns nil if no task is assigned within the timeout.
+func pollWithin(t *testing.T, q Queue, ctx context.Context, agentID int64, timeout time.Duration) *model.Task {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	task, err := q.Poll(ctx, agentID, filterFnTrue)
+	if err != nil {
+		return nil
+	}
+	return task
+}
+
+func TestRedisQueue(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	q, _ := newTestRedisQueue(t, server)
+	ctx := t.Context()
+
+	assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{genDummyTask()}))
+	info := q.Info(ctx)
+	assert.Len(t, info.Pending, 1, "expect task in pending queue")
+
+	got, err := q.Poll(ctx, 1, filterFnTrue)
+	require.NoError(t, err)
+	assert.Equal(t, "1", got.ID)
+	assert.Equal(t, []byte("{}"), got.Data)
+	assert.EqualValues(t, 1, got.AgentID)
+
+	info = q.Info(ctx)
+	assert.Empty(t, info.Pending, "expect task removed from pending queue")
+	assert.Len(t, info.Running, 1, "expect task in running queue")
+
+	waited := make(chan error)
+	go func() { waited <- q.Wait(ctx, got.ID) }()
+	assert.NoError(t, q.Extend(ctx, 1, got.ID))
+	assert.ErrorIs(t, q.Extend(ctx, 2, got.ID), ErrAgentMissMatch)
+	assert.NoError(t, q.Done(ctx, got.ID, model.StatusSuccess))
+	assert.NoError(t, <-waited)
+
+	info = q.Info(ctx)
+	assert.Empty(t, info.Pending)
+	assert.Empty(t, info.Running)
+	assert.ErrorIs(t, q.Extend(ctx, 1, got.ID), ErrNotFound)
+}
+
+func TestRedisQueueLeaseExpired(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	q, _ := newTestRedisQueue(t, server)
+	ctx := t.Context()
+
+	assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{genDummyTask()}))
+	got, err := q.Poll(WithLease(ctx, 200*time.Millisecond), 1, filterFnTrue)
+	require.NoError(t, err)
+
+	// the agent does not extend the lease, so the task is handed out again
+	assert.Nil(t, pollWithin(t, q, ctx, 2, 100*time.Millisecond), "task is leased")
+	again := pollWithin(t, q, ctx, 2, time.Second)
+	require.NotNil(t, again)
+	assert.Equal(t, got.ID, again.ID)
+	assert.Equal(t, got.Data, again.Data)
+	assert.ErrorIs(t, q.Extend(ctx, 1, got.ID), ErrAgentMissMatch)
+	assert.NoError(t, q.Extend(ctx, 2, got.ID))
+}
+
+func TestRedisQueueRestart(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	ctx := t.Context()
+
+	first, stopFirst := newTestRedisQueue(t, server)
+	assert.NoError(t, first.PushAtOnce(ctx, []*model.Task{{ID: "1", Data: []byte("1")}, {ID: "2", Data: []byte("2")}}))
+	running, err := first.Poll(WithLease(ctx, 300*time.Millisecond), 1, filterFnTrue)
+	require.NoError(t, err)
+	assert.Equal(t, "1", running.ID)
+	stopFirst()
+
+	// the pending task is kept and the running one is handed out again after its lease expired
+	second, _ := newTestRedisQueue(t, server)
+	info := second.Info(ctx)
+	assert.Len(t, info.Pending, 1)
+	assert.Len(t, info.Running, 1)
+
+	got := pollWithin(t, second, ctx, 2, time.Second)
+	require.NotNil(t, got)
+	assert.Equal(t, "2", got.ID)
+	got = pollWithin(t, second, ctx, 2, time.Second)
+	require.NotNil(t, got)
+	assert.Equal(t, "1", got.ID)
+	assert.Equal(t, []byte("1"), got.Data)
+}
+
+func TestRedisQueueShared(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	ctx := t.Context()
+	first, _ := newTestRedisQueue(t, server)
+	second, _ := newTestRedisQueue(t, server)
+
+	assert.NoError(t, first.PushAtOnce(ctx, []*model.Task{genDummyTask()}))
+
+	// both servers have an agent polling, only one gets the task
+	tasks := make(chan *model.Task, 2)
+	go func() { tasks <- pollWithin(t, first, ctx, 1, time.Second) }()
+	go func() { tasks <- pollWithin(t, second, ctx, 2, time.Second) }()
+	a, b := <-tasks, <-tasks
+	assert.True(t, (a == nil) != (b == nil), "expect the task to be assigned once")
+	assert.Equal(t, 1, first.(Shared).RunningLocal()+second.(Shared).RunningLocal())
+
+	// the task is finished on the other server
+	waited := make(chan error)
+	go func() { waited <- second.Wait(ctx, "1") }()
+	assert.NoError(t, first.ErrorAtOnce(ctx, []string{"1"}, ErrCancel))
+	assert.ErrorIs(t, <-waited, ErrCancel)
+
+	second.Pause()
+	assert.True(t, first.Info(ctx).Paused)
+	assert.NoError(t, second.PushAtOnce(ctx, []*model.Task{{ID: "2"}}))
+	assert.Nil(t, pollWithin(t, first, ctx, 1, 300*time.Millisecond), "queue is paused")
+	first.Resume()
+	assert.NotNil(t, pollWithin(t, first, ctx, 1, time.Second))
+	assert.Eventually(t, func() bool { return second.(Shared).RunningLocal() == 0 }, time.Second, 10*time.Millisecond)
+
+	// a server paused locally, e.g. while it is drained, leaves the tasks to the others
+	first.(Shared).PauseLocal()
+	assert.NoError(t, first.PushAtOnce(ctx, []*model.Task{{ID: "3"}}))
+	assert.Nil(t, pollWithin(t, first, ctx, 1, 300*time.Millisecond), "server is paused")
+	assert.NotNil(t, pollWithin(t, second, ctx, 2, time.Second))
+	assert.Equal(t, 1, second.(Shared).RunningLocal())
+}
+
+func TestRedisQueueDependencies(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	q, _ := newTestRedisQueue(t, server)
+	ctx := t.Context()
+
+	task1 := &model.Task{ID: "1"}
+	task2 := &model.Task{ID: "2", Dependencies: []string{"1"}, DepStatus: map[string]model.StatusValue{}}
+	task3 := &model.Task{ID: "3"}
+	assert.NoError(t, q.PushAtOnce(ctx, []*model.Task{task2, task1, task3}))
+
+	info := q.Info(ctx)
+	assert.Len(t, info.Pending, 2)
+	assert.Len(t, info.WaitingOnDeps, 1)
+	assert.NoError(t, q.EvictAtOnce(ctx, []string{"3"}))
+	assert.ErrorIs(t, q.EvictAtOnce(ctx, []string{"3"}), ErrNotFound)
+
+	got := pollWithin(t, q, ctx, 1, time.Second)
+	require.NotNil(t, got)
+	assert.Equal(t, "1", got.ID)
+	assert.Nil(t, pollWithin(t, q, ctx, 1, 300*time.Millisecond), "dependency is running")
+
+	assert.NoError(t, q.Done(ctx, "1", model.StatusSuccess))
+	got = pollWithin(t, q, ctx, 1, time.Second)
+	require.NotNil(t, got)
+	assert.Equal(t, "2", got.ID)
+	assert.Equal(t, model.StatusSuccess, got.DepStatus["1"])
+}
@@ -0,0 +1,206 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redis implements the subset of a redis client used by the redis backends
+// of the pubsub, the live logs and the queue.
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	commandTimeout  = 5 * time.Second
+	reconnectPeriod = time.Second
+	maxIdleConns    = 4
+)
+
+var noDeadline time.Time
+
+// Config is the configuration of the connections to a redis server.
+type Config struct {
+	Addr     string
+	Username string
+	Password string
+	// DB is the database selected after connecting.
+	DB int
+	// TLS enables TLS with the given configuration if set.
+	TLS *tls.Config
+}
+
+// Client sends commands to a redis server over a small pool of connections.
+type Client struct {
+	config Config
+
+	sync.Mutex
+	idle   []*Conn
+	closed bool
+}
+
+// NewClient returns a client for the redis server, it connects once to fail early if the server is not reachable.
+func NewClient(ctx context.Context, config Config) (*Client, error) {
+	if config.Addr == "" {
+		return nil, errors.New("redis address is required")
+	}
+
+	c := &Client{config: config}
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+	conn, err := Dial(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	c.put(conn)
+	return c, nil
+}
+
+// Do sends a command and returns its reply. Connections are only reused after successful
+// commands or error replies of the server, so a broken connection is never used twice.
+func (c *Client) Do(ctx context.Context, args ...string) (any, error) {
+	ctx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	deadline, _ := ctx.Deadline()
+	_ = conn.SetDeadline(deadline)
+
+	reply, err := conn.Do(args...)
+	if err != nil && !errors.As(err, new(Error)) {
+		_ = conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+	return reply, err
+}
+
+// Close closes the idle connections, connections in use are closed when they are given back.
+func (c *Client) Close() {
+	c.Lock()
+	defer c.Unlock()
+
+	c.closed = true
+	for _, conn := range c.idle {
+		_ = conn.Close()
+	}
+	c.idle = nil
+}
+
+func (c *Client) get(ctx context.Context) (*Conn, error) {
+	c.Lock()
+	if n := len(c.idle); n > 0 {
+		conn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.Unlock()
+		return conn, nil
+	}
+	closed := c.closed
+	c.Unlock()
+
+	if closed {
+		return nil, errors.New("redis client is closed")
+	}
+	return Dial(ctx, c.config)
+}
+
+func (c *Client) put(conn *Conn) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.closed || len(c.idle) >= maxIdleConns {
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetDeadline(noDeadline)
+	c.idle = append(c.idle, conn)
+}
+
+// Subscribe subscribes to the channel and calls the handler with the data of each message in the order they were
+// published, until the context is done. The first subscription is made before returning to fail early if the server
+// is not reachable, if the connection gets lost later on it subscribes again. Messages published in between are lost.
+func Subscribe(ctx context.Context, config Config, channel string, handler func(data string)) error {
+	sub, err := subscribe(ctx, config, channel)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		for {
+			receive(ctx, sub, handler)
+			_ = sub.Close()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(reconnectPeriod):
+				}
+
+				var err error
+				if sub, err = subscribe(ctx, config, channel); err == nil {
+					break
+				}
+				log.Error().Err(err).Msgf("could not resubscribe to redis channel %s", channel)
+			}
+		}
+	}()
+	return nil
+}
+
+func subscribe(ctx context.Context, config Config, channel string) (*Conn, error) {
+	dialCtx, cancel := context.WithTimeout(ctx, commandTimeout)
+	defer cancel()
+
+	c, err := Dial(dialCtx, config)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.Do("SUBSCRIBE", channel); err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+func receive(ctx context.Context, sub *Conn, handler func(data string)) {
+	// unblock the read if the subscription is stopped
+	stop := context.AfterFunc(ctx, func() { _ = sub.Close() })
+	defer stop()
+
+	for {
+		reply, err := sub.Receive()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("lost redis subscription")
+			}
+			return
+		}
+
+		// pushed messages have the form ["message", channel, data]
+		values, ok := reply.([]any)
+		if !ok || len(values) != 3 || values[0] != "message" { //nolint:mnd
+			continue
+		}
+		data, _ := values[2].(string)
+		handler(data)
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis/redistest"
+)
+
+func TestClient(t *testing.T) {
+	server := redistest.NewServer(t, "secret")
+
+	t.Run("wrong password", func(t *testing.T) {
+		_, err := redis.NewClient(t.Context(), redis.Config{Addr: server.Addr(), Password: "wrong"})
+		assert.ErrorContains(t, err, "WRONGPASS")
+	})
+
+	t.Run("select database", func(t *testing.T) {
+		client, err := redis.NewClient(t.Context(), redis.Config{Addr: server.Addr(), Password: "secret", DB: 2})
+		require.NoError(t, err)
+		defer client.Close()
+
+		_, err = client.Do(t.Context(), "SET", "key", "value")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"key"}, server.Keys(2))
+		assert.Empty(t, server.Keys(0))
+	})
+
+	t.Run("reconnect", func(t *testing.T) {
+		client, err := redis.NewClient(t.Context(), redis.Config{Addr: server.Addr(), Password: "secret"})
+		require.NoError(t, err)
+		defer client.Close()
+
+		server.DropConnections()
+		// the broken connection is dropped, the next command uses a new one
+		_, err = client.Do(t.Context(), "PING")
+		assert.Error(t, err)
+		reply, err := client.Do(t.Context(), "PING")
+		require.NoError(t, err)
+		assert.Equal(t, "PONG", reply)
+
+		// error replies keep the connection
+		_, err = client.Do(t.Context(), "UNKNOWN")
+		assert.ErrorAs(t, err, new(redis.Error))
+	})
+}
+
+func TestTLS(t *testing.T) {
+	server := redistest.NewTLSServer(t, "secret")
+
+	_, err := redis.NewClient(t.Context(), redis.Config{Addr: server.Addr(), Password: "secret"})
+	assert.Error(t, err, "plain connections are refused")
+
+	client, err := redis.NewClient(t.Context(), redis.Config{Addr: server.Addr(), Password: "secret", TLS: server.ClientTLSConfig()})
+	require.NoError(t, err)
+	defer client.Close()
+	reply, err := client.Do(t.Context(), "PING")
+	require.NoError(t, err)
+	assert.Equal(t, "PONG", reply)
+}
+
+func TestSubscribe(t *testing.T) {
+	server := redistest.NewServer(t, "")
+	config := redis.Config{Addr: server.Addr()}
+	client, err := redis.NewClient(t.Context(), config)
+	require.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	received := make(chan string, 10)
+	require.NoError(t, redis.Subscribe(ctx, config, "channel", func(data string) { received <- data }))
+
+	publish := func(data string) {
+		_, err := client.Do(t.Context(), "PUBLISH", "channel", data)
+		require.NoError(t, err)
+	}
+	publish("first")
+	publish("second")
+	assert.Equal(t, "first", <-received)
+	assert.Equal(t, "second", <-received)
+
+	// the subscription is made again after the connection got lost
+	server.DropConnections()
+	_, _ = client.Do(t.Context(), "PING") // drop the broken connection of the client
+	assert.Eventually(t, func() bool { return server.Subscriptions("channel") == 0 }, time.Second, time.Millisecond)
+	assert.Eventually(t, func() bool { return server.Subscriptions("channel") == 1 }, 5*time.Second, 10*time.Millisecond)
+	publish("third")
+	assert.Equal(t, "third", <-received)
+
+	cancel()
+	assert.Eventually(t, func() bool { return server.Subscriptions("channel") == 0 }, time.Second, time.Millisecond)
+}
@@ -0,0 +1,374 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redistest provides an in-memory redis server for tests, implementing
+// the commands used by the redis backends.
+package redistest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/redis"
+)
+
+// Server is an in-memory redis server.
+type Server struct {
+	sync.Mutex
+	listener    net.Listener
+	password    string
+	dbs         map[int]map[string]*value
+	subscribers map[string][]*redis.Conn
+	conns       map[*redis.Conn]struct{}
+	clientTLS   *tls.Config
+}
+
+type value struct {
+	str     string
+	hash    map[string]string
+	expires time.Time
+}
+
+// NewServer starts a server which requires the password if it is not empty.
+// The server is stopped at the end of the test.
+func NewServer(t *testing.T, password string) *Server {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	return newServer(t, listener, password)
+}
+
+// NewTLSServer starts a server using TLS with a self-signed certificate,
+// clients have to use the config returned by ClientTLSConfig.
+func NewTLSServer(t *testing.T, password string) *Server {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "redistest"},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{der}, PrivateKey: key}},
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	s := newServer(t, listener, password)
+	s.clientTLS = &tls.Config{RootCAs: x509.NewCertPool(), MinVersion: tls.VersionTLS12}
+	s.clientTLS.RootCAs.AddCert(cert)
+	return s
+}
+
+// ClientTLSConfig returns the TLS config trusting the certificate of a TLS server.
+func (s *Server) ClientTLSConfig() *tls.Config {
+	return s.clientTLS
+}
+
+func newServer(t *testing.T, listener net.Listener, password string) *Server {
+	s := &Server{
+		listener:    listener,
+		password:    password,
+		dbs:         map[int]map[string]*value{},
+		subscribers: map[string][]*redis.Conn{},
+		conns:       map[*redis.Conn]struct{}{},
+	}
+	t.Cleanup(func() {
+		_ = listener.Close()
+		s.DropConnections()
+	})
+
+	go func() {
+		for {
+			c, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			conn := redis.NewConn(c)
+			s.Lock()
+			s.conns[conn] = struct{}{}
+			s.Unlock()
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+// Addr returns the address the server listens on.
+func (s *Server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// Subscriptions returns the number of subscriptions of the channel.
+func (s *Server) Subscriptions(channel string) int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.subscribers[channel])
+}
+
+// DropConnections closes all client connections, like a restarting server.
+func (s *Server) DropConnections() {
+	s.Lock()
+	defer s.Unlock()
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+}
+
+// Keys returns the keys of the database.
+func (s *Server) Keys(db int) []string {
+	s.Lock()
+	defer s.Unlock()
+	var keys []string
+	for key := range s.dbs[db] {
+		if s.get(db, key) != nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (s *Server) serve(c *redis.Conn) {
+	defer func() {
+		s.Lock()
+		s.unsubscribe(c)
+		delete(s.conns, c)
+		s.Unlock()
+		_ = c.Close()
+	}()
+
+	authenticated := s.password == ""
+	db := 0
+	for {
+		reply, err := c.Receive()
+		if err != nil {
+			return
+		}
+		values, _ := reply.([]any)
+		if len(values) == 0 {
+			return
+		}
+		args := make([]string, len(values))
+		for i, v := range values {
+			args[i], _ = v.(string)
+		}
+
+		s.Lock()
+		var out string
+		switch cmd := strings.ToUpper(args[0]); {
+		case cmd == "AUTH":
+			authenticated = args[len(args)-1] == s.password
+			out = status(authenticated, "WRONGPASS invalid password")
+		case !authenticated:
+			out = "-NOAUTH Authentication required\r\n"
+		case cmd == "SELECT":
+			db, err = strconv.Atoi(args[1])
+			out = status(err == nil, "ERR invalid DB index")
+		default:
+			out = s.command(db, c, cmd, args[1:])
+		}
+		// write while locked, so published messages can't overtake the reply
+		fmt.Fprint(c, out)
+		s.Unlock()
+	}
+}
+
+func (s *Server) command(db int, c *redis.Conn, cmd string, args []string) string {
+	switch cmd {
+	case "PING":
+		return "+PONG\r\n"
+	case "SUBSCRIBE":
+		s.subscribers[args[0]] = append(s.subscribers[args[0]], c)
+		return "*3\r\n" + bulk("subscribe") + bulk(args[0]) + ":1\r\n"
+	case "PUBLISH":
+		for _, sub := range s.subscribers[args[0]] {
+			fmt.Fprint(sub, "*3\r\n"+bulk("message")+bulk(args[0])+bulk(args[1]))
+		}
+		return integer(len(s.subscribers[args[0]]))
+	case "GET":
+		if v := s.get(db, args[0]); v != nil {
+			return bulk(v.str)
+		}
+		return "$-1\r\n"
+	case "MGET":
+		out := fmt.Sprintf("*%d\r\n", len(args))
+		for _, key := range args {
+			if v := s.get(db, key); v != nil {
+				out += bulk(v.str)
+			} else {
+				out += "$-1\r\n"
+			}
+		}
+		return out
+	case "SET":
+		v := &value{str: args[1]}
+		for i := 2; i < len(args); i++ {
+			switch strings.ToUpper(args[i]) {
+			case "NX":
+				if s.get(db, args[0]) != nil {
+					return "$-1\r\n"
+				}
+			case "PX":
+				ms, _ := strconv.Atoi(args[i+1])
+				v.expires = time.Now().Add(time.Duration(ms) * time.Millisecond)
+				i++
+			case "EX":
+				sec, _ := strconv.Atoi(args[i+1])
+				v.expires = time.Now().Add(time.Duration(sec) * time.Second)
+				i++
+			}
+		}
+		s.db(db)[args[0]] = v
+		return "+OK\r\n"
+	case "INCRBY":
+		v := s.get(db, args[0])
+		if v == nil {
+			v = &value{str: "0"}
+			s.db(db)[args[0]] = v
+		}
+		i, _ := strconv.Atoi(v.str)
+		by, _ := strconv.Atoi(args[1])
+		v.str = strconv.Itoa(i + by)
+		return ":" + v.str + "\r\n"
+	case "DEL":
+		deleted := 0
+		for _, key := range args {
+			if s.get(db, key) != nil {
+				deleted++
+			}
+			delete(s.db(db), key)
+		}
+		return integer(deleted)
+	case "HSET", "HSETNX":
+		h := s.hash(db, args[0], true)
+		added := 0
+		for i := 1; i+1 < len(args); i += 2 {
+			if _, ok := h[args[i]]; !ok {
+				added++
+			} else if cmd == "HSETNX" {
+				continue
+			}
+			h[args[i]] = args[i+1]
+		}
+		return integer(added)
+	case "HGET":
+		if v, ok := s.hash(db, args[0], false)[args[1]]; ok {
+			return bulk(v)
+		}
+		return "$-1\r\n"
+	case "HEXISTS":
+		if _, ok := s.hash(db, args[0], false)[args[1]]; ok {
+			return integer(1)
+		}
+		return integer(0)
+	case "HDEL":
+		h := s.hash(db, args[0], false)
+		deleted := 0
+		for _, field := range args[1:] {
+			if _, ok := h[field]; ok {
+				deleted++
+				delete(h, field)
+			}
+		}
+		return integer(deleted)
+	case "HGETALL":
+		h := s.hash(db, args[0], false)
+		out := fmt.Sprintf("*%d\r\n", 2*len(h)) //nolint:mnd
+		for field, v := range h {
+			out += bulk(field) + bulk(v)
+		}
+		return out
+	case "HLEN":
+		return integer(len(s.hash(db, args[0], false)))
+	default:
+		return fmt.Sprintf("-ERR unknown command '%s'\r\n", cmd)
+	}
+}
+
+func (s *Server) db(db int) map[string]*value {
+	if s.dbs[db] == nil {
+		s.dbs[db] = map[string]*value{}
+	}
+	return s.dbs[db]
+}
+
+func (s *Server) get(db int, key string) *value {
+	v := s.db(db)[key]
+	if v != nil && !v.expires.IsZero() && time.Now().After(v.expires) {
+		delete(s.db(db), key)
+		return nil
+	}
+	return v
+}
+
+func (s *Server) hash(db int, key string, create bool) map[string]string {
+	v := s.get(db, key)
+	if v == nil {
+		if !create {
+			return nil
+		}
+		v = &value{hash: map[string]string{}}
+		s.db(db)[key] = v
+	}
+	return v.hash
+}
+
+func (s *Server) unsubscribe(c *redis.Conn) {
+	for channel, subs := range s.subscribers {
+		for i, sub := range subs {
+			if sub == c {
+				s.subscribers[channel] = append(subs[:i], subs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func status(ok bool, err string) string {
+	if ok {
+		return "+OK\r\n"
+	}
+	return "-" + err + "\r\n"
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func integer(i int) string {
+	return fmt.Sprintf(":%d\r\n", i)
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redis
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Conn is a connection speaking the redis serialization protocol (RESP).
+type Conn struct {
+	net.Conn
+	reader *bufio.Reader
+}
+
+// NewConn wraps a network connection, e.g. one accepted by a server.
+func NewConn(c net.Conn) *Conn {
+	return &Conn{Conn: c, reader: bufio.NewReader(c)}
+}
+
+// Dial connects to the redis server, authenticates and selects the database of the config.
+func Dial(ctx context.Context, config Config) (*Conn, error) {
+	var c net.Conn
+	var err error
+	if config.TLS != nil {
+		d := &tls.Dialer{Config: config.TLS}
+		c, err = d.DialContext(ctx, "tcp", config.Addr)
+	} else {
+		var d net.Dialer
+		c, err = d.DialContext(ctx, "tcp", config.Addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rc := NewConn(c)
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = c.SetDeadline(deadline)
+		defer func() { _ = c.SetDeadline(noDeadline) }()
+	}
+
+	if config.Password != "" {
+		args := []string{"AUTH", config.Password}
+		if config.Username != "" {
+			args = []string{"AUTH", config.Username, config.Password}
+		}
+		if _, err := rc.Do(args...); err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("could not authenticate: %w", err)
+		}
+	}
+	if config.DB != 0 {
+		if _, err := rc.Do("SELECT", strconv.Itoa(config.DB)); err != nil {
+			_ = c.Close()
+			return nil, fmt.Errorf("could not select database %d: %w", config.DB, err)
+		}
+	}
+	return rc, nil
+}
+
+// Do sends a command and returns its reply.
+func (c *Conn) Do(args ...string) (any, error) {
+	if err := c.Send(args...); err != nil {
+		return nil, err
+	}
+	return c.Receive()
+}
+
+// Send sends a command without waiting for its reply.
+func (c *Conn) Send(args ...string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	_, err := io.WriteString(c.Conn, b.String())
+	return err
+}
+
+// Receive reads a reply, which is either a string, an int64, nil or a slice of replies.
+// Error replies are returned as Error.
+func (c *Conn) Receive() (any, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, Error(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		data := make([]byte, size+2) //nolint:mnd
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, err
+		}
+		return string(data[:size]), nil
+	case '*':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, err
+		}
+		values := make([]any, size)
+		for i := range values {
+			if values[i], err = c.Receive(); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("unexpected reply: %q", line)
+	}
+}
+
+// Error is an error reply of the redis server.
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
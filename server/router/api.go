@@ -21,6 +21,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server"
 	"go.woodpecker-ci.org/woodpecker/v3/server/api"
 	"go.woodpecker-ci.org/woodpecker/v3/server/api/debug"
+	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/ratelimit"
 	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/session"
 )
 
@@ -33,6 +34,11 @@ func apiRoutes(e *gin.RouterGroup) {
 			user.GET("", api.GetSelf)
 			user.GET("/feed", api.GetFeed)
 			user.GET("/repos", api.GetRepos)
+			user.POST("/repos/sync", api.SyncRepos)
+			user.POST("/2fa/enroll", api.PostTwoFactorEnroll)
+			user.POST("/2fa/activate", api.PostTwoFactorActivate)
+			user.POST("/2fa/verify", api.PostTwoFactorVerify)
+			user.POST("/2fa/recovery-codes", api.PostTwoFactorRecoveryCodes)
 			user.POST("/token", api.PostToken)
 			user.DELETE("/token", api.DeleteToken)
 		}
@@ -45,6 +51,7 @@ func apiRoutes(e *gin.RouterGroup) {
 			users.GET("/:login", api.GetUser)
 			users.PATCH("/:login", api.PatchUser)
 			users.DELETE("/:login", api.DeleteUser)
+			users.DELETE("/:login/2fa", api.DeleteUserTwoFactor)
 		}
 
 		orgs := apiBase.Group("/orgs")
@@ -61,6 +68,7 @@ func apiRoutes(e *gin.RouterGroup) {
 				org := orgBase.Group("")
 				{
 					org.Use(session.MustOrgMember(true))
+					org.PATCH("", session.MustAdmin(), api.PatchOrg)
 					org.DELETE("", session.MustAdmin(), api.DeleteOrg)
 
 					org.GET("/secrets", api.GetOrgSecretList)
@@ -154,6 +162,8 @@ func apiRoutes(e *gin.RouterGroup) {
 					repo.POST("/chown", session.MustRepoAdmin(), api.ChownRepo)
 					repo.POST("/repair", session.MustRepoAdmin(), api.RepairRepo)
 					repo.POST("/move", session.MustRepoAdmin(), api.MoveRepo)
+					repo.POST("/hook/rotate", session.MustRepoAdmin(), api.RotateRepoHook)
+					repo.POST("/hook/prune", session.MustRepoAdmin(), api.PruneRepoHook)
 				}
 			}
 		}
@@ -170,6 +180,8 @@ func apiRoutes(e *gin.RouterGroup) {
 			_badges.GET("/cc.xml", api.GetCC)
 		}
 
+		apiBase.GET("/pipelines/query", session.MustUser(), api.QueryPipelines)
+
 		pipelines := apiBase.Group("/pipelines")
 		{
 			pipelines.Use(session.MustAdmin())
@@ -185,6 +197,12 @@ func apiRoutes(e *gin.RouterGroup) {
 			queue.GET("/norunningpipelines", api.BlockTilQueueHasRunningItem)
 		}
 
+		jwtSecret := apiBase.Group("/jwt-secret")
+		{
+			jwtSecret.Use(session.MustAdmin())
+			jwtSecret.POST("/rotate", api.RotateJWTSecret)
+		}
+
 		// global secrets can be read without actual values by any user
 		readGlobalSecrets := apiBase.Group("/secrets")
 		{
@@ -215,6 +233,8 @@ func apiRoutes(e *gin.RouterGroup) {
 			registries.DELETE("/:registry", api.DeleteGlobalRegistry)
 		}
 
+		apiBase.GET("/info", session.MustUser(), api.GetInfo)
+
 		logLevel := apiBase.Group("/log-level")
 		{
 			logLevel.Use(session.MustAdmin())
@@ -222,6 +242,20 @@ func apiRoutes(e *gin.RouterGroup) {
 			logLevel.POST("", api.SetLogLevel)
 		}
 
+		maintenance := apiBase.Group("/maintenance")
+		{
+			maintenance.Use(session.MustAdmin())
+			maintenance.GET("", api.GetMaintenance)
+			maintenance.POST("", api.SetMaintenance)
+		}
+
+		migrations := apiBase.Group("/migrations")
+		{
+			migrations.Use(session.MustAdmin())
+			migrations.GET("", api.GetMigrations)
+			migrations.POST("/:version/rollback", api.RollbackMigration)
+		}
+
 		agentBase := apiBase.Group("/agents")
 		{
 			agentBase.Use(session.MustAdmin())
@@ -233,6 +267,14 @@ func apiRoutes(e *gin.RouterGroup) {
 			agentBase.DELETE("/:agent_id", api.DeleteAgent)
 		}
 
+		agentTokens := apiBase.Group("/agent-tokens")
+		{
+			agentTokens.Use(session.MustAdmin())
+			agentTokens.GET("", api.GetAgentTokens)
+			agentTokens.POST("", api.PostAgentToken)
+			agentTokens.DELETE("/:token_id", api.DeleteAgentToken)
+		}
+
 		apiBase.GET("/forges", api.GetForges)
 		apiBase.GET("/forges/:forgeId", api.GetForge)
 		forgeBase := apiBase.Group("/forges")
@@ -245,7 +287,12 @@ func apiRoutes(e *gin.RouterGroup) {
 
 		apiBase.GET("/signature/public-key", session.MustUser(), api.GetSignaturePublicKey)
 
-		apiBase.POST("/hook", api.PostHook)
+		apiBase.POST("/hook", ratelimit.Webhook(ratelimit.Config{
+			RepoRate:    server.Config.Webhook.RateLimit,
+			RepoBurst:   server.Config.Webhook.RateLimitBurst,
+			GlobalRate:  server.Config.Webhook.GlobalRateLimit,
+			GlobalBurst: server.Config.Webhook.GlobalRateLimitBurst,
+		}), ratelimit.WebhookConcurrency(server.Config.Webhook.Concurrency, server.Config.Webhook.QueueSize), api.PostHook)
 
 		stream := apiBase.Group("/stream")
 		{
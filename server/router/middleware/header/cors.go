@@ -0,0 +1,79 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package header
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSOptions configures which cross-origin requests are allowed.
+type CORSOptions struct {
+	// AllowedOrigins are the origins like https://dashboard.example.com allowed to send requests, "*" allows all origins.
+	AllowedOrigins []string
+	// AllowedMethods are the methods allowed in cross-origin requests.
+	AllowedMethods []string
+	// AllowCredentials allows cookies in cross-origin requests. It is never enabled for the "*" origin.
+	AllowCredentials bool
+}
+
+// CORS is a middleware function that appends the CORS headers for allowed origins
+// to requests below the path prefix and answers their preflight requests.
+// Without allowed origins no headers are sent, so browsers only allow same-origin requests.
+func CORS(pathPrefix string, opts CORSOptions) gin.HandlerFunc {
+	methods := strings.Join(opts.AllowedMethods, ",")
+	wildcard := slices.Contains(opts.AllowedOrigins, "*")
+
+	return func(c *gin.Context) {
+		origin := c.Request.Header.Get("Origin")
+		if origin == "" || !strings.HasPrefix(c.Request.URL.Path, pathPrefix) {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		preflight := c.Request.Method == http.MethodOptions && c.Request.Header.Get("Access-Control-Request-Method") != ""
+
+		switch {
+		case slices.Contains(opts.AllowedOrigins, origin):
+			c.Header("Access-Control-Allow-Origin", origin)
+			if opts.AllowCredentials {
+				c.Header("Access-Control-Allow-Credentials", "true")
+			}
+		case wildcard:
+			// credentials are never allowed for any origin
+			c.Header("Access-Control-Allow-Origin", "*")
+		default:
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if preflight {
+			c.Header("Access-Control-Allow-Methods", methods)
+			c.Header("Access-Control-Allow-Headers", "authorization, origin, content-type, accept, x-csrf-token")
+			c.Header("Access-Control-Max-Age", "600")
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package header
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORS(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	request := func(opts CORSOptions, method, path, origin string, preflight bool) *httptest.ResponseRecorder {
+		e := gin.New()
+		e.Use(CORS("/api/", opts))
+		e.Use(Options)
+		e.GET("/api/user", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+		e.GET("/web-config.js", func(c *gin.Context) { c.String(http.StatusOK, "ok") })
+
+		req := httptest.NewRequest(method, path, nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, req)
+		return w
+	}
+
+	opts := CORSOptions{
+		AllowedOrigins:   []string{"https://dashboard.example.com"},
+		AllowedMethods:   []string{"GET", "POST"},
+		AllowCredentials: true,
+	}
+
+	t.Run("same-origin by default", func(t *testing.T) {
+		w := request(CORSOptions{}, http.MethodGet, "/api/user", "https://evil.example.com", false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+
+		w = request(CORSOptions{}, http.MethodOptions, "/api/user", "https://evil.example.com", true)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("allowed origin", func(t *testing.T) {
+		w := request(opts, http.MethodGet, "/api/user", "https://dashboard.example.com", false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "https://dashboard.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+		assert.Equal(t, "Origin", w.Header().Get("Vary"))
+
+		w = request(opts, http.MethodOptions, "/api/user", "https://dashboard.example.com", true)
+		assert.Equal(t, http.StatusNoContent, w.Code)
+		assert.Equal(t, "https://dashboard.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "GET,POST", w.Header().Get("Access-Control-Allow-Methods"))
+		assert.NotEmpty(t, w.Header().Get("Access-Control-Allow-Headers"))
+	})
+
+	t.Run("disallowed origin", func(t *testing.T) {
+		w := request(opts, http.MethodGet, "/api/user", "https://evil.example.com", false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+
+		w = request(opts, http.MethodOptions, "/api/user", "https://evil.example.com", true)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Methods"))
+	})
+
+	t.Run("wildcard origin never allows credentials", func(t *testing.T) {
+		wildcard := CORSOptions{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, AllowCredentials: true}
+		w := request(wildcard, http.MethodGet, "/api/user", "https://any.example.com", false)
+		assert.Equal(t, "*", w.Header().Get("Access-Control-Allow-Origin"))
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Credentials"))
+	})
+
+	t.Run("only api paths", func(t *testing.T) {
+		w := request(opts, http.MethodGet, "/web-config.js", "https://dashboard.example.com", false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("plain options request", func(t *testing.T) {
+		w := request(opts, http.MethodOptions, "/api/user", "", false)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+		assert.NotEmpty(t, w.Header().Get("Allow"))
+	})
+}
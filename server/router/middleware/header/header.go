@@ -32,14 +32,11 @@ func NoCache(c *gin.Context) {
 
 // Options is a middleware function that appends headers
 // for options requests and aborts then exits the middleware
-// chain and ends the request.
+// chain and ends the request. CORS preflight requests are answered by CORS.
 func Options(c *gin.Context) {
 	if c.Request.Method != "OPTIONS" {
 		c.Next()
 	} else {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Methods", "GET,POST,PUT,PATCH,DELETE,OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "authorization, origin, content-type, accept")
 		c.Header("Allow", "HEAD,GET,POST,PUT,PATCH,DELETE,OPTIONS")
 		c.Header("Content-Type", "application/json")
 		c.AbortWithStatus(http.StatusOK)
@@ -49,7 +46,6 @@ func Options(c *gin.Context) {
 // Secure is a middleware function that appends security
 // and resource access headers.
 func Secure(c *gin.Context) {
-	c.Header("Access-Control-Allow-Origin", "*")
 	c.Header("X-Frame-Options", "DENY")
 	c.Header("X-Content-Type-Options", "nosniff")
 	c.Header("X-XSS-Protection", "1; mode=block")
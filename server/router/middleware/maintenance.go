@@ -0,0 +1,52 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/maintenance"
+)
+
+// Maintenance is a middleware function that rejects all writes, including webhooks,
+// while the maintenance mode is enabled. Logging in, verifying the second factor, rolling back
+// store migrations and disabling the maintenance mode stay possible.
+func Maintenance(c *gin.Context) {
+	if !maintenance.Enabled() {
+		c.Next()
+		return
+	}
+
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		c.Next()
+		return
+	}
+	switch c.FullPath() {
+	case server.Config.Server.RootPath + "/authorize",
+		server.Config.Server.RootPath + "/api/user/2fa/verify",
+		server.Config.Server.RootPath + "/api/maintenance",
+		server.Config.Server.RootPath + "/api/migrations/:version/rollback":
+		c.Next()
+		return
+	}
+
+	c.Header("Retry-After", "300")
+	c.String(http.StatusServiceUnavailable, "server is in maintenance mode, it is read-only")
+	c.Abort()
+}
@@ -0,0 +1,72 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/maintenance"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+)
+
+func TestMaintenance(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store := mocks.NewMockStore(t)
+	store.On("ServerConfigSet", "maintenance", mock.Anything).Return(nil)
+	require.NoError(t, maintenance.Set(store, true))
+	t.Cleanup(func() { require.NoError(t, maintenance.Set(store, false)) })
+
+	e := gin.New()
+	e.Use(Maintenance)
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	e.GET("/api/repos/:repo_id", ok)
+	e.PATCH("/api/repos/:repo_id", ok)
+	e.POST("/api/hook", ok)
+	e.POST("/api/maintenance", ok)
+	e.POST("/authorize", ok)
+	e.POST("/api/user/2fa/verify", ok)
+	e.POST("/api/user/2fa/recovery-codes", ok)
+	e.POST("/api/migrations/:version/rollback", ok)
+
+	tests := []struct {
+		method string
+		path   string
+		code   int
+	}{
+		{http.MethodGet, "/api/repos/1", http.StatusOK},
+		{http.MethodPatch, "/api/repos/1", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/hook", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/maintenance", http.StatusOK},
+		{http.MethodPost, "/authorize", http.StatusOK},
+		{http.MethodPost, "/api/user/2fa/verify", http.StatusOK},
+		{http.MethodPost, "/api/user/2fa/recovery-codes", http.StatusServiceUnavailable},
+		{http.MethodPost, "/api/migrations/fix-forge-columns/rollback", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			e.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	prometheus_auto "github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	inFlightWebhooks = prometheus_auto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "woodpecker",
+		Subsystem: "webhook",
+		Name:      "in_flight",
+		Help:      "Number of webhooks being processed.",
+	})
+	queuedWebhooks = prometheus_auto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "woodpecker",
+		Subsystem: "webhook",
+		Name:      "queued",
+		Help:      "Number of webhooks waiting to be processed.",
+	})
+)
+
+// webhookQueueTimeout is how long a webhook waits for a free slot. It is below the timeout of
+// most forges, so they get a response they can retry instead of a timed out delivery.
+const webhookQueueTimeout = 8 * time.Second
+
+type webhookPool struct {
+	slots    chan struct{}
+	mu       sync.Mutex
+	queued   int
+	maxQueue int
+	maxWait  time.Duration
+}
+
+// WebhookConcurrency returns a middleware limiting the number of webhooks processed at once.
+// Webhooks above the limit wait for a free slot, if the queue is full or no slot gets free in time they are
+// rejected with 503 Service Unavailable, so the forge can deliver them again later. A concurrency of zero disables the limit.
+func WebhookConcurrency(concurrency, queueSize int) gin.HandlerFunc {
+	if concurrency <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return newWebhookPool(concurrency, queueSize).handle
+}
+
+func newWebhookPool(concurrency, queueSize int) *webhookPool {
+	return &webhookPool{
+		slots:    make(chan struct{}, concurrency),
+		maxQueue: max(queueSize, 0),
+		maxWait:  webhookQueueTimeout,
+	}
+}
+
+func (p *webhookPool) handle(c *gin.Context) {
+	if !p.acquire(c) {
+		return
+	}
+	inFlightWebhooks.Inc()
+	defer func() {
+		inFlightWebhooks.Dec()
+		<-p.slots
+	}()
+
+	c.Next()
+}
+
+// acquire takes a free slot, waiting in the queue if there is none.
+// It returns false if the webhook was rejected.
+func (p *webhookPool) acquire(c *gin.Context) bool {
+	select {
+	case p.slots <- struct{}{}:
+		return true
+	default:
+	}
+
+	p.mu.Lock()
+	if p.queued >= p.maxQueue {
+		p.mu.Unlock()
+		reject(c)
+		return false
+	}
+	p.queued++
+	p.mu.Unlock()
+	queuedWebhooks.Inc()
+
+	defer func() {
+		p.mu.Lock()
+		p.queued--
+		p.mu.Unlock()
+		queuedWebhooks.Dec()
+	}()
+
+	timeout := time.NewTimer(p.maxWait)
+	defer timeout.Stop()
+
+	select {
+	case p.slots <- struct{}{}:
+		return true
+	case <-timeout.C:
+		reject(c)
+		return false
+	case <-c.Request.Context().Done():
+		// the forge gave up on the delivery
+		c.Abort()
+		return false
+	}
+}
+
+func reject(c *gin.Context) {
+	log.Warn().Msg("webhook rejected, too many webhooks are processed")
+	c.Header("Retry-After", "60")
+	c.String(http.StatusServiceUnavailable, "too many webhooks are processed, retry later")
+	c.Abort()
+}
@@ -0,0 +1,128 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookConcurrency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	p := newWebhookPool(2, 1)
+	release := make(chan struct{})
+	var running, maxRunning atomic.Int32
+	e := gin.New()
+	e.POST("/api/hook", p.handle, func(c *gin.Context) {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		<-release
+		running.Add(-1)
+		c.Status(http.StatusOK)
+	})
+
+	send := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/hook", nil))
+		return w
+	}
+
+	var wg sync.WaitGroup
+	responses := make([]*httptest.ResponseRecorder, 3)
+	for i := range responses {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			responses[i] = send()
+		}()
+	}
+
+	// two webhooks are processed and one is queued
+	assert.Eventually(t, func() bool {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return running.Load() == 2 && p.queued == 1
+	}, time.Second, time.Millisecond)
+
+	rejected := send()
+	assert.Equal(t, http.StatusServiceUnavailable, rejected.Code)
+	assert.Equal(t, "60", rejected.Header().Get("Retry-After"))
+
+	close(release)
+	wg.Wait()
+	for _, w := range responses {
+		assert.Equal(t, http.StatusOK, w.Code)
+	}
+	assert.EqualValues(t, 2, maxRunning.Load())
+	assert.Zero(t, p.queued)
+	assert.Empty(t, p.slots)
+}
+
+func TestWebhookConcurrencyQueueTimeout(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	p := newWebhookPool(1, 1)
+	p.maxWait = 10 * time.Millisecond
+	release := make(chan struct{})
+	e := gin.New()
+	e.POST("/api/hook", p.handle, func(c *gin.Context) {
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	done := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/hook", nil))
+		done <- w.Code
+	}()
+	assert.Eventually(t, func() bool { return len(p.slots) == 1 }, time.Second, time.Millisecond)
+
+	// the queued webhook gives up waiting for the slot
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/hook", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	close(release)
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Zero(t, p.queued)
+}
+
+func TestWebhookConcurrencyDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	e := gin.New()
+	e.POST("/api/hook", WebhookConcurrency(0, 0), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	e.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/hook", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
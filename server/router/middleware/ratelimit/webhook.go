@@ -0,0 +1,164 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ratelimit limits the rate and concurrency of incoming webhooks.
+package ratelimit
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/prometheus/client_golang/prometheus"
+	prometheus_auto "github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// repoLimiterTTL is the duration the limiter of a repo is kept after its last webhook.
+	repoLimiterTTL = 10 * time.Minute
+	// maxRepoLimiters is the maximum number of repos whose limiters are kept.
+	maxRepoLimiters = 10000
+)
+
+var throttledWebhooks = prometheus_auto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "woodpecker",
+	Subsystem: "webhook",
+	Name:      "throttled_total",
+	Help:      "Number of webhooks rejected by the rate limit, by the limit which was exceeded.",
+}, []string{"limit"})
+
+// Config is the configuration of the webhook rate limit.
+// The rates are the allowed webhooks per second, zero disables the limit.
+type Config struct {
+	RepoRate    float64
+	RepoBurst   int
+	GlobalRate  float64
+	GlobalBurst int
+}
+
+type webhookLimiter struct {
+	config Config
+	global *rate.Limiter
+	repos  *ttlcache.Cache[string, *rate.Limiter]
+	now    func() time.Time
+}
+
+// Webhook returns a middleware limiting the rate of webhooks per repo and across all repos.
+// Webhooks above the limit are rejected with 429 Too Many Requests and a Retry-After header,
+// so the forge can deliver them again later.
+func Webhook(config Config) gin.HandlerFunc {
+	return newWebhookLimiter(config).handle
+}
+
+func newWebhookLimiter(config Config) *webhookLimiter {
+	l := &webhookLimiter{
+		config: config,
+		repos: ttlcache.New(
+			ttlcache.WithTTL[string, *rate.Limiter](repoLimiterTTL),
+			ttlcache.WithCapacity[string, *rate.Limiter](maxRepoLimiters),
+		),
+		now: time.Now,
+	}
+	if config.GlobalRate > 0 {
+		l.global = rate.NewLimiter(rate.Limit(config.GlobalRate), max(config.GlobalBurst, 1))
+	}
+	return l
+}
+
+func (l *webhookLimiter) handle(c *gin.Context) {
+	now := l.now()
+	var reservations []*rate.Reservation
+	var delay time.Duration
+	exceeded := ""
+
+	for _, limit := range []struct {
+		name    string
+		limiter *rate.Limiter
+	}{
+		{name: "repo", limiter: l.repoLimiter(c.Request)},
+		{name: "global", limiter: l.global},
+	} {
+		if limit.limiter == nil {
+			continue
+		}
+		r := limit.limiter.ReserveN(now, 1)
+		reservations = append(reservations, r)
+		if d := r.DelayFrom(now); d > delay {
+			delay = d
+			exceeded = limit.name
+		}
+	}
+
+	if delay == 0 {
+		c.Next()
+		return
+	}
+
+	// the webhook is rejected, so it must not use up the rate
+	for _, r := range reservations {
+		r.CancelAt(now)
+	}
+	throttledWebhooks.WithLabelValues(exceeded).Inc()
+	log.Warn().Str("limit", exceeded).Msg("webhook rejected by rate limit")
+
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	c.String(http.StatusTooManyRequests, "webhook rate limit exceeded, retry later")
+	c.Abort()
+}
+
+// repoLimiter returns the limiter of the repo the webhook belongs to.
+// The repo is taken from the hook token without verifying it, as the token is verified by the webhook handler.
+// A forged token can only use up the rate of a single repo, while the global rate can be used up by anyone anyway.
+func (l *webhookLimiter) repoLimiter(r *http.Request) *rate.Limiter {
+	if l.config.RepoRate <= 0 {
+		return nil
+	}
+
+	repo := hookRepo(r)
+	if repo == "" {
+		return nil
+	}
+
+	limiter, _ := l.repos.GetOrSet(repo, rate.NewLimiter(rate.Limit(l.config.RepoRate), max(l.config.RepoBurst, 1)))
+	return limiter.Value()
+}
+
+// hookRepo returns the repo identifier of the hook token of the request.
+func hookRepo(r *http.Request) string {
+	raw := r.URL.Query().Get("access_token")
+	if raw == "" {
+		raw = r.Header.Get("X-Gitlab-Token")
+	}
+	if raw == "" {
+		return ""
+	}
+
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(raw, claims); err != nil {
+		return ""
+	}
+	if remoteID, _ := claims["repo-forge-remote-id"].(string); remoteID != "" {
+		forgeID, _ := claims["forge-id"].(string)
+		return forgeID + "/" + remoteID
+	}
+	// tokens of repos activated with older versions
+	repoID, _ := claims["repo-id"].(string)
+	return repoID
+}
@@ -0,0 +1,123 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
+)
+
+func hookToken(t *testing.T, remoteID string) string {
+	hook := token.New(token.HookToken)
+	hook.Set("repo-forge-remote-id", remoteID)
+	hook.Set("forge-id", "1")
+	raw, err := hook.Sign("repo-hash")
+	require.NoError(t, err)
+	return raw
+}
+
+func sendHooks(t *testing.T, l *webhookLimiter, accessToken string, count int) []*httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	e := gin.New()
+	e.POST("/api/hook", l.handle, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	var responses []*httptest.ResponseRecorder
+	for range count {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/hook?access_token="+accessToken, nil)
+		e.ServeHTTP(w, req)
+		responses = append(responses, w)
+	}
+	return responses
+}
+
+func throttledCount(t *testing.T, limit string) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "woodpecker_webhook_throttled_total" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			if metric.GetLabel()[0].GetValue() == limit {
+				return metric.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
+func statusCodes(responses []*httptest.ResponseRecorder) []int {
+	codes := make([]int, 0, len(responses))
+	for _, w := range responses {
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func TestWebhookRepoLimit(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := newWebhookLimiter(Config{RepoRate: 0.5, RepoBurst: 2})
+	l.now = func() time.Time { return now }
+	throttled := throttledCount(t, "repo")
+
+	repoA := hookToken(t, "a")
+	responses := sendHooks(t, l, repoA, 3)
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, statusCodes(responses))
+	assert.Equal(t, "2", responses[2].Header().Get("Retry-After"))
+	assert.Equal(t, throttled+1, throttledCount(t, "repo"))
+
+	// other repos are not affected
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, statusCodes(sendHooks(t, l, hookToken(t, "b"), 2)))
+
+	// rejected webhooks do not use up the rate
+	now = now.Add(2 * time.Second)
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, statusCodes(sendHooks(t, l, repoA, 2)))
+}
+
+func TestWebhookGlobalLimit(t *testing.T) {
+	now := time.Unix(1000, 0)
+	l := newWebhookLimiter(Config{RepoRate: 10, RepoBurst: 10, GlobalRate: 1, GlobalBurst: 3})
+	l.now = func() time.Time { return now }
+	throttled := throttledCount(t, "global")
+
+	assert.Equal(t, []int{http.StatusOK, http.StatusOK}, statusCodes(sendHooks(t, l, hookToken(t, "a"), 2)))
+	responses := sendHooks(t, l, hookToken(t, "b"), 2)
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, statusCodes(responses))
+	assert.Equal(t, "1", responses[1].Header().Get("Retry-After"))
+	assert.Equal(t, throttled+1, throttledCount(t, "global"))
+
+	// webhooks without a token are limited globally only
+	now = now.Add(time.Second)
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests}, statusCodes(sendHooks(t, l, "", 2)))
+}
+
+func TestWebhookNoLimit(t *testing.T) {
+	l := newWebhookLimiter(Config{})
+	for _, code := range statusCodes(sendHooks(t, l, hookToken(t, "a"), 100)) {
+		assert.Equal(t, http.StatusOK, code)
+	}
+}
@@ -143,7 +143,7 @@ func SetPerm() gin.HandlerFunc {
 			perm = new(model.Perm)
 		}
 
-		if user != nil && user.Admin {
+		if IsAdmin(c) {
 			perm.Pull = true
 			perm.Push = true
 			perm.Admin = true
@@ -0,0 +1,111 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"strconv"
+	"time"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
+)
+
+const (
+	// startedClaim is the claim of session tokens holding the unix time of the login.
+	startedClaim = "session-started"
+	// TwoFactorClaim is the claim of session and user tokens whose user verified the second factor.
+	TwoFactorClaim = "2fa"
+)
+
+// NewToken creates a session token for the user, who logged in at started.
+// Session tokens are signed with the hash of the user instead of the jwt secret,
+// so they stay valid when the jwt secret is rotated.
+func NewToken(user *model.User, started time.Time) (string, error) {
+	return signToken(user, started, sessionExpires(started, started), false)
+}
+
+// NewVerifiedToken returns a token of the same type as t, for which the user verified the second factor.
+// Session tokens keep their login time and expiry.
+func NewVerifiedToken(t *token.Token, user *model.User, now time.Time) (string, error) {
+	if t.Type != token.SessToken {
+		_token := token.New(t.Type)
+		_token.Set("user-id", strconv.FormatInt(user.ID, 10))
+		_token.Set(TwoFactorClaim, "true")
+		return _token.Sign(user.Hash)
+	}
+
+	started := now
+	if unix, err := strconv.ParseInt(t.Get(startedClaim), 10, 64); err == nil {
+		started = time.Unix(unix, 0)
+	}
+	expires := sessionExpires(started, now)
+	if t.Expires() != 0 {
+		expires = time.Unix(t.Expires(), 0)
+	}
+	return signToken(user, started, expires, true)
+}
+
+// renewToken returns a new session token if sliding sessions are enabled and the token
+// is within the renewal window, which is the second half of its lifetime.
+// A session is not renewed beyond the maximum lifetime since the login.
+func renewToken(t *token.Token, user *model.User, now time.Time) (string, bool, error) {
+	if !server.Config.Server.SessionSliding || t.Type != token.SessToken || t.Expires() == 0 {
+		return "", false, nil
+	}
+
+	expires := time.Unix(t.Expires(), 0)
+	if expires.Sub(now) > server.Config.Server.SessionExpires/2 {
+		return "", false, nil
+	}
+
+	started := expires.Add(-server.Config.Server.SessionExpires)
+	if unix, err := strconv.ParseInt(t.Get(startedClaim), 10, 64); err == nil {
+		started = time.Unix(unix, 0)
+	}
+
+	renewed := sessionExpires(started, now)
+	if !renewed.After(expires) {
+		// the maximum lifetime is reached
+		return "", false, nil
+	}
+
+	tokenString, err := signToken(user, started, renewed, t.Get(TwoFactorClaim) == "true")
+	if err != nil {
+		return "", false, err
+	}
+	return tokenString, true, nil
+}
+
+func sessionExpires(started, now time.Time) time.Time {
+	expires := now.Add(server.Config.Server.SessionExpires)
+	if !server.Config.Server.SessionSliding {
+		return expires
+	}
+	if limit := started.Add(server.Config.Server.SessionMaxLifetime); expires.After(limit) {
+		return limit
+	}
+	return expires
+}
+
+func signToken(user *model.User, started, expires time.Time, twoFactor bool) (string, error) {
+	_token := token.New(token.SessToken)
+	_token.Set("user-id", strconv.FormatInt(user.ID, 10))
+	_token.Set(startedClaim, strconv.FormatInt(started.Unix(), 10))
+	if twoFactor {
+		_token.Set(TwoFactorClaim, "true")
+	}
+	return _token.SignExpires(user.Hash, expires.Unix())
+}
@@ -0,0 +1,116 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
+)
+
+func parseSessionToken(t *testing.T, user *model.User, raw string) *token.Token {
+	t.Helper()
+	parsed, err := token.Parse([]token.Type{token.SessToken}, raw, func(_ *token.Token) (string, error) {
+		return user.Hash, nil
+	})
+	require.NoError(t, err)
+	return parsed
+}
+
+func TestRenewToken(t *testing.T) {
+	server.Config.Server.SessionExpires = 72 * time.Hour
+	server.Config.Server.SessionMaxLifetime = 7 * 24 * time.Hour
+	defer func() {
+		server.Config.Server.SessionExpires = 0
+		server.Config.Server.SessionSliding = false
+		server.Config.Server.SessionMaxLifetime = 0
+	}()
+
+	user := &model.User{ID: 1, Hash: "hash"}
+	login := time.Now().Truncate(time.Second)
+
+	newSession := func(t *testing.T) *token.Token {
+		raw, err := NewToken(user, login)
+		require.NoError(t, err)
+		return parseSessionToken(t, user, raw)
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		server.Config.Server.SessionSliding = false
+		_, renewed, err := renewToken(newSession(t), user, login.Add(71*time.Hour))
+		assert.NoError(t, err)
+		assert.False(t, renewed)
+	})
+
+	server.Config.Server.SessionSliding = true
+
+	t.Run("before renewal window", func(t *testing.T) {
+		_, renewed, err := renewToken(newSession(t), user, login.Add(35*time.Hour))
+		assert.NoError(t, err)
+		assert.False(t, renewed)
+	})
+
+	t.Run("within renewal window", func(t *testing.T) {
+		now := login.Add(37 * time.Hour)
+		raw, renewed, err := renewToken(newSession(t), user, now)
+		require.NoError(t, err)
+		require.True(t, renewed)
+
+		parsed := parseSessionToken(t, user, raw)
+		assert.Equal(t, now.Add(72*time.Hour).Unix(), parsed.Expires())
+		assert.Equal(t, "1", parsed.Get("user-id"))
+	})
+
+	t.Run("absolute cap", func(t *testing.T) {
+		session := newSession(t)
+		now := login
+		for range 10 {
+			now = now.Add(40 * time.Hour)
+			raw, renewed, err := renewToken(session, user, now)
+			require.NoError(t, err)
+			if !renewed {
+				break
+			}
+			session = parseSessionToken(t, user, raw)
+		}
+		assert.Equal(t, login.Add(7*24*time.Hour).Unix(), session.Expires())
+
+		// the session is not renewed anymore once the maximum lifetime is reached
+		_, renewed, err := renewToken(session, user, login.Add(7*24*time.Hour-time.Hour))
+		assert.NoError(t, err)
+		assert.False(t, renewed)
+	})
+
+	t.Run("user token", func(t *testing.T) {
+		userToken := token.New(token.UserToken)
+		userToken.Set("user-id", "1")
+		raw, err := userToken.SignExpires(user.Hash, login.Add(time.Hour).Unix())
+		require.NoError(t, err)
+		parsed, err := token.Parse([]token.Type{token.UserToken}, raw, func(_ *token.Token) (string, error) {
+			return user.Hash, nil
+		})
+		require.NoError(t, err)
+
+		_, renewed, err := renewToken(parsed, user, login)
+		assert.NoError(t, err)
+		assert.False(t, renewed)
+	})
+}
@@ -17,6 +17,7 @@ package session
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
@@ -24,6 +25,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
 )
 
@@ -54,6 +56,7 @@ func SetUser() gin.HandlerFunc {
 		})
 		if err == nil {
 			c.Set("user", user)
+			c.Set("token", t)
 
 			// if this is a session token (ie not the API token)
 			// this means the user is accessing with a web browser,
@@ -68,12 +71,61 @@ func SetUser() gin.HandlerFunc {
 					c.AbortWithStatus(http.StatusUnauthorized)
 					return
 				}
+
+				// renew the session of active users when sliding sessions are enabled
+				if tokenString, renewed, err := renewToken(t, user, time.Now()); err != nil {
+					log.Error().Err(err).Msgf("cannot renew session of user %s", user.Login)
+				} else if renewed {
+					httputil.SetCookie(c.Writer, c.Request, "user_sess", tokenString, server.Config.Server.Cookie)
+				}
 			}
 		}
 		c.Next()
 	}
 }
 
+// Token returns the token the user of the request authenticated with.
+func Token(c *gin.Context) *token.Token {
+	v, ok := c.Get("token")
+	if !ok {
+		return nil
+	}
+	t, ok := v.(*token.Token)
+	if !ok {
+		return nil
+	}
+	return t
+}
+
+// TwoFactorRequired returns if the user has to verify a second factor to get admin permissions.
+func TwoFactorRequired(user *model.User) bool {
+	return user.Admin && (user.TOTPEnabled || server.Config.Permissions.AdminRequire2FA)
+}
+
+// TwoFactorVerified returns if the token of the request was issued after verifying the second factor.
+func TwoFactorVerified(c *gin.Context) bool {
+	t := Token(c)
+	return t != nil && t.Get(TwoFactorClaim) == "true"
+}
+
+// IsAdmin returns if the user of the request has admin permissions.
+// Admins who have to use a second factor only get them after verifying it.
+func IsAdmin(c *gin.Context) bool {
+	user := User(c)
+	return user != nil && user.Admin && (!TwoFactorRequired(user) || TwoFactorVerified(c))
+}
+
+// ScopedUser returns a copy of the user of the request, which is only an admin if the request has admin permissions.
+func ScopedUser(c *gin.Context) *model.User {
+	user := User(c)
+	if user == nil {
+		return nil
+	}
+	scoped := *user
+	scoped.Admin = IsAdmin(c)
+	return &scoped
+}
+
 func MustAdmin() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		user := User(c)
@@ -81,7 +133,7 @@ func MustAdmin() gin.HandlerFunc {
 		case user == nil:
 			c.String(http.StatusUnauthorized, "User not authorized")
 			c.Abort()
-		case !user.Admin:
+		case !IsAdmin(c):
 			c.String(http.StatusForbidden, "User not authorized")
 			c.Abort()
 		default:
@@ -137,7 +189,7 @@ func MustOrgMember(admin bool) gin.HandlerFunc {
 		}
 
 		// User can access his own, admin can access all
-		if (org.Name == user.Login) || user.Admin {
+		if (org.Name == user.Login) || IsAdmin(c) {
 			c.Next()
 			return
 		}
@@ -0,0 +1,142 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server"
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
+)
+
+func TestTwoFactorAdminGate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	defer func() { server.Config.Permissions.AdminRequire2FA = false }()
+
+	verified := token.New(token.SessToken)
+	verified.Set(TwoFactorClaim, "true")
+
+	tests := []struct {
+		name       string
+		require2FA bool
+		user       *model.User
+		token      *token.Token
+		admin      bool
+	}{
+		{
+			name:  "admin without second factor",
+			user:  &model.User{Admin: true},
+			token: token.New(token.SessToken),
+			admin: true,
+		},
+		{
+			name:       "required but not verified",
+			require2FA: true,
+			user:       &model.User{Admin: true},
+			token:      token.New(token.SessToken),
+			admin:      false,
+		},
+		{
+			name:       "required and verified",
+			require2FA: true,
+			user:       &model.User{Admin: true, TOTPEnabled: true},
+			token:      verified,
+			admin:      true,
+		},
+		{
+			name:       "required for user token",
+			require2FA: true,
+			user:       &model.User{Admin: true, TOTPEnabled: true},
+			token:      token.New(token.UserToken),
+			admin:      false,
+		},
+		{
+			name:  "enrolled admin not verified",
+			user:  &model.User{Admin: true, TOTPEnabled: true},
+			token: token.New(token.SessToken),
+			admin: false,
+		},
+		{
+			name:       "verified non admin",
+			require2FA: true,
+			user:       &model.User{TOTPEnabled: true},
+			token:      verified,
+			admin:      false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server.Config.Permissions.AdminRequire2FA = tt.require2FA
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request, _ = http.NewRequest(http.MethodGet, "/", nil)
+			c.Set("user", tt.user)
+			c.Set("token", tt.token)
+
+			assert.Equal(t, tt.admin, IsAdmin(c))
+			assert.Equal(t, tt.admin, ScopedUser(c).Admin)
+			assert.Equal(t, tt.user.Admin, User(c).Admin, "the stored admin flag is never changed")
+
+			MustAdmin()(c)
+			if tt.admin {
+				assert.False(t, c.IsAborted())
+			} else {
+				assert.True(t, c.IsAborted())
+				assert.Equal(t, http.StatusForbidden, w.Code)
+			}
+		})
+	}
+}
+
+func TestNewVerifiedToken(t *testing.T) {
+	server.Config.Server.SessionExpires = time.Hour
+	server.Config.Server.SessionSliding = true
+	server.Config.Server.SessionMaxLifetime = 24 * time.Hour
+	defer func() {
+		server.Config.Server.SessionExpires = 0
+		server.Config.Server.SessionSliding = false
+		server.Config.Server.SessionMaxLifetime = 0
+	}()
+
+	user := &model.User{ID: 1, Hash: "hash"}
+	login := time.Now().Truncate(time.Second)
+
+	raw, err := NewToken(user, login)
+	require.NoError(t, err)
+	session := parseSessionToken(t, user, raw)
+	assert.Empty(t, session.Get(TwoFactorClaim))
+
+	raw, err = NewVerifiedToken(session, user, login.Add(time.Minute))
+	require.NoError(t, err)
+	verified := parseSessionToken(t, user, raw)
+	assert.Equal(t, "true", verified.Get(TwoFactorClaim))
+	assert.Equal(t, session.Expires(), verified.Expires(), "verifying keeps the expiry of the session")
+
+	// renewed sessions stay verified
+	raw, renewed, err := renewToken(verified, user, login.Add(45*time.Minute))
+	require.NoError(t, err)
+	require.True(t, renewed)
+	assert.Equal(t, "true", parseSessionToken(t, user, raw).Get(TwoFactorClaim))
+}
@@ -45,6 +45,7 @@ func Load(noRouteHandler http.HandlerFunc, middleware ...gin.HandlerFunc) http.H
 	})
 
 	e.Use(header.NoCache)
+	e.Use(header.CORS(server.Config.Server.RootPath+"/api/", server.Config.Server.CORS))
 	e.Use(header.Options)
 	e.Use(header.Secure)
 	e.Use(middleware...)
@@ -0,0 +1,110 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreaper
+
+import (
+	"context"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+const reapInterval = time.Minute
+
+// Reaper marks agents offline which stopped reporting their health and removes dead agents.
+type Reaper struct {
+	store       store.Store
+	queue       queue.Queue
+	idleTimeout time.Duration
+	deadTimeout time.Duration
+	now         func() time.Time
+}
+
+// New returns a reaper marking agents offline after idleTimeout and removing agents registered with
+// the agent secret after deadTimeout. A zero timeout disables the respective action.
+func New(s store.Store, q queue.Queue, idleTimeout, deadTimeout time.Duration) *Reaper {
+	return &Reaper{
+		store:       s,
+		queue:       q,
+		idleTimeout: idleTimeout,
+		deadTimeout: deadTimeout,
+		now:         time.Now,
+	}
+}
+
+// Run reaps the agents periodically until the context is canceled.
+func (r *Reaper) Run(ctx context.Context) {
+	ticker := time.NewTicker(reapInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.Reap(ctx)
+		}
+	}
+}
+
+// Reap checks all agents once and returns how many agents were marked offline and removed.
+// Agents which still have running tasks are never removed, their tasks are handled by the queue.
+func (r *Reaper) Reap(ctx context.Context) (offline, removed int) {
+	agents, err := r.store.AgentList(&model.ListOptions{All: true})
+	if err != nil {
+		log.Error().Err(err).Msg("could not list agents")
+		return 0, 0
+	}
+
+	busy := map[int64]bool{}
+	for _, task := range r.queue.Info(ctx).Running {
+		busy[task.AgentID] = true
+	}
+
+	now := r.now()
+	for _, agent := range agents {
+		lastSeen := agent.LastContact
+		if lastSeen == 0 {
+			lastSeen = agent.Created
+		}
+		idle := now.Sub(time.Unix(lastSeen, 0))
+
+		if r.deadTimeout > 0 && idle >= r.deadTimeout && agent.IsSystemAgent() && !busy[agent.ID] {
+			if err := r.store.AgentDelete(agent); err != nil {
+				log.Error().Err(err).Int64("agent", agent.ID).Msg("could not remove dead agent")
+				continue
+			}
+			r.queue.KickAgentWorkers(agent.ID)
+			log.Info().Int64("agent", agent.ID).Msgf("removed agent '%s' which did not report for %s", agent.Name, idle)
+			removed++
+			continue
+		}
+
+		if r.idleTimeout > 0 && idle >= r.idleTimeout && !agent.Offline {
+			agent.Offline = true
+			if err := r.store.AgentUpdate(agent); err != nil {
+				log.Error().Err(err).Int64("agent", agent.ID).Msg("could not mark agent offline")
+				continue
+			}
+			log.Debug().Int64("agent", agent.ID).Msgf("marked agent '%s' offline", agent.Name)
+			offline++
+		}
+	}
+	return offline, removed
+}
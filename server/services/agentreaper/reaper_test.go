@@ -0,0 +1,103 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentreaper
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/queue"
+	queue_mocks "go.woodpecker-ci.org/woodpecker/v3/server/queue/mocks"
+	store_mocks "go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+)
+
+func TestReap(t *testing.T) {
+	start := time.Unix(1700000000, 0)
+	now := start
+
+	systemAgent := &model.Agent{ID: 1, OwnerID: model.IDNotSet, LastContact: start.Unix()}
+	userAgent := &model.Agent{ID: 2, OwnerID: 5, LastContact: start.Unix()}
+	busyAgent := &model.Agent{ID: 3, OwnerID: model.IDNotSet, LastContact: start.Unix()}
+	activeAgent := &model.Agent{ID: 4, OwnerID: model.IDNotSet}
+	agents := []*model.Agent{systemAgent, userAgent, busyAgent, activeAgent}
+
+	store := store_mocks.NewMockStore(t)
+	store.On("AgentList", &model.ListOptions{All: true}).Return(func(*model.ListOptions) ([]*model.Agent, error) {
+		return agents, nil
+	})
+	store.On("AgentUpdate", mock.Anything).Return(nil)
+	store.On("AgentDelete", systemAgent).Return(func(*model.Agent) error {
+		agents = agents[1:]
+		return nil
+	}).Once()
+
+	q := queue_mocks.NewMockQueue(t)
+	q.On("Info", mock.Anything).Return(queue.InfoT{Running: []*model.Task{{AgentID: busyAgent.ID}}})
+	q.On("KickAgentWorkers", systemAgent.ID).Return().Once()
+
+	reaper := New(store, q, 5*time.Minute, time.Hour)
+	reaper.now = func() time.Time { return now }
+
+	// the active agent keeps reporting its health
+	heartbeat := func() { activeAgent.LastContact = now.Unix() }
+
+	// briefly idle agents are kept as they are
+	now = start.Add(time.Minute)
+	heartbeat()
+	offline, removed := reaper.Reap(t.Context())
+	assert.Equal(t, 0, offline)
+	assert.Equal(t, 0, removed)
+
+	// after the idle timeout the agents are marked offline
+	now = start.Add(6 * time.Minute)
+	heartbeat()
+	offline, removed = reaper.Reap(t.Context())
+	assert.Equal(t, 3, offline)
+	assert.Equal(t, 0, removed)
+	assert.True(t, systemAgent.Offline)
+	assert.True(t, userAgent.Offline)
+	assert.True(t, busyAgent.Offline)
+	assert.False(t, activeAgent.Offline)
+
+	// offline agents are not updated again
+	offline, removed = reaper.Reap(t.Context())
+	assert.Equal(t, 0, offline)
+	assert.Equal(t, 0, removed)
+
+	// after the dead timeout only the agent registered with the agent secret and without running tasks is removed
+	now = start.Add(2 * time.Hour)
+	heartbeat()
+	offline, removed = reaper.Reap(t.Context())
+	assert.Equal(t, 0, offline)
+	assert.Equal(t, 1, removed)
+	assert.Equal(t, []*model.Agent{userAgent, busyAgent, activeAgent}, agents)
+	store.AssertNumberOfCalls(t, "AgentUpdate", 3)
+}
+
+func TestReapDisabled(t *testing.T) {
+	store := store_mocks.NewMockStore(t)
+	store.On("AgentList", mock.Anything).Return([]*model.Agent{{ID: 1, OwnerID: model.IDNotSet, LastContact: 1}}, nil)
+
+	q := queue_mocks.NewMockQueue(t)
+	q.On("Info", mock.Anything).Return(queue.InfoT{})
+
+	offline, removed := New(store, q, 0, 0).Reap(t.Context())
+	assert.Equal(t, 0, offline)
+	assert.Equal(t, 0, removed)
+}
@@ -0,0 +1,185 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agenttoken manages the shared agent tokens stored in the database.
+// Together with the agent secret of the server config they form the set of tokens
+// agents can register with, so the agent secret can be rotated without a downtime.
+// Tokens are only stored hashed, the token itself is only returned once when it is added.
+package agenttoken
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/tokenhash"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+const configKey = "agent-tokens"
+
+// ErrInUse is returned if a token should be retired while agents still use it.
+var ErrInUse = errors.New("agent token is still used by agents")
+
+// List returns all stored agent tokens with their hashed tokens.
+func List(s store.Store) ([]*model.AgentToken, error) {
+	data, err := s.ServerConfigGet(configKey)
+	if errors.Is(err, types.RecordNotExist) {
+		return []*model.AgentToken{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens []*model.AgentToken
+	if err := json.Unmarshal([]byte(data), &tokens); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// Add creates a new agent token and stores it hashed with the given algorithm.
+// The returned token contains the unhashed token.
+func Add(s store.Store, now time.Time, algorithm string) (*model.AgentToken, error) {
+	tokens, err := List(s)
+	if err != nil {
+		return nil, err
+	}
+
+	plain := model.GenerateNewAgentToken()
+	hashed, err := tokenhash.Hash(algorithm, plain)
+	if err != nil {
+		return nil, err
+	}
+
+	token := &model.AgentToken{
+		ID:      1,
+		Token:   hashed,
+		Created: now.Unix(),
+	}
+	for _, t := range tokens {
+		token.ID = max(token.ID, t.ID+1)
+	}
+
+	if err := save(s, append(tokens, token)); err != nil {
+		return nil, err
+	}
+
+	created := *token
+	created.Token = plain
+	return &created, nil
+}
+
+// Retire removes the agent token with the given id. Unless force is set,
+// the token is only removed if no agent is registered with it anymore.
+// If forced, the token is cleared from the agents still registered with it,
+// so they can't authenticate until they register with another token. These agents are returned.
+func Retire(s store.Store, id int64, force bool) ([]*model.Agent, error) {
+	tokens, err := List(s)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, token := range tokens {
+		if token.ID != id {
+			continue
+		}
+
+		if !force {
+			_, err := s.AgentFindByToken(token.Token)
+			if err == nil {
+				return nil, ErrInUse
+			}
+			if !errors.Is(err, types.RecordNotExist) {
+				return nil, err
+			}
+		}
+
+		if err := save(s, append(tokens[:i], tokens[i+1:]...)); err != nil {
+			return nil, err
+		}
+		if !force {
+			return nil, nil
+		}
+		return revoke(s, token.Token)
+	}
+	return nil, types.RecordNotExist
+}
+
+// revoke clears the token of all system agents registered with the given hashed token.
+func revoke(s store.Store, hashed string) ([]*model.Agent, error) {
+	agents, err := s.AgentList(&model.ListOptions{All: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var revoked []*model.Agent
+	for _, agent := range agents {
+		if !agent.IsSystemAgent() || agent.Token != hashed {
+			continue
+		}
+		agent.Token = ""
+		if err := s.AgentUpdate(agent); err != nil {
+			return revoked, err
+		}
+		revoked = append(revoked, agent)
+	}
+	return revoked, nil
+}
+
+// Find returns the stored agent token agents can register with using the given token,
+// or nil if there is none. If the stored token was hashed with another algorithm,
+// it is hashed again with the given one. Tokens the migration could not hash, as the
+// server config was encrypted, are still stored in plain and get hashed here as well.
+func Find(s store.Store, token, algorithm string) (*model.AgentToken, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	tokens, err := List(s)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		matches := tokenhash.Verify(t.Token, token)
+		if !tokenhash.IsHashed(t.Token) {
+			matches = subtle.ConstantTimeCompare([]byte(t.Token), []byte(token)) == 1
+		}
+		if !matches {
+			continue
+		}
+
+		if !tokenhash.IsCurrent(algorithm, t.Token) {
+			if t.Token, err = tokenhash.Hash(algorithm, token); err != nil {
+				return nil, err
+			}
+			if err := save(s, tokens); err != nil {
+				return nil, err
+			}
+		}
+		return t, nil
+	}
+	return nil, nil
+}
+
+func save(s store.Store, tokens []*model.AgentToken) error {
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	return s.ServerConfigSet(configKey, string(data))
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agenttoken
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/tokenhash"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+func newConfigStore(t *testing.T) *mocks.MockStore {
+	config := map[string]string{}
+	store := mocks.NewMockStore(t)
+	store.On("ServerConfigGet", mock.Anything).Maybe().Return(func(key string) (string, error) {
+		value, ok := config[key]
+		if !ok {
+			return "", types.RecordNotExist
+		}
+		return value, nil
+	})
+	store.On("ServerConfigSet", mock.Anything, mock.Anything).Maybe().Return(func(key, value string) error {
+		config[key] = value
+		return nil
+	})
+	return store
+}
+
+func TestTokens(t *testing.T) {
+	store := newConfigStore(t)
+
+	tokens, err := List(store)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	first, err := Add(store, time.Now(), tokenhash.DefaultAlgorithm)
+	require.NoError(t, err)
+	second, err := Add(store, time.Now(), tokenhash.DefaultAlgorithm)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, first.ID)
+	assert.EqualValues(t, 2, second.ID)
+	assert.NotEqual(t, first.Token, second.Token)
+
+	// only the hashes are stored
+	tokens, err = List(store)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	firstHash, err := tokenhash.Hash(tokenhash.DefaultAlgorithm, first.Token)
+	require.NoError(t, err)
+	assert.Equal(t, firstHash, tokens[0].Token)
+
+	for _, token := range []*model.AgentToken{first, second} {
+		found, err := Find(store, token.Token, tokenhash.DefaultAlgorithm)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, token.ID, found.ID)
+	}
+	for _, token := range []string{"unknown", "", firstHash} {
+		found, err := Find(store, token, tokenhash.DefaultAlgorithm)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	}
+
+	t.Run("retire used token", func(t *testing.T) {
+		store.On("AgentFindByToken", firstHash).Once().Return(&model.Agent{ID: 1}, nil)
+		_, err := Retire(store, first.ID, false)
+		assert.ErrorIs(t, err, ErrInUse)
+
+		found, err := Find(store, first.Token, tokenhash.DefaultAlgorithm)
+		require.NoError(t, err)
+		assert.NotNil(t, found)
+	})
+
+	t.Run("retire unused token", func(t *testing.T) {
+		store.On("AgentFindByToken", firstHash).Once().Return(nil, types.RecordNotExist)
+		revoked, err := Retire(store, first.ID, false)
+		require.NoError(t, err)
+		assert.Empty(t, revoked)
+
+		found, err := Find(store, first.Token, tokenhash.DefaultAlgorithm)
+		require.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("force retire", func(t *testing.T) {
+		secondHash, _ := tokenhash.Hash(tokenhash.DefaultAlgorithm, second.Token)
+		systemAgent := &model.Agent{ID: 1, OwnerID: model.IDNotSet, Token: secondHash}
+		store.On("AgentList", mock.Anything).Once().Return([]*model.Agent{
+			systemAgent,
+			{ID: 2, OwnerID: model.IDNotSet, Token: "other"},
+			{ID: 3, OwnerID: 1, Token: secondHash},
+		}, nil)
+		store.On("AgentUpdate", systemAgent).Once().Return(nil)
+
+		revoked, err := Retire(store, second.ID, true)
+		require.NoError(t, err)
+		assert.Equal(t, []*model.Agent{systemAgent}, revoked)
+		assert.Empty(t, systemAgent.Token)
+
+		tokens, err := List(store)
+		require.NoError(t, err)
+		assert.Empty(t, tokens)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := Retire(store, 42, true)
+		assert.ErrorIs(t, err, types.RecordNotExist)
+	})
+}
+
+func TestFindUpgradesHash(t *testing.T) {
+	store := newConfigStore(t)
+
+	token, err := Add(store, time.Now(), "sha512")
+	require.NoError(t, err)
+
+	found, err := Find(store, token.Token, tokenhash.DefaultAlgorithm)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+	assert.True(t, tokenhash.IsCurrent(tokenhash.DefaultAlgorithm, found.Token))
+
+	tokens, err := List(store)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, found.Token, tokens[0].Token)
+	assert.True(t, tokenhash.Verify(tokens[0].Token, token.Token))
+}
+
+func TestFindHashesPlainToken(t *testing.T) {
+	store := newConfigStore(t)
+	// left in plain by the migration, as the server config was encrypted
+	require.NoError(t, store.ServerConfigSet(configKey, `[{"id":1,"token":"plain-token"}]`))
+
+	found, err := Find(store, "wrong-token", tokenhash.DefaultAlgorithm)
+	require.NoError(t, err)
+	assert.Nil(t, found)
+
+	found, err = Find(store, "plain-token", tokenhash.DefaultAlgorithm)
+	require.NoError(t, err)
+	require.NotNil(t, found)
+
+	tokens, err := List(store)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.True(t, tokenhash.IsCurrent(tokenhash.DefaultAlgorithm, tokens[0].Token))
+	assert.True(t, tokenhash.Verify(tokens[0].Token, "plain-token"))
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package serverconfig wraps a store to encrypt the server config values at rest.
+package serverconfig
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/crypto/sha3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+// encryptedPrefix marks encrypted values, values without it were written before encryption was enabled.
+const encryptedPrefix = "encrypted:v1:"
+
+const keySize = 32
+
+// EncryptedStore encrypts the values written by ServerConfigSet with AES-GCM and decrypts them on ServerConfigGet.
+// The key of a config is used as associated data, so values can not be swapped between keys.
+type EncryptedStore struct {
+	store.Store
+	cipher cipher.AEAD
+}
+
+// NewAESCipher returns an AES-GCM cipher with a key derived from the given password.
+func NewAESCipher(password string) (cipher.AEAD, error) {
+	if password == "" {
+		return nil, errors.New("encryption key is empty")
+	}
+
+	key := make([]byte, keySize)
+	sha := sha3.NewShake256()
+	if _, err := sha.Write([]byte(password)); err != nil {
+		return nil, err
+	}
+	if _, err := sha.Read(key); err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// New wraps the store. Any cipher.AEAD can be used, e.g. one backed by a KMS.
+func New(s store.Store, aead cipher.AEAD) *EncryptedStore {
+	return &EncryptedStore{Store: s, cipher: aead}
+}
+
+func (s *EncryptedStore) ServerConfigGet(key string) (string, error) {
+	value, err := s.Store.ServerConfigGet(key)
+	if err != nil {
+		return "", err
+	}
+	return s.decrypt(key, value)
+}
+
+func (s *EncryptedStore) ServerConfigSet(key, value string) error {
+	return s.Store.ServerConfigSet(key, s.encrypt(key, value))
+}
+
+// EncryptAll encrypts all stored values again, including the ones written before encryption was enabled.
+// It returns the number of updated values.
+func (s *EncryptedStore) EncryptAll() (int, error) {
+	configs, err := s.Store.ServerConfigList()
+	if err != nil {
+		return 0, err
+	}
+
+	for i, config := range configs {
+		value, err := s.decrypt(config.Key, config.Value)
+		if err != nil {
+			return i, err
+		}
+		if err := s.ServerConfigSet(config.Key, value); err != nil {
+			return i, err
+		}
+	}
+	return len(configs), nil
+}
+
+func (s *EncryptedStore) encrypt(key, value string) string {
+	nonce := random.GetRandomBytes(uint32(s.cipher.NonceSize()))
+	ciphertext := s.cipher.Seal(nonce, nonce, []byte(value), []byte(key))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(ciphertext)
+}
+
+func (s *EncryptedStore) decrypt(key, value string) (string, error) {
+	encoded, ok := strings.CutPrefix(value, encryptedPrefix)
+	if !ok {
+		// legacy value, it gets encrypted on the next write
+		log.Trace().Msgf("server config '%s' is not encrypted", key)
+		return value, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("could not decode server config '%s': %w", key, err)
+	}
+	if len(data) < s.cipher.NonceSize() {
+		return "", fmt.Errorf("could not decrypt server config '%s': value too short", key)
+	}
+
+	nonce, ciphertext := data[:s.cipher.NonceSize()], data[s.cipher.NonceSize():]
+	plaintext, err := s.cipher.Open(nil, nonce, ciphertext, []byte(key))
+	if err != nil {
+		return "", fmt.Errorf("could not decrypt server config '%s', the encryption key might be wrong: %w", key, err)
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package serverconfig
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+func newConfigStore(t *testing.T, config map[string]string) *mocks.MockStore {
+	store := mocks.NewMockStore(t)
+	store.On("ServerConfigGet", mock.Anything).Maybe().Return(func(key string) (string, error) {
+		value, ok := config[key]
+		if !ok {
+			return "", types.RecordNotExist
+		}
+		return value, nil
+	})
+	store.On("ServerConfigSet", mock.Anything, mock.Anything).Maybe().Return(func(key, value string) error {
+		config[key] = value
+		return nil
+	})
+	store.On("ServerConfigList").Maybe().Return(func() ([]*model.ServerConfig, error) {
+		var configs []*model.ServerConfig
+		for key, value := range config {
+			configs = append(configs, &model.ServerConfig{Key: key, Value: value})
+		}
+		slices.SortFunc(configs, func(a, b *model.ServerConfig) int { return strings.Compare(a.Key, b.Key) })
+		return configs, nil
+	})
+	return store
+}
+
+func newEncryptedStore(t *testing.T, config map[string]string, key string) *EncryptedStore {
+	aead, err := NewAESCipher(key)
+	require.NoError(t, err)
+	return New(newConfigStore(t, config), aead)
+}
+
+func TestRoundTrip(t *testing.T) {
+	config := map[string]string{}
+	s := newEncryptedStore(t, config, "key")
+
+	require.NoError(t, s.ServerConfigSet("jwt-secret", "secret"))
+	assert.True(t, strings.HasPrefix(config["jwt-secret"], encryptedPrefix))
+	assert.NotContains(t, config["jwt-secret"], "secret")
+
+	value, err := s.ServerConfigGet("jwt-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	_, err = s.ServerConfigGet("missing")
+	assert.ErrorIs(t, err, types.RecordNotExist)
+}
+
+func TestKeyMismatch(t *testing.T) {
+	config := map[string]string{}
+	require.NoError(t, newEncryptedStore(t, config, "key").ServerConfigSet("jwt-secret", "secret"))
+
+	_, err := newEncryptedStore(t, config, "other-key").ServerConfigGet("jwt-secret")
+	assert.ErrorContains(t, err, "could not decrypt server config 'jwt-secret'")
+
+	// values are bound to their key
+	config["other"] = config["jwt-secret"]
+	_, err = newEncryptedStore(t, config, "key").ServerConfigGet("other")
+	assert.Error(t, err)
+}
+
+func TestLegacyValueUpgrade(t *testing.T) {
+	config := map[string]string{"jwt-secret": "secret", "signature-private-key": "private"}
+	s := newEncryptedStore(t, config, "key")
+
+	value, err := s.ServerConfigGet("jwt-secret")
+	require.NoError(t, err)
+	assert.Equal(t, "secret", value)
+
+	require.NoError(t, s.ServerConfigSet("jwt-secret", value))
+	assert.True(t, strings.HasPrefix(config["jwt-secret"], encryptedPrefix))
+	assert.Equal(t, "private", config["signature-private-key"])
+
+	count, err := s.EncryptAll()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+	for key, want := range map[string]string{"jwt-secret": "secret", "signature-private-key": "private"} {
+		assert.True(t, strings.HasPrefix(config[key], encryptedPrefix))
+		value, err := s.ServerConfigGet(key)
+		require.NoError(t, err)
+		assert.Equal(t, want, value)
+	}
+}
@@ -0,0 +1,158 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package jwtsecret manages the secret used to sign server issued tokens.
+// After a rotation the previous secrets stay valid for verification until they expire.
+package jwtsecret
+
+import (
+	"encoding/base32"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
+)
+
+const (
+	currentID  = "jwt-secret"
+	previousID = "jwt-secret-previous"
+)
+
+type previousSecret struct {
+	Secret  string `json:"secret"`
+	Expires int64  `json:"expires"`
+}
+
+// Current returns the secret used to sign new tokens and creates it if it does not exist yet.
+func Current(s store.Store) (string, error) {
+	secret, err := s.ServerConfigGet(currentID)
+	if errors.Is(err, types.RecordNotExist) {
+		secret = newSecret()
+		if err := s.ServerConfigSet(currentID, secret); err != nil {
+			return "", err
+		}
+		log.Debug().Msg("created jwt secret")
+		return secret, nil
+	}
+	return secret, err
+}
+
+// Rotate replaces the current secret by a new one, which is returned.
+// Tokens signed with the old secret stay valid until the grace period is over.
+func Rotate(s store.Store, now time.Time, grace time.Duration) (string, error) {
+	current, err := Current(s)
+	if err != nil {
+		return "", err
+	}
+
+	previous, err := loadPrevious(s, now)
+	if err != nil {
+		return "", err
+	}
+	previous = append(previous, previousSecret{Secret: current, Expires: now.Add(grace).Unix()})
+
+	data, err := json.Marshal(previous)
+	if err != nil {
+		return "", err
+	}
+	if err := s.ServerConfigSet(previousID, string(data)); err != nil {
+		return "", err
+	}
+
+	secret := newSecret()
+	if err := s.ServerConfigSet(currentID, secret); err != nil {
+		return "", err
+	}
+	log.Info().Msg("rotated jwt secret")
+	return secret, nil
+}
+
+// Valid returns all secrets tokens can be verified with,
+// the current secret first followed by the previous secrets which did not expire yet.
+func Valid(s store.Store, now time.Time) ([]string, error) {
+	current, err := Current(s)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := loadPrevious(s, now)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := []string{current}
+	for i := len(previous) - 1; i >= 0; i-- {
+		secrets = append(secrets, previous[i].Secret)
+	}
+	return secrets, nil
+}
+
+// Parse parses a token which was signed with the given secret or any other valid secret.
+func Parse(s store.Store, allowedTypes []token.Type, raw, secret string) (*token.Token, error) {
+	t, err := token.Parse(allowedTypes, raw, func(_ *token.Token) (string, error) {
+		return secret, nil
+	})
+	if err == nil {
+		return t, nil
+	}
+
+	secrets, loadErr := Valid(s, time.Now())
+	if loadErr != nil {
+		return nil, errors.Join(err, loadErr)
+	}
+	for _, other := range secrets {
+		if other == secret {
+			continue
+		}
+		if t, otherErr := token.Parse(allowedTypes, raw, func(_ *token.Token) (string, error) {
+			return other, nil
+		}); otherErr == nil {
+			return t, nil
+		}
+	}
+	return nil, err
+}
+
+// loadPrevious returns the previous secrets which did not expire yet, the oldest first.
+func loadPrevious(s store.Store, now time.Time) ([]previousSecret, error) {
+	data, err := s.ServerConfigGet(previousID)
+	if errors.Is(err, types.RecordNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var previous []previousSecret
+	if err := json.Unmarshal([]byte(data), &previous); err != nil {
+		return nil, err
+	}
+
+	valid := previous[:0]
+	for _, p := range previous {
+		if p.Expires > now.Unix() {
+			valid = append(valid, p)
+		}
+	}
+	return valid, nil
+}
+
+func newSecret() string {
+	return base32.StdEncoding.EncodeToString(random.GetRandomBytes(32)) //nolint:mnd
+}
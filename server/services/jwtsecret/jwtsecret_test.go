@@ -0,0 +1,102 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jwtsecret
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
+)
+
+func newConfigStore(t *testing.T) *mocks.MockStore {
+	config := map[string]string{}
+	store := mocks.NewMockStore(t)
+	store.On("ServerConfigGet", mock.Anything).Maybe().Return(func(key string) (string, error) {
+		value, ok := config[key]
+		if !ok {
+			return "", types.RecordNotExist
+		}
+		return value, nil
+	})
+	store.On("ServerConfigSet", mock.Anything, mock.Anything).Maybe().Return(func(key, value string) error {
+		config[key] = value
+		return nil
+	})
+	return store
+}
+
+func TestRotate(t *testing.T) {
+	store := newConfigStore(t)
+	now := time.Now()
+
+	old, err := Current(store)
+	require.NoError(t, err)
+	current, err := Current(store)
+	require.NoError(t, err)
+	assert.Equal(t, old, current, "the secret must only be created once")
+
+	oldToken, err := token.New(token.OAuthStateToken).Sign(old)
+	require.NoError(t, err)
+
+	current, err = Rotate(store, now, time.Hour)
+	require.NoError(t, err)
+	assert.NotEqual(t, old, current)
+
+	newToken, err := token.New(token.OAuthStateToken).Sign(current)
+	require.NoError(t, err)
+
+	t.Run("overlap window", func(t *testing.T) {
+		secrets, err := Valid(store, now.Add(59*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, []string{current, old}, secrets)
+
+		_, err = Parse(store, []token.Type{token.OAuthStateToken}, oldToken, current)
+		assert.NoError(t, err)
+		_, err = Parse(store, []token.Type{token.OAuthStateToken}, newToken, current)
+		assert.NoError(t, err)
+		// a server which did not learn about the rotation yet accepts the new secret too
+		_, err = Parse(store, []token.Type{token.OAuthStateToken}, newToken, old)
+		assert.NoError(t, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		secrets, err := Valid(store, now.Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, []string{current}, secrets)
+	})
+
+	t.Run("rotate again", func(t *testing.T) {
+		newest, err := Rotate(store, now.Add(time.Minute), time.Hour)
+		require.NoError(t, err)
+
+		secrets, err := Valid(store, now.Add(2*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, []string{newest, current, old}, secrets)
+	})
+
+	t.Run("invalid secret", func(t *testing.T) {
+		wrongToken, err := token.New(token.OAuthStateToken).Sign("wrong")
+		require.NoError(t, err)
+		_, err = Parse(store, []token.Type{token.OAuthStateToken}, wrongToken, current)
+		assert.Error(t, err)
+	})
+}
@@ -2,11 +2,16 @@ package file
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	logger "github.com/rs/zerolog/log"
 
@@ -20,11 +25,19 @@ const (
 	maxLineLength int = (pipeline.MaxLogLineLength/3)*4 + (64 * 1024) //nolint:mnd
 )
 
+// gzipMagic are the first bytes of a gzip stream, log files written as JSON lines never start with them.
+var gzipMagic = []byte{0x1f, 0x8b}
+
 type logStore struct {
-	base string
+	sync.Mutex
+	base     string
+	compress bool
 }
 
-func NewLogStore(base string) (log.Service, error) {
+// NewLogStore returns a log store which saves the log of each step as JSON lines in a file.
+// If compress is set, the log files of finished steps are gzip compressed.
+// Compressed and uncompressed files are both read, so compression can be toggled at any time.
+func NewLogStore(base string, compress bool) (log.Service, error) {
 	if base == "" {
 		return nil, fmt.Errorf("file storage base path is required")
 	}
@@ -34,14 +47,14 @@ func NewLogStore(base string) (log.Service, error) {
 			return nil, err
 		}
 	}
-	return logStore{base: base}, nil
+	return &logStore{base: base, compress: compress}, nil
 }
 
-func (l logStore) filePath(id int64) string {
+func (l *logStore) filePath(id int64) string {
 	return filepath.Join(l.base, fmt.Sprintf("%d.json", id))
 }
 
-func (l logStore) LogFind(step *model.Step) ([]*model.LogEntry, error) {
+func (l *logStore) LogFind(step *model.Step) ([]*model.LogEntry, error) {
 	filename := l.filePath(step.ID)
 	file, err := os.Open(filename)
 	if err != nil {
@@ -50,9 +63,15 @@ func (l logStore) LogFind(step *model.Step) ([]*model.LogEntry, error) {
 		}
 		return nil, err
 	}
+	defer file.Close()
+
+	reader, err := newLogReader(file)
+	if err != nil {
+		return nil, err
+	}
 
 	buf := make([]byte, 0, bufio.MaxScanTokenSize)
-	s := bufio.NewScanner(file)
+	s := bufio.NewScanner(reader)
 	s.Buffer(buf, maxLineLength)
 
 	var entries []*model.LogEntry
@@ -69,38 +88,125 @@ func (l logStore) LogFind(step *model.Step) ([]*model.LogEntry, error) {
 		entries = append(entries, entry)
 	}
 
-	return entries, nil
+	return entries, s.Err()
 }
 
-func (l logStore) LogAppend(step *model.Step, logEntries []*model.LogEntry) error {
+func (l *logStore) LogAppend(step *model.Step, logEntries []*model.LogEntry) error {
+	l.Lock()
+	defer l.Unlock()
+
 	path := l.filePath(step.ID)
 
-	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0o600)
 	if err != nil {
 		logger.Error().Err(err).Msgf("could not open log file %s", path)
 		return err
 	}
 
-	var bytes []byte
+	var data []byte
 
 	for _, logEntry := range logEntries {
 		if jsonLine, err := json.Marshal(logEntry); err == nil {
-			bytes = append(bytes, jsonLine...)
-			bytes = append(bytes, byte('\n'))
+			data = append(data, jsonLine...)
+			data = append(data, byte('\n'))
 		} else {
 			logger.Error().Err(err).Msg("could not convert log entry to JSON")
 		}
 	}
 
-	if _, err = file.Write(bytes); err != nil {
+	// entries appended after the file was compressed are added as another gzip member
+	compressed, err := isCompressed(file)
+	if err != nil {
+		return errors.Join(err, file.Close())
+	}
+	if compressed {
+		data, err = gzipData(data)
+		if err != nil {
+			return errors.Join(err, file.Close())
+		}
+	}
+
+	if _, err = file.Write(data); err != nil {
 		logger.Error().Err(err).Msg("could not write out log entries")
 	}
 
 	return file.Close()
 }
 
-func (l logStore) LogDelete(step *model.Step) error {
+func (l *logStore) LogDelete(step *model.Step) error {
 	return os.Remove(l.filePath(step.ID))
 }
 
-func (l logStore) StepFinished(_ *model.Step) {}
+// StepFinished compresses the log file of the step, as it is not appended to anymore.
+func (l *logStore) StepFinished(step *model.Step) {
+	if !l.compress {
+		return
+	}
+
+	l.Lock()
+	defer l.Unlock()
+
+	if err := compressFile(l.filePath(step.ID)); err != nil {
+		logger.Error().Err(err).Msgf("could not compress logs of step %d", step.ID)
+	}
+}
+
+// compressFile replaces the file by a gzip compressed version, already compressed files are kept as they are.
+func compressFile(path string) error {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.HasPrefix(data, gzipMagic) {
+		return nil
+	}
+
+	compressed, err := gzipData(data)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first, so readers never see a partially written file
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, compressed, 0o600); err != nil {
+		return errors.Join(err, os.Remove(tmp))
+	}
+	return os.Rename(tmp, path)
+}
+
+func gzipData(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func isCompressed(file *os.File) (bool, error) {
+	header := make([]byte, len(gzipMagic))
+	n, err := file.ReadAt(header, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	return n == len(gzipMagic) && bytes.Equal(header, gzipMagic), nil
+}
+
+// newLogReader returns a reader for the log file which decompresses it if needed.
+func newLogReader(file *os.File) (io.Reader, error) {
+	reader := bufio.NewReader(file)
+	header, err := reader.Peek(len(gzipMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if !bytes.Equal(header, gzipMagic) {
+		return reader, nil
+	}
+	return gzip.NewReader(reader)
+}
@@ -0,0 +1,93 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log"
+)
+
+func testLogEntries(stepID int64, from, count int) []*model.LogEntry {
+	entries := make([]*model.LogEntry, 0, count)
+	for i := from; i < from+count; i++ {
+		entries = append(entries, &model.LogEntry{
+			StepID: stepID,
+			Line:   i,
+			Data:   fmt.Appendf(nil, "npm warn deprecated some-package@1.0.%d: this package is no longer supported", i),
+		})
+	}
+	return entries
+}
+
+func TestLogStoreCompression(t *testing.T) {
+	base := t.TempDir()
+	compressed, err := NewLogStore(filepath.Join(base, "compressed"), true)
+	require.NoError(t, err)
+	plain, err := NewLogStore(filepath.Join(base, "plain"), false)
+	require.NoError(t, err)
+
+	step := &model.Step{ID: 1}
+	entries := testLogEntries(step.ID, 0, 1000)
+	for _, s := range []log.Service{compressed, plain} {
+		require.NoError(t, s.LogAppend(step, entries[:500]))
+		require.NoError(t, s.LogAppend(step, entries[500:]))
+
+		// running steps are not compressed yet
+		found, err := s.LogFind(step)
+		require.NoError(t, err)
+		assert.Equal(t, entries, found)
+
+		s.StepFinished(step)
+	}
+
+	compressedData, err := os.ReadFile(filepath.Join(base, "compressed", "1.json"))
+	require.NoError(t, err)
+	plainData, err := os.ReadFile(filepath.Join(base, "plain", "1.json"))
+	require.NoError(t, err)
+	assert.Equal(t, gzipMagic, compressedData[:2])
+	assert.Equal(t, byte('{'), plainData[0])
+	assert.Less(t, len(compressedData)*5, len(plainData), "compressed file should be much smaller")
+
+	found, err := compressed.LogFind(step)
+	require.NoError(t, err)
+	assert.Equal(t, entries, found)
+
+	t.Run("append after compression", func(t *testing.T) {
+		more := testLogEntries(step.ID, 1000, 10)
+		require.NoError(t, compressed.LogAppend(step, more))
+
+		found, err := compressed.LogFind(step)
+		require.NoError(t, err)
+		assert.Equal(t, append(entries, more...), found)
+	})
+
+	t.Run("uncompressed files stay readable", func(t *testing.T) {
+		legacy := filepath.Join(base, "compressed", "2.json")
+		require.NoError(t, os.WriteFile(legacy, plainData, 0o600))
+
+		found, err := compressed.LogFind(&model.Step{ID: 2})
+		require.NoError(t, err)
+		assert.Len(t, found, len(entries))
+		assert.Equal(t, entries[999].Data, found[999].Data)
+	})
+}
@@ -0,0 +1,225 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	logger "github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+const (
+	pruneInterval          = time.Hour
+	repoRetentionKeyPrefix = "log-store-retention-repo-"
+)
+
+// RepoRetentionConfigKey returns the server config key of the log retention override of a repo.
+func RepoRetentionConfigKey(repoID int64) string {
+	return fmt.Sprintf("%s%d", repoRetentionKeyPrefix, repoID)
+}
+
+// SetRepoRetention overrides the log retention of a repo, zero keeps the logs of the repo forever.
+func SetRepoRetention(s store.Store, repoID int64, retention time.Duration) error {
+	return s.ServerConfigSet(RepoRetentionConfigKey(repoID), retention.String())
+}
+
+// DeleteRepoRetention removes the log retention override of a repo, so the global retention applies again.
+func DeleteRepoRetention(s store.Store, repoID int64) error {
+	err := s.ServerConfigDelete(RepoRetentionConfigKey(repoID))
+	if errors.Is(err, types.RecordNotExist) {
+		return nil
+	}
+	return err
+}
+
+// Pruner removes log files which are older than the retention.
+type Pruner struct {
+	base      string
+	store     store.Store
+	retention time.Duration
+	now       func() time.Time
+}
+
+// NewPruner returns a pruner for the log files in base.
+func NewPruner(base string, s store.Store, retention time.Duration) *Pruner {
+	return &Pruner{
+		base:      base,
+		store:     s,
+		retention: retention,
+		now:       time.Now,
+	}
+}
+
+// Run prunes the log files periodically until the context is canceled.
+func (p *Pruner) Run(ctx context.Context) {
+	ticker := time.NewTicker(pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		p.Prune()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Prune removes all expired log files once and returns how many files and bytes were reclaimed.
+// Logs of pipelines which are not done yet are never removed.
+func (p *Pruner) Prune() (files int, bytes int64) {
+	retentions, err := p.repoRetentions()
+	if err != nil {
+		logger.Error().Err(err).Msg("could not load log retention overrides of repos")
+		return 0, 0
+	}
+	shortest, ok := minRetention(p.retention, retentions)
+	if !ok {
+		// neither a global retention nor an override, all logs are kept
+		return 0, 0
+	}
+
+	entries, err := os.ReadDir(p.base)
+	if err != nil {
+		logger.Error().Err(err).Msgf("could not read log directory %s", p.base)
+		return 0, 0
+	}
+
+	now := p.now()
+	for _, entry := range entries {
+		stepID, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		id, err := strconv.ParseInt(stepID, 10, 64)
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			// the file was removed in between
+			continue
+		}
+		if now.Sub(info.ModTime()) < shortest {
+			// too young for any retention, no need to look up its pipeline
+			continue
+		}
+
+		retention, ok := p.stepRetention(id, retentions)
+		if !ok || retention <= 0 || now.Sub(info.ModTime()) < retention {
+			continue
+		}
+
+		if err := os.Remove(filepath.Join(p.base, entry.Name())); err != nil {
+			if !os.IsNotExist(err) {
+				logger.Error().Err(err).Msgf("could not remove log file %s", entry.Name())
+			}
+			continue
+		}
+		files++
+		bytes += info.Size()
+	}
+
+	logger.Info().Int("files", files).Int64("bytes", bytes).Msg("pruned expired log files")
+	return files, bytes
+}
+
+// stepRetention returns the retention for the logs of a step,
+// or false if the logs must be kept as the pipeline is not done yet.
+func (p *Pruner) stepRetention(stepID int64, retentions map[int64]time.Duration) (time.Duration, bool) {
+	step, err := p.store.StepLoad(stepID)
+	if errors.Is(err, types.RecordNotExist) {
+		// logs of deleted steps
+		return p.retention, true
+	}
+	if err != nil {
+		logger.Error().Err(err).Msgf("could not load step %d", stepID)
+		return 0, false
+	}
+
+	pipeline, err := p.store.GetPipeline(step.PipelineID)
+	if errors.Is(err, types.RecordNotExist) {
+		return p.retention, true
+	}
+	if err != nil {
+		logger.Error().Err(err).Msgf("could not load pipeline %d", step.PipelineID)
+		return 0, false
+	}
+	if pipeline.Status == model.StatusRunning || pipeline.Status == model.StatusPending || pipeline.Status == model.StatusBlocked {
+		return 0, false
+	}
+
+	if retention, ok := retentions[pipeline.RepoID]; ok {
+		return retention, true
+	}
+	return p.retention, true
+}
+
+// repoRetentions loads the log retention overrides of all repos.
+func (p *Pruner) repoRetentions() (map[int64]time.Duration, error) {
+	configs, err := p.store.ServerConfigList()
+	if err != nil {
+		return nil, err
+	}
+
+	retentions := map[int64]time.Duration{}
+	for _, config := range configs {
+		id, ok := strings.CutPrefix(config.Key, repoRetentionKeyPrefix)
+		if !ok {
+			continue
+		}
+		repoID, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			continue
+		}
+
+		// the listed values might be encrypted, only get decrypts them
+		value, err := p.store.ServerConfigGet(config.Key)
+		if err != nil {
+			logger.Error().Err(err).Msgf("could not load log retention of repo %d", repoID)
+			continue
+		}
+		retention, err := time.ParseDuration(value)
+		if err != nil {
+			logger.Error().Err(err).Msgf("invalid log retention of repo %d", repoID)
+			continue
+		}
+		retentions[repoID] = retention
+	}
+	return retentions, nil
+}
+
+// minRetention returns the shortest of the retentions which expire logs at all.
+func minRetention(global time.Duration, retentions map[int64]time.Duration) (time.Duration, bool) {
+	shortest, ok := global, global > 0
+	for _, retention := range retentions {
+		if retention > 0 && (!ok || retention < shortest) {
+			shortest, ok = retention, true
+		}
+	}
+	return shortest, ok
+}
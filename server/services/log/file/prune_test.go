@@ -0,0 +1,99 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+func TestPrune(t *testing.T) {
+	base := t.TempDir()
+	now := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	writeLog := func(stepID int64, age time.Duration) {
+		path := (&logStore{base: base}).filePath(stepID)
+		require.NoError(t, os.WriteFile(path, []byte("{}\n"), 0o600))
+		modTime := now.Add(-age)
+		require.NoError(t, os.Chtimes(path, modTime, modTime))
+	}
+
+	writeLog(1, 48*time.Hour) // expired
+	writeLog(2, time.Hour)    // too young
+	writeLog(3, 48*time.Hour) // pipeline still running
+	writeLog(4, 48*time.Hour) // repo keeps logs longer
+	writeLog(5, 48*time.Hour) // step was deleted
+	require.NoError(t, os.WriteFile(filepath.Join(base, "other.txt"), nil, 0o600))
+
+	store := mocks.NewMockStore(t)
+	store.On("StepLoad", int64(1)).Return(&model.Step{ID: 1, PipelineID: 10}, nil)
+	store.On("StepLoad", int64(3)).Return(&model.Step{ID: 3, PipelineID: 11}, nil)
+	store.On("StepLoad", int64(4)).Return(&model.Step{ID: 4, PipelineID: 12}, nil)
+	store.On("StepLoad", int64(5)).Return(nil, types.RecordNotExist)
+	store.On("GetPipeline", int64(10)).Return(&model.Pipeline{ID: 10, RepoID: 1, Status: model.StatusSuccess}, nil)
+	store.On("GetPipeline", int64(11)).Return(&model.Pipeline{ID: 11, RepoID: 1, Status: model.StatusRunning}, nil)
+	store.On("GetPipeline", int64(12)).Return(&model.Pipeline{ID: 12, RepoID: 2, Status: model.StatusFailure}, nil)
+	store.On("ServerConfigList").Return([]*model.ServerConfig{
+		{Key: "other"},
+		{Key: RepoRetentionConfigKey(2), Value: "encrypted"},
+	}, nil).Once()
+	store.On("ServerConfigGet", RepoRetentionConfigKey(2)).Return("72h", nil).Once()
+
+	pruner := NewPruner(base, store, 24*time.Hour)
+	pruner.now = func() time.Time { return now }
+
+	files, bytes := pruner.Prune()
+	assert.Equal(t, 2, files)
+	assert.EqualValues(t, 6, bytes)
+
+	entries, err := os.ReadDir(base)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"2.json", "3.json", "4.json", "other.txt"}, names)
+}
+
+func TestPruneWithoutRetention(t *testing.T) {
+	base := t.TempDir()
+	require.NoError(t, os.WriteFile((&logStore{base: base}).filePath(1), []byte("{}\n"), 0o600))
+
+	// without a global retention and overrides the log files are not even looked at
+	store := mocks.NewMockStore(t)
+	store.On("ServerConfigList").Return([]*model.ServerConfig{}, nil).Once()
+	files, _ := NewPruner(base, store, 0).Prune()
+	assert.Zero(t, files)
+
+	// an override of a repo prunes its logs
+	store = mocks.NewMockStore(t)
+	store.On("ServerConfigList").Return([]*model.ServerConfig{{Key: RepoRetentionConfigKey(1)}}, nil).Once()
+	store.On("ServerConfigGet", RepoRetentionConfigKey(1)).Return("1h", nil).Once()
+	store.On("StepLoad", int64(1)).Return(&model.Step{ID: 1, PipelineID: 10}, nil)
+	store.On("GetPipeline", int64(10)).Return(&model.Pipeline{ID: 10, RepoID: 1, Status: model.StatusSuccess}, nil)
+	pruner := NewPruner(base, store, 0)
+	pruner.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	files, _ = pruner.Prune()
+	assert.Equal(t, 1, files)
+}
@@ -0,0 +1,205 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package limit limits the size and the line count of the logs of each step.
+package limit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jellydator/ttlcache/v3"
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+const (
+	// limitsTTL is the time the log limits of a pipeline are cached.
+	limitsTTL = 10 * time.Minute
+	// maxPipelines is the maximum number of pipelines whose log limits are cached.
+	maxPipelines = 10000
+	// usageTTL is the time the log usage of a step is kept after its last log line,
+	// in case the step never reports that it finished.
+	usageTTL = 24 * time.Hour
+
+	// Marker is the log line appended once the logs of a step got truncated.
+	Marker = "[log truncated]"
+)
+
+// Limits are the max size in bytes and the max line count of the logs of a step.
+// Zero means no limit.
+type Limits struct {
+	MaxSize  int
+	MaxLines int
+}
+
+// usage tracks how much a step has logged.
+type usage struct {
+	size      int
+	lines     int
+	truncated bool
+}
+
+// Limiter truncates the logs of steps exceeding their limits.
+// Steps use the limits of their repo, falling back to the default limits.
+type Limiter struct {
+	store    store.Store
+	defaults Limits
+	// limits caches the limits by pipeline id, as the logs of a step are appended in many small batches.
+	limits *ttlcache.Cache[int64, Limits]
+
+	mu    sync.Mutex
+	steps *ttlcache.Cache[int64, *usage]
+}
+
+// New returns a limiter applying the default limits to the logs of all repos without own limits.
+func New(s store.Store, defaults Limits) *Limiter {
+	return &Limiter{
+		store:    s,
+		defaults: defaults,
+		limits: ttlcache.New(
+			ttlcache.WithTTL[int64, Limits](limitsTTL),
+			ttlcache.WithCapacity[int64, Limits](maxPipelines),
+		),
+		steps: ttlcache.New(
+			ttlcache.WithTTL[int64, *usage](usageTTL),
+		),
+	}
+}
+
+// Apply returns the log entries of the step which are within its limits.
+// The entry exceeding a limit is cut and followed by the truncation marker,
+// all later lines of the step are dropped.
+// Only stdout and stderr lines are counted, other entries are always kept.
+func (l *Limiter) Apply(step *model.Step, logEntries []*model.LogEntry) []*model.LogEntry {
+	limits := l.stepLimits(step)
+	if limits.MaxSize <= 0 && limits.MaxLines <= 0 {
+		return logEntries
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var u *usage
+	if item := l.steps.Get(step.ID); item != nil {
+		u = item.Value()
+	} else {
+		u = &usage{}
+	}
+	l.steps.Set(step.ID, u, ttlcache.DefaultTTL)
+
+	kept := make([]*model.LogEntry, 0, len(logEntries))
+	for _, entry := range logEntries {
+		if entry.Type != model.LogEntryStdout && entry.Type != model.LogEntryStderr {
+			kept = append(kept, entry)
+			continue
+		}
+		if u.truncated {
+			continue
+		}
+
+		if limits.MaxLines > 0 && u.lines >= limits.MaxLines {
+			u.truncated = true
+			kept = append(kept, marker(entry, entry.Line, fmt.Sprintf("exceeded %d lines", limits.MaxLines)))
+			continue
+		}
+
+		if limits.MaxSize > 0 && u.size+len(entry.Data) > limits.MaxSize {
+			u.truncated = true
+			line := entry.Line
+			if data := cut(entry.Data, limits.MaxSize-u.size); len(data) > 0 {
+				part := *entry
+				part.Data = data
+				kept = append(kept, &part)
+				line++
+			}
+			kept = append(kept, marker(entry, line, fmt.Sprintf("exceeded %d bytes", limits.MaxSize)))
+			continue
+		}
+
+		u.lines++
+		u.size += len(entry.Data)
+		kept = append(kept, entry)
+	}
+	return kept
+}
+
+// StepFinished forgets the log usage of the step.
+func (l *Limiter) StepFinished(step *model.Step) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.steps.Delete(step.ID)
+}
+
+// stepLimits returns the limits of the repo the step belongs to.
+func (l *Limiter) stepLimits(step *model.Step) Limits {
+	if item := l.limits.Get(step.PipelineID); item != nil {
+		return item.Value()
+	}
+
+	limits := l.defaults
+	pipeline, err := l.store.GetPipeline(step.PipelineID)
+	if err != nil {
+		log.Error().Err(err).Msgf("could not find pipeline %d to get its log limits, using the default limits", step.PipelineID)
+		return limits
+	}
+	repo, err := l.store.GetRepo(pipeline.RepoID)
+	if err != nil {
+		log.Error().Err(err).Msgf("could not find repo %d to get its log limits, using the default limits", pipeline.RepoID)
+		return limits
+	}
+	if repo.LogMaxSize != 0 {
+		limits.MaxSize = repo.LogMaxSize
+	}
+	if repo.LogMaxLines != 0 {
+		limits.MaxLines = repo.LogMaxLines
+	}
+	l.limits.Set(step.PipelineID, limits, ttlcache.DefaultTTL)
+	return limits
+}
+
+// marker returns the truncation marker replacing the given entry.
+func marker(entry *model.LogEntry, line int, reason string) *model.LogEntry {
+	return &model.LogEntry{
+		StepID: entry.StepID,
+		Time:   entry.Time,
+		Line:   line,
+		Data:   fmt.Appendf(nil, "%s the log %s", Marker, reason),
+		Type:   model.LogEntryStderr,
+	}
+}
+
+// cut returns at most size bytes of data without splitting a multi-byte character.
+func cut(data []byte, size int) []byte {
+	if size <= 0 {
+		return nil
+	}
+	if len(data) <= size {
+		return data
+	}
+	data = data[:size]
+	// drop an incomplete trailing character so viewers never get invalid UTF-8
+	for i := 0; i < utf8.UTFMax && len(data) > 0; i++ {
+		r, n := utf8.DecodeLastRune(data)
+		if r != utf8.RuneError || n != 1 {
+			break
+		}
+		data = data[:len(data)-1]
+	}
+	return data
+}
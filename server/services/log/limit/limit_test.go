@@ -0,0 +1,125 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package limit
+
+import (
+	"fmt"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+)
+
+// lines returns count stdout log entries of the step starting with the given line number.
+func lines(step *model.Step, start, count int, data string) []*model.LogEntry {
+	entries := make([]*model.LogEntry, 0, count)
+	for i := range count {
+		entries = append(entries, &model.LogEntry{StepID: step.ID, Line: start + i, Data: []byte(data)})
+	}
+	return entries
+}
+
+func data(entries []*model.LogEntry) []string {
+	out := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, fmt.Sprintf("%d:%s", entry.Line, entry.Data))
+	}
+	return out
+}
+
+func newLimiter(t *testing.T, defaults Limits, repo *model.Repo) *Limiter {
+	store := mocks.NewMockStore(t)
+	store.On("GetPipeline", int64(10)).Return(&model.Pipeline{ID: 10, RepoID: repo.ID}, nil).Once()
+	store.On("GetRepo", repo.ID).Return(repo, nil).Once()
+	return New(store, defaults)
+}
+
+func TestLimitLines(t *testing.T) {
+	limiter := newLimiter(t, Limits{MaxLines: 3}, &model.Repo{ID: 1})
+	step := &model.Step{ID: 1, PipelineID: 10}
+
+	assert.Equal(t, []string{"0:a", "1:a"}, data(limiter.Apply(step, lines(step, 0, 2, "a"))))
+	assert.Equal(t, []string{
+		"2:b",
+		"3:[log truncated] the log exceeded 3 lines",
+	}, data(limiter.Apply(step, lines(step, 2, 3, "b"))))
+	assert.Empty(t, limiter.Apply(step, lines(step, 5, 2, "c")), "no lines are kept after truncation")
+
+	exitCode := &model.LogEntry{StepID: step.ID, Line: 7, Type: model.LogEntryExitCode, Data: []byte("1")}
+	assert.Equal(t, []*model.LogEntry{exitCode}, limiter.Apply(step, []*model.LogEntry{exitCode}))
+
+	// other steps have their own limits
+	other := &model.Step{ID: 2, PipelineID: 10}
+	assert.Len(t, limiter.Apply(other, lines(other, 0, 3, "a")), 3)
+}
+
+func TestLimitSize(t *testing.T) {
+	limiter := newLimiter(t, Limits{MaxSize: 10}, &model.Repo{ID: 1})
+	step := &model.Step{ID: 1, PipelineID: 10}
+
+	assert.Equal(t, []string{"0:1234", "1:1234"}, data(limiter.Apply(step, lines(step, 0, 2, "1234"))))
+	assert.Equal(t, []string{
+		"2:12",
+		"3:[log truncated] the log exceeded 10 bytes",
+	}, data(limiter.Apply(step, lines(step, 2, 2, "1234"))))
+	assert.Empty(t, limiter.Apply(step, lines(step, 4, 1, "1")))
+
+	// a step ending exactly at the limit gets the marker on its next line
+	exact := &model.Step{ID: 2, PipelineID: 10}
+	assert.Equal(t, []string{
+		"0:1234567890",
+		"1:[log truncated] the log exceeded 10 bytes",
+	}, data(limiter.Apply(exact, lines(exact, 0, 2, "1234567890"))))
+}
+
+func TestLimitSizeKeepsValidUTF8(t *testing.T) {
+	limiter := newLimiter(t, Limits{MaxSize: 5}, &model.Repo{ID: 1})
+	step := &model.Step{ID: 1, PipelineID: 10}
+
+	// each character takes three bytes
+	entries := limiter.Apply(step, lines(step, 0, 1, "€€€"))
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "€", string(entries[0].Data))
+		assert.True(t, utf8.Valid(entries[0].Data))
+	}
+}
+
+func TestLimitRepoOverride(t *testing.T) {
+	limiter := newLimiter(t, Limits{MaxLines: 1}, &model.Repo{ID: 1, LogMaxLines: 3})
+	step := &model.Step{ID: 1, PipelineID: 10}
+
+	assert.Len(t, limiter.Apply(step, lines(step, 0, 3, "a")), 3)
+	assert.Len(t, limiter.Apply(step, lines(step, 3, 1, "a")), 1, "only the marker is added")
+}
+
+func TestLimitStepFinished(t *testing.T) {
+	limiter := newLimiter(t, Limits{MaxLines: 1}, &model.Repo{ID: 1})
+	step := &model.Step{ID: 1, PipelineID: 10}
+
+	assert.Len(t, limiter.Apply(step, lines(step, 0, 2, "a")), 2)
+	limiter.StepFinished(step)
+	assert.Equal(t, []string{"0:a"}, data(limiter.Apply(step, lines(step, 0, 1, "a"))))
+}
+
+func TestNoLimits(t *testing.T) {
+	limiter := newLimiter(t, Limits{}, &model.Repo{ID: 1})
+	step := &model.Step{ID: 1, PipelineID: 10}
+
+	entries := lines(step, 0, 100, "a")
+	assert.Equal(t, entries, limiter.Apply(step, entries))
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package routing provides a log store which stores the logs of each pipeline in the log store selected for its repo
+// when the pipeline was created.
+package routing
+
+import (
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+	logger "github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+const (
+	// routeTTL is the time the log store of a pipeline is cached.
+	routeTTL = 10 * time.Minute
+	// maxRoutes is the maximum number of pipelines whose log store is cached.
+	maxRoutes = 10000
+)
+
+type logStore struct {
+	fallback log.Service
+	stores   map[string]log.Service
+	store    store.Store
+	// routes caches the log store name by pipeline id, as the logs of a step are appended in many small batches.
+	routes *ttlcache.Cache[int64, string]
+}
+
+// NewLogStore returns a log store which stores the logs of the pipelines with a log store set in the named log store
+// and the logs of all other pipelines in the fallback log store.
+// The logs stay in their log store if the log store of a repo is changed later, as it is kept per pipeline.
+func NewLogStore(fallback log.Service, stores map[string]log.Service, s store.Store) log.Service {
+	return &logStore{
+		fallback: fallback,
+		stores:   stores,
+		store:    s,
+		routes: ttlcache.New(
+			ttlcache.WithTTL[int64, string](routeTTL),
+			ttlcache.WithCapacity[int64, string](maxRoutes),
+		),
+	}
+}
+
+// route returns the log store of the pipeline the step belongs to.
+func (l *logStore) route(step *model.Step) (log.Service, error) {
+	name, err := l.routeName(step.PipelineID)
+	if err != nil {
+		return nil, err
+	}
+	if logStore, ok := l.stores[name]; ok {
+		return logStore, nil
+	}
+	if name != "" {
+		logger.Warn().Msgf("log store '%s' of pipeline %d is not configured, using the default log store", name, step.PipelineID)
+	}
+	return l.fallback, nil
+}
+
+func (l *logStore) routeName(pipelineID int64) (string, error) {
+	if item := l.routes.Get(pipelineID); item != nil {
+		return item.Value(), nil
+	}
+
+	pipeline, err := l.store.GetPipeline(pipelineID)
+	if err != nil {
+		return "", err
+	}
+	l.routes.Set(pipelineID, pipeline.LogStore, ttlcache.DefaultTTL)
+	return pipeline.LogStore, nil
+}
+
+func (l *logStore) LogFind(step *model.Step) ([]*model.LogEntry, error) {
+	logStore, err := l.route(step)
+	if err != nil {
+		return nil, err
+	}
+	return logStore.LogFind(step)
+}
+
+func (l *logStore) LogAppend(step *model.Step, logEntries []*model.LogEntry) error {
+	logStore, err := l.route(step)
+	if err != nil {
+		return err
+	}
+	return logStore.LogAppend(step, logEntries)
+}
+
+func (l *logStore) LogDelete(step *model.Step) error {
+	logStore, err := l.route(step)
+	if err != nil {
+		return err
+	}
+	return logStore.LogDelete(step)
+}
+
+func (l *logStore) StepFinished(step *model.Step) {
+	logStore, err := l.route(step)
+	if err != nil {
+		logger.Error().Err(err).Msgf("could not find the log store of step %d", step.ID)
+		return
+	}
+	logStore.StepFinished(step)
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package routing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+)
+
+// fakeLogStore keeps the logs in memory.
+type fakeLogStore struct {
+	logs     map[int64][]*model.LogEntry
+	finished []int64
+}
+
+func newFakeLogStore() *fakeLogStore {
+	return &fakeLogStore{logs: map[int64][]*model.LogEntry{}}
+}
+
+func (f *fakeLogStore) LogFind(step *model.Step) ([]*model.LogEntry, error) {
+	return f.logs[step.ID], nil
+}
+
+func (f *fakeLogStore) LogAppend(step *model.Step, logEntries []*model.LogEntry) error {
+	f.logs[step.ID] = append(f.logs[step.ID], logEntries...)
+	return nil
+}
+
+func (f *fakeLogStore) LogDelete(step *model.Step) error {
+	delete(f.logs, step.ID)
+	return nil
+}
+
+func (f *fakeLogStore) StepFinished(step *model.Step) {
+	f.finished = append(f.finished, step.ID)
+}
+
+func TestLogStore(t *testing.T) {
+	store := mocks.NewMockStore(t)
+	store.On("GetPipeline", int64(10)).Return(&model.Pipeline{ID: 10, RepoID: 1}, nil).Once()
+	store.On("GetPipeline", int64(20)).Return(&model.Pipeline{ID: 20, RepoID: 2, LogStore: "s3"}, nil).Once()
+	store.On("GetPipeline", int64(30)).Return(&model.Pipeline{ID: 30, RepoID: 3, LogStore: "removed"}, nil).Once()
+	// the repo of this pipeline changed its log store after the pipeline was created
+	store.On("GetPipeline", int64(40)).Return(&model.Pipeline{ID: 40, RepoID: 2}, nil).Once()
+
+	fallback, s3 := newFakeLogStore(), newFakeLogStore()
+	logStore := NewLogStore(fallback, map[string]log.Service{"s3": s3}, store)
+
+	defaultStep := &model.Step{ID: 1, PipelineID: 10}
+	routedStep := &model.Step{ID: 2, PipelineID: 20}
+	unknownStep := &model.Step{ID: 3, PipelineID: 30}
+	olderStep := &model.Step{ID: 4, PipelineID: 40}
+
+	for _, step := range []*model.Step{defaultStep, routedStep, unknownStep, olderStep} {
+		// the log store of a pipeline is only looked up once
+		for range 2 {
+			require.NoError(t, logStore.LogAppend(step, []*model.LogEntry{{StepID: step.ID, Data: []byte("hello")}}))
+		}
+		logStore.StepFinished(step)
+	}
+
+	assert.Len(t, fallback.logs[defaultStep.ID], 2)
+	assert.Len(t, s3.logs[routedStep.ID], 2)
+	assert.Len(t, fallback.logs[unknownStep.ID], 2, "pipelines with a log store which is not configured use the fallback")
+	assert.Len(t, fallback.logs[olderStep.ID], 2, "pipelines keep the log store they were created with")
+	assert.NotContains(t, fallback.logs, routedStep.ID)
+	assert.Equal(t, []int64{defaultStep.ID, unknownStep.ID, olderStep.ID}, fallback.finished)
+	assert.Equal(t, []int64{routedStep.ID}, s3.finished)
+
+	entries, err := logStore.LogFind(routedStep)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	require.NoError(t, logStore.LogDelete(routedStep))
+	assert.Empty(t, s3.logs)
+	assert.Len(t, fallback.logs, 3)
+}
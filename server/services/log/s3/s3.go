@@ -0,0 +1,346 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	logger "github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/log"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+const (
+	// chunkSize is the amount of log data after which the log of a running step is uploaded as another chunk,
+	// so the logs survive a server restart and at most a chunk per step is kept in memory.
+	chunkSize = 1 << 20
+	// staleAfter is the time after which the buffered log of a step without new entries is uploaded
+	// and dropped from memory, e.g. if the step never finished as its agent got lost.
+	staleAfter    = 30 * time.Minute
+	sweepInterval = time.Minute
+	// requestTimeout is the timeout for a single request to the object storage.
+	requestTimeout = time.Minute
+	defaultRegion  = "us-east-1"
+)
+
+// Config is the configuration of the s3 log store.
+type Config struct {
+	Bucket      string
+	Endpoint    string
+	Region      string
+	Credentials Credentials
+}
+
+// stepLog is the buffered log of a running step.
+// Its lock is held during uploads, so only appends to the same step wait for them.
+type stepLog struct {
+	sync.Mutex
+	prefix string
+	// chunks is the number of uploaded chunks, data holds the log written after the last one.
+	chunks int
+	data   []byte
+	// loaded is set once the chunks uploaded before, e.g. by a server which got restarted, got counted.
+	loaded bool
+	// updated is guarded by the lock of the log store.
+	updated time.Time
+}
+
+type logStore struct {
+	// the lock only guards the running map, requests to the object storage are done without it
+	sync.Mutex
+
+	client    *client
+	store     store.Store
+	running   map[int64]*stepLog
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// NewLogStore returns a log store which saves the log of each step as chunks of JSON lines
+// with the keys <repo-id>/<pipeline-id>/<step-id>/<chunk>.json, as objects can not be appended to.
+// Logs of running steps are buffered in memory and uploaded as another chunk once the buffer
+// is full, the step finished, or no new entries were written for a while.
+func NewLogStore(config Config, s store.Store) (log.Service, error) {
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("s3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = defaultRegion
+	}
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", config.Region)
+	}
+
+	return &logStore{
+		client: &client{
+			http:        http.DefaultClient,
+			endpoint:    strings.TrimSuffix(config.Endpoint, "/"),
+			bucket:      config.Bucket,
+			region:      config.Region,
+			credentials: config.Credentials,
+			now:         time.Now,
+		},
+		store:   s,
+		running: map[int64]*stepLog{},
+		now:     time.Now,
+	}, nil
+}
+
+func (l *logStore) objectPrefix(step *model.Step) (string, error) {
+	pipeline, err := l.store.GetPipeline(step.PipelineID)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d/%d/%d/", pipeline.RepoID, step.PipelineID, step.ID), nil
+}
+
+// chunkKey returns the key of a chunk, the number is padded so the chunks are listed in order.
+func chunkKey(prefix string, chunk int) string {
+	return fmt.Sprintf("%s%06d.json", prefix, chunk)
+}
+
+func (l *logStore) LogFind(step *model.Step) ([]*model.LogEntry, error) {
+	l.Lock()
+	running, ok := l.running[step.ID]
+	l.Unlock()
+	if ok {
+		running.Lock()
+		prefix, chunks, data, loaded := running.prefix, running.chunks, bytes.Clone(running.data), running.loaded
+		running.Unlock()
+		if loaded {
+			// uploaded chunks are never changed, so they can be read without holding the lock
+			keys := make([]string, 0, chunks)
+			for chunk := range chunks {
+				keys = append(keys, chunkKey(prefix, chunk))
+			}
+			entries, err := l.download(keys)
+			if err != nil {
+				return nil, err
+			}
+			buffered, err := parseEntries(data)
+			if err != nil {
+				return nil, err
+			}
+			return append(entries, buffered...), nil
+		}
+	}
+
+	prefix, err := l.objectPrefix(step)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := l.list(prefix)
+	if err != nil {
+		return nil, err
+	}
+	return l.download(keys)
+}
+
+func (l *logStore) LogAppend(step *model.Step, logEntries []*model.LogEntry) error {
+	l.sweep()
+
+	l.Lock()
+	running, ok := l.running[step.ID]
+	if !ok {
+		running = &stepLog{}
+		l.running[step.ID] = running
+	}
+	running.updated = l.now()
+	l.Unlock()
+
+	running.Lock()
+	defer running.Unlock()
+
+	if !running.loaded {
+		prefix, err := l.objectPrefix(step)
+		if err != nil {
+			return err
+		}
+		keys, err := l.list(prefix)
+		if err != nil {
+			return err
+		}
+		running.prefix = prefix
+		running.chunks = len(keys)
+		running.loaded = true
+	}
+
+	for _, logEntry := range logEntries {
+		if jsonLine, err := json.Marshal(logEntry); err == nil {
+			running.data = append(running.data, jsonLine...)
+			running.data = append(running.data, byte('\n'))
+		} else {
+			logger.Error().Err(err).Msg("could not convert log entry to JSON")
+		}
+	}
+
+	if len(running.data) < chunkSize {
+		return nil
+	}
+	return l.flush(running)
+}
+
+func (l *logStore) LogDelete(step *model.Step) error {
+	l.Lock()
+	delete(l.running, step.ID)
+	l.Unlock()
+
+	prefix, err := l.objectPrefix(step)
+	if err != nil {
+		return err
+	}
+	keys, err := l.list(prefix)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		err := l.client.deleteObject(ctx, key)
+		cancel()
+		if err != nil && !errors.Is(err, errObjectNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *logStore) StepFinished(step *model.Step) {
+	l.Lock()
+	running, ok := l.running[step.ID]
+	l.Unlock()
+	if !ok {
+		return
+	}
+
+	running.Lock()
+	defer running.Unlock()
+	if err := l.flush(running); err != nil {
+		logger.Error().Err(err).Msgf("could not upload logs of step %d", step.ID)
+	}
+
+	// dropped after the upload, so appends arriving in the meantime are not lost
+	l.Lock()
+	if l.running[step.ID] == running {
+		delete(l.running, step.ID)
+	}
+	l.Unlock()
+}
+
+// sweep uploads and drops the logs of steps which did not get new entries for a while.
+// It runs at most once per sweep interval.
+func (l *logStore) sweep() {
+	l.Lock()
+	now := l.now()
+	if now.Sub(l.lastSweep) < sweepInterval {
+		l.Unlock()
+		return
+	}
+	l.lastSweep = now
+	stale := map[int64]*stepLog{}
+	for stepID, running := range l.running {
+		if now.Sub(running.updated) > staleAfter {
+			stale[stepID] = running
+		}
+	}
+	l.Unlock()
+
+	for stepID, running := range stale {
+		running.Lock()
+		if err := l.flush(running); err != nil {
+			logger.Error().Err(err).Msgf("could not upload logs of stale step %d", stepID)
+			running.Unlock()
+			continue
+		}
+		// the step is kept if it got new entries during the upload
+		l.Lock()
+		if l.running[stepID] == running && now.Sub(running.updated) > staleAfter {
+			delete(l.running, stepID)
+		}
+		l.Unlock()
+		running.Unlock()
+	}
+}
+
+// flush uploads the buffered log of a step as another chunk. The lock of the step has to be held.
+func (l *logStore) flush(running *stepLog) error {
+	if !running.loaded || len(running.data) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	if err := l.client.putObject(ctx, chunkKey(running.prefix, running.chunks), running.data); err != nil {
+		return err
+	}
+	running.chunks++
+	running.data = nil
+	return nil
+}
+
+// list returns the keys of the uploaded chunks of a log in order.
+func (l *logStore) list(prefix string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	return l.client.listObjects(ctx, prefix)
+}
+
+// download returns the entries of the given chunks, which are read one by one.
+func (l *logStore) download(keys []string) ([]*model.LogEntry, error) {
+	var entries []*model.LogEntry
+	for _, key := range keys {
+		ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+		data, err := l.client.getObject(ctx, key)
+		cancel()
+		if errors.Is(err, errObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		chunk, err := parseEntries(data)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, chunk...)
+	}
+	return entries, nil
+}
+
+func parseEntries(data []byte) ([]*model.LogEntry, error) {
+	var entries []*model.LogEntry
+	for line := range bytes.Lines(data) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		entry := &model.LogEntry{}
+		if err := json.Unmarshal(line, entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
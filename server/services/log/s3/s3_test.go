@@ -0,0 +1,246 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package s3
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+)
+
+// fakeS3 is an in-memory implementation of the object operations used by the log store.
+type fakeS3 struct {
+	sync.Mutex
+	objects map[string][]byte
+	puts    int
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=key/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	f.Lock()
+	defer f.Unlock()
+	switch {
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = data
+		f.puts++
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.list(w, r)
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// list returns two keys per page, so the continuation of listings is used.
+func (f *fakeS3) list(w http.ResponseWriter, r *http.Request) {
+	bucket := r.URL.Path + "/"
+	var keys []string
+	for path := range f.objects {
+		key := strings.TrimPrefix(path, bucket)
+		if strings.HasPrefix(key, r.URL.Query().Get("prefix")) && key > r.URL.Query().Get("continuation-token") {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	type contents struct {
+		Key string
+	}
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []contents
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+	}{}
+	for _, key := range keys {
+		if len(result.Contents) == 2 { //nolint:mnd
+			result.IsTruncated = true
+			result.NextContinuationToken = result.Contents[1].Key
+			break
+		}
+		result.Contents = append(result.Contents, contents{Key: key})
+	}
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func newTestLogStore(t *testing.T, fake *fakeS3) *logStore {
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	store := mocks.NewMockStore(t)
+	store.On("GetPipeline", int64(2)).Return(&model.Pipeline{ID: 2, RepoID: 1}, nil)
+
+	service, err := NewLogStore(Config{
+		Bucket:      "logs",
+		Endpoint:    srv.URL,
+		Credentials: Credentials{AccessKeyID: "key", SecretAccessKey: "secret"},
+	}, store)
+	require.NoError(t, err)
+	return service.(*logStore)
+}
+
+func TestLogStore(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	logStore := newTestLogStore(t, fake)
+
+	step := &model.Step{ID: 3, PipelineID: 2}
+	require.NoError(t, logStore.LogAppend(step, []*model.LogEntry{
+		{StepID: 3, Line: 0, Data: []byte("hello")},
+		{StepID: 3, Line: 1, Data: []byte("world")},
+	}))
+
+	// running steps are read from the buffer
+	entries, err := logStore.LogFind(step)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 0, fake.puts)
+
+	logStore.StepFinished(step)
+	assert.Equal(t, 1, fake.puts)
+	assert.Contains(t, fake.objects, "/logs/1/2/3/000000.json")
+	assert.Empty(t, logStore.running)
+
+	entries, err = logStore.LogFind(step)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []byte("world"), entries[1].Data)
+
+	require.NoError(t, logStore.LogDelete(step))
+	assert.Empty(t, fake.objects)
+
+	entries, err = logStore.LogFind(step)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestLogStoreRestart(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{
+		// flushed before the server restarted
+		"/logs/1/2/3/000000.json": []byte(`{"step_id":3,"line":0,"data":"aGVsbG8="}` + "\n"),
+	}}
+	logStore := newTestLogStore(t, fake)
+
+	step := &model.Step{ID: 3, PipelineID: 2}
+	require.NoError(t, logStore.LogAppend(step, []*model.LogEntry{{StepID: 3, Line: 1, Data: []byte("world")}}))
+
+	entries, err := logStore.LogFind(step)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	logStore.StepFinished(step)
+	assert.Equal(t, 1, fake.puts)
+	assert.Contains(t, fake.objects, "/logs/1/2/3/000001.json", "the chunk uploaded before is kept")
+
+	entries, err = logStore.LogFind(step)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, []byte("hello"), entries[0].Data)
+	assert.Equal(t, []byte("world"), entries[1].Data)
+}
+
+func TestLogStoreChunks(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	logStore := newTestLogStore(t, fake)
+
+	step := &model.Step{ID: 3, PipelineID: 2}
+	line := bytes.Repeat([]byte("a"), 64*1024)
+	for i := range 50 {
+		require.NoError(t, logStore.LogAppend(step, []*model.LogEntry{{StepID: 3, Line: i, Data: line}}))
+
+		// only the log written after the last chunk is kept in memory
+		logStore.Lock()
+		assert.Less(t, len(logStore.running[3].data), chunkSize)
+		logStore.Unlock()
+	}
+	assert.Equal(t, 4, fake.puts, "expect a chunk per MiB")
+
+	entries, err := logStore.LogFind(step)
+	require.NoError(t, err)
+	require.Len(t, entries, 50)
+	for i, entry := range entries {
+		assert.Equal(t, i, entry.Line)
+	}
+
+	logStore.StepFinished(step)
+	entries, err = logStore.LogFind(step)
+	require.NoError(t, err)
+	assert.Len(t, entries, 50)
+
+	require.NoError(t, logStore.LogDelete(step))
+	assert.Empty(t, fake.objects)
+}
+
+func TestLogStoreStale(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	logStore := newTestLogStore(t, fake)
+	now := time.Now()
+	logStore.now = func() time.Time { return now }
+
+	lost := &model.Step{ID: 3, PipelineID: 2}
+	active := &model.Step{ID: 4, PipelineID: 2}
+	require.NoError(t, logStore.LogAppend(lost, []*model.LogEntry{{StepID: 3, Data: []byte("hello")}}))
+
+	// the step which never finished is uploaded and dropped with the next append after it got stale
+	now = now.Add(staleAfter + time.Second)
+	require.NoError(t, logStore.LogAppend(active, []*model.LogEntry{{StepID: 4, Data: []byte("world")}}))
+	assert.Contains(t, fake.objects, "/logs/1/2/3/000000.json")
+	assert.NotContains(t, logStore.running, int64(3))
+	assert.Contains(t, logStore.running, int64(4))
+
+	entries, err := logStore.LogFind(lost)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, []byte("hello"), entries[0].Data)
+
+	// late entries of a dropped step are added as another chunk
+	require.NoError(t, logStore.LogAppend(lost, []*model.LogEntry{{StepID: 3, Line: 1, Data: []byte("late")}}))
+	logStore.StepFinished(lost)
+	assert.Contains(t, fake.objects, "/logs/1/2/3/000001.json")
+	entries, err = logStore.LogFind(lost)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestSigningKey(t *testing.T) {
+	// example from the AWS signature version 4 documentation
+	key := signingKey("wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "20120215", "us-east-1", "iam")
+	assert.Equal(t, "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d", hex.EncodeToString(key))
+}
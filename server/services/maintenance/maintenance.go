@@ -0,0 +1,95 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maintenance manages the server wide maintenance mode.
+// While it is enabled the server is read-only: webhooks and all other writes are rejected.
+// The mode is stored in the database and reloaded periodically, so all servers sharing it follow changes.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+const (
+	configKey = "maintenance"
+	// ReloadInterval is the interval the mode is reloaded with, so changes made on other servers apply.
+	ReloadInterval = 10 * time.Second
+)
+
+var enabled atomic.Bool
+
+// Enabled returns whether the maintenance mode is enabled.
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Load reads the stored maintenance mode, it is disabled if it was never set.
+func Load(s store.Store) error {
+	value, err := s.ServerConfigGet(configKey)
+	if errors.Is(err, types.RecordNotExist) {
+		enabled.Store(false)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	on, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	enabled.Store(on)
+	return nil
+}
+
+// Set enables or disables the maintenance mode and stores it, so it is kept across restarts.
+func Set(s store.Store, on bool) error {
+	if err := s.ServerConfigSet(configKey, strconv.FormatBool(on)); err != nil {
+		return err
+	}
+	enabled.Store(on)
+	log.Info().Msgf("maintenance mode enabled: %t", on)
+	return nil
+}
+
+// Watch reloads the stored maintenance mode periodically until the context is canceled.
+func Watch(ctx context.Context, s store.Store, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			was := Enabled()
+			if err := Load(s); err != nil {
+				log.Error().Err(err).Msg("could not reload maintenance mode")
+				continue
+			}
+			if on := Enabled(); on != was {
+				log.Info().Msgf("maintenance mode enabled by another server: %t", on)
+			}
+		}
+	}
+}
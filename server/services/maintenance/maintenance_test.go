@@ -0,0 +1,86 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maintenance
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/mocks"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+func TestSetAndLoad(t *testing.T) {
+	config := map[string]string{}
+	store := mocks.NewMockStore(t)
+	store.On("ServerConfigGet", mock.Anything).Return(func(key string) (string, error) {
+		value, ok := config[key]
+		if !ok {
+			return "", types.RecordNotExist
+		}
+		return value, nil
+	})
+	store.On("ServerConfigSet", mock.Anything, mock.Anything).Return(func(key, value string) error {
+		config[key] = value
+		return nil
+	})
+	t.Cleanup(func() { enabled.Store(false) })
+
+	require.NoError(t, Load(store))
+	assert.False(t, Enabled())
+
+	require.NoError(t, Set(store, true))
+	assert.True(t, Enabled())
+
+	enabled.Store(false)
+	require.NoError(t, Load(store))
+	assert.True(t, Enabled(), "the stored mode must be loaded")
+
+	require.NoError(t, Set(store, false))
+	assert.False(t, Enabled())
+}
+
+func TestWatch(t *testing.T) {
+	var value atomic.Value
+	value.Store("false")
+	store := mocks.NewMockStore(t)
+	store.On("ServerConfigGet", configKey).Return(func(string) (string, error) {
+		return value.Load().(string), nil
+	})
+	t.Cleanup(func() { enabled.Store(false) })
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan struct{})
+	go func() {
+		Watch(ctx, store, 10*time.Millisecond)
+		close(done)
+	}()
+
+	// another server sharing the database enables the maintenance mode
+	value.Store("true")
+	assert.Eventually(t, Enabled, time.Second, 10*time.Millisecond)
+
+	value.Store("false")
+	assert.Eventually(t, func() bool { return !Enabled() }, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+}
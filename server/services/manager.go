@@ -23,9 +23,11 @@ import (
 	"github.com/urfave/cli/v3"
 
 	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge/retry"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/config"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/environment"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/notification"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/registry"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/secret"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/utils"
@@ -44,6 +46,7 @@ type Manager interface {
 	RegistryService() registry.Service
 	ConfigServiceFromRepo(repo *model.Repo) config.Service
 	EnvironmentService() environment.Service
+	NotificationService() notification.Service
 	ForgeFromRepo(repo *model.Repo) (forge.Forge, error)
 	ForgeFromUser(user *model.User) (forge.Forge, error)
 	ForgeByID(forgeID int64) (forge.Forge, error)
@@ -57,8 +60,10 @@ type manager struct {
 	registry            registry.Service
 	config              config.Service
 	environment         environment.Service
+	notification        notification.Service
 	forgeCache          *ttlcache.Cache[int64, forge.Forge]
 	setupForge          SetupForge
+	forgeRetry          retry.Config
 	client              *utils.Client
 }
 
@@ -83,17 +88,27 @@ func NewManager(c *cli.Command, store store.Store, setupForge SetupForge) (Manag
 		return nil, err
 	}
 
+	secretService, err := setupSecretService(c, store)
+	if err != nil {
+		return nil, err
+	}
+
 	return &manager{
 		signaturePrivateKey: signaturePrivateKey,
 		signaturePublicKey:  signaturePublicKey,
 		store:               store,
-		secret:              setupSecretService(store),
+		secret:              secretService,
 		registry:            setupRegistryService(store, c.String("docker-config")),
 		config:              configService,
 		environment:         environment.Parse(c.StringSlice("environment")),
+		notification:        setupNotificationService(c),
 		forgeCache:          ttlcache.New(ttlcache.WithDisableTouchOnHit[int64, forge.Forge]()),
 		setupForge:          setupForge,
-		client:              client,
+		forgeRetry: retry.Config{
+			Max:     c.Uint("forge-retry-max"),
+			Backoff: c.Duration("forge-retry-backoff"),
+		},
+		client: client,
 	}, nil
 }
 
@@ -129,6 +144,10 @@ func (m *manager) EnvironmentService() environment.Service {
 	return m.environment
 }
 
+func (m *manager) NotificationService() notification.Service {
+	return m.notification
+}
+
 func (m *manager) ForgeFromRepo(repo *model.Repo) (forge.Forge, error) {
 	return m.ForgeByID(repo.ForgeID)
 }
@@ -152,6 +171,9 @@ func (m *manager) ForgeByID(id int64) (forge.Forge, error) {
 	if err != nil {
 		return nil, err
 	}
+	if m.forgeRetry.Max > 0 {
+		forge = retry.New(forge, m.forgeRetry)
+	}
 
 	m.forgeCache.Set(id, forge, forgeCacheTTL)
 
@@ -12,6 +12,7 @@ import (
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/config"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/environment"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/notification"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/registry"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/secret"
 )
@@ -328,6 +329,52 @@ func (_c *MockManager_ForgeFromUser_Call) RunAndReturn(run func(user *model.User
 	return _c
 }
 
+// NotificationService provides a mock function for the type MockManager
+func (_mock *MockManager) NotificationService() notification.Service {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for NotificationService")
+	}
+
+	var r0 notification.Service
+	if returnFunc, ok := ret.Get(0).(func() notification.Service); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(notification.Service)
+		}
+	}
+	return r0
+}
+
+// MockManager_NotificationService_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'NotificationService'
+type MockManager_NotificationService_Call struct {
+	*mock.Call
+}
+
+// NotificationService is a helper method to define mock.On call
+func (_e *MockManager_Expecter) NotificationService() *MockManager_NotificationService_Call {
+	return &MockManager_NotificationService_Call{Call: _e.mock.On("NotificationService")}
+}
+
+func (_c *MockManager_NotificationService_Call) Run(run func()) *MockManager_NotificationService_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockManager_NotificationService_Call) Return(service notification.Service) *MockManager_NotificationService_Call {
+	_c.Call.Return(service)
+	return _c
+}
+
+func (_c *MockManager_NotificationService_Call) RunAndReturn(run func() notification.Service) *MockManager_NotificationService_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RegistryService provides a mock function for the type MockManager
 func (_mock *MockManager) RegistryService() registry.Service {
 	ret := _mock.Called()
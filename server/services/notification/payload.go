@@ -0,0 +1,108 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"fmt"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+// Payload is sent to the notification webhook of a repo.
+type Payload struct {
+	Event       string          `json:"event"`
+	Repo        PayloadRepo     `json:"repo"`
+	Pipeline    PayloadPipeline `json:"pipeline"`
+	FailedSteps []PayloadStep   `json:"failed_steps"`
+}
+
+// PayloadRepo is the repo of the notified pipeline.
+type PayloadRepo struct {
+	ID       int64  `json:"id"`
+	FullName string `json:"full_name"`
+	ForgeURL string `json:"forge_url"`
+}
+
+// PayloadPipeline is the notified pipeline.
+type PayloadPipeline struct {
+	Number   int64              `json:"number"`
+	Status   model.StatusValue  `json:"status"`
+	Event    model.WebhookEvent `json:"event"`
+	Branch   string             `json:"branch"`
+	Ref      string             `json:"ref"`
+	Commit   string             `json:"commit"`
+	Message  string             `json:"message"`
+	Author   string             `json:"author"`
+	Created  int64              `json:"created"`
+	Started  int64              `json:"started"`
+	Finished int64              `json:"finished"`
+	URL      string             `json:"url"`
+	ForgeURL string             `json:"forge_url"`
+}
+
+// PayloadStep is a failed step of the notified pipeline.
+type PayloadStep struct {
+	Workflow string            `json:"workflow"`
+	Name     string            `json:"name"`
+	State    model.StatusValue `json:"state"`
+	ExitCode int               `json:"exit_code"`
+	Error    string            `json:"error,omitempty"`
+}
+
+const eventPipeline = "pipeline"
+
+func newPayload(host string, repo *model.Repo, pipeline *model.Pipeline) *Payload {
+	payload := &Payload{
+		Event: eventPipeline,
+		Repo: PayloadRepo{
+			ID:       repo.ID,
+			FullName: repo.FullName,
+			ForgeURL: repo.ForgeURL,
+		},
+		Pipeline: PayloadPipeline{
+			Number:   pipeline.Number,
+			Status:   pipeline.Status,
+			Event:    pipeline.Event,
+			Branch:   pipeline.Branch,
+			Ref:      pipeline.Ref,
+			Commit:   pipeline.Commit,
+			Message:  pipeline.Message,
+			Author:   pipeline.Author,
+			Created:  pipeline.Created,
+			Started:  pipeline.Started,
+			Finished: pipeline.Finished,
+			URL:      fmt.Sprintf("%s/repos/%d/pipeline/%d", host, repo.ID, pipeline.Number),
+			ForgeURL: pipeline.ForgeURL,
+		},
+		FailedSteps: []PayloadStep{},
+	}
+
+	for _, workflow := range pipeline.Workflows {
+		for _, step := range workflow.Children {
+			if !step.Failing() {
+				continue
+			}
+			payload.FailedSteps = append(payload.FailedSteps, PayloadStep{
+				Workflow: workflow.Name,
+				Name:     step.Name,
+				State:    step.State,
+				ExitCode: step.ExitCode,
+				Error:    step.Error,
+			})
+		}
+	}
+
+	return payload
+}
@@ -0,0 +1,35 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+// Service notifies about pipeline state transitions.
+type Service interface {
+	// Notify sends the pipeline to the notification webhook of the repo,
+	// if the repo has one and is subscribed to the pipeline's status.
+	Notify(repo *model.Repo, pipeline *model.Pipeline)
+}
+
+// DefaultEvents are the pipeline statuses notified about if a repo has not selected any.
+var DefaultEvents = []model.StatusValue{
+	model.StatusSuccess,
+	model.StatusFailure,
+	model.StatusError,
+	model.StatusKilled,
+	model.StatusDeclined,
+}
@@ -0,0 +1,144 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+const (
+	// SignatureHeader holds the hex encoded HMAC-SHA256 of the body, keyed with the secret of the repo.
+	SignatureHeader = "X-Woodpecker-Signature-256"
+	// EventHeader holds the kind of the notification.
+	EventHeader = "X-Woodpecker-Event"
+
+	defaultRetries = 3
+	defaultBackoff = time.Second
+	sendTimeout    = time.Minute
+)
+
+type webhook struct {
+	client  *http.Client
+	host    string
+	retries int
+	backoff time.Duration
+}
+
+// NewWebhook returns a Service posting a JSON payload to the notification webhook of the repo.
+// Failed deliveries are retried with an exponential backoff. The host is used to link the pipeline.
+func NewWebhook(client *http.Client, host string) Service {
+	return &webhook{
+		client:  client,
+		host:    host,
+		retries: defaultRetries,
+		backoff: defaultBackoff,
+	}
+}
+
+func (w *webhook) Notify(repo *model.Repo, pipeline *model.Pipeline) {
+	if repo.NotifyURL == "" || !subscribed(repo.NotifyEvents, pipeline.Status) {
+		return
+	}
+
+	body, err := json.Marshal(newPayload(w.host, repo, pipeline))
+	if err != nil {
+		log.Error().Err(err).Msg("cannot marshal notification payload")
+		return
+	}
+
+	url, secret := repo.NotifyURL, repo.NotifySecret
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		defer cancel()
+
+		if err := w.send(ctx, url, secret, body); err != nil {
+			log.Error().Err(err).Str("repo", repo.FullName).Int64("pipeline", pipeline.Number).Msg("cannot send notification")
+		}
+	}()
+}
+
+// send posts the body to the url, retrying failed deliveries.
+func (w *webhook) send(ctx context.Context, url, secret string, body []byte) error {
+	backoff := w.backoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		var retry bool
+		if retry, err = w.post(ctx, url, secret, body); err == nil || !retry || attempt >= w.retries {
+			return err
+		}
+
+		log.Debug().Err(err).Msgf("notification failed, retry in %s", backoff)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// post sends the body once and returns whether a failed delivery should be retried.
+func (w *webhook) post(ctx context.Context, url, secret string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, eventPipeline)
+	if secret != "" {
+		req.Header.Set(SignatureHeader, Sign(secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return false, nil
+	}
+	err = fmt.Errorf("notification webhook returned status %d", resp.StatusCode)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError, err
+}
+
+// Sign returns the signature of the body as sent in the SignatureHeader.
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func subscribed(events []model.StatusValue, status model.StatusValue) bool {
+	if len(events) == 0 {
+		events = DefaultEvents
+	}
+	return slices.Contains(events, status)
+}
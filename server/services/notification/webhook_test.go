@@ -0,0 +1,159 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package notification
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+var fakePipeline = &model.Pipeline{
+	Number:   42,
+	Status:   model.StatusFailure,
+	Event:    model.EventPush,
+	Branch:   "main",
+	Ref:      "refs/heads/main",
+	Commit:   "d2aa3a3",
+	Message:  "fix tests",
+	Author:   "octocat",
+	Created:  1700000000,
+	Started:  1700000010,
+	Finished: 1700000070,
+	ForgeURL: "https://github.com/octocat/hello-world/commit/d2aa3a3",
+	Workflows: []*model.Workflow{
+		{
+			Name: "test",
+			Children: []*model.Step{
+				{Name: "clone", State: model.StatusSuccess, Failure: model.FailureFail},
+				{Name: "lint", State: model.StatusFailure, Failure: model.FailureIgnore, ExitCode: 1},
+				{Name: "test", State: model.StatusFailure, Failure: model.FailureFail, ExitCode: 2},
+			},
+		},
+		{
+			Name: "build",
+			Children: []*model.Step{
+				{Name: "build", State: model.StatusError, Failure: model.FailureFail, Error: "image not found"},
+			},
+		},
+	},
+}
+
+func TestPayload(t *testing.T) {
+	repo := &model.Repo{
+		ID:       1,
+		FullName: "octocat/hello-world",
+		ForgeURL: "https://github.com/octocat/hello-world",
+	}
+
+	body, err := json.Marshal(newPayload("https://ci.example.com", repo, fakePipeline))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"event": "pipeline",
+		"repo": {
+			"id": 1,
+			"full_name": "octocat/hello-world",
+			"forge_url": "https://github.com/octocat/hello-world"
+		},
+		"pipeline": {
+			"number": 42,
+			"status": "failure",
+			"event": "push",
+			"branch": "main",
+			"ref": "refs/heads/main",
+			"commit": "d2aa3a3",
+			"message": "fix tests",
+			"author": "octocat",
+			"created": 1700000000,
+			"started": 1700000010,
+			"finished": 1700000070,
+			"url": "https://ci.example.com/repos/1/pipeline/42",
+			"forge_url": "https://github.com/octocat/hello-world/commit/d2aa3a3"
+		},
+		"failed_steps": [
+			{"workflow": "test", "name": "test", "state": "failure", "exit_code": 2},
+			{"workflow": "build", "name": "build", "state": "error", "exit_code": 0, "error": "image not found"}
+		]
+	}`, string(body))
+}
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event":"pipeline"}`)
+	assert.Equal(t, "sha256=fd8e01e800c7716fc89abf088891e2fffde2b936f38bc2217794e6c92535a6aa", Sign("secret", body))
+	assert.NotEqual(t, Sign("secret", body), Sign("other", body))
+}
+
+func TestSubscribed(t *testing.T) {
+	assert.True(t, subscribed(nil, model.StatusFailure))
+	assert.False(t, subscribed(nil, model.StatusRunning))
+	assert.True(t, subscribed([]model.StatusValue{model.StatusRunning}, model.StatusRunning))
+	assert.False(t, subscribed([]model.StatusValue{model.StatusRunning}, model.StatusSuccess))
+}
+
+func TestSend(t *testing.T) {
+	var calls atomic.Int32
+	var signature, event string
+	var received []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		signature = r.Header.Get(SignatureHeader)
+		event = r.Header.Get(EventHeader)
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	w := &webhook{client: srv.Client(), retries: 3, backoff: time.Millisecond}
+	body := []byte(`{"event":"pipeline"}`)
+	require.NoError(t, w.send(t.Context(), srv.URL, "secret", body))
+	assert.EqualValues(t, 3, calls.Load())
+	assert.Equal(t, Sign("secret", body), signature)
+	assert.Equal(t, "pipeline", event)
+	assert.Equal(t, body, received)
+}
+
+func TestSendGivesUp(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	w := &webhook{client: srv.Client(), retries: 2, backoff: time.Millisecond}
+	assert.EqualError(t, w.send(t.Context(), srv.URL, "", nil), "notification webhook returned status 503")
+	assert.EqualValues(t, 3, calls.Load())
+
+	// client errors are not retried
+	calls.Store(0)
+	srv.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		assert.Empty(t, r.Header.Get(SignatureHeader))
+		w.WriteHeader(http.StatusNotFound)
+	})
+	assert.Error(t, w.send(t.Context(), srv.URL, "", nil))
+	assert.EqualValues(t, 1, calls.Load())
+}
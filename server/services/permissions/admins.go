@@ -1,18 +1,181 @@
 package permissions
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/cache"
+	"go.woodpecker-ci.org/woodpecker/v3/server/forge"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
-	"go.woodpecker-ci.org/woodpecker/v3/shared/utils"
 )
 
+// TeamResolver returns if the user is a member of the team in the format <org>/<team>.
+type TeamResolver func(ctx context.Context, _forge forge.Forge, user *model.User, team string) (bool, error)
+
+// NewAdmins creates the admins from user names and team references in the format @<org>/<team>.
+// Empty entries are ignored.
 func NewAdmins(admins []string) *Admins {
-	return &Admins{admins: utils.SliceToBoolMap(admins)}
+	a := &Admins{static: admins}
+	a.set(admins)
+	return a
 }
 
 type Admins struct {
-	admins map[string]bool
+	mu       sync.RWMutex
+	static   []string
+	file     string
+	admins   map[string]bool
+	teams    []string
+	resolver TeamResolver
+}
+
+// SetTeamResolver sets the resolver used to check the membership of the admin teams.
+// Without resolver only the user names grant admin permissions.
+func (a *Admins) SetTeamResolver(resolver TeamResolver) {
+	a.resolver = resolver
+}
+
+// LoadFile adds the admins listed in the file to the ones passed to NewAdmins.
+// The file is read again on every Reload.
+func (a *Admins) LoadFile(path string) error {
+	a.file = path
+	return a.Reload()
+}
+
+// Reload reads the admin file again and merges its entries with the ones passed to NewAdmins.
+// If the file can't be read or contains invalid entries, the current admins are kept.
+func (a *Admins) Reload() error {
+	if a.file == "" {
+		return nil
+	}
+
+	entries, err := ReadAdminFile(a.file)
+	if err != nil {
+		return err
+	}
+	a.set(append(slices.Clone(a.static), entries...))
+	return nil
+}
+
+func (a *Admins) set(entries []string) {
+	admins := make(map[string]bool)
+	var teams []string
+	for _, admin := range entries {
+		if admin == "" {
+			continue
+		}
+		if team, ok := strings.CutPrefix(admin, "@"); ok {
+			if !slices.Contains(teams, team) {
+				teams = append(teams, team)
+			}
+			continue
+		}
+		admins[admin] = true
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.admins = admins
+	a.teams = teams
+}
+
+// Configured returns if any admins are configured. In that case the configuration decides
+// about the admin permission of a user on every login.
+func (a *Admins) Configured() bool {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.admins) > 0 || len(a.teams) > 0
+}
+
+func (a *Admins) IsAdmin(ctx context.Context, _forge forge.Forge, user *model.User) bool {
+	a.mu.RLock()
+	isAdmin, teams := a.admins[user.Login], a.teams
+	a.mu.RUnlock()
+
+	if isAdmin {
+		return true
+	}
+	if a.resolver == nil {
+		return false
+	}
+	for _, team := range teams {
+		member, err := a.resolver(ctx, _forge, user, team)
+		if err != nil {
+			log.Error().Err(err).Msgf("cannot check membership of %s in admin team %s", user.Login, team)
+			continue
+		}
+		if member {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadAdminFile reads the admins from a file with one user name or team reference per line.
+// Empty lines and lines starting with # are ignored.
+func ReadAdminFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var admins []string
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := ValidateAdmin(line); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+		admins = append(admins, line)
+	}
+	return admins, nil
+}
+
+// ValidateAdmins checks that all non empty entries are valid user names or team references.
+func ValidateAdmins(admins []string) error {
+	for _, admin := range admins {
+		if admin == "" {
+			continue
+		}
+		if err := ValidateAdmin(admin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ValidateAdmin checks that the entry is a user name or a reference in the format @<org> or @<org>/<team>.
+func ValidateAdmin(admin string) error {
+	if admin == "" {
+		return fmt.Errorf("admin must not be empty")
+	}
+	if strings.ContainsFunc(admin, unicode.IsSpace) {
+		return fmt.Errorf("admin '%s' must not contain whitespace", admin)
+	}
+	if team, ok := strings.CutPrefix(admin, "@"); ok {
+		if org, name, hasTeam := strings.Cut(team, "/"); org == "" || (hasTeam && name == "") {
+			return fmt.Errorf("admin team '%s' must be in the format @<org> or @<org>/<team>", admin)
+		}
+	}
+	return nil
 }
 
-func (a *Admins) IsAdmin(user *model.User) bool {
-	return a.admins[user.Login]
+// MembershipResolver resolves the admin teams using the membership service, which caches the memberships.
+func MembershipResolver(membership cache.MembershipService) TeamResolver {
+	return func(ctx context.Context, _forge forge.Forge, user *model.User, team string) (bool, error) {
+		perm, err := membership.Get(ctx, _forge, user, team)
+		if err != nil {
+			return false, err
+		}
+		return perm.Member, nil
+	}
 }
@@ -1,18 +1,138 @@
 package permissions
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
+	"go.woodpecker-ci.org/woodpecker/v3/server/cache"
+	forge_mocks "go.woodpecker-ci.org/woodpecker/v3/server/forge/mocks"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 )
 
+// teamForge is a forge with teams, the members are given per "<org>/<team>".
+type teamForge struct {
+	*forge_mocks.MockForge
+	members map[string][]string
+	calls   int
+}
+
+func (f *teamForge) TeamMembership(_ context.Context, u *model.User, org, team string) (bool, error) {
+	f.calls++
+	for _, member := range f.members[org+"/"+team] {
+		if member == u.Login {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func TestAdmins(t *testing.T) {
 	a := NewAdmins([]string{"woodpecker-ci"})
-	assert.True(t, a.IsAdmin(&model.User{Login: "woodpecker-ci"}))
-	assert.False(t, a.IsAdmin(&model.User{Login: "not-woodpecker-ci"}))
+	assert.True(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "woodpecker-ci"}))
+	assert.False(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "not-woodpecker-ci"}))
 	empty := NewAdmins([]string{})
-	assert.False(t, empty.IsAdmin(&model.User{Login: "woodpecker-ci"}))
-	assert.False(t, empty.IsAdmin(&model.User{Login: "not-woodpecker-ci"}))
+	assert.False(t, empty.IsAdmin(t.Context(), nil, &model.User{Login: "woodpecker-ci"}))
+	assert.False(t, empty.IsAdmin(t.Context(), nil, &model.User{Login: "not-woodpecker-ci"}))
+	assert.True(t, a.Configured())
+	assert.False(t, empty.Configured())
+	assert.True(t, NewAdmins([]string{"@myorg/platform"}).Configured())
+}
+
+func TestAdminsTeams(t *testing.T) {
+	forge := &teamForge{
+		MockForge: forge_mocks.NewMockForge(t),
+		members:   map[string][]string{"myorg/platform": {"jane"}},
+	}
+	a := NewAdmins([]string{"john", "@myorg/platform"})
+
+	t.Run("without resolver", func(t *testing.T) {
+		assert.True(t, a.IsAdmin(t.Context(), forge, &model.User{Login: "john"}))
+		assert.False(t, a.IsAdmin(t.Context(), forge, &model.User{Login: "jane"}))
+		assert.Zero(t, forge.calls)
+	})
+
+	a.SetTeamResolver(MembershipResolver(cache.NewMembershipService(nil)))
+
+	t.Run("user name", func(t *testing.T) {
+		assert.True(t, a.IsAdmin(t.Context(), forge, &model.User{Login: "john", ForgeRemoteID: "1"}))
+		assert.Zero(t, forge.calls)
+	})
+
+	t.Run("team member", func(t *testing.T) {
+		jane := &model.User{Login: "jane", ForgeRemoteID: "2"}
+		assert.True(t, a.IsAdmin(t.Context(), forge, jane))
+		assert.True(t, a.IsAdmin(t.Context(), forge, jane))
+		assert.Equal(t, 1, forge.calls, "the membership must be cached")
+	})
+
+	t.Run("no team member", func(t *testing.T) {
+		assert.False(t, a.IsAdmin(t.Context(), forge, &model.User{Login: "bob", ForgeRemoteID: "3"}))
+	})
+
+	t.Run("team is not a user name", func(t *testing.T) {
+		assert.False(t, a.IsAdmin(t.Context(), forge, &model.User{Login: "@myorg/platform", ForgeRemoteID: "4"}))
+	})
+}
+
+func TestAdminsMerge(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "admins")
+	require.NoError(t, os.WriteFile(file, []byte("# emergency admins\njane\n\n  john  \n@myorg/platform\n"), 0o600))
+
+	a := NewAdmins([]string{"john", "", "@myorg/platform", "@myorg/platform"})
+	require.NoError(t, a.LoadFile(file))
+
+	for _, login := range []string{"john", "jane"} {
+		assert.True(t, a.IsAdmin(t.Context(), nil, &model.User{Login: login}))
+	}
+	assert.False(t, a.IsAdmin(t.Context(), nil, &model.User{Login: ""}))
+	assert.False(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "# emergency admins"}))
+	assert.Len(t, a.admins, 2)
+	assert.Equal(t, []string{"myorg/platform"}, a.teams)
+}
+
+func TestAdminsReload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "admins")
+	require.NoError(t, os.WriteFile(file, []byte("jane\n"), 0o600))
+
+	a := NewAdmins([]string{"john"})
+	require.NoError(t, a.LoadFile(file))
+	assert.True(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "jane"}))
+
+	t.Run("changed file", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte("bob\n"), 0o600))
+		require.NoError(t, a.Reload())
+		assert.True(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "bob"}))
+		assert.False(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "jane"}))
+		assert.True(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "john"}), "admins of the flag are kept")
+	})
+
+	t.Run("invalid file keeps admins", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(file, []byte("alice\n@myorg/\n"), 0o600))
+		assert.EqualError(t, a.Reload(), file+":2: admin team '@myorg/' must be in the format @<org> or @<org>/<team>")
+		assert.True(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "bob"}))
+		assert.False(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "alice"}))
+	})
+
+	t.Run("missing file keeps admins", func(t *testing.T) {
+		require.NoError(t, os.Remove(file))
+		assert.Error(t, a.Reload())
+		assert.True(t, a.IsAdmin(t.Context(), nil, &model.User{Login: "bob"}))
+	})
+
+	t.Run("without file", func(t *testing.T) {
+		assert.NoError(t, NewAdmins([]string{"john"}).Reload())
+	})
+}
+
+func TestValidateAdmins(t *testing.T) {
+	assert.NoError(t, ValidateAdmins([]string{"john", "", "@myorg", "@myorg/platform", "@group/sub/team"}))
+	assert.EqualError(t, ValidateAdmins([]string{"john doe"}), "admin 'john doe' must not contain whitespace")
+	assert.EqualError(t, ValidateAdmins([]string{"@"}), "admin team '@' must be in the format @<org> or @<org>/<team>")
+	assert.EqualError(t, ValidateAdmins([]string{"@/platform"}), "admin team '@/platform' must be in the format @<org> or @<org>/<team>")
+	assert.EqualError(t, ValidateAdmin(""), "admin must not be empty")
 }
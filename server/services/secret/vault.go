@@ -0,0 +1,293 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jellydator/ttlcache/v3"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+const (
+	defaultVaultMount    = "secret"
+	defaultVaultPrefix   = "woodpecker"
+	defaultVaultCacheTTL = 30 * time.Second
+	vaultRequestTimeout  = 30 * time.Second
+)
+
+// VaultConfig is the configuration of the vault secret service.
+type VaultConfig struct {
+	Address string
+	// Mount is the path of the kv version 2 secrets engine.
+	Mount string
+	// Prefix is the path inside of the secrets engine the secrets are stored below.
+	Prefix   string
+	Auth     VaultAuth
+	CacheTTL time.Duration
+}
+
+// vaultSecret is the data of a secret stored in vault.
+type vaultSecret struct {
+	Value  string               `json:"value"`
+	Images []string             `json:"images,omitempty"`
+	Events []model.WebhookEvent `json:"events,omitempty"`
+}
+
+// vaultScope is the folder of the secrets of a repository, an organization or the global secrets.
+type vaultScope struct {
+	path   string
+	orgID  int64
+	repoID int64
+}
+
+type vault struct {
+	client *vaultClient
+	prefix string
+	cache  *ttlcache.Cache[string, []*model.Secret]
+}
+
+// NewVault returns a secret service which stores the secrets in a kv version 2 secrets engine of vault.
+// The secrets are stored as <prefix>/repo/<repo-id>/<name>, <prefix>/org/<org-id>/<name> and <prefix>/global/<name>.
+// The secrets of each of these folders are cached for a short time, as they are fetched for every pipeline.
+func NewVault(config VaultConfig) (Service, error) {
+	if config.Address == "" {
+		return nil, errors.New("vault address is required")
+	}
+	if config.Auth.Token == "" && (config.Auth.RoleID == "" || config.Auth.SecretID == "") {
+		return nil, errors.New("vault token or approle role id and secret id are required")
+	}
+	if config.Mount == "" {
+		config.Mount = defaultVaultMount
+	}
+	if config.Prefix == "" {
+		config.Prefix = defaultVaultPrefix
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = defaultVaultCacheTTL
+	}
+
+	return &vault{
+		client: &vaultClient{
+			http:    http.DefaultClient,
+			address: strings.TrimSuffix(config.Address, "/"),
+			mount:   strings.Trim(config.Mount, "/"),
+			auth:    config.Auth,
+		},
+		prefix: strings.Trim(config.Prefix, "/"),
+		cache:  ttlcache.New(ttlcache.WithTTL[string, []*model.Secret](config.CacheTTL)),
+	}, nil
+}
+
+func (v *vault) repoScope(repo *model.Repo) vaultScope {
+	return vaultScope{path: v.prefix + "/repo/" + strconv.FormatInt(repo.ID, 10), repoID: repo.ID}
+}
+
+func (v *vault) orgScope(orgID int64) vaultScope {
+	return vaultScope{path: v.prefix + "/org/" + strconv.FormatInt(orgID, 10), orgID: orgID}
+}
+
+func (v *vault) globalScope() vaultScope {
+	return vaultScope{path: v.prefix + "/global"}
+}
+
+// secrets returns all secrets of the scope sorted by name.
+func (v *vault) secrets(scope vaultScope) ([]*model.Secret, error) {
+	if item := v.cache.Get(scope.path); item != nil {
+		return item.Value(), nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), vaultRequestTimeout)
+	defer cancel()
+
+	names, err := v.client.list(ctx, scope.path)
+	if err != nil {
+		return nil, err
+	}
+
+	secrets := make([]*model.Secret, 0, len(names))
+	for _, name := range names {
+		data := &vaultSecret{}
+		err := v.client.read(ctx, scope.path+"/"+name, data)
+		if errors.Is(err, errVaultNotFound) {
+			// removed in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read secret %s: %w", name, err)
+		}
+		secrets = append(secrets, &model.Secret{
+			OrgID:  scope.orgID,
+			RepoID: scope.repoID,
+			Name:   name,
+			Value:  data.Value,
+			Images: data.Images,
+			Events: data.Events,
+		})
+	}
+
+	v.cache.Set(scope.path, secrets, ttlcache.DefaultTTL)
+	return secrets, nil
+}
+
+func (v *vault) find(scope vaultScope, name string) (*model.Secret, error) {
+	secrets, err := v.secrets(scope)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		if secret.Name == name {
+			return copySecret(secret), nil
+		}
+	}
+	return nil, types.RecordNotExist
+}
+
+func (v *vault) list(scope vaultScope, p *model.ListOptions) ([]*model.Secret, error) {
+	secrets, err := v.secrets(scope)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		secrets = model.ApplyPagination(p, secrets)
+	}
+	// the cached secrets must not be changed by the caller
+	copies := make([]*model.Secret, 0, len(secrets))
+	for _, secret := range secrets {
+		copies = append(copies, copySecret(secret))
+	}
+	return copies, nil
+}
+
+// copySecret returns a copy of a cached secret, which can be changed by the caller.
+func copySecret(secret *model.Secret) *model.Secret {
+	c := *secret
+	c.Images = slices.Clone(secret.Images)
+	c.Events = slices.Clone(secret.Events)
+	return &c
+}
+
+func (v *vault) write(scope vaultScope, in *model.Secret, create bool) error {
+	defer v.cache.Delete(scope.path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), vaultRequestTimeout)
+	defer cancel()
+	return v.client.write(ctx, scope.path+"/"+in.Name, &vaultSecret{
+		Value:  in.Value,
+		Images: in.Images,
+		Events: in.Events,
+	}, create)
+}
+
+func (v *vault) delete(scope vaultScope, name string) error {
+	if _, err := v.find(scope, name); err != nil {
+		return err
+	}
+	defer v.cache.Delete(scope.path)
+
+	ctx, cancel := context.WithTimeout(context.Background(), vaultRequestTimeout)
+	defer cancel()
+	return v.client.delete(ctx, scope.path+"/"+name)
+}
+
+func (v *vault) SecretFind(repo *model.Repo, name string) (*model.Secret, error) {
+	return v.find(v.repoScope(repo), name)
+}
+
+func (v *vault) SecretList(repo *model.Repo, p *model.ListOptions) ([]*model.Secret, error) {
+	return v.list(v.repoScope(repo), p)
+}
+
+func (v *vault) SecretListPipeline(repo *model.Repo, _ *model.Pipeline) ([]*model.Secret, error) {
+	// Return only secrets with unique name
+	// Priority order in case of duplicate names are repository, user/organization, global
+	var secrets []*model.Secret
+	uniq := make(map[string]struct{})
+	for _, scope := range []vaultScope{v.repoScope(repo), v.orgScope(repo.OrgID), v.globalScope()} {
+		list, err := v.secrets(scope)
+		if err != nil {
+			return nil, err
+		}
+		for _, secret := range list {
+			if _, ok := uniq[secret.Name]; ok {
+				continue
+			}
+			uniq[secret.Name] = struct{}{}
+			secrets = append(secrets, copySecret(secret))
+		}
+	}
+	return secrets, nil
+}
+
+func (v *vault) SecretCreate(repo *model.Repo, in *model.Secret) error {
+	return v.write(v.repoScope(repo), in, true)
+}
+
+func (v *vault) SecretUpdate(repo *model.Repo, in *model.Secret) error {
+	return v.write(v.repoScope(repo), in, false)
+}
+
+func (v *vault) SecretDelete(repo *model.Repo, name string) error {
+	return v.delete(v.repoScope(repo), name)
+}
+
+func (v *vault) OrgSecretFind(owner int64, name string) (*model.Secret, error) {
+	return v.find(v.orgScope(owner), name)
+}
+
+func (v *vault) OrgSecretList(owner int64, p *model.ListOptions) ([]*model.Secret, error) {
+	return v.list(v.orgScope(owner), p)
+}
+
+func (v *vault) OrgSecretCreate(owner int64, in *model.Secret) error {
+	return v.write(v.orgScope(owner), in, true)
+}
+
+func (v *vault) OrgSecretUpdate(owner int64, in *model.Secret) error {
+	return v.write(v.orgScope(owner), in, false)
+}
+
+func (v *vault) OrgSecretDelete(owner int64, name string) error {
+	return v.delete(v.orgScope(owner), name)
+}
+
+func (v *vault) GlobalSecretFind(name string) (*model.Secret, error) {
+	return v.find(v.globalScope(), name)
+}
+
+func (v *vault) GlobalSecretList(p *model.ListOptions) ([]*model.Secret, error) {
+	return v.list(v.globalScope(), p)
+}
+
+func (v *vault) GlobalSecretCreate(in *model.Secret) error {
+	return v.write(v.globalScope(), in, true)
+}
+
+func (v *vault) GlobalSecretUpdate(in *model.Secret) error {
+	return v.write(v.globalScope(), in, false)
+}
+
+func (v *vault) GlobalSecretDelete(name string) error {
+	return v.delete(v.globalScope(), name)
+}
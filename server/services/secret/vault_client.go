@@ -0,0 +1,206 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+var (
+	errVaultNotFound  = errors.New("vault: secret not found")
+	errVaultForbidden = errors.New("vault: permission denied")
+)
+
+// VaultAuth are the credentials used to authenticate at vault,
+// either a token or the role id and secret id of an AppRole.
+type VaultAuth struct {
+	Token    string
+	RoleID   string
+	SecretID string
+}
+
+// vaultClient implements the subset of the vault API used to manage secrets in a kv version 2 secrets engine.
+type vaultClient struct {
+	sync.Mutex
+
+	http    *http.Client
+	address string
+	mount   string
+	auth    VaultAuth
+	token   string
+}
+
+type vaultResponse struct {
+	Data json.RawMessage `json:"data"`
+	Auth *struct {
+		ClientToken string `json:"client_token"`
+	} `json:"auth"`
+	Errors []string `json:"errors"`
+}
+
+// read returns the data of the latest version of the secret at path.
+func (c *vaultClient) read(ctx context.Context, path string, data any) error {
+	var resp struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := c.request(ctx, http.MethodGet, c.mount+"/data/"+path, nil, &resp); err != nil {
+		return err
+	}
+	return json.Unmarshal(resp.Data, data)
+}
+
+// write stores data as new version of the secret at path.
+// If create is set the secret must not exist yet.
+func (c *vaultClient) write(ctx context.Context, path string, data any, create bool) error {
+	body := map[string]any{"data": data}
+	if create {
+		// check-and-set with version zero only allows the write if the secret does not exist
+		body["options"] = map[string]any{"cas": 0}
+	}
+	return c.request(ctx, http.MethodPost, c.mount+"/data/"+path, body, nil)
+}
+
+// list returns the names of the secrets below path.
+func (c *vaultClient) list(ctx context.Context, path string) ([]string, error) {
+	var resp struct {
+		Keys []string `json:"keys"`
+	}
+	err := c.request(ctx, "LIST", c.mount+"/metadata/"+path, nil, &resp)
+	if errors.Is(err, errVaultNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(resp.Keys))
+	for _, key := range resp.Keys {
+		// keys ending with a slash are folders
+		if !strings.HasSuffix(key, "/") {
+			names = append(names, key)
+		}
+	}
+	return names, nil
+}
+
+// delete removes all versions of the secret at path.
+func (c *vaultClient) delete(ctx context.Context, path string) error {
+	return c.request(ctx, http.MethodDelete, c.mount+"/metadata/"+path, nil, nil)
+}
+
+func (c *vaultClient) request(ctx context.Context, method, path string, body, data any) error {
+	token, err := c.clientToken(ctx, false)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.do(ctx, method, path, token, body)
+	// the token of an AppRole login expires, so login again once
+	if errors.Is(err, errVaultForbidden) && c.auth.RoleID != "" {
+		if token, err = c.clientToken(ctx, true); err != nil {
+			return err
+		}
+		resp, err = c.do(ctx, method, path, token, body)
+	}
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		return nil
+	}
+	return json.Unmarshal(resp.Data, data)
+}
+
+func (c *vaultClient) clientToken(ctx context.Context, renew bool) (string, error) {
+	if c.auth.RoleID == "" {
+		return c.auth.Token, nil
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	if c.token != "" && !renew {
+		return c.token, nil
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "auth/approle/login", "", map[string]string{
+		"role_id":   c.auth.RoleID,
+		"secret_id": c.auth.SecretID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("vault: approle login failed: %w", err)
+	}
+	if resp.Auth == nil || resp.Auth.ClientToken == "" {
+		return "", errors.New("vault: approle login did not return a token")
+	}
+	c.token = resp.Auth.ClientToken
+	return c.token, nil
+}
+
+// do sends the request and returns the decoded response.
+func (c *vaultClient) do(ctx context.Context, method, path, token string, body any) (*vaultResponse, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.address+"/v1/"+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("X-Vault-Token", token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &vaultResponse{}
+	if data, err := io.ReadAll(resp.Body); err != nil {
+		return nil, err
+	} else if len(data) > 0 {
+		if err := json.Unmarshal(data, result); err != nil {
+			return nil, fmt.Errorf("vault: could not decode response: %w", err)
+		}
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errVaultNotFound
+	case resp.StatusCode == http.StatusForbidden:
+		return nil, errVaultForbidden
+	case resp.StatusCode >= http.StatusBadRequest:
+		return nil, fmt.Errorf("vault: %s %s failed with status %d: %s", method, path, resp.StatusCode, strings.Join(result.Errors, ", "))
+	}
+	return result, nil
+}
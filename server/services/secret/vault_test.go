@@ -0,0 +1,231 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secret_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/secret"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+// fakeVault is an in-memory kv version 2 secrets engine mounted at "secret".
+type fakeVault struct {
+	sync.Mutex
+	token   string
+	secrets map[string]json.RawMessage
+	reads   int
+	logins  int
+}
+
+func (f *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.Lock()
+	defer f.Unlock()
+
+	if r.URL.Path == "/v1/auth/approle/login" {
+		var login map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&login)
+		if login["role_id"] != "role" || login["secret_id"] != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.logins++
+		f.token = "approle-token"
+		_, _ = w.Write([]byte(`{"auth":{"client_token":"approle-token"}}`))
+		return
+	}
+
+	if r.Header.Get("X-Vault-Token") != f.token {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		return
+	}
+
+	switch {
+	case r.Method == "LIST" && strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/"):
+		folder := strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/") + "/"
+		var keys []string
+		for path := range f.secrets {
+			if name, ok := strings.CutPrefix(path, folder); ok {
+				// nested folders are listed with a trailing slash
+				if subfolder, _, nested := strings.Cut(name, "/"); nested {
+					name = subfolder + "/"
+				}
+				keys = append(keys, name)
+			}
+		}
+		if len(keys) == 0 {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		sort.Strings(keys)
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"keys": keys}})
+	case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		f.reads++
+		data, ok := f.secrets[strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"data": data}})
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		path := strings.TrimPrefix(r.URL.Path, "/v1/secret/data/")
+		var body struct {
+			Data    json.RawMessage `json:"data"`
+			Options *struct {
+				CAS int `json:"cas"`
+			} `json:"options"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if _, exists := f.secrets[path]; exists && body.Options != nil && body.Options.CAS == 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"errors":["check-and-set parameter did not match the current version"]}`))
+			return
+		}
+		f.secrets[path] = body.Data
+		_, _ = w.Write([]byte(`{"data":{"version":1}}`))
+	case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/v1/secret/metadata/"):
+		delete(f.secrets, strings.TrimPrefix(r.URL.Path, "/v1/secret/metadata/"))
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newFakeVault(t *testing.T, token string) (*fakeVault, string) {
+	fake := &fakeVault{
+		token: token,
+		secrets: map[string]json.RawMessage{
+			"woodpecker/global/shared":   json.RawMessage(`{"value":"global-shared"}`),
+			"woodpecker/global/global":   json.RawMessage(`{"value":"global-value","events":["push"]}`),
+			"woodpecker/org/2/shared":    json.RawMessage(`{"value":"org-shared"}`),
+			"woodpecker/repo/1/shared":   json.RawMessage(`{"value":"repo-shared","images":["alpine"]}`),
+			"woodpecker/repo/1/nested/a": json.RawMessage(`{"value":"nested"}`),
+		},
+	}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+	return fake, srv.URL
+}
+
+func TestVaultFetch(t *testing.T) {
+	fake, addr := newFakeVault(t, "token")
+	service, err := secret.NewVault(secret.VaultConfig{Address: addr, Auth: secret.VaultAuth{Token: "token"}})
+	require.NoError(t, err)
+
+	repo := &model.Repo{ID: 1, OrgID: 2}
+	secrets, err := service.SecretListPipeline(repo, &model.Pipeline{})
+	require.NoError(t, err)
+	assert.Equal(t, []*model.Secret{
+		{RepoID: 1, Name: "shared", Value: "repo-shared", Images: []string{"alpine"}},
+		{Name: "global", Value: "global-value", Events: []model.WebhookEvent{model.EventPush}},
+	}, secrets)
+
+	s, err := service.OrgSecretFind(2, "shared")
+	require.NoError(t, err)
+	assert.Equal(t, &model.Secret{OrgID: 2, Name: "shared", Value: "org-shared"}, s)
+
+	_, err = service.SecretFind(repo, "missing")
+	assert.ErrorIs(t, err, types.RecordNotExist)
+
+	t.Run("wrong token", func(t *testing.T) {
+		service, err := secret.NewVault(secret.VaultConfig{Address: addr, Auth: secret.VaultAuth{Token: "wrong"}})
+		require.NoError(t, err)
+		_, err = service.GlobalSecretList(&model.ListOptions{All: true})
+		assert.ErrorContains(t, err, "permission denied")
+	})
+
+	t.Run("manage secrets", func(t *testing.T) {
+		in := &model.Secret{RepoID: 1, Name: "new", Value: "new-value", Events: []model.WebhookEvent{model.EventTag}}
+		require.NoError(t, service.SecretCreate(repo, in))
+		assert.Error(t, service.SecretCreate(repo, in), "existing secrets must not be overwritten")
+
+		in.Value = "changed"
+		require.NoError(t, service.SecretUpdate(repo, in))
+		s, err := service.SecretFind(repo, "new")
+		require.NoError(t, err)
+		assert.Equal(t, in, s)
+
+		require.NoError(t, service.SecretDelete(repo, "new"))
+		assert.ErrorIs(t, service.SecretDelete(repo, "new"), types.RecordNotExist)
+		assert.NotContains(t, fake.secrets, "woodpecker/repo/1/new")
+	})
+}
+
+func TestVaultCache(t *testing.T) {
+	fake, addr := newFakeVault(t, "token")
+	service, err := secret.NewVault(secret.VaultConfig{Address: addr, Auth: secret.VaultAuth{Token: "token"}})
+	require.NoError(t, err)
+
+	repo := &model.Repo{ID: 1, OrgID: 2}
+	for range 3 {
+		_, err := service.SecretListPipeline(repo, &model.Pipeline{})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 4, fake.reads, "each secret must only be read once")
+
+	// the returned secrets are copies of the cached ones
+	list, err := service.SecretList(repo, &model.ListOptions{All: true})
+	require.NoError(t, err)
+	require.NotEmpty(t, list)
+	list[0].Value = "modified"
+	list, err = service.SecretList(repo, &model.ListOptions{All: true})
+	require.NoError(t, err)
+	assert.NotEqual(t, "modified", list[0].Value)
+
+	// a change of the secret is visible immediately
+	s, err := service.SecretFind(repo, "shared")
+	require.NoError(t, err)
+	s.Value = "changed"
+	require.NoError(t, service.SecretUpdate(repo, s))
+	s, err = service.SecretFind(repo, "shared")
+	require.NoError(t, err)
+	assert.Equal(t, "changed", s.Value)
+	assert.Equal(t, 5, fake.reads)
+}
+
+func TestVaultAppRole(t *testing.T) {
+	fake, addr := newFakeVault(t, "")
+	service, err := secret.NewVault(secret.VaultConfig{Address: addr, Auth: secret.VaultAuth{RoleID: "role", SecretID: "secret"}})
+	require.NoError(t, err)
+
+	list, err := service.GlobalSecretList(&model.ListOptions{All: true})
+	require.NoError(t, err)
+	assert.Len(t, list, 2)
+	assert.Equal(t, 1, fake.logins)
+
+	// the token expired
+	fake.token = "other"
+	_, err = service.OrgSecretList(2, &model.ListOptions{All: true})
+	require.NoError(t, err)
+	assert.Equal(t, 2, fake.logins)
+}
+
+func TestNewVault(t *testing.T) {
+	_, err := secret.NewVault(secret.VaultConfig{Auth: secret.VaultAuth{Token: "token"}})
+	assert.EqualError(t, err, "vault address is required")
+	_, err = secret.NewVault(secret.VaultConfig{Address: "http://vault:8200", Auth: secret.VaultAuth{RoleID: "role"}})
+	assert.EqualError(t, err, "vault token or approle role id and secret id are required")
+}
@@ -21,20 +21,27 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"net/http"
 	"strings"
+	"time"
 
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v3"
 
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/config"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/notification"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/registry"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/secret"
 	"go.woodpecker-ci.org/woodpecker/v3/server/services/utils"
+	host_matcher "go.woodpecker-ci.org/woodpecker/v3/server/services/utils/hostmatcher"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
 )
 
+const notificationTimeout = 10 * time.Second
+
 func setupRegistryService(store store.Store, dockerConfig string) registry.Service {
 	if dockerConfig != "" {
 		return registry.NewCombined(
@@ -46,16 +53,33 @@ func setupRegistryService(store store.Store, dockerConfig string) registry.Servi
 	return registry.NewDB(store)
 }
 
-func setupSecretService(store store.Store) secret.Service {
-	// TODO(1544): fix encrypted store
-	// // encryption
-	// encryptedSecretStore := encryptedStore.NewSecretStore(v)
-	// err := encryption.Encryption(c, v).WithClient(encryptedSecretStore).Build()
-	// if err != nil {
-	// 	log.Fatal().Err(err).Msg("could not create encryption service")
-	// }
-
-	return secret.NewDB(store)
+func setupSecretService(c *cli.Command, store store.Store) (secret.Service, error) {
+	switch c.String("secret-backend") {
+	case "database":
+		// TODO(1544): fix encrypted store
+		// // encryption
+		// encryptedSecretStore := encryptedStore.NewSecretStore(v)
+		// err := encryption.Encryption(c, v).WithClient(encryptedSecretStore).Build()
+		// if err != nil {
+		// 	log.Fatal().Err(err).Msg("could not create encryption service")
+		// }
+
+		return secret.NewDB(store), nil
+	case "vault":
+		return secret.NewVault(secret.VaultConfig{
+			Address: c.String("vault-addr"),
+			Mount:   c.String("vault-mount"),
+			Prefix:  c.String("vault-prefix"),
+			Auth: secret.VaultAuth{
+				Token:    c.String("vault-token"),
+				RoleID:   c.String("vault-role-id"),
+				SecretID: c.String("vault-secret-id"),
+			},
+			CacheTTL: c.Duration("vault-cache-ttl"),
+		})
+	default:
+		return nil, fmt.Errorf("secret backend '%s' is not supported", c.String("secret-backend"))
+	}
 }
 
 func setupConfigService(c *cli.Command, client *utils.Client) (config.Service, error) {
@@ -74,6 +98,26 @@ func setupConfigService(c *cli.Command, client *utils.Client) (config.Service, e
 	return configFetcher, nil
 }
 
+// setupNotificationService creates the service which sends the pipeline notifications to the configured webhooks.
+func setupNotificationService(c *cli.Command) notification.Service {
+	allowedHosts := c.String("extensions-allowed-hosts")
+	if allowedHosts == "" {
+		allowedHosts = host_matcher.MatchBuiltinExternal
+	}
+	allowedHostMatcher := host_matcher.ParseHostMatchList("WOODPECKER_EXTENSIONS_ALLOWED_HOSTS", allowedHosts)
+
+	client := &http.Client{
+		Timeout: notificationTimeout,
+		Transport: httputil.NewUserAgentRoundTripper(
+			&http.Transport{
+				DialContext: host_matcher.NewDialContext("notifications", allowedHostMatcher),
+			},
+			"server-notifications",
+		),
+	}
+	return notification.NewWebhook(client, strings.TrimSuffix(c.String("server-host"), "/"))
+}
+
 // setupSignatureKeys generate or load key pair to sign webhooks requests (i.e. used for service extensions).
 func setupSignatureKeys(_store store.Store) (ed25519.PrivateKey, crypto.PublicKey, error) {
 	privKeyID := "signature-private-key"
@@ -107,6 +151,30 @@ func setupForgeService(c *cli.Command, _store store.Store) error {
 		return err
 	}
 	forgeExists := err == nil
+
+	_forge, err = ForgeFromFlags(c, _forge)
+	if err != nil {
+		return err
+	}
+
+	if forgeExists {
+		err := _store.ForgeUpdate(_forge)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := _store.ForgeCreate(_forge)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// ForgeFromFlags applies the forge configured by the flags to the given forge,
+// if it is nil a new forge is returned.
+func ForgeFromFlags(c *cli.Command, _forge *model.Forge) (*model.Forge, error) {
 	if _forge == nil {
 		_forge = &model.Forge{
 			ID: 0,
@@ -121,6 +189,8 @@ func setupForgeService(c *cli.Command, _store store.Store) error {
 	_forge.URL = c.String("forge-url")
 	_forge.SkipVerify = c.Bool("forge-skip-verify")
 	_forge.OAuthHost = c.String("forge-oauth-host")
+	_forge.AdditionalOptions["proxy"] = c.String("forge-proxy")
+	_forge.AdditionalOptions["no-proxy"] = c.String("forge-no-proxy")
 
 	switch {
 	case c.String("addon-forge") != "":
@@ -157,20 +227,8 @@ func setupForgeService(c *cli.Command, _store store.Store) error {
 		_forge.AdditionalOptions["git-password"] = c.String("bitbucket-dc-git-password")
 		_forge.AdditionalOptions["oauth-enable-project-admin-scope"] = c.Bool("bitbucket-dc-oauth-enable-oauth2-scope-project-admin")
 	default:
-		return errors.New("forge not configured")
-	}
-
-	if forgeExists {
-		err := _store.ForgeUpdate(_forge)
-		if err != nil {
-			return err
-		}
-	} else {
-		err := _store.ForgeCreate(_forge)
-		if err != nil {
-			return err
-		}
+		return nil, errors.New("forge not configured")
 	}
 
-	return nil
+	return _forge, nil
 }
@@ -0,0 +1,120 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tokenhash hashes secrets like agent tokens before they get stored.
+// Every hash is prefixed with the name of its algorithm, so hashes created with
+// an older algorithm can still be verified and upgraded after the configured
+// algorithm changed.
+//
+// The hashed tokens are generated randomly with enough entropy, so they are neither
+// salted nor stretched. This keeps the hashes deterministic and allows to look up
+// an agent by its hashed token.
+package tokenhash
+
+import (
+	"crypto/sha256"
+	"crypto/sha3"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// DefaultAlgorithm is used if no other algorithm is configured.
+const DefaultAlgorithm = "sha256"
+
+// ErrUnknownAlgorithm is returned if a token should be hashed with an unsupported algorithm.
+var ErrUnknownAlgorithm = errors.New("unknown token hash algorithm")
+
+var algorithms = map[string]func([]byte) []byte{
+	"sha256": func(b []byte) []byte {
+		sum := sha256.Sum256(b)
+		return sum[:]
+	},
+	"sha512": func(b []byte) []byte {
+		sum := sha512.Sum512(b)
+		return sum[:]
+	},
+	"sha3-256": func(b []byte) []byte {
+		sum := sha3.Sum256(b)
+		return sum[:]
+	},
+}
+
+// Algorithms returns the names of all supported algorithms.
+func Algorithms() []string {
+	names := make([]string, 0, len(algorithms))
+	for name := range algorithms {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Hash hashes the token with the given algorithm.
+func Hash(algorithm, token string) (string, error) {
+	hash, ok := algorithms[algorithm]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownAlgorithm, algorithm)
+	}
+	return algorithm + ":" + hex.EncodeToString(hash([]byte(token))), nil
+}
+
+// Verify checks in constant time if the hashed value is a hash of the token.
+func Verify(hashed, token string) bool {
+	algorithm, _, ok := strings.Cut(hashed, ":")
+	if !ok || token == "" {
+		return false
+	}
+	expected, err := Hash(algorithm, token)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(hashed)) == 1
+}
+
+// IsHashed checks if the value is a hash created by one of the supported algorithms.
+func IsHashed(value string) bool {
+	algorithm, _, ok := strings.Cut(value, ":")
+	if !ok {
+		return false
+	}
+	_, ok = algorithms[algorithm]
+	return ok
+}
+
+// IsCurrent checks if the hashed value was created with the given algorithm.
+// Hashes of other algorithms should be replaced the next time the token is presented.
+func IsCurrent(algorithm, hashed string) bool {
+	return strings.HasPrefix(hashed, algorithm+":")
+}
+
+// Candidates returns the hashes of the token for all supported algorithms,
+// starting with the given one. They are used to look up stored hashes.
+func Candidates(algorithm, token string) []string {
+	names := Algorithms()
+	if i := slices.Index(names, algorithm); i > 0 {
+		names = append([]string{algorithm}, slices.Delete(names, i, i+1)...)
+	}
+
+	hashes := make([]string, 0, len(names))
+	for _, name := range names {
+		hash, _ := Hash(name, token)
+		hashes = append(hashes, hash)
+	}
+	return hashes
+}
@@ -0,0 +1,71 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenhash
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHash(t *testing.T) {
+	for _, algorithm := range Algorithms() {
+		t.Run(algorithm, func(t *testing.T) {
+			hashed, err := Hash(algorithm, "token")
+			require.NoError(t, err)
+			assert.NotContains(t, hashed, "token")
+			assert.True(t, IsHashed(hashed))
+			assert.True(t, IsCurrent(algorithm, hashed))
+
+			again, err := Hash(algorithm, "token")
+			require.NoError(t, err)
+			assert.Equal(t, hashed, again)
+
+			assert.True(t, Verify(hashed, "token"))
+			assert.False(t, Verify(hashed, "other"))
+			assert.False(t, Verify(hashed, ""))
+			assert.False(t, Verify(hashed, hashed))
+		})
+	}
+
+	_, err := Hash("md5", "token")
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+}
+
+func TestVerify(t *testing.T) {
+	hashed, err := Hash("sha256", "token")
+	require.NoError(t, err)
+
+	// hashes must match completely, a prefix or a different algorithm is not enough
+	assert.False(t, Verify(hashed[:len(hashed)-1], "token"))
+	assert.False(t, Verify(hashed+"0", "token"))
+	assert.False(t, Verify("sha512"+hashed[len("sha256"):], "token"))
+
+	// unhashed tokens are never valid
+	assert.False(t, IsHashed("token"))
+	assert.False(t, Verify("token", "token"))
+	assert.False(t, Verify("md5:token", "token"))
+}
+
+func TestCandidates(t *testing.T) {
+	candidates := Candidates("sha512", "token")
+	require.Len(t, candidates, len(Algorithms()))
+	assert.True(t, IsCurrent("sha512", candidates[0]))
+	for _, hashed := range candidates {
+		assert.True(t, Verify(hashed, "token"))
+	}
+	assert.Equal(t, []string{"sha256", "sha3-256", "sha512"}, Algorithms())
+}
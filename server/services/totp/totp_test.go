@@ -0,0 +1,110 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package totp
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// rfcSecret is the base32 encoded secret "12345678901234567890" of the RFC 6238 test vectors.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestCode(t *testing.T) {
+	// the RFC test vectors use 8 digits, the codes are their last 6 digits
+	for unix, expected := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		code, err := Code(rfcSecret, Step(time.Unix(unix, 0)))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code, unix)
+	}
+
+	_, err := Code("not base32!", 1)
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+	current := Step(now)
+
+	step, ok := Validate(rfcSecret, "081804", now, 0)
+	assert.True(t, ok)
+	assert.Equal(t, current, step)
+
+	previous, err := Code(rfcSecret, current-1)
+	require.NoError(t, err)
+	step, ok = Validate(rfcSecret, previous, now, 0)
+	assert.True(t, ok, "codes of the previous period are accepted for clock drift")
+	assert.Equal(t, current-1, step)
+
+	_, ok = Validate(rfcSecret, "081 804", now, 0)
+	assert.True(t, ok, "spaces are ignored")
+
+	_, ok = Validate(rfcSecret, "081804", now, current)
+	assert.False(t, ok, "used codes are rejected")
+
+	_, ok = Validate(rfcSecret, "000000", now, 0)
+	assert.False(t, ok)
+
+	_, ok = Validate(rfcSecret, "081804", now.Add(5*Period), 0)
+	assert.False(t, ok, "expired codes are rejected")
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	other, err := GenerateSecret()
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, other)
+
+	code, err := Code(secret, Step(time.Now()))
+	require.NoError(t, err)
+	_, ok := Validate(secret, code, time.Now(), 0)
+	assert.True(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri, err := url.Parse(URI("Woodpecker", "octocat", rfcSecret))
+	require.NoError(t, err)
+	assert.Equal(t, "otpauth", uri.Scheme)
+	assert.Equal(t, "totp", uri.Host)
+	assert.Equal(t, "/Woodpecker:octocat", uri.Path)
+	assert.Equal(t, rfcSecret, uri.Query().Get("secret"))
+	assert.Equal(t, "Woodpecker", uri.Query().Get("issuer"))
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := RecoveryCodes(10)
+	require.NoError(t, err)
+	assert.Len(t, codes, 10)
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Len(t, code, 17)
+		assert.Equal(t, strings.ToLower(code), code)
+		assert.False(t, seen[code])
+		seen[code] = true
+	}
+}
@@ -14,19 +14,100 @@
 
 package store
 
-import "time"
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+)
 
 type XORM struct {
-	Log             bool
-	ShowSQL         bool
-	MaxIdleConns    int
-	MaxOpenConns    int
+	Log     bool
+	ShowSQL bool
+	// SlowQueryThreshold is the duration after which a query is logged, zero disables the logging.
+	SlowQueryThreshold time.Duration
+	Pool
+}
+
+// Pool are the connection pool settings of a database engine.
+type Pool struct {
+	MaxIdleConns int
+	// MaxOpenConns limits the open connections, zero or less means unlimited.
+	MaxOpenConns int
+	// ConnMaxLifetime is the time a connection may be reused, zero means forever.
 	ConnMaxLifetime time.Duration
+	// ConnMaxIdleTime is the time a connection may be idle before it is closed, zero means forever.
+	ConnMaxIdleTime time.Duration
+}
+
+// Validate checks the pool settings are consistent.
+func (p Pool) Validate() error {
+	if p.MaxOpenConns > 0 && p.MaxIdleConns > p.MaxOpenConns {
+		return fmt.Errorf("max idle connections (%d) must not exceed max open connections (%d)", p.MaxIdleConns, p.MaxOpenConns)
+	}
+	if p.MaxIdleConns < 0 || p.ConnMaxLifetime < 0 || p.ConnMaxIdleTime < 0 {
+		return fmt.Errorf("connection pool settings must not be negative")
+	}
+	return nil
+}
+
+// SQLite are options only applied to sqlite3 databases.
+type SQLite struct {
+	// JournalMode is the journal_mode pragma, empty keeps the default of the database.
+	JournalMode string
+	// BusyTimeout is how long to wait for a locked database before failing.
+	BusyTimeout time.Duration
+}
+
+// TLSModes are the supported modes of TLS connections to the database.
+var TLSModes = []string{"disable", "require", "verify-ca", "verify-full"}
+
+// TLS are the options of TLS connections to mysql and postgres databases.
+// They are translated into the parameters of the driver, parameters already set in the data source are kept.
+type TLS struct {
+	// Mode is one of TLSModes, empty means verify-full.
+	Mode string
+	// CA is the path of the certificate authority verifying the server certificate.
+	CA string
+	// Cert and Key are the paths of the client certificate and its key.
+	Cert string
+	Key  string
+}
+
+// Enabled reports whether any TLS option is set.
+func (t TLS) Enabled() bool {
+	return t != TLS{}
+}
+
+// Validate checks the TLS mode is supported and the certificate files exist.
+func (t TLS) Validate() error {
+	if t.Mode != "" && !slices.Contains(TLSModes, t.Mode) {
+		return fmt.Errorf("tls mode '%s' is not supported", t.Mode)
+	}
+	if (t.Cert == "") != (t.Key == "") {
+		return errors.New("tls client certificate and key must be set together")
+	}
+	for _, file := range []string{t.CA, t.Cert, t.Key} {
+		if file == "" {
+			continue
+		}
+		if _, err := os.Stat(file); err != nil {
+			return fmt.Errorf("tls file: %w", err)
+		}
+	}
+	return nil
 }
 
 // Opts are options for a new database connection.
 type Opts struct {
 	Driver string
 	Config string
-	XORM   XORM
+	// ReplicaConfig is the optional connection string of a read replica.
+	ReplicaConfig string
+	// ReplicaPool are the pool settings of the read replica, if nil the pool settings of XORM are used.
+	ReplicaPool *Pool
+	XORM        XORM
+	SQLite      SQLite
+	TLS         TLS
 }
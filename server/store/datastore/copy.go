@@ -0,0 +1,230 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"xorm.io/builder"
+	"xorm.io/xorm"
+	"xorm.io/xorm/schemas"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+// copyBatchSize is the number of rows read from the source at once.
+const copyBatchSize = 1000
+
+// copyTables are all tables in the order they are copied, so rows are copied after the rows they reference.
+var copyTables = []any{
+	new(model.Forge),
+	new(model.User),
+	new(model.Org),
+	new(model.Repo),
+	new(model.Perm),
+	new(model.Agent),
+	new(model.ServerConfig),
+	new(model.Secret),
+	new(model.Registry),
+	new(model.Cron),
+	new(model.Redirection),
+	new(model.Pipeline),
+	new(model.Config),
+	new(model.PipelineConfig),
+	new(model.Workflow),
+	new(model.Step),
+	new(model.LogEntry),
+	new(model.Task),
+	new(model.Lease),
+}
+
+// TableCopy reports the rows of a table copied by Copy.
+type TableCopy struct {
+	Table string
+	// Copied is the number of rows inserted into the target.
+	Copied int64
+	// Source and Target are the number of rows in the tables after copying.
+	Source int64
+	Target int64
+}
+
+// Copy copies the rows of all tables from one store into another, preserving their IDs. Both stores have to be
+// migrated already. Rows which exist in the target are skipped, so an interrupted copy can be resumed.
+// After copying the row counts of all tables are compared, a mismatch is returned as error.
+func Copy(ctx context.Context, from, to store.Store, progress func(TableCopy)) ([]TableCopy, error) {
+	source, err := engineOf(from)
+	if err != nil {
+		return nil, fmt.Errorf("source: %w", err)
+	}
+	target, err := engineOf(to)
+	if err != nil {
+		return nil, fmt.Errorf("target: %w", err)
+	}
+
+	var copies []TableCopy
+	for _, bean := range copyTables {
+		tc, err := copyTable(ctx, source, target, bean)
+		if err != nil {
+			return copies, fmt.Errorf("could not copy table %s: %w", tc.Table, err)
+		}
+		if progress != nil {
+			progress(tc)
+		}
+		copies = append(copies, tc)
+	}
+
+	var errs []error
+	for _, tc := range copies {
+		if tc.Source != tc.Target {
+			errs = append(errs, fmt.Errorf("table %s has %d rows in the source but %d in the target", tc.Table, tc.Source, tc.Target))
+		}
+	}
+	return copies, errors.Join(errs...)
+}
+
+func engineOf(s store.Store) (*xorm.Engine, error) {
+	switch s := s.(type) {
+	case *storage:
+		return s.engine, nil
+	case *replicated:
+		return engineOf(s.Store)
+	default:
+		return nil, fmt.Errorf("unsupported store %T", s)
+	}
+}
+
+func copyTable(ctx context.Context, source, target *xorm.Engine, bean any) (TableCopy, error) {
+	table, err := source.TableInfo(bean)
+	if err != nil {
+		return TableCopy{}, err
+	}
+	tc := TableCopy{Table: table.Name}
+
+	keys := keyColumns(table)
+	if len(keys) == 0 {
+		return tc, fmt.Errorf("table has no primary key or unique index")
+	}
+	autoIncr := table.AutoIncrColumn()
+
+	// rows up to the highest id in the target were copied already by a previous run
+	var lastID int64
+	if autoIncr != nil {
+		if _, err := target.Table(table.Name).Select(fmt.Sprintf("COALESCE(MAX(%s), 0)", target.Quote(autoIncr.Name))).Get(&lastID); err != nil {
+			return tc, err
+		}
+	}
+
+	beanType := reflect.TypeOf(bean).Elem()
+	for offset := 0; ; offset += copyBatchSize {
+		if err := ctx.Err(); err != nil {
+			return tc, err
+		}
+
+		rows := reflect.New(reflect.SliceOf(reflect.PointerTo(beanType)))
+		sess := source.Table(table.Name).Asc(keys...)
+		if autoIncr != nil {
+			sess = sess.Where(builder.Gt{autoIncr.Name: lastID}).Limit(copyBatchSize)
+		} else {
+			sess = sess.Limit(copyBatchSize, offset)
+		}
+		if err := sess.Find(rows.Interface()); err != nil {
+			return tc, err
+		}
+
+		n := rows.Elem().Len()
+		for i := range n {
+			row := rows.Elem().Index(i).Interface()
+			inserted, err := insertRow(target, table, keys, row)
+			if err != nil {
+				return tc, err
+			}
+			if inserted {
+				tc.Copied++
+			}
+			if autoIncr != nil {
+				id, err := autoIncr.ValueOf(row)
+				if err != nil {
+					return tc, err
+				}
+				lastID = id.Int()
+			}
+		}
+		if n < copyBatchSize {
+			break
+		}
+	}
+
+	if autoIncr != nil && tc.Copied > 0 {
+		if err := resetSequence(target, table.Name, autoIncr.Name); err != nil {
+			return tc, err
+		}
+	}
+
+	if tc.Source, err = source.Table(table.Name).Count(); err != nil {
+		return tc, err
+	}
+	if tc.Target, err = target.Table(table.Name).Count(); err != nil {
+		return tc, err
+	}
+	return tc, nil
+}
+
+// insertRow inserts the row into the target, if no row with the same key exists yet.
+func insertRow(target *xorm.Engine, table *schemas.Table, keys []string, row any) (bool, error) {
+	cond := builder.Eq{}
+	for _, key := range keys {
+		value, err := table.GetColumn(key).ValueOf(row)
+		if err != nil {
+			return false, err
+		}
+		cond[key] = value.Interface()
+	}
+	exists, err := target.Table(table.Name).Where(cond).Exist()
+	if err != nil || exists {
+		return false, err
+	}
+
+	// keep the timestamps of the source
+	_, err = target.NoAutoTime().Insert(row)
+	return err == nil, err
+}
+
+// keyColumns returns the primary key columns of the table, or the columns of a unique index
+// for tables without primary key.
+func keyColumns(table *schemas.Table) []string {
+	if len(table.PrimaryKeys) > 0 {
+		return table.PrimaryKeys
+	}
+	for _, index := range table.Indexes {
+		if index.Type == schemas.UniqueType {
+			return index.Cols
+		}
+	}
+	return nil
+}
+
+// resetSequence sets the sequence of an auto increment column to its highest value,
+// as inserting rows with an explicit id does not advance it on postgres.
+func resetSequence(e *xorm.Engine, table, column string) error {
+	if e.Dialect().URI().DBType != schemas.POSTGRES {
+		return nil
+	}
+	_, err := e.Exec(fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%s', '%s'), (SELECT MAX(%s) FROM %s))", table, column, e.Quote(column), e.Quote(table)))
+	return err
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+func newSqliteStore(t *testing.T) store.Store {
+	s, err := NewEngine(&store.Opts{
+		Driver: "sqlite3",
+		Config: filepath.Join(t.TempDir(), "woodpecker.sqlite"),
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, s.Close()) })
+	require.NoError(t, s.Migrate(t.Context(), true, 0))
+	return s
+}
+
+func TestCopy(t *testing.T) {
+	source := newSqliteStore(t)
+	target := newSqliteStore(t)
+
+	user := &model.User{Login: "octocat", Email: "octocat@example.com", ForgeRemoteID: "1", Admin: true, Hash: "1"}
+	require.NoError(t, source.CreateUser(user))
+	// leave a gap in the ids
+	require.NoError(t, source.CreateUser(&model.User{Login: "deleted", ForgeRemoteID: "2", Hash: "2"}))
+	_, err := source.(*storage).engine.ID(2).Delete(new(model.User))
+	require.NoError(t, err)
+
+	repo := &model.Repo{UserID: user.ID, FullName: "octocat/hello-world", Owner: "octocat", Name: "hello-world", ForgeRemoteID: "1"}
+	require.NoError(t, source.CreateRepo(repo))
+	require.NoError(t, source.PermUpsert(&model.Perm{UserID: user.ID, RepoID: repo.ID, Push: true}))
+	require.NoError(t, source.ServerConfigSet("key", "value"))
+
+	pipeline := &model.Pipeline{RepoID: repo.ID, Status: model.StatusSuccess, Commit: "abc"}
+	require.NoError(t, source.CreatePipeline(pipeline, &model.Step{Name: "build", State: model.StatusSuccess}))
+	// timestamps are kept as they are
+	_, err = source.(*storage).engine.Exec("UPDATE pipelines SET created = 1000 WHERE id = ?", pipeline.ID)
+	require.NoError(t, err)
+	require.NoError(t, source.TaskInsert(&model.Task{ID: "1", Labels: map[string]string{"platform": "linux"}}))
+
+	copies, err := Copy(t.Context(), source, target, nil)
+	require.NoError(t, err)
+	assert.Len(t, copies, len(copyTables))
+	for _, tc := range copies {
+		assert.Equal(t, tc.Source, tc.Target, tc.Table)
+	}
+
+	copiedUser, err := target.GetUser(user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user, copiedUser)
+
+	copiedRepo, err := target.GetRepo(repo.ID)
+	require.NoError(t, err)
+	assert.Equal(t, repo.FullName, copiedRepo.FullName)
+
+	perm, err := target.PermFind(user, repo)
+	require.NoError(t, err)
+	assert.True(t, perm.Push)
+
+	sourcePipeline, err := source.GetPipeline(pipeline.ID)
+	require.NoError(t, err)
+	copiedPipeline, err := target.GetPipeline(pipeline.ID)
+	require.NoError(t, err)
+	assert.Equal(t, sourcePipeline, copiedPipeline)
+	assert.EqualValues(t, 1000, copiedPipeline.Created)
+	steps, err := target.StepList(copiedPipeline)
+	require.NoError(t, err)
+	assert.Len(t, steps, 1)
+
+	value, err := target.ServerConfigGet("key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+
+	tasks, err := target.TaskList()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"platform": "linux"}, tasks[0].Labels)
+
+	t.Run("resume", func(t *testing.T) {
+		require.NoError(t, source.CreateUser(&model.User{Login: "new", ForgeRemoteID: "3", Hash: "3"}))
+
+		copies, err := Copy(t.Context(), source, target, nil)
+		require.NoError(t, err)
+		for _, tc := range copies {
+			switch tc.Table {
+			case "users", "orgs":
+				// creating a user creates its org as well
+				assert.EqualValues(t, 1, tc.Copied, tc.Table)
+			default:
+				assert.Zero(t, tc.Copied, tc.Table)
+			}
+		}
+
+		// new rows in the target get ids after the copied ones
+		another := &model.User{Login: "another", ForgeRemoteID: "4", Hash: "4"}
+		require.NoError(t, target.CreateUser(another))
+		assert.EqualValues(t, 4, another.ID)
+	})
+
+	t.Run("verify", func(t *testing.T) {
+		require.NoError(t, target.ServerConfigSet("other", "value"))
+
+		_, err := Copy(t.Context(), source, target, nil)
+		assert.ErrorContains(t, err, "table server_configs has 1 rows in the source but 2 in the target")
+	})
+}
+
+func TestCopyTablesComplete(t *testing.T) {
+	engine := newSqliteStore(t).(*storage).engine
+
+	tables, err := engine.DBMetas()
+	require.NoError(t, err)
+	var names []string
+	for _, table := range tables {
+		if table.Name != "xormigrate_migrations" {
+			names = append(names, table.Name)
+		}
+	}
+
+	var copied []string
+	for _, bean := range copyTables {
+		table, err := engine.TableInfo(bean)
+		require.NoError(t, err)
+		copied = append(copied, table.Name)
+	}
+	assert.ElementsMatch(t, names, copied)
+}
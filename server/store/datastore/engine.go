@@ -16,11 +16,15 @@ package datastore
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/rs/zerolog"
 	"xorm.io/xorm"
 	xlog "xorm.io/xorm/log"
 
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store"
 	"go.woodpecker-ci.org/woodpecker/v3/server/store/datastore/migration"
 )
@@ -32,7 +36,45 @@ type storage struct {
 const perPage = 50
 
 func NewEngine(opts *store.Opts) (store.Store, error) {
-	engine, err := xorm.NewEngine(opts.Driver, opts.Config)
+	if err := opts.XORM.Pool.Validate(); err != nil {
+		return nil, err
+	}
+	replicaPool := opts.XORM.Pool
+	if opts.ReplicaPool != nil {
+		if err := opts.ReplicaPool.Validate(); err != nil {
+			return nil, fmt.Errorf("replica: %w", err)
+		}
+		replicaPool = *opts.ReplicaPool
+	}
+
+	primary, err := newStorage(opts, opts.Config, opts.XORM.Pool)
+	if err != nil {
+		return nil, err
+	}
+	if opts.ReplicaConfig == "" {
+		return primary, nil
+	}
+
+	replica, err := newStorage(opts, opts.ReplicaConfig, replicaPool)
+	if err != nil {
+		return nil, errors.Join(err, primary.Close())
+	}
+	return &replicated{Store: primary, replica: replica}, nil
+}
+
+func newStorage(opts *store.Opts, config string, pool store.Pool) (*storage, error) {
+	if opts.Driver == "sqlite3" {
+		var err error
+		if config, err = sqliteDataSource(config, opts.SQLite); err != nil {
+			return nil, err
+		}
+	}
+	config, err := tlsDataSource(opts.Driver, config, opts.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	engine, err := xorm.NewEngine(opts.Driver, config)
 	if err != nil {
 		return nil, err
 	}
@@ -45,9 +87,11 @@ func NewEngine(opts *store.Opts) (store.Store, error) {
 	logger := newXORMLogger(level)
 	engine.SetLogger(logger)
 	engine.ShowSQL(opts.XORM.ShowSQL)
-	engine.SetMaxOpenConns(opts.XORM.MaxOpenConns)
-	engine.SetMaxIdleConns(opts.XORM.MaxIdleConns)
-	engine.SetConnMaxLifetime(opts.XORM.ConnMaxLifetime)
+	engine.AddHook(newQueryHook(opts.XORM.SlowQueryThreshold))
+	engine.SetMaxOpenConns(pool.MaxOpenConns)
+	engine.SetMaxIdleConns(pool.MaxIdleConns)
+	engine.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	engine.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	return &storage{
 		engine: engine,
@@ -59,10 +103,26 @@ func (s storage) Ping() error {
 }
 
 // Migrate old storage or init new one.
-func (s storage) Migrate(ctx context.Context, allowLong bool) error {
+func (s storage) Migrate(ctx context.Context, allowLong bool, lockTimeout time.Duration) error {
+	unlock, err := s.lockMigrations(ctx, lockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
 	return migration.Migrate(ctx, s.engine, allowLong)
 }
 
+// MigrationStatus lists all known migrations and whether they were applied.
+func (s storage) MigrationStatus() ([]*model.MigrationStatus, error) {
+	return migration.Status(s.engine)
+}
+
+// MigrationRollback reverts the last applied migration if it matches the given id.
+func (s storage) MigrationRollback(id string) error {
+	return migration.Rollback(s.engine, id)
+}
+
 func (s storage) Close() error {
 	return s.engine.Close()
 }
@@ -54,6 +54,70 @@ func (s storage) GetPipelineQueue() ([]*model.Feed, error) {
 	return feed, err
 }
 
+func (s storage) QueryPipelines(user *model.User, query *model.PipelineQuery, p *model.ListOptions) ([]*model.Feed, error) {
+	feed := make([]*model.Feed, 0, perPage)
+
+	sess := s.paginate(p).Table("pipelines").
+		Select(s.getFeedSelect()).
+		Join("INNER", "repos", "pipelines.repo_id = repos.id")
+	// the pipelines of inactive repos are hidden like the repos themselves
+	cond := builder.NewCond().And(builder.Eq{"repos.active": true})
+	if user != nil {
+		sess = sess.Join("INNER", "perms", "repos.id = perms.repo_id")
+		cond = cond.And(builder.Eq{"perms.user_id": user.ID, "perms.pull": true})
+	}
+
+	if len(query.RepoIDs) != 0 {
+		cond = cond.And(builder.In("pipelines.repo_id", query.RepoIDs))
+	}
+	if len(query.Events) != 0 {
+		cond = cond.And(builder.In("pipelines.event", query.Events))
+	}
+	if len(query.Statuses) != 0 {
+		cond = cond.And(builder.In("pipelines.status", query.Statuses))
+	}
+	if len(query.Authors) != 0 {
+		cond = cond.And(builder.In("pipelines.author", query.Authors))
+	}
+	if query.After != 0 {
+		cond = cond.And(builder.Gt{"pipelines.created": query.After})
+	}
+	if query.Before != 0 {
+		cond = cond.And(builder.Lt{"pipelines.created": query.Before})
+	}
+
+	const duration = "(pipelines.finished - pipelines.started)"
+	if query.MinDuration != 0 || query.MaxDuration != 0 || query.SortBy == model.PipelineSortDuration {
+		// only finished pipelines have a duration
+		cond = cond.And(builder.Gt{"pipelines.started": 0}, builder.Gt{"pipelines.finished": 0})
+	}
+	if query.MinDuration != 0 {
+		cond = cond.And(builder.Expr(duration+" >= ?", query.MinDuration))
+	}
+	if query.MaxDuration != 0 {
+		cond = cond.And(builder.Expr(duration+" <= ?", query.MaxDuration))
+	}
+
+	order := "pipelines.created"
+	switch query.SortBy {
+	case model.PipelineSortStarted:
+		order = "pipelines.started"
+	case model.PipelineSortFinished:
+		order = "pipelines.finished"
+	case model.PipelineSortDuration:
+		order = duration
+	}
+	direction := " DESC"
+	if query.Ascending {
+		direction = " ASC"
+	}
+
+	err := sess.Where(cond).
+		OrderBy(order + direction + ", pipelines.id" + direction).
+		Find(&feed)
+	return feed, err
+}
+
 func (s storage) UserFeed(user *model.User) ([]*model.Feed, error) {
 	feed := make([]*model.Feed, 0, perPage)
 	err := s.engine.Table("repos").
@@ -202,3 +202,74 @@ func TestRepoListLatest(t *testing.T) {
 	assert.EqualValues(t, model.StatusKilled, pipelines[1].Status)
 	assert.Equal(t, repo2.ID, pipelines[1].RepoID)
 }
+
+func TestQueryPipelines(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Repo), new(model.User), new(model.Perm), new(model.Pipeline), new(model.Org))
+	defer closer()
+
+	user := &model.User{Login: "joe", Email: "foo@bar.com"}
+	assert.NoError(t, store.CreateUser(user))
+
+	repo1 := &model.Repo{Owner: "bradrydzewski", Name: "test", FullName: "bradrydzewski/test", ForgeRemoteID: "1", IsActive: true}
+	repo2 := &model.Repo{Owner: "test", Name: "test", FullName: "test/test", ForgeRemoteID: "2", IsActive: true}
+	inactive := &model.Repo{Owner: "test", Name: "inactive", FullName: "test/inactive", ForgeRemoteID: "3"}
+	assert.NoError(t, store.CreateRepo(repo1))
+	assert.NoError(t, store.CreateRepo(repo2))
+	assert.NoError(t, store.CreateRepo(inactive))
+	assert.NoError(t, store.PermUpsert(&model.Perm{UserID: user.ID, Repo: repo1, Pull: true}))
+
+	pipelines := []*model.Pipeline{
+		{RepoID: repo1.ID, Event: model.EventPush, Status: model.StatusSuccess, Author: "joe", Created: 100, Started: 110, Finished: 170},
+		{RepoID: repo1.ID, Event: model.EventPull, Status: model.StatusFailure, Author: "jane", Created: 200, Started: 210, Finished: 230},
+		{RepoID: repo1.ID, Event: model.EventPush, Status: model.StatusRunning, Author: "jane", Created: 300, Started: 310},
+		{RepoID: repo2.ID, Event: model.EventTag, Status: model.StatusSuccess, Author: "joe", Created: 400, Started: 410, Finished: 500},
+		// the pipelines of inactive repos are never returned
+		{RepoID: inactive.ID, Event: model.EventPush, Status: model.StatusSuccess, Author: "joe", Created: 500, Started: 510, Finished: 520},
+	}
+	for _, pipeline := range pipelines {
+		created := pipeline.Created
+		assert.NoError(t, store.CreatePipeline(pipeline))
+		// the creation time is set automatically on insert
+		pipeline.Created = created
+		_, err := store.engine.Exec("UPDATE pipelines SET created = ? WHERE id = ?", created, pipeline.ID)
+		assert.NoError(t, err)
+	}
+
+	ids := func(feed []*model.Feed) (ids []int64) {
+		for _, item := range feed {
+			ids = append(ids, item.ID)
+		}
+		return ids
+	}
+	p1, p2, p3, p4 := pipelines[0].ID, pipelines[1].ID, pipelines[2].ID, pipelines[3].ID
+
+	tests := []struct {
+		name  string
+		user  *model.User
+		query model.PipelineQuery
+		want  []int64
+	}{
+		{name: "all", query: model.PipelineQuery{}, want: []int64{p4, p3, p2, p1}},
+		{name: "user permissions", user: user, query: model.PipelineQuery{}, want: []int64{p3, p2, p1}},
+		{name: "repo", query: model.PipelineQuery{RepoIDs: []int64{repo2.ID}}, want: []int64{p4}},
+		{name: "event", query: model.PipelineQuery{Events: []model.WebhookEvent{model.EventPush, model.EventTag}}, want: []int64{p4, p3, p1}},
+		{name: "status", query: model.PipelineQuery{Statuses: []model.StatusValue{model.StatusFailure}}, want: []int64{p2}},
+		{name: "author", query: model.PipelineQuery{Authors: []string{"jane"}}, want: []int64{p3, p2}},
+		{name: "time range", query: model.PipelineQuery{After: 100, Before: 400}, want: []int64{p3, p2}},
+		{name: "min duration", query: model.PipelineQuery{MinDuration: 60}, want: []int64{p4, p1}},
+		{name: "max duration", query: model.PipelineQuery{MaxDuration: 60}, want: []int64{p2, p1}},
+		{name: "sort by duration", query: model.PipelineQuery{SortBy: model.PipelineSortDuration}, want: []int64{p4, p1, p2}},
+		{name: "ascending", query: model.PipelineQuery{Ascending: true, SortBy: model.PipelineSortStarted}, want: []int64{p1, p2, p3, p4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := store.QueryPipelines(tt.user, &tt.query, &model.ListOptions{All: true})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, ids(feed))
+		})
+	}
+
+	feed, err := store.QueryPipelines(nil, &model.PipelineQuery{}, &model.ListOptions{Page: 2, PerPage: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{p1}, ids(feed))
+}
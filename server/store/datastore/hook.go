@@ -0,0 +1,76 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	prometheus_auto "github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+	"xorm.io/xorm/contexts"
+)
+
+var queryDuration = prometheus_auto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "woodpecker",
+	Subsystem: "database",
+	Name:      "query_duration_seconds",
+	Help:      "Duration of the database queries, by sql operation.",
+	Buckets:   prometheus.DefBuckets,
+}, []string{"operation"})
+
+// queryHook records the duration of all queries and logs the queries
+// which took longer than the slow query threshold.
+type queryHook struct {
+	logger        zerolog.Logger
+	slowThreshold time.Duration
+}
+
+func newQueryHook(slowThreshold time.Duration) *queryHook {
+	return &queryHook{
+		logger:        log.With().Str("component", "xorm").Logger(),
+		slowThreshold: slowThreshold,
+	}
+}
+
+// BeforeProcess implement contexts.Hook.
+func (h *queryHook) BeforeProcess(c *contexts.ContextHook) (context.Context, error) {
+	return c.Ctx, nil
+}
+
+// AfterProcess implement contexts.Hook.
+func (h *queryHook) AfterProcess(c *contexts.ContextHook) error {
+	queryDuration.WithLabelValues(queryOperation(c.SQL)).Observe(c.ExecuteTime.Seconds())
+
+	if h.slowThreshold > 0 && c.ExecuteTime >= h.slowThreshold {
+		// the arguments are not logged, they can contain secrets
+		h.logger.Warn().Err(c.Err).Str("sql", c.SQL).Dur("elapsed", c.ExecuteTime).Msg("slow query")
+	}
+	return nil
+}
+
+// queryOperation returns the lower case sql command of the query, e.g. select.
+func queryOperation(sql string) string {
+	operation, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	switch operation = strings.ToLower(operation); operation {
+	case "select", "insert", "update", "delete":
+		return operation
+	default:
+		return "other"
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"xorm.io/xorm/contexts"
+)
+
+func queryCount(t *testing.T, operation string) uint64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "woodpecker_database_query_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "operation" && label.GetValue() == operation {
+					return metric.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func runQuery(t *testing.T, hook *queryHook, sql string, elapsed time.Duration) {
+	c := contexts.NewContextHook(context.Background(), sql, []any{"secret-value"})
+	_, err := hook.BeforeProcess(c)
+	require.NoError(t, err)
+	c.ExecuteTime = elapsed
+	require.NoError(t, hook.AfterProcess(c))
+}
+
+func TestQueryHook(t *testing.T) {
+	var out bytes.Buffer
+	hook := newQueryHook(100 * time.Millisecond)
+	hook.logger = zerolog.New(&out)
+
+	selects, updates := queryCount(t, "select"), queryCount(t, "update")
+
+	runQuery(t, hook, "SELECT * FROM `repos` WHERE `id`=?", time.Millisecond)
+	assert.Empty(t, out.String(), "fast queries are not logged")
+
+	runQuery(t, hook, "UPDATE `repos` SET `name`=? WHERE `id`=?", 2*time.Second)
+	assert.Contains(t, out.String(), `"sql":"UPDATE `+"`repos`"+` SET `+"`name`"+`=? WHERE `+"`id`"+`=?"`)
+	assert.Contains(t, out.String(), `"elapsed":2000`)
+	assert.Contains(t, out.String(), `"message":"slow query"`)
+	assert.NotContains(t, out.String(), "secret-value")
+
+	assert.Equal(t, selects+1, queryCount(t, "select"))
+	assert.Equal(t, updates+1, queryCount(t, "update"))
+
+	t.Run("disabled", func(t *testing.T) {
+		var out bytes.Buffer
+		hook := newQueryHook(0)
+		hook.logger = zerolog.New(&out)
+
+		runQuery(t, hook, "DELETE FROM `repos`", time.Hour)
+		assert.Empty(t, out.String())
+	})
+}
+
+func TestQueryOperation(t *testing.T) {
+	assert.Equal(t, "select", queryOperation("SELECT 1"))
+	assert.Equal(t, "insert", queryOperation("  insert INTO `repos` VALUES (?)"))
+	assert.Equal(t, "other", queryOperation("CREATE TABLE `repos` (`id` INTEGER)"))
+	assert.Equal(t, "other", queryOperation(""))
+}
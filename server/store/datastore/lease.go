@@ -0,0 +1,55 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"xorm.io/builder"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+// LeaseAcquire tries to take or renew the lease. It succeeds if the lease is free, expired at now or
+// already held by the same holder.
+func (s storage) LeaseAcquire(lease *model.Lease, now int64) (bool, error) {
+	cols, err := s.engine.ID(lease.Name).
+		Where(builder.Or(builder.Eq{"holder": lease.Holder}, builder.Lt{"expires": now})).
+		Cols("holder", "expires").Update(lease)
+	if err != nil {
+		return false, err
+	}
+	if cols != 0 {
+		return true, nil
+	}
+
+	exist, err := s.engine.Exist(&model.Lease{Name: lease.Name})
+	if err != nil || exist {
+		return false, err
+	}
+
+	if _, err := s.engine.Insert(lease); err != nil {
+		// another instance created the lease in the meantime
+		if exist, _ := s.engine.Exist(&model.Lease{Name: lease.Name}); exist {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// LeaseRelease gives up the lease if it is held by the holder of the given lease.
+func (s storage) LeaseRelease(lease *model.Lease) error {
+	_, err := s.engine.ID(lease.Name).Where(builder.Eq{"holder": lease.Holder}).Delete(new(model.Lease))
+	return err
+}
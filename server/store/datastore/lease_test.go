@@ -0,0 +1,73 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+)
+
+func TestLeaseAcquire(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Lease))
+	defer closer()
+
+	instance1 := &model.Lease{Name: "cron", Holder: "instance-1", Expires: 200}
+	instance2 := &model.Lease{Name: "cron", Holder: "instance-2", Expires: 200}
+
+	// first instance gets the free lease
+	ok, err := store.LeaseAcquire(instance1, 100)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// second instance has to wait while the lease is valid
+	ok, err = store.LeaseAcquire(instance2, 100)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// holder can renew its lease
+	instance1.Expires = 300
+	ok, err = store.LeaseAcquire(instance1, 150)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = store.LeaseAcquire(instance2, 250)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// second instance takes over once the lease of the first one expired
+	instance2.Expires = 400
+	ok, err = store.LeaseAcquire(instance2, 301)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = store.LeaseAcquire(instance1, 302)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// releasing a lease held by someone else has no effect
+	assert.NoError(t, store.LeaseRelease(instance1))
+	ok, err = store.LeaseAcquire(instance1, 303)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// after release the lease is free again
+	assert.NoError(t, store.LeaseRelease(instance2))
+	ok, err = store.LeaseAcquire(instance1, 304)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
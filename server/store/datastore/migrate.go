@@ -0,0 +1,108 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/tink/go/subtle/random"
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+const (
+	migrationLeaseName = "migrations"
+	// the lease is renewed while migrating, so it only expires if the migrating instance died.
+	migrationLeaseTTL      = time.Minute
+	migrationLeaseRenew    = migrationLeaseTTL / 3
+	migrationLockRetryTime = time.Second
+)
+
+// lockMigrations waits until this instance holds the migration lease, or fails with types.MigrationLocked
+// once the timeout is reached. A timeout of zero waits until the context is done.
+// The returned function releases the lease.
+func (s storage) lockMigrations(ctx context.Context, timeout time.Duration) (func(), error) {
+	// the leases table has to exist before the first migration
+	if err := s.engine.Sync(new(model.Lease)); err != nil {
+		return nil, fmt.Errorf("could not create leases table: %w", err)
+	}
+
+	hostname, _ := os.Hostname()
+	lease := &model.Lease{Name: migrationLeaseName, Holder: fmt.Sprintf("%s-%x", hostname, random.GetRandomBytes(4))} //nolint:mnd
+
+	acquire := func() (bool, error) {
+		now := time.Now()
+		lease.Expires = now.Add(migrationLeaseTTL).Unix()
+		return s.LeaseAcquire(lease, now.Unix())
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for waiting := false; ; waiting = true {
+		ok, err := acquire()
+		if err != nil {
+			return nil, fmt.Errorf("could not acquire migration lock: %w", err)
+		}
+		if ok {
+			break
+		}
+		if !waiting {
+			log.Info().Msg("migration lock is held by another instance, waiting for it to finish migrating")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-deadline:
+			return nil, types.MigrationLocked
+		case <-time.After(migrationLockRetryTime):
+		}
+	}
+	log.Debug().Msg("acquired migration lock")
+
+	done := make(chan struct{})
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(migrationLeaseRenew):
+				if ok, err := acquire(); err != nil || !ok {
+					log.Error().Err(err).Msg("could not renew migration lock")
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-renewed
+		if err := s.LeaseRelease(lease); err != nil {
+			log.Error().Err(err).Msg("could not release migration lock")
+		}
+	}, nil
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+func TestMigrateLock(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "woodpecker.sqlite")
+	open := func() *storage {
+		s, err := NewEngine(&store.Opts{Driver: "sqlite3", Config: config})
+		require.NoError(t, err)
+		t.Cleanup(func() { assert.NoError(t, s.Close()) })
+		return s.(*storage)
+	}
+	first, second := open(), open()
+
+	unlock, err := first.lockMigrations(t.Context(), 0)
+	require.NoError(t, err)
+
+	// the second engine gives up while the first one holds the lock
+	start := time.Now()
+	assert.ErrorIs(t, second.Migrate(t.Context(), true, 50*time.Millisecond), types.MigrationLocked)
+	assert.Less(t, time.Since(start), migrationLockRetryTime)
+
+	unlock()
+	require.NoError(t, second.Migrate(t.Context(), true, time.Second))
+
+	// the lock is released after migrating
+	unlock, err = first.lockMigrations(t.Context(), 50*time.Millisecond)
+	require.NoError(t, err)
+	unlock()
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/builder"
+	"xorm.io/xorm"
+)
+
+// hashAgentToken hashes a token like the token hash service does with its default algorithm,
+// which is inlined so later changes of the service do not alter this migration.
+func hashAgentToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func isHashedAgentToken(token string) bool {
+	for _, algorithm := range []string{"sha256:", "sha512:", "sha3-256:"} {
+		if strings.HasPrefix(token, algorithm) {
+			return true
+		}
+	}
+	return false
+}
+
+var hashAgentTokens = xormigrate.Migration{
+	ID: "hash-agent-tokens",
+	MigrateSession: func(sess *xorm.Session) (err error) {
+		type agents struct {
+			ID    int64  `xorm:"pk autoincr 'id'"`
+			Token string `xorm:"token"`
+		}
+
+		type serverConfigs struct {
+			Key   string `xorm:"pk 'key'"`
+			Value string `xorm:"value"`
+		}
+
+		if err := sess.Sync(new(agents), new(serverConfigs)); err != nil {
+			return fmt.Errorf("sync models failed: %w", err)
+		}
+
+		var allAgents []*agents
+		if err := sess.Find(&allAgents); err != nil {
+			return fmt.Errorf("find all agents failed: %w", err)
+		}
+
+		for _, agent := range allAgents {
+			if agent.Token == "" || isHashedAgentToken(agent.Token) {
+				continue
+			}
+			agent.Token = hashAgentToken(agent.Token)
+			if _, err := sess.Where(builder.Eq{"id": agent.ID}).Cols("token").Update(agent); err != nil {
+				return fmt.Errorf("updating token of agent %d failed: %w", agent.ID, err)
+			}
+		}
+
+		// shared agent tokens are stored as json list in the server config
+		config := &serverConfigs{}
+		has, err := sess.ID("agent-tokens").Get(config)
+		if err != nil {
+			return fmt.Errorf("getting agent tokens failed: %w", err)
+		}
+		// encrypted configs can't be read here, their tokens are hashed once they are used
+		if !has || strings.HasPrefix(config.Value, "encrypted:v1:") {
+			return nil
+		}
+
+		var tokens []map[string]any
+		if err := json.Unmarshal([]byte(config.Value), &tokens); err != nil {
+			return fmt.Errorf("parsing agent tokens failed: %w", err)
+		}
+		for _, token := range tokens {
+			plain, ok := token["token"].(string)
+			if !ok {
+				return errors.New("agent token without token found")
+			}
+			if isHashedAgentToken(plain) {
+				continue
+			}
+			token["token"] = hashAgentToken(plain)
+		}
+
+		data, err := json.Marshal(tokens)
+		if err != nil {
+			return err
+		}
+		config.Value = string(data)
+		_, err = sess.ID("agent-tokens").Cols("value").Update(config)
+		return err
+	},
+}
@@ -0,0 +1,102 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"fmt"
+
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+// Status returns all known migrations in execution order and whether they were applied.
+func Status(e *xorm.Engine) ([]*model.MigrationStatus, error) {
+	return status(e, migrationTasks)
+}
+
+// Rollback reverts the migration with the given id. It has to be the last applied one
+// and needs to provide a rollback function.
+func Rollback(e *xorm.Engine, id string) error {
+	return rollback(e, migrationTasks, id)
+}
+
+func status(e *xorm.Engine, tasks []*xormigrate.Migration) ([]*model.MigrationStatus, error) {
+	applied, err := appliedMigrations(e)
+	if err != nil {
+		return nil, err
+	}
+
+	list := make([]*model.MigrationStatus, 0, len(tasks))
+	for _, task := range tasks {
+		list = append(list, &model.MigrationStatus{
+			ID:          task.ID,
+			Description: task.Description,
+			Applied:     applied[task.ID],
+			Reversible:  task.Rollback != nil || task.RollbackSession != nil,
+		})
+	}
+	return list, nil
+}
+
+func rollback(e *xorm.Engine, tasks []*xormigrate.Migration, id string) error {
+	applied, err := appliedMigrations(e)
+	if err != nil {
+		return err
+	}
+
+	var last *xormigrate.Migration
+	for _, task := range tasks {
+		if applied[task.ID] {
+			last = task
+		}
+	}
+
+	switch {
+	case !applied[id] || last == nil:
+		return fmt.Errorf("migration %s not applied: %w", id, types.RecordNotExist)
+	case last.ID != id:
+		return fmt.Errorf("%w: last applied migration is %s", types.MigrationNotLast, last.ID)
+	case last.Rollback == nil && last.RollbackSession == nil:
+		return fmt.Errorf("%w: %s", types.MigrationNotReversible, id)
+	}
+
+	m := xormigrate.New(e, tasks)
+	m.SetLogger(&xormigrateLogger{})
+	return m.RollbackMigration(last)
+}
+
+func appliedMigrations(e *xorm.Engine) (map[string]bool, error) {
+	exist, err := e.IsTableExist(new(xormigrate.Migration))
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[string]bool)
+	if !exist {
+		return applied, nil
+	}
+
+	var rows []*xormigrate.Migration
+	if err := e.Find(&rows); err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		applied[row.ID] = true
+	}
+	return applied, nil
+}
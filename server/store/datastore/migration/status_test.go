@@ -0,0 +1,106 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"src.techknowlogick.com/xormigrate"
+	"xorm.io/xorm"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store/types"
+)
+
+type statusTestBean struct {
+	ID int64 `xorm:"pk autoincr 'id'"`
+}
+
+func TestStatus(t *testing.T) {
+	engine, closeDB := testDB(t, true)
+	defer closeDB()
+
+	list, err := Status(engine)
+	require.NoError(t, err)
+	require.Len(t, list, len(migrationTasks))
+	for _, m := range list {
+		assert.False(t, m.Applied, m.ID)
+	}
+
+	require.NoError(t, Migrate(t.Context(), engine, true))
+
+	list, err = Status(engine)
+	require.NoError(t, err)
+	require.Len(t, list, len(migrationTasks))
+	for i, m := range list {
+		assert.Equal(t, migrationTasks[i].ID, m.ID)
+		assert.True(t, m.Applied, m.ID)
+	}
+}
+
+func TestRollback(t *testing.T) {
+	engine, closeDB := testDB(t, true)
+	defer closeDB()
+
+	tasks := []*xormigrate.Migration{
+		{
+			ID: "irreversible",
+			Migrate: func(*xorm.Engine) error {
+				return nil
+			},
+		},
+		{
+			ID: "create-bean",
+			Migrate: func(e *xorm.Engine) error {
+				return e.Sync(new(statusTestBean))
+			},
+			Rollback: func(e *xorm.Engine) error {
+				return e.DropTables(new(statusTestBean))
+			},
+		},
+		{
+			ID: "pending",
+			Migrate: func(*xorm.Engine) error {
+				return nil
+			},
+		},
+	}
+	require.NoError(t, xormigrate.New(engine, tasks[:2]).Migrate())
+
+	list, err := status(engine, tasks)
+	require.NoError(t, err)
+	require.Len(t, list, 3)
+	assert.True(t, list[0].Applied)
+	assert.False(t, list[0].Reversible)
+	assert.True(t, list[1].Applied)
+	assert.True(t, list[1].Reversible)
+	assert.False(t, list[2].Applied)
+
+	assert.ErrorIs(t, rollback(engine, tasks, "pending"), types.RecordNotExist)
+	assert.ErrorIs(t, rollback(engine, tasks, "irreversible"), types.MigrationNotLast)
+
+	require.NoError(t, rollback(engine, tasks, "create-bean"))
+	exist, err := engine.IsTableExist(new(statusTestBean))
+	require.NoError(t, err)
+	assert.False(t, exist)
+
+	list, err = status(engine, tasks)
+	require.NoError(t, err)
+	assert.True(t, list[0].Applied)
+	assert.False(t, list[1].Applied)
+
+	assert.ErrorIs(t, rollback(engine, tasks, "irreversible"), types.MigrationNotReversible)
+}
@@ -61,6 +61,19 @@ func (s storage) GetPipelineLastBefore(repo *model.Repo, branch string, num int6
 		Get(pipeline))
 }
 
+func (s storage) GetPipelineByCacheKey(repo *model.Repo, cacheKey string) (*model.Pipeline, error) {
+	pipeline := new(model.Pipeline)
+	return pipeline, wrapGet(s.engine.
+		Desc("number").
+		Where(builder.Eq{
+			"repo_id":   repo.ID,
+			"cache_key": cacheKey,
+			"cache_hit": 0,
+			"status":    model.StatusSuccess,
+		}).
+		Get(pipeline))
+}
+
 func (s storage) GetPipelineList(repo *model.Repo, p *model.ListOptions, f *model.PipelineFilter) ([]*model.Pipeline, error) {
 	pipelines := make([]*model.Pipeline, 0, 16)
 
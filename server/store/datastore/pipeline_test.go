@@ -224,6 +224,26 @@ func TestPipelineIncrement(t *testing.T) {
 	assert.EqualValues(t, 1, pipelineC.Number)
 }
 
+func TestPipelineByCacheKey(t *testing.T) {
+	store, closer := newTestStore(t, new(model.Pipeline))
+	defer closer()
+
+	_, err := store.engine.Insert(
+		&model.Pipeline{ID: 1, Number: 1, RepoID: 1, Status: model.StatusSuccess, CacheKey: "abc"},
+		&model.Pipeline{ID: 2, Number: 2, RepoID: 1, Status: model.StatusFailure, CacheKey: "abc"},
+		&model.Pipeline{ID: 3, Number: 3, RepoID: 1, Status: model.StatusSuccess, CacheKey: "abc", CacheHit: 1},
+		&model.Pipeline{ID: 4, Number: 1, RepoID: 2, Status: model.StatusSuccess, CacheKey: "abc"},
+	)
+	assert.NoError(t, err)
+
+	pipeline, err := store.GetPipelineByCacheKey(&model.Repo{ID: 1}, "abc")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, pipeline.ID)
+
+	_, err = store.GetPipelineByCacheKey(&model.Repo{ID: 1}, "def")
+	assert.ErrorIs(t, err, types.RecordNotExist)
+}
+
 func TestDeletePipeline(t *testing.T) {
 	store, closer := newTestStore(t, new(model.Pipeline), new(model.Repo), new(model.Workflow),
 		new(model.Step), new(model.LogEntry), new(model.PipelineConfig), new(model.Config))
@@ -0,0 +1,100 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"database/sql"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+// poolSettings reads the pool settings back from the database handle, as sql.DB has no getters for most of them.
+func poolSettings(db *sql.DB) store.Pool {
+	v := reflect.ValueOf(db).Elem()
+	return store.Pool{
+		MaxOpenConns:    db.Stats().MaxOpenConnections,
+		MaxIdleConns:    int(v.FieldByName("maxIdleCount").Int()),
+		ConnMaxLifetime: time.Duration(v.FieldByName("maxLifetime").Int()),
+		ConnMaxIdleTime: time.Duration(v.FieldByName("maxIdleTime").Int()),
+	}
+}
+
+func TestPoolSettings(t *testing.T) {
+	primaryPool := store.Pool{MaxOpenConns: 20, MaxIdleConns: 5, ConnMaxLifetime: time.Hour, ConnMaxIdleTime: time.Minute}
+	replicaPool := store.Pool{MaxOpenConns: 50, MaxIdleConns: 10, ConnMaxLifetime: 2 * time.Hour, ConnMaxIdleTime: 5 * time.Minute}
+
+	t.Run("primary and replica", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := NewEngine(&store.Opts{
+			Driver:        "sqlite3",
+			Config:        filepath.Join(dir, "primary.sqlite"),
+			ReplicaConfig: filepath.Join(dir, "replica.sqlite"),
+			ReplicaPool:   &replicaPool,
+			XORM:          store.XORM{Pool: primaryPool},
+		})
+		require.NoError(t, err)
+		defer s.Close()
+
+		r, ok := s.(*replicated)
+		require.True(t, ok)
+		assert.Equal(t, primaryPool, poolSettings(r.Store.(*storage).engine.DB().DB))
+		assert.Equal(t, replicaPool, poolSettings(r.replica.engine.DB().DB))
+	})
+
+	t.Run("replica uses primary settings by default", func(t *testing.T) {
+		dir := t.TempDir()
+		s, err := NewEngine(&store.Opts{
+			Driver:        "sqlite3",
+			Config:        filepath.Join(dir, "primary.sqlite"),
+			ReplicaConfig: filepath.Join(dir, "replica.sqlite"),
+			XORM:          store.XORM{Pool: primaryPool},
+		})
+		require.NoError(t, err)
+		defer s.Close()
+
+		assert.Equal(t, primaryPool, poolSettings(s.(*replicated).replica.engine.DB().DB))
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewEngine(&store.Opts{
+			Driver: "sqlite3",
+			Config: filepath.Join(t.TempDir(), "primary.sqlite"),
+			XORM:   store.XORM{Pool: store.Pool{MaxOpenConns: 2, MaxIdleConns: 5}},
+		})
+		assert.EqualError(t, err, "max idle connections (5) must not exceed max open connections (2)")
+
+		_, err = NewEngine(&store.Opts{
+			Driver:        "sqlite3",
+			Config:        filepath.Join(t.TempDir(), "primary.sqlite"),
+			ReplicaConfig: filepath.Join(t.TempDir(), "replica.sqlite"),
+			ReplicaPool:   &store.Pool{MaxOpenConns: 1, MaxIdleConns: 2},
+		})
+		assert.EqualError(t, err, "replica: max idle connections (2) must not exceed max open connections (1)")
+	})
+}
+
+func TestPoolValidate(t *testing.T) {
+	assert.NoError(t, store.Pool{MaxOpenConns: 10, MaxIdleConns: 10}.Validate())
+	assert.NoError(t, store.Pool{MaxOpenConns: 0, MaxIdleConns: 10}.Validate(), "zero max open connections means unlimited")
+	assert.Error(t, store.Pool{MaxOpenConns: 2, MaxIdleConns: 3}.Validate())
+	assert.Error(t, store.Pool{ConnMaxIdleTime: -time.Second}.Validate())
+}
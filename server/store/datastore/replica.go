@@ -0,0 +1,95 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"errors"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+// replicated sends all writes and most reads to the primary database,
+// only the list and count queries used by the UI are served by the read replica.
+// Each store method runs its transaction on a single database, so reads within
+// a transaction always use the primary.
+type replicated struct {
+	store.Store
+	replica *storage
+}
+
+func (r *replicated) Ping() error {
+	return errors.Join(r.Store.Ping(), r.replica.Ping())
+}
+
+func (r *replicated) Close() error {
+	return errors.Join(r.Store.Close(), r.replica.Close())
+}
+
+func (r *replicated) GetUserList(p *model.ListOptions) ([]*model.User, error) {
+	return r.replica.GetUserList(p)
+}
+
+func (r *replicated) GetUserCount() (int64, error) {
+	return r.replica.GetUserCount()
+}
+
+func (r *replicated) GetRepoCount() (int64, error) {
+	return r.replica.GetRepoCount()
+}
+
+func (r *replicated) GetPipelineList(repo *model.Repo, p *model.ListOptions, f *model.PipelineFilter) ([]*model.Pipeline, error) {
+	return r.replica.GetPipelineList(repo, p, f)
+}
+
+func (r *replicated) GetPipelineQueue() ([]*model.Feed, error) {
+	return r.replica.GetPipelineQueue()
+}
+
+func (r *replicated) QueryPipelines(user *model.User, query *model.PipelineQuery, p *model.ListOptions) ([]*model.Feed, error) {
+	return r.replica.QueryPipelines(user, query, p)
+}
+
+func (r *replicated) GetPipelineCount() (int64, error) {
+	return r.replica.GetPipelineCount()
+}
+
+func (r *replicated) UserFeed(user *model.User) ([]*model.Feed, error) {
+	return r.replica.UserFeed(user)
+}
+
+func (r *replicated) RepoList(user *model.User, owned, active bool, filter *model.RepoFilter) ([]*model.Repo, error) {
+	return r.replica.RepoList(user, owned, active, filter)
+}
+
+func (r *replicated) RepoListLatest(user *model.User) ([]*model.Feed, error) {
+	return r.replica.RepoListLatest(user)
+}
+
+func (r *replicated) RepoListAll(active bool, p *model.ListOptions) ([]*model.Repo, error) {
+	return r.replica.RepoListAll(active, p)
+}
+
+func (r *replicated) AgentList(p *model.ListOptions) ([]*model.Agent, error) {
+	return r.replica.AgentList(p)
+}
+
+func (r *replicated) OrgList(p *model.ListOptions) ([]*model.Org, error) {
+	return r.replica.OrgList(p)
+}
+
+func (r *replicated) OrgRepoList(org *model.Org, p *model.ListOptions) ([]*model.Repo, error) {
+	return r.replica.OrgRepoList(org, p)
+}
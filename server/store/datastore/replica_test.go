@@ -0,0 +1,63 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/model"
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+func TestReplicaRouting(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewEngine(&store.Opts{
+		Driver:        "sqlite3",
+		Config:        filepath.Join(dir, "primary.sqlite"),
+		ReplicaConfig: filepath.Join(dir, "replica.sqlite"),
+	})
+	require.NoError(t, err)
+	defer s.Close()
+
+	r, ok := s.(*replicated)
+	require.True(t, ok)
+	primary, ok := r.Store.(*storage)
+	require.True(t, ok)
+	require.NoError(t, primary.engine.Sync(new(model.Repo)))
+	require.NoError(t, r.replica.engine.Sync(new(model.Repo)))
+	assert.NoError(t, s.Ping())
+
+	// writes go to the primary
+	require.NoError(t, s.CreateRepo(&model.Repo{ForgeRemoteID: "1", Owner: "octocat", Name: "primary", FullName: "octocat/primary", IsActive: true}))
+	_, err = r.replica.engine.Insert(&model.Repo{ForgeRemoteID: "2", Owner: "octocat", Name: "replica", FullName: "octocat/replica", IsActive: true})
+	require.NoError(t, err)
+
+	// lists are read from the replica
+	repos, err := s.RepoListAll(true, &model.ListOptions{All: true})
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	assert.Equal(t, "octocat/replica", repos[0].FullName)
+
+	// other reads use the primary
+	repo, err := s.GetRepoName("octocat/primary")
+	require.NoError(t, err)
+	assert.Equal(t, "primary", repo.Name)
+	_, err = s.GetRepoName("octocat/replica")
+	assert.Error(t, err)
+}
@@ -63,3 +63,8 @@ func (s storage) ServerConfigDelete(key string) error {
 
 	return wrapDelete(s.engine.Delete(config))
 }
+
+func (s storage) ServerConfigList() ([]*model.ServerConfig, error) {
+	configs := make([]*model.ServerConfig, 0)
+	return configs, s.engine.OrderBy("`key`").Find(&configs)
+}
@@ -46,4 +46,9 @@ func TestServerConfigGetSet(t *testing.T) {
 	value, err = store.ServerConfigGet("config_not_exist")
 	assert.Error(t, err)
 	assert.Empty(t, value)
+
+	assert.NoError(t, store.ServerConfigSet("another", "value"))
+	configs, err := store.ServerConfigList()
+	assert.NoError(t, err)
+	assert.Equal(t, []*model.ServerConfig{{Key: "another", Value: "value"}, {Key: "test", Value: "new-wonderland"}}, configs)
 }
@@ -0,0 +1,58 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+var sqliteJournalModes = []string{"DELETE", "TRUNCATE", "PERSIST", "MEMORY", "WAL", "OFF"}
+
+// sqliteDataSource adds the pragmas to the data source, so the driver applies them
+// to every new connection of the pool. Pragmas already set in the data source are kept.
+func sqliteDataSource(config string, opts store.SQLite) (string, error) {
+	params := url.Values{}
+	if opts.JournalMode != "" {
+		mode := strings.ToUpper(opts.JournalMode)
+		if !slices.Contains(sqliteJournalModes, mode) {
+			return "", fmt.Errorf("invalid sqlite journal mode '%s'", opts.JournalMode)
+		}
+		params.Set("_journal_mode", mode)
+	}
+	if opts.BusyTimeout > 0 {
+		params.Set("_busy_timeout", strconv.FormatInt(opts.BusyTimeout.Milliseconds(), 10))
+	}
+
+	path, query, _ := strings.Cut(config, "?")
+	existing, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid sqlite data source: %w", err)
+	}
+	for key, values := range params {
+		if !existing.Has(key) {
+			existing[key] = values
+		}
+	}
+	if len(existing) == 0 {
+		return path, nil
+	}
+	return path + "?" + existing.Encode(), nil
+}
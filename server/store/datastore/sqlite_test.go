@@ -0,0 +1,67 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+func TestSqlitePragmas(t *testing.T) {
+	s, err := NewEngine(&store.Opts{
+		Driver: "sqlite3",
+		Config: filepath.Join(t.TempDir(), "woodpecker.sqlite"),
+		XORM:   store.XORM{Pool: store.Pool{MaxOpenConns: 2}},
+		SQLite: store.SQLite{JournalMode: "wal", BusyTimeout: 3 * time.Second},
+	})
+	require.NoError(t, err)
+	defer s.Close()
+	engine := s.(*storage).engine
+
+	var journalMode string
+	_, err = engine.SQL("PRAGMA journal_mode").Get(&journalMode)
+	require.NoError(t, err)
+	assert.Equal(t, "wal", journalMode)
+
+	var busyTimeout int
+	_, err = engine.SQL("PRAGMA busy_timeout").Get(&busyTimeout)
+	require.NoError(t, err)
+	assert.Equal(t, 3000, busyTimeout)
+}
+
+func TestSqliteDataSource(t *testing.T) {
+	opts := store.SQLite{JournalMode: "WAL", BusyTimeout: time.Second}
+
+	config, err := sqliteDataSource("woodpecker.sqlite", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "woodpecker.sqlite?_busy_timeout=1000&_journal_mode=WAL", config)
+
+	config, err = sqliteDataSource("woodpecker.sqlite?_journal_mode=DELETE&cache=shared", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, "woodpecker.sqlite?_busy_timeout=1000&_journal_mode=DELETE&cache=shared", config)
+
+	config, err = sqliteDataSource("woodpecker.sqlite", store.SQLite{})
+	assert.NoError(t, err)
+	assert.Equal(t, "woodpecker.sqlite", config)
+
+	_, err = sqliteDataSource("woodpecker.sqlite", store.SQLite{JournalMode: "fast"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+// mysqlTLSConfigName is the name the tls config is registered with at the mysql driver.
+const mysqlTLSConfigName = "woodpecker"
+
+// tlsDataSource adds the tls options to the data source. Parameters already set in the data source are kept.
+func tlsDataSource(driver, config string, opts store.TLS) (string, error) {
+	if !opts.Enabled() {
+		return config, nil
+	}
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	switch driver {
+	case DriverPostgres:
+		return postgresTLSDataSource(config, opts)
+	case DriverMysql:
+		return mysqlTLSDataSource(config, opts)
+	default:
+		return "", fmt.Errorf("tls options are not supported by the %s driver", driver)
+	}
+}
+
+func postgresTLSDataSource(config string, opts store.TLS) (string, error) {
+	mode := opts.Mode
+	if mode == "" {
+		mode = "verify-full"
+	}
+	params := [][2]string{
+		{"sslmode", mode},
+		{"sslrootcert", opts.CA},
+		{"sslcert", opts.Cert},
+		{"sslkey", opts.Key},
+	}
+
+	// the data source is either an url or a list of key=value pairs
+	if strings.HasPrefix(config, "postgres://") || strings.HasPrefix(config, "postgresql://") {
+		base, query, _ := strings.Cut(config, "?")
+		existing, err := url.ParseQuery(query)
+		if err != nil {
+			return "", fmt.Errorf("invalid postgres data source: %w", err)
+		}
+		for _, param := range params {
+			if param[1] != "" && !existing.Has(param[0]) {
+				existing.Set(param[0], param[1])
+			}
+		}
+		return base + "?" + existing.Encode(), nil
+	}
+
+	for _, param := range params {
+		if param[1] != "" && !strings.Contains(" "+config, " "+param[0]+"=") {
+			config += " " + param[0] + "=" + postgresQuote(param[1])
+		}
+	}
+	return strings.TrimSpace(config), nil
+}
+
+// postgresQuote quotes a value of a key=value data source if required.
+func postgresQuote(value string) string {
+	if !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+func mysqlTLSDataSource(config string, opts store.TLS) (string, error) {
+	base, query, _ := strings.Cut(config, "?")
+	existing, err := url.ParseQuery(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid mysql data source: %w", err)
+	}
+	if existing.Has("tls") {
+		return config, nil
+	}
+
+	if opts.Mode == "disable" {
+		existing.Set("tls", "false")
+	} else {
+		tlsConfig, err := mysqlTLSConfig(opts)
+		if err != nil {
+			return "", err
+		}
+		if err := mysql.RegisterTLSConfig(mysqlTLSConfigName, tlsConfig); err != nil {
+			return "", err
+		}
+		existing.Set("tls", mysqlTLSConfigName)
+	}
+	return base + "?" + existing.Encode(), nil
+}
+
+// mysqlTLSConfig returns the tls config for the mode, the server name is set by the driver.
+func mysqlTLSConfig(opts store.TLS) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if opts.CA != "" {
+		ca, err := os.ReadFile(opts.CA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", opts.CA)
+		}
+	}
+	if opts.Cert != "" {
+		cert, err := tls.LoadX509KeyPair(opts.Cert, opts.Key)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch opts.Mode {
+	case "require":
+		tlsConfig.InsecureSkipVerify = true
+	case "verify-ca":
+		// verify the certificate chain, but not the host name
+		tlsConfig.InsecureSkipVerify = true
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyCertificateChain(rawCerts, tlsConfig.RootCAs)
+		}
+	}
+	return tlsConfig, nil
+}
+
+func verifyCertificateChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server did not send a certificate")
+	}
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+	return err
+}
@@ -0,0 +1,213 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package datastore
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.woodpecker-ci.org/woodpecker/v3/server/store"
+)
+
+// writeCertificate writes a self-signed certificate and its key to dir.
+func writeCertificate(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "woodpecker"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certFile, keyFile
+}
+
+func TestTLSDataSource(t *testing.T) {
+	dir := t.TempDir()
+	cert, key := writeCertificate(t, dir)
+	caDir := filepath.Join(dir, "my certs")
+	require.NoError(t, os.Mkdir(caDir, 0o700))
+	ca, _ := writeCertificate(t, caDir)
+
+	tests := []struct {
+		name    string
+		driver  string
+		config  string
+		opts    store.TLS
+		want    string
+		wantErr string
+	}{
+		{
+			name:   "no tls options",
+			driver: DriverPostgres,
+			config: "postgres://woodpecker@db/woodpecker",
+			want:   "postgres://woodpecker@db/woodpecker",
+		},
+		{
+			name:   "postgres url",
+			driver: DriverPostgres,
+			config: "postgres://woodpecker@db/woodpecker?connect_timeout=5",
+			opts:   store.TLS{CA: cert, Cert: cert, Key: key},
+			want:   "postgres://woodpecker@db/woodpecker?connect_timeout=5&sslcert=" + url.QueryEscape(cert) + "&sslkey=" + url.QueryEscape(key) + "&sslmode=verify-full&sslrootcert=" + url.QueryEscape(cert),
+		},
+		{
+			name:   "postgres url keeps parameters",
+			driver: DriverPostgres,
+			config: "postgresql://woodpecker@db/woodpecker?sslmode=disable",
+			opts:   store.TLS{Mode: "require"},
+			want:   "postgresql://woodpecker@db/woodpecker?sslmode=disable",
+		},
+		{
+			name:    "postgres invalid url",
+			driver:  DriverPostgres,
+			config:  "postgres://woodpecker@db/woodpecker?password=100%",
+			opts:    store.TLS{Mode: "require"},
+			wantErr: `invalid postgres data source: invalid URL escape "%"`,
+		},
+		{
+			name:   "postgres key value pairs",
+			driver: DriverPostgres,
+			config: "host=db user=woodpecker dbname=woodpecker",
+			opts:   store.TLS{Mode: "verify-ca", CA: ca},
+			want:   "host=db user=woodpecker dbname=woodpecker sslmode=verify-ca sslrootcert='" + ca + "'",
+		},
+		{
+			name:   "postgres key value pairs keep parameters",
+			driver: DriverPostgres,
+			config: "host=db sslmode=disable",
+			opts:   store.TLS{Mode: "require"},
+			want:   "host=db sslmode=disable",
+		},
+		{
+			name:   "mysql",
+			driver: DriverMysql,
+			config: "root:password@tcp(db:3306)/woodpecker?parseTime=true",
+			opts:   store.TLS{Mode: "verify-ca", CA: ca, Cert: cert, Key: key},
+			want:   "root:password@tcp(db:3306)/woodpecker?parseTime=true&tls=woodpecker",
+		},
+		{
+			name:   "mysql without parameters",
+			driver: DriverMysql,
+			config: "root:password@tcp(db:3306)/woodpecker",
+			opts:   store.TLS{Mode: "require"},
+			want:   "root:password@tcp(db:3306)/woodpecker?tls=woodpecker",
+		},
+		{
+			name:   "mysql disabled",
+			driver: DriverMysql,
+			config: "root:password@tcp(db:3306)/woodpecker?parseTime=true",
+			opts:   store.TLS{Mode: "disable"},
+			want:   "root:password@tcp(db:3306)/woodpecker?parseTime=true&tls=false",
+		},
+		{
+			name:   "mysql keeps parameters",
+			driver: DriverMysql,
+			config: "root:password@tcp(db:3306)/woodpecker?tls=skip-verify",
+			opts:   store.TLS{Mode: "verify-full"},
+			want:   "root:password@tcp(db:3306)/woodpecker?tls=skip-verify",
+		},
+		{
+			name:    "mysql invalid ca",
+			driver:  DriverMysql,
+			config:  "root:password@tcp(db:3306)/woodpecker",
+			opts:    store.TLS{CA: key},
+			wantErr: "no certificates found in " + key,
+		},
+		{
+			name:    "sqlite",
+			driver:  "sqlite3",
+			config:  "woodpecker.sqlite",
+			opts:    store.TLS{Mode: "require"},
+			wantErr: "tls options are not supported by the sqlite3 driver",
+		},
+		{
+			name:    "invalid mode",
+			driver:  DriverPostgres,
+			config:  "host=db",
+			opts:    store.TLS{Mode: "prefer"},
+			wantErr: "tls mode 'prefer' is not supported",
+		},
+		{
+			name:    "missing file",
+			driver:  DriverPostgres,
+			config:  "host=db",
+			opts:    store.TLS{CA: filepath.Join(dir, "missing.pem")},
+			wantErr: "tls file: stat " + filepath.Join(dir, "missing.pem") + ": no such file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config, err := tlsDataSource(tt.driver, tt.config, tt.opts)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, config)
+		})
+	}
+}
+
+func TestPostgresQuote(t *testing.T) {
+	assert.Equal(t, "/etc/ca.pem", postgresQuote("/etc/ca.pem"))
+	assert.Equal(t, `'/etc/my certs/it\'s.pem'`, postgresQuote("/etc/my certs/it's.pem"))
+}
+
+func TestMysqlTLSConfigVerifyCA(t *testing.T) {
+	ca, _ := writeCertificate(t, t.TempDir())
+	other, _ := writeCertificate(t, t.TempDir())
+	der := func(file string) []byte {
+		data, err := os.ReadFile(file)
+		require.NoError(t, err)
+		block, _ := pem.Decode(data)
+		return block.Bytes
+	}
+
+	tlsConfig, err := mysqlTLSConfig(store.TLS{Mode: "verify-ca", CA: ca})
+	require.NoError(t, err)
+	assert.True(t, tlsConfig.InsecureSkipVerify, "the host name is not verified")
+	assert.NoError(t, tlsConfig.VerifyPeerCertificate([][]byte{der(ca)}, nil))
+	assert.Error(t, tlsConfig.VerifyPeerCertificate([][]byte{der(other)}, nil))
+
+	tlsConfig, err = mysqlTLSConfig(store.TLS{CA: ca})
+	require.NoError(t, err)
+	assert.False(t, tlsConfig.InsecureSkipVerify)
+	assert.Nil(t, tlsConfig.VerifyPeerCertificate)
+}
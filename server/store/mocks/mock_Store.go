@@ -6,6 +6,7 @@ package mocks
 
 import (
 	"context"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
@@ -1889,6 +1890,74 @@ func (_c *MockStore_GetPipelineBadge_Call) RunAndReturn(run func(repo *model.Rep
 	return _c
 }
 
+// GetPipelineByCacheKey provides a mock function for the type MockStore
+func (_mock *MockStore) GetPipelineByCacheKey(repo *model.Repo, s string) (*model.Pipeline, error) {
+	ret := _mock.Called(repo, s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetPipelineByCacheKey")
+	}
+
+	var r0 *model.Pipeline
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(*model.Repo, string) (*model.Pipeline, error)); ok {
+		return returnFunc(repo, s)
+	}
+	if returnFunc, ok := ret.Get(0).(func(*model.Repo, string) *model.Pipeline); ok {
+		r0 = returnFunc(repo, s)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*model.Pipeline)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(*model.Repo, string) error); ok {
+		r1 = returnFunc(repo, s)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_GetPipelineByCacheKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetPipelineByCacheKey'
+type MockStore_GetPipelineByCacheKey_Call struct {
+	*mock.Call
+}
+
+// GetPipelineByCacheKey is a helper method to define mock.On call
+//   - repo *model.Repo
+//   - s string
+func (_e *MockStore_Expecter) GetPipelineByCacheKey(repo interface{}, s interface{}) *MockStore_GetPipelineByCacheKey_Call {
+	return &MockStore_GetPipelineByCacheKey_Call{Call: _e.mock.On("GetPipelineByCacheKey", repo, s)}
+}
+
+func (_c *MockStore_GetPipelineByCacheKey_Call) Run(run func(repo *model.Repo, s string)) *MockStore_GetPipelineByCacheKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 *model.Repo
+		if args[0] != nil {
+			arg0 = args[0].(*model.Repo)
+		}
+		var arg1 string
+		if args[1] != nil {
+			arg1 = args[1].(string)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_GetPipelineByCacheKey_Call) Return(pipeline *model.Pipeline, err error) *MockStore_GetPipelineByCacheKey_Call {
+	_c.Call.Return(pipeline, err)
+	return _c
+}
+
+func (_c *MockStore_GetPipelineByCacheKey_Call) RunAndReturn(run func(repo *model.Repo, s string) (*model.Pipeline, error)) *MockStore_GetPipelineByCacheKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetPipelineCount provides a mock function for the type MockStore
 func (_mock *MockStore) GetPipelineCount() (int64, error) {
 	ret := _mock.Called()
@@ -3277,6 +3346,123 @@ func (_c *MockStore_HasRedirectionForRepo_Call) RunAndReturn(run func(n int64, s
 	return _c
 }
 
+// LeaseAcquire provides a mock function for the type MockStore
+func (_mock *MockStore) LeaseAcquire(lease *model.Lease, n int64) (bool, error) {
+	ret := _mock.Called(lease, n)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LeaseAcquire")
+	}
+
+	var r0 bool
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(*model.Lease, int64) (bool, error)); ok {
+		return returnFunc(lease, n)
+	}
+	if returnFunc, ok := ret.Get(0).(func(*model.Lease, int64) bool); ok {
+		r0 = returnFunc(lease, n)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+	if returnFunc, ok := ret.Get(1).(func(*model.Lease, int64) error); ok {
+		r1 = returnFunc(lease, n)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_LeaseAcquire_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LeaseAcquire'
+type MockStore_LeaseAcquire_Call struct {
+	*mock.Call
+}
+
+// LeaseAcquire is a helper method to define mock.On call
+//   - lease *model.Lease
+//   - n int64
+func (_e *MockStore_Expecter) LeaseAcquire(lease interface{}, n interface{}) *MockStore_LeaseAcquire_Call {
+	return &MockStore_LeaseAcquire_Call{Call: _e.mock.On("LeaseAcquire", lease, n)}
+}
+
+func (_c *MockStore_LeaseAcquire_Call) Run(run func(lease *model.Lease, n int64)) *MockStore_LeaseAcquire_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 *model.Lease
+		if args[0] != nil {
+			arg0 = args[0].(*model.Lease)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_LeaseAcquire_Call) Return(b bool, err error) *MockStore_LeaseAcquire_Call {
+	_c.Call.Return(b, err)
+	return _c
+}
+
+func (_c *MockStore_LeaseAcquire_Call) RunAndReturn(run func(lease *model.Lease, n int64) (bool, error)) *MockStore_LeaseAcquire_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LeaseRelease provides a mock function for the type MockStore
+func (_mock *MockStore) LeaseRelease(lease *model.Lease) error {
+	ret := _mock.Called(lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LeaseRelease")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(*model.Lease) error); ok {
+		r0 = returnFunc(lease)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockStore_LeaseRelease_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LeaseRelease'
+type MockStore_LeaseRelease_Call struct {
+	*mock.Call
+}
+
+// LeaseRelease is a helper method to define mock.On call
+//   - lease *model.Lease
+func (_e *MockStore_Expecter) LeaseRelease(lease interface{}) *MockStore_LeaseRelease_Call {
+	return &MockStore_LeaseRelease_Call{Call: _e.mock.On("LeaseRelease", lease)}
+}
+
+func (_c *MockStore_LeaseRelease_Call) Run(run func(lease *model.Lease)) *MockStore_LeaseRelease_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 *model.Lease
+		if args[0] != nil {
+			arg0 = args[0].(*model.Lease)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_LeaseRelease_Call) Return(err error) *MockStore_LeaseRelease_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockStore_LeaseRelease_Call) RunAndReturn(run func(lease *model.Lease) error) *MockStore_LeaseRelease_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // LogAppend provides a mock function for the type MockStore
 func (_mock *MockStore) LogAppend(step *model.Step, logEntrys []*model.LogEntry) error {
 	ret := _mock.Called(step, logEntrys)
@@ -3448,16 +3634,16 @@ func (_c *MockStore_LogFind_Call) RunAndReturn(run func(step *model.Step) ([]*mo
 }
 
 // Migrate provides a mock function for the type MockStore
-func (_mock *MockStore) Migrate(context1 context.Context, b bool) error {
-	ret := _mock.Called(context1, b)
+func (_mock *MockStore) Migrate(context1 context.Context, b bool, duration time.Duration) error {
+	ret := _mock.Called(context1, b, duration)
 
 	if len(ret) == 0 {
 		panic("no return value specified for Migrate")
 	}
 
 	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(context.Context, bool) error); ok {
-		r0 = returnFunc(context1, b)
+	if returnFunc, ok := ret.Get(0).(func(context.Context, bool, time.Duration) error); ok {
+		r0 = returnFunc(context1, b, duration)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -3472,11 +3658,12 @@ type MockStore_Migrate_Call struct {
 // Migrate is a helper method to define mock.On call
 //   - context1 context.Context
 //   - b bool
-func (_e *MockStore_Expecter) Migrate(context1 interface{}, b interface{}) *MockStore_Migrate_Call {
-	return &MockStore_Migrate_Call{Call: _e.mock.On("Migrate", context1, b)}
+//   - duration time.Duration
+func (_e *MockStore_Expecter) Migrate(context1 interface{}, b interface{}, duration interface{}) *MockStore_Migrate_Call {
+	return &MockStore_Migrate_Call{Call: _e.mock.On("Migrate", context1, b, duration)}
 }
 
-func (_c *MockStore_Migrate_Call) Run(run func(context1 context.Context, b bool)) *MockStore_Migrate_Call {
+func (_c *MockStore_Migrate_Call) Run(run func(context1 context.Context, b bool, duration time.Duration)) *MockStore_Migrate_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		var arg0 context.Context
 		if args[0] != nil {
@@ -3486,9 +3673,14 @@ func (_c *MockStore_Migrate_Call) Run(run func(context1 context.Context, b bool)
 		if args[1] != nil {
 			arg1 = args[1].(bool)
 		}
+		var arg2 time.Duration
+		if args[2] != nil {
+			arg2 = args[2].(time.Duration)
+		}
 		run(
 			arg0,
 			arg1,
+			arg2,
 		)
 	})
 	return _c
@@ -3499,7 +3691,113 @@ func (_c *MockStore_Migrate_Call) Return(err error) *MockStore_Migrate_Call {
 	return _c
 }
 
-func (_c *MockStore_Migrate_Call) RunAndReturn(run func(context1 context.Context, b bool) error) *MockStore_Migrate_Call {
+func (_c *MockStore_Migrate_Call) RunAndReturn(run func(context1 context.Context, b bool, duration time.Duration) error) *MockStore_Migrate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MigrationRollback provides a mock function for the type MockStore
+func (_mock *MockStore) MigrationRollback(s string) error {
+	ret := _mock.Called(s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for MigrationRollback")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(s)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockStore_MigrationRollback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MigrationRollback'
+type MockStore_MigrationRollback_Call struct {
+	*mock.Call
+}
+
+// MigrationRollback is a helper method to define mock.On call
+//   - s string
+func (_e *MockStore_Expecter) MigrationRollback(s interface{}) *MockStore_MigrationRollback_Call {
+	return &MockStore_MigrationRollback_Call{Call: _e.mock.On("MigrationRollback", s)}
+}
+
+func (_c *MockStore_MigrationRollback_Call) Run(run func(s string)) *MockStore_MigrationRollback_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_MigrationRollback_Call) Return(err error) *MockStore_MigrationRollback_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockStore_MigrationRollback_Call) RunAndReturn(run func(s string) error) *MockStore_MigrationRollback_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// MigrationStatus provides a mock function for the type MockStore
+func (_mock *MockStore) MigrationStatus() ([]*model.MigrationStatus, error) {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for MigrationStatus")
+	}
+
+	var r0 []*model.MigrationStatus
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() ([]*model.MigrationStatus, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() []*model.MigrationStatus); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.MigrationStatus)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_MigrationStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MigrationStatus'
+type MockStore_MigrationStatus_Call struct {
+	*mock.Call
+}
+
+// MigrationStatus is a helper method to define mock.On call
+func (_e *MockStore_Expecter) MigrationStatus() *MockStore_MigrationStatus_Call {
+	return &MockStore_MigrationStatus_Call{Call: _e.mock.On("MigrationStatus")}
+}
+
+func (_c *MockStore_MigrationStatus_Call) Run(run func()) *MockStore_MigrationStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockStore_MigrationStatus_Call) Return(migrationStatuss []*model.MigrationStatus, err error) *MockStore_MigrationStatus_Call {
+	_c.Call.Return(migrationStatuss, err)
+	return _c
+}
+
+func (_c *MockStore_MigrationStatus_Call) RunAndReturn(run func() ([]*model.MigrationStatus, error)) *MockStore_MigrationStatus_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -4403,6 +4701,80 @@ func (_c *MockStore_PipelineConfigCreate_Call) RunAndReturn(run func(pipelineCon
 	return _c
 }
 
+// QueryPipelines provides a mock function for the type MockStore
+func (_mock *MockStore) QueryPipelines(user *model.User, pipelineQuery *model.PipelineQuery, listOptions *model.ListOptions) ([]*model.Feed, error) {
+	ret := _mock.Called(user, pipelineQuery, listOptions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for QueryPipelines")
+	}
+
+	var r0 []*model.Feed
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(*model.User, *model.PipelineQuery, *model.ListOptions) ([]*model.Feed, error)); ok {
+		return returnFunc(user, pipelineQuery, listOptions)
+	}
+	if returnFunc, ok := ret.Get(0).(func(*model.User, *model.PipelineQuery, *model.ListOptions) []*model.Feed); ok {
+		r0 = returnFunc(user, pipelineQuery, listOptions)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.Feed)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(*model.User, *model.PipelineQuery, *model.ListOptions) error); ok {
+		r1 = returnFunc(user, pipelineQuery, listOptions)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_QueryPipelines_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'QueryPipelines'
+type MockStore_QueryPipelines_Call struct {
+	*mock.Call
+}
+
+// QueryPipelines is a helper method to define mock.On call
+//   - user *model.User
+//   - pipelineQuery *model.PipelineQuery
+//   - listOptions *model.ListOptions
+func (_e *MockStore_Expecter) QueryPipelines(user interface{}, pipelineQuery interface{}, listOptions interface{}) *MockStore_QueryPipelines_Call {
+	return &MockStore_QueryPipelines_Call{Call: _e.mock.On("QueryPipelines", user, pipelineQuery, listOptions)}
+}
+
+func (_c *MockStore_QueryPipelines_Call) Run(run func(user *model.User, pipelineQuery *model.PipelineQuery, listOptions *model.ListOptions)) *MockStore_QueryPipelines_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 *model.User
+		if args[0] != nil {
+			arg0 = args[0].(*model.User)
+		}
+		var arg1 *model.PipelineQuery
+		if args[1] != nil {
+			arg1 = args[1].(*model.PipelineQuery)
+		}
+		var arg2 *model.ListOptions
+		if args[2] != nil {
+			arg2 = args[2].(*model.ListOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockStore_QueryPipelines_Call) Return(feeds []*model.Feed, err error) *MockStore_QueryPipelines_Call {
+	_c.Call.Return(feeds, err)
+	return _c
+}
+
+func (_c *MockStore_QueryPipelines_Call) RunAndReturn(run func(user *model.User, pipelineQuery *model.PipelineQuery, listOptions *model.ListOptions) ([]*model.Feed, error)) *MockStore_QueryPipelines_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RegistryCreate provides a mock function for the type MockStore
 func (_mock *MockStore) RegistryCreate(registry *model.Registry) error {
 	ret := _mock.Called(registry)
@@ -5424,6 +5796,61 @@ func (_c *MockStore_ServerConfigGet_Call) RunAndReturn(run func(s string) (strin
 	return _c
 }
 
+// ServerConfigList provides a mock function for the type MockStore
+func (_mock *MockStore) ServerConfigList() ([]*model.ServerConfig, error) {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for ServerConfigList")
+	}
+
+	var r0 []*model.ServerConfig
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() ([]*model.ServerConfig, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() []*model.ServerConfig); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*model.ServerConfig)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockStore_ServerConfigList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ServerConfigList'
+type MockStore_ServerConfigList_Call struct {
+	*mock.Call
+}
+
+// ServerConfigList is a helper method to define mock.On call
+func (_e *MockStore_Expecter) ServerConfigList() *MockStore_ServerConfigList_Call {
+	return &MockStore_ServerConfigList_Call{Call: _e.mock.On("ServerConfigList")}
+}
+
+func (_c *MockStore_ServerConfigList_Call) Run(run func()) *MockStore_ServerConfigList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockStore_ServerConfigList_Call) Return(serverConfigs []*model.ServerConfig, err error) *MockStore_ServerConfigList_Call {
+	_c.Call.Return(serverConfigs, err)
+	return _c
+}
+
+func (_c *MockStore_ServerConfigList_Call) RunAndReturn(run func() ([]*model.ServerConfig, error)) *MockStore_ServerConfigList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // ServerConfigSet provides a mock function for the type MockStore
 func (_mock *MockStore) ServerConfigSet(s string, s1 string) error {
 	ret := _mock.Called(s, s1)
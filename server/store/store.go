@@ -16,6 +16,7 @@ package store
 
 import (
 	"context"
+	"time"
 
 	"go.woodpecker-ci.org/woodpecker/v3/server/model"
 )
@@ -76,6 +77,8 @@ type Store interface {
 	GetPipelineLast(*model.Repo, string) (*model.Pipeline, error)
 	// GetPipelineLastBefore gets the last pipeline before pipeline number N.
 	GetPipelineLastBefore(*model.Repo, string, int64) (*model.Pipeline, error)
+	// GetPipelineByCacheKey gets the last successful pipeline with the given result cache key.
+	GetPipelineByCacheKey(*model.Repo, string) (*model.Pipeline, error)
 	// GetPipelineList gets a list of pipelines for the repository
 	GetPipelineList(*model.Repo, *model.ListOptions, *model.PipelineFilter) ([]*model.Pipeline, error)
 	// GetRepoLatestPipelines gets the latest pipelines for the given repo IDs.
@@ -84,6 +87,8 @@ type Store interface {
 	GetActivePipelineList(repo *model.Repo) ([]*model.Pipeline, error)
 	// GetPipelineQueue gets a list of pipelines in queue.
 	GetPipelineQueue() ([]*model.Feed, error)
+	// QueryPipelines gets the pipelines matching the query of the repos the user can pull, or of all repos if the user is nil.
+	QueryPipelines(*model.User, *model.PipelineQuery, *model.ListOptions) ([]*model.Feed, error)
 	// GetPipelineCount gets a count of all pipelines in the system.
 	GetPipelineCount() (int64, error)
 	// CreatePipeline creates a new pipeline and steps.
@@ -159,6 +164,7 @@ type Store interface {
 	ServerConfigGet(string) (string, error)
 	ServerConfigSet(string, string) error
 	ServerConfigDelete(string) error
+	ServerConfigList() ([]*model.ServerConfig, error)
 
 	// Cron
 	CronCreate(*model.Cron) error
@@ -169,6 +175,10 @@ type Store interface {
 	CronListNextExecute(int64, int64) ([]*model.Cron, error)
 	CronGetLock(*model.Cron, int64) (bool, error)
 
+	// Lease
+	LeaseAcquire(*model.Lease, int64) (bool, error)
+	LeaseRelease(*model.Lease) error
+
 	// Forge
 	ForgeCreate(*model.Forge) error
 	ForgeGet(int64) (*model.Forge, error)
@@ -206,5 +216,8 @@ type Store interface {
 	// Store operations
 	Ping() error
 	Close() error
-	Migrate(context.Context, bool) error
+	// Migrate runs the migrations while holding the migration lock, a positive timeout limits the time waiting for the lock.
+	Migrate(context.Context, bool, time.Duration) error
+	MigrationStatus() ([]*model.MigrationStatus, error)
+	MigrationRollback(string) error
 }
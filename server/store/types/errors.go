@@ -14,6 +14,15 @@
 
 package types
 
-import "database/sql"
+import (
+	"database/sql"
+	"errors"
+)
 
 var RecordNotExist = sql.ErrNoRows
+
+var (
+	MigrationNotLast       = errors.New("only the last applied migration can be rolled back")
+	MigrationNotReversible = errors.New("migration has no rollback")
+	MigrationLocked        = errors.New("migration lock is held by another instance")
+)
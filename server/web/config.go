@@ -25,12 +25,13 @@ import (
 
 	"go.woodpecker-ci.org/woodpecker/v3/server"
 	"go.woodpecker-ci.org/woodpecker/v3/server/router/middleware/session"
+	"go.woodpecker-ci.org/woodpecker/v3/server/services/maintenance"
 	"go.woodpecker-ci.org/woodpecker/v3/shared/token"
 	"go.woodpecker-ci.org/woodpecker/v3/version"
 )
 
 func Config(c *gin.Context) {
-	user := session.User(c)
+	user := session.ScopedUser(c)
 
 	var csrf string
 	if user != nil {
@@ -47,6 +48,8 @@ func Config(c *gin.Context) {
 		"root_path":              server.Config.Server.RootPath,
 		"enable_swagger":         server.Config.WebUI.EnableSwagger,
 		"user_registered_agents": !server.Config.Agent.DisableUserRegisteredAgentRegistration,
+		"maintenance":            maintenance.Enabled(),
+		"two_factor_pending":     user != nil && session.TwoFactorRequired(session.User(c)) && !session.TwoFactorVerified(c),
 	}
 
 	// default func map with json parser.
@@ -81,4 +84,6 @@ window.WOODPECKER_ROOT_PATH = "{{ .root_path }}";
 window.WOODPECKER_ENABLE_SWAGGER = {{ .enable_swagger }};
 window.WOODPECKER_SKIP_VERSION_CHECK = {{ .skip_version_check }}
 window.WOODPECKER_USER_REGISTERED_AGENTS = {{ .user_registered_agents }}
+window.WOODPECKER_MAINTENANCE = {{ .maintenance }}
+window.WOODPECKER_TWO_FACTOR_PENDING = {{ .two_factor_pending }}
 `
@@ -0,0 +1,107 @@
+// Copyright 2025 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package web
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"go.woodpecker-ci.org/woodpecker/v3/shared/httputil"
+)
+
+const (
+	remoteFileTimeout = 10 * time.Second
+	// remoteFileRetry is the maximum time until a failed fetch is retried.
+	remoteFileRetry = time.Minute
+	// remoteFileMaxSize limits the size of a fetched file.
+	remoteFileMaxSize = 10 << 20
+)
+
+// IsRemoteFile returns whether the custom file is a http(s) url instead of a local path.
+func IsRemoteFile(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// remoteFile fetches a custom file from a url and caches it.
+// If a fetch fails the last fetched content is served, or no content if there is none.
+// The fetch is done without holding the lock, while it runs other requests get the last content.
+type remoteFile struct {
+	sync.Mutex
+	url    string
+	ttl    time.Duration
+	client *http.Client
+
+	data     []byte
+	expires  time.Time
+	fetching bool
+}
+
+func newRemoteFile(url string, ttl time.Duration) *remoteFile {
+	return &remoteFile{
+		url:    url,
+		ttl:    ttl,
+		client: httputil.WrapClient(&http.Client{Timeout: remoteFileTimeout}, "custom-files"),
+	}
+}
+
+func (f *remoteFile) content(ctx context.Context) []byte {
+	f.Lock()
+	if f.fetching || time.Now().Before(f.expires) {
+		defer f.Unlock()
+		return f.data
+	}
+	f.fetching = true
+	f.Unlock()
+
+	// the content is shared, so the request going away must not cancel the fetch
+	data, err := f.fetch(context.WithoutCancel(ctx))
+
+	f.Lock()
+	defer f.Unlock()
+	f.fetching = false
+	if err != nil {
+		log.Error().Err(err).Msgf("could not fetch custom file from %s", f.url)
+		f.expires = time.Now().Add(min(f.ttl, remoteFileRetry))
+		return f.data
+	}
+
+	f.data = data
+	f.expires = time.Now().Add(f.ttl)
+	return f.data
+}
+
+func (f *remoteFile) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, remoteFileMaxSize))
+}
@@ -68,26 +68,40 @@ func New() (*gin.Engine, error) {
 }
 
 func handleCustomFilesAndAssets(fs *prefixFS) func(ctx *gin.Context) {
-	serveFileOrEmptyContent := func(w http.ResponseWriter, r *http.Request, localFileName, fileName string) {
-		if len(localFileName) > 0 {
-			http.ServeFile(w, r, localFileName)
-		} else {
-			// prefer zero content over sending a 404 Not Found
-			http.ServeContent(w, r, fileName, time.Now(), bytes.NewReader([]byte{}))
-		}
-	}
+	customJs := customFile(server.Config.Server.CustomJsFile)
+	customCSS := customFile(server.Config.Server.CustomCSSFile)
+
 	return func(ctx *gin.Context) {
 		switch {
 		case strings.HasSuffix(ctx.Request.RequestURI, "/assets/custom.js"):
-			serveFileOrEmptyContent(ctx.Writer, ctx.Request, server.Config.Server.CustomJsFile, "file.js")
+			customJs(ctx.Writer, ctx.Request, "file.js")
 		case strings.HasSuffix(ctx.Request.RequestURI, "/assets/custom.css"):
-			serveFileOrEmptyContent(ctx.Writer, ctx.Request, server.Config.Server.CustomCSSFile, "file.css")
+			customCSS(ctx.Writer, ctx.Request, "file.css")
 		default:
 			serveFile(fs)(ctx)
 		}
 	}
 }
 
+// customFile returns a handler serving the local or remote custom file.
+func customFile(path string) func(w http.ResponseWriter, r *http.Request, fileName string) {
+	if IsRemoteFile(path) {
+		remote := newRemoteFile(path, server.Config.Server.CustomFilesCacheTTL)
+		return func(w http.ResponseWriter, r *http.Request, fileName string) {
+			http.ServeContent(w, r, fileName, time.Time{}, bytes.NewReader(remote.content(r.Context())))
+		}
+	}
+
+	return func(w http.ResponseWriter, r *http.Request, fileName string) {
+		if len(path) > 0 {
+			http.ServeFile(w, r, path)
+		} else {
+			// prefer zero content over sending a 404 Not Found
+			http.ServeContent(w, r, fileName, time.Now(), bytes.NewReader([]byte{}))
+		}
+	}
+}
+
 func serveFile(f *prefixFS) func(ctx *gin.Context) {
 	return func(ctx *gin.Context) {
 		file, err := f.Open(ctx.Request.URL.Path)
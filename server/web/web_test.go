@@ -19,6 +19,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -93,3 +94,83 @@ func Test_custom_file_return_actual_content(t *testing.T) {
 		})
 	}
 }
+
+func Test_custom_file_from_url(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	requests := 0
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests++
+		_, _ = w.Write([]byte("REMOTE-DATA"))
+	}))
+	defer cdn.Close()
+
+	server.Config.Server.CustomJsFile = cdn.URL + "/custom.js"
+	server.Config.Server.CustomCSSFile = ""
+	server.Config.Server.CustomFilesCacheTTL = time.Hour
+	router, err := New()
+	assert.NoError(t, err)
+
+	get := func() *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodGet, "/assets/custom.js", nil)
+		assert.NoError(t, err)
+		request.RequestURI = "/assets/custom.js" // additional required for mocking
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, request)
+		return rr
+	}
+
+	rr := get()
+	assert.Equal(t, 200, rr.Code)
+	assert.Equal(t, []byte("REMOTE-DATA"), rr.Body.Bytes())
+	assert.Contains(t, rr.Header().Get("Content-Type"), "javascript")
+
+	// served from the cache
+	rr = get()
+	assert.Equal(t, []byte("REMOTE-DATA"), rr.Body.Bytes())
+	assert.Equal(t, 1, requests)
+}
+
+func Test_custom_file_from_unreachable_url(t *testing.T) {
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("REMOTE-DATA"))
+	}))
+
+	file := newRemoteFile(cdn.URL+"/custom.css", 0)
+	assert.Equal(t, []byte("REMOTE-DATA"), file.content(t.Context()))
+
+	// the last fetched content is used as fallback
+	cdn.Close()
+	assert.Equal(t, []byte("REMOTE-DATA"), file.content(t.Context()))
+
+	// no content if it was never fetched
+	file = newRemoteFile(cdn.URL+"/custom.css", 0)
+	assert.Empty(t, file.content(t.Context()))
+}
+
+func Test_custom_file_fetch_does_not_block(t *testing.T) {
+	release := make(chan struct{})
+	cdn := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		<-release
+		_, _ = w.Write([]byte("NEW-DATA"))
+	}))
+	defer cdn.Close()
+
+	file := newRemoteFile(cdn.URL+"/custom.css", time.Hour)
+	file.data = []byte("OLD-DATA")
+
+	done := make(chan []byte)
+	go func() { done <- file.content(t.Context()) }()
+
+	// while the slow fetch is running the last content is served right away
+	assert.Eventually(t, func() bool {
+		file.Lock()
+		defer file.Unlock()
+		return file.fetching
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []byte("OLD-DATA"), file.content(t.Context()))
+
+	close(release)
+	assert.Equal(t, []byte("NEW-DATA"), <-done)
+	assert.Equal(t, []byte("NEW-DATA"), file.content(t.Context()))
+}
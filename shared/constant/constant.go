@@ -39,3 +39,7 @@ var TrustedClonePlugins = []string{
 
 // TaskTimeout is the time till a running task is counted as dead.
 var TaskTimeout = time.Minute
+
+// PrefetchLeaseMetadataKey is the grpc metadata key agents use to poll a workflow ahead of time.
+// Its value is the duration the server reserves the workflow for the agent until it gets started.
+const PrefetchLeaseMetadataKey = "prefetch-lease"
@@ -39,30 +39,51 @@ func IsHTTPS(r *http.Request) bool {
 	}
 }
 
-// SetCookie writes the cookie value.
-func SetCookie(w http.ResponseWriter, r *http.Request, name, value string) {
-	cookie := http.Cookie{
+// CookieOptions are the attributes of the cookies set by the server.
+type CookieOptions struct {
+	// Path defaults to "/".
+	Path string
+	// Domain defaults to a host-only cookie.
+	Domain string
+	// Secure forces the Secure attribute, if nil it is set for HTTPS requests.
+	Secure   *bool
+	SameSite http.SameSite
+}
+
+func (o CookieOptions) cookie(r *http.Request, name, value string) *http.Cookie {
+	cookie := &http.Cookie{
 		Name:     name,
 		Value:    value,
-		Path:     "/",
-		Domain:   r.URL.Host,
+		Path:     o.Path,
+		Domain:   o.Domain,
 		HttpOnly: true,
 		Secure:   IsHTTPS(r),
-		MaxAge:   math.MaxInt32, // the cookie value (token) is responsible for expiration
+		SameSite: o.SameSite,
+	}
+	if cookie.Path == "" {
+		cookie.Path = "/"
+	}
+	if cookie.Domain == "" {
+		cookie.Domain = r.URL.Host
 	}
+	if o.Secure != nil {
+		cookie.Secure = *o.Secure
+	}
+	return cookie
+}
 
-	http.SetCookie(w, &cookie)
+// SetCookie writes the cookie value.
+func SetCookie(w http.ResponseWriter, r *http.Request, name, value string, opts CookieOptions) {
+	cookie := opts.cookie(r, name, value)
+	cookie.MaxAge = math.MaxInt32 // the cookie value (token) is responsible for expiration
+
+	http.SetCookie(w, cookie)
 }
 
 // DelCookie deletes a cookie.
-func DelCookie(w http.ResponseWriter, r *http.Request, name string) {
-	cookie := http.Cookie{
-		Name:   name,
-		Value:  "deleted",
-		Path:   "/",
-		Domain: r.URL.Host,
-		MaxAge: -1,
-	}
+func DelCookie(w http.ResponseWriter, r *http.Request, name string, opts CookieOptions) {
+	cookie := opts.cookie(r, name, "deleted")
+	cookie.MaxAge = -1
 
-	http.SetCookie(w, &cookie)
+	http.SetCookie(w, cookie)
 }
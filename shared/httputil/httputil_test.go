@@ -0,0 +1,78 @@
+// Copyright 2023 Woodpecker Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetCookie(t *testing.T) {
+	secure := true
+	insecure := false
+
+	tests := []struct {
+		name   string
+		https  bool
+		opts   CookieOptions
+		expect string
+	}{
+		{
+			name:   "defaults",
+			expect: "user_sess=token; Path=/; Max-Age=2147483647; HttpOnly",
+		},
+		{
+			name:   "https request",
+			https:  true,
+			opts:   CookieOptions{Path: "/ci", SameSite: http.SameSiteLaxMode},
+			expect: "user_sess=token; Path=/ci; Max-Age=2147483647; HttpOnly; Secure; SameSite=Lax",
+		},
+		{
+			name:   "forced secure with domain",
+			opts:   CookieOptions{Domain: "example.com", Secure: &secure, SameSite: http.SameSiteNoneMode},
+			expect: "user_sess=token; Path=/; Domain=example.com; Max-Age=2147483647; HttpOnly; Secure; SameSite=None",
+		},
+		{
+			name:   "forced insecure",
+			https:  true,
+			opts:   CookieOptions{Secure: &insecure, SameSite: http.SameSiteStrictMode},
+			expect: "user_sess=token; Path=/; Max-Age=2147483647; HttpOnly; SameSite=Strict",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/login", nil)
+			if tt.https {
+				r.Header.Set("X-Forwarded-Proto", "https")
+			}
+			w := httptest.NewRecorder()
+
+			SetCookie(w, r, "user_sess", "token", tt.opts)
+			assert.Equal(t, tt.expect, w.Header().Get("Set-Cookie"))
+		})
+	}
+}
+
+func TestDelCookie(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/logout", nil)
+	w := httptest.NewRecorder()
+
+	DelCookie(w, r, "user_sess", CookieOptions{Path: "/ci", SameSite: http.SameSiteLaxMode})
+	assert.Equal(t, "user_sess=deleted; Path=/ci; Max-Age=0; HttpOnly; SameSite=Lax", w.Header().Get("Set-Cookie"))
+}
@@ -25,6 +25,10 @@ import (
 
 type SecretFunc func(*Token) (string, error)
 
+// SecretsFunc returns all secrets a token may be signed with, e.g. the current and
+// the previous secret while rotating it.
+type SecretsFunc func(*Token) ([]string, error)
+
 type Type string
 
 const (
@@ -40,11 +44,17 @@ const (
 const SignerAlgo = "HS256"
 
 type Token struct {
-	Type   Type
-	claims jwt.MapClaims
+	Type    Type
+	claims  jwt.MapClaims
+	expires int64
 }
 
 func Parse(allowedTypes []Type, raw string, fn SecretFunc) (*Token, error) {
+	return ParseSecrets(allowedTypes, raw, singleSecret(fn))
+}
+
+// ParseSecrets parses a token which is valid if it is signed with any of the secrets.
+func ParseSecrets(allowedTypes []Type, raw string, fn SecretsFunc) (*Token, error) {
 	token := &Token{
 		claims: jwt.MapClaims{},
 	}
@@ -66,6 +76,11 @@ func Parse(allowedTypes []Type, raw string, fn SecretFunc) (*Token, error) {
 }
 
 func ParseRequest(allowedTypes []Type, r *http.Request, fn SecretFunc) (*Token, error) {
+	return ParseRequestSecrets(allowedTypes, r, singleSecret(fn))
+}
+
+// ParseRequestSecrets parses the token of a request which is valid if it is signed with any of the secrets.
+func ParseRequestSecrets(allowedTypes []Type, r *http.Request, fn SecretsFunc) (*Token, error) {
 	// first we attempt to get the token from the
 	// authorization header.
 	token := r.Header.Get("Authorization")
@@ -75,19 +90,19 @@ func ParseRequest(allowedTypes []Type, r *http.Request, fn SecretFunc) (*Token,
 		if _, err := fmt.Sscanf(token, "Bearer %s", &bearer); err != nil {
 			return nil, err
 		}
-		return Parse(allowedTypes, bearer, fn)
+		return ParseSecrets(allowedTypes, bearer, fn)
 	}
 
 	token = r.Header.Get("X-Gitlab-Token")
 	if len(token) != 0 {
-		return Parse(allowedTypes, token, fn)
+		return ParseSecrets(allowedTypes, token, fn)
 	}
 
 	// then we attempt to get the token from the
 	// access_token url query parameter
 	token = r.FormValue("access_token")
 	if len(token) != 0 {
-		return Parse(allowedTypes, token, fn)
+		return ParseSecrets(allowedTypes, token, fn)
 	}
 
 	// and finally we attempt to get the token from
@@ -96,7 +111,7 @@ func ParseRequest(allowedTypes []Type, r *http.Request, fn SecretFunc) (*Token,
 	if err != nil {
 		return nil, err
 	}
-	return Parse(allowedTypes, cookie.Value, fn)
+	return ParseSecrets(allowedTypes, cookie.Value, fn)
 }
 
 func CheckCsrf(r *http.Request, fn SecretFunc) error {
@@ -144,6 +159,11 @@ func (t *Token) SignExpires(secret string, exp int64) (string, error) {
 	return token.SignedString([]byte(secret))
 }
 
+// Expires returns the expiration date of a parsed token as unix timestamp, zero if it does not expire.
+func (t *Token) Expires() int64 {
+	return t.expires
+}
+
 func (t *Token) Set(key, value string) {
 	t.claims[key] = value
 }
@@ -157,7 +177,7 @@ func (t *Token) Get(key string) string {
 	return claim
 }
 
-func keyFunc(token *Token, fn SecretFunc) jwt.Keyfunc {
+func keyFunc(token *Token, fn SecretsFunc) jwt.Keyfunc {
 	return func(t *jwt.Token) (any, error) {
 		claims, ok := t.Claims.(jwt.MapClaims)
 		if !ok {
@@ -176,6 +196,10 @@ func keyFunc(token *Token, fn SecretFunc) jwt.Keyfunc {
 		}
 		token.Type = Type(tokenType)
 
+		if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+			token.expires = exp.Unix()
+		}
+
 		// copy custom claims
 		for k, v := range claims {
 			// skip the reserved claims https://datatracker.ietf.org/doc/html/rfc7519#section-4.1
@@ -191,8 +215,28 @@ func keyFunc(token *Token, fn SecretFunc) jwt.Keyfunc {
 		}
 
 		// invoke the callback function to retrieve
-		// the secret key used to verify
-		secret, err := fn(token)
-		return []byte(secret), err
+		// the secret keys used to verify
+		secrets, err := fn(token)
+		if err != nil {
+			return nil, err
+		}
+		if len(secrets) == 1 {
+			return []byte(secrets[0]), nil
+		}
+		keys := make([]jwt.VerificationKey, 0, len(secrets))
+		for _, secret := range secrets {
+			keys = append(keys, []byte(secret))
+		}
+		return jwt.VerificationKeySet{Keys: keys}, nil
+	}
+}
+
+func singleSecret(fn SecretFunc) SecretsFunc {
+	return func(t *Token) ([]string, error) {
+		secret, err := fn(t)
+		if err != nil {
+			return nil, err
+		}
+		return []string{secret}, nil
 	}
 }
@@ -2,6 +2,7 @@ package token_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
@@ -51,3 +52,34 @@ func TestTokenWrongSecret(t *testing.T) {
 
 	assert.ErrorIs(t, err, jwt.ErrSignatureInvalid)
 }
+
+func TestTokenExpires(t *testing.T) {
+	_token := token.New(token.SessToken)
+	signedToken, err := _token.SignExpires(jwtSecret, time.Now().Add(time.Hour).Unix())
+	assert.NoError(t, err)
+
+	parsed, err := token.Parse([]token.Type{token.SessToken}, signedToken, func(_ *token.Token) (string, error) {
+		return jwtSecret, nil
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, time.Now().Add(time.Hour).Unix(), parsed.Expires(), 1)
+}
+
+func TestTokenSecrets(t *testing.T) {
+	_token := token.New(token.HookToken)
+	_token.Set("repo-id", "1")
+	signedToken, err := _token.Sign(jwtSecret)
+	assert.NoError(t, err)
+
+	// any of the secrets is accepted
+	parsed, err := token.ParseSecrets([]token.Type{token.HookToken}, signedToken, func(_ *token.Token) ([]string, error) {
+		return []string{"the-new-secret", jwtSecret}, nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "1", parsed.Get("repo-id"))
+
+	_, err = token.ParseSecrets([]token.Type{token.HookToken}, signedToken, func(_ *token.Token) ([]string, error) {
+		return []string{"the-new-secret", "another-wrong-secret"}, nil
+	})
+	assert.ErrorIs(t, err, jwt.ErrSignatureInvalid)
+}
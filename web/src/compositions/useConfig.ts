@@ -9,6 +9,8 @@ declare global {
     WOODPECKER_ROOT_PATH: string | undefined;
     WOODPECKER_ENABLE_SWAGGER: boolean | undefined;
     WOODPECKER_USER_REGISTERED_AGENTS: boolean | undefined;
+    WOODPECKER_MAINTENANCE: boolean | undefined;
+    WOODPECKER_TWO_FACTOR_PENDING: boolean | undefined;
   }
 }
 
@@ -20,4 +22,6 @@ export default () => ({
   rootPath: window.WOODPECKER_ROOT_PATH ?? '',
   enableSwagger: window.WOODPECKER_ENABLE_SWAGGER === true || false,
   userRegisteredAgents: window.WOODPECKER_USER_REGISTERED_AGENTS || false,
+  maintenance: window.WOODPECKER_MAINTENANCE === true || false,
+  twoFactorPending: window.WOODPECKER_TWO_FACTOR_PENDING === true || false,
 });
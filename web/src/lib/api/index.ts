@@ -145,7 +145,7 @@ export default class WoodpeckerClient extends ApiClient {
   async restartPipeline(
     repoId: number,
     pipeline: string,
-    opts?: { event?: string; deploy_to?: string; fork?: boolean },
+    opts?: { event?: string; deploy_to?: string; fork?: boolean; force?: boolean },
   ): Promise<Pipeline> {
     const query = encodeQueryString(opts);
     return this._post(`/api/repos/${repoId}/pipelines/${pipeline}?${query}`) as Promise<Pipeline>;
@@ -308,6 +308,10 @@ export default class WoodpeckerClient extends ApiClient {
     return this._post('/api/user/token') as Promise<string>;
   }
 
+  async verifyTwoFactor(code: string): Promise<string> {
+    return this._post('/api/user/2fa/verify', { code }) as Promise<string>;
+  }
+
   async getSignaturePublicKey(): Promise<string> {
     return this._get('/api/signature/public-key') as Promise<string>;
   }
@@ -12,5 +12,13 @@ export interface Agent {
   capacity: number;
   version: string;
   no_schedule: boolean;
+  offline: boolean;
   custom_labels: Record<string, string>;
+  resource_capacity: Resources;
+  resource_usage: Resources;
+}
+
+export interface Resources {
+  cpu: number;
+  memory: number;
 }
@@ -58,11 +58,36 @@ export interface Repo {
   // The amount of time in minutes before the pipeline is killed.
   timeout: number;
 
+  // x-dart-type: Duration
+  // The maximum timeout in minutes an admin allowed for the repository, zero means the global maximum.
+  max_timeout?: number;
+
+  // The max number of workflows an admin allowed a pipeline of the repository to expand to, zero means the global limit.
+  max_matrix_jobs?: number;
+
+  // The log store an admin selected for the repository, empty means the default log store.
+  log_store?: string;
+
+  // The max log size of a step in bytes an admin set for the repository, zero means the server default.
+  log_max_size?: number;
+
+  // The max log line count of a step an admin set for the repository, zero means the server default.
+  log_max_lines?: number;
+
   // Whether pull requests should trigger a pipeline.
   allow_pr: boolean;
 
   allow_deploy: boolean;
 
+  // Whether the result of an identical successful pipeline is reused instead of running it again.
+  result_cache: boolean;
+
+  // Whether skipped workflows report a commit status, so they can be required by branch protection
+  workflow_status_checks: boolean;
+
+  // Whether secrets are exposed to pull requests from forks, unset means the org or server setting.
+  expose_secrets_to_forks?: boolean | null;
+
   config_file: string;
 
   visibility: RepoVisibility;
@@ -80,8 +105,20 @@ export interface Repo {
 
   netrc_trusted: string[];
 
+  // Plugins an admin allowed to run privileged for the repository in addition to the global ones.
+  privileged_plugins?: string[];
+
+  // Clone plugins an admin trusted for the repository in addition to the global ones.
+  trusted_clone_plugins?: string[];
+
   // Endpoint for config extensions
   config_extension_endpoint: string;
+
+  // Webhook notified about pipeline state transitions
+  notify_url: string;
+
+  // Pipeline statuses the notification webhook is called for
+  notify_events: string[];
 }
 
 /* eslint-disable no-unused-vars */
@@ -109,11 +146,14 @@ export type RepoSettings = Pick<
   | 'approval_allowed_users'
   | 'allow_pr'
   | 'allow_deploy'
+  | 'workflow_status_checks'
   | 'cancel_previous_pipeline_events'
   | 'netrc_trusted'
 >;
 
-export type ExtensionSettings = Pick<Repo, 'config_extension_endpoint'>;
+export type ExtensionSettings = Pick<Repo, 'config_extension_endpoint' | 'notify_url' | 'notify_events'> & {
+  notify_secret?: string;
+};
 
 export interface RepoPermissions {
   pull: boolean;
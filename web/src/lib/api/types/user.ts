@@ -24,6 +24,9 @@ export interface User {
   active: boolean;
   // Whether the account is currently active.
 
+  totp_enabled?: boolean;
+  // Whether the account has a second factor enabled.
+
   org_id: number;
   // The ID of the org assigned to the user.
 }
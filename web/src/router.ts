@@ -338,6 +338,12 @@ const routes: RouteRecordRaw[] = [
     meta: { blank: true },
     props: true,
   },
+  {
+    path: `${rootPath}/login/2fa`,
+    name: 'login-2fa',
+    component: (): Component => import('~/views/LoginTwoFactor.vue'),
+    meta: { blank: true },
+  },
   {
     path: `${rootPath}/cli/auth`,
     component: (): Component => import('~/views/cli/Auth.vue'),
@@ -383,6 +389,13 @@ router.beforeEach(async (to, _, next) => {
     return;
   }
 
+  // admins with a second factor have to verify it before they get admin permissions
+  const { user, twoFactorPending } = useConfig();
+  if (twoFactorPending && user?.totp_enabled && to.name !== 'login-2fa') {
+    next({ name: 'login-2fa' });
+    return;
+  }
+
   next();
 });
 
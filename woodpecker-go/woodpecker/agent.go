@@ -1,11 +1,17 @@
 package woodpecker
 
-import "fmt"
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+)
 
 const (
-	pathAgents     = "%s/api/agents"
-	pathAgent      = "%s/api/agents/%d"
-	pathAgentTasks = "%s/api/agents/%d/tasks"
+	pathAgents      = "%s/api/agents"
+	pathAgent       = "%s/api/agents/%d"
+	pathAgentTasks  = "%s/api/agents/%d/tasks"
+	pathAgentTokens = "%s/api/agent-tokens"
+	pathAgentToken  = "%s/api/agent-tokens/%d?%s"
 )
 
 // AgentCreate creates a new agent.
@@ -48,3 +54,26 @@ func (c *client) AgentTasksList(agentID int64) ([]*Task, error) {
 	uri := fmt.Sprintf(pathAgentTasks, c.addr, agentID)
 	return out, c.get(uri, &out)
 }
+
+// AgentTokenList returns the shared agent tokens stored on the server.
+func (c *client) AgentTokenList() ([]*AgentToken, error) {
+	out := make([]*AgentToken, 0, 5)
+	uri := fmt.Sprintf(pathAgentTokens, c.addr)
+	return out, c.get(uri, &out)
+}
+
+// AgentTokenCreate creates a new shared agent token.
+func (c *client) AgentTokenCreate() (*AgentToken, error) {
+	out := new(AgentToken)
+	uri := fmt.Sprintf(pathAgentTokens, c.addr)
+	return out, c.post(uri, nil, out)
+}
+
+// AgentTokenDelete retires the shared agent token with the given id.
+// Unless force is set, the token is only removed if no agent uses it anymore.
+func (c *client) AgentTokenDelete(tokenID int64, force bool) error {
+	query := url.Values{}
+	query.Set("force", strconv.FormatBool(force))
+	uri := fmt.Sprintf(pathAgentToken, c.addr, tokenID, query.Encode())
+	return c.delete(uri)
+}
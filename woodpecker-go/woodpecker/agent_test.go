@@ -507,3 +507,38 @@ func TestClient_AgentTasksList(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_AgentTokens(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/agent-tokens":
+			_, err := fmt.Fprint(w, `[{"id":1,"token":"abc","created":1}]`)
+			assert.NoError(t, err)
+		case r.Method == http.MethodPost && r.URL.Path == "/api/agent-tokens":
+			_, err := fmt.Fprint(w, `{"id":2,"token":"def","created":2}`)
+			assert.NoError(t, err)
+		case r.Method == http.MethodDelete && r.URL.Path == "/api/agent-tokens/1":
+			if r.URL.Query().Get("force") != "true" {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+
+	tokens, err := client.AgentTokenList()
+	assert.NoError(t, err)
+	assert.Equal(t, []*AgentToken{{ID: 1, Token: "abc", Created: 1}}, tokens)
+
+	token, err := client.AgentTokenCreate()
+	assert.NoError(t, err)
+	assert.Equal(t, &AgentToken{ID: 2, Token: "def", Created: 2}, token)
+
+	assert.Error(t, client.AgentTokenDelete(1, false))
+	assert.NoError(t, client.AgentTokenDelete(1, true))
+}
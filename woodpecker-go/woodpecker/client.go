@@ -23,12 +23,18 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker/httputil"
 )
 
 const (
-	pathLogLevel = "%s/api/log-level"
+	pathInfo            = "%s/api/info"
+	pathLogLevel        = "%s/api/log-level"
+	pathMaintenance     = "%s/api/maintenance"
+	pathMigrations      = "%s/api/migrations"
+	pathMigrationUndo   = "%s/api/migrations/%s/rollback"
+	pathRotateJWTSecret = "%s/api/jwt-secret/rotate?%s"
 
 	//nolint:godot
 	// TODO: implement endpoints
@@ -72,6 +78,14 @@ func (c *client) SetAddress(addr string) {
 	c.addr = addr
 }
 
+// ServerInfo returns the version, forge kind and enabled features of the server.
+func (c *client) ServerInfo() (*ServerInfo, error) {
+	out := new(ServerInfo)
+	uri := fmt.Sprintf(pathInfo, c.addr)
+	err := c.get(uri, out)
+	return out, err
+}
+
 // LogLevel returns the current logging level.
 func (c *client) LogLevel() (*LogLevel, error) {
 	out := new(LogLevel)
@@ -88,6 +102,45 @@ func (c *client) SetLogLevel(in *LogLevel) (*LogLevel, error) {
 	return out, err
 }
 
+// Maintenance returns whether the maintenance mode of the server is enabled.
+func (c *client) Maintenance() (*Maintenance, error) {
+	out := new(Maintenance)
+	uri := fmt.Sprintf(pathMaintenance, c.addr)
+	err := c.get(uri, out)
+	return out, err
+}
+
+// SetMaintenance enables or disables the maintenance mode of the server.
+func (c *client) SetMaintenance(in *Maintenance) (*Maintenance, error) {
+	out := new(Maintenance)
+	uri := fmt.Sprintf(pathMaintenance, c.addr)
+	err := c.post(uri, in, out)
+	return out, err
+}
+
+// MigrationStatus returns all store migrations of the server and whether they were applied.
+func (c *client) MigrationStatus() ([]*Migration, error) {
+	var out []*Migration
+	uri := fmt.Sprintf(pathMigrations, c.addr)
+	err := c.get(uri, &out)
+	return out, err
+}
+
+// MigrationRollback reverts the last applied store migration of the server.
+func (c *client) MigrationRollback(version string) error {
+	uri := fmt.Sprintf(pathMigrationUndo, c.addr, url.PathEscape(version))
+	return c.post(uri, nil, nil)
+}
+
+// RotateJWTSecret rotates the secret the server signs its tokens with.
+// Tokens signed with the previous secret stay valid for the grace period.
+func (c *client) RotateJWTSecret(gracePeriod time.Duration) error {
+	query := url.Values{}
+	query.Set("grace_period", gracePeriod.String())
+	uri := fmt.Sprintf(pathRotateJWTSecret, c.addr, query.Encode())
+	return c.post(uri, nil, nil)
+}
+
 //
 // HTTP request helper functions.
 //
@@ -21,6 +21,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -55,3 +56,37 @@ func Test_LogLevel(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, strings.EqualFold(newLvl.Level, logLevel))
 }
+
+func Test_RotateJWTSecret(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/jwt-secret/rotate", r.URL.Path)
+		assert.Equal(t, "1h0m0s", r.URL.Query().Get("grace_period"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+	assert.NoError(t, client.RotateJWTSecret(time.Hour))
+}
+
+func Test_ServerInfo(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/info", r.URL.Path)
+		_, err := fmt.Fprint(w, `{"version":"3.5.0","forge":"github","features":{"swagger":true,"open_registration":false,"log_store":"file"}}`)
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+	info, err := client.ServerInfo()
+	assert.NoError(t, err)
+	assert.Equal(t, &ServerInfo{
+		Version: "3.5.0",
+		Forge:   "github",
+		Features: ServerFeatures{
+			Swagger:  true,
+			LogStore: "file",
+		},
+	}, info)
+}
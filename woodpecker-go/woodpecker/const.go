@@ -29,14 +29,16 @@ const (
 
 // Status values.
 const (
-	StatusBlocked = "blocked"
-	StatusSkipped = "skipped"
-	StatusPending = "pending"
-	StatusRunning = "running"
-	StatusSuccess = "success"
-	StatusFailure = "failure"
-	StatusKilled  = "killed"
-	StatusError   = "error"
+	StatusBlocked  = "blocked"
+	StatusSkipped  = "skipped"
+	StatusPending  = "pending"
+	StatusRunning  = "running"
+	StatusSuccess  = "success"
+	StatusFailure  = "failure"
+	StatusKilled   = "killed"
+	StatusError    = "error"
+	StatusDeclined = "declined"
+	StatusCreated  = "created"
 )
 
 // LogEntryType identifies the type of line in the logs.
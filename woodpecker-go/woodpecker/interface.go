@@ -15,7 +15,9 @@
 package woodpecker
 
 import (
+	"context"
 	"net/http"
+	"time"
 )
 
 // Client is used to communicate with a Woodpecker server.
@@ -46,6 +48,23 @@ type Client interface {
 	// It is recommended to specify forgeID (default is 1).
 	UserDel(login string, forgeID ...int64) error
 
+	// UserResetTwoFactor removes the second factor of a user account.
+	// It is recommended to specify forgeID (default is 1).
+	UserResetTwoFactor(login string, forgeID ...int64) error
+
+	// TwoFactorEnroll creates a new second factor secret for the current user.
+	TwoFactorEnroll() (*TwoFactorEnrollment, error)
+
+	// TwoFactorActivate enables the enrolled second factor and returns the recovery codes.
+	TwoFactorActivate(code string) (*TwoFactorRecoveryCodes, error)
+
+	// TwoFactorVerify verifies a TOTP or recovery code and returns a new token
+	// with admin permissions for admins.
+	TwoFactorVerify(code string) (string, error)
+
+	// TwoFactorRecoveryCodes replaces the recovery codes of the current user.
+	TwoFactorRecoveryCodes(code string) (*TwoFactorRecoveryCodes, error)
+
 	// Repo returns a repository by name.
 	Repo(repoID int64) (*Repo, error)
 
@@ -56,6 +75,10 @@ type Client interface {
 	// access in the host system.
 	RepoList(opt RepoListOptions) ([]*Repo, error)
 
+	// RepoSync synchronizes the repositories of the user with the forge and
+	// returns what changed.
+	RepoSync(opt RepoSyncOptions) (*RepoSyncReport, error)
+
 	// RepoPost activates a repository.
 	RepoPost(opt RepoPostOptions) (*Repo, error)
 
@@ -68,8 +91,15 @@ type Client interface {
 	// RepoChown updates a repository owner.
 	RepoChown(repoID int64) (*Repo, error)
 
-	// RepoRepair repairs the repository hooks.
-	RepoRepair(repoID int64) error
+	// RepoRepair repairs the repository hooks and returns what was fixed.
+	RepoRepair(repoID int64) (*RepoRepairReport, error)
+
+	// RepoHookRotate rotates the webhook secret of the repository and re-registers the webhook.
+	// Webhooks using the previous secret are accepted for the grace period.
+	RepoHookRotate(repoID int64, gracePeriod time.Duration) error
+
+	// RepoHookPrune revokes the previous webhook secrets of the repository.
+	RepoHookPrune(repoID int64) error
 
 	// RepoDel deletes a repository.
 	RepoDel(repoID int64) error
@@ -89,6 +119,9 @@ type Client interface {
 	// PipelineQueue returns a list of enqueued pipelines.
 	PipelineQueue() ([]*Feed, error)
 
+	// PipelineQuery returns the pipelines across all accessible repositories matching the query.
+	PipelineQuery(query *PipelineQuery, opt ListOptions) ([]*Feed, error)
+
 	// PipelineCreate returns creates a pipeline on specified branch.
 	PipelineCreate(repoID int64, opts *PipelineOptions) (*Pipeline, error)
 
@@ -110,6 +143,9 @@ type Client interface {
 	// StepLogEntries returns the LogEntries for the given pipeline step
 	StepLogEntries(repoID, pipeline, stepID int64) ([]*LogEntry, error)
 
+	// StepLogStream streams the LogEntries of a running pipeline step until it is done.
+	StepLogStream(ctx context.Context, repoID, pipeline, stepID int64, fn func(*LogEntry) error) error
+
 	// Deploy triggers a deployment for an existing pipeline using the specified
 	// target environment.
 	Deploy(repoID, pipeline int64, opt DeployOptions) (*Pipeline, error)
@@ -189,6 +225,9 @@ type Client interface {
 	// OrgList returns a list of all organizations.
 	OrgList(opt ListOptions) ([]*Org, error)
 
+	// OrgPatch updates an organization.
+	OrgPatch(orgID int64, org *OrgPatch) (*Org, error)
+
 	// OrgSecret returns an organization secret by name.
 	OrgSecret(orgID int64, secret string) (*Secret, error)
 
@@ -222,12 +261,30 @@ type Client interface {
 	// QueueInfo returns the queue state.
 	QueueInfo() (*Info, error)
 
+	// ServerInfo returns the server's version, forge kind and enabled features.
+	ServerInfo() (*ServerInfo, error)
+
 	// LogLevel returns the current logging level.
 	LogLevel() (*LogLevel, error)
 
 	// SetLogLevel sets the server's logging level.
 	SetLogLevel(logLevel *LogLevel) (*LogLevel, error)
 
+	// Maintenance returns whether the server's maintenance mode is enabled.
+	Maintenance() (*Maintenance, error)
+
+	// SetMaintenance enables or disables the server's maintenance mode.
+	SetMaintenance(maintenance *Maintenance) (*Maintenance, error)
+
+	// MigrationStatus returns all store migrations and whether they were applied.
+	MigrationStatus() ([]*Migration, error)
+
+	// MigrationRollback reverts the last applied store migration.
+	MigrationRollback(version string) error
+
+	// RotateJWTSecret rotates the secret the server signs its tokens with.
+	RotateJWTSecret(gracePeriod time.Duration) error
+
 	// CronList list all cron jobs of a repo.
 	CronList(repoID int64, opt CronListOptions) ([]*Cron, error)
 
@@ -243,6 +300,9 @@ type Client interface {
 	// CronUpdate update an existing cron job of a repo.
 	CronUpdate(repoID int64, cron *Cron) (*Cron, error)
 
+	// CronRun runs a cron job of a repo now and returns the created pipeline.
+	CronRun(repoID, cronID int64) (*Pipeline, error)
+
 	// AgentList returns a list of all registered agents.
 	AgentList() ([]*Agent, error)
 
@@ -260,4 +320,13 @@ type Client interface {
 
 	// AgentTasksList returns a list of all tasks executed by an agent.
 	AgentTasksList(int64) ([]*Task, error)
+
+	// AgentTokenList returns the shared agent tokens.
+	AgentTokenList() ([]*AgentToken, error)
+
+	// AgentTokenCreate creates a new shared agent token.
+	AgentTokenCreate() (*AgentToken, error)
+
+	// AgentTokenDelete retires a shared agent token.
+	AgentTokenDelete(tokenID int64, force bool) error
 }
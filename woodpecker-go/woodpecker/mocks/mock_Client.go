@@ -5,7 +5,9 @@
 package mocks
 
 import (
+	"context"
 	"net/http"
+	"time"
 
 	mock "github.com/stretchr/testify/mock"
 	"go.woodpecker-ci.org/woodpecker/v3/woodpecker-go/woodpecker"
@@ -330,6 +332,173 @@ func (_c *MockClient_AgentTasksList_Call) RunAndReturn(run func(n int64) ([]*woo
 	return _c
 }
 
+// AgentTokenCreate provides a mock function for the type MockClient
+func (_mock *MockClient) AgentTokenCreate() (*woodpecker.AgentToken, error) {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AgentTokenCreate")
+	}
+
+	var r0 *woodpecker.AgentToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() (*woodpecker.AgentToken, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() *woodpecker.AgentToken); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.AgentToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_AgentTokenCreate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AgentTokenCreate'
+type MockClient_AgentTokenCreate_Call struct {
+	*mock.Call
+}
+
+// AgentTokenCreate is a helper method to define mock.On call
+func (_e *MockClient_Expecter) AgentTokenCreate() *MockClient_AgentTokenCreate_Call {
+	return &MockClient_AgentTokenCreate_Call{Call: _e.mock.On("AgentTokenCreate")}
+}
+
+func (_c *MockClient_AgentTokenCreate_Call) Run(run func()) *MockClient_AgentTokenCreate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClient_AgentTokenCreate_Call) Return(agentToken *woodpecker.AgentToken, err error) *MockClient_AgentTokenCreate_Call {
+	_c.Call.Return(agentToken, err)
+	return _c
+}
+
+func (_c *MockClient_AgentTokenCreate_Call) RunAndReturn(run func() (*woodpecker.AgentToken, error)) *MockClient_AgentTokenCreate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AgentTokenDelete provides a mock function for the type MockClient
+func (_mock *MockClient) AgentTokenDelete(tokenID int64, force bool) error {
+	ret := _mock.Called(tokenID, force)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AgentTokenDelete")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(int64, bool) error); ok {
+		r0 = returnFunc(tokenID, force)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockClient_AgentTokenDelete_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AgentTokenDelete'
+type MockClient_AgentTokenDelete_Call struct {
+	*mock.Call
+}
+
+// AgentTokenDelete is a helper method to define mock.On call
+//   - tokenID int64
+//   - force bool
+func (_e *MockClient_Expecter) AgentTokenDelete(tokenID interface{}, force interface{}) *MockClient_AgentTokenDelete_Call {
+	return &MockClient_AgentTokenDelete_Call{Call: _e.mock.On("AgentTokenDelete", tokenID, force)}
+}
+
+func (_c *MockClient_AgentTokenDelete_Call) Run(run func(tokenID int64, force bool)) *MockClient_AgentTokenDelete_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int64
+		if args[0] != nil {
+			arg0 = args[0].(int64)
+		}
+		var arg1 bool
+		if args[1] != nil {
+			arg1 = args[1].(bool)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_AgentTokenDelete_Call) Return(err error) *MockClient_AgentTokenDelete_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockClient_AgentTokenDelete_Call) RunAndReturn(run func(tokenID int64, force bool) error) *MockClient_AgentTokenDelete_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AgentTokenList provides a mock function for the type MockClient
+func (_mock *MockClient) AgentTokenList() ([]*woodpecker.AgentToken, error) {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for AgentTokenList")
+	}
+
+	var r0 []*woodpecker.AgentToken
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() ([]*woodpecker.AgentToken, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() []*woodpecker.AgentToken); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*woodpecker.AgentToken)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_AgentTokenList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AgentTokenList'
+type MockClient_AgentTokenList_Call struct {
+	*mock.Call
+}
+
+// AgentTokenList is a helper method to define mock.On call
+func (_e *MockClient_Expecter) AgentTokenList() *MockClient_AgentTokenList_Call {
+	return &MockClient_AgentTokenList_Call{Call: _e.mock.On("AgentTokenList")}
+}
+
+func (_c *MockClient_AgentTokenList_Call) Run(run func()) *MockClient_AgentTokenList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClient_AgentTokenList_Call) Return(agentTokens []*woodpecker.AgentToken, err error) *MockClient_AgentTokenList_Call {
+	_c.Call.Return(agentTokens, err)
+	return _c
+}
+
+func (_c *MockClient_AgentTokenList_Call) RunAndReturn(run func() ([]*woodpecker.AgentToken, error)) *MockClient_AgentTokenList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AgentUpdate provides a mock function for the type MockClient
 func (_mock *MockClient) AgentUpdate(agent *woodpecker.Agent) (*woodpecker.Agent, error) {
 	ret := _mock.Called(agent)
@@ -653,6 +822,74 @@ func (_c *MockClient_CronList_Call) RunAndReturn(run func(repoID int64, opt wood
 	return _c
 }
 
+// CronRun provides a mock function for the type MockClient
+func (_mock *MockClient) CronRun(repoID int64, cronID int64) (*woodpecker.Pipeline, error) {
+	ret := _mock.Called(repoID, cronID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CronRun")
+	}
+
+	var r0 *woodpecker.Pipeline
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(int64, int64) (*woodpecker.Pipeline, error)); ok {
+		return returnFunc(repoID, cronID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(int64, int64) *woodpecker.Pipeline); ok {
+		r0 = returnFunc(repoID, cronID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.Pipeline)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(int64, int64) error); ok {
+		r1 = returnFunc(repoID, cronID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_CronRun_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CronRun'
+type MockClient_CronRun_Call struct {
+	*mock.Call
+}
+
+// CronRun is a helper method to define mock.On call
+//   - repoID int64
+//   - cronID int64
+func (_e *MockClient_Expecter) CronRun(repoID interface{}, cronID interface{}) *MockClient_CronRun_Call {
+	return &MockClient_CronRun_Call{Call: _e.mock.On("CronRun", repoID, cronID)}
+}
+
+func (_c *MockClient_CronRun_Call) Run(run func(repoID int64, cronID int64)) *MockClient_CronRun_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int64
+		if args[0] != nil {
+			arg0 = args[0].(int64)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_CronRun_Call) Return(pipeline *woodpecker.Pipeline, err error) *MockClient_CronRun_Call {
+	_c.Call.Return(pipeline, err)
+	return _c
+}
+
+func (_c *MockClient_CronRun_Call) RunAndReturn(run func(repoID int64, cronID int64) (*woodpecker.Pipeline, error)) *MockClient_CronRun_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // CronUpdate provides a mock function for the type MockClient
 func (_mock *MockClient) CronUpdate(repoID int64, cron *woodpecker.Cron) (*woodpecker.Cron, error) {
 	ret := _mock.Called(repoID, cron)
@@ -1505,112 +1742,94 @@ func (_c *MockClient_LogsPurge_Call) RunAndReturn(run func(repoID int64, pipelin
 	return _c
 }
 
-// Org provides a mock function for the type MockClient
-func (_mock *MockClient) Org(orgID int64) (*woodpecker.Org, error) {
-	ret := _mock.Called(orgID)
+// Maintenance provides a mock function for the type MockClient
+func (_mock *MockClient) Maintenance() (*woodpecker.Maintenance, error) {
+	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for Org")
+		panic("no return value specified for Maintenance")
 	}
 
-	var r0 *woodpecker.Org
+	var r0 *woodpecker.Maintenance
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(int64) (*woodpecker.Org, error)); ok {
-		return returnFunc(orgID)
+	if returnFunc, ok := ret.Get(0).(func() (*woodpecker.Maintenance, error)); ok {
+		return returnFunc()
 	}
-	if returnFunc, ok := ret.Get(0).(func(int64) *woodpecker.Org); ok {
-		r0 = returnFunc(orgID)
+	if returnFunc, ok := ret.Get(0).(func() *woodpecker.Maintenance); ok {
+		r0 = returnFunc()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*woodpecker.Org)
+			r0 = ret.Get(0).(*woodpecker.Maintenance)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(int64) error); ok {
-		r1 = returnFunc(orgID)
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockClient_Org_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Org'
-type MockClient_Org_Call struct {
+// MockClient_Maintenance_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Maintenance'
+type MockClient_Maintenance_Call struct {
 	*mock.Call
 }
 
-// Org is a helper method to define mock.On call
-//   - orgID int64
-func (_e *MockClient_Expecter) Org(orgID interface{}) *MockClient_Org_Call {
-	return &MockClient_Org_Call{Call: _e.mock.On("Org", orgID)}
+// Maintenance is a helper method to define mock.On call
+func (_e *MockClient_Expecter) Maintenance() *MockClient_Maintenance_Call {
+	return &MockClient_Maintenance_Call{Call: _e.mock.On("Maintenance")}
 }
 
-func (_c *MockClient_Org_Call) Run(run func(orgID int64)) *MockClient_Org_Call {
+func (_c *MockClient_Maintenance_Call) Run(run func()) *MockClient_Maintenance_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 int64
-		if args[0] != nil {
-			arg0 = args[0].(int64)
-		}
-		run(
-			arg0,
-		)
+		run()
 	})
 	return _c
 }
 
-func (_c *MockClient_Org_Call) Return(org *woodpecker.Org, err error) *MockClient_Org_Call {
-	_c.Call.Return(org, err)
+func (_c *MockClient_Maintenance_Call) Return(maintenance *woodpecker.Maintenance, err error) *MockClient_Maintenance_Call {
+	_c.Call.Return(maintenance, err)
 	return _c
 }
 
-func (_c *MockClient_Org_Call) RunAndReturn(run func(orgID int64) (*woodpecker.Org, error)) *MockClient_Org_Call {
+func (_c *MockClient_Maintenance_Call) RunAndReturn(run func() (*woodpecker.Maintenance, error)) *MockClient_Maintenance_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// OrgList provides a mock function for the type MockClient
-func (_mock *MockClient) OrgList(opt woodpecker.ListOptions) ([]*woodpecker.Org, error) {
-	ret := _mock.Called(opt)
+// MigrationRollback provides a mock function for the type MockClient
+func (_mock *MockClient) MigrationRollback(version string) error {
+	ret := _mock.Called(version)
 
 	if len(ret) == 0 {
-		panic("no return value specified for OrgList")
+		panic("no return value specified for MigrationRollback")
 	}
 
-	var r0 []*woodpecker.Org
-	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(woodpecker.ListOptions) ([]*woodpecker.Org, error)); ok {
-		return returnFunc(opt)
-	}
-	if returnFunc, ok := ret.Get(0).(func(woodpecker.ListOptions) []*woodpecker.Org); ok {
-		r0 = returnFunc(opt)
-	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*woodpecker.Org)
-		}
-	}
-	if returnFunc, ok := ret.Get(1).(func(woodpecker.ListOptions) error); ok {
-		r1 = returnFunc(opt)
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string) error); ok {
+		r0 = returnFunc(version)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
-	return r0, r1
+	return r0
 }
 
-// MockClient_OrgList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrgList'
-type MockClient_OrgList_Call struct {
+// MockClient_MigrationRollback_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MigrationRollback'
+type MockClient_MigrationRollback_Call struct {
 	*mock.Call
 }
 
-// OrgList is a helper method to define mock.On call
-//   - opt woodpecker.ListOptions
-func (_e *MockClient_Expecter) OrgList(opt interface{}) *MockClient_OrgList_Call {
-	return &MockClient_OrgList_Call{Call: _e.mock.On("OrgList", opt)}
+// MigrationRollback is a helper method to define mock.On call
+//   - version string
+func (_e *MockClient_Expecter) MigrationRollback(version interface{}) *MockClient_MigrationRollback_Call {
+	return &MockClient_MigrationRollback_Call{Call: _e.mock.On("MigrationRollback", version)}
 }
 
-func (_c *MockClient_OrgList_Call) Run(run func(opt woodpecker.ListOptions)) *MockClient_OrgList_Call {
+func (_c *MockClient_MigrationRollback_Call) Run(run func(version string)) *MockClient_MigrationRollback_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 woodpecker.ListOptions
+		var arg0 string
 		if args[0] != nil {
-			arg0 = args[0].(woodpecker.ListOptions)
+			arg0 = args[0].(string)
 		}
 		run(
 			arg0,
@@ -1619,28 +1838,207 @@ func (_c *MockClient_OrgList_Call) Run(run func(opt woodpecker.ListOptions)) *Mo
 	return _c
 }
 
-func (_c *MockClient_OrgList_Call) Return(orgs []*woodpecker.Org, err error) *MockClient_OrgList_Call {
-	_c.Call.Return(orgs, err)
+func (_c *MockClient_MigrationRollback_Call) Return(err error) *MockClient_MigrationRollback_Call {
+	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockClient_OrgList_Call) RunAndReturn(run func(opt woodpecker.ListOptions) ([]*woodpecker.Org, error)) *MockClient_OrgList_Call {
+func (_c *MockClient_MigrationRollback_Call) RunAndReturn(run func(version string) error) *MockClient_MigrationRollback_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// OrgLookup provides a mock function for the type MockClient
-func (_mock *MockClient) OrgLookup(orgName string) (*woodpecker.Org, error) {
-	ret := _mock.Called(orgName)
+// MigrationStatus provides a mock function for the type MockClient
+func (_mock *MockClient) MigrationStatus() ([]*woodpecker.Migration, error) {
+	ret := _mock.Called()
 
 	if len(ret) == 0 {
-		panic("no return value specified for OrgLookup")
+		panic("no return value specified for MigrationStatus")
 	}
 
-	var r0 *woodpecker.Org
+	var r0 []*woodpecker.Migration
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(string) (*woodpecker.Org, error)); ok {
-		return returnFunc(orgName)
+	if returnFunc, ok := ret.Get(0).(func() ([]*woodpecker.Migration, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() []*woodpecker.Migration); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*woodpecker.Migration)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_MigrationStatus_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'MigrationStatus'
+type MockClient_MigrationStatus_Call struct {
+	*mock.Call
+}
+
+// MigrationStatus is a helper method to define mock.On call
+func (_e *MockClient_Expecter) MigrationStatus() *MockClient_MigrationStatus_Call {
+	return &MockClient_MigrationStatus_Call{Call: _e.mock.On("MigrationStatus")}
+}
+
+func (_c *MockClient_MigrationStatus_Call) Run(run func()) *MockClient_MigrationStatus_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClient_MigrationStatus_Call) Return(migrations []*woodpecker.Migration, err error) *MockClient_MigrationStatus_Call {
+	_c.Call.Return(migrations, err)
+	return _c
+}
+
+func (_c *MockClient_MigrationStatus_Call) RunAndReturn(run func() ([]*woodpecker.Migration, error)) *MockClient_MigrationStatus_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Org provides a mock function for the type MockClient
+func (_mock *MockClient) Org(orgID int64) (*woodpecker.Org, error) {
+	ret := _mock.Called(orgID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Org")
+	}
+
+	var r0 *woodpecker.Org
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(int64) (*woodpecker.Org, error)); ok {
+		return returnFunc(orgID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(int64) *woodpecker.Org); ok {
+		r0 = returnFunc(orgID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.Org)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = returnFunc(orgID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_Org_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Org'
+type MockClient_Org_Call struct {
+	*mock.Call
+}
+
+// Org is a helper method to define mock.On call
+//   - orgID int64
+func (_e *MockClient_Expecter) Org(orgID interface{}) *MockClient_Org_Call {
+	return &MockClient_Org_Call{Call: _e.mock.On("Org", orgID)}
+}
+
+func (_c *MockClient_Org_Call) Run(run func(orgID int64)) *MockClient_Org_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int64
+		if args[0] != nil {
+			arg0 = args[0].(int64)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_Org_Call) Return(org *woodpecker.Org, err error) *MockClient_Org_Call {
+	_c.Call.Return(org, err)
+	return _c
+}
+
+func (_c *MockClient_Org_Call) RunAndReturn(run func(orgID int64) (*woodpecker.Org, error)) *MockClient_Org_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrgList provides a mock function for the type MockClient
+func (_mock *MockClient) OrgList(opt woodpecker.ListOptions) ([]*woodpecker.Org, error) {
+	ret := _mock.Called(opt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrgList")
+	}
+
+	var r0 []*woodpecker.Org
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(woodpecker.ListOptions) ([]*woodpecker.Org, error)); ok {
+		return returnFunc(opt)
+	}
+	if returnFunc, ok := ret.Get(0).(func(woodpecker.ListOptions) []*woodpecker.Org); ok {
+		r0 = returnFunc(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*woodpecker.Org)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(woodpecker.ListOptions) error); ok {
+		r1 = returnFunc(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_OrgList_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrgList'
+type MockClient_OrgList_Call struct {
+	*mock.Call
+}
+
+// OrgList is a helper method to define mock.On call
+//   - opt woodpecker.ListOptions
+func (_e *MockClient_Expecter) OrgList(opt interface{}) *MockClient_OrgList_Call {
+	return &MockClient_OrgList_Call{Call: _e.mock.On("OrgList", opt)}
+}
+
+func (_c *MockClient_OrgList_Call) Run(run func(opt woodpecker.ListOptions)) *MockClient_OrgList_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 woodpecker.ListOptions
+		if args[0] != nil {
+			arg0 = args[0].(woodpecker.ListOptions)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_OrgList_Call) Return(orgs []*woodpecker.Org, err error) *MockClient_OrgList_Call {
+	_c.Call.Return(orgs, err)
+	return _c
+}
+
+func (_c *MockClient_OrgList_Call) RunAndReturn(run func(opt woodpecker.ListOptions) ([]*woodpecker.Org, error)) *MockClient_OrgList_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// OrgLookup provides a mock function for the type MockClient
+func (_mock *MockClient) OrgLookup(orgName string) (*woodpecker.Org, error) {
+	ret := _mock.Called(orgName)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrgLookup")
+	}
+
+	var r0 *woodpecker.Org
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (*woodpecker.Org, error)); ok {
+		return returnFunc(orgName)
 	}
 	if returnFunc, ok := ret.Get(0).(func(string) *woodpecker.Org); ok {
 		r0 = returnFunc(orgName)
@@ -1691,6 +2089,74 @@ func (_c *MockClient_OrgLookup_Call) RunAndReturn(run func(orgName string) (*woo
 	return _c
 }
 
+// OrgPatch provides a mock function for the type MockClient
+func (_mock *MockClient) OrgPatch(orgID int64, org *woodpecker.OrgPatch) (*woodpecker.Org, error) {
+	ret := _mock.Called(orgID, org)
+
+	if len(ret) == 0 {
+		panic("no return value specified for OrgPatch")
+	}
+
+	var r0 *woodpecker.Org
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(int64, *woodpecker.OrgPatch) (*woodpecker.Org, error)); ok {
+		return returnFunc(orgID, org)
+	}
+	if returnFunc, ok := ret.Get(0).(func(int64, *woodpecker.OrgPatch) *woodpecker.Org); ok {
+		r0 = returnFunc(orgID, org)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.Org)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(int64, *woodpecker.OrgPatch) error); ok {
+		r1 = returnFunc(orgID, org)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_OrgPatch_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'OrgPatch'
+type MockClient_OrgPatch_Call struct {
+	*mock.Call
+}
+
+// OrgPatch is a helper method to define mock.On call
+//   - orgID int64
+//   - org *woodpecker.OrgPatch
+func (_e *MockClient_Expecter) OrgPatch(orgID interface{}, org interface{}) *MockClient_OrgPatch_Call {
+	return &MockClient_OrgPatch_Call{Call: _e.mock.On("OrgPatch", orgID, org)}
+}
+
+func (_c *MockClient_OrgPatch_Call) Run(run func(orgID int64, org *woodpecker.OrgPatch)) *MockClient_OrgPatch_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int64
+		if args[0] != nil {
+			arg0 = args[0].(int64)
+		}
+		var arg1 *woodpecker.OrgPatch
+		if args[1] != nil {
+			arg1 = args[1].(*woodpecker.OrgPatch)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_OrgPatch_Call) Return(org1 *woodpecker.Org, err error) *MockClient_OrgPatch_Call {
+	_c.Call.Return(org1, err)
+	return _c
+}
+
+func (_c *MockClient_OrgPatch_Call) RunAndReturn(run func(orgID int64, org *woodpecker.OrgPatch) (*woodpecker.Org, error)) *MockClient_OrgPatch_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // OrgRegistry provides a mock function for the type MockClient
 func (_mock *MockClient) OrgRegistry(orgID int64, registry string) (*woodpecker.Registry, error) {
 	ret := _mock.Called(orgID, registry)
@@ -2937,84 +3403,152 @@ func (_c *MockClient_PipelineQueue_Call) RunAndReturn(run func() ([]*woodpecker.
 	return _c
 }
 
-// PipelineStart provides a mock function for the type MockClient
-func (_mock *MockClient) PipelineStart(repoID int64, num int64, opt woodpecker.PipelineStartOptions) (*woodpecker.Pipeline, error) {
-	ret := _mock.Called(repoID, num, opt)
+// PipelineQuery provides a mock function for the type MockClient
+func (_mock *MockClient) PipelineQuery(query *woodpecker.PipelineQuery, opt woodpecker.ListOptions) ([]*woodpecker.Feed, error) {
+	ret := _mock.Called(query, opt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for PipelineStart")
+		panic("no return value specified for PipelineQuery")
 	}
 
-	var r0 *woodpecker.Pipeline
+	var r0 []*woodpecker.Feed
 	var r1 error
-	if returnFunc, ok := ret.Get(0).(func(int64, int64, woodpecker.PipelineStartOptions) (*woodpecker.Pipeline, error)); ok {
-		return returnFunc(repoID, num, opt)
+	if returnFunc, ok := ret.Get(0).(func(*woodpecker.PipelineQuery, woodpecker.ListOptions) ([]*woodpecker.Feed, error)); ok {
+		return returnFunc(query, opt)
 	}
-	if returnFunc, ok := ret.Get(0).(func(int64, int64, woodpecker.PipelineStartOptions) *woodpecker.Pipeline); ok {
-		r0 = returnFunc(repoID, num, opt)
+	if returnFunc, ok := ret.Get(0).(func(*woodpecker.PipelineQuery, woodpecker.ListOptions) []*woodpecker.Feed); ok {
+		r0 = returnFunc(query, opt)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*woodpecker.Pipeline)
+			r0 = ret.Get(0).([]*woodpecker.Feed)
 		}
 	}
-	if returnFunc, ok := ret.Get(1).(func(int64, int64, woodpecker.PipelineStartOptions) error); ok {
-		r1 = returnFunc(repoID, num, opt)
+	if returnFunc, ok := ret.Get(1).(func(*woodpecker.PipelineQuery, woodpecker.ListOptions) error); ok {
+		r1 = returnFunc(query, opt)
 	} else {
 		r1 = ret.Error(1)
 	}
 	return r0, r1
 }
 
-// MockClient_PipelineStart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PipelineStart'
-type MockClient_PipelineStart_Call struct {
+// MockClient_PipelineQuery_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PipelineQuery'
+type MockClient_PipelineQuery_Call struct {
 	*mock.Call
 }
 
-// PipelineStart is a helper method to define mock.On call
-//   - repoID int64
-//   - num int64
-//   - opt woodpecker.PipelineStartOptions
-func (_e *MockClient_Expecter) PipelineStart(repoID interface{}, num interface{}, opt interface{}) *MockClient_PipelineStart_Call {
-	return &MockClient_PipelineStart_Call{Call: _e.mock.On("PipelineStart", repoID, num, opt)}
+// PipelineQuery is a helper method to define mock.On call
+//   - query *woodpecker.PipelineQuery
+//   - opt woodpecker.ListOptions
+func (_e *MockClient_Expecter) PipelineQuery(query interface{}, opt interface{}) *MockClient_PipelineQuery_Call {
+	return &MockClient_PipelineQuery_Call{Call: _e.mock.On("PipelineQuery", query, opt)}
 }
 
-func (_c *MockClient_PipelineStart_Call) Run(run func(repoID int64, num int64, opt woodpecker.PipelineStartOptions)) *MockClient_PipelineStart_Call {
+func (_c *MockClient_PipelineQuery_Call) Run(run func(query *woodpecker.PipelineQuery, opt woodpecker.ListOptions)) *MockClient_PipelineQuery_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		var arg0 int64
+		var arg0 *woodpecker.PipelineQuery
 		if args[0] != nil {
-			arg0 = args[0].(int64)
+			arg0 = args[0].(*woodpecker.PipelineQuery)
 		}
-		var arg1 int64
+		var arg1 woodpecker.ListOptions
 		if args[1] != nil {
-			arg1 = args[1].(int64)
-		}
-		var arg2 woodpecker.PipelineStartOptions
-		if args[2] != nil {
-			arg2 = args[2].(woodpecker.PipelineStartOptions)
+			arg1 = args[1].(woodpecker.ListOptions)
 		}
 		run(
 			arg0,
 			arg1,
-			arg2,
 		)
 	})
 	return _c
 }
 
-func (_c *MockClient_PipelineStart_Call) Return(pipeline *woodpecker.Pipeline, err error) *MockClient_PipelineStart_Call {
-	_c.Call.Return(pipeline, err)
+func (_c *MockClient_PipelineQuery_Call) Return(feeds []*woodpecker.Feed, err error) *MockClient_PipelineQuery_Call {
+	_c.Call.Return(feeds, err)
 	return _c
 }
 
-func (_c *MockClient_PipelineStart_Call) RunAndReturn(run func(repoID int64, num int64, opt woodpecker.PipelineStartOptions) (*woodpecker.Pipeline, error)) *MockClient_PipelineStart_Call {
+func (_c *MockClient_PipelineQuery_Call) RunAndReturn(run func(query *woodpecker.PipelineQuery, opt woodpecker.ListOptions) ([]*woodpecker.Feed, error)) *MockClient_PipelineQuery_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// PipelineStop provides a mock function for the type MockClient
-func (_mock *MockClient) PipelineStop(repoID int64, pipeline int64) error {
-	ret := _mock.Called(repoID, pipeline)
-
+// PipelineStart provides a mock function for the type MockClient
+func (_mock *MockClient) PipelineStart(repoID int64, num int64, opt woodpecker.PipelineStartOptions) (*woodpecker.Pipeline, error) {
+	ret := _mock.Called(repoID, num, opt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PipelineStart")
+	}
+
+	var r0 *woodpecker.Pipeline
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(int64, int64, woodpecker.PipelineStartOptions) (*woodpecker.Pipeline, error)); ok {
+		return returnFunc(repoID, num, opt)
+	}
+	if returnFunc, ok := ret.Get(0).(func(int64, int64, woodpecker.PipelineStartOptions) *woodpecker.Pipeline); ok {
+		r0 = returnFunc(repoID, num, opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.Pipeline)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(int64, int64, woodpecker.PipelineStartOptions) error); ok {
+		r1 = returnFunc(repoID, num, opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_PipelineStart_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PipelineStart'
+type MockClient_PipelineStart_Call struct {
+	*mock.Call
+}
+
+// PipelineStart is a helper method to define mock.On call
+//   - repoID int64
+//   - num int64
+//   - opt woodpecker.PipelineStartOptions
+func (_e *MockClient_Expecter) PipelineStart(repoID interface{}, num interface{}, opt interface{}) *MockClient_PipelineStart_Call {
+	return &MockClient_PipelineStart_Call{Call: _e.mock.On("PipelineStart", repoID, num, opt)}
+}
+
+func (_c *MockClient_PipelineStart_Call) Run(run func(repoID int64, num int64, opt woodpecker.PipelineStartOptions)) *MockClient_PipelineStart_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int64
+		if args[0] != nil {
+			arg0 = args[0].(int64)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		var arg2 woodpecker.PipelineStartOptions
+		if args[2] != nil {
+			arg2 = args[2].(woodpecker.PipelineStartOptions)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_PipelineStart_Call) Return(pipeline *woodpecker.Pipeline, err error) *MockClient_PipelineStart_Call {
+	_c.Call.Return(pipeline, err)
+	return _c
+}
+
+func (_c *MockClient_PipelineStart_Call) RunAndReturn(run func(repoID int64, num int64, opt woodpecker.PipelineStartOptions) (*woodpecker.Pipeline, error)) *MockClient_PipelineStart_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PipelineStop provides a mock function for the type MockClient
+func (_mock *MockClient) PipelineStop(repoID int64, pipeline int64) error {
+	ret := _mock.Called(repoID, pipeline)
+
 	if len(ret) == 0 {
 		panic("no return value specified for PipelineStop")
 	}
@@ -3627,6 +4161,114 @@ func (_c *MockClient_RepoDel_Call) RunAndReturn(run func(repoID int64) error) *M
 	return _c
 }
 
+// RepoHookPrune provides a mock function for the type MockClient
+func (_mock *MockClient) RepoHookPrune(repoID int64) error {
+	ret := _mock.Called(repoID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RepoHookPrune")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = returnFunc(repoID)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockClient_RepoHookPrune_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RepoHookPrune'
+type MockClient_RepoHookPrune_Call struct {
+	*mock.Call
+}
+
+// RepoHookPrune is a helper method to define mock.On call
+//   - repoID int64
+func (_e *MockClient_Expecter) RepoHookPrune(repoID interface{}) *MockClient_RepoHookPrune_Call {
+	return &MockClient_RepoHookPrune_Call{Call: _e.mock.On("RepoHookPrune", repoID)}
+}
+
+func (_c *MockClient_RepoHookPrune_Call) Run(run func(repoID int64)) *MockClient_RepoHookPrune_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int64
+		if args[0] != nil {
+			arg0 = args[0].(int64)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_RepoHookPrune_Call) Return(err error) *MockClient_RepoHookPrune_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockClient_RepoHookPrune_Call) RunAndReturn(run func(repoID int64) error) *MockClient_RepoHookPrune_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RepoHookRotate provides a mock function for the type MockClient
+func (_mock *MockClient) RepoHookRotate(repoID int64, gracePeriod time.Duration) error {
+	ret := _mock.Called(repoID, gracePeriod)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RepoHookRotate")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(int64, time.Duration) error); ok {
+		r0 = returnFunc(repoID, gracePeriod)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockClient_RepoHookRotate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RepoHookRotate'
+type MockClient_RepoHookRotate_Call struct {
+	*mock.Call
+}
+
+// RepoHookRotate is a helper method to define mock.On call
+//   - repoID int64
+//   - gracePeriod time.Duration
+func (_e *MockClient_Expecter) RepoHookRotate(repoID interface{}, gracePeriod interface{}) *MockClient_RepoHookRotate_Call {
+	return &MockClient_RepoHookRotate_Call{Call: _e.mock.On("RepoHookRotate", repoID, gracePeriod)}
+}
+
+func (_c *MockClient_RepoHookRotate_Call) Run(run func(repoID int64, gracePeriod time.Duration)) *MockClient_RepoHookRotate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 int64
+		if args[0] != nil {
+			arg0 = args[0].(int64)
+		}
+		var arg1 time.Duration
+		if args[1] != nil {
+			arg1 = args[1].(time.Duration)
+		}
+		run(
+			arg0,
+			arg1,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_RepoHookRotate_Call) Return(err error) *MockClient_RepoHookRotate_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockClient_RepoHookRotate_Call) RunAndReturn(run func(repoID int64, gracePeriod time.Duration) error) *MockClient_RepoHookRotate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // RepoList provides a mock function for the type MockClient
 func (_mock *MockClient) RepoList(opt woodpecker.RepoListOptions) ([]*woodpecker.Repo, error) {
 	ret := _mock.Called(opt)
@@ -3939,20 +4581,31 @@ func (_c *MockClient_RepoPost_Call) RunAndReturn(run func(opt woodpecker.RepoPos
 }
 
 // RepoRepair provides a mock function for the type MockClient
-func (_mock *MockClient) RepoRepair(repoID int64) error {
+func (_mock *MockClient) RepoRepair(repoID int64) (*woodpecker.RepoRepairReport, error) {
 	ret := _mock.Called(repoID)
 
 	if len(ret) == 0 {
 		panic("no return value specified for RepoRepair")
 	}
 
-	var r0 error
-	if returnFunc, ok := ret.Get(0).(func(int64) error); ok {
+	var r0 *woodpecker.RepoRepairReport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(int64) (*woodpecker.RepoRepairReport, error)); ok {
+		return returnFunc(repoID)
+	}
+	if returnFunc, ok := ret.Get(0).(func(int64) *woodpecker.RepoRepairReport); ok {
 		r0 = returnFunc(repoID)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.RepoRepairReport)
+		}
 	}
-	return r0
+	if returnFunc, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = returnFunc(repoID)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
 }
 
 // MockClient_RepoRepair_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RepoRepair'
@@ -3979,12 +4632,125 @@ func (_c *MockClient_RepoRepair_Call) Run(run func(repoID int64)) *MockClient_Re
 	return _c
 }
 
-func (_c *MockClient_RepoRepair_Call) Return(err error) *MockClient_RepoRepair_Call {
+func (_c *MockClient_RepoRepair_Call) Return(repoRepairReport *woodpecker.RepoRepairReport, err error) *MockClient_RepoRepair_Call {
+	_c.Call.Return(repoRepairReport, err)
+	return _c
+}
+
+func (_c *MockClient_RepoRepair_Call) RunAndReturn(run func(repoID int64) (*woodpecker.RepoRepairReport, error)) *MockClient_RepoRepair_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RepoSync provides a mock function for the type MockClient
+func (_mock *MockClient) RepoSync(opt woodpecker.RepoSyncOptions) (*woodpecker.RepoSyncReport, error) {
+	ret := _mock.Called(opt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RepoSync")
+	}
+
+	var r0 *woodpecker.RepoSyncReport
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(woodpecker.RepoSyncOptions) (*woodpecker.RepoSyncReport, error)); ok {
+		return returnFunc(opt)
+	}
+	if returnFunc, ok := ret.Get(0).(func(woodpecker.RepoSyncOptions) *woodpecker.RepoSyncReport); ok {
+		r0 = returnFunc(opt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.RepoSyncReport)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(woodpecker.RepoSyncOptions) error); ok {
+		r1 = returnFunc(opt)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_RepoSync_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RepoSync'
+type MockClient_RepoSync_Call struct {
+	*mock.Call
+}
+
+// RepoSync is a helper method to define mock.On call
+//   - opt woodpecker.RepoSyncOptions
+func (_e *MockClient_Expecter) RepoSync(opt interface{}) *MockClient_RepoSync_Call {
+	return &MockClient_RepoSync_Call{Call: _e.mock.On("RepoSync", opt)}
+}
+
+func (_c *MockClient_RepoSync_Call) Run(run func(opt woodpecker.RepoSyncOptions)) *MockClient_RepoSync_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 woodpecker.RepoSyncOptions
+		if args[0] != nil {
+			arg0 = args[0].(woodpecker.RepoSyncOptions)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_RepoSync_Call) Return(repoSyncReport *woodpecker.RepoSyncReport, err error) *MockClient_RepoSync_Call {
+	_c.Call.Return(repoSyncReport, err)
+	return _c
+}
+
+func (_c *MockClient_RepoSync_Call) RunAndReturn(run func(opt woodpecker.RepoSyncOptions) (*woodpecker.RepoSyncReport, error)) *MockClient_RepoSync_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RotateJWTSecret provides a mock function for the type MockClient
+func (_mock *MockClient) RotateJWTSecret(gracePeriod time.Duration) error {
+	ret := _mock.Called(gracePeriod)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RotateJWTSecret")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(time.Duration) error); ok {
+		r0 = returnFunc(gracePeriod)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockClient_RotateJWTSecret_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RotateJWTSecret'
+type MockClient_RotateJWTSecret_Call struct {
+	*mock.Call
+}
+
+// RotateJWTSecret is a helper method to define mock.On call
+//   - gracePeriod time.Duration
+func (_e *MockClient_Expecter) RotateJWTSecret(gracePeriod interface{}) *MockClient_RotateJWTSecret_Call {
+	return &MockClient_RotateJWTSecret_Call{Call: _e.mock.On("RotateJWTSecret", gracePeriod)}
+}
+
+func (_c *MockClient_RotateJWTSecret_Call) Run(run func(gracePeriod time.Duration)) *MockClient_RotateJWTSecret_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 time.Duration
+		if args[0] != nil {
+			arg0 = args[0].(time.Duration)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_RotateJWTSecret_Call) Return(err error) *MockClient_RotateJWTSecret_Call {
 	_c.Call.Return(err)
 	return _c
 }
 
-func (_c *MockClient_RepoRepair_Call) RunAndReturn(run func(repoID int64) error) *MockClient_RepoRepair_Call {
+func (_c *MockClient_RotateJWTSecret_Call) RunAndReturn(run func(gracePeriod time.Duration) error) *MockClient_RotateJWTSecret_Call {
 	_c.Call.Return(run)
 	return _c
 }
@@ -4373,14 +5139,69 @@ func (_c *MockClient_Self_Call) RunAndReturn(run func() (*woodpecker.User, error
 	return _c
 }
 
-// SetAddress provides a mock function for the type MockClient
-func (_mock *MockClient) SetAddress(s string) {
-	_mock.Called(s)
-	return
-}
+// ServerInfo provides a mock function for the type MockClient
+func (_mock *MockClient) ServerInfo() (*woodpecker.ServerInfo, error) {
+	ret := _mock.Called()
 
-// MockClient_SetAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAddress'
-type MockClient_SetAddress_Call struct {
+	if len(ret) == 0 {
+		panic("no return value specified for ServerInfo")
+	}
+
+	var r0 *woodpecker.ServerInfo
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() (*woodpecker.ServerInfo, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() *woodpecker.ServerInfo); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.ServerInfo)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_ServerInfo_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ServerInfo'
+type MockClient_ServerInfo_Call struct {
+	*mock.Call
+}
+
+// ServerInfo is a helper method to define mock.On call
+func (_e *MockClient_Expecter) ServerInfo() *MockClient_ServerInfo_Call {
+	return &MockClient_ServerInfo_Call{Call: _e.mock.On("ServerInfo")}
+}
+
+func (_c *MockClient_ServerInfo_Call) Run(run func()) *MockClient_ServerInfo_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClient_ServerInfo_Call) Return(serverInfo *woodpecker.ServerInfo, err error) *MockClient_ServerInfo_Call {
+	_c.Call.Return(serverInfo, err)
+	return _c
+}
+
+func (_c *MockClient_ServerInfo_Call) RunAndReturn(run func() (*woodpecker.ServerInfo, error)) *MockClient_ServerInfo_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetAddress provides a mock function for the type MockClient
+func (_mock *MockClient) SetAddress(s string) {
+	_mock.Called(s)
+	return
+}
+
+// MockClient_SetAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetAddress'
+type MockClient_SetAddress_Call struct {
 	*mock.Call
 }
 
@@ -4515,6 +5336,68 @@ func (_c *MockClient_SetLogLevel_Call) RunAndReturn(run func(logLevel *woodpecke
 	return _c
 }
 
+// SetMaintenance provides a mock function for the type MockClient
+func (_mock *MockClient) SetMaintenance(maintenance *woodpecker.Maintenance) (*woodpecker.Maintenance, error) {
+	ret := _mock.Called(maintenance)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetMaintenance")
+	}
+
+	var r0 *woodpecker.Maintenance
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(*woodpecker.Maintenance) (*woodpecker.Maintenance, error)); ok {
+		return returnFunc(maintenance)
+	}
+	if returnFunc, ok := ret.Get(0).(func(*woodpecker.Maintenance) *woodpecker.Maintenance); ok {
+		r0 = returnFunc(maintenance)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.Maintenance)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(*woodpecker.Maintenance) error); ok {
+		r1 = returnFunc(maintenance)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_SetMaintenance_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetMaintenance'
+type MockClient_SetMaintenance_Call struct {
+	*mock.Call
+}
+
+// SetMaintenance is a helper method to define mock.On call
+//   - maintenance *woodpecker.Maintenance
+func (_e *MockClient_Expecter) SetMaintenance(maintenance interface{}) *MockClient_SetMaintenance_Call {
+	return &MockClient_SetMaintenance_Call{Call: _e.mock.On("SetMaintenance", maintenance)}
+}
+
+func (_c *MockClient_SetMaintenance_Call) Run(run func(maintenance *woodpecker.Maintenance)) *MockClient_SetMaintenance_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 *woodpecker.Maintenance
+		if args[0] != nil {
+			arg0 = args[0].(*woodpecker.Maintenance)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_SetMaintenance_Call) Return(maintenance1 *woodpecker.Maintenance, err error) *MockClient_SetMaintenance_Call {
+	_c.Call.Return(maintenance1, err)
+	return _c
+}
+
+func (_c *MockClient_SetMaintenance_Call) RunAndReturn(run func(maintenance *woodpecker.Maintenance) (*woodpecker.Maintenance, error)) *MockClient_SetMaintenance_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // StepLogEntries provides a mock function for the type MockClient
 func (_mock *MockClient) StepLogEntries(repoID int64, pipeline int64, stepID int64) ([]*woodpecker.LogEntry, error) {
 	ret := _mock.Called(repoID, pipeline, stepID)
@@ -4589,6 +5472,81 @@ func (_c *MockClient_StepLogEntries_Call) RunAndReturn(run func(repoID int64, pi
 	return _c
 }
 
+// StepLogStream provides a mock function for the type MockClient
+func (_mock *MockClient) StepLogStream(ctx context.Context, repoID int64, pipeline int64, stepID int64, fn func(*woodpecker.LogEntry) error) error {
+	ret := _mock.Called(ctx, repoID, pipeline, stepID, fn)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StepLogStream")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(context.Context, int64, int64, int64, func(*woodpecker.LogEntry) error) error); ok {
+		r0 = returnFunc(ctx, repoID, pipeline, stepID, fn)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockClient_StepLogStream_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StepLogStream'
+type MockClient_StepLogStream_Call struct {
+	*mock.Call
+}
+
+// StepLogStream is a helper method to define mock.On call
+//   - ctx context.Context
+//   - repoID int64
+//   - pipeline int64
+//   - stepID int64
+//   - fn func(*woodpecker.LogEntry) error
+func (_e *MockClient_Expecter) StepLogStream(ctx interface{}, repoID interface{}, pipeline interface{}, stepID interface{}, fn interface{}) *MockClient_StepLogStream_Call {
+	return &MockClient_StepLogStream_Call{Call: _e.mock.On("StepLogStream", ctx, repoID, pipeline, stepID, fn)}
+}
+
+func (_c *MockClient_StepLogStream_Call) Run(run func(ctx context.Context, repoID int64, pipeline int64, stepID int64, fn func(*woodpecker.LogEntry) error)) *MockClient_StepLogStream_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 context.Context
+		if args[0] != nil {
+			arg0 = args[0].(context.Context)
+		}
+		var arg1 int64
+		if args[1] != nil {
+			arg1 = args[1].(int64)
+		}
+		var arg2 int64
+		if args[2] != nil {
+			arg2 = args[2].(int64)
+		}
+		var arg3 int64
+		if args[3] != nil {
+			arg3 = args[3].(int64)
+		}
+		var arg4 func(*woodpecker.LogEntry) error
+		if args[4] != nil {
+			arg4 = args[4].(func(*woodpecker.LogEntry) error)
+		}
+		run(
+			arg0,
+			arg1,
+			arg2,
+			arg3,
+			arg4,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_StepLogStream_Call) Return(err error) *MockClient_StepLogStream_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockClient_StepLogStream_Call) RunAndReturn(run func(ctx context.Context, repoID int64, pipeline int64, stepID int64, fn func(*woodpecker.LogEntry) error) error) *MockClient_StepLogStream_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // StepLogsPurge provides a mock function for the type MockClient
 func (_mock *MockClient) StepLogsPurge(repoID int64, pipelineNumber int64, stepID int64) error {
 	ret := _mock.Called(repoID, pipelineNumber, stepID)
@@ -4652,6 +5610,245 @@ func (_c *MockClient_StepLogsPurge_Call) RunAndReturn(run func(repoID int64, pip
 	return _c
 }
 
+// TwoFactorActivate provides a mock function for the type MockClient
+func (_mock *MockClient) TwoFactorActivate(code string) (*woodpecker.TwoFactorRecoveryCodes, error) {
+	ret := _mock.Called(code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TwoFactorActivate")
+	}
+
+	var r0 *woodpecker.TwoFactorRecoveryCodes
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (*woodpecker.TwoFactorRecoveryCodes, error)); ok {
+		return returnFunc(code)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) *woodpecker.TwoFactorRecoveryCodes); ok {
+		r0 = returnFunc(code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.TwoFactorRecoveryCodes)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(code)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_TwoFactorActivate_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TwoFactorActivate'
+type MockClient_TwoFactorActivate_Call struct {
+	*mock.Call
+}
+
+// TwoFactorActivate is a helper method to define mock.On call
+//   - code string
+func (_e *MockClient_Expecter) TwoFactorActivate(code interface{}) *MockClient_TwoFactorActivate_Call {
+	return &MockClient_TwoFactorActivate_Call{Call: _e.mock.On("TwoFactorActivate", code)}
+}
+
+func (_c *MockClient_TwoFactorActivate_Call) Run(run func(code string)) *MockClient_TwoFactorActivate_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_TwoFactorActivate_Call) Return(twoFactorRecoveryCodes *woodpecker.TwoFactorRecoveryCodes, err error) *MockClient_TwoFactorActivate_Call {
+	_c.Call.Return(twoFactorRecoveryCodes, err)
+	return _c
+}
+
+func (_c *MockClient_TwoFactorActivate_Call) RunAndReturn(run func(code string) (*woodpecker.TwoFactorRecoveryCodes, error)) *MockClient_TwoFactorActivate_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TwoFactorEnroll provides a mock function for the type MockClient
+func (_mock *MockClient) TwoFactorEnroll() (*woodpecker.TwoFactorEnrollment, error) {
+	ret := _mock.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for TwoFactorEnroll")
+	}
+
+	var r0 *woodpecker.TwoFactorEnrollment
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func() (*woodpecker.TwoFactorEnrollment, error)); ok {
+		return returnFunc()
+	}
+	if returnFunc, ok := ret.Get(0).(func() *woodpecker.TwoFactorEnrollment); ok {
+		r0 = returnFunc()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.TwoFactorEnrollment)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func() error); ok {
+		r1 = returnFunc()
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_TwoFactorEnroll_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TwoFactorEnroll'
+type MockClient_TwoFactorEnroll_Call struct {
+	*mock.Call
+}
+
+// TwoFactorEnroll is a helper method to define mock.On call
+func (_e *MockClient_Expecter) TwoFactorEnroll() *MockClient_TwoFactorEnroll_Call {
+	return &MockClient_TwoFactorEnroll_Call{Call: _e.mock.On("TwoFactorEnroll")}
+}
+
+func (_c *MockClient_TwoFactorEnroll_Call) Run(run func()) *MockClient_TwoFactorEnroll_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *MockClient_TwoFactorEnroll_Call) Return(twoFactorEnrollment *woodpecker.TwoFactorEnrollment, err error) *MockClient_TwoFactorEnroll_Call {
+	_c.Call.Return(twoFactorEnrollment, err)
+	return _c
+}
+
+func (_c *MockClient_TwoFactorEnroll_Call) RunAndReturn(run func() (*woodpecker.TwoFactorEnrollment, error)) *MockClient_TwoFactorEnroll_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TwoFactorRecoveryCodes provides a mock function for the type MockClient
+func (_mock *MockClient) TwoFactorRecoveryCodes(code string) (*woodpecker.TwoFactorRecoveryCodes, error) {
+	ret := _mock.Called(code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TwoFactorRecoveryCodes")
+	}
+
+	var r0 *woodpecker.TwoFactorRecoveryCodes
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (*woodpecker.TwoFactorRecoveryCodes, error)); ok {
+		return returnFunc(code)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) *woodpecker.TwoFactorRecoveryCodes); ok {
+		r0 = returnFunc(code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*woodpecker.TwoFactorRecoveryCodes)
+		}
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(code)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_TwoFactorRecoveryCodes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TwoFactorRecoveryCodes'
+type MockClient_TwoFactorRecoveryCodes_Call struct {
+	*mock.Call
+}
+
+// TwoFactorRecoveryCodes is a helper method to define mock.On call
+//   - code string
+func (_e *MockClient_Expecter) TwoFactorRecoveryCodes(code interface{}) *MockClient_TwoFactorRecoveryCodes_Call {
+	return &MockClient_TwoFactorRecoveryCodes_Call{Call: _e.mock.On("TwoFactorRecoveryCodes", code)}
+}
+
+func (_c *MockClient_TwoFactorRecoveryCodes_Call) Run(run func(code string)) *MockClient_TwoFactorRecoveryCodes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_TwoFactorRecoveryCodes_Call) Return(twoFactorRecoveryCodes *woodpecker.TwoFactorRecoveryCodes, err error) *MockClient_TwoFactorRecoveryCodes_Call {
+	_c.Call.Return(twoFactorRecoveryCodes, err)
+	return _c
+}
+
+func (_c *MockClient_TwoFactorRecoveryCodes_Call) RunAndReturn(run func(code string) (*woodpecker.TwoFactorRecoveryCodes, error)) *MockClient_TwoFactorRecoveryCodes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TwoFactorVerify provides a mock function for the type MockClient
+func (_mock *MockClient) TwoFactorVerify(code string) (string, error) {
+	ret := _mock.Called(code)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TwoFactorVerify")
+	}
+
+	var r0 string
+	var r1 error
+	if returnFunc, ok := ret.Get(0).(func(string) (string, error)); ok {
+		return returnFunc(code)
+	}
+	if returnFunc, ok := ret.Get(0).(func(string) string); ok {
+		r0 = returnFunc(code)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+	if returnFunc, ok := ret.Get(1).(func(string) error); ok {
+		r1 = returnFunc(code)
+	} else {
+		r1 = ret.Error(1)
+	}
+	return r0, r1
+}
+
+// MockClient_TwoFactorVerify_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TwoFactorVerify'
+type MockClient_TwoFactorVerify_Call struct {
+	*mock.Call
+}
+
+// TwoFactorVerify is a helper method to define mock.On call
+//   - code string
+func (_e *MockClient_Expecter) TwoFactorVerify(code interface{}) *MockClient_TwoFactorVerify_Call {
+	return &MockClient_TwoFactorVerify_Call{Call: _e.mock.On("TwoFactorVerify", code)}
+}
+
+func (_c *MockClient_TwoFactorVerify_Call) Run(run func(code string)) *MockClient_TwoFactorVerify_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		run(
+			arg0,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_TwoFactorVerify_Call) Return(s string, err error) *MockClient_TwoFactorVerify_Call {
+	_c.Call.Return(s, err)
+	return _c
+}
+
+func (_c *MockClient_TwoFactorVerify_Call) RunAndReturn(run func(code string) (string, error)) *MockClient_TwoFactorVerify_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // User provides a mock function for the type MockClient
 func (_mock *MockClient) User(login string, forgeID ...int64) (*woodpecker.User, error) {
 	var tmpRet mock.Arguments
@@ -4980,3 +6177,69 @@ func (_c *MockClient_UserPost_Call) RunAndReturn(run func(user *woodpecker.User)
 	_c.Call.Return(run)
 	return _c
 }
+
+// UserResetTwoFactor provides a mock function for the type MockClient
+func (_mock *MockClient) UserResetTwoFactor(login string, forgeID ...int64) error {
+	var tmpRet mock.Arguments
+	if len(forgeID) > 0 {
+		tmpRet = _mock.Called(login, forgeID)
+	} else {
+		tmpRet = _mock.Called(login)
+	}
+	ret := tmpRet
+
+	if len(ret) == 0 {
+		panic("no return value specified for UserResetTwoFactor")
+	}
+
+	var r0 error
+	if returnFunc, ok := ret.Get(0).(func(string, ...int64) error); ok {
+		r0 = returnFunc(login, forgeID...)
+	} else {
+		r0 = ret.Error(0)
+	}
+	return r0
+}
+
+// MockClient_UserResetTwoFactor_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UserResetTwoFactor'
+type MockClient_UserResetTwoFactor_Call struct {
+	*mock.Call
+}
+
+// UserResetTwoFactor is a helper method to define mock.On call
+//   - login string
+//   - forgeID ...int64
+func (_e *MockClient_Expecter) UserResetTwoFactor(login interface{}, forgeID ...interface{}) *MockClient_UserResetTwoFactor_Call {
+	return &MockClient_UserResetTwoFactor_Call{Call: _e.mock.On("UserResetTwoFactor",
+		append([]interface{}{login}, forgeID...)...)}
+}
+
+func (_c *MockClient_UserResetTwoFactor_Call) Run(run func(login string, forgeID ...int64)) *MockClient_UserResetTwoFactor_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		var arg0 string
+		if args[0] != nil {
+			arg0 = args[0].(string)
+		}
+		var arg1 []int64
+		var variadicArgs []int64
+		if len(args) > 1 {
+			variadicArgs = args[1].([]int64)
+		}
+		arg1 = variadicArgs
+		run(
+			arg0,
+			arg1...,
+		)
+	})
+	return _c
+}
+
+func (_c *MockClient_UserResetTwoFactor_Call) Return(err error) *MockClient_UserResetTwoFactor_Call {
+	_c.Call.Return(err)
+	return _c
+}
+
+func (_c *MockClient_UserResetTwoFactor_Call) RunAndReturn(run func(login string, forgeID ...int64) error) *MockClient_UserResetTwoFactor_Call {
+	_c.Call.Return(run)
+	return _c
+}
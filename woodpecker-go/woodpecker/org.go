@@ -39,6 +39,14 @@ func (c *client) OrgList(opt ListOptions) ([]*Org, error) {
 	return out, err
 }
 
+// OrgPatch updates an organization.
+func (c *client) OrgPatch(orgID int64, in *OrgPatch) (*Org, error) {
+	out := new(Org)
+	uri := fmt.Sprintf(pathOrg, c.addr, orgID)
+	err := c.patch(uri, in, out)
+	return out, err
+}
+
 // OrgSecret returns an organization secret by name.
 func (c *client) OrgSecret(orgID int64, secret string) (*Secret, error) {
 	out := new(Secret)
@@ -4,13 +4,75 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const (
 	pathPipelineQueue    = "%s/api/pipelines"
+	pathPipelineQuery    = "%s/api/pipelines/query"
 	pathPipelineMetadata = "%s/api/repos/%d/pipelines/%d/metadata"
 )
 
+// PipelineQuery selects pipelines across repositories, unset fields do not filter.
+type PipelineQuery struct {
+	RepoIDs  []int64
+	Events   []string
+	Statuses []string
+	Authors  []string
+	// After and Before limit the creation time of the pipelines.
+	After  time.Time
+	Before time.Time
+	// MinDuration and MaxDuration limit the duration of finished pipelines.
+	MinDuration time.Duration
+	MaxDuration time.Duration
+	// SortBy is one of created (default), started, finished or duration.
+	SortBy    string
+	Ascending bool
+}
+
+// QueryEncode returns the URL query parameters for the PipelineQuery.
+func (query *PipelineQuery) QueryEncode(opt ListOptions) string {
+	values := opt.getURLQuery()
+	if len(query.RepoIDs) > 0 {
+		repoIDs := make([]string, 0, len(query.RepoIDs))
+		for _, repoID := range query.RepoIDs {
+			repoIDs = append(repoIDs, strconv.FormatInt(repoID, 10))
+		}
+		values.Add("repo_id", strings.Join(repoIDs, ","))
+	}
+	if len(query.Events) > 0 {
+		values.Add("event", strings.Join(query.Events, ","))
+	}
+	if len(query.Statuses) > 0 {
+		values.Add("status", strings.Join(query.Statuses, ","))
+	}
+	if len(query.Authors) > 0 {
+		values.Add("author", strings.Join(query.Authors, ","))
+	}
+	if !query.After.IsZero() {
+		values.Add("after", query.After.Format(time.RFC3339))
+	}
+	if !query.Before.IsZero() {
+		values.Add("before", query.Before.Format(time.RFC3339))
+	}
+	if query.MinDuration > 0 {
+		values.Add("min_duration", strconv.FormatInt(int64(query.MinDuration.Seconds()), 10))
+	}
+	if query.MaxDuration > 0 {
+		values.Add("max_duration", strconv.FormatInt(int64(query.MaxDuration.Seconds()), 10))
+	}
+	if query.SortBy != "" {
+		values.Add("sort_by", query.SortBy)
+	}
+	if query.Ascending {
+		values.Add("ascending", "true")
+	}
+	return values.Encode()
+}
+
 // PipelineQueue returns a list of enqueued pipelines.
 func (c *client) PipelineQueue() ([]*Feed, error) {
 	var out []*Feed
@@ -19,6 +81,15 @@ func (c *client) PipelineQueue() ([]*Feed, error) {
 	return out, err
 }
 
+// PipelineQuery returns the pipelines across all accessible repositories matching the query.
+func (c *client) PipelineQuery(query *PipelineQuery, opt ListOptions) ([]*Feed, error) {
+	var out []*Feed
+	uri, _ := url.Parse(fmt.Sprintf(pathPipelineQuery, c.addr))
+	uri.RawQuery = query.QueryEncode(opt)
+	err := c.get(uri.String(), &out)
+	return out, err
+}
+
 // PipelineMetadata returns metadata for a pipeline, workflow name is optional.
 func (c *client) PipelineMetadata(repoID int64, pipelineNumber int) ([]byte, error) {
 	uri := fmt.Sprintf(pathPipelineMetadata, c.addr, repoID, pipelineNumber)
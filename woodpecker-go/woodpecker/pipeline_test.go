@@ -0,0 +1,34 @@
+package woodpecker
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_PipelineQuery(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/api/pipelines/query", r.URL.Path)
+		assert.Equal(t, "after=2025-01-01T00%3A00%3A00Z&event=push%2Ctag&min_duration=60&page=2&perPage=10&repo_id=1%2C2&sort_by=duration", r.URL.RawQuery)
+
+		_, err := fmt.Fprint(w, `[{"repo_id":1,"id":2,"number":3,"event":"push"}]`)
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+	feed, err := client.PipelineQuery(&PipelineQuery{
+		RepoIDs:     []int64{1, 2},
+		Events:      []string{"push", "tag"},
+		After:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		MinDuration: time.Minute,
+		SortBy:      "duration",
+	}, ListOptions{Page: 2, PerPage: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, []*Feed{{RepoID: 1, ID: 2, Number: 3, Event: "push"}}, feed)
+}
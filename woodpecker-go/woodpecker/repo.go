@@ -15,6 +15,8 @@ const (
 	pathRepoMove       = "%s/api/repos/%d/move"
 	pathChown          = "%s/api/repos/%d/chown"
 	pathRepair         = "%s/api/repos/%d/repair"
+	pathRepoHookRotate = "%s/api/repos/%d/hook/rotate?%s"
+	pathRepoHookPrune  = "%s/api/repos/%d/hook/prune"
 	pathPipelines      = "%s/api/repos/%d/pipelines"
 	pathPipeline       = "%s/api/repos/%d/pipelines/%v"
 	pathPipelineLogs   = "%s/api/repos/%d/logs/%d"
@@ -59,6 +61,7 @@ type DeployOptions struct {
 
 type PipelineStartOptions struct {
 	Params map[string]string // custom KEY=value parameters to be injected into the step environment
+	Force  bool              // run the pipeline even if the result of an identical pipeline could be reused
 }
 
 type PipelineLastOptions struct {
@@ -110,6 +113,9 @@ func (opt *DeployOptions) QueryEncode() string {
 // QueryEncode returns the URL query parameters for the PipelineStartOptions.
 func (opt *PipelineStartOptions) QueryEncode() string {
 	query := mapValues(opt.Params)
+	if opt.Force {
+		query.Add("force", "true")
+	}
 	return query.Encode()
 }
 
@@ -169,9 +175,25 @@ func (c *client) RepoChown(repoID int64) (*Repo, error) {
 	return out, err
 }
 
-// RepoRepair repairs the repository hooks.
-func (c *client) RepoRepair(repoID int64) error {
+// RepoRepair repairs the repository hooks and returns what was fixed.
+func (c *client) RepoRepair(repoID int64) (*RepoRepairReport, error) {
+	out := new(RepoRepairReport)
 	uri := fmt.Sprintf(pathRepair, c.addr, repoID)
+	return out, c.post(uri, nil, out)
+}
+
+// RepoHookRotate rotates the webhook secret of the repository and re-registers the webhook.
+// Webhooks using the previous secret are accepted for the grace period.
+func (c *client) RepoHookRotate(repoID int64, gracePeriod time.Duration) error {
+	query := url.Values{}
+	query.Set("grace_period", gracePeriod.String())
+	uri := fmt.Sprintf(pathRepoHookRotate, c.addr, repoID, query.Encode())
+	return c.post(uri, nil, nil)
+}
+
+// RepoHookPrune revokes the previous webhook secrets of the repository.
+func (c *client) RepoHookPrune(repoID int64) error {
+	uri := fmt.Sprintf(pathRepoHookPrune, c.addr, repoID)
 	return c.post(uri, nil, nil)
 }
 
@@ -311,6 +333,13 @@ func (c *client) CronGet(repoID, cronID int64) (*Cron, error) {
 	return out, c.get(uri, out)
 }
 
+// CronRun runs a cron job by cron-id for the specified repository now.
+func (c *client) CronRun(repoID, cronID int64) (*Pipeline, error) {
+	out := new(Pipeline)
+	uri := fmt.Sprintf(pathRepoCron, c.addr, repoID, cronID)
+	return out, c.post(uri, nil, out)
+}
+
 // Pipeline returns a repository pipeline by pipeline-id.
 func (c *client) Pipeline(repoID, pipeline int64) (*Pipeline, error) {
 	out := new(Pipeline)
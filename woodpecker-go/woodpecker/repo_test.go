@@ -220,6 +220,25 @@ func TestClientPipelineStart(t *testing.T) {
 				ID: 789,
 			},
 		},
+		{
+			name: "forced",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/repos/123/pipelines/456?force=true", r.URL.RequestURI())
+
+				w.WriteHeader(http.StatusOK)
+				_, err := fmt.Fprint(w, `{"id":789}`)
+				assert.NoError(t, err)
+			},
+			repoID:     123,
+			pipelineID: 456,
+			opts: PipelineStartOptions{
+				Force: true,
+			},
+			expectedPipeline: &Pipeline{
+				ID: 789,
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -442,3 +461,28 @@ func TestClientRepoMove(t *testing.T) {
 		})
 	}
 }
+
+func TestClientRepoHookRotate(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/repos/123/hook/rotate", r.URL.Path)
+		assert.Equal(t, "30m0s", r.URL.Query().Get("grace_period"))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+	assert.NoError(t, client.RepoHookRotate(123, 30*time.Minute))
+}
+
+func TestClientRepoHookPrune(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/repos/123/hook/prune", r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+	assert.NoError(t, client.RepoHookPrune(123))
+}
@@ -0,0 +1,83 @@
+package woodpecker
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	pathStreamStepLogs = "%s/api/stream/logs/%d/%d/%d?replay_lines=0&replay_bytes=0"
+
+	// maxStreamLineSize is the maximum size of a single line of the event stream.
+	maxStreamLineSize = 1024 * 1024
+)
+
+// StepLogStream streams the logs of a running step, starting with all lines written so far.
+// It calls fn for every log entry and returns when the step is done or ctx is canceled.
+func (c *client) StepLogStream(ctx context.Context, repoID, pipeline, stepID int64, fn func(*LogEntry) error) error {
+	uri := fmt.Sprintf(pathStreamStepLogs, c.addr, repoID, pipeline, stepID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, uri, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode > http.StatusPartialContent {
+		out, _ := io.ReadAll(resp.Body)
+		return &ClientError{
+			StatusCode: resp.StatusCode,
+			Message:    string(out),
+		}
+	}
+
+	return readLogStream(resp.Body, fn)
+}
+
+// readLogStream reads the server sent events of a log stream.
+// The server ends the stream with an error event, which is "eof" if the step finished.
+func readLogStream(r io.Reader, fn func(*LogEntry) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxStreamLineSize)
+
+	var event, data string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if event == "error" {
+				switch data {
+				case "eof", "step not running (anymore)":
+					return nil
+				default:
+					return errors.New(data)
+				}
+			}
+			if data != "" {
+				entry := new(LogEntry)
+				if err := json.Unmarshal([]byte(data), entry); err != nil {
+					return err
+				}
+				if err := fn(entry); err != nil {
+					return err
+				}
+			}
+			event, data = "", ""
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+	return scanner.Err()
+}
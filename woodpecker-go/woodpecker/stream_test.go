@@ -0,0 +1,44 @@
+package woodpecker
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStepLogStream(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/stream/logs/1/2/3", r.URL.Path)
+		assert.Equal(t, "0", r.URL.Query().Get("replay_lines"))
+		_, err := fmt.Fprint(w, ": ping\n\n"+
+			"id: 1\ndata: {\"line\":0,\"data\":\"aGVsbG8=\"}\n\n"+
+			"id: 2\ndata: {\"line\":1,\"data\":\"d29ybGQ=\"}\n\n"+
+			"event: error\ndata: eof\n\n")
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	var lines []string
+	client := NewClient(ts.URL, http.DefaultClient)
+	err := client.StepLogStream(t.Context(), 1, 2, 3, func(entry *LogEntry) error {
+		lines = append(lines, string(entry.Data))
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"hello", "world"}, lines)
+}
+
+func TestStepLogStreamError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, err := fmt.Fprint(w, "event: error\ndata: pipeline not found\n\n")
+		assert.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+	err := client.StepLogStream(t.Context(), 1, 2, 3, func(*LogEntry) error { return nil })
+	assert.EqualError(t, err, "pipeline not found")
+}
@@ -46,6 +46,23 @@ type (
 		Avatar        string `json:"avatar_url"`
 		Active        bool   `json:"active"`
 		Admin         bool   `json:"admin"`
+		TOTPEnabled   bool   `json:"totp_enabled"`
+	}
+
+	// TwoFactorEnrollment is the secret of an enrolled second factor.
+	TwoFactorEnrollment struct {
+		Secret string `json:"secret"`
+		URI    string `json:"uri"`
+	}
+
+	// TwoFactorCode is a TOTP or recovery code.
+	TwoFactorCode struct {
+		Code string `json:"code"`
+	}
+
+	// TwoFactorRecoveryCodes are the recovery codes of a second factor.
+	TwoFactorRecoveryCodes struct {
+		RecoveryCodes []string `json:"recovery_codes"`
 	}
 
 	TrustedConfiguration struct {
@@ -67,26 +84,69 @@ type (
 		Branch                       string               `json:"default_branch,omitempty"`
 		SCMKind                      string               `json:"scm,omitempty"`
 		Timeout                      int64                `json:"timeout,omitempty"`
+		MaxTimeout                   int64                `json:"max_timeout,omitempty"`
+		MaxMatrixJobs                int                  `json:"max_matrix_jobs,omitempty"`
+		LogStore                     string               `json:"log_store,omitempty"`
+		LogMaxSize                   int                  `json:"log_max_size,omitempty"`
+		LogMaxLines                  int                  `json:"log_max_lines,omitempty"`
+		Priority                     int                  `json:"priority"`
 		Visibility                   string               `json:"visibility"`
 		IsSCMPrivate                 bool                 `json:"private"`
 		Trusted                      TrustedConfiguration `json:"trusted"`
 		RequireApproval              ApprovalMode         `json:"require_approval"`
 		IsActive                     bool                 `json:"active"`
 		AllowPull                    bool                 `json:"allow_pr"`
+		ResultCache                  bool                 `json:"result_cache"`
+		WorkflowStatusChecks         bool                 `json:"workflow_status_checks"`
+		ExposeSecretsToForks         *bool                `json:"expose_secrets_to_forks"`
 		Config                       string               `json:"config_file"`
 		CancelPreviousPipelineEvents []string             `json:"cancel_previous_pipeline_events"`
 		NetrcTrustedPlugins          []string             `json:"netrc_trusted"`
+		PrivilegedPlugins            []string             `json:"privileged_plugins"`
+		TrustedClonePlugins          []string             `json:"trusted_clone_plugins"`
 	}
 
 	// RepoPatch defines a repository patch request.
 	RepoPatch struct {
-		Config          *string       `json:"config_file,omitempty"`
-		IsTrusted       *bool         `json:"trusted,omitempty"`
-		RequireApproval *ApprovalMode `json:"require_approval,omitempty"`
-		Timeout         *int64        `json:"timeout,omitempty"`
-		Visibility      *string       `json:"visibility"`
-		AllowPull       *bool         `json:"allow_pr,omitempty"`
-		PipelineCounter *int          `json:"pipeline_counter,omitempty"`
+		Config               *string       `json:"config_file,omitempty"`
+		IsTrusted            *bool         `json:"trusted,omitempty"`
+		RequireApproval      *ApprovalMode `json:"require_approval,omitempty"`
+		Timeout              *int64        `json:"timeout,omitempty"`
+		MaxTimeout           *int64        `json:"max_timeout,omitempty"`
+		MaxMatrixJobs        *int          `json:"max_matrix_jobs,omitempty"`
+		LogStore             *string       `json:"log_store,omitempty"`
+		LogMaxSize           *int          `json:"log_max_size,omitempty"`
+		LogMaxLines          *int          `json:"log_max_lines,omitempty"`
+		LogRetention         *string       `json:"log_retention,omitempty"`
+		Priority             *int          `json:"priority,omitempty"`
+		Visibility           *string       `json:"visibility"`
+		AllowPull            *bool         `json:"allow_pr,omitempty"`
+		ResultCache          *bool         `json:"result_cache,omitempty"`
+		WorkflowStatusChecks *bool         `json:"workflow_status_checks,omitempty"`
+		ExposeSecretsToForks *bool         `json:"expose_secrets_to_forks,omitempty"`
+		PipelineCounter      *int          `json:"pipeline_counter,omitempty"`
+		PrivilegedPlugins    *[]string     `json:"privileged_plugins,omitempty"`
+		TrustedClonePlugins  *[]string     `json:"trusted_clone_plugins,omitempty"`
+	}
+
+	// RepoRepairReport describes what was fixed while repairing a repository.
+	RepoRepairReport struct {
+		RepoID  int64    `json:"repo_id"`
+		Changes []string `json:"changes"`
+	}
+
+	// RepoSyncReport describes what changed while synchronizing the repositories with the forge.
+	RepoSyncReport struct {
+		Added   []string     `json:"added"`
+		Enabled []string     `json:"enabled"`
+		Removed []string     `json:"removed"`
+		Renamed []RepoRename `json:"renamed"`
+	}
+
+	// RepoRename describes a renamed repository.
+	RepoRename struct {
+		From string `json:"from"`
+		To   string `json:"to"`
 	}
 
 	PipelineError struct {
@@ -125,6 +185,7 @@ type (
 		Reviewer    string           `json:"reviewed_by"`
 		Reviewed    int64            `json:"reviewed"`
 		Workflows   []*Workflow      `json:"workflows,omitempty"`
+		CacheHit    int64            `json:"cache_hit,omitempty"`
 	}
 
 	// Workflow represents a workflow in the pipeline.
@@ -223,11 +284,38 @@ type (
 		Paused        bool       `json:"paused,omitempty"`
 	}
 
+	// ServerInfo describes the server.
+	ServerInfo struct {
+		Version  string         `json:"version"`
+		Forge    string         `json:"forge"`
+		Features ServerFeatures `json:"features"`
+	}
+
+	// ServerFeatures are the features enabled on the server.
+	ServerFeatures struct {
+		Swagger          bool   `json:"swagger"`
+		OpenRegistration bool   `json:"open_registration"`
+		LogStore         string `json:"log_store"`
+	}
+
 	// LogLevel is for checking/setting logging level.
 	LogLevel struct {
 		Level string `json:"log-level"`
 	}
 
+	// Maintenance is for checking/setting the maintenance mode.
+	Maintenance struct {
+		Enabled bool `json:"enabled"`
+	}
+
+	// Migration is a store migration of the server.
+	Migration struct {
+		ID          string `json:"id"`
+		Description string `json:"description"`
+		Applied     bool   `json:"applied"`
+		Reversible  bool   `json:"reversible"`
+	}
+
 	// LogEntry is a single log entry.
 	LogEntry struct {
 		ID     int64        `json:"id"`
@@ -240,14 +328,15 @@ type (
 
 	// Cron is the JSON data of a cron job.
 	Cron struct {
-		ID        int64  `json:"id"`
-		Name      string `json:"name"`
-		RepoID    int64  `json:"repo_id"`
-		CreatorID int64  `json:"creator_id"`
-		NextExec  int64  `json:"next_exec"`
-		Schedule  string `json:"schedule"`
-		Created   int64  `json:"created"`
-		Branch    string `json:"branch"`
+		ID        int64             `json:"id"`
+		Name      string            `json:"name"`
+		RepoID    int64             `json:"repo_id"`
+		CreatorID int64             `json:"creator_id"`
+		NextExec  int64             `json:"next_exec"`
+		Schedule  string            `json:"schedule"`
+		Created   int64             `json:"created"`
+		Branch    string            `json:"branch"`
+		Variables map[string]string `json:"variables"`
 	}
 
 	// PipelineOptions is the JSON data for creating a new pipeline.
@@ -272,23 +361,50 @@ type (
 		Capacity     int32             `json:"capacity"`
 		Version      string            `json:"version"`
 		NoSchedule   bool              `json:"no_schedule"`
+		Offline      bool              `json:"offline"`
 		CustomLabels map[string]string `json:"custom_labels"`
 	}
 
+	// AgentToken is the JSON data for a shared agent token.
+	AgentToken struct {
+		ID      int64  `json:"id"`
+		Token   string `json:"token"`
+		Created int64  `json:"created"`
+	}
+
 	// Task is the JSON data for a task.
 	Task struct {
-		ID           string            `json:"id"`
-		Labels       map[string]string `json:"labels"`
-		Dependencies []string          `json:"dependencies"`
-		RunOn        []string          `json:"run_on"`
-		DepStatus    map[string]string `json:"dep_status"`
-		AgentID      int64             `json:"agent_id"`
+		ID             string            `json:"id"`
+		Labels         map[string]string `json:"labels"`
+		Dependencies   []string          `json:"dependencies"`
+		RunOn          []string          `json:"run_on"`
+		DepStatus      map[string]string `json:"dep_status"`
+		AgentID        int64             `json:"agent_id"`
+		AgentName      string            `json:"agent_name"`
+		PipelineNumber int64             `json:"pipeline_number"`
+		RepoID         int64             `json:"repo_id"`
+		RepoFullName   string            `json:"repo_full_name"`
 	}
 
 	// Org is the JSON data for an organization.
 	Org struct {
-		ID     int64  `json:"id"`
-		Name   string `json:"name"`
-		IsUser bool   `json:"is_user"`
+		ID           int64           `json:"id"`
+		Name         string          `json:"name"`
+		IsUser       bool            `json:"is_user"`
+		FeatureFlags OrgFeatureFlags `json:"feature_flags"`
+	}
+
+	// OrgFeatureFlags overrides the global pipeline feature flags for all repos of an org.
+	OrgFeatureFlags struct {
+		AllowPullRequests    *bool     `json:"allow_pull_requests,omitempty"`
+		PrivilegedPlugins    *[]string `json:"privileged_plugins,omitempty"`
+		ExposeSecretsToForks *bool     `json:"expose_secrets_to_forks,omitempty"`
+		DefaultTimeout       *int64    `json:"default_timeout,omitempty"`
+		MaxRunningPipelines  *int      `json:"max_running_pipelines,omitempty"`
+	}
+
+	// OrgPatch defines an organization patch request.
+	OrgPatch struct {
+		FeatureFlags *OrgFeatureFlags `json:"feature_flags,omitempty"`
 	}
 )
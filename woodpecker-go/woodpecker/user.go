@@ -2,20 +2,30 @@ package woodpecker
 
 import (
 	"fmt"
+	"io"
+	"net/http"
 	"net/url"
 )
 
 const (
-	pathSelf  = "%s/api/user"
-	pathRepos = "%s/api/user/repos"
-	pathUsers = "%s/api/users"
-	pathUser  = "%s/api/users/%s?forge_id=%d"
+	pathSelf      = "%s/api/user"
+	pathRepos     = "%s/api/user/repos"
+	pathReposSync = "%s/api/user/repos/sync"
+	pathUsers     = "%s/api/users"
+	pathUser      = "%s/api/users/%s?forge_id=%d"
+	pathUser2FA   = "%s/api/users/%s/2fa?forge_id=%d"
+	pathSelf2FA   = "%s/api/user/2fa/%s"
 )
 
 type RepoListOptions struct {
 	All bool // query all repos, including inactive ones
 }
 
+type RepoSyncOptions struct {
+	Org    string // only synchronize repos of this organization
+	Enable string // activate added repos with a full name matching this glob pattern
+}
+
 type UserListOptions struct {
 	ListOptions
 }
@@ -29,6 +39,18 @@ func (opt *RepoListOptions) QueryEncode() string {
 	return query.Encode()
 }
 
+// QueryEncode returns the URL query parameters for the RepoSyncOptions.
+func (opt *RepoSyncOptions) QueryEncode() string {
+	query := make(url.Values)
+	if opt.Org != "" {
+		query.Add("org", opt.Org)
+	}
+	if opt.Enable != "" {
+		query.Add("enable", opt.Enable)
+	}
+	return query.Encode()
+}
+
 // Self returns the currently authenticated user.
 func (c *client) Self() (*User, error) {
 	out := new(User)
@@ -85,6 +107,49 @@ func (c *client) UserDel(login string, forgeID ...int64) error {
 	return c.delete(fmt.Sprintf(pathUser, c.addr, login, forgeID[0]))
 }
 
+// UserResetTwoFactor removes the second factor of a user account.
+// It is recommended to specify forgeID (default is 1).
+func (c *client) UserResetTwoFactor(login string, forgeID ...int64) error {
+	if len(forgeID) == 0 {
+		forgeID = []int64{defaultForgeID}
+	}
+	return c.delete(fmt.Sprintf(pathUser2FA, c.addr, login, forgeID[0]))
+}
+
+// TwoFactorEnroll creates a new second factor secret for the current user.
+func (c *client) TwoFactorEnroll() (*TwoFactorEnrollment, error) {
+	out := new(TwoFactorEnrollment)
+	uri := fmt.Sprintf(pathSelf2FA, c.addr, "enroll")
+	return out, c.post(uri, nil, out)
+}
+
+// TwoFactorActivate enables the enrolled second factor and returns the recovery codes.
+func (c *client) TwoFactorActivate(code string) (*TwoFactorRecoveryCodes, error) {
+	out := new(TwoFactorRecoveryCodes)
+	uri := fmt.Sprintf(pathSelf2FA, c.addr, "activate")
+	return out, c.post(uri, &TwoFactorCode{Code: code}, out)
+}
+
+// TwoFactorVerify verifies a TOTP or recovery code and returns a new token
+// with admin permissions for admins.
+func (c *client) TwoFactorVerify(code string) (string, error) {
+	uri := fmt.Sprintf(pathSelf2FA, c.addr, "verify")
+	body, err := c.open(uri, http.MethodPost, &TwoFactorCode{Code: code})
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+	token, err := io.ReadAll(body)
+	return string(token), err
+}
+
+// TwoFactorRecoveryCodes replaces the recovery codes of the current user.
+func (c *client) TwoFactorRecoveryCodes(code string) (*TwoFactorRecoveryCodes, error) {
+	out := new(TwoFactorRecoveryCodes)
+	uri := fmt.Sprintf(pathSelf2FA, c.addr, "recovery-codes")
+	return out, c.post(uri, &TwoFactorCode{Code: code}, out)
+}
+
 // RepoList returns a list of all repositories to which
 // the user has explicit access in the host system.
 func (c *client) RepoList(opt RepoListOptions) ([]*Repo, error) {
@@ -94,3 +159,12 @@ func (c *client) RepoList(opt RepoListOptions) ([]*Repo, error) {
 	err := c.get(uri.String(), &out)
 	return out, err
 }
+
+// RepoSync synchronizes the repositories of the user with the forge and
+// returns what changed.
+func (c *client) RepoSync(opt RepoSyncOptions) (*RepoSyncReport, error) {
+	out := new(RepoSyncReport)
+	uri, _ := url.Parse(fmt.Sprintf(pathReposSync, c.addr))
+	uri.RawQuery = opt.QueryEncode()
+	return out, c.post(uri.String(), nil, out)
+}
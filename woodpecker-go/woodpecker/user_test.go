@@ -337,3 +337,108 @@ func TestClient_RepoList(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_RepoSync(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		opt      RepoSyncOptions
+		expected *RepoSyncReport
+		wantErr  bool
+	}{
+		{
+			name: "success",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "/api/user/repos/sync?enable=octo-org%2F%2A&org=octo-org", r.URL.RequestURI())
+				w.WriteHeader(http.StatusOK)
+				_, err := fmt.Fprint(w, `{"added":["octo-org/new"],"enabled":["octo-org/new"],"removed":["octo-org/gone"],"renamed":[{"from":"octo-org/old","to":"octo-org/renamed"}]}`)
+				assert.NoError(t, err)
+			},
+			opt: RepoSyncOptions{Org: "octo-org", Enable: "octo-org/*"},
+			expected: &RepoSyncReport{
+				Added:   []string{"octo-org/new"},
+				Enabled: []string{"octo-org/new"},
+				Removed: []string{"octo-org/gone"},
+				Renamed: []RepoRename{{From: "octo-org/old", To: "octo-org/renamed"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "server error",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			opt:     RepoSyncOptions{},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			client := NewClient(ts.URL, http.DefaultClient)
+			report, err := client.RepoSync(tt.opt)
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, report)
+		})
+	}
+}
+
+func TestClient_UserResetTwoFactor(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodDelete, r.Method)
+		assert.Equal(t, "/api/users/octocat/2fa?forge_id=2", r.URL.RequestURI())
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+	assert.NoError(t, client.UserResetTwoFactor("octocat", 2))
+}
+
+func TestClient_TwoFactor(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		switch r.URL.Path {
+		case "/api/user/2fa/enroll":
+			_, err := fmt.Fprint(w, `{"secret":"JBSWY3DPEHPK3PXP","uri":"otpauth://totp/Woodpecker:octocat"}`)
+			assert.NoError(t, err)
+		case "/api/user/2fa/activate", "/api/user/2fa/recovery-codes":
+			_, err := fmt.Fprint(w, `{"recovery_codes":["0123abcd-4567efab"]}`)
+			assert.NoError(t, err)
+		case "/api/user/2fa/verify":
+			_, err := fmt.Fprint(w, "verified-token")
+			assert.NoError(t, err)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	client := NewClient(ts.URL, http.DefaultClient)
+
+	enrollment, err := client.TwoFactorEnroll()
+	assert.NoError(t, err)
+	assert.Equal(t, &TwoFactorEnrollment{Secret: "JBSWY3DPEHPK3PXP", URI: "otpauth://totp/Woodpecker:octocat"}, enrollment)
+
+	codes, err := client.TwoFactorActivate("123456")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0123abcd-4567efab"}, codes.RecoveryCodes)
+
+	token, err := client.TwoFactorVerify("123456")
+	assert.NoError(t, err)
+	assert.Equal(t, "verified-token", token)
+
+	codes, err = client.TwoFactorRecoveryCodes("123456")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0123abcd-4567efab"}, codes.RecoveryCodes)
+}